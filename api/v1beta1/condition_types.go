@@ -40,6 +40,30 @@ const (
 	// the reconciliation succeeded.
 	ReconciliationSucceededReason string = "ReconciliationSucceeded"
 
+	// DependencyNotReadyReason represents the fact that one of the
+	// KluctlDeployments listed in spec.dependsOn is not ready yet.
+	DependencyNotReadyReason string = "DependencyNotReady"
+
+	// DependencyCycleReason represents the fact that the spec.dependsOn
+	// references of multiple KluctlDeployments form a cycle.
+	DependencyCycleReason string = "DependencyCycle"
+
+	// ArgsFromNotFoundReason represents the fact that a ConfigMap, Secret
+	// or key referenced via spec.argsFrom does not exist.
+	ArgsFromNotFoundReason string = "ArgsFromNotFound"
+
 	// WaitingForLegacyMigrationReason means that the controller is waiting for the legacy controller to set `readyForMigration=true`
 	WaitingForLegacyMigrationReason string = "WaitingForLegacyMigration"
 )
+
+const (
+	// TerminatingCondition indicates that the KluctlDeployment is being deleted and the controller is garbage
+	// collecting the deployed objects.
+	TerminatingCondition string = "Terminating"
+
+	// DeletingReason represents the fact that the controller is deleting the deployed objects.
+	DeletingReason string = "Deleting"
+
+	// DeleteFailedReason represents the fact that deleting the deployed objects failed.
+	DeleteFailedReason string = "DeleteFailed"
+)
@@ -33,6 +33,12 @@ const (
 
 	KluctlDeployModeFull   = "full-deploy"
 	KluctlDeployPokeImages = "poke-images"
+
+	DeletionPolicyOrphan        = "orphan"
+	DeletionPolicyDelete        = "delete"
+	DeletionPolicyDeleteAndWait = "delete-and-wait"
+
+	DefaultDeletionTimeout = 10 * time.Minute
 )
 
 // The following annotations are set by the CLI (gitops sub-commands) and the webui. The values contains a JSON serialized
@@ -44,6 +50,10 @@ const (
 	KluctlRequestPruneAnnotation     = "kluctl.io/request-prune"
 	KluctlRequestValidateAnnotation  = "kluctl.io/request-validate"
 
+	// KluctlRequestCancelAnnotation contains a JSON serialized CancelRequest. It causes the controller to cancel the
+	// reconciliation that is currently in progress.
+	KluctlRequestCancelAnnotation = "kluctl.io/request-cancel"
+
 	// SourceOverrideScheme is used when source overrides are setup via the CLI
 	SourceOverrideScheme = "grpc+source-override"
 )
@@ -145,6 +155,12 @@ type KluctlDeploymentSpec struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Args *runtime.RawExtension `json:"args,omitempty"`
 
+	// ArgsFrom specifies a list of ConfigMaps and Secrets from which dynamic target args are loaded. Args are merged in
+	// the given order, with the inline args from spec.args taking precedence. Changes to the referenced objects
+	// cause a reconciliation. Args loaded from Secrets are treated as sensitive.
+	// +optional
+	ArgsFrom []ArgsFromReference `json:"argsFrom,omitempty"`
+
 	// Images contains a list of fixed image overrides.
 	// Equivalent to using '--fixed-images-file' when calling kluctl.
 	// +optional
@@ -229,6 +245,22 @@ type KluctlDeploymentSpec struct {
 	// +optional
 	Delete bool `json:"delete,omitempty"`
 
+	// DeletionPolicy specifies what happens to the deployed objects when the KluctlDeployment gets deleted.
+	// 'orphan' leaves all objects untouched, 'delete' deletes all objects without waiting for them to vanish and
+	// 'delete-and-wait' deletes all objects and waits until they are gone before the finalizer is removed.
+	// Defaults to 'delete' if Delete is true and to 'orphan' otherwise.
+	// +kubebuilder:validation:Enum=orphan;delete;delete-and-wait
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// DeletionTimeout specifies how long the controller tries to delete the deployed objects. After the timeout,
+	// the finalizer is removed even if objects remain in the cluster, and a command result with a warning is stored.
+	// Defaults to 10m.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	DeletionTimeout *metav1.Duration `json:"deletionTimeout,omitempty"`
+
 	// Manual enables manual deployments, meaning that the deployment will initially start as a dry run deployment
 	// and only after manual approval cause a real deployment
 	// +optional
@@ -242,6 +274,59 @@ type KluctlDeploymentSpec struct {
 	// 2. Use the Kluctl Webui to manually approve a deployment, which will set this field appropriately.
 	// +optional
 	ManualObjectsHash *string `json:"manualObjectsHash,omitempty"`
+
+	// DependsOn specifies a list of other KluctlDeployments that must be ready before this KluctlDeployment is
+	// deployed. While any of the dependencies is not ready, the deployment is skipped and the Ready condition is
+	// set to False with the reason DependencyNotReady.
+	// +optional
+	DependsOn []DependsOnReference `json:"dependsOn,omitempty"`
+}
+
+// DependsOnReference references a KluctlDeployment that must be ready before the referencing KluctlDeployment can
+// be deployed.
+type DependsOnReference struct {
+	// Namespace of the referenced KluctlDeployment. Defaults to the namespace of the referencing KluctlDeployment.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the referenced KluctlDeployment.
+	// +required
+	Name string `json:"name"`
+
+	// MinRevision specifies the minimum generation of the referenced KluctlDeployment that must have been
+	// reconciled successfully. If omitted, the current generation of the referenced KluctlDeployment must be ready.
+	// +optional
+	MinRevision *int64 `json:"minRevision,omitempty"`
+}
+
+// ArgsFromReference references a ConfigMap or Secret in the namespace of the KluctlDeployment from which args are
+// loaded. Values are parsed as YAML, the same way as values passed via '--arg'.
+type ArgsFromReference struct {
+	// Kind of the referenced object.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +required
+	Kind string `json:"kind"`
+
+	// Name of the referenced object.
+	// +required
+	Name string `json:"name"`
+
+	// Items maps keys of the referenced object to args. If omitted, all keys are loaded, each of them into the arg
+	// with the same name.
+	// +optional
+	Items []ArgsFromItem `json:"items,omitempty"`
+}
+
+// ArgsFromItem maps a single key of a ConfigMap or Secret to an arg.
+type ArgsFromItem struct {
+	// Key of the value in the referenced object.
+	// +required
+	Key string `json:"key"`
+
+	// Arg specifies the arg to load the value into. Nested args can be specified via dots, e.g. 'a.b'. Defaults to
+	// the key.
+	// +optional
+	Arg string `json:"arg,omitempty"`
 }
 
 // GetRetryInterval returns the retry interval
@@ -252,6 +337,25 @@ func (in KluctlDeploymentSpec) GetRetryInterval() time.Duration {
 	return in.Interval.Duration
 }
 
+// GetDeletionPolicy returns the deletion policy, taking the legacy Delete field into account
+func (in KluctlDeploymentSpec) GetDeletionPolicy() string {
+	if in.DeletionPolicy != "" {
+		return in.DeletionPolicy
+	}
+	if in.Delete {
+		return DeletionPolicyDelete
+	}
+	return DeletionPolicyOrphan
+}
+
+// GetDeletionTimeout returns the deletion timeout
+func (in KluctlDeploymentSpec) GetDeletionTimeout() time.Duration {
+	if in.DeletionTimeout != nil {
+		return in.DeletionTimeout.Duration
+	}
+	return DefaultDeletionTimeout
+}
+
 type ProjectSource struct {
 	// Git specifies a git repository as project source
 	// +optional
@@ -493,6 +597,10 @@ type KluctlDeploymentStatus struct {
 	// +optional
 	ValidateRequestResult *ManualRequestResult `json:"validateRequestResult,omitempty"`
 
+	// CancelRequestResult contains the result of the last cancel request
+	// +optional
+	CancelRequestResult *CancelRequestResult `json:"cancelRequestResult,omitempty"`
+
 	// ObservedGeneration is the last reconciled generation.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
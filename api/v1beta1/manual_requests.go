@@ -33,3 +33,43 @@ type ManualRequestResult struct {
 	// +optional
 	CommandError string `json:"commandError,omitempty"`
 }
+
+// CancelRequest is used in json form inside the kluctl.io/request-cancel annotation
+type CancelRequest struct {
+	// RequestValue is set to the time of the request by the CLI
+	// +required
+	RequestValue string `json:"requestValue"`
+
+	// RequestedBy identifies the user that requested the cancellation
+	// +optional
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+type CancelRequestResult struct {
+	// +required
+	Request CancelRequest `json:"request"`
+
+	// HandledTime is the time at which the controller picked up the request
+	// +required
+	HandledTime metav1.Time `json:"handledTime"`
+
+	// ReconcileId is the ID of the cancelled reconciliation. It is empty if no reconciliation was in progress.
+	// +optional
+	ReconcileId string `json:"reconcileId,omitempty"`
+
+	// Phase is the phase the reconciliation was in when the request was handled
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// EndTime is the time at which the cancelled reconciliation finished
+	// +optional
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+
+	// Stuck is set to true if the reconciliation did not finish within the grace period after cancellation, which
+	// means that it is in a phase that can not be cancelled
+	// +optional
+	Stuck bool `json:"stuck,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+}
@@ -28,6 +28,77 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgsFromItem) DeepCopyInto(out *ArgsFromItem) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgsFromItem.
+func (in *ArgsFromItem) DeepCopy() *ArgsFromItem {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgsFromItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgsFromReference) DeepCopyInto(out *ArgsFromReference) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ArgsFromItem, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgsFromReference.
+func (in *ArgsFromReference) DeepCopy() *ArgsFromReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgsFromReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CancelRequest) DeepCopyInto(out *CancelRequest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CancelRequest.
+func (in *CancelRequest) DeepCopy() *CancelRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(CancelRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CancelRequestResult) DeepCopyInto(out *CancelRequestResult) {
+	*out = *in
+	out.Request = in.Request
+	in.HandledTime.DeepCopyInto(&out.HandledTime)
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CancelRequestResult.
+func (in *CancelRequestResult) DeepCopy() *CancelRequestResult {
+	if in == nil {
+		return nil
+	}
+	out := new(CancelRequestResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Decryption) DeepCopyInto(out *Decryption) {
 	*out = *in
@@ -48,6 +119,26 @@ func (in *Decryption) DeepCopy() *Decryption {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependsOnReference) DeepCopyInto(out *DependsOnReference) {
+	*out = *in
+	if in.MinRevision != nil {
+		in, out := &in.MinRevision, &out.MinRevision
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependsOnReference.
+func (in *DependsOnReference) DeepCopy() *DependsOnReference {
+	if in == nil {
+		return nil
+	}
+	out := new(DependsOnReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmCredentials) DeepCopyInto(out *HelmCredentials) {
 	*out = *in
@@ -189,6 +280,13 @@ func (in *KluctlDeploymentSpec) DeepCopyInto(out *KluctlDeploymentSpec) {
 		*out = new(runtime.RawExtension)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ArgsFrom != nil {
+		in, out := &in.ArgsFrom, &out.ArgsFrom
+		*out = make([]ArgsFromReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Images != nil {
 		in, out := &in.Images, &out.Images
 		*out = make([]types.FixedImage, len(*in))
@@ -216,11 +314,23 @@ func (in *KluctlDeploymentSpec) DeepCopyInto(out *KluctlDeploymentSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DeletionTimeout != nil {
+		in, out := &in.DeletionTimeout, &out.DeletionTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	if in.ManualObjectsHash != nil {
 		in, out := &in.ManualObjectsHash, &out.ManualObjectsHash
 		*out = new(string)
 		**out = **in
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]DependsOnReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KluctlDeploymentSpec.
@@ -261,6 +371,11 @@ func (in *KluctlDeploymentStatus) DeepCopyInto(out *KluctlDeploymentStatus) {
 		*out = new(ManualRequestResult)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CancelRequestResult != nil {
+		in, out := &in.CancelRequestResult, &out.CancelRequestResult
+		*out = new(CancelRequestResult)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
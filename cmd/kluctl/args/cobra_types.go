@@ -3,6 +3,10 @@ package args
 import (
 	"fmt"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ExistingPathType string
@@ -64,3 +68,93 @@ func (s *ExistingDirType) Type() string {
 }
 
 func (s *ExistingDirType) String() string { return string(*s) }
+
+// DebugType is a boolean like flag that can optionally be set to a comma separated list of debug topics, e.g.
+// '--debug=vars'. Any value other than 'false' enables debug logging.
+type DebugType struct {
+	Enabled bool
+	Topics  []string
+}
+
+func (s *DebugType) Set(val string) error {
+	switch val {
+	case "", "true":
+		s.Enabled = true
+	case "false":
+		s.Enabled = false
+		s.Topics = nil
+	default:
+		s.Enabled = true
+		for _, t := range strings.Split(val, ",") {
+			t = strings.TrimSpace(t)
+			if t != "" && !slices.Contains(s.Topics, t) {
+				s.Topics = append(s.Topics, t)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *DebugType) Type() string {
+	return "topics"
+}
+
+func (s *DebugType) String() string {
+	if !s.Enabled {
+		return "false"
+	}
+	if len(s.Topics) == 0 {
+		return "true"
+	}
+	return strings.Join(s.Topics, ",")
+}
+
+// NoOptDefVal allows passing the flag without a value
+func (s *DebugType) NoOptDefVal() string {
+	return "true"
+}
+
+// AgeType is a duration flag that additionally accepts days, e.g. '30d' or '1d12h'.
+type AgeType time.Duration
+
+func (s *AgeType) Set(val string) error {
+	d, err := ParseAge(val)
+	if err != nil {
+		return err
+	}
+	*s = AgeType(d)
+	return nil
+}
+
+func (s *AgeType) Type() string {
+	return "duration"
+}
+
+func (s *AgeType) String() string {
+	if *s == 0 {
+		return ""
+	}
+	return time.Duration(*s).String()
+}
+
+// ParseAge parses a duration in the format accepted by time.ParseDuration with an optional leading number of days,
+// e.g. '30d', '1d12h' or '90m'.
+func ParseAge(val string) (time.Duration, error) {
+	var days time.Duration
+	if i := strings.IndexByte(val, 'd'); i != -1 {
+		n, err := strconv.ParseUint(val[:i], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %s", val)
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		val = val[i+1:]
+		if val == "" {
+			return days, nil
+		}
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, err
+	}
+	return days + d, nil
+}
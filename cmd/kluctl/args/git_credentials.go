@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/gobwas/glob"
+	"github.com/kluctl/kluctl/lib/credentials"
 	git_auth "github.com/kluctl/kluctl/lib/git/auth"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"os"
@@ -12,10 +13,11 @@ import (
 
 type GitCredentials struct {
 	GitUsername          []string `group:"git" skipenv:"true" help:"Specify username to use for Git basic authentication. Must be in the form --git-username=<host>/<path>=<username>."`
-	GitPassword          []string `group:"git" skipenv:"true" help:"Specify password to use for Git basic authentication. Must be in the form --git-password=<host>/<path>=<password>."`
+	GitPassword          []string `group:"git" skipenv:"true" sensitive:"true" help:"Specify password to use for Git basic authentication. Must be in the form --git-password=<host>/<path>=<password>."`
 	GitSshKeyFile        []string `group:"git" skipenv:"true" help:"Specify SSH key to use for Git authentication. Must be in the form --git-ssh-key-file=<host>/<path>=<filePath>."`
 	GitSshKnownHostsFile []string `group:"git" skipenv:"true" help:"Specify known_hosts file to use for Git authentication. Must be in the form --git-ssh-known-hosts-file=<host>/<path>=<filePath>."`
 	GitCAFile            []string `group:"git" skipenv:"true" help:"Specify CA bundle to use for https verification. Must be in the form --git-ca-file=<registry>/<repo>=<filePath>."`
+	GitCredentialsExec   []string `group:"git" skipenv:"true" help:"Specify an executable that is invoked to retrieve Git credentials. It is invoked with 'git <host> <path>' as arguments and must print a JSON object with 'username' and 'password'/'token' or 'sshKey' to stdout. Credentials are cached per host. Can be specified multiple times."`
 }
 
 func (c *GitCredentials) BuildAuthProvider(ctx context.Context) (git_auth.GitAuthProvider, error) {
@@ -108,5 +110,16 @@ func (c *GitCredentials) BuildAuthProvider(ctx context.Context) (git_auth.GitAut
 		la.AddEntry(*e)
 	}
 
-	return la, nil
+	if len(c.GitCredentialsExec) == 0 {
+		return la, nil
+	}
+
+	ret := &git_auth.GitAuthProviders{}
+	ret.RegisterAuthProvider(la, true)
+	for _, x := range c.GitCredentialsExec {
+		ret.RegisterAuthProvider(&git_auth.GitExecAuthProvider{
+			Exec: credentials.NewExecProvider(x),
+		}, true)
+	}
+	return ret, nil
 }
@@ -2,8 +2,11 @@ package args
 
 import (
 	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"path/filepath"
+	"strings"
 )
 
 type InclusionFlags struct {
@@ -35,3 +38,40 @@ func (args *InclusionFlags) ParseInclusionFromArgs() (*utils.Inclusion, error) {
 	}
 	return inclusion, nil
 }
+
+type PruneExcludeFlags struct {
+	PruneExclude  []string `group:"inclusion" help:"Exclude objects from orphan detection and pruning. The rule has the form 'group=<glob>,kind=<glob>,namespace=<glob>', where each part is optional. An empty group matches the core API group. Rules are merged with the pruneExclude rules from .kluctl.yaml. Can be specified multiple times."`
+	ShowSelection bool     `group:"inclusion" help:"Print the effective prune exclusion rules before the command starts."`
+}
+
+// ParsePruneExcludeFromArgs parses all --prune-exclude rules
+func (args *PruneExcludeFlags) ParsePruneExcludeFromArgs() ([]types.PruneExcludeRule, error) {
+	var ret []types.PruneExcludeRule
+	for _, s := range args.PruneExclude {
+		r := types.PruneExcludeRule{
+			Reason: "excluded via --prune-exclude",
+		}
+		for _, p := range strings.Split(s, ",") {
+			k, v, ok := strings.Cut(p, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid --prune-exclude rule '%s', expected 'key=value' pairs", s)
+			}
+			switch k {
+			case "group":
+				r.Group = &v
+			case "kind":
+				r.Kind = &v
+			case "namespace":
+				r.Namespace = &v
+			default:
+				return nil, fmt.Errorf("invalid --prune-exclude rule '%s', unknown key '%s'", s, k)
+			}
+		}
+		err := yaml.ValidateStructs(&r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --prune-exclude rule '%s': %w", s, err)
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
@@ -37,18 +37,102 @@ type IgnoreFlags struct {
 	IgnoreKluctlMetadata bool `group:"misc" help:"Ignores changes in Kluctl related metadata (e.g. tags, discriminators, ...)"`
 }
 
+type DiffNormalizationFlags struct {
+	NoListNormalization bool `group:"misc" help:"Disable matching of list elements by their merge keys when diffing. Lists are then compared index by index, which causes reorders to show up as changes. Only useful for debugging."`
+}
+
+type ServeResultFlags struct {
+	ServeResult        bool   `group:"misc" help:"After the command has finished, start a temporary local HTTP server that renders the command result as HTML report. The server keeps running until Ctrl-C is pressed."`
+	ServeResultAddress string `group:"misc" help:"The address to bind the result server to when --serve-result is used. Binds to a random port on localhost by default." default:"127.0.0.1:0"`
+}
+
+type GitlabMRReportFlags struct {
+	GitlabComment     bool   `group:"misc" help:"Post the command result as a note to a GitLab merge request. A previously posted note for the same target and discriminator is updated instead of creating a new one. The token is read from the GITLAB_TOKEN environment variable, falling back to CI_JOB_TOKEN. Failures are added as warnings to the command result and do not fail the command."`
+	GitlabURL         string `group:"misc" help:"The GitLab URL used for --gitlab-comment, e.g. for self-managed instances. Defaults to $CI_SERVER_URL or https://gitlab.com."`
+	GitlabProject     string `group:"misc" help:"The GitLab project ID or path used for --gitlab-comment. Defaults to $CI_PROJECT_ID."`
+	GitlabMR          int    `group:"misc" help:"The merge request IID used for --gitlab-comment. Defaults to $CI_MERGE_REQUEST_IID."`
+	GitlabArtifactURL string `group:"misc" help:"The URL linked in the note when the result is too large and had to be truncated, e.g. the URL of a job artifact containing the full result. Defaults to $CI_JOB_URL."`
+}
+
+type GithubPRReportFlags struct {
+	GithubComment bool   `group:"misc" help:"Post the command result as a comment to a GitHub pull request. A previously posted comment for the same target is updated instead of creating a new one. The token is read from the GITHUB_TOKEN environment variable. Failures are added as warnings to the command result and do not fail the command."`
+	GithubRepo    string `group:"misc" help:"The GitHub repository in the form 'owner/repo' used for --github-comment. Defaults to $GITHUB_REPOSITORY."`
+	GithubPR      int    `group:"misc" help:"The pull request number used for --github-comment. Defaults to the pull request found in $GITHUB_REF."`
+	GithubApiURL  string `group:"misc" help:"The GitHub API URL used for --github-comment. Defaults to $GITHUB_API_URL or https://api.github.com."`
+}
+
+type NotifyFlags struct {
+	NotifySlackWebhook string `group:"misc" sensitive:"true" help:"Post a notification about the command result to the given Slack incoming webhook URL. Overrides the Slack notification configured in .kluctl.yaml. The result is always obfuscated before the notification is built. Failures are added as warnings to the command result and do not fail the command."`
+	NotifyWebuiURL     string `group:"misc" help:"The URL of the Kluctl Webui, used to link to the command result in notifications. Overrides the webuiUrl configured in .kluctl.yaml."`
+}
+
+type ShowOrderingFlags struct {
+	ShowOrdering bool `group:"misc" help:"Print the order in which hooks and objects of each deployment item are applied, including where the weights originate from (priority table, kluctl.io/order-weight or Argo CD sync-waves). The output is written to stderr before the command starts."`
+}
+
+type StaleFieldManagerFlags struct {
+	StaleFieldManager []string `group:"misc" help:"Additionally consider the given field manager as stale, e.g. when a custom field manager was used in the past. Fields owned by kluctl related field managers that used server-side apply are always considered stale. Can be specified multiple times."`
+}
+
 type AbortOnErrorFlags struct {
 	AbortOnError bool `group:"misc" help:"Abort deploying when an error occurs instead of trying the remaining deployments"`
 }
 
+type SafetyThresholdFlags struct {
+	OverrideSafetyThreshold bool `group:"misc" help:"Proceed even if the number of changed or deleted objects exceeds the safety threshold configured for the target."`
+}
+
+type DeletedManifestsFlags struct {
+	NoCaptureDeletedManifests bool `group:"misc" help:"Don't capture the last live manifests of deleted/pruned objects in the command result. Captured manifests can be restored via 'kluctl results restore-object'."`
+	MaxDeletedManifestSize    int  `group:"misc" help:"Maximum size in bytes of a single captured manifest of a deleted/pruned object. Larger manifests are not captured and a warning is added to the command result instead." default:"262144"`
+}
+
+type ChangedFilesFlags struct {
+	OnlyChangedFiles bool     `group:"misc" help:"Only render and diff deployment items that are affected by changed files. Changed files are determined via 'git diff --name-only <base>' or passed via --changed-file. The result is marked as partial. Changes to .kluctl.yaml, shared vars files or other files that are not part of a single deployment item cause a full diff."`
+	ChangedFilesBase string   `group:"misc" help:"The git revision to compare against when determining changed files for --only-changed-files." default:"HEAD"`
+	ChangedFile      []string `group:"misc" help:"Explicitly specify a changed file for --only-changed-files. The path must be relative to the git repository root. Can be specified multiple times. If specified, git is not used to determine changed files."`
+}
+
 type OutputFormatFlags struct {
-	OutputFormat []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can either be 'text' or 'yaml'. Can be specified multiple times. The actual format for yaml is currently not documented and subject to change."`
-	NoObfuscate  bool     `group:"misc" help:"Disable obfuscation of sensitive/secret data"`
-	ShortOutput  bool     `group:"misc" help:"When using the 'text' output format (which is the default), only names of changes objects are shown instead of showing all changes."`
+	OutputFormat  []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Prefix the path with '+' (e.g. 'yaml=+results.yaml') to append to the file instead of replacing it, in which case yaml documents are separated with '---'. Format can either be 'text', 'yaml', 'json', 'markdown', 'markdown-collapsible', 'changelog', 'html', 'diff', 'archive', 'gotemplate' or 'gotemplate-string'. The 'markdown' format is suitable for pull request comments, 'markdown-collapsible' additionally wraps long diffs into collapsible blocks. The 'changelog' format prints a short summary of all changes, suitable for release notes. The 'html' format renders a self-contained report with collapsible diffs. The 'diff' format writes a unified diff document with one file per object, suitable for patch viewers and other diff tooling. The 'archive' format writes a portable archive of the obfuscated result to the given file (e.g. 'archive=result.tar.gz'), which can later be inspected via 'kluctl results show --from-file'. The 'gotemplate' format renders the result with a custom Go template (including sprig functions) and is specified as 'gotemplate=templateFile=path', while 'gotemplate-string=template' takes the template inline and always writes to stdout. Can be specified multiple times. The actual format for yaml and json is currently not documented and subject to change."`
+	NoObfuscate   bool     `group:"misc" help:"Disable obfuscation of sensitive/secret data"`
+	ObfuscateMode string   `group:"misc" help:"How sensitive/secret data is obfuscated. 'redact' replaces all values with a constant placeholder. 'hash' replaces values with salted hashes, so that identical values lead to identical tokens and diffs still show whether a value changed. The salt is random per command result and never stored." default:"redact"`
+	ShortOutput   bool     `group:"misc" help:"When using the 'text' output format (which is the default), only names of changes objects are shown instead of showing all changes."`
+
+	ShowTimings bool `group:"misc" help:"When using the 'text' output format, additionally print the durations of the command stages and the objects that took the longest to apply, to run as hook or to become ready."`
+
+	ShowIgnored bool `group:"misc" help:"When using the 'text' output format, additionally print changes that were ignored due to the kluctl.io/ignore-diff-field* annotations or because the object is scaled by a HorizontalPodAutoscaler."`
+
+	ShowNewObjectYaml     bool `group:"misc" help:"When using the 'text' or 'markdown' output formats, additionally print the rendered manifests of new objects. Manifests are obfuscated the same way as diffs. Ignored when --short-output is used."`
+	ShowDeletedObjectYaml bool `group:"misc" help:"When using the 'text' or 'markdown' output formats, additionally print the manifests of deleted and orphan objects as they currently exist in the cluster. Manifests are obfuscated the same way as diffs. Ignored when --short-output is used."`
+	MaxObjectYamlLines    int  `group:"misc" help:"Maximum number of lines printed per manifest when using --show-new-object-yaml or --show-deleted-object-yaml. Set to 0 to disable the limit. --full also disables this limit." default:"200"`
+
+	ObfuscationRulesFile string `group:"misc" help:"Path to a yaml file with a list of additional obfuscation rules, in the same format as the 'obfuscate' field in .kluctl.yaml. The rules are applied together with the rules found in .kluctl.yaml and deployment.yaml files."`
+
+	MaxOutputLines int  `group:"misc" help:"Maximum number of lines printed to stdout when using the 'text' output format. Output written to files and the 'yaml' format are never truncated. The default limit only applies when stdout is a terminal. Set to 0 to disable the limit." default:"10000"`
+	MaxDiffLines   int  `group:"misc" help:"Maximum number of diff lines printed per changed object when using the 'text' output format. The default limit only applies when stdout is a terminal. Set to 0 to disable the limit." default:"1000"`
+	Full           bool `group:"misc" help:"Disable all truncation of the 'text' output."`
+	NoPager        bool `group:"misc" help:"Don't page the 'text' output through $PAGER when stdout is a terminal and the output exceeds one screen."`
+
+	TableWidthFlags
+
+	Color string `group:"misc" help:"Colorize diffs in the 'text' output printed to stdout. Can be 'auto', 'always' or 'never'. 'auto' enables colors when stdout is a terminal, unless --no-color is passed or NO_COLOR is set. Output written to files and all other formats are never colorized." default:"auto"`
+
+	OutputFilterKind        []string `group:"misc" help:"Only print objects of the given kinds, in the format 'Kind' or 'group/Kind'. Can be specified multiple times or as comma separated list. Only affects the printed output, the full result is still written to the result store."`
+	OutputFilterNamespace   []string `group:"misc" help:"Only print objects in the given namespaces. Glob patterns are supported. Can be specified multiple times or as comma separated list."`
+	OutputFilterName        []string `group:"misc" help:"Only print objects with the given names. Glob patterns are supported. Can be specified multiple times or as comma separated list."`
+	OutputFilterLabel       string   `group:"misc" help:"Only print objects whose labels match the given label selector, e.g. 'app=my-app,tier!=db'."`
+	OutputFilterChangedOnly bool     `group:"misc" help:"Only print objects that are new, changed or deleted."`
+
+	ShowEffectiveFlags bool `group:"misc" help:"Print the effective output format flags and where they originate from (command line or project defaults)."`
+}
+
+type TableWidthFlags struct {
+	TableWidth int `group:"misc" help:"Total width of the tables (e.g. diffs and validation results) in the 'text' output printed to stdout. If set to 0, the width of the terminal is used, or 120 if stdout is not a terminal. Set it to a large value to avoid wrapping, e.g. when piping into 'less -S'."`
 }
 
 type OutputFlags struct {
-	Output []string `group:"misc" short:"o" help:"Specify output target file. Can be specified multiple times"`
+	Output []string `group:"misc" short:"o" help:"Specify output target file. Prefix the path with '+' to append to the file instead of replacing it. Can be specified multiple times"`
 }
 
 type RenderOutputDirFlags struct {
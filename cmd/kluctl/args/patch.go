@@ -0,0 +1,96 @@
+package args
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+type ObjectPatchFlags struct {
+	Patch []string `group:"misc" help:"Patch all rendered objects matching a selector, in the form '<selector>:<patch>'. The selector is a comma separated list of 'group=<glob>', 'kind=<glob>', 'namespace=<glob>', 'name=<glob>', 'label.<key>=<value>' and 'tag=<tag>' parts. The patch is either a JSON patch (a list of operations) or a strategic merge patch (an object), in JSON or YAML format. If the patch starts with '@', it is read from the given file. Patches are applied after the patches from .kluctl.yaml. Can be specified multiple times."`
+}
+
+// ParseObjectPatchesFromArgs parses all --patch arguments
+func (args *ObjectPatchFlags) ParseObjectPatchesFromArgs() ([]types.ObjectPatch, error) {
+	var ret []types.ObjectPatch
+	for _, s := range args.Patch {
+		p, err := parseObjectPatch(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --patch '%s': %w", s, err)
+		}
+		ret = append(ret, *p)
+	}
+	return ret, nil
+}
+
+func parseObjectPatch(s string) (*types.ObjectPatch, error) {
+	selectorStr, body, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("expected '<selector>:<patch>'")
+	}
+
+	var p types.ObjectPatch
+	for _, part := range strings.Split(selectorStr, ",") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected 'key=value' pairs in selector")
+		}
+		switch {
+		case k == "group":
+			p.Selector.Group = &v
+		case k == "kind":
+			p.Selector.Kind = &v
+		case k == "namespace":
+			p.Selector.Namespace = &v
+		case k == "name":
+			p.Selector.Name = &v
+		case k == "tag":
+			p.Selector.Tags = append(p.Selector.Tags, v)
+		case strings.HasPrefix(k, "label."):
+			if p.Selector.Labels == nil {
+				p.Selector.Labels = map[string]string{}
+			}
+			p.Selector.Labels[strings.TrimPrefix(k, "label.")] = v
+		default:
+			return nil, fmt.Errorf("unknown selector key '%s'", k)
+		}
+	}
+
+	if strings.HasPrefix(body, "@") {
+		b, err := os.ReadFile(body[1:])
+		if err != nil {
+			return nil, err
+		}
+		body = string(b)
+	}
+
+	var x any
+	err := yaml.ReadYamlString(body, &x)
+	if err != nil {
+		return nil, err
+	}
+	switch v := x.(type) {
+	case map[string]any:
+		p.Patch = uo.FromMap(v)
+	case []any:
+		for _, op := range v {
+			m, ok := op.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("JSON patch operations must be objects")
+			}
+			p.JsonPatch = append(p.JsonPatch, uo.FromMap(m))
+		}
+	default:
+		return nil, fmt.Errorf("patch must either be a list of JSON patch operations or a strategic merge patch object")
+	}
+
+	err = yaml.ValidateStructs(&p)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -1,7 +1,10 @@
 package args
 
 import (
+	"fmt"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
+	"github.com/kluctl/kluctl/v2/pkg/projectlock"
+	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"os"
 	"path/filepath"
@@ -23,43 +26,84 @@ func (a ProjectDir) GetProjectDir() (string, error) {
 	return cwd, nil
 }
 
+type FetchFlags struct {
+	FetchConcurrency int `group:"project" help:"Specify the maximum number of concurrent network fetches (git fetches, Helm Chart pulls and OCI pulls) while loading the project." default:"4"`
+}
+
 type ProjectFlags struct {
 	ProjectDir
 	SourceOverrides
+	FetchFlags
 
 	ProjectConfig ExistingFileType `group:"project" short:"c" help:"Location of the .kluctl.yaml config file. Defaults to $PROJECT/.kluctl.yaml" exts:"yml,yaml"`
 
 	Timeout                time.Duration `group:"project" help:"Specify timeout for all operations, including loading of the project, all external api calls and waiting for readiness." default:"10m"`
 	GitCacheUpdateInterval time.Duration `group:"project" help:"Specify the time to wait between git cache updates. Defaults to not wait at all and always updating caches."`
+
+	Lenient bool `group:"project" help:"Report unknown fields in project config files (e.g. .kluctl.yaml and deployment.yaml) as warnings instead of failing."`
+}
+
+type LockFileFlags struct {
+	LockFile string `group:"project" help:"Location of the lock file. Defaults to $PROJECT/.kluctl-lock.yaml"`
+}
+
+// GetLockFile returns the lock file to use for the given project directory
+func (f *LockFileFlags) GetLockFile(projectDir string) string {
+	if f.LockFile != "" {
+		return f.LockFile
+	}
+	return filepath.Join(projectDir, projectlock.DefaultLockFileName)
+}
+
+type LockFlags struct {
+	LockFileFlags
+
+	Locked bool `group:"project" help:"Fail if any externally resolved input (git and OCI sources, Helm Charts, images and vars sources) differs from the lock file. Use 'kluctl lock write' to create or update the lock file."`
 }
 
 type ArgsFlags struct {
 	Arg          []string `group:"project" short:"a" help:"Passes a template argument in the form of name=value. Nested args can be set with the '-a my.nested.arg=value' syntax. Values are interpreted as yaml values, meaning that 'true' and 'false' will lead to boolean values and numbers will be treated as numbers. Use quotes if you want these to be treated as strings. If the value starts with @, it is treated as a file, meaning that the contents of the file will be loaded and treated as yaml."`
-	ArgsFromFile []string `group:"project" help:"Loads a yaml file and makes it available as arguments, meaning that they will be available thought the global 'args' variable."`
+	ArgsFromFile []string `group:"project" help:"Loads a yaml file and makes it available as arguments, meaning that they will be available thought the global 'args' variable. SOPS encrypted files are decrypted automatically, but are not supported by gitops commands."`
 }
 
-func (a *ArgsFlags) LoadArgs() (*uo.UnstructuredObject, error) {
+// LoadArgs loads all args passed via --arg and --args-from-file. Files are decrypted if they are sops encrypted, in
+// which case the names of the resulting args are returned as sensitive args.
+func (a *ArgsFlags) LoadArgs() (*uo.UnstructuredObject, []string, error) {
 	if a == nil {
-		return uo.New(), nil
+		return uo.New(), nil, nil
 	}
 
+	d := decryptor.NewDecryptor("", decryptor.MaxEncryptedFileSize)
+	// args files are decrypted as a whole, so that the integrity check can be performed
+	d.SetCheckSopsMac(true)
+	d.AddLocalKeyService()
+
 	var args *uo.UnstructuredObject
 	optionArgs, err := kluctl_project.ParseArgs(a.Arg)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	args, err = kluctl_project.ConvertArgsToVars(optionArgs, true)
+	args, sensitiveArgs, err := kluctl_project.ConvertArgsToVars(optionArgs, true, d)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	for _, a := range a.ArgsFromFile {
-		optionArgs2, err := uo.FromFile(a)
+		b, encrypted, err := kluctl_project.LoadArgsFile(a, d)
+		if err != nil {
+			return nil, nil, err
+		}
+		optionArgs2, err := uo.FromString(string(b))
 		if err != nil {
-			return nil, err
+			return nil, nil, fmt.Errorf("failed to load args file %s: %w", a, err)
+		}
+		if encrypted {
+			for k := range optionArgs2.Object {
+				sensitiveArgs = append(sensitiveArgs, k)
+			}
 		}
 		args.Merge(optionArgs2)
 	}
-	return args, nil
+	return args, sensitiveArgs, nil
 }
 
 type TargetFlagsBase struct {
@@ -70,24 +114,47 @@ type TargetFlagsBase struct {
 type TargetFlags struct {
 	TargetFlagsBase
 	Context string `group:"project" help:"Overrides the context name specified in the target. If the selected target does not specify a context or the no-name target is used, --context will override the currently active context."`
+
+	StrictNamespaceOverride bool `group:"project" help:"Fail instead of warning when the namespaceOverride of the target would move objects from multiple namespaces into the override namespace."`
 }
 
 type KubeconfigFlags struct {
-	Kubeconfig ExistingFileType `group:"project" help:"Overrides the kubeconfig to use."`
+	Kubeconfig     ExistingFileType `group:"project" help:"Overrides the kubeconfig to use."`
+	KubeconfigFrom string           `group:"project" sensitive:"true" help:"Overrides the kubeconfigFrom of the target. Must be one of 'file:<path>', 'in-cluster', 'secret:[<context>/]<namespace>/<name>[:<key>]' or 'exec:<command> [<args>...]'. Secrets are read from the cluster of the given context, using the default kubeconfig or the one passed via --kubeconfig."`
 }
 
 type CommandResultReadOnlyFlags struct {
 	CommandResultNamespace string `group:"results" help:"Override the namespace to be used when writing command results." default:"kluctl-results"`
+
+	CommandResultS3Flags
+}
+
+type CommandResultS3Flags struct {
+	CommandResultS3Bucket   string `group:"results" help:"Store command results in the given S3 compatible bucket instead of the cluster. Credentials are taken from the standard AWS environment variables and shared config files."`
+	CommandResultS3Prefix   string `group:"results" help:"The key prefix to use for command results stored in the bucket given via --command-result-s3-bucket."`
+	CommandResultS3Region   string `group:"results" help:"The region of the bucket given via --command-result-s3-bucket."`
+	CommandResultS3Endpoint string `group:"results" help:"Override the S3 endpoint used for --command-result-s3-bucket, e.g. to use MinIO or 'https://storage.googleapis.com' for Google Cloud Storage with HMAC keys."`
 }
 
 type CommandResultWriteFlags struct {
-	WriteCommandResult       bool `group:"results" help:"Enable writing of command results into the cluster. This is enabled by default." default:"true"`
-	ForceWriteCommandResult  bool `group:"results" help:"Force writing of command results, even if the command is run in dry-run mode."`
-	KeepCommandResultsCount  int  `group:"results" help:"Configure how many old command results to keep." default:"5"`
-	KeepValidateResultsCount int  `group:"results" help:"Configure how many old validate results to keep." default:"2"`
+	WriteCommandResult       bool    `group:"results" help:"Enable writing of command results into the cluster. This is enabled by default." default:"true"`
+	ForceWriteCommandResult  bool    `group:"results" help:"Force writing of command results, even if the command is run in dry-run mode."`
+	KeepCommandResultsCount  int     `group:"results" help:"Configure how many old command results to keep." default:"5"`
+	KeepValidateResultsCount int     `group:"results" help:"Configure how many old validate results to keep." default:"2"`
+	KeepCommandResultsMaxAge AgeType `group:"results" help:"Configure the maximum age of old command results to keep, e.g. '30d'. Older results of the same target are removed after a new result is written. Disabled by default."`
+	ResultStoreWrite         string  `group:"results" help:"Controls how failures to write the command result are handled after all retries failed. Can be 'strict' or 'best-effort'. 'strict' fails the command, while 'best-effort' only adds a warning to the command result." default:"strict"`
+
+	SignCommandResultKey ExistingFileType `group:"results" help:"Sign command results with the given private key before writing them to result stores. PEM encoded ECDSA and Ed25519 keys and keys generated via 'cosign generate-key-pair' are supported. Encrypted cosign keys are decrypted with the password from the COSIGN_PASSWORD environment variable. Signed command results are always obfuscated."`
 }
 
 type CommandResultFlags struct {
 	CommandResultReadOnlyFlags
 	CommandResultWriteFlags
 }
+
+type ResultWebhookFlags struct {
+	ResultWebhook        []string      `group:"results" sensitive:"true" help:"POST the compacted and obfuscated result as JSON to the given URL after the command has finished. Can be specified multiple times. Failures are added as warnings to the result and do not fail the command."`
+	ResultWebhookHeader  []string      `group:"results" sensitive:"true" help:"Add the given header to all requests sent to --result-webhook URLs. Must be in the form 'Name: value'. Can be specified multiple times, e.g. via the KLUCTL_RESULT_WEBHOOK_HEADER environment variable."`
+	ResultWebhookTimeout time.Duration `group:"results" help:"Timeout for each attempt to send a result to a --result-webhook URL." default:"10s"`
+	ResultWebhookRetries int           `group:"results" help:"Number of retries when a --result-webhook URL responds with a 5xx status. Retries are performed with exponential backoff." default:"3"`
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/gobwas/glob"
+	"github.com/kluctl/kluctl/lib/credentials"
 	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"os"
@@ -12,10 +13,10 @@ import (
 
 type RegistryCredentials struct {
 	RegistryUsername      []string `group:"registry" skipenv:"true" help:"Specify username to use for OCI authentication. Must be in the form --registry-username=<registry>/<repo>=<username>."`
-	RegistryPassword      []string `group:"registry" skipenv:"true" help:"Specify password to use for OCI authentication. Must be in the form --registry-password=<registry>/<repo>=<password>."`
-	RegistryIdentityToken []string `group:"registry" skipenv:"true" help:"Specify identity token to use for OCI authentication. Must be in the form --registry-identity-token=<registry>/<repo>=<identity-token>."`
-	RegistryToken         []string `group:"registry" skipenv:"true" help:"Specify registry token to use for OCI authentication. Must be in the form --registry-token=<registry>/<repo>=<token>."`
-	RegistryCreds         []string `group:"registry" skipenv:"true" help:"This is a shortcut to --registry-username, --registry-password and --registry-token. It can be specified in two different forms. The first one is --registry-creds=<registry>/<repo>=<username>:<password>, which specifies the username and password for the same registry. The second form is --registry-creds=<registry>/<repo>=<token>, which specifies a JWT token for the specified registry."`
+	RegistryPassword      []string `group:"registry" skipenv:"true" sensitive:"true" help:"Specify password to use for OCI authentication. Must be in the form --registry-password=<registry>/<repo>=<password>."`
+	RegistryIdentityToken []string `group:"registry" skipenv:"true" sensitive:"true" help:"Specify identity token to use for OCI authentication. Must be in the form --registry-identity-token=<registry>/<repo>=<identity-token>."`
+	RegistryToken         []string `group:"registry" skipenv:"true" sensitive:"true" help:"Specify registry token to use for OCI authentication. Must be in the form --registry-token=<registry>/<repo>=<token>."`
+	RegistryCreds         []string `group:"registry" skipenv:"true" sensitive:"true" help:"This is a shortcut to --registry-username, --registry-password and --registry-token. It can be specified in two different forms. The first one is --registry-creds=<registry>/<repo>=<username>:<password>, which specifies the username and password for the same registry. The second form is --registry-creds=<registry>/<repo>=<token>, which specifies a JWT token for the specified registry."`
 
 	RegistryKeyFile  []string `group:"registry" skipenv:"true" help:"Specify key to use for OCI authentication. Must be in the form --registry-key-file=<registry>/<repo>=<filePath>."`
 	RegistryCertFile []string `group:"registry" skipenv:"true" help:"Specify certificate to use for OCI authentication. Must be in the form --registry-cert-file=<registry>/<repo>=<filePath>."`
@@ -23,6 +24,8 @@ type RegistryCredentials struct {
 
 	RegistryPlainHttp             []string `group:"registry" skipenv:"true" help:"Forces the use of http (no TLS). Must be in the form --registry-plain-http=<registry>/<repo>."`
 	RegistryInsecureSkipTlsVerify []string `group:"registry" skipenv:"true" help:"Controls skipping of TLS verification. Must be in the form --registry-insecure-skip-tls-verify=<registry>/<repo>."`
+
+	RegistryCredentialsExec []string `group:"registry" skipenv:"true" help:"Specify an executable that is invoked to retrieve OCI credentials. It is invoked with 'oci <registry> <repo>' as arguments and must print a JSON object with 'username' and 'password', 'identityToken' or 'token' to stdout. Credentials are cached per registry. Can be specified multiple times."`
 }
 
 func (c *RegistryCredentials) BuildAuthProvider(ctx context.Context) (auth_provider.OciAuthProvider, error) {
@@ -156,5 +159,16 @@ func (c *RegistryCredentials) BuildAuthProvider(ctx context.Context) (auth_provi
 		la.AddEntry(*e)
 	}
 
-	return la, nil
+	if len(c.RegistryCredentialsExec) == 0 {
+		return la, nil
+	}
+
+	ret := &auth_provider.OciAuthProviders{}
+	ret.RegisterAuthProvider(la, true)
+	for _, x := range c.RegistryCredentialsExec {
+		ret.RegisterAuthProvider(&auth_provider.OciExecAuthProvider{
+			Exec: credentials.NewExecProvider(x),
+		}, true)
+	}
+	return ret, nil
 }
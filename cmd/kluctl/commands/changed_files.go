@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+)
+
+// restrictToChangedFiles restricts the deployment collection to the deployment items that are affected by the changed
+// files. If any of the changed files might affect all deployment items, the collection is left untouched and a note
+// is recorded instead.
+func restrictToChangedFiles(ctx context.Context, targetCtx *target_context.TargetContext, flags *args.ChangedFilesFlags) error {
+	changedFiles, err := getChangedFiles(ctx, targetCtx.KluctlProject.LoadArgs.RepoRoot, flags)
+	if err != nil {
+		return err
+	}
+
+	c := targetCtx.DeploymentCollection
+	sel := c.SelectByChangedFiles(changedFiles)
+	if sel.Full {
+		c.PartialNote = fmt.Sprintf("performed a full diff, as %s", sel.Reason)
+		status.Infof(ctx, "Performing a full diff, as %s", sel.Reason)
+		return nil
+	}
+
+	c.RestrictToDeploymentItemDirs(sel.DeploymentItemDirs)
+	c.PartialNote = fmt.Sprintf("only %d deployment items affected by %d changed files were rendered and diffed", len(sel.DeploymentItemDirs), len(changedFiles))
+	targetCtx.Params.Inclusion = c.Inclusion
+
+	status.Infof(ctx, "Only diffing %d deployment items affected by %d changed files", len(sel.DeploymentItemDirs), len(changedFiles))
+	for _, d := range sel.DeploymentItemDirs {
+		status.Tracef(ctx, "Affected deployment item: %s", d)
+	}
+	return nil
+}
+
+func getChangedFiles(ctx context.Context, repoRoot string, flags *args.ChangedFilesFlags) ([]string, error) {
+	if len(flags.ChangedFile) != 0 {
+		return flags.ChangedFile, nil
+	}
+
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	cmd := exec.CommandContext(ctx, "git", "diff", "--name-only", flags.ChangedFilesBase, "--")
+	cmd.Dir = repoRoot
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine changed files via 'git diff --name-only %s': %w: %s", flags.ChangedFilesBase, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var ret []string
+	for _, l := range strings.Split(stdout.String(), "\n") {
+		l = strings.TrimSpace(l)
+		if l != "" {
+			ret = append(ret, l)
+		}
+	}
+	return ret, nil
+}
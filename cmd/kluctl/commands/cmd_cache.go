@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/kluctl/kluctl/v2/pkg/localcache"
+)
+
+type cacheCmd struct {
+	Info  cacheInfoCmd  `cmd:"" help:"Show disk usage of the local caches"`
+	Clean cacheCleanCmd `cmd:"" help:"Remove entries from the local caches"`
+}
+
+// parseCacheTypes parses the values passed via --type, defaulting to all cache types
+func parseCacheTypes(l []string) ([]localcache.CacheType, error) {
+	if len(l) == 0 {
+		return localcache.AllCacheTypes, nil
+	}
+	var ret []localcache.CacheType
+	for _, s := range l {
+		t, err := localcache.ParseCacheType(s)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, t)
+	}
+	return ret, nil
+}
+
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
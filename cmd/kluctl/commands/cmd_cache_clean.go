@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/localcache"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type cacheCleanCmd struct {
+	Type      []string      `group:"misc" help:"Only consider the given cache type. Must be one of git, helm, discovery or yaml-bundle. Can be specified multiple times. Defaults to all cache types."`
+	OlderThan time.Duration `group:"misc" help:"Only remove entries that were not used for the given duration, e.g. '168h'."`
+	MaxSize   string        `group:"misc" help:"Remove least recently used entries until the remaining entries fit into the given size, e.g. '10Gi' or '500M'."`
+	DryRun    bool          `group:"misc" help:"Only list the entries that would be removed."`
+}
+
+func (cmd *cacheCleanCmd) Help() string {
+	return `Removes entries from the local caches.
+
+Without --older-than and --max-size, all entries of the selected cache types are removed. When both are specified,
+entries matching either of them are removed. Entries of the git and helm caches are locked before they are removed, which
+means that cleaning waits for concurrently running kluctl processes that currently use these entries.
+`
+}
+
+func (cmd *cacheCleanCmd) Run(ctx context.Context) error {
+	types, err := parseCacheTypes(cmd.Type)
+	if err != nil {
+		return err
+	}
+	opts := localcache.CleanOptions{
+		OlderThan: cmd.OlderThan,
+		Now:       time.Now(),
+	}
+	if cmd.MaxSize != "" {
+		q, err := resource.ParseQuantity(cmd.MaxSize)
+		if err != nil {
+			return fmt.Errorf("invalid --max-size: %w", err)
+		}
+		maxSize := q.Value()
+		opts.MaxSize = &maxSize
+	}
+
+	entries, err := localcache.ListEntries(utils.GetCacheDir(ctx), types)
+	if err != nil {
+		return err
+	}
+	toRemove := localcache.SelectForRemoval(entries, opts)
+
+	var freed int64
+	for _, e := range toRemove {
+		if cmd.DryRun {
+			status.Infof(ctx, "Would remove %s cache entry %s (%s)", e.Type, e.Path, formatByteSize(e.Size))
+			freed += e.Size
+			continue
+		}
+		err = e.Remove()
+		if err != nil {
+			return fmt.Errorf("failed to remove %s cache entry %s: %w", e.Type, e.Path, err)
+		}
+		status.Infof(ctx, "Removed %s cache entry %s (%s)", e.Type, e.Path, formatByteSize(e.Size))
+		freed += e.Size
+	}
+
+	if cmd.DryRun {
+		status.Infof(ctx, "Would remove %d of %d cache entries, freeing %s", len(toRemove), len(entries), formatByteSize(freed))
+	} else {
+		status.Infof(ctx, "Removed %d of %d cache entries, freed %s", len(toRemove), len(entries), formatByteSize(freed))
+	}
+	return nil
+}
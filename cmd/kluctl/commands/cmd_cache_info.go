@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/localcache"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+)
+
+type cacheInfoCmd struct {
+	Type []string `group:"misc" help:"Only consider the given cache type. Must be one of git, helm, discovery or yaml-bundle. Can be specified multiple times. Defaults to all cache types."`
+	args.OutputFlags
+}
+
+func (cmd *cacheInfoCmd) Help() string {
+	return `Shows the disk usage and the number of entries of the local caches.
+
+The following caches are considered:
+- git: mirrored git repositories, used for git based projects, includes and Helm charts
+- helm: pulled Helm charts
+- discovery: the Kubernetes API discovery cache
+- yaml-bundle: YAML bundles fetched for deployment items, cached by digest
+
+The cache dir can be changed via the KLUCTL_CACHE_DIR environment variable.
+The output format can be specified via '-o', e.g. '-o yaml' or '-o json=cache.json'. The default format is 'text'.
+`
+}
+
+func (cmd *cacheInfoCmd) Run(ctx context.Context) error {
+	types, err := parseCacheTypes(cmd.Type)
+	if err != nil {
+		return err
+	}
+	cacheDir := utils.GetCacheDir(ctx)
+	entries, err := localcache.ListEntries(cacheDir, types)
+	if err != nil {
+		return err
+	}
+	info := localcache.BuildInfo(cacheDir, types, entries)
+
+	return outputHelper(ctx, cmd.Output, nil, func(format string, tmpl *template.Template, limits *textOutputLimits) (string, error) {
+		return formatCacheInfo(info, format)
+	})
+}
+
+func formatCacheInfo(info []localcache.CacheInfo, format string) (string, error) {
+	switch format {
+	case "text":
+		var t utils.PrettyTable
+		t.AddRow("TYPE", "ENTRIES", "SIZE", "DIR")
+		var entries int
+		var size int64
+		for _, ci := range info {
+			t.AddRow(string(ci.Type), fmt.Sprint(ci.Entries), formatByteSize(ci.Size), ci.Dir)
+			entries += ci.Entries
+			size += ci.Size
+		}
+		t.AddRow("total", fmt.Sprint(entries), formatByteSize(size), "")
+		return t.Render(nil), nil
+	case "yaml":
+		return yaml.WriteYamlString(info)
+	case "json":
+		return formatJson(info)
+	default:
+		return "", fmt.Errorf("invalid format: %s", format)
+	}
+}
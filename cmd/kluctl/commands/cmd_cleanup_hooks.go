@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"time"
+)
+
+type cleanupHooksCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.YesFlags
+	args.DryRunFlags
+	args.OutputFormatFlags
+	args.RenderOutputDirFlags
+	args.CommandResultFlags
+
+	Discriminator string        `group:"misc" help:"Override the target discriminator."`
+	OlderThan     time.Duration `group:"misc" help:"Only delete hooks of runs that were started before the given duration. Hooks of more recent runs are not touched, as these runs might still be active." default:"1h"`
+}
+
+func (cmd *cleanupHooksCmd) Help() string {
+	return `Hooks are labeled with the id of the deployment run that applied them. If a deployment is interrupted while
+executing hooks, hooks that should have been deleted after execution (via the 'hook-succeeded' or 'hook-failed'
+delete policies) are left behind. These are neither detected as orphans by 'kluctl prune' nor reliably cleaned
+up by the next deployment. The same applies to objects created by hooks and declared via 'kluctl.io/hook-creates'.
+
+This command searches the target cluster for such hooks and objects and deletes them. Only hooks of runs that were started
+longer ago than specified via --older-than are deleted.`
+}
+
+func (cmd *cleanupHooksCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "cleanup-hooks"); err != nil {
+		return err
+	}
+
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		gitCredentials:       cmd.GitCredentials,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		dryRunArgs:           &cmd.DryRunFlags,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		commandResultFlags:   &cmd.CommandResultFlags,
+		discriminator:        cmd.Discriminator,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		return cmd.runCmdCleanupHooks(ctx, cmdCtx)
+	})
+}
+
+func (cmd *cleanupHooksCmd) runCmdCleanupHooks(ctx context.Context, cmdCtx *commandCtx) error {
+	cmd.OutputFormatFlags = resolveOutputFormatFlags(ctx, cmdCtx, cmd.OutputFormatFlags)
+
+	cmd2 := commands.NewCleanupHooksCommand(cmdCtx.targetCtx.Target.Discriminator, cmdCtx.targetCtx, true)
+	cmd2.OlderThan = cmd.OlderThan
+	result := cmd2.Run(func(refs []k8s2.ObjectRef) error {
+		return confirmDeletion(ctx, refs, cmd.DryRun, cmd.Yes)
+	})
+	err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) != 0 {
+		return fmt.Errorf("command failed")
+	}
+	return nil
+}
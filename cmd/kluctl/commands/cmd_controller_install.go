@@ -24,6 +24,10 @@ func (cmd *controllerInstallCmd) Help() string {
 }
 
 func (cmd *controllerInstallCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "controller install"); err != nil {
+		return err
+	}
+
 	src, err := embed_util.NewEmbeddedFiles(controller.Project, "kluctl-controller-deployment")
 	if err != nil {
 		return err
@@ -8,6 +8,7 @@ import (
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/controllers"
 	"github.com/kluctl/kluctl/v2/pkg/sourceoverride"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/metrics"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -28,6 +29,7 @@ import (
 	crtlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"testing"
+	"time"
 )
 
 var (
@@ -54,6 +56,9 @@ type controllerRunCmd struct {
 	DefaultServiceAccount string `group:"misc" help:"Default service account used for impersonation."`
 	DryRun                bool   `group:"misc" help:"Run all deployments in dryRun=true mode."`
 
+	SourceCacheMaxSize int           `group:"misc" help:"Maximum size in megabytes of the source cache that is shared between all KluctlDeployments. Set to 0 to disable the shared source cache." default:"1024"`
+	SourceCacheMaxAge  time.Duration `group:"misc" help:"Maximum age of shared source cache entries. Sources older than this are fetched again. A value of 0 still merges concurrent fetches of the same source." default:"1m"`
+
 	args.CommandResultFlags
 }
 
@@ -187,6 +192,10 @@ func (cmd *controllerRunCmd) Run(ctx context.Context) error {
 		SshPool:               sshPool,
 	}
 
+	if cmd.SourceCacheMaxSize > 0 {
+		r.SourceCache = controllers.NewSourceCache(filepath.Join(utils.GetTmpBaseDir(ctx), "source-cache"), int64(cmd.SourceCacheMaxSize)*1024*1024, cmd.SourceCacheMaxAge)
+	}
+
 	r.ResultStore, err = buildResultStoreRW(ctx, restConfig, mgr.GetRESTMapper(), &cmd.CommandResultFlags, true)
 	if err != nil {
 		return err
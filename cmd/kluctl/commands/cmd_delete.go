@@ -21,9 +21,12 @@ type deleteCmd struct {
 	args.RegistryCredentials
 	args.YesFlags
 	args.DryRunFlags
+	args.DeletedManifestsFlags
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
 	args.CommandResultFlags
+	args.NotifyFlags
+	args.ResultWebhookFlags
 
 	Discriminator string `group:"misc" help:"Override the discriminator used to find objects for deletion."`
 
@@ -39,6 +42,10 @@ take the local target/state into account!`
 }
 
 func (cmd *deleteCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "delete"); err != nil {
+		return err
+	}
+
 	ptArgs := projectTargetCommandArgs{
 		projectFlags:         cmd.ProjectFlags,
 		kubeconfigFlags:      cmd.KubeconfigFlags,
@@ -52,9 +59,14 @@ func (cmd *deleteCmd) Run(ctx context.Context) error {
 		dryRunArgs:           &cmd.DryRunFlags,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		commandResultFlags:   &cmd.CommandResultFlags,
+		notifyFlags:          &cmd.NotifyFlags,
+		resultWebhookFlags:   &cmd.ResultWebhookFlags,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		cmd.OutputFormatFlags = resolveOutputFormatFlags(ctx, cmdCtx, cmd.OutputFormatFlags)
+
 		cmd2 := commands.NewDeleteCommand(cmd.Discriminator, cmdCtx.targetCtx, nil, !cmd.NoWait)
+		cmd2.DeletedManifests = buildDeletedManifestsOptions(cmd.DeletedManifestsFlags)
 
 		result := cmd2.Run(cmdCtx.targetCtx.SharedContext.Ctx, cmdCtx.targetCtx.SharedContext.K, func(refs []k8s2.ObjectRef) error {
 			return confirmDeletion(ctx, refs, cmd.DryRun, cmd.Yes)
@@ -6,7 +6,9 @@ import (
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/events"
 	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"github.com/kluctl/kluctl/v2/pkg/results/htmlreport"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 )
 
@@ -17,6 +19,7 @@ type deployCmd struct {
 	args.ArgsFlags
 	args.ImageFlags
 	args.InclusionFlags
+	args.PruneExcludeFlags
 	args.GitCredentials
 	args.HelmCredentials
 	args.RegistryCredentials
@@ -24,17 +27,40 @@ type deployCmd struct {
 	args.DryRunFlags
 	args.ForceApplyFlags
 	args.ReplaceOnErrorFlags
+	args.DiffNormalizationFlags
 	args.AbortOnErrorFlags
+	args.SafetyThresholdFlags
+	args.DeletedManifestsFlags
 	args.HookFlags
+	args.ShowOrderingFlags
 	args.OutputFormatFlags
+	args.ServeResultFlags
+	args.GitlabMRReportFlags
+	args.GithubPRReportFlags
 	args.RenderOutputDirFlags
+	args.ObjectPatchFlags
 	args.CommandResultFlags
+	args.NotifyFlags
+	args.ResultWebhookFlags
+	args.LockFlags
+	args.StaleFieldManagerFlags
 
 	DeployExtraFlags
 
 	Discriminator string `group:"misc" help:"Override the target discriminator."`
+	NoProbes      bool   `group:"misc" help:"Don't execute HTTP probes declared via the kluctl.io/validate-probe-url annotation while waiting for readiness."`
+	Force         bool   `group:"misc" help:"Deploy even if live objects carrying the discriminator appear to originate from a different project or target."`
+	StreamEvents  string `group:"misc" help:"Stream events (objectApplied, objectChanged, hookStarted, hookFinished, error, warning and pruneDeleted) as JSON lines to the given file while deploying. Use '-' to stream to stdout, in which case you should write the command result to a file via -o. The last line always contains the summary of the command result."`
+
+	FailOnOwnershipConflict bool `group:"misc" help:"Fail before applying anything if objects are already managed by a different target, as detected by a different discriminator on the live objects. Objects with the kluctl.io/ownership-takeover annotation are excluded. Useful for CI."`
+
+	TakeOwnershipFrom []string `group:"misc" help:"Take over field ownership from the given field managers before applying objects, e.g. 'kubectl-client-side-apply' to migrate objects that were previously applied with 'kubectl apply'. This also removes the kubectl.kubernetes.io/last-applied-configuration annotation. Can be specified multiple times."`
 
 	internal bool
+
+	// namespaceOverride and beforeDeploy are used by 'preview create'
+	namespaceOverride string
+	beforeDeploy      func(ctx context.Context, cmdCtx *commandCtx) error
 }
 
 type DeployExtraFlags struct {
@@ -50,6 +76,10 @@ It will also output a list of prunable objects (without actually deleting them).
 }
 
 func (cmd *deployCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "deploy"); err != nil {
+		return err
+	}
+
 	ptArgs := projectTargetCommandArgs{
 		projectFlags:         cmd.ProjectFlags,
 		kubeconfigFlags:      cmd.KubeconfigFlags,
@@ -57,71 +87,136 @@ func (cmd *deployCmd) Run(ctx context.Context) error {
 		argsFlags:            cmd.ArgsFlags,
 		imageFlags:           cmd.ImageFlags,
 		inclusionFlags:       cmd.InclusionFlags,
+		pruneExcludeFlags:    cmd.PruneExcludeFlags,
 		gitCredentials:       cmd.GitCredentials,
 		helmCredentials:      cmd.HelmCredentials,
 		registryCredentials:  cmd.RegistryCredentials,
 		dryRunArgs:           &cmd.DryRunFlags,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		objectPatchFlags:     cmd.ObjectPatchFlags,
 		commandResultFlags:   &cmd.CommandResultFlags,
+		notifyFlags:          &cmd.NotifyFlags,
+		resultWebhookFlags:   &cmd.ResultWebhookFlags,
+		lockFlags:            &cmd.LockFlags,
 		internalDeploy:       cmd.internal,
 		discriminator:        cmd.Discriminator,
+		namespaceOverride:    cmd.namespaceOverride,
 	}
-	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+
+	ctx, es, err := withEventStream(ctx, cmd.StreamEvents)
+	if err != nil {
+		return err
+	}
+	err = withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		return cmd.runCmdDeploy(ctx, cmdCtx)
 	})
+	if err2 := es.close(); err == nil {
+		err = err2
+	}
+	return err
 }
 
 func (cmd *deployCmd) runCmdDeploy(ctx context.Context, cmdCtx *commandCtx) error {
 	status.Trace(ctx, "enter runCmdDeploy")
 	defer status.Trace(ctx, "leave runCmdDeploy")
 
+	cmd.OutputFormatFlags = resolveOutputFormatFlags(ctx, cmdCtx, cmd.OutputFormatFlags)
+
+	if cmd.beforeDeploy != nil {
+		err := cmd.beforeDeploy(ctx, cmdCtx)
+		if err != nil {
+			return err
+		}
+	}
+
 	cmd2 := commands.NewDeployCommand(cmdCtx.targetCtx)
 	cmd2.ForceApply = cmd.ForceApply
 	cmd2.ReplaceOnError = cmd.ReplaceOnError
 	cmd2.ForceReplaceOnError = cmd.ForceReplaceOnError
+	cmd2.NoListNormalization = cmd.NoListNormalization
 	cmd2.AbortOnError = cmd.AbortOnError
 	cmd2.ReadinessTimeout = cmd.ReadinessTimeout
 	cmd2.NoWait = cmd.NoWait
+	cmd2.NoProbes = cmd.NoProbes
+	cmd2.TakeOwnershipFrom = cmd.TakeOwnershipFrom
 	cmd2.Prune = cmd.Prune
 	cmd2.WaitPrune = !cmd.NoWait
+	cmd2.ResultStore = cmdCtx.resultStore
+	cmd2.Force = cmd.Force
+	cmd2.FailOnOwnershipConflict = cmd.FailOnOwnershipConflict
+	cmd2.OverrideSafetyThreshold = cmd.OverrideSafetyThreshold
+	cmd2.DeletedManifests = buildDeletedManifestsOptions(cmd.DeletedManifestsFlags)
+	cmd2.StaleFieldManagers = cmd.StaleFieldManager
 
+	if cmd.ShowOrdering {
+		showOrdering(ctx, cmdCtx)
+	}
+	if cmd.ShowSelection {
+		showSelection(ctx, cmdCtx)
+	}
+
+	var confirmation *result.ConfirmationInfo
 	cb := func(diffResult *result.CommandResult) error {
-		return cmd.diffResultCb(ctx, cmdCtx, diffResult)
+		var err error
+		confirmation, err = cmd.diffResultCb(ctx, cmdCtx, diffResult)
+		return err
 	}
-	if cmd.Yes || cmd.DryRun {
+	if cmd.DryRun || (cmd.Yes && cmdCtx.targetCtx.Target.Confirmation == nil) {
+		// with --yes, the diff is only needed to apply the confirmation policy
 		cb = nil
 	}
 
+	// dry-run deployments are written to the result store as well, as these are used as rehearsals. Results are
+	// flagged via command.dryRun so that they can be distinguished from real deployments.
 	result := cmd2.Run(cb)
-	err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
+	result.Confirmation = confirmation
+	obfuscator, err := newObfuscator(cmdCtx, cmd.OutputFormatFlags)
+	if err != nil {
+		return err
+	}
+	reportGitlabMR(ctx, cmd.GitlabMRReportFlags, obfuscator, result)
+	reportGithubPR(ctx, cmd.GithubPRReportFlags, obfuscator, result)
+	err = outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, true)
+	events.Emit(ctx, events.Event{Type: events.Summary, Summary: result.BuildSummary()})
 	if err != nil {
 		return err
 	}
+	if cmd.ServeResult {
+		err = htmlreport.Serve(ctx, cmd.ServeResultAddress, result)
+		if err != nil {
+			return err
+		}
+	}
 	if len(result.Errors) != 0 {
 		return fmt.Errorf("command failed")
 	}
 	return nil
 }
 
-func (cmd *deployCmd) diffResultCb(ctx context.Context, cmdCtx *commandCtx, diffResult *result.CommandResult) error {
+func (cmd *deployCmd) diffResultCb(ctx context.Context, cmdCtx *commandCtx, diffResult *result.CommandResult) (*result.ConfirmationInfo, error) {
+	if cmd.Yes {
+		return applyConfirmationPolicy(ctx, cmdCtx, diffResult, false)
+	}
+
 	flags := cmd.OutputFormatFlags
 	flags.OutputFormat = nil // use default output format
 
 	err := outputCommandResult(ctx, cmdCtx, flags, diffResult, false)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if cmd.Yes || cmd.DryRun {
-		return nil
+	confirmation, err := applyConfirmationPolicy(ctx, cmdCtx, diffResult, true)
+	if err != nil {
+		return confirmation, err
 	}
 	if len(diffResult.Errors) != 0 {
 		if !prompts.AskForConfirmation(ctx, "The diff resulted in errors, do you still want to proceed?") {
-			return fmt.Errorf("aborted")
+			return confirmation, fmt.Errorf("aborted")
 		}
 	} else {
 		if !prompts.AskForConfirmation(ctx, "The diff succeeded, do you want to proceed?") {
-			return fmt.Errorf("aborted")
+			return confirmation, fmt.Errorf("aborted")
 		}
 	}
-	return nil
+	return confirmation, nil
 }
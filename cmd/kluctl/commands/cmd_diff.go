@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/results/htmlreport"
 )
 
 type diffCmd struct {
@@ -13,6 +14,7 @@ type diffCmd struct {
 	args.TargetFlags
 	args.ArgsFlags
 	args.InclusionFlags
+	args.PruneExcludeFlags
 	args.ImageFlags
 	args.GitCredentials
 	args.HelmCredentials
@@ -20,8 +22,17 @@ type diffCmd struct {
 	args.ForceApplyFlags
 	args.ReplaceOnErrorFlags
 	args.IgnoreFlags
+	args.DiffNormalizationFlags
+	args.ShowOrderingFlags
 	args.OutputFormatFlags
+	args.ServeResultFlags
+	args.GitlabMRReportFlags
+	args.GithubPRReportFlags
 	args.RenderOutputDirFlags
+	args.ObjectPatchFlags
+	args.LockFlags
+	args.ChangedFilesFlags
+	args.StaleFieldManagerFlags
 
 	Discriminator string `group:"misc" help:"Override the target discriminator."`
 }
@@ -41,13 +52,19 @@ func (cmd *diffCmd) Run(ctx context.Context) error {
 		argsFlags:            cmd.ArgsFlags,
 		imageFlags:           cmd.ImageFlags,
 		inclusionFlags:       cmd.InclusionFlags,
+		pruneExcludeFlags:    cmd.PruneExcludeFlags,
 		gitCredentials:       cmd.GitCredentials,
 		helmCredentials:      cmd.HelmCredentials,
 		registryCredentials:  cmd.RegistryCredentials,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		objectPatchFlags:     cmd.ObjectPatchFlags,
+		lockFlags:            &cmd.LockFlags,
+		changedFilesFlags:    &cmd.ChangedFilesFlags,
 		discriminator:        cmd.Discriminator,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		cmd.OutputFormatFlags = resolveOutputFormatFlags(ctx, cmdCtx, cmd.OutputFormatFlags)
+
 		cmd2 := commands.NewDiffCommand(cmdCtx.targetCtx)
 		cmd2.ForceApply = cmd.ForceApply
 		cmd2.ReplaceOnError = cmd.ReplaceOnError
@@ -56,11 +73,31 @@ func (cmd *diffCmd) Run(ctx context.Context) error {
 		cmd2.IgnoreLabels = cmd.IgnoreLabels
 		cmd2.IgnoreAnnotations = cmd.IgnoreAnnotations
 		cmd2.IgnoreKluctlMetadata = cmd.IgnoreKluctlMetadata
+		cmd2.NoListNormalization = cmd.NoListNormalization
+		cmd2.StaleFieldManagers = cmd.StaleFieldManager
+		if cmd.ShowOrdering {
+			showOrdering(ctx, cmdCtx)
+		}
+		if cmd.ShowSelection {
+			showSelection(ctx, cmdCtx)
+		}
 		result := cmd2.Run()
-		err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, false)
+		obfuscator, err := newObfuscator(cmdCtx, cmd.OutputFormatFlags)
 		if err != nil {
 			return err
 		}
+		reportGitlabMR(ctx, cmd.GitlabMRReportFlags, obfuscator, result)
+		reportGithubPR(ctx, cmd.GithubPRReportFlags, obfuscator, result)
+		err = outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, false)
+		if err != nil {
+			return err
+		}
+		if cmd.ServeResult {
+			err = htmlreport.Serve(ctx, cmd.ServeResultAddress, result)
+			if err != nil {
+				return err
+			}
+		}
 		if len(result.Errors) != 0 {
 			return fmt.Errorf("command failed")
 		}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"strings"
+)
+
+type fixOwnershipCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.ImageFlags
+	args.InclusionFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.YesFlags
+	args.DryRunFlags
+	args.StaleFieldManagerFlags
+	args.OutputFormatFlags
+	args.RenderOutputDirFlags
+	args.CommandResultFlags
+
+	Discriminator string `group:"misc" help:"Override the target discriminator."`
+}
+
+func (cmd *fixOwnershipCmd) Help() string {
+	return `Server-side apply only prunes fields that are owned by the field manager that performs the apply. Fields
+that are owned by field managers of previous kluctl versions or by renamed field managers are therefore never
+removed from live objects, even if they are removed from the rendered objects. 'kluctl diff' and 'kluctl deploy'
+warn about such objects.
+
+This command searches the target cluster for objects with fields owned by stale field managers. Ownership of fields
+that are still rendered is migrated to the current field manager, while fields that are not rendered anymore and
+not owned by any other field manager are removed. A full report is printed before anything is changed.`
+}
+
+func (cmd *fixOwnershipCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "fix-ownership"); err != nil {
+		return err
+	}
+
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		imageFlags:           cmd.ImageFlags,
+		inclusionFlags:       cmd.InclusionFlags,
+		gitCredentials:       cmd.GitCredentials,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		dryRunArgs:           &cmd.DryRunFlags,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		commandResultFlags:   &cmd.CommandResultFlags,
+		discriminator:        cmd.Discriminator,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		return cmd.runCmdFixOwnership(ctx, cmdCtx)
+	})
+}
+
+func (cmd *fixOwnershipCmd) runCmdFixOwnership(ctx context.Context, cmdCtx *commandCtx) error {
+	cmd.OutputFormatFlags = resolveOutputFormatFlags(ctx, cmdCtx, cmd.OutputFormatFlags)
+
+	cmd2 := commands.NewFixOwnershipCommand(cmdCtx.targetCtx, cmd.DryRun)
+	cmd2.StaleFieldManagers = cmd.StaleFieldManager
+	result := cmd2.Run(func(findings []commands.StaleOwnership) error {
+		return confirmFixOwnership(ctx, findings, cmd.DryRun, cmd.Yes)
+	})
+	err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) != 0 {
+		return fmt.Errorf("command failed")
+	}
+	return nil
+}
+
+func confirmFixOwnership(ctx context.Context, findings []commands.StaleOwnership, dryRun bool, forceYes bool) error {
+	if len(findings) == 0 {
+		_, _ = getStderr(ctx).WriteString("No fields owned by stale field managers found\n")
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("The following objects have fields owned by stale field managers:\n")
+	for _, f := range findings {
+		sb.WriteString(fmt.Sprintf("  %s (%s)\n", f.Ref.String(), strings.Join(f.Managers, ", ")))
+		for _, p := range f.MigratedFields {
+			sb.WriteString(fmt.Sprintf("    migrate: %s\n", p))
+		}
+		for _, p := range f.RemovedFields {
+			sb.WriteString(fmt.Sprintf("    remove:  %s\n", p))
+		}
+	}
+	_, _ = getStderr(ctx).WriteString(sb.String())
+
+	if !forceYes && !dryRun {
+		if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you really want to fix ownership of %d objects?", len(findings))) {
+			return fmt.Errorf("aborted")
+		}
+	}
+	return nil
+}
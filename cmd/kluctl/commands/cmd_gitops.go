@@ -16,6 +16,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/prompts"
 	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/sourceoverride"
+	"github.com/kluctl/kluctl/v2/pkg/stats"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	flag "github.com/spf13/pflag"
@@ -34,6 +35,7 @@ import (
 	"net/url"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -46,6 +48,7 @@ type gitopsCmd struct {
 	Deploy    gitopsDeployCmd    `cmd:"" help:"Trigger a GitOps deployment"`
 	Prune     gitopsPruneCmd     `cmd:"" help:"Trigger a GitOps prune"`
 	Validate  gitopsValidateCmd  `cmd:"" help:"Trigger a GitOps validate"`
+	Cancel    gitopsCancelCmd    `cmd:"" help:"Cancel an in-progress GitOps reconciliation"`
 	Logs      gitopsLogsCmd      `cmd:"" help:"Show logs from controller"`
 }
 
@@ -116,6 +119,10 @@ func (g *gitopsCmdHelper) init(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if isReadOnly(ctx) {
+		restConfig = k8s.WrapReadOnly(restConfig)
+	}
+	restConfig = stats.WrapRestConfig(ctx, restConfig)
 	defaultNs, _, err := clientConfig.Namespace()
 	if err != nil {
 		return err
@@ -547,10 +554,15 @@ func (g *gitopsCmdHelper) buildOverridePatch(ctx context.Context, kdIn *v1beta1.
 }
 
 func (g *gitopsCmdHelper) overrideDeploymentArgs(kd *v1beta1.KluctlDeployment) error {
-	overrideArgs, err := g.overridableArgs.ArgsFlags.LoadArgs()
+	overrideArgs, sensitiveArgs, err := g.overridableArgs.ArgsFlags.LoadArgs()
 	if err != nil {
 		return err
 	}
+	// overridden args are passed to the controller as part of the spec and end up in the stored command results, which
+	// must never contain decrypted values
+	if len(sensitiveArgs) != 0 {
+		return fmt.Errorf("sops encrypted args (%s) can't be passed to gitops commands, as the decrypted values would be stored in the command result. Pass encrypted args via the KluctlDeployment instead", strings.Join(sensitiveArgs, ", "))
+	}
 	if overrideArgs.IsZero() {
 		return nil
 	}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"os/user"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+type gitopsCancelCmd struct {
+	args.GitOpsArgs
+
+	NoWait bool `group:"misc" help:"Don't wait for the controller to handle the cancel request."`
+}
+
+func (cmd *gitopsCancelCmd) Help() string {
+	return `This command will cancel the reconciliation that is currently in progress for an existing KluctlDeployment.
+It does this by setting the annotation 'kluctl.io/request-cancel', which is observed by the controller while
+reconciling. The controller then cancels the running command gracefully, which results in an aborted command result,
+and requeues the KluctlDeployment.
+
+The command waits for the cancelled reconciliation to finish. If the reconciliation is in a phase that can not be
+cancelled and does not finish in time, this is reported by the controller and the command fails.`
+}
+
+func (cmd *gitopsCancelCmd) Run(ctx context.Context) error {
+	g := gitopsCmdHelper{
+		args:        cmd.GitOpsArgs,
+		noArgsReact: noArgsAutoDetectProjectAsk,
+	}
+	err := g.init(ctx)
+	if err != nil {
+		return err
+	}
+
+	requestedBy := g.whoAmI(ctx)
+
+	for _, kd := range g.kds {
+		key := client.ObjectKeyFromObject(&kd)
+		cr := v1beta1.CancelRequest{
+			RequestValue: time.Now().Format(time.RFC3339Nano),
+			RequestedBy:  requestedBy,
+		}
+		crJson, err := yaml.WriteJsonString(&cr)
+		if err != nil {
+			return err
+		}
+
+		_, err = g.patchDeployment(ctx, key, func(kd *v1beta1.KluctlDeployment) error {
+			a := kd.GetAnnotations()
+			if a == nil {
+				a = map[string]string{}
+			}
+			a[v1beta1.KluctlRequestCancelAnnotation] = crJson
+			kd.SetAnnotations(a)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if cmd.NoWait {
+			continue
+		}
+
+		rr, err := g.waitForCancelRequest(ctx, key, "Waiting for controller to handle the cancel request", func(rr *v1beta1.CancelRequestResult) bool {
+			return rr.Request.RequestValue == cr.RequestValue
+		})
+		if err != nil {
+			return err
+		}
+		if rr.ReconcileId == "" {
+			status.Infof(ctx, "No reconciliation was in progress for %s/%s", key.Namespace, key.Name)
+			continue
+		}
+		status.Infof(ctx, "Cancelling reconciliation %s of %s/%s in phase '%s'", rr.ReconcileId, key.Namespace, key.Name, rr.Phase)
+
+		rr, err = g.waitForCancelRequest(ctx, key, "Waiting for the cancelled reconciliation to finish", func(rr *v1beta1.CancelRequestResult) bool {
+			return rr.Request.RequestValue != cr.RequestValue || rr.EndTime != nil || rr.Stuck
+		})
+		if err != nil {
+			return err
+		}
+		if rr.Request.RequestValue != cr.RequestValue {
+			return fmt.Errorf("cancel request for %s/%s got superseded by another cancel request", key.Namespace, key.Name)
+		}
+		if rr.Stuck && rr.EndTime == nil {
+			return fmt.Errorf("%s/%s: %s", key.Namespace, key.Name, rr.Message)
+		}
+		status.Infof(ctx, "%s/%s: %s", key.Namespace, key.Name, rr.Message)
+	}
+	return nil
+}
+
+func (g *gitopsCmdHelper) waitForCancelRequest(ctx context.Context, key client.ObjectKey, msg string, cond func(rr *v1beta1.CancelRequestResult) bool) (*v1beta1.CancelRequestResult, error) {
+	s := status.Start(ctx, msg)
+	defer s.Failed()
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-tick.C:
+			var kd v1beta1.KluctlDeployment
+			err := g.client.Get(ctx, key, &kd)
+			if err != nil {
+				return nil, err
+			}
+			rr := kd.Status.CancelRequestResult
+			if rr != nil && cond(rr) {
+				s.Success()
+				return rr, nil
+			}
+		}
+	}
+}
+
+// whoAmI returns the name of the user as seen by the cluster, falling back to the local user name
+func (g *gitopsCmdHelper) whoAmI(ctx context.Context) string {
+	ssr := &authenticationv1.SelfSubjectReview{}
+	err := g.client.Create(ctx, ssr)
+	if err == nil && ssr.Status.UserInfo.Username != "" {
+		return ssr.Status.UserInfo.Username
+	}
+	status.Tracef(ctx, "Failed to determine user via SelfSubjectReview: %v", err)
+
+	u, err := user.Current()
+	if err != nil {
+		return "unknown"
+	}
+	return u.Username
+}
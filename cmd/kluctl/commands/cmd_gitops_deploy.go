@@ -56,7 +56,7 @@ func (cmd *gitopsDeployCmd) Run(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
-			err = outputCommandResult2(ctx, cmd.OutputFormatFlags, cmdResult)
+			err = outputCommandResult2(ctx, cmd.OutputFormatFlags, cmdResult, getChangelogRules(nil, cmdResult))
 			if err != nil {
 				return err
 			}
@@ -55,7 +55,7 @@ func (cmd *gitopsDiffCmd) Run(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
-			err = outputCommandResult2(ctx, cmd.OutputFormatFlags, cmdResult)
+			err = outputCommandResult2(ctx, cmd.OutputFormatFlags, cmdResult, getChangelogRules(nil, cmdResult))
 			if err != nil {
 				return err
 			}
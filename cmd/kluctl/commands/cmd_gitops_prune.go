@@ -54,7 +54,7 @@ func (cmd *gitopsPruneCmd) Run(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
-			err = outputCommandResult2(ctx, cmd.OutputFormatFlags, cmdResult)
+			err = outputCommandResult2(ctx, cmd.OutputFormatFlags, cmdResult, getChangelogRules(nil, cmdResult))
 			if err != nil {
 				return err
 			}
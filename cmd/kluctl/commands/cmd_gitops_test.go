@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getsops/sops/v3/age"
+	"github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/vars/sops_test_resources"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverrideDeploymentArgsRejectsEncryptedArgs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	key, err := sops_test_resources.TestResources.ReadFile("test-key.txt")
+	assert.NoError(t, err)
+	t.Setenv(age.SopsAgeKeyEnv, string(key))
+
+	dir := t.TempDir()
+	encrypted, err := sops_test_resources.TestResources.ReadFile("test.yaml")
+	assert.NoError(t, err)
+	encryptedFile := filepath.Join(dir, "encrypted.yaml")
+	assert.NoError(t, os.WriteFile(encryptedFile, encrypted, 0o600))
+	plainFile := filepath.Join(dir, "plain.yaml")
+	assert.NoError(t, os.WriteFile(plainFile, []byte("a: b\n"), 0o600))
+
+	g := &gitopsCmdHelper{}
+	g.overridableArgs.ArgsFlags = args.ArgsFlags{ArgsFromFile: []string{plainFile}}
+	kd := &v1beta1.KluctlDeployment{}
+	assert.NoError(t, g.overrideDeploymentArgs(kd))
+	assert.JSONEq(t, `{"a":"b"}`, string(kd.Spec.Args.Raw))
+
+	for _, a := range []args.ArgsFlags{
+		{ArgsFromFile: []string{encryptedFile}},
+		{Arg: []string{"secret=@" + encryptedFile}},
+	} {
+		g.overridableArgs.ArgsFlags = a
+		kd = &v1beta1.KluctlDeployment{}
+		err = g.overrideDeploymentArgs(kd)
+		assert.ErrorContains(t, err, "can't be passed to gitops commands")
+		assert.Nil(t, kd.Spec.Args)
+	}
+}
@@ -16,6 +16,7 @@ type gitopsValidateCmd struct {
 	args.GitOpsLogArgs
 	args.GitOpsOverridableArgs
 	args.OutputFlags
+	args.TableWidthFlags
 
 	WarningsAsErrors bool `group:"misc" help:"Consider warnings as failures"`
 }
@@ -56,7 +57,7 @@ func (cmd *gitopsValidateCmd) Run(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
-			err = outputValidateResult2(ctx, cmd.Output, cmdResult)
+			err = outputValidateResult2(ctx, cmd.Output, cmd.TableWidthFlags, cmdResult)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+)
+
+type helmAdoptCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.YesFlags
+	args.DryRunFlags
+	args.RenderOutputDirFlags
+
+	Namespace             string `group:"misc" short:"n" help:"The namespace of the Helm release." required:"true"`
+	Into                  string `group:"misc" help:"The deployment item directory to adopt the release into, relative to the project directory. The deployment item must already be referenced from a deployment.yaml." required:"true"`
+	Repo                  string `group:"misc" help:"The Helm repository (or OCI url) of the chart. Required, as Helm does not record the repository in the release." required:"true"`
+	DeleteReleaseMetadata bool   `group:"misc" help:"Delete the Helm release metadata (the Helm storage Secrets) after adoption, so that Helm does not claim ownership anymore."`
+
+	release string
+}
+
+func (cmd *helmAdoptCmd) Help() string {
+	return `Reads the manifest and values of an existing Helm release from the Helm storage Secret and writes a
+helm-chart.yaml and helm-values.yaml into the given deployment item. The chart version is taken from the release,
+so that the next deployment does not change the deployed objects. All live objects of the release are then
+labeled with the Kluctl tracking metadata (discriminator, tags and common labels/annotations) of the deployment item.
+
+Use --dry-run to only show the files that would be written and all objects that would be relabeled.`
+}
+
+func (cmd *helmAdoptCmd) ArgsUsage() string {
+	return "RELEASE"
+}
+
+func (cmd *helmAdoptCmd) SetArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one release name")
+	}
+	cmd.release = args[0]
+	return nil
+}
+
+func (cmd *helmAdoptCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "helm-adopt"); err != nil {
+		return err
+	}
+
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		gitCredentials:       cmd.GitCredentials,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		dryRunArgs:           &cmd.DryRunFlags,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		return cmd.runCmdHelmAdopt(ctx, cmdCtx)
+	})
+}
+
+func (cmd *helmAdoptCmd) runCmdHelmAdopt(ctx context.Context, cmdCtx *commandCtx) error {
+	cmd2 := commands.NewHelmAdoptCommand(cmdCtx.targetCtx)
+	cmd2.ReleaseName = cmd.release
+	cmd2.Namespace = cmd.Namespace
+	cmd2.ItemDir = cmd.Into
+	cmd2.Repo = cmd.Repo
+	cmd2.DeleteReleaseMetadata = cmd.DeleteReleaseMetadata
+
+	plan, err := cmd2.Plan()
+	if err != nil {
+		return err
+	}
+	for _, w := range plan.Warnings {
+		status.Warning(ctx, w)
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString("The following files will be written:\n")
+	for _, f := range plan.Files {
+		buf.WriteString(fmt.Sprintf("  %s\n", f.Path))
+	}
+	buf.WriteString("The following tracking metadata will be added:\n")
+	writeSortedMap(&buf, plan.Labels)
+	writeSortedMap(&buf, plan.Annotations)
+	buf.WriteString("The following objects will be relabeled:\n")
+	for _, ref := range plan.Objects {
+		buf.WriteString(fmt.Sprintf("  %s\n", ref.String()))
+	}
+	if len(plan.DeleteRefs) != 0 {
+		buf.WriteString("The following Helm release metadata will be deleted:\n")
+		for _, ref := range plan.DeleteRefs {
+			buf.WriteString(fmt.Sprintf("  %s\n", ref.String()))
+		}
+	}
+	status.Flush(ctx)
+	_, _ = getStderr(ctx).WriteString(buf.String())
+
+	if cmd.DryRun {
+		return nil
+	}
+	if !cmd.Yes {
+		if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you really want to adopt the Helm release %s/%s?", cmd.Namespace, cmd.release)) {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	s := status.Startf(ctx, "Adopting Helm release %s/%s", cmd.Namespace, cmd.release)
+	err = cmd2.Apply(plan)
+	if err != nil {
+		s.FailedWithMessagef("Failed to adopt Helm release: %s", err.Error())
+		return err
+	}
+	s.Success()
+
+	status.Infof(ctx, "Run 'kluctl helm-pull' to pre-pull the chart before deploying the target")
+	return nil
+}
+
+func writeSortedMap(buf *strings.Builder, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteString(fmt.Sprintf("  %s: %s\n", k, m[k]))
+	}
+}
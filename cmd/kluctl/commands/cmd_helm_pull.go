@@ -25,6 +25,7 @@ import (
 
 type helmPullCmd struct {
 	args.ProjectDir
+	args.FetchFlags
 	args.GitCredentials
 	args.HelmCredentials
 	args.RegistryCredentials
@@ -45,6 +46,8 @@ func (cmd *helmPullCmd) Run(ctx context.Context) error {
 	if !yaml.Exists(filepath.Join(projectDir, ".kluctl.yaml")) && !yaml.Exists(filepath.Join(projectDir, ".kluctl-library.yaml")) {
 		return fmt.Errorf("helm-pull can only be used on the root of a Kluctl project that must have a .kluctl.yaml or .kluctl-library.yaml file")
 	}
+	ctx = repocache.WithFetchScheduler(ctx, repocache.NewFetchScheduler(cmd.FetchConcurrency))
+
 	sshPool := &ssh_pool.SshPool{}
 	messageCallbacks := &messages.MessageCallbacks{
 		WarningFn:            func(s string) { status.Warning(ctx, s) },
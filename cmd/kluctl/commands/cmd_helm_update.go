@@ -28,6 +28,7 @@ import (
 
 type helmUpdateCmd struct {
 	args.ProjectDir
+	args.FetchFlags
 	args.GitCredentials
 	args.HelmCredentials
 	args.RegistryCredentials
@@ -56,6 +57,8 @@ func (cmd *helmUpdateCmd) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	ctx = repocache.WithFetchScheduler(ctx, repocache.NewFetchScheduler(cmd.FetchConcurrency))
+
 	sshPool := &ssh_pool.SshPool{}
 	messageCallbacks := &messages.MessageCallbacks{
 		WarningFn:            func(s string) { status.Warning(ctx, s) },
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"strings"
+)
+
+type lintCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.ImageFlags
+	args.InclusionFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.RenderOutputDirFlags
+	args.OfflineKubernetesFlags
+
+	DeprecationsOnly bool `group:"misc" help:"Only report usages of deprecated features."`
+}
+
+func (cmd *lintCmd) Help() string {
+	return `Renders the target and reports usages of deprecated features and other configuration warnings.
+
+For each deprecated feature, the replacement and the kluctl version in which the feature stops working is reported.
+Deprecations listed in 'deprecations.errors' of .kluctl.yaml are marked as errors, but do not abort rendering.
+The command fails if any findings are reported.`
+}
+
+func (cmd *lintCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:          cmd.ProjectFlags,
+		kubeconfigFlags:       cmd.KubeconfigFlags,
+		targetFlags:           cmd.TargetFlags,
+		argsFlags:             cmd.ArgsFlags,
+		imageFlags:            cmd.ImageFlags,
+		inclusionFlags:        cmd.InclusionFlags,
+		gitCredentials:        cmd.GitCredentials,
+		helmCredentials:       cmd.HelmCredentials,
+		registryCredentials:   cmd.RegistryCredentials,
+		renderOutputDirFlags:  cmd.RenderOutputDirFlags,
+		offlineKubernetes:     cmd.OfflineKubernetes,
+		kubernetesVersion:     cmd.KubernetesVersion,
+		skipDeprecationErrors: true,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		targetCtx := cmdCtx.targetCtx
+
+		findings := targetCtx.SharedContext.Deprecations.GetWarnings()
+		if !cmd.DeprecationsOnly {
+			findings = append(findings, targetCtx.SharedContext.VarsLoader.GetWarnings()...)
+			findings = append(findings, targetCtx.SharedContext.ConfigWarnings.GetWarnings()...)
+		}
+
+		status.Flush(ctx)
+		if len(findings) == 0 {
+			_, _ = getStdout(ctx).WriteString("No findings\n")
+			return nil
+		}
+		_, _ = getStdout(ctx).WriteString(formatLintFindings(findings, targetCtx.KluctlProject.GetDeprecationErrors()))
+		return fmt.Errorf("lint reported %d findings", len(findings))
+	})
+}
+
+func formatLintFindings(findings []result.DeploymentError, deprecationErrors []string) string {
+	isError := map[string]bool{}
+	for _, id := range deprecationErrors {
+		isError[id] = true
+	}
+
+	var sb strings.Builder
+	for _, f := range findings {
+		if f.Deprecation == nil {
+			sb.WriteString(fmt.Sprintf("warning: %s\n", f.Message))
+			continue
+		}
+		level := "warning"
+		if isError[f.Deprecation.Id] {
+			level = "error"
+		}
+		sb.WriteString(fmt.Sprintf("%s: deprecation %s: %s\n", level, f.Deprecation.Id, f.Message))
+		if f.Deprecation.Replacement != "" {
+			sb.WriteString(fmt.Sprintf("  replacement: %s\n", f.Deprecation.Replacement))
+		}
+		sb.WriteString(fmt.Sprintf("  removed in:  %s\n", f.Deprecation.RemovedIn))
+	}
+	return sb.String()
+}
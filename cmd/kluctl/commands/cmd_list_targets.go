@@ -37,7 +37,7 @@ func (cmd *listTargetsCmd) Run(ctx context.Context) error {
 			if targetNamesStr != "" {
 				targetNamesStr += "\n"
 			}
-			return outputResult2(ctx, cmd.Output, targetNamesStr)
+			return outputResult2(ctx, cmd.Output, targetNamesStr, false)
 		}
 		return outputYamlResult(ctx, cmd.Output, result, false)
 	})
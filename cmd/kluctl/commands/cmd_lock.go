@@ -0,0 +1,5 @@
+package commands
+
+type lockCmd struct {
+	Write lockWriteCmd `cmd:"" help:"Write the lock file for a target"`
+}
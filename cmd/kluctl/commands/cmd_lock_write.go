@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/projectlock"
+)
+
+type lockWriteCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.ImageFlags
+	args.InclusionFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.OfflineKubernetesFlags
+	args.LockFileFlags
+}
+
+func (cmd *lockWriteCmd) Help() string {
+	return `Renders the target and records all inputs that were resolved from external sources into the lock file.
+
+This includes the commits of git includes and git vars sources, the digests of OCI includes, the versions and
+content digests of Helm Charts, the images returned by images.get_image() and the content hashes of git and http vars
+sources. Sensitive vars sources are not recorded.
+
+The lock file contains one entry per target, entries of other targets are preserved. Use --locked on deploy, diff or
+render to fail when any of these inputs resolves differently than recorded.`
+}
+
+func (cmd *lockWriteCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:        cmd.ProjectFlags,
+		kubeconfigFlags:     cmd.KubeconfigFlags,
+		targetFlags:         cmd.TargetFlags,
+		argsFlags:           cmd.ArgsFlags,
+		imageFlags:          cmd.ImageFlags,
+		inclusionFlags:      cmd.InclusionFlags,
+		gitCredentials:      cmd.GitCredentials,
+		helmCredentials:     cmd.HelmCredentials,
+		registryCredentials: cmd.RegistryCredentials,
+		offlineKubernetes:   cmd.OfflineKubernetes,
+		kubernetesVersion:   cmd.KubernetesVersion,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		lockFile := cmd.GetLockFile(cmdCtx.targetCtx.KluctlProject.LoadArgs.ProjectDir)
+		err := projectlock.WriteTargetLock(lockFile, cmd.Target, cmdCtx.lockRecorder.GetLock())
+		if err != nil {
+			return err
+		}
+		status.Infof(ctx, "Written lock for target '%s' to %s", cmd.Target, lockFile)
+		return nil
+	})
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"text/template"
+)
+
+type planCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.InclusionFlags
+	args.PruneExcludeFlags
+	args.ImageFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.OutputFlags
+	args.RenderOutputDirFlags
+	args.ObjectPatchFlags
+
+	NoWait        bool   `group:"misc" help:"Don't wait for objects readiness."`
+	Prune         bool   `group:"misc" help:"Include the pruning of orphaned objects in the plan, as done by 'deploy --prune'."`
+	Discriminator string `group:"misc" help:"Override the target discriminator."`
+}
+
+func (cmd *planCmd) Help() string {
+	return `The plan lists the ordered sequence of operations that a deploy would perform, including hooks,
+applied objects per deployment item, readiness waits, barriers and pruning. Objects are not
+diffed. Deployment items between two barriers are applied in parallel.
+
+The output format can be either 'text' (the default) or 'yaml'. The plan also contains the
+estimated number of progress steps of each deployment item.`
+}
+
+func (cmd *planCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		imageFlags:           cmd.ImageFlags,
+		inclusionFlags:       cmd.InclusionFlags,
+		pruneExcludeFlags:    cmd.PruneExcludeFlags,
+		gitCredentials:       cmd.GitCredentials,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		objectPatchFlags:     cmd.ObjectPatchFlags,
+		discriminator:        cmd.Discriminator,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		cmd2 := commands.NewPlanCommand(cmdCtx.targetCtx)
+		cmd2.NoWait = cmd.NoWait
+		cmd2.Prune = cmd.Prune
+		pr := cmd2.Run()
+
+		err := outputHelper(ctx, cmd.Output, nil, func(format string, tmpl *template.Template, limits *textOutputLimits) (string, error) {
+			return formatPlanResult(pr, format)
+		})
+		if err != nil {
+			return err
+		}
+		if len(pr.Errors) != 0 {
+			return fmt.Errorf("command failed")
+		}
+		return nil
+	})
+}
+
+func formatPlanResult(pr *commands.PlanResult, format string) (string, error) {
+	switch format {
+	case "text":
+		buf := bytes.NewBuffer(nil)
+		if pr.Plan != nil {
+			buf.WriteString("\nPlan:\n")
+			buf.WriteString(utils.DescribeApplyPlan(pr.Plan))
+		}
+		if len(pr.Warnings) != 0 {
+			buf.WriteString("\nWarnings:\n")
+			prettyErrors(buf, pr.Warnings)
+		}
+		if len(pr.Errors) != 0 {
+			buf.WriteString("\nErrors:\n")
+			prettyErrors(buf, pr.Errors)
+		}
+		return buf.String(), nil
+	case "yaml":
+		return yaml.WriteYamlString(pr)
+	default:
+		return "", fmt.Errorf("invalid plan format: %s", format)
+	}
+}
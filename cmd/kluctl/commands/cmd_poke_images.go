@@ -23,6 +23,8 @@ type pokeImagesCmd struct {
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
 	args.CommandResultFlags
+	args.NotifyFlags
+	args.ResultWebhookFlags
 }
 
 func (cmd *pokeImagesCmd) Help() string {
@@ -32,6 +34,10 @@ replaced`
 }
 
 func (cmd *pokeImagesCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "poke-images"); err != nil {
+		return err
+	}
+
 	ptArgs := projectTargetCommandArgs{
 		projectFlags:         cmd.ProjectFlags,
 		kubeconfigFlags:      cmd.KubeconfigFlags,
@@ -45,8 +51,12 @@ func (cmd *pokeImagesCmd) Run(ctx context.Context) error {
 		dryRunArgs:           &cmd.DryRunFlags,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		commandResultFlags:   &cmd.CommandResultFlags,
+		notifyFlags:          &cmd.NotifyFlags,
+		resultWebhookFlags:   &cmd.ResultWebhookFlags,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		cmd.OutputFormatFlags = resolveOutputFormatFlags(ctx, cmdCtx, cmd.OutputFormatFlags)
+
 		if !cmd.Yes && !cmd.DryRun {
 			if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you really want to poke images to the context/cluster %s?", cmdCtx.targetCtx.ClusterContext)) {
 				return fmt.Errorf("aborted")
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/preview"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	client2 "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type previewCmd struct {
+	Create previewCreateCmd `cmd:"" help:"Create or update a preview environment derived from a template target"`
+	Delete previewDeleteCmd `cmd:"" help:"Delete a preview environment"`
+	List   previewListCmd   `cmd:"" help:"List preview environments"`
+	Gc     previewGcCmd     `cmd:"" help:"Delete preview environments that were not deployed for some time"`
+}
+
+// PreviewClusterFlags are used by the preview sub-commands that work without the Kluctl project
+type PreviewClusterFlags struct {
+	Kubeconfig args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context    string                `group:"misc" help:"The kubernetes context to use. Defaults to the current context."`
+
+	args.CommandResultReadOnlyFlags
+}
+
+type previewCluster struct {
+	flags  PreviewClusterFlags
+	config *rest.Config
+	mapper meta.RESTMapper
+	k      *k8s.K8sCluster
+	client client2.Client
+}
+
+func (f *PreviewClusterFlags) connect(ctx context.Context, dryRun bool) (*previewCluster, error) {
+	r := clientcmd.NewDefaultClientConfigLoadingRules()
+	r.ExplicitPath = f.Kubeconfig.String()
+	configOverrides := &clientcmd.ConfigOverrides{
+		CurrentContext: f.Context,
+	}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(r, configOverrides).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	discovery, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	k, err := k8s.NewK8sCluster(ctx, config, discovery, mapper, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	c, err := k.ToClient()
+	if err != nil {
+		return nil, err
+	}
+	return &previewCluster{flags: *f, config: config, mapper: mapper, k: k, client: c}, nil
+}
+
+// deletePreview deletes all objects with the discriminator of the preview, the namespace of the preview if it was
+// created for the preview, all stored command results of the preview and finally the preview record itself.
+func (pc *previewCluster) deletePreview(ctx context.Context, p *preview.Preview, dryRun bool, yes bool, wait bool) error {
+	cmd := commands.NewDeleteCommand(p.Discriminator, nil, nil, wait)
+	r := cmd.Run(ctx, pc.k, func(refs []k8s2.ObjectRef) error {
+		return confirmDeletion(ctx, refs, dryRun, yes)
+	})
+	if len(r.Errors) != 0 {
+		return fmt.Errorf("failed to delete objects of preview %s: %s", p.Name, r.Errors[0].Message)
+	}
+	if dryRun {
+		return nil
+	}
+
+	if p.CreatedNamespace {
+		ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: p.Namespace}}
+		err := pc.client.Delete(ctx, &ns)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete namespace %s of preview %s: %w", p.Namespace, p.Name, err)
+		}
+	}
+
+	err := pc.deletePreviewResults(ctx, p)
+	if err != nil {
+		return fmt.Errorf("failed to delete command results of preview %s: %w", p.Name, err)
+	}
+
+	err = preview.Delete(ctx, pc.client, pc.flags.CommandResultNamespace, p.Name)
+	if err != nil {
+		return err
+	}
+	status.Infof(ctx, "Deleted preview %s", p.Name)
+	return nil
+}
+
+func (pc *previewCluster) deletePreviewResults(ctx context.Context, p *preview.Preview) error {
+	flags := args.CommandResultFlags{
+		CommandResultReadOnlyFlags: pc.flags.CommandResultReadOnlyFlags,
+		CommandResultWriteFlags:    args.CommandResultWriteFlags{WriteCommandResult: true},
+	}
+	store, err := buildResultStoreRW(ctx, pc.config, pc.mapper, &flags, false)
+	if err != nil {
+		return err
+	}
+	summaries, err := store.ListCommandResultSummaries(results.ListResultSummariesOptions{
+		Namespace: pc.flags.CommandResultNamespace,
+	})
+	if err != nil {
+		return err
+	}
+	for _, rs := range summaries {
+		if rs.TargetKey.Discriminator != p.Discriminator {
+			continue
+		}
+		err = store.DeleteCommandResult(rs.Id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/preview"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type previewCreateCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.ImageFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.YesFlags
+	args.DryRunFlags
+	args.ForceApplyFlags
+	args.ReplaceOnErrorFlags
+	args.AbortOnErrorFlags
+	args.OutputFormatFlags
+	args.CommandResultFlags
+
+	DeployExtraFlags
+
+	Name      string `group:"misc" help:"The name of the preview, e.g. 'pr-123'. Must be a valid DNS label." required:"true"`
+	Namespace string `group:"misc" help:"The namespace to deploy the preview into. Defaults to the name of the preview."`
+}
+
+func (cmd *previewCreateCmd) Help() string {
+	return `Deploys a preview environment derived from the target given via -t, which serves as template.
+
+All namespaced objects of the template target are moved into the preview namespace (see namespaceOverride of
+targets), which is created if it does not exist yet. The discriminator of the template target is suffixed with the
+preview namespace, so that previews never see each other's objects as orphans. The template target must therefore
+define a discriminator. Additional overrides can be passed via the usual -a/--arg arguments.
+
+The preview is recorded in the cluster, so that it can later be listed and deleted without access to the project.
+Running this command again for the same name re-deploys the preview.
+`
+}
+
+func (cmd *previewCreateCmd) Run(ctx context.Context) error {
+	err := preview.ValidateName(cmd.Name)
+	if err != nil {
+		return err
+	}
+	namespace := cmd.Namespace
+	if namespace == "" {
+		namespace = cmd.Name
+	}
+
+	cmd2 := deployCmd{
+		ProjectFlags:        cmd.ProjectFlags,
+		KubeconfigFlags:     cmd.KubeconfigFlags,
+		TargetFlags:         cmd.TargetFlags,
+		ArgsFlags:           cmd.ArgsFlags,
+		ImageFlags:          cmd.ImageFlags,
+		GitCredentials:      cmd.GitCredentials,
+		HelmCredentials:     cmd.HelmCredentials,
+		RegistryCredentials: cmd.RegistryCredentials,
+		YesFlags:            cmd.YesFlags,
+		DryRunFlags:         cmd.DryRunFlags,
+		ForceApplyFlags:     cmd.ForceApplyFlags,
+		ReplaceOnErrorFlags: cmd.ReplaceOnErrorFlags,
+		AbortOnErrorFlags:   cmd.AbortOnErrorFlags,
+		OutputFormatFlags:   cmd.OutputFormatFlags,
+		CommandResultFlags:  cmd.CommandResultFlags,
+		DeployExtraFlags:    cmd.DeployExtraFlags,
+
+		namespaceOverride: namespace,
+		beforeDeploy: func(ctx context.Context, cmdCtx *commandCtx) error {
+			return cmd.recordPreview(ctx, cmdCtx, namespace)
+		},
+	}
+	return cmd2.Run(ctx)
+}
+
+// recordPreview creates the preview namespace and writes the preview record before anything is deployed, so that
+// failed deployments can still be cleaned up via 'preview delete' and 'preview gc'
+func (cmd *previewCreateCmd) recordPreview(ctx context.Context, cmdCtx *commandCtx, namespace string) error {
+	discriminator := cmdCtx.targetCtx.Target.Discriminator
+	if discriminator == "" {
+		return fmt.Errorf("previews require the template target to define a discriminator")
+	}
+	if cmd.DryRun {
+		return nil
+	}
+
+	c, err := cmdCtx.targetCtx.SharedContext.K.ToClient()
+	if err != nil {
+		return err
+	}
+
+	old, err := preview.Get(ctx, c, cmd.CommandResultNamespace, cmd.Name)
+	if err != nil {
+		return err
+	}
+	if old != nil && (old.Target != cmd.Target || old.Namespace != namespace) {
+		return fmt.Errorf("preview %s already exists for target '%s' in namespace %s", cmd.Name, old.Target, old.Namespace)
+	}
+
+	created, err := preview.EnsureNamespace(ctx, c, namespace, map[string]string{preview.PreviewLabel: cmd.Name})
+	if err != nil {
+		return err
+	}
+
+	now := metav1.NewTime(time.Now())
+	p := &preview.Preview{
+		Name:             cmd.Name,
+		Target:           cmd.Target,
+		Namespace:        namespace,
+		Discriminator:    discriminator,
+		CreatedNamespace: created,
+		CreationTime:     now,
+		LastDeployed:     now,
+	}
+	if old != nil {
+		p.CreatedNamespace = p.CreatedNamespace || old.CreatedNamespace
+		p.CreationTime = old.CreationTime
+	}
+	err = preview.Write(ctx, c, cmd.CommandResultNamespace, p)
+	if err != nil {
+		return err
+	}
+
+	status.Infof(ctx, "Deploying preview %s into namespace %s with discriminator %s", cmd.Name, namespace, discriminator)
+	return nil
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/preview"
+)
+
+type previewDeleteCmd struct {
+	PreviewClusterFlags
+
+	args.YesFlags
+	args.DryRunFlags
+
+	NoWait bool `group:"misc" help:"Don't wait for deletion of objects to finish.'"`
+
+	name string
+}
+
+func (cmd *previewDeleteCmd) Help() string {
+	return `Deletes all objects with the discriminator of the preview. The preview namespace is deleted as well if it
+was created by 'preview create'. Stored command results of the preview and the preview record are removed afterwards.
+
+The Kluctl project is not required, as everything needed is read from the preview record in the cluster.
+`
+}
+
+func (cmd *previewDeleteCmd) ArgsUsage() string {
+	return "NAME"
+}
+
+func (cmd *previewDeleteCmd) SetArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one preview name")
+	}
+	cmd.name = args[0]
+	return nil
+}
+
+func (cmd *previewDeleteCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "preview delete"); err != nil {
+		return err
+	}
+
+	pc, err := cmd.connect(ctx, cmd.DryRun)
+	if err != nil {
+		return err
+	}
+	p, err := preview.Get(ctx, pc.client, cmd.CommandResultNamespace, cmd.name)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("preview %s not found", cmd.name)
+	}
+	return pc.deletePreview(ctx, p, cmd.DryRun, cmd.Yes, !cmd.NoWait)
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/preview"
+)
+
+type previewGcCmd struct {
+	PreviewClusterFlags
+
+	args.YesFlags
+	args.DryRunFlags
+
+	OlderThan args.AgeType `group:"misc" help:"Delete all previews that were not deployed since this duration, e.g. '7d' or '12h'." required:"true"`
+	NoWait    bool         `group:"misc" help:"Don't wait for deletion of objects to finish.'"`
+}
+
+func (cmd *previewGcCmd) Help() string {
+	return `Deletes all preview environments that were not deployed (via 'preview create') within the given duration.
+This is meant to clean up leaked previews, e.g. of pull requests that got closed without running 'preview delete'.
+
+Previews are deleted the same way as with 'preview delete'.
+`
+}
+
+func (cmd *previewGcCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "preview gc"); err != nil {
+		return err
+	}
+	if cmd.OlderThan <= 0 {
+		return fmt.Errorf("--older-than must be greater than zero")
+	}
+
+	pc, err := cmd.connect(ctx, cmd.DryRun)
+	if err != nil {
+		return err
+	}
+	previews, err := preview.List(ctx, pc.client, cmd.CommandResultNamespace)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var errs *multierror.Error
+	deleted := 0
+	for _, p := range previews {
+		if !p.IsExpired(time.Duration(cmd.OlderThan), now) {
+			continue
+		}
+		err = pc.deletePreview(ctx, &p, cmd.DryRun, cmd.Yes, !cmd.NoWait)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		deleted++
+	}
+	status.Infof(ctx, "Deleted %d of %d previews", deleted, len(previews))
+	return errs.ErrorOrNil()
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/preview"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+)
+
+type previewListCmd struct {
+	PreviewClusterFlags
+
+	args.OutputFlags
+}
+
+func (cmd *previewListCmd) Help() string {
+	return `Lists all preview environments recorded in the cluster.
+
+The output format can be specified via '-o', e.g. '-o yaml' or '-o json=previews.json'. The default format is 'text'.
+`
+}
+
+func (cmd *previewListCmd) Run(ctx context.Context) error {
+	pc, err := cmd.connect(ctx, true)
+	if err != nil {
+		return err
+	}
+	previews, err := preview.List(ctx, pc.client, cmd.CommandResultNamespace)
+	if err != nil {
+		return err
+	}
+
+	return outputHelper(ctx, cmd.Output, nil, func(format string, tmpl *template.Template, limits *textOutputLimits) (string, error) {
+		return formatPreviews(previews, format)
+	})
+}
+
+func formatPreviews(previews []preview.Preview, format string) (string, error) {
+	switch format {
+	case "text":
+		var t utils.PrettyTable
+		t.AddRow("NAME", "TARGET", "NAMESPACE", "DISCRIMINATOR", "CREATED", "LAST DEPLOYED")
+		for _, p := range previews {
+			t.AddRow(p.Name, p.Target, p.Namespace, p.Discriminator,
+				p.CreationTime.UTC().Format("2006-01-02 15:04:05"), p.LastDeployed.UTC().Format("2006-01-02 15:04:05"))
+		}
+		return t.Render(nil), nil
+	case "yaml":
+		return yaml.WriteYamlString(previews)
+	case "json":
+		return formatJson(previews)
+	default:
+		return "", fmt.Errorf("invalid format: %s", format)
+	}
+}
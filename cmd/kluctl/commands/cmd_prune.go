@@ -15,14 +15,19 @@ type pruneCmd struct {
 	args.ArgsFlags
 	args.ImageFlags
 	args.InclusionFlags
+	args.PruneExcludeFlags
 	args.GitCredentials
 	args.HelmCredentials
 	args.RegistryCredentials
 	args.YesFlags
 	args.DryRunFlags
+	args.SafetyThresholdFlags
+	args.DeletedManifestsFlags
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
 	args.CommandResultFlags
+	args.NotifyFlags
+	args.ResultWebhookFlags
 
 	Discriminator string `group:"misc" help:"Override the target discriminator."`
 }
@@ -36,6 +41,10 @@ func (cmd *pruneCmd) Help() string {
 }
 
 func (cmd *pruneCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "prune"); err != nil {
+		return err
+	}
+
 	ptArgs := projectTargetCommandArgs{
 		projectFlags:         cmd.ProjectFlags,
 		kubeconfigFlags:      cmd.KubeconfigFlags,
@@ -43,12 +52,15 @@ func (cmd *pruneCmd) Run(ctx context.Context) error {
 		argsFlags:            cmd.ArgsFlags,
 		imageFlags:           cmd.ImageFlags,
 		inclusionFlags:       cmd.InclusionFlags,
+		pruneExcludeFlags:    cmd.PruneExcludeFlags,
 		gitCredentials:       cmd.GitCredentials,
 		helmCredentials:      cmd.HelmCredentials,
 		registryCredentials:  cmd.RegistryCredentials,
 		dryRunArgs:           &cmd.DryRunFlags,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		commandResultFlags:   &cmd.CommandResultFlags,
+		notifyFlags:          &cmd.NotifyFlags,
+		resultWebhookFlags:   &cmd.ResultWebhookFlags,
 		discriminator:        cmd.Discriminator,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
@@ -57,7 +69,15 @@ func (cmd *pruneCmd) Run(ctx context.Context) error {
 }
 
 func (cmd *pruneCmd) runCmdPrune(ctx context.Context, cmdCtx *commandCtx) error {
+	cmd.OutputFormatFlags = resolveOutputFormatFlags(ctx, cmdCtx, cmd.OutputFormatFlags)
+
+	if cmd.ShowSelection {
+		showSelection(ctx, cmdCtx)
+	}
+
 	cmd2 := commands.NewPruneCommand(cmdCtx.targetCtx.Target.Discriminator, cmdCtx.targetCtx, true)
+	cmd2.OverrideSafetyThreshold = cmd.OverrideSafetyThreshold
+	cmd2.DeletedManifests = buildDeletedManifestsOptions(cmd.DeletedManifestsFlags)
 	result := cmd2.Run(func(refs []k8s2.ObjectRef) error {
 		return confirmDeletion(ctx, refs, cmd.DryRun, cmd.Yes)
 	})
@@ -21,7 +21,9 @@ type renderCmd struct {
 	args.HelmCredentials
 	args.RegistryCredentials
 	args.RenderOutputDirFlags
+	args.ObjectPatchFlags
 	args.OfflineKubernetesFlags
+	args.LockFlags
 
 	PrintAll bool `group:"misc" help:"Write all rendered manifests to stdout"`
 }
@@ -53,6 +55,8 @@ func (cmd *renderCmd) Run(ctx context.Context) error {
 		helmCredentials:      cmd.HelmCredentials,
 		registryCredentials:  cmd.RegistryCredentials,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		objectPatchFlags:     cmd.ObjectPatchFlags,
+		lockFlags:            &cmd.LockFlags,
 		offlineKubernetes:    cmd.OfflineKubernetes,
 		kubernetesVersion:    cmd.KubernetesVersion,
 	}
@@ -0,0 +1,35 @@
+package commands
+
+import "fmt"
+
+type resultsCmd struct {
+	List   resultsListCmd   `cmd:"" help:"List stored command results of one or more clusters"`
+	Get    resultsGetCmd    `cmd:"" help:"Show a stored command result"`
+	Export resultsExportCmd `cmd:"" help:"Export a stored command result"`
+	Import resultsImportCmd `cmd:"" help:"Import a command result from an archive"`
+	Show   resultsShowCmd   `cmd:"" help:"Show a stored command result as HTML report or text"`
+	Verify resultsVerifyCmd `cmd:"" help:"Verify the signature of a stored command result"`
+	Diff   resultsDiffCmd   `cmd:"" help:"Compare two stored command results"`
+
+	RestoreObject resultsRestoreObjectCmd `cmd:"" help:"Restore an object that got deleted by a stored command result"`
+
+	FlushSpool resultsFlushSpoolCmd `cmd:"" help:"Retry writing locally spooled command results"`
+	Prune      resultsPruneCmd      `cmd:"" help:"Remove old command results from the result store"`
+}
+
+// setResultIdArg allows passing the command result id either as positional argument or via --result-id
+func setResultIdArg(resultId *string, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("expected at most one command result id")
+	}
+	if len(args) == 1 {
+		if *resultId != "" && *resultId != args[0] {
+			return fmt.Errorf("conflicting command result ids passed via argument and --result-id")
+		}
+		*resultId = args[0]
+	}
+	if *resultId == "" {
+		return fmt.Errorf("expected a command result id")
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/results/resultdiff"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+)
+
+type resultsDiffCmd struct {
+	Kubeconfig args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context    string                `group:"misc" help:"The kubernetes context to use. Defaults to the current context."`
+
+	args.CommandResultS3Flags
+
+	Project       string `group:"misc" help:"Only consider results of the project with the given repository key (e.g. 'github.com/org/repo') when resolving 'latest' ids."`
+	ProjectSubDir string `group:"misc" help:"Only consider results of the project in the given sub directory of the repository when resolving 'latest' ids. Requires --project."`
+	Target        string `group:"misc" help:"Only consider results of the given target when resolving 'latest' ids."`
+	Command       string `group:"misc" help:"Only consider results of the given command (e.g. 'deploy') when resolving 'latest' ids."`
+
+	args.OutputFlags
+	args.TableWidthFlags
+
+	idA string
+	idB string
+}
+
+func (cmd *resultsDiffCmd) Help() string {
+	return `Compares two command results from the result store and shows what changed from the first (older) to the
+second (newer) result. This includes objects that were added or removed, objects with different manifests and
+errors and warnings that appeared or disappeared.
+
+Objects are compared by their applied manifests, or by their rendered manifests for commands that do not apply
+anything (e.g. diff). Fields populated by the API server are ignored. Secret data and fields matching obfuscation
+rules are always obfuscated, even if the results were written with --no-obfuscate.
+
+Instead of ids, 'latest' and 'latest~N' can be passed to refer to the newest and the N-th newest result. These are
+resolved within the results matching --project, --target and --command, which must all belong to the same target.
+For example, 'kluctl results diff latest~1 latest --target prod --command deploy' compares the last two deployments
+of the 'prod' target.
+
+The output format can be specified via '-o', e.g. '-o yaml' or '-o json=diff.json'. The default format is 'text'.
+`
+}
+
+func (cmd *resultsDiffCmd) ArgsUsage() string {
+	return "RESULT_ID_A RESULT_ID_B"
+}
+
+func (cmd *resultsDiffCmd) SetArgs(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected exactly two command result ids")
+	}
+	cmd.idA = args[0]
+	cmd.idB = args[1]
+	return nil
+}
+
+func (cmd *resultsDiffCmd) Run(ctx context.Context) error {
+	if cmd.ProjectSubDir != "" && cmd.Project == "" {
+		return fmt.Errorf("--project-sub-dir requires --project")
+	}
+
+	store, err := createResultStore(ctx, cmd.Kubeconfig.String(), cmd.Context, &cmd.CommandResultS3Flags)
+	if err != nil {
+		return err
+	}
+
+	var entries []result.CommandResultIndexEntry
+	if isLatestShorthand(cmd.idA) || isLatestShorthand(cmd.idB) {
+		var options results.ListResultSummariesOptions
+		if cmd.Project != "" {
+			repoKey, err := gittypes.ParseRepoKey(cmd.Project, "git")
+			if err != nil {
+				return err
+			}
+			options.ProjectFilter = &gittypes.ProjectKey{
+				RepoKey: repoKey,
+				SubDir:  cmd.ProjectSubDir,
+			}
+		}
+		entries, err = store.ListCommandResultIndex(options)
+		if err != nil {
+			return err
+		}
+		entries = filterResultIndexEntries(entries, cmd.Target, time.Time{})
+		if cmd.Command != "" {
+			var filtered []result.CommandResultIndexEntry
+			for _, e := range entries {
+				if e.Command == cmd.Command {
+					filtered = append(filtered, e)
+				}
+			}
+			entries = filtered
+		}
+	}
+
+	getResult := func(id string) (*result.CommandResult, error) {
+		id, err := resolveResultId(entries, id)
+		if err != nil {
+			return nil, err
+		}
+		cr, err := store.GetCommandResult(results.GetCommandResultOptions{
+			Id: id,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if cr == nil {
+			return nil, fmt.Errorf("command result %s not found", id)
+		}
+		return cr, nil
+	}
+
+	crA, err := getResult(cmd.idA)
+	if err != nil {
+		return err
+	}
+	crB, err := getResult(cmd.idB)
+	if err != nil {
+		return err
+	}
+
+	d, err := resultdiff.Compare(crA, crB)
+	if err != nil {
+		return err
+	}
+
+	limits := newTableOutputLimits(ctx, cmd.TableWidthFlags)
+	return outputHelper(ctx, cmd.Output, limits, func(format string, tmpl *template.Template, limits *textOutputLimits) (string, error) {
+		return formatResultDiff(ctx, d, format, limits)
+	})
+}
+
+func isLatestShorthand(id string) bool {
+	return id == "latest" || strings.HasPrefix(id, "latest~")
+}
+
+// resolveResultId resolves 'latest' and 'latest~N' to the id of the newest or N-th newest entry. entries must be sorted
+// by start time, newest first, and must all belong to the same target. Other ids are returned unchanged.
+func resolveResultId(entries []result.CommandResultIndexEntry, id string) (string, error) {
+	if !isLatestShorthand(id) {
+		return id, nil
+	}
+	n := 0
+	if s, ok := strings.CutPrefix(id, "latest~"); ok {
+		var err error
+		n, err = strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid command result id '%s', expected 'latest' or 'latest~N'", id)
+		}
+	}
+
+	for i := 1; i < len(entries); i++ {
+		if entries[i].ProjectKey != entries[0].ProjectKey || entries[i].TargetKey != entries[0].TargetKey {
+			return "", fmt.Errorf("'%s' is ambiguous as the command results belong to multiple targets, use --project and --target to select one", id)
+		}
+	}
+	if n >= len(entries) {
+		return "", fmt.Errorf("'%s' does not refer to an existing command result, only %d results found", id, len(entries))
+	}
+	return entries[n].Id, nil
+}
+
+func formatResultDiff(ctx context.Context, d *resultdiff.ResultDiff, format string, limits *textOutputLimits) (string, error) {
+	switch format {
+	case "text":
+		return formatResultDiffText(ctx, d, limits), nil
+	case "yaml":
+		return yaml.WriteYamlString(d)
+	case "json":
+		return formatJson(d)
+	default:
+		return "", fmt.Errorf("invalid format: %s", format)
+	}
+}
+
+func formatResultDiffText(ctx context.Context, d *resultdiff.ResultDiff, limits *textOutputLimits) string {
+	buf := &strings.Builder{}
+
+	var t utils.PrettyTable
+	t.AddRow("", "ID", "COMMAND", "TARGET", "START TIME")
+	for _, x := range []struct {
+		name string
+		info resultdiff.ResultInfo
+	}{{"A", d.A}, {"B", d.B}} {
+		t.AddRow(x.name, x.info.Id, x.info.Command, x.info.TargetKey.TargetName, x.info.StartTime.UTC().Format("2006-01-02 15:04:05"))
+	}
+	buf.WriteString(t.RenderFit(limits.getTableWidth()))
+
+	if d.IsEmpty() {
+		buf.WriteString("\nNo differences found.\n")
+		return buf.String()
+	}
+
+	prettyRefs := func(title string, refs []k8s.ObjectRef) {
+		if len(refs) == 0 {
+			return
+		}
+		buf.WriteString(fmt.Sprintf("\n%s:\n", title))
+		for _, ref := range refs {
+			buf.WriteString(fmt.Sprintf("  %s\n", ref.String()))
+		}
+	}
+	prettyErrors := func(title string, errs []result.DeploymentError) {
+		if len(errs) == 0 {
+			return
+		}
+		buf.WriteString(fmt.Sprintf("\n%s:\n", title))
+		for _, e := range errs {
+			buf.WriteString(fmt.Sprintf("  %s: %s\n", e.Ref.String(), e.Message))
+		}
+	}
+
+	prettyRefs("Added objects", d.AddedObjects)
+	prettyRefs("Removed objects", d.RemovedObjects)
+
+	if len(d.ChangedObjects) != 0 {
+		buf.WriteString("\nChanged objects:\n")
+		for _, o := range d.ChangedObjects {
+			buf.WriteString(fmt.Sprintf("  %s\n", o.Ref.String()))
+		}
+		msgs := getMessageCatalog(ctx)
+		for _, o := range d.ChangedObjects {
+			buf.WriteString("\n")
+			prettyChanges(buf, o.Ref, o.Changes, limits, msgs)
+		}
+	}
+
+	prettyErrors("New errors", d.AddedErrors)
+	prettyErrors("Resolved errors", d.RemovedErrors)
+	prettyErrors("New warnings", d.AddedWarnings)
+	prettyErrors("Resolved warnings", d.RemovedWarnings)
+
+	return buf.String()
+}
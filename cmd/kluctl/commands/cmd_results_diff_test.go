@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveResultId(t *testing.T) {
+	entries := buildTestIndexEntries(time.Now())
+	entriesA := filterResultIndexEntries(entries, "a", time.Time{})
+
+	id, err := resolveResultId(nil, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", id)
+
+	id, err = resolveResultId(entriesA, "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "0", id)
+	id, err = resolveResultId(entriesA, "latest~1")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", id)
+
+	_, err = resolveResultId(entriesA, "latest~3")
+	assert.ErrorContains(t, err, "only 3 results found")
+	_, err = resolveResultId(entriesA, "latest~x")
+	assert.ErrorContains(t, err, "invalid command result id")
+	_, err = resolveResultId(entries, "latest")
+	assert.ErrorContains(t, err, "ambiguous")
+	_, err = resolveResultId([]result.CommandResultIndexEntry{}, "latest")
+	assert.ErrorContains(t, err, "only 0 results found")
+}
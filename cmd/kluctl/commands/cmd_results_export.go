@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"strings"
+)
+
+type resultsExportCmd struct {
+	Kubeconfig args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context    string                `group:"misc" help:"The kubernetes context to use. Defaults to the current context."`
+
+	args.CommandResultS3Flags
+
+	ResultId    string `group:"misc" help:"The ID of the command result to export. Can also be passed as positional argument."`
+	Anonymize   bool   `group:"misc" help:"Replace names, namespaces, cluster IDs and URLs with stable placeholders."`
+	MappingFile string `group:"misc" help:"Path to write the placeholder mapping to. Required when --anonymize is used."`
+	Output      string `group:"misc" short:"o" help:"Path to write the exported result to. Defaults to stdout." default:"-"`
+	Format      string `group:"misc" help:"The format to export the command result in. Can be 'yaml' or 'archive'. Defaults to 'archive' if the output path ends with '.tar.gz' or '.tgz' and to 'yaml' otherwise."`
+}
+
+func (cmd *resultsExportCmd) Help() string {
+	return `Exports a command result from the result store as YAML or as portable archive.
+
+Archives bundle the compacted and obfuscated command result together with metadata about the export. They can be
+inspected without access to the cluster via 'kluctl results show --from-file' or imported into a result store via
+'kluctl results import'. The archive format is versioned, so that archives exported by older versions of kluctl can
+still be read. Archives can also be written directly by commands like deploy, e.g. via '-o archive=result.tar.gz'.
+
+When --anonymize is passed, a consistent pseudonymization is applied to the result, so that it can be shared
+with others without leaking internal hostnames, namespaces and object names. The structure, object counts, kinds,
+change types and field paths are kept intact. The mapping from placeholders to original values is written to the file
+specified via --mapping-file, which allows to de-anonymize answers locally.
+`
+}
+
+func (cmd *resultsExportCmd) ArgsUsage() string {
+	return "[RESULT_ID]"
+}
+
+func (cmd *resultsExportCmd) SetArgs(args []string) error {
+	return setResultIdArg(&cmd.ResultId, args)
+}
+
+func (cmd *resultsExportCmd) Run(ctx context.Context) error {
+	if cmd.Anonymize && cmd.MappingFile == "" {
+		return fmt.Errorf("--mapping-file is required when --anonymize is used")
+	}
+	format, err := getResultExportFormat(cmd.Format, cmd.Output)
+	if err != nil {
+		return err
+	}
+	if format == "archive" && cmd.Output == "-" {
+		return fmt.Errorf("the archive format requires an output file, e.g. '-o result.tar.gz'")
+	}
+
+	store, err := createResultStore(ctx, cmd.Kubeconfig.String(), cmd.Context, &cmd.CommandResultS3Flags)
+	if err != nil {
+		return err
+	}
+
+	cr, err := store.GetCommandResult(results.GetCommandResultOptions{
+		Id: cmd.ResultId,
+	})
+	if err != nil {
+		return err
+	}
+	if cr == nil {
+		return fmt.Errorf("command result %s not found", cmd.ResultId)
+	}
+
+	if cmd.Anonymize {
+		a := results.NewAnonymizer()
+		cr, err = a.AnonymizeCommandResult(cr)
+		if err != nil {
+			return err
+		}
+		err = yaml.WriteYamlFile(cmd.MappingFile, a.Mapping())
+		if err != nil {
+			return err
+		}
+	}
+
+	if format == "archive" {
+		b, err := results.WriteResultArchive(cr)
+		if err != nil {
+			return err
+		}
+		return outputResult(ctx, &cmd.Output, string(b), false)
+	}
+
+	s, err := yaml.WriteYamlString(cr.ToCompacted())
+	if err != nil {
+		return err
+	}
+	return outputResult(ctx, &cmd.Output, s, true)
+}
+
+// getResultExportFormat returns the export format, which is derived from the output path if not explicitly specified
+func getResultExportFormat(format string, output string) (string, error) {
+	switch format {
+	case "yaml", "archive":
+		return format, nil
+	case "":
+		if strings.HasSuffix(output, ".tar.gz") || strings.HasSuffix(output, ".tgz") {
+			return "archive", nil
+		}
+		return "yaml", nil
+	default:
+		return "", fmt.Errorf("invalid format: %s", format)
+	}
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"k8s.io/client-go/tools/clientcmd"
+	client2 "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type resultsFlushSpoolCmd struct {
+	Kubeconfig args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context    string                `group:"misc" help:"The kubernetes context to use. Defaults to the current context."`
+
+	KeepCommandResultsCount int `group:"results" help:"Configure how many old command results to keep." default:"5"`
+}
+
+func (cmd *resultsFlushSpoolCmd) Help() string {
+	return `Retries writing command results that were spooled locally because writing them to the result store failed.
+
+Only results of the cluster of the selected context are written. Results spooled for in-cluster result stores are
+written into the namespace of the original result store. Results spooled for other result stores (e.g. S3) are only
+retried automatically by the next command of the same target.
+`
+}
+
+func (cmd *resultsFlushSpoolCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "results flush-spool"); err != nil {
+		return err
+	}
+
+	r := clientcmd.NewDefaultClientConfigLoadingRules()
+	r.ExplicitPath = cmd.Kubeconfig.String()
+	configOverrides := &clientcmd.ConfigOverrides{
+		CurrentContext: cmd.Context,
+	}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(r, configOverrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+	_, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, config)
+	if err != nil {
+		return err
+	}
+	c, err := client2.New(config, client2.Options{Mapper: mapper})
+	if err != nil {
+		return err
+	}
+	clusterId, _, err := k8s.DetermineClusterId(ctx, c, config)
+	if err != nil {
+		return fmt.Errorf("failed to determine cluster ID: %w", err)
+	}
+
+	stores := map[string]results.ResultStore{}
+	flushed, err := flushResultSpool(ctx, clusterId, func(e *results.SpoolEntry) (results.ResultWriter, error) {
+		if e.Namespace == "" {
+			status.Infof(ctx, "Skipping spooled command result %s for %s, as it can only be retried by the next command of the same target", e.Result.Id, e.WriterName)
+			return nil, nil
+		}
+		if s, ok := stores[e.Namespace]; ok {
+			return s, nil
+		}
+		flags := args.CommandResultFlags{
+			CommandResultReadOnlyFlags: args.CommandResultReadOnlyFlags{CommandResultNamespace: e.Namespace},
+			CommandResultWriteFlags: args.CommandResultWriteFlags{
+				WriteCommandResult:      true,
+				KeepCommandResultsCount: cmd.KeepCommandResultsCount,
+			},
+		}
+		s, err := buildResultStoreRW(ctx, config, mapper, &flags, false)
+		if err != nil {
+			return nil, err
+		}
+		stores[e.Namespace] = s
+		return s, nil
+	})
+	status.Infof(ctx, "Flushed %d spooled command results", flushed)
+	return err
+}
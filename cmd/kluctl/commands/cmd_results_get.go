@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+)
+
+type resultsGetCmd struct {
+	Kubeconfig args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context    string                `group:"misc" help:"The kubernetes context to use. Defaults to the current context."`
+
+	args.CommandResultS3Flags
+
+	ResultId string `group:"misc" help:"The ID of the command result to show. Can also be passed as positional argument."`
+
+	args.OutputFormatFlags
+}
+
+func (cmd *resultsGetCmd) Help() string {
+	return `Shows a command result from the result store.
+
+The text output starts with the invocation that led to the result, which includes the sanitized command line,
+the relevant environment variables and the kluctl version. Use '-o yaml' or '-o json' to dump the full stored
+command result.
+`
+}
+
+func (cmd *resultsGetCmd) ArgsUsage() string {
+	return "[RESULT_ID]"
+}
+
+func (cmd *resultsGetCmd) SetArgs(args []string) error {
+	return setResultIdArg(&cmd.ResultId, args)
+}
+
+func (cmd *resultsGetCmd) Run(ctx context.Context) error {
+	store, err := createResultStore(ctx, cmd.Kubeconfig.String(), cmd.Context, &cmd.CommandResultS3Flags)
+	if err != nil {
+		return err
+	}
+
+	cr, err := store.GetCommandResult(results.GetCommandResultOptions{
+		Id: cmd.ResultId,
+	})
+	if err != nil {
+		return err
+	}
+
+	return outputCommandResult2(ctx, cmd.OutputFormatFlags, cr, getChangelogRules(nil, cr))
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type resultsImportCmd struct {
+	Kubeconfig args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context    string                `group:"misc" help:"The kubernetes context to use. Defaults to the current context."`
+
+	args.CommandResultReadOnlyFlags
+
+	KeepCommandResultsCount int `group:"results" help:"Configure how many old command results to keep." default:"5"`
+
+	file string
+}
+
+func (cmd *resultsImportCmd) Help() string {
+	return `Imports a command result from an archive into the result store, so that it can be inspected with the other
+'kluctl results' sub-commands and the webui.
+
+Archives are written by 'kluctl results export' and the 'archive' output format. The command result keeps its
+original id. Please note that importing a result triggers the same cleanup of old command results as any other
+command writing a result to the result store.
+`
+}
+
+func (cmd *resultsImportCmd) ArgsUsage() string {
+	return "ARCHIVE_FILE"
+}
+
+func (cmd *resultsImportCmd) SetArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one archive file")
+	}
+	cmd.file = args[0]
+	return nil
+}
+
+func (cmd *resultsImportCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "results import"); err != nil {
+		return err
+	}
+
+	md, cr, err := results.ReadResultArchiveFile(cmd.file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", cmd.file, err)
+	}
+
+	var config *rest.Config
+	var mapper meta.RESTMapper
+	if cmd.CommandResultS3Bucket == "" {
+		r := clientcmd.NewDefaultClientConfigLoadingRules()
+		r.ExplicitPath = cmd.Kubeconfig.String()
+		configOverrides := &clientcmd.ConfigOverrides{
+			CurrentContext: cmd.Context,
+		}
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(r, configOverrides).ClientConfig()
+		if err != nil {
+			return err
+		}
+		_, mapper, err = k8s.CreateDiscoveryAndMapper(ctx, config)
+		if err != nil {
+			return err
+		}
+	}
+
+	flags := args.CommandResultFlags{
+		CommandResultReadOnlyFlags: cmd.CommandResultReadOnlyFlags,
+		CommandResultWriteFlags: args.CommandResultWriteFlags{
+			WriteCommandResult:      true,
+			KeepCommandResultsCount: cmd.KeepCommandResultsCount,
+		},
+	}
+	store, err := buildResultStoreRW(ctx, config, mapper, &flags, false)
+	if err != nil {
+		return err
+	}
+
+	s := status.Startf(ctx, "Importing command result %s", cr.Id)
+	err = store.WriteCommandResult(cr)
+	if err != nil {
+		s.FailedWithMessagef("Failed to import command result %s: %s", cr.Id, err.Error())
+		return err
+	}
+	s.Success()
+	status.Infof(ctx, "Imported command result %s of target %s, which was exported at %s", cr.Id, cr.TargetKey.TargetName, md.ExportTime.UTC().Format("2006-01-02 15:04:05"))
+	return nil
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"text/template"
+	"time"
+
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+)
+
+type resultsListCmd struct {
+	Kubeconfig  args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context     []string              `group:"misc" help:"List of kubernetes contexts to use. Defaults to the current context."`
+	AllContexts bool                  `group:"misc" help:"Use all Kubernetes contexts found in the kubeconfig."`
+
+	args.CommandResultS3Flags
+
+	Project       string       `group:"misc" help:"Only list results of the project with the given repository key, e.g. 'github.com/org/repo'."`
+	ProjectSubDir string       `group:"misc" help:"Only list results of the project in the given sub directory of the repository. Requires --project."`
+	Target        string       `group:"misc" help:"Only list results of the given target."`
+	Since         args.AgeType `group:"misc" help:"Only list results of commands that were started within the given duration, e.g. '7d' or '12h'."`
+
+	Limit  int `group:"misc" help:"Maximum number of results to list. Set to 0 to disable the limit." default:"100"`
+	Offset int `group:"misc" help:"Number of results to skip before listing. Can be used together with --limit to page through results."`
+
+	args.OutputFlags
+}
+
+func (cmd *resultsListCmd) Help() string {
+	return `Lists the command results of one or more clusters.
+
+Only the result index of each cluster is read, which contains a lightweight summary record per command result. The
+index is maintained whenever a command result is written and is backfilled on demand for clusters that do not have
+an index yet. Use 'kluctl results get' to fetch a full command result.
+
+Results are sorted by start time, newest first. The results can be filtered via --project, --target and --since.
+By default, only the newest 100 results are listed, use --limit and --offset to page through older results.
+
+The output format can be specified via '-o', e.g. '-o yaml' or '-o json=results.json'. The default format is 'text'.
+`
+}
+
+func (cmd *resultsListCmd) Run(ctx context.Context) error {
+	if cmd.ProjectSubDir != "" && cmd.Project == "" {
+		return fmt.Errorf("--project-sub-dir requires --project")
+	}
+	if cmd.Limit < 0 || cmd.Offset < 0 {
+		return fmt.Errorf("--limit and --offset must not be negative")
+	}
+
+	var options results.ListResultSummariesOptions
+	if cmd.Project != "" {
+		repoKey, err := gittypes.ParseRepoKey(cmd.Project, "git")
+		if err != nil {
+			return err
+		}
+		options.ProjectFilter = &gittypes.ProjectKey{
+			RepoKey: repoKey,
+			SubDir:  cmd.ProjectSubDir,
+		}
+	}
+
+	stores, _, err := createResultStores(ctx, cmd.Kubeconfig.String(), cmd.Context, cmd.AllContexts, false, &cmd.CommandResultS3Flags)
+	if err != nil {
+		return err
+	}
+
+	rc := results.NewResultsCollector(ctx, stores)
+	entries, err := rc.ListCommandResultIndex(options)
+	if err != nil {
+		return err
+	}
+
+	var since time.Time
+	if cmd.Since > 0 {
+		since = time.Now().Add(-time.Duration(cmd.Since))
+	}
+	entries = filterResultIndexEntries(entries, cmd.Target, since)
+	entries = pageResultIndexEntries(entries, cmd.Offset, cmd.Limit)
+
+	return outputHelper(ctx, cmd.Output, nil, func(format string, tmpl *template.Template, limits *textOutputLimits) (string, error) {
+		return formatResultIndexEntries(entries, format)
+	})
+}
+
+// filterResultIndexEntries returns the entries matching the given target name and started after since. Empty filter
+// values match all entries.
+func filterResultIndexEntries(entries []result.CommandResultIndexEntry, target string, since time.Time) []result.CommandResultIndexEntry {
+	var ret []result.CommandResultIndexEntry
+	for _, e := range entries {
+		if target != "" && e.TargetKey.TargetName != target {
+			continue
+		}
+		if !since.IsZero() && e.StartTime.Time.Before(since) {
+			continue
+		}
+		ret = append(ret, e)
+	}
+	return ret
+}
+
+// pageResultIndexEntries skips the first offset entries and returns at most limit entries. A limit of 0 means no limit.
+func pageResultIndexEntries(entries []result.CommandResultIndexEntry, offset int, limit int) []result.CommandResultIndexEntry {
+	if offset >= len(entries) {
+		return nil
+	}
+	entries = entries[offset:]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+func formatResultIndexEntries(entries []result.CommandResultIndexEntry, format string) (string, error) {
+	switch format {
+	case "text":
+		var t utils.PrettyTable
+		t.AddRow("ID", "COMMAND", "TARGET", "CLUSTER", "START TIME", "NEW", "CHANGED", "DELETED", "ORPHAN", "ERRORS", "WARNINGS")
+		for _, e := range entries {
+			t.AddRow(e.Id, e.Command, e.TargetKey.TargetName, e.ClusterId, e.StartTime.UTC().Format("2006-01-02 15:04:05"),
+				fmt.Sprint(e.Counts.NewObjects), fmt.Sprint(e.Counts.ChangedObjects), fmt.Sprint(e.Counts.DeletedObjects),
+				fmt.Sprint(e.Counts.OrphanObjects), fmt.Sprint(e.Counts.Errors), fmt.Sprint(e.Counts.Warnings))
+		}
+		return t.Render(nil), nil
+	case "yaml":
+		return yaml.WriteYamlString(entries)
+	case "json":
+		return formatJson(entries)
+	default:
+		return "", fmt.Errorf("invalid format: %s", format)
+	}
+}
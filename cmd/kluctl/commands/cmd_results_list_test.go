@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func buildTestIndexEntries(now time.Time) []result.CommandResultIndexEntry {
+	var ret []result.CommandResultIndexEntry
+	for i, target := range []string{"a", "b", "a", "b", "a"} {
+		ret = append(ret, result.CommandResultIndexEntry{
+			Id:        string(rune('0' + i)),
+			TargetKey: result.TargetKey{TargetName: target},
+			StartTime: metav1.NewTime(now.Add(-time.Duration(i) * time.Hour)),
+		})
+	}
+	return ret
+}
+
+func getIndexEntryIds(entries []result.CommandResultIndexEntry) []string {
+	var ret []string
+	for _, e := range entries {
+		ret = append(ret, e.Id)
+	}
+	return ret
+}
+
+func TestFilterResultIndexEntries(t *testing.T) {
+	now := time.Now()
+	entries := buildTestIndexEntries(now)
+
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, getIndexEntryIds(filterResultIndexEntries(entries, "", time.Time{})))
+	assert.Equal(t, []string{"0", "2", "4"}, getIndexEntryIds(filterResultIndexEntries(entries, "a", time.Time{})))
+	assert.Equal(t, []string{"0", "1", "2"}, getIndexEntryIds(filterResultIndexEntries(entries, "", now.Add(-150*time.Minute))))
+	assert.Equal(t, []string{"1"}, getIndexEntryIds(filterResultIndexEntries(entries, "b", now.Add(-150*time.Minute))))
+	assert.Empty(t, filterResultIndexEntries(entries, "c", time.Time{}))
+}
+
+func TestPageResultIndexEntries(t *testing.T) {
+	entries := buildTestIndexEntries(time.Now())
+
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, getIndexEntryIds(pageResultIndexEntries(entries, 0, 0)))
+	assert.Equal(t, []string{"0", "1"}, getIndexEntryIds(pageResultIndexEntries(entries, 0, 2)))
+	assert.Equal(t, []string{"2", "3"}, getIndexEntryIds(pageResultIndexEntries(entries, 2, 2)))
+	assert.Equal(t, []string{"4"}, getIndexEntryIds(pageResultIndexEntries(entries, 4, 2)))
+	assert.Empty(t, pageResultIndexEntries(entries, 5, 2))
+}
+
+func TestSetResultIdArg(t *testing.T) {
+	var id string
+	assert.ErrorContains(t, setResultIdArg(&id, nil), "expected a command result id")
+	assert.NoError(t, setResultIdArg(&id, []string{"x"}))
+	assert.Equal(t, "x", id)
+
+	id = "y"
+	assert.NoError(t, setResultIdArg(&id, nil))
+	assert.Equal(t, "y", id)
+	assert.ErrorContains(t, setResultIdArg(&id, []string{"x"}), "conflicting")
+	assert.ErrorContains(t, setResultIdArg(&id, []string{"x", "z"}), "at most one")
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type resultsPruneCmd struct {
+	Kubeconfig args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context    string                `group:"misc" help:"The kubernetes context to use. Defaults to the current context."`
+
+	args.CommandResultReadOnlyFlags
+
+	KeepPerTarget int          `group:"results" help:"Keep this many of the most recent command results per target."`
+	OlderThan     args.AgeType `group:"results" help:"Remove all command results that were started before this duration, e.g. '30d' or '12h'."`
+	DryRun        bool         `group:"misc" help:"Only show which command results would be removed, without removing them."`
+
+	args.OutputFlags
+}
+
+func (cmd *resultsPruneCmd) Help() string {
+	return `Removes old command results from the result store of the cluster.
+
+At least one of --keep-per-target and --older-than must be specified. If both are specified, a command result is
+removed when it exceeds the number of results to keep or when it is older than the given age. Results are grouped
+by project and target. All parts of a removed result, including its entry in the result index, are removed.
+
+Only results stored in the namespace given by --command-result-namespace are considered. Use --dry-run to see what
+would be removed.
+`
+}
+
+func (cmd *resultsPruneCmd) Run(ctx context.Context) error {
+	if !cmd.DryRun {
+		if err := checkNotReadOnly(ctx, "results prune"); err != nil {
+			return err
+		}
+	}
+
+	policy := results.RetentionPolicy{
+		KeepPerTarget: cmd.KeepPerTarget,
+		MaxAge:        time.Duration(cmd.OlderThan),
+	}
+	if policy.IsEmpty() {
+		return fmt.Errorf("at least one of --keep-per-target and --older-than must be specified")
+	}
+
+	r := clientcmd.NewDefaultClientConfigLoadingRules()
+	r.ExplicitPath = cmd.Kubeconfig.String()
+	configOverrides := &clientcmd.ConfigOverrides{
+		CurrentContext: cmd.Context,
+	}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(r, configOverrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+	_, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	var store results.ResultStore
+	if cmd.DryRun {
+		store, err = buildResultStoreRO(ctx, config, mapper, &cmd.CommandResultReadOnlyFlags)
+	} else {
+		flags := args.CommandResultFlags{
+			CommandResultReadOnlyFlags: cmd.CommandResultReadOnlyFlags,
+			CommandResultWriteFlags:    args.CommandResultWriteFlags{WriteCommandResult: true},
+		}
+		store, err = buildResultStoreRW(ctx, config, mapper, &flags, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := results.PruneCommandResults(store, results.ListResultSummariesOptions{
+		Namespace: cmd.CommandResultNamespace,
+	}, policy, cmd.DryRun, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return outputHelper(ctx, cmd.Output, nil, func(format string, tmpl *template.Template, limits *textOutputLimits) (string, error) {
+		return formatPruneReport(report, format)
+	})
+}
+
+func formatPruneReport(report *results.PruneReport, format string) (string, error) {
+	switch format {
+	case "text":
+		removedHeader := "REMOVED"
+		if report.DryRun {
+			removedHeader = "WOULD REMOVE"
+		}
+		var t utils.PrettyTable
+		t.AddRow("PROJECT", "TARGET", "DISCRIMINATOR", "CLUSTER", removedHeader, "KEPT")
+		removed := 0
+		for _, x := range report.Targets {
+			project := x.ProjectKey.RepoKey.String()
+			if x.ProjectKey.SubDir != "" {
+				project += "/" + x.ProjectKey.SubDir
+			}
+			t.AddRow(project, x.TargetKey.TargetName, x.TargetKey.Discriminator, x.TargetKey.ClusterId,
+				fmt.Sprint(x.Removed), fmt.Sprint(x.Kept))
+			removed += x.Removed
+		}
+		s := t.Render([]int{-1, -1, -1, -1, -1, -1})
+		if report.DryRun {
+			s += fmt.Sprintf("\n%d command results would be removed\n", removed)
+		} else {
+			s += fmt.Sprintf("\n%d command results removed\n", removed)
+		}
+		return s, nil
+	case "yaml":
+		return yaml.WriteYamlString(report)
+	case "json":
+		return formatJson(report)
+	default:
+		return "", fmt.Errorf("invalid format: %s", format)
+	}
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+type resultsRestoreObjectCmd struct {
+	Kubeconfig args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context    string                `group:"misc" help:"The kubernetes context to use. Defaults to the current context."`
+
+	args.CommandResultS3Flags
+
+	ResultId string `group:"misc" help:"The ID of the command result that recorded the deletion." required:"true"`
+	Object   string `group:"misc" help:"The deleted object to restore, in the same format as printed in command results, e.g. 'my-namespace/ConfigMap/my-config'." required:"true"`
+
+	args.YesFlags
+	args.DryRunFlags
+}
+
+func (cmd *resultsRestoreObjectCmd) Help() string {
+	return `Re-applies the last live manifest of an object that got deleted or pruned by the given command result.
+
+Manifests are only available if they were captured while deleting, which is the default unless
+--no-capture-deleted-manifests was passed or the manifest exceeded --max-deleted-manifest-size. Server-populated
+fields (e.g. status, managedFields and ownerReferences) are not part of the captured manifests. Secrets that were
+obfuscated in the command result and objects with fields that were obfuscated by obfuscation rules can not be restored.
+
+The object is not restored if it already exists.
+`
+}
+
+func (cmd *resultsRestoreObjectCmd) Run(ctx context.Context) error {
+	if !cmd.DryRun {
+		if err := checkNotReadOnly(ctx, "results restore-object"); err != nil {
+			return err
+		}
+	}
+
+	var contexts []string
+	if cmd.Context != "" {
+		contexts = append(contexts, cmd.Context)
+	}
+	stores, configs, err := createResultStores(ctx, cmd.Kubeconfig.String(), contexts, false, false, &cmd.CommandResultS3Flags)
+	if err != nil {
+		return err
+	}
+
+	// the object storage result store is appended after the cluster stores if configured
+	cr, err := stores[len(stores)-1].GetCommandResult(results.GetCommandResultOptions{
+		Id: cmd.ResultId,
+	})
+	if err != nil {
+		return err
+	}
+	if cr == nil {
+		return fmt.Errorf("command result %s not found", cmd.ResultId)
+	}
+
+	o, err := findDeletedManifest(cr, cmd.Object)
+	if err != nil {
+		return err
+	}
+	ref := o.Ref
+
+	discovery, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, configs[0])
+	if err != nil {
+		return err
+	}
+	k, err := k8s.NewK8sCluster(ctx, configs[0], discovery, mapper, cmd.DryRun)
+	if err != nil {
+		return err
+	}
+
+	existing, _, err := k.GetSingleObject(ref)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%s already exists and is not restored", ref.String())
+	}
+
+	if !cmd.Yes && !cmd.DryRun {
+		if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you really want to restore %s?", ref.String())) {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	_, apiWarnings, err := k.ApplyObject(o.DeletedManifest, k8s.PatchOptions{})
+	for _, w := range apiWarnings {
+		status.Warningf(ctx, "%s: %s", ref.String(), w.Text)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cmd.DryRun {
+		status.Infof(ctx, "Restored %s (dry-run)", ref.String())
+	} else {
+		status.Infof(ctx, "Restored %s", ref.String())
+	}
+	return nil
+}
+
+// findDeletedManifest returns the deleted object matching the given ref string, which must have a captured manifest
+func findDeletedManifest(cr *result.CommandResult, refStr string) (*result.ResultObject, error) {
+	var matches []*result.ResultObject
+	for i := range cr.Objects {
+		o := &cr.Objects[i]
+		if o.Deleted && o.Ref.String() == refStr {
+			matches = append(matches, o)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("command result %s contains no deleted object %s", cr.Id, refStr)
+	}
+	if len(matches) > 1 {
+		var gvks []string
+		for _, o := range matches {
+			gvks = append(gvks, o.Ref.GroupVersionKind().String())
+		}
+		return nil, fmt.Errorf("deleted object %s is ambiguous, it matches objects with the following kinds: %s", refStr, strings.Join(gvks, "; "))
+	}
+
+	o := matches[0]
+	if o.DeletedManifest == nil {
+		return nil, fmt.Errorf("the manifest of the deleted object %s was not captured", refStr)
+	}
+	if diff.IsObfuscatedSecret(o.DeletedManifest) {
+		return nil, fmt.Errorf("the manifest of the deleted object %s contains obfuscated secret data and can not be restored", refStr)
+	}
+	if o.ObfuscatedByRules {
+		return nil, fmt.Errorf("the manifest of the deleted object %s contains fields obfuscated by obfuscation rules and can not be restored", refStr)
+	}
+	return o, nil
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/results/htmlreport"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+type resultsShowCmd struct {
+	Kubeconfig args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context    string                `group:"misc" help:"The kubernetes context to use. Defaults to the current context."`
+
+	args.CommandResultS3Flags
+
+	ResultId string                `group:"misc" help:"The ID of the command result to show. Can also be passed as positional argument."`
+	FromFile args.ExistingFileType `group:"misc" help:"Show the command result from the given archive instead of the result store. Archives are written by 'kluctl results export' and the 'archive' output format."`
+
+	Format      string `group:"misc" help:"The format to render the command result in. Can be 'html' or 'text'. The 'text' format is the same as printed by the command that produced the result." default:"html"`
+	ShortOutput bool   `group:"misc" help:"When using the 'text' format, only names of changed objects are shown instead of showing all changes."`
+
+	Serve        bool   `group:"misc" help:"Start a temporary local HTTP server that serves the report instead of writing it to stdout. The server keeps running until Ctrl-C is pressed."`
+	ServeAddress string `group:"misc" help:"The address to bind the server to. Binds to a random port on localhost by default." default:"127.0.0.1:0"`
+}
+
+func (cmd *resultsShowCmd) Help() string {
+	return `Renders a command result from the result store as HTML report, containing a summary, the list of
+objects and collapsible diffs of all changed objects. This is the same report as produced by the 'html'
+output format.
+
+Pass '--format text' to render the command result as text instead, exactly as it was printed by the command that
+produced it. --short-output can be used to only show the names of changed objects.
+
+When --serve is passed, a temporary HTTP server is started that serves the report until Ctrl-C is pressed.
+No state is persisted by the server.
+
+Pass '--from-file result.tar.gz' to show a command result from an archive instead, which does not require access to
+the cluster or result store.
+`
+}
+
+func (cmd *resultsShowCmd) ArgsUsage() string {
+	return "[RESULT_ID]"
+}
+
+func (cmd *resultsShowCmd) SetArgs(args []string) error {
+	if cmd.FromFile != "" {
+		if len(args) != 0 || cmd.ResultId != "" {
+			return fmt.Errorf("a command result id can't be passed together with --from-file")
+		}
+		return nil
+	}
+	return setResultIdArg(&cmd.ResultId, args)
+}
+
+func (cmd *resultsShowCmd) Run(ctx context.Context) error {
+	switch cmd.Format {
+	case "html", "text":
+	default:
+		return fmt.Errorf("invalid format: %s", cmd.Format)
+	}
+	if cmd.Serve && cmd.Format != "html" {
+		return fmt.Errorf("--serve is only supported with the 'html' format")
+	}
+
+	cr, err := cmd.loadCommandResult(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Format == "text" {
+		return outputCommandResult2(ctx, args.OutputFormatFlags{
+			OutputFormat: []string{"text"},
+			ShortOutput:  cmd.ShortOutput,
+			Full:         true,
+		}, cr, nil)
+	}
+
+	if cmd.Serve {
+		return htmlreport.Serve(ctx, cmd.ServeAddress, cr)
+	}
+
+	s, err := htmlreport.RenderString(cr)
+	if err != nil {
+		return err
+	}
+	status.Flush(ctx)
+	_, err = getStdout(ctx).Write([]byte(s))
+	return err
+}
+
+func (cmd *resultsShowCmd) loadCommandResult(ctx context.Context) (*result.CommandResult, error) {
+	if cmd.FromFile != "" {
+		_, cr, err := results.ReadResultArchiveFile(cmd.FromFile.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", cmd.FromFile.String(), err)
+		}
+		return cr, nil
+	}
+
+	store, err := createResultStore(ctx, cmd.Kubeconfig.String(), cmd.Context, &cmd.CommandResultS3Flags)
+	if err != nil {
+		return nil, err
+	}
+	cr, err := store.GetCommandResult(results.GetCommandResultOptions{
+		Id: cmd.ResultId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cr == nil {
+		return nil, fmt.Errorf("command result %s not found", cmd.ResultId)
+	}
+	return cr, nil
+}
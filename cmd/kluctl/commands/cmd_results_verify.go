@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+)
+
+type resultsVerifyCmd struct {
+	Kubeconfig args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
+	Context    string                `group:"misc" help:"The kubernetes context to use. Defaults to the current context."`
+
+	args.CommandResultS3Flags
+
+	Key              args.ExistingFileType `group:"misc" help:"The PEM encoded public key (e.g. a cosign.pub) that the command result must be signed with. If omitted, the signature is only checked against the public key stored in the command result, which is reported as 'valid (untrusted key)' and causes the command to fail."`
+	RequireSignature bool                  `group:"misc" help:"Fail if the command result is unsigned."`
+
+	resultId string
+}
+
+func (cmd *resultsVerifyCmd) Help() string {
+	return `Verifies the signature of a command result from the result store and reports the key it was signed with.
+
+Command results are only signed when the command that produced them was invoked with --sign-command-result-key.
+Results without a signature (e.g. results written by older versions of kluctl) are reported as unsigned. The
+command fails if the signature is invalid, which means that the command result was modified after it was signed.
+
+The public key that the result is expected to be signed with must be passed via --key. Without it, the signature can
+only be checked against the public key stored in the command result itself, which does not prove anything, as a
+modified result could simply be re-signed with another key. Such results are reported as 'valid (untrusted key)' and
+the command fails.
+
+Only key based signing is supported. Keyless signing via OIDC identities (Fulcio/Rekor) is not supported.
+`
+}
+
+func (cmd *resultsVerifyCmd) ArgsUsage() string {
+	return "RESULT_ID"
+}
+
+func (cmd *resultsVerifyCmd) SetArgs(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one command result id")
+	}
+	cmd.resultId = args[0]
+	return nil
+}
+
+func (cmd *resultsVerifyCmd) Run(ctx context.Context) error {
+	var trustedKey crypto.PublicKey
+	if cmd.Key != "" {
+		var err error
+		trustedKey, err = results.LoadPublicKey(cmd.Key.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	store, err := createResultStore(ctx, cmd.Kubeconfig.String(), cmd.Context, &cmd.CommandResultS3Flags)
+	if err != nil {
+		return err
+	}
+
+	cr, err := store.GetCommandResult(results.GetCommandResultOptions{
+		Id: cmd.resultId,
+	})
+	if err != nil {
+		return err
+	}
+
+	vr := results.VerifyCommandResult(cr, trustedKey)
+	switch vr.Status {
+	case results.VerifyStatusValid:
+		return outputResult(ctx, nil, fmt.Sprintf("Command result %s: %s, signed by key %s\n", cmd.resultId, vr.Status, vr.KeyId), false)
+	case results.VerifyStatusUnsigned:
+		err = outputResult(ctx, nil, fmt.Sprintf("Command result %s: %s\n", cmd.resultId, vr.Status), false)
+		if err != nil {
+			return err
+		}
+		if cmd.RequireSignature {
+			return fmt.Errorf("command result %s is not signed", cmd.resultId)
+		}
+		return nil
+	case results.VerifyStatusUntrusted:
+		_ = outputResult(ctx, nil, fmt.Sprintf("Command result %s: %s, signed by key %s\n", cmd.resultId, vr.Status, vr.KeyId), false)
+		return fmt.Errorf("command result %s was not verified against a trusted key, pass the expected public key via --key", cmd.resultId)
+	default:
+		_ = outputResult(ctx, nil, fmt.Sprintf("Command result %s: %s, %s\n", cmd.resultId, vr.Status, vr.Message), false)
+		return fmt.Errorf("signature of command result %s is invalid: %s", cmd.resultId, vr.Message)
+	}
+}
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+type rollbackCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.YesFlags
+	args.DryRunFlags
+	args.ForceApplyFlags
+	args.ReplaceOnErrorFlags
+	args.DiffNormalizationFlags
+	args.AbortOnErrorFlags
+	args.DeletedManifestsFlags
+	args.HookFlags
+	args.OutputFormatFlags
+	args.RenderOutputDirFlags
+	args.CommandResultFlags
+	args.NotifyFlags
+	args.ResultWebhookFlags
+
+	ToResult string `group:"misc" help:"The id of the command result to roll back to. Defaults to the previous successful deployment of the target."`
+	NoWait   bool   `group:"misc" help:"Don't wait for objects readiness."`
+	Prune    bool   `group:"misc" help:"Prune objects that were added after the command result was created without asking for confirmation."`
+}
+
+func (cmd *rollbackCmd) Help() string {
+	return `This command re-applies the rendered objects that were recorded in a stored command result. The project is
+still loaded to determine the target, discriminator and project configuration, but the objects themselves are
+taken from the stored result. Hooks are not executed.
+
+Objects that were deployed after the command result was created are detected via the discriminator and can
+optionally be pruned. Rolling back to a result that was deployed with a different discriminator is refused.
+
+A new command result is written, which references the command result that was rolled back to.
+`
+}
+
+func (cmd *rollbackCmd) Run(ctx context.Context) error {
+	if err := checkNotReadOnly(ctx, "rollback"); err != nil {
+		return err
+	}
+
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		gitCredentials:       cmd.GitCredentials,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		dryRunArgs:           &cmd.DryRunFlags,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		commandResultFlags:   &cmd.CommandResultFlags,
+		notifyFlags:          &cmd.NotifyFlags,
+		resultWebhookFlags:   &cmd.ResultWebhookFlags,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		return cmd.runCmdRollback(ctx, cmdCtx)
+	})
+}
+
+func (cmd *rollbackCmd) runCmdRollback(ctx context.Context, cmdCtx *commandCtx) error {
+	cmd.OutputFormatFlags = resolveOutputFormatFlags(ctx, cmdCtx, cmd.OutputFormatFlags)
+
+	resultStore, err := cmd.getResultStore(ctx, cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	cmd2 := commands.NewRollbackCommand(cmdCtx.targetCtx, resultStore)
+	cmd2.SourceResultId = cmd.ToResult
+	cmd2.ForceApply = cmd.ForceApply
+	cmd2.ReplaceOnError = cmd.ReplaceOnError
+	cmd2.ForceReplaceOnError = cmd.ForceReplaceOnError
+	cmd2.NoListNormalization = cmd.NoListNormalization
+	cmd2.AbortOnError = cmd.AbortOnError
+	cmd2.ReadinessTimeout = cmd.ReadinessTimeout
+	cmd2.NoWait = cmd.NoWait
+	cmd2.WaitPrune = !cmd.NoWait
+	cmd2.DeletedManifests = buildDeletedManifestsOptions(cmd.DeletedManifestsFlags)
+
+	var confirmation *result.ConfirmationInfo
+	diffCb := func(diffResult *result.CommandResult) error {
+		var err error
+		confirmation, err = cmd.diffResultCb(ctx, cmdCtx, diffResult)
+		return err
+	}
+	if cmd.DryRun || (cmd.Yes && cmdCtx.targetCtx.Target.Confirmation == nil) {
+		// in dry-run mode, the result itself contains the full diff. With --yes, the diff is only needed to apply the
+		// confirmation policy.
+		diffCb = nil
+	}
+
+	result := cmd2.Run(diffCb, func(refs []k8s2.ObjectRef) bool {
+		return cmd.confirmPrune(ctx, refs)
+	})
+	result.Confirmation = confirmation
+	err = outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) != 0 {
+		return fmt.Errorf("command failed")
+	}
+	return nil
+}
+
+// getResultStore returns the result store of the command context or a read-only one if writing of command results
+// is disabled, as a rollback always needs to read the result to roll back to
+func (cmd *rollbackCmd) getResultStore(ctx context.Context, cmdCtx *commandCtx) (results.ResultStore, error) {
+	if cmdCtx.resultStore != nil {
+		return cmdCtx.resultStore, nil
+	}
+	k := cmdCtx.targetCtx.SharedContext.K
+	if k == nil {
+		return nil, nil
+	}
+	restConfig, err := k.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := k.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return buildResultStoreRO(ctx, restConfig, mapper, &cmd.CommandResultReadOnlyFlags)
+}
+
+func (cmd *rollbackCmd) diffResultCb(ctx context.Context, cmdCtx *commandCtx, diffResult *result.CommandResult) (*result.ConfirmationInfo, error) {
+	if cmd.Yes {
+		return applyConfirmationPolicy(ctx, cmdCtx, diffResult, false)
+	}
+
+	flags := cmd.OutputFormatFlags
+	flags.OutputFormat = nil // use default output format
+
+	err := outputCommandResult(ctx, cmdCtx, flags, diffResult, false)
+	if err != nil {
+		return nil, err
+	}
+	confirmation, err := applyConfirmationPolicy(ctx, cmdCtx, diffResult, true)
+	if err != nil {
+		return confirmation, err
+	}
+	if len(diffResult.Errors) != 0 {
+		if !prompts.AskForConfirmation(ctx, "The diff resulted in errors, do you still want to proceed?") {
+			return confirmation, fmt.Errorf("aborted")
+		}
+	} else {
+		if !prompts.AskForConfirmation(ctx, "The diff succeeded, do you want to proceed with the rollback?") {
+			return confirmation, fmt.Errorf("aborted")
+		}
+	}
+	return confirmation, nil
+}
+
+func (cmd *rollbackCmd) confirmPrune(ctx context.Context, refs []k8s2.ObjectRef) bool {
+	_, _ = getStderr(ctx).WriteString("The following objects were added after the command result was created:\n")
+	for _, ref := range refs {
+		_, _ = getStderr(ctx).WriteString(fmt.Sprintf("  %s\n", ref.String()))
+	}
+	if cmd.Prune {
+		return true
+	}
+	if cmd.Yes || cmd.DryRun {
+		status.Info(ctx, "Not pruning added objects, use --prune to prune them")
+		return false
+	}
+	return prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you want to prune %d objects?", len(refs)))
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"github.com/kluctl/kluctl/v2/pkg/selfupdate"
+	"github.com/kluctl/kluctl/v2/pkg/version"
+)
+
+type selfUpdateCmd struct {
+	args.YesFlags
+
+	Force bool `group:"misc" help:"Re-install the latest release even if the current version is up-to-date."`
+}
+
+func (cmd *selfUpdateCmd) Help() string {
+	return `Downloads the latest kluctl release for the current platform from GitHub, verifies it against the
+checksums published with the release and then atomically replaces the running executable. The checksums file must be
+signed with the release signing key that is built into kluctl, so that a compromised release download can't replace
+the executable.
+
+If kluctl was installed via a package manager (e.g. Homebrew) or the install location is not writable, the
+executable is not touched and instructions on how to update are printed instead.`
+}
+
+func (cmd *selfUpdateCmd) Run(ctx context.Context) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	exePath, err = filepath.EvalSymlinks(exePath)
+	if err != nil {
+		return err
+	}
+	err = selfupdate.CheckInstallLocation(exePath)
+	if err != nil {
+		return err
+	}
+
+	c := selfupdate.NewClient("")
+	release, err := c.GetLatestRelease(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to determine latest release: %w", err)
+	}
+	latest, err := release.Version()
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Force && version.GetVersion() != "0.0.0" {
+		if cur, err := semver.NewVersion(version.GetVersion()); err == nil && !cur.LessThan(latest) {
+			status.Infof(ctx, "kluctl %s is already up-to-date", cur.String())
+			return nil
+		}
+	}
+
+	if !cmd.Yes {
+		if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Update %s from version %s to %s?", exePath, version.GetVersion(), latest.String())) {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	s := status.Startf(ctx, "Updating kluctl to %s", latest.String())
+	defer s.Failed()
+	err = c.Update(ctx, release, exePath, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	s.Success()
+	return nil
+}
@@ -3,9 +3,14 @@ package commands
 import (
 	"context"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"time"
 )
 
@@ -19,20 +24,38 @@ type validateCmd struct {
 	args.HelmCredentials
 	args.RegistryCredentials
 	args.OutputFlags
+	args.TableWidthFlags
 	args.RenderOutputDirFlags
+	args.CommandResultReadOnlyFlags
+	args.ResultWebhookFlags
 
+	Result           string        `group:"misc" help:"Validate the objects recorded in the given command result (fetched from the result store) instead of rendering the project. The project source is not needed in this case."`
 	Wait             time.Duration `group:"misc" help:"Wait for the given amount of time until the deployment validates"`
 	Sleep            time.Duration `group:"misc" help:"Sleep duration between validation attempts" default:"5s"`
 	WarningsAsErrors bool          `group:"misc" help:"Consider warnings as failures"`
+	NoProbes         bool          `group:"misc" help:"Don't execute HTTP probes declared via the kluctl.io/validate-probe-url annotation"`
 }
 
 func (cmd *validateCmd) Help() string {
 	return `This means that all objects are retrieved from the cluster and checked for readiness.
 
+When --result is specified, the project is not loaded at all. Instead, the objects recorded in the given command
+result are fetched from the result store and validated against the live cluster. Objects that do not exist anymore
+are reported as warnings.
+
 TODO: This needs to be better documented!`
 }
 
+type validateRunner interface {
+	Run(ctx context.Context) *result.ValidateResult
+	ForgetRemoteObject(ref k8s.ObjectRef)
+}
+
 func (cmd *validateCmd) Run(ctx context.Context) error {
+	if cmd.Result != "" {
+		return cmd.runForStoredResult(ctx)
+	}
+
 	ptArgs := projectTargetCommandArgs{
 		projectFlags:         cmd.ProjectFlags,
 		kubeconfigFlags:      cmd.KubeconfigFlags,
@@ -43,21 +66,80 @@ func (cmd *validateCmd) Run(ctx context.Context) error {
 		helmCredentials:      cmd.HelmCredentials,
 		registryCredentials:  cmd.RegistryCredentials,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		resultWebhookFlags:   &cmd.ResultWebhookFlags,
 	}
 
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		cmd2 := commands.NewValidateCommand("", cmdCtx.targetCtx)
+		cmd2.NoProbes = cmd.NoProbes
 		return cmd.doValidate(ctx, cmdCtx, cmd2)
 	})
 }
 
-func (cmd *validateCmd) doValidate(ctx context.Context, cmdCtx *commandCtx, cmd2 *commands.ValidateCommand) error {
+func (cmd *validateCmd) runForStoredResult(ctx context.Context) error {
+	var contextOverride *string
+	if cmd.Context != "" {
+		contextOverride = &cmd.Context
+	}
+	restConfig, _, err := clientConfigGetter(ctx, &cmd.KubeconfigFlags, false)(contextOverride)
+	if err != nil {
+		return err
+	}
+	discovery, mapper, err := k8s2.CreateDiscoveryAndMapper(ctx, restConfig)
+	if err != nil {
+		return err
+	}
+	k, err := k8s2.NewK8sCluster(ctx, restConfig, discovery, mapper, true)
+	if err != nil {
+		return err
+	}
+
+	resultStore, err := buildResultStoreRO(ctx, restConfig, mapper, &cmd.CommandResultReadOnlyFlags)
+	if err != nil {
+		return err
+	}
+	cr, err := resultStore.GetCommandResult(results.GetCommandResultOptions{
+		Id: cmd.Result,
+	})
+	if err != nil {
+		return err
+	}
+	if cr == nil {
+		return fmt.Errorf("command result %s not found", cmd.Result)
+	}
+
+	// validating against another cluster would report bogus results, as the recorded objects most likely don't exist
+	if cr.ClusterInfo.ClusterId != "" {
+		c, err := k.ToClient()
+		if err != nil {
+			return err
+		}
+		clusterId, _, err := k8s2.DetermineClusterId(ctx, c, restConfig)
+		if err != nil {
+			return fmt.Errorf("failed to determine cluster ID: %w", err)
+		}
+		if clusterId != cr.ClusterInfo.ClusterId {
+			return fmt.Errorf("command result %s was recorded on cluster %s, but the current cluster is %s", cmd.Result, cr.ClusterInfo.ClusterId, clusterId)
+		}
+	}
+
+	cmdCtx := &commandCtx{
+		resultId:           uuid.NewString(),
+		resultWebhookFlags: &cmd.ResultWebhookFlags,
+	}
+	cmd2 := commands.NewValidateStoredResultCommand(ctx, k, cr)
+	cmd2.NoProbes = cmd.NoProbes
+	return cmd.doValidate(ctx, cmdCtx, cmd2)
+}
+
+func (cmd *validateCmd) doValidate(ctx context.Context, cmdCtx *commandCtx, cmd2 validateRunner) error {
 	startTime := time.Now()
 	for true {
 		result := cmd2.Run(ctx)
 		failed := len(result.Errors) != 0 || (cmd.WarningsAsErrors && len(result.Warnings) != 0)
+		final := !failed || cmd.Wait <= 0 || time.Now().Sub(startTime) > cmd.Wait
 
-		err := outputValidateResult(ctx, cmdCtx, cmd.Output, result)
+		err := outputValidateResult(ctx, cmdCtx, cmd.Output, cmd.TableWidthFlags, result, final)
 		if err != nil {
 			return err
 		}
@@ -67,7 +149,7 @@ func (cmd *validateCmd) doValidate(ctx context.Context, cmdCtx *commandCtx, cmd2
 			return nil
 		}
 
-		if cmd.Wait <= 0 || time.Now().Sub(startTime) > cmd.Wait {
+		if final {
 			return fmt.Errorf("Validation failed")
 		}
 
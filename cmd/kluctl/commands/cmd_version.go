@@ -2,15 +2,33 @@ package commands
 
 import (
 	"context"
+	"fmt"
+
 	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/selfupdate"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/version"
 )
 
 type versionCmd struct {
+	CheckUpdate bool `group:"misc" help:"Check whether a newer kluctl release is available. This always queries the GitHub releases API, ignoring the cached result of the startup update check."`
 }
 
 func (cmd *versionCmd) Run(ctx context.Context) error {
 	status.Flush(ctx)
 	_, err := getStdout(ctx).WriteString(version.GetVersion() + "\n")
+	if err != nil || !cmd.CheckUpdate {
+		return err
+	}
+
+	r, err := selfupdate.NewClient("").CheckForUpdate(ctx, utils.GetCacheDir(ctx), version.GetVersion(), true)
+	if err != nil {
+		return fmt.Errorf("failed to check for updates: %w", err)
+	}
+	if r.UpdateAvailable() {
+		_, err = getStdout(ctx).WriteString(fmt.Sprintf("A newer version %s is available, run 'kluctl self-update' to update\n", r.LatestVersion.String()))
+	} else {
+		_, err = getStdout(ctx).WriteString("kluctl is up-to-date\n")
+	}
 	return err
 }
@@ -16,7 +16,28 @@ type webuiCmd struct {
 	Build webuiBuildCmd `cmd:"build" help:"Build the static Kluctl Webui"`
 }
 
-func createResultStores(ctx context.Context, kubeconfigOverride string, k8sContexts []string, allContexts bool, inCluster bool) ([]results.ResultStore, []*rest.Config, error) {
+// createResultStore returns the object storage result store if a bucket is configured in s3Flags and the in-cluster
+// result store of the given context otherwise
+func createResultStore(ctx context.Context, kubeconfigOverride string, k8sContext string, s3Flags *args.CommandResultS3Flags) (results.ResultStore, error) {
+	if s3Flags != nil && s3Flags.CommandResultS3Bucket != "" {
+		return buildResultStoreS3(ctx, s3Flags, false, 0, 0)
+	}
+
+	var contexts []string
+	if k8sContext != "" {
+		contexts = append(contexts, k8sContext)
+	}
+	stores, _, err := createResultStores(ctx, kubeconfigOverride, contexts, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	return stores[0], nil
+}
+
+// createResultStores creates the in-cluster result stores of all given contexts. If a bucket is configured in s3Flags,
+// the object storage result store is appended to the returned stores. The returned configs only contain the configs of
+// the in-cluster stores.
+func createResultStores(ctx context.Context, kubeconfigOverride string, k8sContexts []string, allContexts bool, inCluster bool, s3Flags *args.CommandResultS3Flags) ([]results.ResultStore, []*rest.Config, error) {
 	r := clientcmd.NewDefaultClientConfigLoadingRules()
 	r.ExplicitPath = kubeconfigOverride
 
@@ -67,6 +88,9 @@ func createResultStores(ctx context.Context, kubeconfigOverride string, k8sConte
 			if err != nil {
 				return err
 			}
+			if isReadOnly(ctx) {
+				config = k8s.WrapReadOnly(config)
+			}
 
 			_, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, config)
 			if err != nil {
@@ -87,5 +111,13 @@ func createResultStores(ctx context.Context, kubeconfigOverride string, k8sConte
 		return nil, nil, gh.ErrorOrNil()
 	}
 
+	if s3Flags != nil && s3Flags.CommandResultS3Bucket != "" {
+		store, err := buildResultStoreS3(ctx, s3Flags, false, 0, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		stores = append(stores, store)
+	}
+
 	return stores, configs, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/webui"
 	"time"
@@ -14,6 +15,8 @@ type webuiBuildCmd struct {
 	Context     []string `group:"misc" help:"List of kubernetes contexts to use. Defaults to the current context."`
 	AllContexts bool     `group:"misc" help:"Use all Kubernetes contexts found in the kubeconfig."`
 	MaxResults  int      `group:"misc" help:"Specify the maximum number of results per target." default:"1"`
+
+	args.CommandResultS3Flags
 }
 
 func (cmd *webuiBuildCmd) Help() string {
@@ -26,7 +29,7 @@ func (cmd *webuiBuildCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("this build of Kluctl does not have the webui embedded")
 	}
 
-	stores, _, err := createResultStores(ctx, "", cmd.Context, cmd.AllContexts, false)
+	stores, _, err := createResultStores(ctx, "", cmd.Context, cmd.AllContexts, false, &cmd.CommandResultS3Flags)
 	if err != nil {
 		return err
 	}
@@ -22,6 +22,8 @@ type webuiRunCmd struct {
 	Context     []string              `group:"misc" help:"List of kubernetes contexts to use."`
 	AllContexts bool                  `group:"misc" help:"Use all Kubernetes contexts found in the kubeconfig."`
 
+	args.CommandResultS3Flags
+
 	InCluster           bool   `group:"misc" help:"This enables in-cluster functionality. This also enforces authentication."`
 	InClusterContext    string `group:"misc" help:"The context to use fo in-cluster functionality."`
 	ControllerNamespace string `group:"misc" help:"The namespace where the controller runs in." default:"kluctl-system"`
@@ -147,7 +149,7 @@ func (cmd *webuiRunCmd) Run(ctx context.Context) error {
 		}
 	}
 
-	stores, configs, err := createResultStores(ctx, cmd.Kubeconfig.String(), cmd.Context, cmd.AllContexts, cmd.InCluster)
+	stores, configs, err := createResultStores(ctx, cmd.Kubeconfig.String(), cmd.Context, cmd.AllContexts, cmd.InCluster, &cmd.CommandResultS3Flags)
 	if err != nil {
 		return err
 	}
@@ -25,6 +25,17 @@ type runProvider interface {
 	Run(ctx context.Context) error
 }
 
+// positionalArgsProvider is implemented by commands that accept positional arguments
+type noOptDefValProvider interface {
+	NoOptDefVal() string
+}
+
+type positionalArgsProvider interface {
+	// ArgsUsage returns the usage string of the positional arguments, e.g. "RELEASE"
+	ArgsUsage() string
+	SetArgs(args []string) error
+}
+
 type rootCommand struct {
 	rootCmd    *commandAndGroups
 	groupInfos []groupInfo
@@ -67,9 +78,20 @@ func (c *rootCommand) buildCobraCmd(parent *commandAndGroups, cmdStruct interfac
 		},
 	}
 
+	argsP, hasArgs := cmdStruct.(positionalArgsProvider)
+	if hasArgs {
+		cg.cmd.Use = fmt.Sprintf("%s %s", name, argsP.ArgsUsage())
+	}
+
 	runP, ok := cmdStruct.(runProvider)
 	if ok {
 		cg.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			if hasArgs {
+				err := argsP.SetArgs(args)
+				if err != nil {
+					return err
+				}
+			}
 			return runP.Run(cmd.Context())
 		}
 	}
@@ -161,6 +183,7 @@ func (c *rootCommand) buildCobraArg(cg *commandAndGroups, f reflect.StructField,
 	defaultValue := f.Tag.Get("default")
 	required := f.Tag.Get("required") == "true"
 	skipEnv := f.Tag.Get("skipenv")
+	sensitive := f.Tag.Get("sensitive")
 
 	group := groupOverride
 	if group == "" {
@@ -174,6 +197,9 @@ func (c *rootCommand) buildCobraArg(cg *commandAndGroups, f reflect.StructField,
 	case pflag.Value:
 		v3 := v2.(pflag.Value)
 		cg.cmd.PersistentFlags().VarP(v3, name, shortFlag, help)
+		if x, ok := v3.(noOptDefValProvider); ok {
+			cg.cmd.PersistentFlags().Lookup(name).NoOptDefVal = x.NoOptDefVal()
+		}
 		switch v3.Type() {
 		case "existingfile":
 			exts := strings.Split(f.Tag.Get("exts"), ",")
@@ -234,6 +260,7 @@ func (c *rootCommand) buildCobraArg(cg *commandAndGroups, f reflect.StructField,
 	}
 
 	_ = cg.cmd.PersistentFlags().SetAnnotation(name, "skipenv", []string{skipEnv})
+	_ = cg.cmd.PersistentFlags().SetAnnotation(name, "sensitive", []string{sensitive})
 
 	return nil
 }
@@ -3,20 +3,54 @@ package commands
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
 	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/i18n"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/results/htmlreport"
+	"github.com/kluctl/kluctl/v2/pkg/results/mdreport"
+	"github.com/kluctl/kluctl/v2/pkg/results/patchreport"
+	"github.com/kluctl/kluctl/v2/pkg/results/tmplreport"
+	"github.com/kluctl/kluctl/v2/pkg/stats"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"io"
-	"os"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
 	"strings"
+	"text/template"
+	"time"
 )
 
-func formatCommandResultText(cr *result.CommandResult, short bool) string {
+// objectYamlOptions controls printing of the manifests of new, deleted and orphan objects
+type objectYamlOptions struct {
+	showNew     bool
+	showDeleted bool
+	maxLines    int
+}
+
+func newObjectYamlOptions(flags args.OutputFormatFlags) objectYamlOptions {
+	o := objectYamlOptions{
+		showNew:     flags.ShowNewObjectYaml,
+		showDeleted: flags.ShowDeletedObjectYaml,
+		maxLines:    flags.MaxObjectYamlLines,
+	}
+	if flags.Full {
+		o.maxLines = 0
+	}
+	return o
+}
+
+func formatCommandResultText(cr *result.CommandResult, short bool, showTimings bool, showIgnored bool, objectYaml objectYamlOptions, limits *textOutputLimits, msgs i18n.Catalog) string {
 	buf := bytes.NewBuffer(nil)
 
 	var newObjects []k8s.ObjectRef
@@ -24,8 +58,31 @@ func formatCommandResultText(cr *result.CommandResult, short bool) string {
 	var deletedObjects []k8s.ObjectRef
 	var orphanObjects []k8s.ObjectRef
 	var appliedHookObjects []k8s.ObjectRef
+	var migratedObjects []result.ResultObject
+	var movedObjects []result.ResultObject
+	var ignoredObjects []k8s.ObjectRef
+	var securityRelevantObjects []result.ResultObject
+	counts := result.CommandResultCounts{
+		Errors:   len(cr.Errors),
+		Warnings: len(cr.Warnings),
+	}
+
+	if cr.Command.Invocation != nil {
+		buf.WriteString(fmt.Sprintf("\n%s\n", msgs.Sprintf(i18n.MsgInvocation, formatInvocation(cr.Command.Invocation))))
+	}
+	if cr.Command.Partial {
+		buf.WriteString(fmt.Sprintf("\n%s\n", msgs.Sprintf(i18n.MsgPartialResult, cr.Command.PartialNote)))
+	} else if cr.Command.PartialNote != "" {
+		buf.WriteString(fmt.Sprintf("\n%s\n", msgs.Sprintf(i18n.MsgPartialNote, cr.Command.PartialNote)))
+	}
+	if cr.Command.RollbackSourceResultId != "" {
+		buf.WriteString(fmt.Sprintf("\n%s\n", msgs.Sprintf(i18n.MsgRolledBack, cr.Command.RollbackSourceResultId)))
+	}
+
+	headerLen := buf.Len()
 
 	for _, o := range cr.Objects {
+		counts.Add(o)
 		if o.New {
 			newObjects = append(newObjects, o.Ref)
 		}
@@ -41,14 +98,43 @@ func formatCommandResultText(cr *result.CommandResult, short bool) string {
 		if o.Hook {
 			appliedHookObjects = append(appliedHookObjects, o.Ref)
 		}
+		if o.OwnershipMigration != nil {
+			migratedObjects = append(migratedObjects, o)
+		}
+		if o.Moved != nil {
+			movedObjects = append(movedObjects, o)
+		}
+		if len(o.IgnoredChanges) != 0 {
+			ignoredObjects = append(ignoredObjects, o.Ref)
+		}
+		if o.SecurityRelevant {
+			securityRelevantObjects = append(securityRelevantObjects, o)
+		}
+	}
+
+	if len(securityRelevantObjects) != 0 {
+		prettySecurityRelevantObjects(buf, securityRelevantObjects, limits, msgs)
 	}
 
 	if len(newObjects) != 0 {
-		buf.WriteString("\nNew objects:\n")
+		writeHeading(buf, msgs, i18n.MsgNewObjects)
 		prettyObjectRefs(buf, newObjects)
+
+		if !short && objectYaml.showNew {
+			for _, o := range cr.Objects {
+				if !o.New {
+					continue
+				}
+				m := o.Rendered
+				if m == nil {
+					m = o.Applied
+				}
+				prettyManifest(buf, o.Ref, m, objectYaml.maxLines, limits, msgs)
+			}
+		}
 	}
 	if len(changedObjects) != 0 {
-		buf.WriteString("\nChanged objects:\n")
+		writeHeading(buf, msgs, i18n.MsgChangedObjects)
 		prettyObjectRefs(buf, changedObjects)
 
 		if !short {
@@ -60,36 +146,194 @@ func formatCommandResultText(cr *result.CommandResult, short bool) string {
 				if i != 0 {
 					buf.WriteString("\n")
 				}
-				prettyChanges(buf, o.Ref, o.Changes)
+				prettyChanges(buf, o.Ref, o.Changes, limits, msgs)
+			}
+		}
+	}
+
+	if showIgnored && len(ignoredObjects) != 0 {
+		writeHeading(buf, msgs, i18n.MsgIgnoredChanges)
+		prettyObjectRefs(buf, ignoredObjects)
+
+		if !short {
+			for _, o := range cr.Objects {
+				if len(o.IgnoredChanges) == 0 {
+					continue
+				}
+				buf.WriteString("\n")
+				prettyChanges(buf, o.Ref, o.IgnoredChanges, limits, msgs)
 			}
 		}
 	}
 
+	if len(movedObjects) != 0 {
+		writeHeading(buf, msgs, i18n.MsgMovedObjects)
+		for _, o := range movedObjects {
+			buf.WriteString(fmt.Sprintf("  %s\n", msgs.Sprintf(i18n.MsgMovedObject, o.Ref.String(), o.Moved.FromItem, o.Moved.ToItem)))
+		}
+	}
+
 	if len(deletedObjects) != 0 {
-		buf.WriteString("\nDeleted objects:\n")
+		if cr.Command.DryRun {
+			// deletions were only performed in dry-run mode
+			writeHeading(buf, msgs, i18n.MsgWouldDeleteObjects)
+		} else {
+			writeHeading(buf, msgs, i18n.MsgDeletedObjects)
+		}
 		prettyObjectRefs(buf, deletedObjects)
+
+		if !short && objectYaml.showDeleted {
+			for _, o := range cr.Objects {
+				if o.Deleted {
+					prettyManifest(buf, o.Ref, getLiveManifest(o), objectYaml.maxLines, limits, msgs)
+				}
+			}
+		}
 	}
 
-	if len(appliedHookObjects) != 0 {
-		buf.WriteString("\nApplied hooks:\n")
+	if cr.ResourceDelta != nil {
+		writeHeading(buf, msgs, i18n.MsgResourceDelta)
+		prettyResourceDelta(buf, cr.ResourceDelta.Total, msgs)
+	}
+
+	if !short && len(cr.Phases) != 0 {
+		// phases already contain the applied hooks
+		writeHeading(buf, msgs, i18n.MsgPhases)
+		prettyPhases(buf, cr.Phases, msgs)
+	} else if len(appliedHookObjects) != 0 {
+		writeHeading(buf, msgs, i18n.MsgAppliedHooks)
 		prettyObjectRefs(buf, appliedHookObjects)
 	}
 	if len(orphanObjects) != 0 {
-		buf.WriteString("\nOrphan objects:\n")
+		writeHeading(buf, msgs, i18n.MsgOrphanObjects)
 		prettyObjectRefs(buf, orphanObjects)
+
+		if !short && objectYaml.showDeleted {
+			for _, o := range cr.Objects {
+				if o.Orphan {
+					prettyManifest(buf, o.Ref, getLiveManifest(o), objectYaml.maxLines, limits, msgs)
+				}
+			}
+		}
+	}
+	if len(migratedObjects) != 0 {
+		writeHeading(buf, msgs, i18n.MsgTookOwnership)
+		var coOwned []result.ResultObject
+		for _, o := range migratedObjects {
+			if len(o.OwnershipMigration.From) != 0 {
+				buf.WriteString(fmt.Sprintf("  %s\n", msgs.Sprintf(i18n.MsgTookOwnershipFrom, o.Ref.String(), strings.Join(o.OwnershipMigration.From, ", "))))
+			} else {
+				buf.WriteString(fmt.Sprintf("  %s\n", msgs.Sprintf(i18n.MsgTookOwnershipAnn, o.Ref.String(), utils2.LastAppliedConfigurationAnnotation)))
+			}
+			if len(o.OwnershipMigration.CoOwners) != 0 {
+				coOwned = append(coOwned, o)
+			}
+		}
+		if len(coOwned) != 0 {
+			writeHeading(buf, msgs, i18n.MsgCoOwnedObjects)
+			for _, o := range coOwned {
+				buf.WriteString(fmt.Sprintf("  %s\n", msgs.Sprintf(i18n.MsgCoOwnedObject, o.Ref.String(), strings.Join(o.OwnershipMigration.CoOwners, ", "))))
+			}
+		}
+	}
+	if !short && len(cr.Fetches) != 0 {
+		writeHeading(buf, msgs, i18n.MsgFetches)
+		prettyFetches(buf, cr.Fetches)
+	}
+	if showTimings {
+		if timings := commandTimingEntries(cr.Timings); len(timings) != 0 {
+			writeHeading(buf, msgs, i18n.MsgTimings)
+			prettyTimingEntries(buf, "  ", timings)
+		}
+		slowest := findSlowestObjects(cr.Objects, maxSlowestObjects)
+		if len(slowest) != 0 {
+			writeHeading(buf, msgs, i18n.MsgSlowestObjects)
+			prettySlowestObjects(buf, slowest)
+		}
+	}
+	if len(cr.RerunJobs) != 0 {
+		writeHeading(buf, msgs, i18n.MsgRerunJobs)
+		for _, rj := range cr.RerunJobs {
+			buf.WriteString(fmt.Sprintf("  %s\n", msgs.Sprintf(i18n.MsgRerunJob, rj.Ref.String(), rj.Reason, rj.Status)))
+		}
+	}
+	if len(cr.OwnershipConflicts) != 0 {
+		writeHeading(buf, msgs, i18n.MsgOwnershipConflicts)
+		for _, c := range cr.OwnershipConflicts {
+			if c.OtherTarget != "" {
+				buf.WriteString(fmt.Sprintf("  %s\n", msgs.Sprintf(i18n.MsgConflictWithTarget, c.Ref.String(), c.OtherDiscriminator, c.OtherTarget, c.OtherResultId)))
+			} else {
+				buf.WriteString(fmt.Sprintf("  %s\n", msgs.Sprintf(i18n.MsgOwnershipConflict, c.Ref.String(), c.OtherDiscriminator)))
+			}
+		}
 	}
 
 	if len(cr.Warnings) != 0 {
-		buf.WriteString("\nWarnings:\n")
+		writeHeading(buf, msgs, i18n.MsgWarnings)
 		prettyErrors(buf, cr.Warnings)
 	}
 
 	if len(cr.Errors) != 0 {
-		buf.WriteString("\nErrors:\n")
+		writeHeading(buf, msgs, i18n.MsgErrors)
 		prettyErrors(buf, cr.Errors)
 	}
 
-	return buf.String()
+	header := buf.String()[:headerLen]
+	body := buf.String()[headerLen:]
+	if body == "" {
+		// avoid completely empty output, which looks like the command silently failed
+		return fmt.Sprintf("%s\n%s\n", header, msgs.Sprintf(i18n.MsgNoChanges))
+	}
+	summary := fmt.Sprintf("\n%s\n", formatCommandResultCounts(counts, msgs))
+	return header + summary + body + summary
+}
+
+func formatCommandResultCounts(c result.CommandResultCounts, msgs i18n.Catalog) string {
+	s := msgs.Sprintf(i18n.MsgSummary, c.NewObjects, c.ChangedObjects, c.DeletedObjects, c.OrphanObjects,
+		c.AppliedHookObjects, c.Errors, c.Warnings)
+	if c.SecurityRelevantObjects != 0 {
+		s += ", " + msgs.Sprintf(i18n.MsgSummarySecurityRelevant, c.SecurityRelevantObjects)
+	}
+	return s
+}
+
+// prettySecurityRelevantObjects writes the section with changes of security-sensitive kinds. The section is always
+// expanded, independent of --short-output and the diff line limits, as it is meant for security reviews.
+func prettySecurityRelevantObjects(buf io.StringWriter, objects []result.ResultObject, limits *textOutputLimits, msgs i18n.Catalog) {
+	var expandedLimits *textOutputLimits
+	if limits != nil {
+		l := *limits
+		l.maxDiffLines = 0
+		expandedLimits = &l
+	}
+
+	writeHeading(buf, msgs, i18n.MsgSecurityRelevantChanges)
+	for _, o := range objects {
+		if o.New {
+			_, _ = buf.WriteString(fmt.Sprintf("  %s\n", msgs.Sprintf(i18n.MsgSecurityRelevantNew, o.Ref.String())))
+		} else if o.Deleted {
+			_, _ = buf.WriteString(fmt.Sprintf("  %s\n", msgs.Sprintf(i18n.MsgSecurityRelevantDeleted, o.Ref.String())))
+		} else {
+			_, _ = buf.WriteString(fmt.Sprintf("  %s\n", o.Ref.String()))
+		}
+	}
+	for _, o := range objects {
+		if o.New {
+			m := o.Rendered
+			if m == nil {
+				m = o.Applied
+			}
+			prettyManifest(buf, o.Ref, m, 0, expandedLimits, msgs)
+		} else if len(o.Changes) != 0 {
+			_, _ = buf.WriteString("\n")
+			prettyChanges(buf, o.Ref, o.Changes, expandedLimits, msgs)
+		}
+	}
+}
+
+// writeHeading writes the given message as section heading, preceded by an empty line
+func writeHeading(buf io.StringWriter, msgs i18n.Catalog, id i18n.MessageId) {
+	_, _ = buf.WriteString(fmt.Sprintf("\n%s:\n", msgs.Sprintf(id)))
 }
 
 func prettyObjectRefs(buf io.StringWriter, refs []k8s.ObjectRef) {
@@ -98,29 +342,283 @@ func prettyObjectRefs(buf io.StringWriter, refs []k8s.ObjectRef) {
 	}
 }
 
-func prettyErrors(buf io.StringWriter, errors []result.DeploymentError) {
+// formatQuantityDelta formats the given quantity with an explicit sign for positive values
+func formatQuantityDelta(q resource.Quantity) string {
+	if q.Sign() > 0 {
+		return "+" + q.String()
+	}
+	return q.String()
+}
+
+func prettyResourceDelta(buf io.StringWriter, q result.ResourceQuantities, msgs i18n.Catalog) {
+	var t utils.PrettyTable
+	t.AddRow(msgs.Sprintf(i18n.MsgResourceDeltaColumnResource), msgs.Sprintf(i18n.MsgResourceDeltaColumnRequests), msgs.Sprintf(i18n.MsgResourceDeltaColumnLimits))
+	t.AddRow("cpu", formatQuantityDelta(q.CpuRequests), formatQuantityDelta(q.CpuLimits))
+	t.AddRow("memory", formatQuantityDelta(q.MemoryRequests), formatQuantityDelta(q.MemoryLimits))
+	_, _ = buf.WriteString(t.Render([]int{-1, -1, -1}))
+}
+
+var phaseTitles = map[result.PhaseType]i18n.MessageId{
+	result.PhasePreDeployHooks:  i18n.MsgPhasePreDeployHooks,
+	result.PhaseApply:           i18n.MsgPhaseApply,
+	result.PhasePostDeployHooks: i18n.MsgPhasePostDeployHooks,
+	result.PhasePostDeployWaits: i18n.MsgPhasePostDeployWaits,
+	result.PhasePrune:           i18n.MsgPhasePrune,
+}
+
+func prettyPhases(buf io.StringWriter, phases []result.Phase, msgs i18n.Catalog) {
+	for _, p := range phases {
+		title := string(p.Type)
+		if id, ok := phaseTitles[p.Type]; ok {
+			title = msgs.Sprintf(id)
+		}
+		if p.DeploymentItem != "" {
+			title += fmt.Sprintf(" (%s)", p.DeploymentItem)
+		}
+		duration := p.EndTime.Sub(p.StartTime.Time).Round(time.Millisecond)
+		_, _ = buf.WriteString(fmt.Sprintf("  %s: %s - %s (%s)\n", title,
+			p.StartTime.Local().Format("15:04:05.000"), p.EndTime.Local().Format("15:04:05.000"), duration.String()))
+		for _, ref := range p.Objects {
+			_, _ = buf.WriteString(fmt.Sprintf("    %s\n", ref.String()))
+		}
+	}
+}
+
+func prettyFetches(buf io.StringWriter, fetches []result.FetchTiming) {
+	for _, f := range fetches {
+		duration := f.EndTime.Sub(f.StartTime.Time).Round(time.Millisecond)
+		s := fmt.Sprintf("  %s %s: %s", f.Type, f.Source, duration.String())
+		if f.Attempts > 1 {
+			s += fmt.Sprintf(", %d attempts", f.Attempts)
+		}
+		if f.Deduplicated != 0 {
+			s += fmt.Sprintf(", %d deduplicated", f.Deduplicated)
+		}
+		if f.Error != "" {
+			s += fmt.Sprintf(", failed: %s", f.Error)
+		}
+		_, _ = buf.WriteString(s + "\n")
+	}
+}
+
+// maxSlowestObjects limits the number of objects printed in the 'Slowest objects' section
+const maxSlowestObjects = 10
+
+type timingEntry struct {
+	name     string
+	duration *metav1.Duration
+}
+
+// commandTimingEntries returns all stages of the command that were actually performed
+func commandTimingEntries(t *result.CommandTimings) []timingEntry {
+	if t == nil {
+		return nil
+	}
+	return filterTimingEntries([]timingEntry{
+		{"render", t.Render},
+		{"diff", t.Diff},
+		{"apply", t.Apply},
+		{"hooks", t.Hooks},
+		{"prune", t.Prune},
+	})
+}
+
+func objectTimingEntries(t *result.ObjectTimings) []timingEntry {
+	return filterTimingEntries([]timingEntry{
+		{"apply", t.Apply},
+		{"wait for hook", t.WaitHook},
+		{"wait for readiness", t.WaitReadiness},
+	})
+}
+
+func filterTimingEntries(entries []timingEntry) []timingEntry {
+	var ret []timingEntry
+	for _, e := range entries {
+		if e.duration != nil {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+func prettyTimingEntries(buf io.StringWriter, indent string, entries []timingEntry) {
+	for _, e := range entries {
+		_, _ = buf.WriteString(fmt.Sprintf("%s%s: %s\n", indent, e.name, e.duration.Round(time.Millisecond).String()))
+	}
+}
+
+// findSlowestObjects returns up to n objects with recorded timings, sorted by their total duration in descending order
+func findSlowestObjects(objects []result.ResultObject, n int) []result.ResultObject {
+	var ret []result.ResultObject
+	for _, o := range objects {
+		if o.Timings != nil && o.Timings.Total() != 0 {
+			ret = append(ret, o)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		ti, tj := ret[i].Timings.Total(), ret[j].Timings.Total()
+		if ti != tj {
+			return ti > tj
+		}
+		return ret[i].Ref.Less(ret[j].Ref)
+	})
+	if len(ret) > n {
+		ret = ret[:n]
+	}
+	return ret
+}
+
+func prettySlowestObjects(buf io.StringWriter, objects []result.ResultObject) {
+	for _, o := range objects {
+		_, _ = buf.WriteString(fmt.Sprintf("  %s: %s\n", o.Ref.String(), o.Timings.Total().Round(time.Millisecond).String()))
+		prettyTimingEntries(buf, "    ", objectTimingEntries(o.Timings))
+	}
+}
+
+// maxGroupedErrorRefs limits the number of object refs printed for a group of identical errors or warnings
+const maxGroupedErrorRefs = 10
+
+type errorGroup struct {
+	message string
+	causes  []result.ErrorCause
+	refs    []k8s.ObjectRef
+}
+
+// groupErrors groups errors with identical messages and causes, e.g. when an admission webhook is down and every
+// object fails with the same error. Groups are sorted by message and refs are sorted inside each group, so that the
+// output is stable across runs.
+func groupErrors(errors []result.DeploymentError) []*errorGroup {
+	var ret []*errorGroup
+	byKey := map[string]*errorGroup{}
+	keys := map[*errorGroup]string{}
 	for _, e := range errors {
-		prefix := ""
-		if s := e.Ref.String(); s != "" {
-			prefix = fmt.Sprintf("%s: ", s)
+		key := e.Message
+		for _, c := range e.Causes {
+			key += "\x00" + formatErrorCause(c)
 		}
-		_, _ = buf.WriteString(fmt.Sprintf("  %s%s\n", prefix, e.Message))
+		g, ok := byKey[key]
+		if !ok {
+			g = &errorGroup{message: e.Message, causes: e.Causes}
+			byKey[key] = g
+			keys[g] = key
+			ret = append(ret, g)
+		}
+		g.refs = append(g.refs, e.Ref)
+	}
+	for _, g := range ret {
+		sort.SliceStable(g.refs, func(i, j int) bool {
+			return g.refs[i].Less(g.refs[j])
+		})
 	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].message != ret[j].message {
+			return ret[i].message < ret[j].message
+		}
+		if ret[i].refs[0] != ret[j].refs[0] {
+			return ret[i].refs[0].Less(ret[j].refs[0])
+		}
+		return keys[ret[i]] < keys[ret[j]]
+	})
+	return ret
 }
 
-func prettyChanges(buf io.StringWriter, ref k8s.ObjectRef, changes []result.Change) {
-	_, _ = buf.WriteString(fmt.Sprintf("Diff for object %s\n", ref.String()))
+// prettyErrors prints errors or warnings. Identical messages are printed only once, together with the number and a
+// capped list of affected objects.
+func prettyErrors(buf io.StringWriter, errors []result.DeploymentError) {
+	for _, g := range groupErrors(errors) {
+		if len(g.refs) == 1 {
+			prefix := ""
+			if s := g.refs[0].String(); s != "" {
+				prefix = fmt.Sprintf("%s: ", s)
+			}
+			_, _ = buf.WriteString(fmt.Sprintf("  %s%s\n", prefix, g.message))
+		} else {
+			_, _ = buf.WriteString(fmt.Sprintf("  (x%d objects) %s\n", len(g.refs), g.message))
+			for i, ref := range g.refs {
+				if i == maxGroupedErrorRefs {
+					_, _ = buf.WriteString(fmt.Sprintf("    ... and %d more\n", len(g.refs)-i))
+					break
+				}
+				if s := ref.String(); s != "" {
+					_, _ = buf.WriteString(fmt.Sprintf("    %s\n", s))
+				}
+			}
+		}
+		for _, c := range g.causes {
+			_, _ = buf.WriteString(fmt.Sprintf("    - %s\n", formatErrorCause(c)))
+		}
+	}
+}
+
+func formatErrorCause(c result.ErrorCause) string {
+	s := c.Message
+	if c.Reason != "" {
+		s = fmt.Sprintf("%s (%s)", s, c.Reason)
+	}
+	if c.Field != "" {
+		s = fmt.Sprintf("%s: %s", c.Field, s)
+	}
+	return s
+}
+
+func prettyChanges(buf io.StringWriter, ref k8s.ObjectRef, changes []result.Change, limits *textOutputLimits, msgs i18n.Catalog) {
+	color := limits != nil && limits.color
+
+	header := msgs.Sprintf(i18n.MsgDiffForObject, ref.String())
+	if color {
+		header = colorize(header, ansiBold)
+	}
+	_, _ = buf.WriteString(header + "\n")
 
 	var t utils.PrettyTable
-	t.AddRow("Path", "Diff")
+	t.AddRow(msgs.Sprintf(i18n.MsgDiffColumnPath), msgs.Sprintf(i18n.MsgDiffColumnDiff))
 
 	for _, c := range changes {
-		t.AddRow(c.JsonPath, c.UnifiedDiff)
+		d := c.UnifiedDiff
+		if color {
+			d = colorizeUnifiedDiff(d)
+		}
+		t.AddRow(c.JsonPath, d)
+	}
+	s := t.RenderFit(limits.getTableWidth())
+	if limits != nil {
+		s = truncateLines(s, limits.maxDiffLines)
 	}
-	s := t.Render([]int{60})
 	_, _ = buf.WriteString(s)
 }
 
+// getLiveManifest returns the manifest of a deleted or orphan object as it exists (or existed) in the cluster
+func getLiveManifest(o result.ResultObject) *uo.UnstructuredObject {
+	if o.Remote != nil {
+		return o.Remote
+	}
+	return o.DeletedManifest
+}
+
+// prettyManifest prints the given manifest as indented yaml, truncated after maxLines lines
+func prettyManifest(buf io.StringWriter, ref k8s.ObjectRef, m *uo.UnstructuredObject, maxLines int, limits *textOutputLimits, msgs i18n.Catalog) {
+	if m == nil {
+		return
+	}
+	y, err := yaml.WriteYamlString(m)
+	if err != nil {
+		y = fmt.Sprintf("failed to convert manifest to yaml: %s\n", err.Error())
+	}
+	y = truncateLinesWithMarker(y, maxLines, func(n int) string {
+		return msgs.Sprintf(i18n.MsgManifestTruncated, n) + "\n"
+	})
+
+	header := msgs.Sprintf(i18n.MsgManifestForObject, ref.String())
+	if limits != nil && limits.color {
+		header = colorize(header, ansiBold)
+	}
+	_, _ = buf.WriteString("\n" + header + "\n")
+	for _, l := range strings.SplitAfter(strings.TrimSuffix(y, "\n"), "\n") {
+		_, _ = buf.WriteString("  " + l)
+	}
+	_, _ = buf.WriteString("\n")
+}
+
 func formatCommandResultYaml(cr *result.CommandResult) (string, error) {
 	b, err := yaml.WriteYamlString(cr.ToCompacted())
 	if err != nil {
@@ -129,33 +627,108 @@ func formatCommandResultYaml(cr *result.CommandResult) (string, error) {
 	return b, nil
 }
 
-func formatCommandResult(cr *result.CommandResult, format string, short bool) (string, error) {
+func formatCommandResultJson(cr *result.CommandResult) (string, error) {
+	return formatJson(cr.ToCompacted())
+}
+
+func formatCommandResultChangelog(cr *result.CommandResult, rules []types.ChangelogRule) (string, error) {
+	entries, err := diff.BuildChangelog(cr, rules)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "No changes\n", nil
+	}
+	buf := bytes.NewBuffer(nil)
+	for _, e := range entries {
+		buf.WriteString(fmt.Sprintf("- %s\n", e.String()))
+	}
+	return buf.String(), nil
+}
+
+// getChangelogRules returns the changelog rules of the target, followed by the global rules of the project config
+func getChangelogRules(cmdCtx *commandCtx, cr *result.CommandResult) []types.ChangelogRule {
+	var ret []types.ChangelogRule
+	if cr.Target.Output != nil && cr.Target.Output.Changelog != nil {
+		ret = append(ret, cr.Target.Output.Changelog.Rules...)
+	}
+	if cmdCtx != nil && cmdCtx.targetCtx != nil {
+		c := cmdCtx.targetCtx.KluctlProject.Config.Output
+		if c != nil && c.Changelog != nil {
+			ret = append(ret, c.Changelog.Rules...)
+		}
+	}
+	return ret
+}
+
+// getSecuritySensitiveKinds returns the security-sensitive kinds of the target, falling back to the ones of the
+// project config. nil is returned if none are configured, in which case the defaults are used.
+func getSecuritySensitiveKinds(cmdCtx *commandCtx, cr *result.CommandResult) []string {
+	if cr.Target.Output != nil && cr.Target.Output.SecuritySensitiveKinds != nil {
+		return cr.Target.Output.SecuritySensitiveKinds
+	}
+	if cmdCtx != nil && cmdCtx.targetCtx != nil {
+		c := cmdCtx.targetCtx.KluctlProject.Config.Output
+		if c != nil && c.SecuritySensitiveKinds != nil {
+			return c.SecuritySensitiveKinds
+		}
+	}
+	return nil
+}
+
+func formatCommandResult(cr *result.CommandResult, format string, short bool, showTimings bool, showIgnored bool, objectYaml objectYamlOptions, limits *textOutputLimits, changelogRules []types.ChangelogRule, msgs i18n.Catalog) (string, error) {
 	switch format {
 	case "text":
-		return formatCommandResultText(cr, short), nil
+		return formatCommandResultText(cr, short, showTimings, showIgnored, objectYaml, limits, msgs), nil
 	case "yaml":
 		return formatCommandResultYaml(cr)
+	case "json":
+		return formatCommandResultJson(cr)
+	case "markdown", "markdown-collapsible":
+		return mdreport.Render(cr, mdreport.Options{
+			Short:                 short,
+			Collapsible:           format == "markdown-collapsible",
+			ShowNewObjectYaml:     objectYaml.showNew,
+			ShowDeletedObjectYaml: objectYaml.showDeleted,
+			MaxObjectYamlLines:    objectYaml.maxLines,
+		}), nil
+	case "changelog":
+		return formatCommandResultChangelog(cr, changelogRules)
+	case "html":
+		return htmlreport.RenderString(cr)
+	case "diff":
+		return patchreport.Render(cr)
+	case "archive":
+		b, err := results.WriteResultArchive(cr)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
 	default:
 		return "", fmt.Errorf("invalid format: %s", format)
 	}
 }
 
-func prettyValidationResults(buf io.StringWriter, results []result.ValidateResultEntry) {
+func prettyValidationResults(buf io.StringWriter, results []result.ValidateResultEntry, limits *textOutputLimits, msgs i18n.Catalog) {
 	var t utils.PrettyTable
-	t.AddRow("Object", "Message")
+	t.AddRow(msgs.Sprintf(i18n.MsgValidationColumnObject), msgs.Sprintf(i18n.MsgValidationColumnMessage))
 
 	for _, e := range results {
 		t.AddRow(e.Ref.String(), e.Message)
 	}
-	s := t.Render([]int{60})
+	s := t.RenderFit(limits.getTableWidth())
 	_, _ = buf.WriteString(s)
 }
 
-func formatValidateResultText(vr *result.ValidateResult) string {
+func formatValidateResultText(vr *result.ValidateResult, limits *textOutputLimits, msgs i18n.Catalog) string {
 	buf := bytes.NewBuffer(nil)
 
+	if vr.SourceResultId != "" {
+		buf.WriteString(fmt.Sprintf("\n%s\n", msgs.Sprintf(i18n.MsgValidatedFromResult, vr.SourceResultId)))
+	}
+
 	if len(vr.Warnings) != 0 {
-		buf.WriteString("\nValidation Warnings:\n")
+		writeHeading(buf, msgs, i18n.MsgValidationWarnings)
 		prettyErrors(buf, vr.Warnings)
 	}
 
@@ -163,7 +736,7 @@ func formatValidateResultText(vr *result.ValidateResult) string {
 		if buf.Len() != 0 {
 			buf.WriteString("\n")
 		}
-		buf.WriteString("Validation Errors:\n")
+		buf.WriteString(msgs.Sprintf(i18n.MsgValidationErrors) + ":\n")
 		prettyErrors(buf, vr.Errors)
 	}
 
@@ -171,8 +744,8 @@ func formatValidateResultText(vr *result.ValidateResult) string {
 		if buf.Len() != 0 {
 			buf.WriteString("\n")
 		}
-		buf.WriteString("Results:\n")
-		prettyValidationResults(buf, vr.Results)
+		buf.WriteString(msgs.Sprintf(i18n.MsgValidationResults) + ":\n")
+		prettyValidationResults(buf, vr.Results, limits, msgs)
 	}
 
 	return buf.String()
@@ -186,34 +759,125 @@ func formatValidateResultYaml(vr *result.ValidateResult) (string, error) {
 	return string(b), nil
 }
 
-func formatValidateResult(vr *result.ValidateResult, format string) (string, error) {
+func formatValidateResult(vr *result.ValidateResult, format string, limits *textOutputLimits, msgs i18n.Catalog) (string, error) {
 	switch format {
 	case "text":
-		return formatValidateResultText(vr), nil
+		return formatValidateResultText(vr, limits, msgs), nil
 	case "yaml":
 		return formatValidateResultYaml(vr)
+	case "json":
+		return formatJson(vr)
+	case "markdown", "markdown-collapsible":
+		return mdreport.RenderValidateResult(vr), nil
 	default:
 		return "", fmt.Errorf("invalid validation result format: %s", format)
 	}
 }
 
-func outputHelper(ctx context.Context, output []string, cb func(format string) (string, error)) error {
+func formatJson(o any) (string, error) {
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+// outputSpec is a parsed output format specification, as passed via --output-format
+type outputSpec struct {
+	format string
+	// template is only set for the 'gotemplate' and 'gotemplate-string' formats
+	template *template.Template
+	path     *string
+}
+
+// parseOutputSpec parses an output spec in the form 'format=path'. The 'gotemplate' format expects the template file
+// before the optional path, in the form 'gotemplate=templateFile=path'. The 'gotemplate-string' format treats
+// everything after the first '=' as the template, so its output is always written to stdout. The 'archive' format
+// always requires a path, as it is binary.
+func parseOutputSpec(o string) (*outputSpec, error) {
+	s := strings.SplitN(o, "=", 2)
+	ret := &outputSpec{
+		format: s[0],
+	}
+	if len(s) > 1 {
+		ret.path = &s[1]
+	}
+
+	var err error
+	switch ret.format {
+	case "gotemplate":
+		if ret.path == nil {
+			return nil, fmt.Errorf("the gotemplate format requires a template file, e.g. 'gotemplate=template.tmpl'")
+		}
+		s = strings.SplitN(*ret.path, "=", 2)
+		ret.path = nil
+		if len(s) > 1 {
+			ret.path = &s[1]
+		}
+		ret.template, err = tmplreport.ParseFile(s[0])
+	case "gotemplate-string":
+		if ret.path == nil {
+			return nil, fmt.Errorf("the gotemplate-string format requires a template, e.g. 'gotemplate-string={{ .Summary.ChangedObjects }}'")
+		}
+		ret.template, err = tmplreport.Parse(ret.format, *ret.path)
+		ret.path = nil
+	case "archive":
+		if ret.path == nil || *ret.path == "-" {
+			return nil, fmt.Errorf("the archive format requires an output file, e.g. 'archive=result.tar.gz'")
+		}
+		if _, appendOutput := parseOutputPath(*ret.path); appendOutput {
+			return nil, fmt.Errorf("the archive format can't be appended to an existing file")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// outputHelper invokes cb for every requested output format and writes the result to the requested target. limits are
+// only passed to cb and applied when the 'text' format is written to stdout. tmpl is only passed for the 'gotemplate'
+// and 'gotemplate-string' formats.
+func outputHelper(ctx context.Context, output []string, limits *textOutputLimits, cb func(format string, tmpl *template.Template, limits *textOutputLimits) (string, error)) error {
 	if len(output) == 0 {
 		output = []string{"text"}
 	}
+
+	// parse all specs first, so that invalid templates are reported before anything is written
+	var specs []*outputSpec
 	for _, o := range output {
-		s := strings.SplitN(o, "=", 2)
-		format := s[0]
-		var path *string
-		if len(s) > 1 {
-			path = &s[1]
+		spec, err := parseOutputSpec(o)
+		if err != nil {
+			return err
+		}
+		specs = append(specs, spec)
+	}
+
+	for _, spec := range specs {
+		path := spec.path
+		var formatLimits *textOutputLimits
+		if spec.format == "text" && (path == nil || *path == "-") {
+			formatLimits = limits
 		}
-		r, err := cb(format)
+
+		r, err := cb(spec.format, spec.template, formatLimits)
 		if err != nil {
 			return err
 		}
 
-		err = outputResult(ctx, path, r)
+		if formatLimits != nil {
+			r = truncateLines(r, formatLimits.maxLines)
+			if formatLimits.usePager(ctx, r) {
+				status.Flush(ctx)
+				err = outputWithPager(ctx, r)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		err = outputResult(ctx, path, r, spec.format == "yaml")
 		if err != nil {
 			return err
 		}
@@ -221,71 +885,137 @@ func outputHelper(ctx context.Context, output []string, cb func(format string) (
 	return nil
 }
 
+// newObfuscator creates an obfuscator with the obfuscation rules found in .kluctl.yaml, in all deployment projects and
+// in the file passed via --obfuscation-rules-file
+func newObfuscator(cmdCtx *commandCtx, flags args.OutputFormatFlags) (*diff.Obfuscator, error) {
+	mode, err := diff.ParseObfuscateMode(flags.ObfuscateMode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --obfuscate-mode: %w", err)
+	}
+	obfuscator := &diff.Obfuscator{
+		Mode: mode,
+	}
+	if cmdCtx != nil && cmdCtx.targetCtx != nil {
+		obfuscator.Rules = append(obfuscator.Rules, cmdCtx.targetCtx.KluctlProject.Config.Obfuscate...)
+		c := cmdCtx.targetCtx.DeploymentCollection
+		if c != nil && c.Project != nil {
+			obfuscator.Rules = append(obfuscator.Rules, c.Project.GetObfuscationRules()...)
+		}
+	}
+	if flags.ObfuscationRulesFile != "" {
+		var rules []types.ObfuscationRule
+		err := yaml.ReadYamlFile(flags.ObfuscationRulesFile, &rules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load obfuscation rules: %w", err)
+		}
+		obfuscator.Rules = append(obfuscator.Rules, rules...)
+	}
+	return obfuscator, nil
+}
+
 func outputCommandResult(ctx context.Context, cmdCtx *commandCtx, flags args.OutputFormatFlags, cr *result.CommandResult, writeToResultStore bool) error {
 	cr.Id = cmdCtx.resultId
 	cr.Command.Initiator = result.CommandInititiator_CommandLine
+	cr.Command.ReadOnly = isReadOnly(ctx)
+	if cr.Command.Invocation == nil {
+		cr.Command.Invocation = buildInvocationInfo(ctx, getSensitiveArgs(cmdCtx))
+	}
+	if cr.Fetches == nil {
+		cr.Fetches = cmdCtx.fetchScheduler.GetTimings()
+	}
+	if cr.ProjectLock == nil {
+		cr.ProjectLock = cmdCtx.lockRecorder.GetLock()
+	}
+	cr.MarkSecurityRelevant(getSecuritySensitiveKinds(cmdCtx, cr))
+	if c := stats.FromContext(ctx); c != nil {
+		c.SetTimings(cr.Timings)
+		cr.Stats = c.Build()
+	}
 
-	if !flags.NoObfuscate {
-		var obfuscator diff.Obfuscator
-		err := obfuscator.ObfuscateResult(cr)
+	// signed results are always obfuscated, so that verification does not require access to secrets
+	sign := writeToResultStore && cmdCtx.resultSigner != nil
+	if flags.NoObfuscate && sign {
+		status.Warning(ctx, "--no-obfuscate is ignored as command results are signed")
+	}
+	obfuscator, err := newObfuscator(cmdCtx, flags)
+	if err != nil {
+		return err
+	}
+	if !flags.NoObfuscate || sign {
+		err = obfuscator.ObfuscateResult(cr)
 		if err != nil {
 			return err
 		}
 	}
 
 	var resultStoreErr error
-	if writeToResultStore && cmdCtx.resultStore != nil {
-		s := status.Start(ctx, "Writing command result")
-		defer s.Failed()
-
-		didWarn := false
-		if cr.ClusterInfo.ClusterId == "" {
-			warning := "failed to determine cluster ID due to missing get/list permissions for the kube-system namespace. This might result in follow up issues in regard to cluster differentiation stored command results"
-			cr.Warnings = append(cr.Warnings, result.DeploymentError{
-				Message: warning,
-			})
-			status.Warning(ctx, warning)
-			didWarn = true
-		}
-
-		resultStoreErr = cmdCtx.resultStore.WriteCommandResult(cr)
-		if resultStoreErr != nil {
-			s.FailedWithMessagef("Failed to write result to result store: %s", resultStoreErr.Error())
-		} else {
-			if didWarn {
-				s.Warning()
-			} else {
-				s.Success()
-			}
+	if writeToResultStore {
+		notifySlack(ctx, cmdCtx, obfuscator, cr)
+		postCommandResultWebhooks(ctx, cmdCtx, obfuscator, cr)
+		writeConfigMapNotification(ctx, cmdCtx, cr)
+
+		var signer *results.ResultSigner
+		if sign {
+			signer = cmdCtx.resultSigner
 		}
+		resultStoreErr = writeCommandResult(ctx, cmdCtx.resultWriters, signer, cr, cmdCtx.resultStoreBestEffort)
 	}
-	err := outputCommandResult2(ctx, flags, cr)
+	err = outputCommandResult2(ctx, flags, cr, getChangelogRules(cmdCtx, cr))
 	if err == nil && resultStoreErr != nil {
 		return resultStoreErr
 	}
 	return err
 }
 
-func outputCommandResult2(ctx context.Context, flags args.OutputFormatFlags, cr *result.CommandResult) error {
+func outputCommandResult2(ctx context.Context, flags args.OutputFormatFlags, cr *result.CommandResult, changelogRules []types.ChangelogRule) error {
+	filter, err := newCommandResultOutputFilter(flags)
+	if err != nil {
+		return err
+	}
+	if filter != nil {
+		total := len(cr.Objects)
+		cr = filter.Apply(cr)
+		status.Infof(ctx, "Output is filtered, showing %d of %d objects", len(cr.Objects), total)
+	}
+
+	limits, err := newTextOutputLimits(ctx, flags)
+	if err != nil {
+		return err
+	}
+
 	status.Flush(ctx)
-	err := outputHelper(ctx, flags.OutputFormat, func(format string) (string, error) {
-		return formatCommandResult(cr, format, flags.ShortOutput)
+	err = outputHelper(ctx, flags.OutputFormat, limits, func(format string, tmpl *template.Template, limits *textOutputLimits) (string, error) {
+		if tmpl != nil {
+			return tmplreport.RenderCommandResult(tmpl, cr)
+		}
+		return formatCommandResult(cr, format, flags.ShortOutput, flags.ShowTimings, flags.ShowIgnored, newObjectYamlOptions(flags), limits, changelogRules, getMessageCatalog(ctx))
 	})
 	status.Flush(ctx)
 	return err
 }
 
-func outputValidateResult(ctx context.Context, cmdCtx *commandCtx, output []string, vr *result.ValidateResult) error {
+// outputValidateResult outputs the validate result. final must be true for the last result of a validation, so that
+// only final results are posted to result webhooks.
+func outputValidateResult(ctx context.Context, cmdCtx *commandCtx, output []string, tableWidthFlags args.TableWidthFlags, vr *result.ValidateResult, final bool) error {
 	vr.Id = cmdCtx.resultId
+	vr.ReadOnly = isReadOnly(ctx)
+
+	if final {
+		postValidateResultWebhooks(ctx, cmdCtx, vr)
+	}
 
-	return outputValidateResult2(ctx, output, vr)
+	return outputValidateResult2(ctx, output, tableWidthFlags, vr)
 }
 
-func outputValidateResult2(ctx context.Context, output []string, vr *result.ValidateResult) error {
+func outputValidateResult2(ctx context.Context, output []string, tableWidthFlags args.TableWidthFlags, vr *result.ValidateResult) error {
 	status.Flush(ctx)
 
-	err := outputHelper(ctx, output, func(format string) (string, error) {
-		return formatValidateResult(vr, format)
+	limits := newTableOutputLimits(ctx, tableWidthFlags)
+	err := outputHelper(ctx, output, limits, func(format string, tmpl *template.Template, limits *textOutputLimits) (string, error) {
+		if tmpl != nil {
+			return tmplreport.RenderValidateResult(tmpl, vr)
+		}
+		return formatValidateResult(vr, format, limits, getMessageCatalog(ctx))
 	})
 	status.Flush(ctx)
 	return err
@@ -312,33 +1042,30 @@ func outputYamlResult(ctx context.Context, output []string, result interface{},
 		}
 		s = x
 	}
-	return outputResult2(ctx, output, s)
+	return outputResult2(ctx, output, s, true)
 }
 
-func outputResult(ctx context.Context, f *string, result string) error {
+// outputResult writes the result to stdout or to the given file. See writeOutputFile for details about how files are
+// written. yamlDoc must be true if result is a yaml document, so that it is properly separated when appending.
+func outputResult(ctx context.Context, f *string, result string, yamlDoc bool) error {
 	// make sure there is no pending render of a status line
 	status.Flush(ctx)
 
-	var w io.Writer
-	w = getStdout(ctx)
 	if f != nil && *f != "-" {
-		f, err := os.Create(*f)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		w = f
+		return writeOutputFile(*f, result, yamlDoc)
 	}
-	_, err := w.Write([]byte(result))
+	stdStreamsMutex.Lock()
+	defer stdStreamsMutex.Unlock()
+	_, err := getStdout(ctx).Write([]byte(result))
 	return err
 }
 
-func outputResult2(ctx context.Context, output []string, result string) error {
+func outputResult2(ctx context.Context, output []string, result string, yamlDoc bool) error {
 	if len(output) == 0 {
 		output = []string{"-"}
 	}
 	for _, o := range output {
-		err := outputResult(ctx, &o, result)
+		err := outputResult(ctx, &o, result, yamlDoc)
 		if err != nil {
 			return err
 		}
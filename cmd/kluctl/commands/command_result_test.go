@@ -0,0 +1,628 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/i18n"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/results/tmplreport"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func buildJsonTestCommandResult() *result.CommandResult {
+	ref := func(name string) k8s.ObjectRef {
+		return k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: name}
+	}
+	cm := uo.New()
+	cm.SetK8sGVKs("", "v1", "ConfigMap")
+	cm.SetK8sNamespace("ns")
+	cm.SetK8sName("cm")
+	_ = cm.SetNestedField(map[string]any{"a": "1", "b": "2"}, "data")
+
+	startTime := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return &result.CommandResult{
+		Id: "id",
+		ClusterInfo: result.ClusterInfo{
+			ClusterId: "cluster-id",
+		},
+		Command: result.CommandInfo{
+			Command:   "diff",
+			Target:    "test",
+			StartTime: startTime,
+			EndTime:   startTime,
+		},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: ref("new"), New: true}, Rendered: cm},
+			{BaseObject: result.BaseObject{Ref: ref("cm"), Changes: []result.Change{
+				{Type: "update", JsonPath: "data.b", OldValue: &apiextensionsv1.JSON{Raw: []byte(`"1"`)}, NewValue: &apiextensionsv1.JSON{Raw: []byte(`"2"`)}},
+				{Type: "insert", JsonPath: "data.a", NewValue: &apiextensionsv1.JSON{Raw: []byte(`"1"`)}},
+			}}, Rendered: cm, Remote: cm},
+		},
+		Warnings: []result.DeploymentError{
+			{Ref: ref("cm"), Message: "warning"},
+		},
+	}
+}
+
+func TestFormatCommandResultJson(t *testing.T) {
+	cr := buildJsonTestCommandResult()
+
+	j, err := formatCommandResult(cr, "json", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(j), &m))
+	assert.Equal(t, "id", m["id"])
+	assert.Equal(t, "cluster-id", m["clusterInfo"].(map[string]any)["clusterId"])
+	assert.Equal(t, "diff", m["command"].(map[string]any)["command"])
+
+	// field names must match the yaml output
+	y, err := formatCommandResult(cr, "yaml", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	var m2 map[string]any
+	assert.NoError(t, yaml.ReadYamlString(y, &m2))
+	assert.Equal(t, m2, m)
+
+	// round-trip
+	var ccr result.CompactedCommandResult
+	assert.NoError(t, json.Unmarshal([]byte(j), &ccr))
+	j3, err := formatCommandResult(ccr.ToNonCompacted(), "json", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, j, j3)
+
+	// stable regardless of ordering
+	cr2 := buildJsonTestCommandResult()
+	cr2.Objects[0], cr2.Objects[1] = cr2.Objects[1], cr2.Objects[0]
+	cr2.Objects[0].Changes[0], cr2.Objects[0].Changes[1] = cr2.Objects[0].Changes[1], cr2.Objects[0].Changes[0]
+	j2, err := formatCommandResult(cr2, "json", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, j, j2)
+}
+
+func TestFormatValidateResultJson(t *testing.T) {
+	vr := &result.ValidateResult{
+		Id:    "id",
+		Ready: true,
+		Results: []result.ValidateResultEntry{
+			{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: "cm"}, Message: "ready"},
+		},
+	}
+
+	j, err := formatValidateResult(vr, "json", nil, nil)
+	assert.NoError(t, err)
+	y, err := formatValidateResult(vr, "yaml", nil, nil)
+	assert.NoError(t, err)
+
+	var m, m2 map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(j), &m))
+	assert.NoError(t, yaml.ReadYamlString(y, &m2))
+	assert.Equal(t, m2, m)
+	assert.Equal(t, "id", m["id"])
+
+	var vr2 result.ValidateResult
+	assert.NoError(t, json.Unmarshal([]byte(j), &vr2))
+	assert.Equal(t, vr.Results, vr2.Results)
+}
+
+func TestFormatValidateResultTextTableWidth(t *testing.T) {
+	vr := &result.ValidateResult{
+		Results: []result.ValidateResultEntry{
+			{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: "cm"}, Message: strings.Repeat("x", 200)},
+		},
+	}
+
+	// output that does not go to a terminal uses the default width
+	s, err := formatValidateResult(vr, "text", nil, nil)
+	assert.NoError(t, err)
+	for _, l := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		if strings.HasPrefix(l, "+") || strings.HasPrefix(l, "|") {
+			assert.Len(t, l, defaultTableWidth)
+		}
+	}
+
+	s, err = formatValidateResult(vr, "text", &textOutputLimits{tableWidth: 300}, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "| ns/ConfigMap/cm | "+strings.Repeat("x", 200)+strings.Repeat(" ", 300-7-15-200)+" |\n")
+}
+
+func TestFormatCommandResultTextCatalog(t *testing.T) {
+	cr := buildJsonTestCommandResult()
+
+	s, err := formatCommandResult(cr, "text", true, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "\nNew objects:\n  ns/ConfigMap/new\n")
+	assert.Contains(t, s, "\nWarnings:\n  ns/ConfigMap/cm: warning\n")
+
+	s, err = formatCommandResult(cr, "text", true, false, false, objectYamlOptions{}, nil, nil, i18n.Catalog{
+		i18n.MsgNewObjects: "Neue Objekte",
+		i18n.MsgWarnings:   "Warnungen",
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, s, "\nNeue Objekte:\n  ns/ConfigMap/new\n")
+	assert.Contains(t, s, "\nWarnungen:\n  ns/ConfigMap/cm: warning\n")
+	// not translated messages fall back to english
+	assert.Contains(t, s, "\nChanged objects:\n")
+}
+
+func TestFormatCommandResultSummary(t *testing.T) {
+	cr := buildJsonTestCommandResult()
+
+	s, err := formatCommandResult(cr, "text", true, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	summary := "\nSummary: 1 new, 1 changed, 0 deleted, 0 orphan, 0 hooks applied, 0 errors, 1 warnings\n"
+	assert.True(t, strings.HasPrefix(s, summary))
+	assert.True(t, strings.HasSuffix(s, summary))
+
+	j, err := formatCommandResult(cr, "json", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	var ccr result.CompactedCommandResult
+	assert.NoError(t, json.Unmarshal([]byte(j), &ccr))
+	assert.Equal(t, &result.CommandResultCounts{NewObjects: 1, ChangedObjects: 1, Warnings: 1, Kinds: map[string]result.KindCounts{
+		"ConfigMap": {New: 1, Changed: 1},
+	}}, ccr.Summary)
+
+	s, err = formatCommandResult(&result.CommandResult{}, "text", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "\nNo changes\n", s)
+}
+
+func TestFindDeletedManifest(t *testing.T) {
+	cm := uo.New()
+	cm.SetK8sGVKs("", "v1", "ConfigMap")
+	cm.SetK8sNamespace("ns")
+	cm.SetK8sName("cm")
+	secret := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": "s", "namespace": "ns"},
+		"data":       map[string]any{"a": "KioqKio="},
+	})
+	cert := uo.New()
+	cert.SetK8sGVKs("cert-manager.io", "v1", "Certificate")
+	cert.SetK8sNamespace("ns")
+	cert.SetK8sName("cert")
+
+	cr := &result.CommandResult{
+		Id: "id",
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: cm.GetK8sRef(), Deleted: true}, DeletedManifest: cm},
+			{BaseObject: result.BaseObject{Ref: secret.GetK8sRef(), Deleted: true}, DeletedManifest: secret},
+			{BaseObject: result.BaseObject{Ref: cert.GetK8sRef(), Deleted: true, ObfuscatedByRules: true}, DeletedManifest: cert},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: "uncaptured"}, Deleted: true}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: "orphan"}, Orphan: true}},
+		},
+	}
+
+	o, err := findDeletedManifest(cr, "ns/ConfigMap/cm")
+	assert.NoError(t, err)
+	assert.Equal(t, cm, o.DeletedManifest)
+
+	_, err = findDeletedManifest(cr, "ns/Secret/s")
+	assert.ErrorContains(t, err, "contains obfuscated secret data")
+	_, err = findDeletedManifest(cr, "ns/Certificate/cert")
+	assert.ErrorContains(t, err, "contains fields obfuscated by obfuscation rules")
+	_, err = findDeletedManifest(cr, "ns/ConfigMap/uncaptured")
+	assert.ErrorContains(t, err, "was not captured")
+	_, err = findDeletedManifest(cr, "ns/ConfigMap/orphan")
+	assert.ErrorContains(t, err, "contains no deleted object ns/ConfigMap/orphan")
+
+	// captured manifests survive compaction
+	j, err := formatCommandResult(cr, "json", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	var ccr result.CompactedCommandResult
+	assert.NoError(t, json.Unmarshal([]byte(j), &ccr))
+	o, err = findDeletedManifest(ccr.ToNonCompacted(), "ns/ConfigMap/cm")
+	assert.NoError(t, err)
+	assert.Equal(t, cm, o.DeletedManifest)
+	_, err = findDeletedManifest(ccr.ToNonCompacted(), "ns/Certificate/cert")
+	assert.ErrorContains(t, err, "contains fields obfuscated by obfuscation rules")
+}
+
+func TestCommandResultOutputFilter(t *testing.T) {
+	newObj := func(group string, kind string, namespace string, name string, app string) result.ResultObject {
+		o := uo.New()
+		o.SetK8sName(name)
+		o.SetK8sLabel("app", app)
+		return result.ResultObject{
+			BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Group: group, Version: "v1", Kind: kind, Namespace: namespace, Name: name}},
+			Rendered:   o,
+		}
+	}
+	cr := &result.CommandResult{
+		Objects: []result.ResultObject{
+			newObj("apps", "Deployment", "team-a", "web", "web"),
+			newObj("apps", "StatefulSet", "team-a", "db", "db"),
+			newObj("", "ConfigMap", "team-a", "web-config", "web"),
+			newObj("", "ConfigMap", "team-b", "other", "other"),
+		},
+		Warnings: []result.DeploymentError{{Message: "warning"}},
+	}
+	cr.Objects[0].New = true
+	cr.Objects[2].Changes = []result.Change{{Type: "update", JsonPath: "data.a"}}
+
+	names := func(flags args.OutputFormatFlags) []string {
+		f, err := newCommandResultOutputFilter(flags)
+		assert.NoError(t, err)
+		if f == nil {
+			return nil
+		}
+		filtered := f.Apply(cr)
+		assert.Equal(t, cr.Warnings, filtered.Warnings)
+		var ret []string
+		for _, o := range filtered.Objects {
+			ret = append(ret, o.Ref.Name)
+		}
+		return ret
+	}
+
+	assert.Nil(t, names(args.OutputFormatFlags{}))
+	assert.Equal(t, []string{"web", "db"}, names(args.OutputFormatFlags{OutputFilterKind: []string{"Deployment,StatefulSet"}}))
+	assert.Equal(t, []string{"web"}, names(args.OutputFormatFlags{OutputFilterKind: []string{"apps/deployment"}}))
+	assert.Nil(t, names(args.OutputFormatFlags{OutputFilterKind: []string{"batch/Deployment"}}))
+	assert.Equal(t, []string{"other"}, names(args.OutputFormatFlags{OutputFilterNamespace: []string{"team-b"}}))
+	assert.Equal(t, []string{"web", "web-config"}, names(args.OutputFormatFlags{OutputFilterName: []string{"web*"}}))
+	assert.Equal(t, []string{"web", "web-config"}, names(args.OutputFormatFlags{OutputFilterLabel: "app=web"}))
+	assert.Equal(t, []string{"web", "web-config"}, names(args.OutputFormatFlags{OutputFilterChangedOnly: true}))
+	assert.Equal(t, []string{"web-config"}, names(args.OutputFormatFlags{OutputFilterChangedOnly: true, OutputFilterKind: []string{"ConfigMap"}}))
+
+	_, err := newCommandResultOutputFilter(args.OutputFormatFlags{OutputFilterName: []string{"["}})
+	assert.ErrorContains(t, err, "invalid output filter pattern")
+	_, err = newCommandResultOutputFilter(args.OutputFormatFlags{OutputFilterLabel: "a in"})
+	assert.ErrorContains(t, err, "invalid output filter label selector")
+
+	// matching objects keep their full changes
+	f, _ := newCommandResultOutputFilter(args.OutputFormatFlags{OutputFilterName: []string{"web-config"}})
+	s := formatCommandResultText(f.Apply(cr), false, false, false, objectYamlOptions{}, nil, nil)
+	assert.Contains(t, s, "data.a")
+	assert.NotContains(t, s, "team-a/Deployment/web")
+}
+
+func TestFormatCommandResultErrorCauses(t *testing.T) {
+	cr := buildJsonTestCommandResult()
+	cr.Errors = []result.DeploymentError{
+		{Ref: k8s.ObjectRef{Kind: "Deployment", Namespace: "ns", Name: "d"}, Message: "invalid", Causes: []result.ErrorCause{
+			{Field: "spec.replicas", Reason: "FieldValueInvalid", Message: "must be greater than or equal to 0"},
+			{Message: "no field"},
+		}},
+	}
+
+	s, err := formatCommandResult(cr, "text", true, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "  ns/Deployment/d: invalid\n    - spec.replicas: must be greater than or equal to 0 (FieldValueInvalid)\n    - no field\n")
+
+	s, err = formatCommandResult(cr, "yaml", true, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "field: spec.replicas")
+	assert.Contains(t, s, "reason: FieldValueInvalid")
+}
+
+func TestFormatCommandResultGroupedErrors(t *testing.T) {
+	cr := buildJsonTestCommandResult()
+	cr.Warnings = nil
+	for i := 14; i >= 0; i-- {
+		cr.Errors = append(cr.Errors, result.DeploymentError{
+			Ref:     k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: fmt.Sprintf("cm%02d", i)},
+			Message: "webhook xyz unavailable",
+		})
+	}
+	cr.Errors = append(cr.Errors,
+		result.DeploymentError{Ref: k8s.ObjectRef{Kind: "Deployment", Namespace: "ns", Name: "d2"}, Message: "another error"},
+		result.DeploymentError{Ref: k8s.ObjectRef{Kind: "Deployment", Namespace: "ns", Name: "d1"}, Message: "another error"},
+	)
+	orig := cr.DeepCopy()
+
+	s, err := formatCommandResult(cr, "text", true, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "\nErrors:\n  (x2 objects) another error\n    ns/Deployment/d1\n    ns/Deployment/d2\n  (x15 objects) webhook xyz unavailable\n    ns/ConfigMap/cm00\n")
+	assert.Contains(t, s, "    ns/ConfigMap/cm09\n    ... and 5 more\n")
+	assert.NotContains(t, s, "cm10")
+	assert.Contains(t, s, "17 errors")
+
+	// the result itself must stay untouched
+	assert.Equal(t, orig, cr)
+}
+
+func TestFormatCommandResultTimings(t *testing.T) {
+	d := func(s string) *metav1.Duration {
+		x, _ := time.ParseDuration(s)
+		return &metav1.Duration{Duration: x}
+	}
+
+	cr := buildJsonTestCommandResult()
+	cr.Timings = &result.CommandTimings{Render: d("2s"), Apply: d("10s")}
+	cr.Objects[0].Timings = &result.ObjectTimings{Apply: d("100ms")}
+	cr.Objects[1].Timings = &result.ObjectTimings{Apply: d("200ms"), WaitReadiness: d("5s")}
+	for i := 0; i < maxSlowestObjects; i++ {
+		cr.Objects = append(cr.Objects, result.ResultObject{BaseObject: result.BaseObject{
+			Ref:     k8s.ObjectRef{Version: "v1", Kind: "Job", Namespace: "ns", Name: fmt.Sprintf("hook%02d", i)},
+			Hook:    true,
+			Timings: &result.ObjectTimings{Apply: d("50ms"), WaitHook: d("1s")},
+		}})
+	}
+
+	s, err := formatCommandResult(cr, "text", true, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, s, "Slowest objects")
+
+	s, err = formatCommandResult(cr, "text", true, true, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "\nTimings:\n  render: 2s\n  apply: 10s\n")
+	assert.Contains(t, s, "\nSlowest objects:\n  ns/ConfigMap/cm: 5.2s\n    apply: 200ms\n    wait for readiness: 5s\n  ns/Job/hook00: 1.05s\n    apply: 50ms\n    wait for hook: 1s\n")
+	// only the slowest objects are printed
+	slowest := s[strings.Index(s, "Slowest objects:"):strings.Index(s, "Warnings:")]
+	assert.Contains(t, slowest, "ns/Job/hook08")
+	assert.NotContains(t, slowest, "ns/Job/hook09")
+	assert.NotContains(t, slowest, "ns/ConfigMap/new")
+
+	// timings are always part of the yaml output
+	s, err = formatCommandResult(cr, "yaml", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "waitReadiness: 5s")
+	assert.Contains(t, s, "render: 2s")
+}
+
+func TestFormatCommandResultShowIgnored(t *testing.T) {
+	cr := buildJsonTestCommandResult()
+	cr.Objects[1].IgnoredChanges = []result.Change{
+		{Type: "update", JsonPath: "spec.replicas", UnifiedDiff: "-5\n+1"},
+	}
+
+	s, err := formatCommandResult(cr, "text", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, s, "Ignored changes")
+
+	s, err = formatCommandResult(cr, "text", false, false, true, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "\nIgnored changes:\n  ns/ConfigMap/cm\n")
+	assert.Contains(t, s, "spec.replicas")
+
+	s, err = formatCommandResult(cr, "text", true, false, true, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "\nIgnored changes:\n  ns/ConfigMap/cm\n")
+	assert.NotContains(t, s, "spec.replicas")
+}
+
+func TestFormatCommandResultObjectYaml(t *testing.T) {
+	cr := buildJsonTestCommandResult()
+	cr.Objects = append(cr.Objects, result.ResultObject{
+		BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: "orphan"}, Orphan: true},
+		Remote:     cr.Objects[0].Rendered,
+	})
+
+	s, err := formatCommandResult(cr, "text", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, s, "Manifest of object")
+
+	s, err = formatCommandResult(cr, "text", false, false, false, objectYamlOptions{showNew: true}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "\nNew objects:\n  ns/ConfigMap/new\n\nManifest of object ns/ConfigMap/new\n  apiVersion: v1\n  data:\n    a: \"1\"\n")
+	assert.NotContains(t, s, "Manifest of object ns/ConfigMap/orphan")
+
+	s, err = formatCommandResult(cr, "text", false, false, false, objectYamlOptions{showDeleted: true, maxLines: 3}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, s, "Manifest of object ns/ConfigMap/new")
+	assert.Contains(t, s, "\nOrphan objects:\n  ns/ConfigMap/orphan\n\nManifest of object ns/ConfigMap/orphan\n  apiVersion: v1\n  data:\n    a: \"1\"\n  (truncated, 5 more lines")
+
+	// --short-output implies no manifests
+	s, err = formatCommandResult(cr, "text", true, false, false, objectYamlOptions{showNew: true, showDeleted: true}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, s, "Manifest of object")
+
+	s, err = formatCommandResult(cr, "markdown", false, false, false, objectYamlOptions{showNew: true}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "Manifest of `ns/ConfigMap/new`:\n\n```yaml\napiVersion: v1\n")
+}
+
+func TestFormatCommandResultSecurityRelevant(t *testing.T) {
+	cr := buildJsonTestCommandResult()
+
+	s, err := formatCommandResult(cr, "text", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, s, "Security-relevant changes")
+	assert.NotContains(t, s, "security-relevant")
+
+	cr.MarkSecurityRelevant([]string{"ConfigMap"})
+
+	// the section is always expanded, even with --short-output
+	s, err = formatCommandResult(cr, "text", true, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, ", 1 warnings, 2 security-relevant\n")
+	assert.Contains(t, s, "\nSecurity-relevant changes:\n  ns/ConfigMap/new (new)\n  ns/ConfigMap/cm\n\nManifest of object ns/ConfigMap/new\n")
+	assert.Contains(t, s, "Diff for object ns/ConfigMap/cm")
+	assert.Less(t, strings.Index(s, "Security-relevant changes"), strings.Index(s, "New objects"))
+
+	// diffs of security-relevant objects are never truncated
+	limits := &textOutputLimits{maxDiffLines: 1}
+	s, err = formatCommandResult(cr, "text", false, false, false, objectYamlOptions{}, limits, nil, nil)
+	assert.NoError(t, err)
+	section := s[strings.Index(s, "Security-relevant changes"):strings.Index(s, "New objects")]
+	assert.NotContains(t, section, "truncated")
+	assert.Contains(t, s[strings.Index(s, "Changed objects"):], "truncated")
+
+	y, err := formatCommandResult(cr, "yaml", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(y, "securityRelevant: true"))
+}
+
+func TestFormatCommandResultResourceDelta(t *testing.T) {
+	q := func(cpuRequests string, memoryRequests string) result.ResourceQuantities {
+		return result.ResourceQuantities{
+			CpuRequests:    resource.MustParse(cpuRequests),
+			CpuLimits:      resource.MustParse("0"),
+			MemoryRequests: resource.MustParse(memoryRequests),
+			MemoryLimits:   resource.MustParse("0"),
+		}
+	}
+
+	cr := buildJsonTestCommandResult()
+	cr.ResourceDelta = &result.ResourceDelta{
+		Total: q("500m", "-1Gi"),
+		Namespaces: []result.NamespaceResourceDelta{
+			{Namespace: "ns1", Delta: q("1500m", "0")},
+			{Namespace: "ns2", Delta: q("-1", "-1Gi")},
+		},
+	}
+
+	s, err := formatCommandResult(cr, "text", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, `
+Resource delta:
++----------+----------+--------+
+| Resource | Requests | Limits |
++----------+----------+--------+
+| cpu      | +500m    | 0      |
++----------+----------+--------+
+| memory   | -1Gi     | 0      |
++----------+----------+--------+
+`)
+
+	// the per-namespace breakdown is only part of the yaml output
+	assert.NotContains(t, s, "ns2")
+	s, err = formatCommandResult(cr, "yaml", false, false, false, objectYamlOptions{}, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "resourceDelta:\n")
+	assert.Contains(t, s, "- delta:\n")
+}
+
+func TestFormatCommandResultTextColor(t *testing.T) {
+	cr := buildJsonTestCommandResult()
+	cr.Objects[1].Changes[0].UnifiedDiff = "-1\n+2"
+
+	s, err := formatCommandResult(cr, "text", false, false, false, objectYamlOptions{}, &textOutputLimits{color: true}, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, s, ansiBold+"Diff for object ns/ConfigMap/cm"+ansiReset+"\n")
+	assert.Contains(t, s, ansiRed+"-1"+ansiReset)
+	assert.Contains(t, s, ansiGreen+"+2"+ansiReset)
+
+	// files and other formats are never colorized
+	for _, format := range []string{"text", "yaml", "json"} {
+		s, err = formatCommandResult(cr, format, false, false, false, objectYamlOptions{}, nil, nil, nil)
+		assert.NoError(t, err)
+		assert.NotContains(t, s, "\x1b[")
+	}
+}
+
+func TestOutputCommandResultObfuscationRules(t *testing.T) {
+	dir := t.TempDir()
+	rulesFile := filepath.Join(dir, "rules.yaml")
+	assert.NoError(t, os.WriteFile(rulesFile, []byte("- kind: ConfigMap\n  fieldPath: data.b\n"), 0o600))
+
+	run := func(noObfuscate bool) *result.CommandResult {
+		cr := buildJsonTestCommandResult()
+		cr.Fetches = []result.FetchTiming{}
+		cr.ProjectLock = &types.ProjectLock{}
+		cr.Command.Invocation = &result.InvocationInfo{}
+
+		flags := args.OutputFormatFlags{
+			OutputFormat:         []string{"yaml=" + filepath.Join(dir, "out.yaml")},
+			NoObfuscate:          noObfuscate,
+			ObfuscationRulesFile: rulesFile,
+		}
+		err := outputCommandResult(context.Background(), &commandCtx{}, flags, cr, false)
+		assert.NoError(t, err)
+		return cr
+	}
+
+	cr := run(false)
+	assert.Equal(t, map[string]any{"a": "1", "b": "*****"}, cr.Objects[0].Rendered.Object["data"])
+	assert.Equal(t, `"*****"`, string(cr.Objects[1].Changes[0].NewValue.Raw))
+	assert.Equal(t, `"1"`, string(cr.Objects[1].Changes[1].NewValue.Raw))
+
+	cr = run(true)
+	assert.Equal(t, map[string]any{"a": "1", "b": "2"}, cr.Objects[0].Rendered.Object["data"])
+	assert.Equal(t, `"2"`, string(cr.Objects[1].Changes[0].NewValue.Raw))
+}
+
+func TestOutputCommandResultGoTemplate(t *testing.T) {
+	dir := t.TempDir()
+	tmplFile := filepath.Join(dir, "t.tmpl")
+	outFile := filepath.Join(dir, "out.txt")
+	assert.NoError(t, os.WriteFile(tmplFile, []byte("{{ range .ChangedObjects }}{{ .Ref.Name }}\n{{ end }}"), 0o600))
+
+	flags := args.OutputFormatFlags{
+		OutputFormat: []string{"gotemplate=" + tmplFile + "=" + outFile},
+	}
+	err := outputCommandResult2(context.Background(), flags, buildJsonTestCommandResult(), nil)
+	assert.NoError(t, err)
+	b, err := os.ReadFile(outFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "cm\n", string(b))
+
+	spec, err := parseOutputSpec("gotemplate-string={{ .Summary.ChangedObjects }}=x")
+	assert.NoError(t, err)
+	assert.Nil(t, spec.path)
+	s, err := tmplreport.RenderCommandResult(spec.template, buildJsonTestCommandResult())
+	assert.NoError(t, err)
+	assert.Equal(t, "1=x", s)
+
+	_, err = parseOutputSpec("gotemplate")
+	assert.ErrorContains(t, err, "requires a template file")
+
+	// template errors are reported before anything is written
+	assert.NoError(t, os.WriteFile(tmplFile, []byte("line1\n{{ .Foo "), 0o600))
+	flags.OutputFormat = []string{"yaml=" + outFile + "2", "gotemplate=" + tmplFile}
+	err = outputCommandResult2(context.Background(), flags, buildJsonTestCommandResult(), nil)
+	assert.ErrorContains(t, err, tmplFile+":2:")
+	assert.NoFileExists(t, outFile+"2")
+}
+
+func TestOutputCommandResultArchive(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "result.tar.gz")
+
+	flags := args.OutputFormatFlags{
+		OutputFormat: []string{"archive=" + outFile},
+	}
+	cr := buildJsonTestCommandResult()
+	err := outputCommandResult2(context.Background(), flags, cr, nil)
+	assert.NoError(t, err)
+
+	md, cr2, err := results.ReadResultArchiveFile(outFile)
+	assert.NoError(t, err)
+	assert.Equal(t, results.ResultArchiveVersion, md.Version)
+	assert.Equal(t, cr.Id, cr2.Id)
+	assert.Len(t, cr2.Objects, len(cr.Objects))
+
+	_, err = parseOutputSpec("archive")
+	assert.ErrorContains(t, err, "requires an output file")
+	_, err = parseOutputSpec("archive=-")
+	assert.ErrorContains(t, err, "requires an output file")
+	_, err = parseOutputSpec("archive=+" + outFile)
+	assert.ErrorContains(t, err, "can't be appended")
+}
+
+func TestGetResultExportFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		output string
+		want   string
+	}{
+		{"", "-", "yaml"},
+		{"", "result.yaml", "yaml"},
+		{"", "result.tar.gz", "archive"},
+		{"", "result.tgz", "archive"},
+		{"yaml", "result.tar.gz", "yaml"},
+		{"archive", "result", "archive"},
+	} {
+		f, err := getResultExportFormat(tc.format, tc.output)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, f, "%s/%s", tc.format, tc.output)
+	}
+	_, err := getResultExportFormat("json", "-")
+	assert.ErrorContains(t, err, "invalid format")
+}
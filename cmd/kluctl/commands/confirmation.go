@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+const acknowledgeResponse = "ack"
+
+// countConfirmationCategories counts the warnings of the diff result per confirmation category. Ownership conflicts
+// are also reported as warnings, which are not counted again as generic warnings.
+func countConfirmationCategories(diffResult *result.CommandResult) map[types.ConfirmationCategory]int {
+	ret := map[types.ConfirmationCategory]int{}
+	for _, o := range diffResult.Objects {
+		if o.Orphan {
+			ret[types.ConfirmationCategoryOrphanObjects]++
+		}
+	}
+
+	conflictRefs := map[k8s.ObjectRef]bool{}
+	for _, c := range diffResult.OwnershipConflicts {
+		ret[types.ConfirmationCategoryOwnershipConflicts]++
+		conflictRefs[c.Ref] = true
+	}
+
+	for _, w := range diffResult.Warnings {
+		if w.Deprecation != nil {
+			ret[types.ConfirmationCategoryDeprecations]++
+		} else if !conflictRefs[w.Ref] {
+			ret[types.ConfirmationCategoryWarnings]++
+		}
+	}
+	return ret
+}
+
+func describeConfirmationCategory(c types.ConfirmationCategory, count int) string {
+	switch c {
+	case types.ConfirmationCategoryOrphanObjects:
+		return fmt.Sprintf("%d orphan objects", count)
+	case types.ConfirmationCategoryOwnershipConflicts:
+		return fmt.Sprintf("%d ownership conflicts", count)
+	case types.ConfirmationCategoryDeprecations:
+		return fmt.Sprintf("%d deprecation warnings", count)
+	default:
+		return fmt.Sprintf("%d warnings", count)
+	}
+}
+
+// buildConfirmationDecisions decides how each category of warnings found in the diff result is handled, based on the
+// confirmation policy of the target
+func buildConfirmationDecisions(policy *types.ConfirmationConfig, diffResult *result.CommandResult, interactive bool) *result.ConfirmationInfo {
+	counts := countConfirmationCategories(diffResult)
+
+	info := &result.ConfirmationInfo{
+		Interactive: interactive,
+	}
+	for _, c := range types.AllConfirmationCategories() {
+		count := counts[c]
+		if count == 0 {
+			continue
+		}
+		d := result.ConfirmationDecision{
+			Category: c,
+			Count:    count,
+			Decision: result.ConfirmationDisplayed,
+		}
+		ack := slices.Contains(policy.Acknowledge, c)
+		if interactive {
+			if ack {
+				// replaced after prompting
+				d.Decision = result.ConfirmationRejected
+			}
+		} else if slices.Contains(policy.AbortNonInteractive, c) {
+			d.Decision = result.ConfirmationAborted
+		} else if ack {
+			d.Decision = result.ConfirmationAutoConfirmed
+		}
+		info.Decisions = append(info.Decisions, d)
+	}
+	return info
+}
+
+func describeConfirmationDecisions(info *result.ConfirmationInfo, decision result.ConfirmationDecisionType) []string {
+	var ret []string
+	for _, d := range info.Decisions {
+		if d.Decision == decision {
+			ret = append(ret, describeConfirmationCategory(d.Category, d.Count))
+		}
+	}
+	return ret
+}
+
+// applyConfirmationPolicy applies the confirmation policy of the target to the diff result. In interactive mode, the
+// user is asked to acknowledge the categories that require acknowledgement. In non-interactive mode, an error is
+// returned if the diff contains categories that must abort the command. The returned info must be recorded in the
+// command result, even if an error is returned. It is nil if no policy is configured.
+func applyConfirmationPolicy(ctx context.Context, cmdCtx *commandCtx, diffResult *result.CommandResult, interactive bool) (*result.ConfirmationInfo, error) {
+	policy := cmdCtx.targetCtx.Target.Confirmation
+	if policy == nil {
+		return nil, nil
+	}
+
+	info := buildConfirmationDecisions(policy, diffResult, interactive)
+
+	if aborted := describeConfirmationDecisions(info, result.ConfirmationAborted); len(aborted) != 0 {
+		return info, fmt.Errorf("aborted, as the diff contains %s, which can not be confirmed non-interactively", strings.Join(aborted, ", "))
+	}
+
+	pending := describeConfirmationDecisions(info, result.ConfirmationRejected)
+	if len(pending) == 0 {
+		return info, nil
+	}
+
+	_, _ = getStderr(ctx).WriteString("The diff contains warnings that must be acknowledged:\n")
+	for _, s := range pending {
+		_, _ = getStderr(ctx).WriteString(fmt.Sprintf("  %s\n", s))
+	}
+	response, err := prompts.Prompt(ctx, false, fmt.Sprintf("Type '%s' to acknowledge these warnings: ", acknowledgeResponse))
+	if err != nil || strings.TrimSpace(response) != acknowledgeResponse {
+		return info, fmt.Errorf("aborted, as the warnings were not acknowledged")
+	}
+	for i := range info.Decisions {
+		if info.Decisions[i].Decision == result.ConfirmationRejected {
+			info.Decisions[i].Decision = result.ConfirmationAcknowledged
+		}
+	}
+	return info, nil
+}
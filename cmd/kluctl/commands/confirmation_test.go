@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPromptProvider struct {
+	response string
+	prompts  []string
+}
+
+func (p *testPromptProvider) Prompt(ctx context.Context, password bool, message string) (string, error) {
+	p.prompts = append(p.prompts, message)
+	return p.response, nil
+}
+
+func buildConfirmationTestDiffResult() *result.CommandResult {
+	ref := func(name string) k8s.ObjectRef {
+		return k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: name}
+	}
+	return &result.CommandResult{
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: ref("orphan1"), Orphan: true}},
+			{BaseObject: result.BaseObject{Ref: ref("orphan2"), Orphan: true}},
+			{BaseObject: result.BaseObject{Ref: ref("new"), New: true}},
+		},
+		OwnershipConflicts: []result.OwnershipConflict{
+			{Ref: ref("conflict"), OtherDiscriminator: "other"},
+		},
+		Warnings: []result.DeploymentError{
+			{Ref: ref("conflict"), Message: "ownership conflict"},
+			{Message: "deprecated", Deprecation: &result.DeprecationInfo{Id: "x"}},
+			{Ref: ref("new"), Message: "api warning"},
+		},
+	}
+}
+
+func newConfirmationTestCtx(policy *types.ConfirmationConfig, response string) (context.Context, *commandCtx, *testPromptProvider, *bytes.Buffer) {
+	pp := &testPromptProvider{response: response}
+	stderr := bytes.NewBuffer(nil)
+	ctx := prompts.NewContext(context.Background(), pp)
+	ctx = WithStdStreams(ctx, bytes.NewBuffer(nil), stderr)
+	cmdCtx := &commandCtx{
+		targetCtx: &target_context.TargetContext{
+			Target: types.Target{Confirmation: policy},
+		},
+	}
+	return ctx, cmdCtx, pp, stderr
+}
+
+func TestCountConfirmationCategories(t *testing.T) {
+	assert.Equal(t, map[types.ConfirmationCategory]int{
+		types.ConfirmationCategoryOrphanObjects:      2,
+		types.ConfirmationCategoryOwnershipConflicts: 1,
+		types.ConfirmationCategoryDeprecations:       1,
+		types.ConfirmationCategoryWarnings:           1,
+	}, countConfirmationCategories(buildConfirmationTestDiffResult()))
+}
+
+func TestApplyConfirmationPolicyInteractive(t *testing.T) {
+	policy := &types.ConfirmationConfig{
+		Acknowledge:         []types.ConfirmationCategory{types.ConfirmationCategoryOrphanObjects, types.ConfirmationCategoryOwnershipConflicts},
+		AbortNonInteractive: []types.ConfirmationCategory{types.ConfirmationCategoryOrphanObjects},
+	}
+
+	ctx, cmdCtx, pp, stderr := newConfirmationTestCtx(policy, "ack")
+	info, err := applyConfirmationPolicy(ctx, cmdCtx, buildConfirmationTestDiffResult(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Type 'ack' to acknowledge these warnings: "}, pp.prompts)
+	assert.Equal(t, "The diff contains warnings that must be acknowledged:\n  2 orphan objects\n  1 ownership conflicts\n", stderr.String())
+	assert.Equal(t, &result.ConfirmationInfo{
+		Interactive: true,
+		Decisions: []result.ConfirmationDecision{
+			{Category: types.ConfirmationCategoryOrphanObjects, Count: 2, Decision: result.ConfirmationAcknowledged},
+			{Category: types.ConfirmationCategoryOwnershipConflicts, Count: 1, Decision: result.ConfirmationAcknowledged},
+			{Category: types.ConfirmationCategoryDeprecations, Count: 1, Decision: result.ConfirmationDisplayed},
+			{Category: types.ConfirmationCategoryWarnings, Count: 1, Decision: result.ConfirmationDisplayed},
+		},
+	}, info)
+
+	// "yes" is not an acknowledgement
+	ctx, cmdCtx, _, _ = newConfirmationTestCtx(policy, "yes")
+	info, err = applyConfirmationPolicy(ctx, cmdCtx, buildConfirmationTestDiffResult(), true)
+	assert.EqualError(t, err, "aborted, as the warnings were not acknowledged")
+	assert.Equal(t, result.ConfirmationRejected, info.Decisions[0].Decision)
+	assert.Equal(t, result.ConfirmationRejected, info.Decisions[1].Decision)
+
+	// nothing to acknowledge
+	ctx, cmdCtx, pp, _ = newConfirmationTestCtx(policy, "")
+	info, err = applyConfirmationPolicy(ctx, cmdCtx, &result.CommandResult{}, true)
+	assert.NoError(t, err)
+	assert.Empty(t, pp.prompts)
+	assert.Equal(t, &result.ConfirmationInfo{Interactive: true}, info)
+}
+
+func TestApplyConfirmationPolicyNonInteractive(t *testing.T) {
+	policy := &types.ConfirmationConfig{
+		Acknowledge:         []types.ConfirmationCategory{types.ConfirmationCategoryOwnershipConflicts},
+		AbortNonInteractive: []types.ConfirmationCategory{types.ConfirmationCategoryOrphanObjects, types.ConfirmationCategoryDeprecations},
+	}
+
+	ctx, cmdCtx, pp, _ := newConfirmationTestCtx(policy, "")
+	info, err := applyConfirmationPolicy(ctx, cmdCtx, buildConfirmationTestDiffResult(), false)
+	assert.EqualError(t, err, "aborted, as the diff contains 2 orphan objects, 1 deprecation warnings, which can not be confirmed non-interactively")
+	assert.Empty(t, pp.prompts)
+	assert.Equal(t, &result.ConfirmationInfo{
+		Decisions: []result.ConfirmationDecision{
+			{Category: types.ConfirmationCategoryOrphanObjects, Count: 2, Decision: result.ConfirmationAborted},
+			{Category: types.ConfirmationCategoryOwnershipConflicts, Count: 1, Decision: result.ConfirmationAutoConfirmed},
+			{Category: types.ConfirmationCategoryDeprecations, Count: 1, Decision: result.ConfirmationAborted},
+			{Category: types.ConfirmationCategoryWarnings, Count: 1, Decision: result.ConfirmationDisplayed},
+		},
+	}, info)
+
+	// no policy, nothing recorded
+	ctx, cmdCtx, _, _ = newConfirmationTestCtx(nil, "")
+	info, err = applyConfirmationPolicy(ctx, cmdCtx, buildConfirmationTestDiffResult(), false)
+	assert.NoError(t, err)
+	assert.Nil(t, info)
+}
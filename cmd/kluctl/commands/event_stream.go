@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/kluctl/kluctl/v2/pkg/deployment/events"
+)
+
+// lockedWriter serializes writes with all other output written to the std streams
+type lockedWriter struct {
+	w io.Writer
+}
+
+func (w *lockedWriter) Write(b []byte) (int, error) {
+	stdStreamsMutex.Lock()
+	defer stdStreamsMutex.Unlock()
+	return w.w.Write(b)
+}
+
+type eventStream struct {
+	sink *events.JsonLinesSink
+	f    *os.File
+}
+
+// withEventStream installs a JSON lines event sink into the returned context if p is not empty. '-' streams the events
+// to stdout. The returned stream must be closed after the command has finished.
+func withEventStream(ctx context.Context, p string) (context.Context, *eventStream, error) {
+	if p == "" {
+		return ctx, nil, nil
+	}
+
+	es := &eventStream{}
+	if p == "-" {
+		stdout, _ := getStdStreams(ctx)
+		es.sink = events.NewJsonLinesSink(&lockedWriter{w: stdout})
+	} else {
+		err := os.MkdirAll(filepath.Dir(p), 0o755)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create directory for event stream %s: %w", p, err)
+		}
+		es.f, err = os.Create(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create event stream %s: %w", p, err)
+		}
+		es.sink = events.NewJsonLinesSink(es.f)
+	}
+	return events.NewContext(ctx, es.sink), es, nil
+}
+
+func (es *eventStream) close() error {
+	if es == nil {
+		return nil
+	}
+	err := es.sink.Err()
+	if es.f != nil {
+		if err2 := es.f.Close(); err == nil {
+			err = err2
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write event stream: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/version"
+	"github.com/spf13/pflag"
+)
+
+const redactedValue = "<redacted>"
+
+// buildInvocationInfo records the effective command line and the KLUCTL_ environment variables of the current
+// invocation. Values of flags marked as sensitive and of sensitive args are redacted. Environment variables are only
+// recorded with their values if they correspond to a non-sensitive flag of the current command.
+func buildInvocationInfo(ctx context.Context, sensitiveArgs map[string]bool) *result.InvocationInfo {
+	cmd := getCobraCommand(ctx)
+	if cmd == nil {
+		return nil
+	}
+
+	info := &result.InvocationInfo{
+		KluctlVersion: version.GetVersion(),
+		GoVersion:     runtime.Version(),
+		Platform:      fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+
+	info.Args = strings.Split(cmd.CommandPath(), " ")[1:]
+
+	var safeEnvPatterns []*regexp.Regexp
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		sensitive := isFlagSensitive(flag)
+		if !sensitive && !isFlagSkipEnv(flag) {
+			envName := strings.ToUpper(strings.ReplaceAll(flag.Name, "-", "_"))
+			safeEnvPatterns = append(safeEnvPatterns, regexp.MustCompile(fmt.Sprintf(`^KLUCTL_%s(_\d+)?$`, envName)))
+		}
+
+		if !flag.Changed && flag.Value.String() == flag.DefValue {
+			return
+		}
+		for _, v := range getFlagValues(flag) {
+			if sensitive {
+				v = redactedValue
+			} else if flag.Name == "arg" {
+				v = redactArgValue(v, sensitiveArgs)
+			}
+			info.Args = append(info.Args, fmt.Sprintf("--%s=%s", flag.Name, v))
+		}
+	})
+
+	for _, e := range os.Environ() {
+		n, v, _ := strings.Cut(e, "=")
+		if !strings.HasPrefix(n, "KLUCTL_") {
+			continue
+		}
+		safe := false
+		for _, r := range safeEnvPatterns {
+			if r.MatchString(n) {
+				safe = true
+				break
+			}
+		}
+		if !safe {
+			v = redactedValue
+		} else if strings.HasPrefix(n, "KLUCTL_ARG") {
+			v = redactArgValue(v, sensitiveArgs)
+		}
+		if info.Env == nil {
+			info.Env = map[string]string{}
+		}
+		info.Env[n] = v
+	}
+
+	return info
+}
+
+func isFlagSensitive(flag *pflag.Flag) bool {
+	a := flag.Annotations["sensitive"]
+	return len(a) != 0 && a[0] == "true"
+}
+
+func isFlagSkipEnv(flag *pflag.Flag) bool {
+	a := flag.Annotations["skipenv"]
+	return len(a) != 0 && a[0] == "true"
+}
+
+func getFlagValues(flag *pflag.Flag) []string {
+	if sv, ok := flag.Value.(pflag.SliceValue); ok {
+		return sv.GetSlice()
+	}
+	return []string{flag.Value.String()}
+}
+
+func redactArgValue(v string, sensitiveArgs map[string]bool) string {
+	name, _, found := strings.Cut(v, "=")
+	if !found {
+		return v
+	}
+	// nested args are passed as my.nested.arg=value
+	rootName, _, _ := strings.Cut(name, ".")
+	if sensitiveArgs[rootName] {
+		return name + "=" + redactedValue
+	}
+	return v
+}
+
+func getSensitiveArgs(cmdCtx *commandCtx) map[string]bool {
+	ret := map[string]bool{}
+	if cmdCtx == nil || cmdCtx.targetCtx == nil {
+		return ret
+	}
+	for _, n := range cmdCtx.targetCtx.KluctlProject.GetSensitiveArgs() {
+		ret[n] = true
+	}
+	return ret
+}
+
+// formatInvocation formats the invocation into a single line, suitable for the text output
+func formatInvocation(info *result.InvocationInfo) string {
+	args := append([]string{"kluctl"}, info.Args...)
+
+	var envNames []string
+	for n := range info.Env {
+		envNames = append(envNames, n)
+	}
+	sort.Strings(envNames)
+	var env []string
+	for _, n := range envNames {
+		env = append(env, fmt.Sprintf("%s=%s", n, info.Env[n]))
+	}
+
+	s := strings.Join(append(env, args...), " ")
+	return fmt.Sprintf("%s (kluctl %s, %s, %s)", s, info.KluctlVersion, info.GoVersion, info.Platform)
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/stretchr/testify/assert"
+)
+
+type invocationTestCmd struct {
+	args.KubeconfigFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.ArgsFlags
+}
+
+func (cmd *invocationTestCmd) Run(ctx context.Context) error {
+	return nil
+}
+
+func TestBuildInvocationInfoRedactsCredentials(t *testing.T) {
+	cmd, err := buildRootCobraCmd(&invocationTestCmd{}, "kluctl", "", "", nil)
+	assert.NoError(t, err)
+
+	err = cmd.ParseFlags([]string{
+		"--helm-creds=charts.example.com=user:s3cr3t-helmsecret",
+		"--helm-password=charts.example.com=s3cr3t-helmpassword",
+		"--registry-creds=registry.example.com=s3cr3t-registrysecret",
+		"--registry-password=registry.example.com=s3cr3t-registrypassword",
+		"--registry-token=registry.example.com=s3cr3t-registrytoken",
+		"--registry-identity-token=registry.example.com=s3cr3t-registryidentitytoken",
+		"--git-password=github.com=s3cr3t-gitpassword",
+		"--kubeconfig-from=exec:get-kubeconfig --token=s3cr3t-execsecret",
+		"--helm-username=charts.example.com=helm-user",
+		"--arg=password=s3cr3t-argsecret",
+		"--arg=name=visible",
+	})
+	assert.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), cobraCmdContextKey{}, cmd)
+	info := buildInvocationInfo(ctx, map[string]bool{"password": true})
+	joined := strings.Join(info.Args, " ")
+
+	for _, s := range []string{"s3cr3t-helmsecret", "s3cr3t-helmpassword", "s3cr3t-registrysecret", "s3cr3t-registrypassword", "s3cr3t-registrytoken",
+		"s3cr3t-registryidentitytoken", "s3cr3t-gitpassword", "s3cr3t-execsecret", "s3cr3t-argsecret"} {
+		assert.NotContains(t, joined, s)
+	}
+	assert.Contains(t, info.Args, "--helm-creds="+redactedValue)
+	assert.Contains(t, info.Args, "--registry-creds="+redactedValue)
+	assert.Contains(t, info.Args, "--kubeconfig-from="+redactedValue)
+	assert.Contains(t, info.Args, "--helm-username=charts.example.com=helm-user")
+	assert.Contains(t, info.Args, "--arg=password="+redactedValue)
+	assert.Contains(t, info.Args, "--arg=name=visible")
+}
@@ -0,0 +1,31 @@
+package commands
+
+import "strings"
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+)
+
+func colorize(s string, color string) string {
+	if s == "" {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// colorizeUnifiedDiff colors added lines green and removed lines red. Each line is colored individually, so that
+// truncating the output at line boundaries never leaves an unterminated escape sequence behind.
+func colorizeUnifiedDiff(diff string) string {
+	lines := strings.Split(diff, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, "+") {
+			lines[i] = colorize(l, ansiGreen)
+		} else if strings.HasPrefix(l, "-") {
+			lines[i] = colorize(l, ansiRed)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"strconv"
+	"strings"
+)
+
+func isFlagExplicitlySet(ctx context.Context, name string) bool {
+	cmd := getCobraCommand(ctx)
+	if cmd == nil {
+		return false
+	}
+	f := cmd.Flag(name)
+	if f == nil {
+		return false
+	}
+	// values passed via environment variables or the kluctl config file do not mark the flag as changed, so we also
+	// compare with the default value
+	return f.Changed || f.Value.String() != f.DefValue
+}
+
+// resolveOutputFormatFlags applies the output defaults found in the project config (.kluctl.yaml) to all flags that
+// were not explicitly passed via the command line. Target specific defaults take precedence over global defaults.
+func resolveOutputFormatFlags(ctx context.Context, cmdCtx *commandCtx, flags args.OutputFormatFlags) args.OutputFormatFlags {
+	var globalConfig, targetConfig *types.OutputConfig
+	targetName := ""
+	if cmdCtx != nil && cmdCtx.targetCtx != nil {
+		globalConfig = cmdCtx.targetCtx.KluctlProject.Config.Output
+		targetConfig = cmdCtx.targetCtx.Target.Output
+		targetName = cmdCtx.targetCtx.Target.Name
+	}
+
+	pickConfig := func(explicit bool, isSet func(c *types.OutputConfig) bool) (*types.OutputConfig, string) {
+		if explicit {
+			return nil, "command line"
+		}
+		if targetConfig != nil && isSet(targetConfig) {
+			return targetConfig, fmt.Sprintf("target '%s' in project config", targetName)
+		}
+		if globalConfig != nil && isSet(globalConfig) {
+			return globalConfig, "project config"
+		}
+		return nil, "default"
+	}
+
+	var sources [][3]string
+
+	c, source := pickConfig(isFlagExplicitlySet(ctx, "output-format"), func(c *types.OutputConfig) bool {
+		return len(c.Formats) != 0
+	})
+	if c != nil {
+		flags.OutputFormat = append([]string{}, c.Formats...)
+	}
+	outputFormat := "text"
+	if len(flags.OutputFormat) != 0 {
+		outputFormat = strings.Join(flags.OutputFormat, ",")
+	}
+	sources = append(sources, [3]string{"output-format", outputFormat, source})
+
+	c, source = pickConfig(isFlagExplicitlySet(ctx, "short-output"), func(c *types.OutputConfig) bool {
+		return c.ShortOutput != nil
+	})
+	if c != nil {
+		flags.ShortOutput = *c.ShortOutput
+	}
+	sources = append(sources, [3]string{"short-output", strconv.FormatBool(flags.ShortOutput), source})
+
+	c, source = pickConfig(isFlagExplicitlySet(ctx, "no-obfuscate"), func(c *types.OutputConfig) bool {
+		return c.NoObfuscate != nil
+	})
+	if c != nil {
+		// validation ensures that the project config can only enforce obfuscation
+		flags.NoObfuscate = *c.NoObfuscate
+	}
+	sources = append(sources, [3]string{"no-obfuscate", strconv.FormatBool(flags.NoObfuscate), source})
+
+	if flags.ShowEffectiveFlags {
+		status.Info(ctx, "Effective output flags:")
+		for _, x := range sources {
+			status.Infof(ctx, "  --%s=%s (from %s)", x[0], x[1], x[2])
+		}
+	}
+
+	return flags
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOutputDefaultsTestCmdCtx(globalConfig *types.OutputConfig, targetConfig *types.OutputConfig) *commandCtx {
+	p := &kluctl_project.LoadedKluctlProject{}
+	p.Config.Output = globalConfig
+	return &commandCtx{
+		targetCtx: &target_context.TargetContext{
+			KluctlProject: p,
+			Target: types.Target{
+				Name:   "test",
+				Output: targetConfig,
+			},
+		},
+	}
+}
+
+func TestResolveOutputFormatFlags(t *testing.T) {
+	globalConfig := &types.OutputConfig{
+		Formats:     []string{"yaml"},
+		ShortOutput: utils.Ptr(true),
+	}
+	targetConfig := &types.OutputConfig{
+		Formats: []string{"json"},
+	}
+
+	// no project config, flags are kept as they are
+	ctx, flags := newOutputLimitsTestCtx(t, true)
+	r := resolveOutputFormatFlags(ctx, nil, flags)
+	assert.Empty(t, r.OutputFormat)
+	assert.False(t, r.ShortOutput)
+
+	// global defaults apply when no flag is set
+	ctx, flags = newOutputLimitsTestCtx(t, true)
+	r = resolveOutputFormatFlags(ctx, newOutputDefaultsTestCmdCtx(globalConfig, nil), flags)
+	assert.Equal(t, []string{"yaml"}, r.OutputFormat)
+	assert.True(t, r.ShortOutput)
+
+	// target defaults take precedence over global defaults
+	ctx, flags = newOutputLimitsTestCtx(t, true)
+	r = resolveOutputFormatFlags(ctx, newOutputDefaultsTestCmdCtx(globalConfig, targetConfig), flags)
+	assert.Equal(t, []string{"json"}, r.OutputFormat)
+	assert.True(t, r.ShortOutput)
+
+	// explicitly set flags override all defaults, even when set to the flag's default value
+	ctx, flags = newOutputLimitsTestCtx(t, true, "--output-format=text", "--short-output=false")
+	r = resolveOutputFormatFlags(ctx, newOutputDefaultsTestCmdCtx(globalConfig, targetConfig), flags)
+	assert.Equal(t, []string{"text"}, r.OutputFormat)
+	assert.False(t, r.ShortOutput)
+
+	// only the explicitly set flag is overridden
+	ctx, flags = newOutputLimitsTestCtx(t, true, "--output-format=text")
+	r = resolveOutputFormatFlags(ctx, newOutputDefaultsTestCmdCtx(globalConfig, targetConfig), flags)
+	assert.Equal(t, []string{"text"}, r.OutputFormat)
+	assert.True(t, r.ShortOutput)
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// parseOutputPath strips the '+' prefix from the given output path and reports whether the output should be appended
+// to the file instead of replacing it
+func parseOutputPath(p string) (string, bool) {
+	if strings.HasPrefix(p, "+") {
+		return p[1:], true
+	}
+	return p, false
+}
+
+// writeOutputFile writes the output to the given file, creating missing parent directories. Paths prefixed with '+'
+// are appended to, with yaml documents being separated by '---' if yamlDoc is true. Writes to new and regular files are
+// atomic, so that readers never see partially written files. Symlinks and special files (e.g. /dev/stdout) are written
+// to directly, as replacing them is not possible or not desired.
+func writeOutputFile(p string, content string, yamlDoc bool) error {
+	p, appendOutput := parseOutputPath(p)
+
+	dir := filepath.Dir(p)
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory for output file %s: %w", p, err)
+	}
+
+	if appendOutput {
+		return appendOutputFile(p, content, yamlDoc)
+	}
+	if st, err := os.Lstat(p); err == nil && !st.Mode().IsRegular() {
+		return writeOutputFileDirect(p, content)
+	}
+	return writeOutputFileAtomic(p, content)
+}
+
+func writeOutputFileDirect(p string, content string) error {
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
+	if err != nil {
+		return fmt.Errorf("failed to open output file %s: %w", p, err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(content)
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", p, err)
+	}
+	return nil
+}
+
+func appendOutputFile(p string, content string, yamlDoc bool) error {
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o666)
+	if err != nil {
+		return fmt.Errorf("failed to open output file %s: %w", p, err)
+	}
+	defer f.Close()
+
+	if yamlDoc {
+		st, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if st.Size() != 0 {
+			content = "---\n" + content
+		}
+	}
+
+	_, err = f.WriteString(content)
+	if err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", p, err)
+	}
+	return nil
+}
+
+func writeOutputFileAtomic(p string, content string) error {
+	mode := os.FileMode(0o644)
+	if st, err := os.Stat(p); err == nil {
+		mode = st.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create output file %s: %w", p, err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = tmp.WriteString(content)
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if err == nil {
+		err = tmp.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", p, err)
+	}
+	err = os.Rename(tmp.Name(), p)
+	if err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", p, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "sub", "dir", "out.yaml")
+
+	// parent directories are created
+	assert.NoError(t, writeOutputFile(p, "a: 1\n", true))
+	assert.NoError(t, writeOutputFile(p, "a: 2\n", true))
+	b, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "a: 2\n", string(b))
+
+	assert.NoError(t, writeOutputFile("+"+p, "a: 3\n", true))
+	assert.NoError(t, writeOutputFile("+"+p, "a: 4\n", true))
+	b, err = os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "a: 2\n---\na: 3\n---\na: 4\n", string(b))
+
+	// non-yaml output is appended as is, also to new files
+	p2 := filepath.Join(dir, "out.txt")
+	assert.NoError(t, writeOutputFile("+"+p2, "x\n", false))
+	assert.NoError(t, writeOutputFile("+"+p2, "y\n", false))
+	b, err = os.ReadFile(p2)
+	assert.NoError(t, err)
+	assert.Equal(t, "x\ny\n", string(b))
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(p))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteOutputFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "file")
+	assert.NoError(t, os.WriteFile(f, []byte("x"), 0o600))
+
+	err := writeOutputFile(filepath.Join(f, "out.yaml"), "a: 1\n", true)
+	assert.ErrorContains(t, err, "failed to create directory for output file "+filepath.Join(f, "out.yaml"))
+}
+
+func TestWriteOutputFileKeepsMode(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "out.yaml")
+	assert.NoError(t, os.WriteFile(p, []byte("x"), 0o600))
+	assert.NoError(t, writeOutputFile(p, "a: 1\n", true))
+	st, err := os.Stat(p)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), st.Mode().Perm())
+}
+
+func TestWriteOutputFileNonRegular(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	dir := t.TempDir()
+
+	// same as /dev/stdout, which is a symlink to a special file
+	devNull := filepath.Join(dir, "null")
+	assert.NoError(t, os.Symlink(os.DevNull, devNull))
+	assert.NoError(t, writeOutputFile(devNull, "a: 1\n", true))
+	st, err := os.Lstat(devNull)
+	assert.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, st.Mode().Type())
+	st, err = os.Stat(os.DevNull)
+	assert.NoError(t, err)
+	assert.Equal(t, os.ModeDevice|os.ModeCharDevice, st.Mode().Type())
+
+	// symlinks to regular files are written through and kept
+	target := filepath.Join(dir, "target.yaml")
+	assert.NoError(t, os.WriteFile(target, []byte("old: x\nmore: y\n"), 0o600))
+	link := filepath.Join(dir, "link.yaml")
+	assert.NoError(t, os.Symlink(target, link))
+	assert.NoError(t, writeOutputFile(link, "a: 1\n", true))
+	st, err = os.Lstat(link)
+	assert.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, st.Mode().Type())
+	b, err := os.ReadFile(target)
+	assert.NoError(t, err)
+	assert.Equal(t, "a: 1\n", string(b))
+}
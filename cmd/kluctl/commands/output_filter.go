@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// commandResultOutputFilter filters the objects of command results before they are printed. It is never applied to
+// results written to the result store.
+type commandResultOutputFilter struct {
+	kinds       []string
+	namespaces  []string
+	names       []string
+	selector    labels.Selector
+	changedOnly bool
+}
+
+func splitFilterValues(values []string) []string {
+	var ret []string
+	for _, v := range values {
+		for _, x := range strings.Split(v, ",") {
+			x = strings.TrimSpace(x)
+			if x != "" {
+				ret = append(ret, x)
+			}
+		}
+	}
+	return ret
+}
+
+// newCommandResultOutputFilter returns nil if no filtering was requested
+func newCommandResultOutputFilter(flags args.OutputFormatFlags) (*commandResultOutputFilter, error) {
+	f := &commandResultOutputFilter{
+		kinds:       splitFilterValues(flags.OutputFilterKind),
+		namespaces:  splitFilterValues(flags.OutputFilterNamespace),
+		names:       splitFilterValues(flags.OutputFilterName),
+		changedOnly: flags.OutputFilterChangedOnly,
+	}
+	for _, p := range append(append([]string{}, f.namespaces...), f.names...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid output filter pattern '%s': %w", p, err)
+		}
+	}
+	if flags.OutputFilterLabel != "" {
+		s, err := labels.Parse(flags.OutputFilterLabel)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output filter label selector: %w", err)
+		}
+		f.selector = s
+	}
+
+	if len(f.kinds) == 0 && len(f.namespaces) == 0 && len(f.names) == 0 && f.selector == nil && !f.changedOnly {
+		return nil, nil
+	}
+	return f, nil
+}
+
+func matchAnyPattern(patterns []string, s string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if m, _ := path.Match(p, s); m {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *commandResultOutputFilter) matchKind(o *result.ResultObject) bool {
+	if len(f.kinds) == 0 {
+		return true
+	}
+	for _, k := range f.kinds {
+		if result.MatchKindSelector(o.Ref, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *commandResultOutputFilter) matchLabels(o *result.ResultObject) bool {
+	if f.selector == nil {
+		return true
+	}
+	// prefer the rendered object, as it reflects the desired state
+	x := o.Rendered
+	if x == nil {
+		x = o.Remote
+	}
+	if x == nil {
+		x = o.Applied
+	}
+	if x == nil {
+		x = o.DeletedManifest
+	}
+	if x == nil {
+		return false
+	}
+	return f.selector.Matches(labels.Set(x.GetK8sLabels()))
+}
+
+func (f *commandResultOutputFilter) Match(o *result.ResultObject) bool {
+	if f.changedOnly && !o.New && !o.Deleted && len(o.Changes) == 0 {
+		return false
+	}
+	return f.matchKind(o) &&
+		matchAnyPattern(f.namespaces, o.Ref.Namespace) &&
+		matchAnyPattern(f.names, o.Ref.Name) &&
+		f.matchLabels(o)
+}
+
+// Apply returns a shallow copy of the command result that only contains the matching objects. Errors, warnings and
+// all other parts of the result are kept as they are.
+func (f *commandResultOutputFilter) Apply(cr *result.CommandResult) *result.CommandResult {
+	ret := *cr
+	ret.Objects = nil
+	for i := range cr.Objects {
+		if f.Match(&cr.Objects[i]) {
+			ret.Objects = append(ret.Objects, cr.Objects[i])
+		}
+	}
+	return &ret
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/lib/term"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/mattn/go-isatty"
+)
+
+// we must determine this before anything has a chance to override os.Stdout
+var isStdoutTerminal = isatty.IsTerminal(os.Stdout.Fd())
+
+// defaultTableWidth is used when stdout is not a terminal, so that redirected output does not depend on the
+// environment
+const defaultTableWidth = 120
+
+// textOutputLimits controls truncation, paging, colors and table widths of the 'text' output. It is only used when
+// the output goes to stdout, files are never truncated or colorized.
+type textOutputLimits struct {
+	maxLines     int
+	maxDiffLines int
+	noPager      bool
+	color        bool
+	tableWidth   int
+	terminal     bool
+}
+
+func isStdoutTerminalCtx(ctx context.Context) bool {
+	if stdout, _ := getStdStreams(ctx); stdout != os.Stdout {
+		return false
+	}
+	return isStdoutTerminal
+}
+
+// newTableOutputLimits creates limits that only control table widths, for commands that don't support the other
+// output limits
+func newTableOutputLimits(ctx context.Context, flags args.TableWidthFlags) *textOutputLimits {
+	return &textOutputLimits{
+		noPager:    true,
+		tableWidth: flags.TableWidth,
+		terminal:   isStdoutTerminalCtx(ctx),
+	}
+}
+
+func newTextOutputLimits(ctx context.Context, flags args.OutputFormatFlags) (*textOutputLimits, error) {
+	l := &textOutputLimits{
+		maxLines:     flags.MaxOutputLines,
+		maxDiffLines: flags.MaxDiffLines,
+		noPager:      flags.NoPager,
+		tableWidth:   flags.TableWidth,
+		terminal:     isStdoutTerminalCtx(ctx),
+	}
+	if flags.Full {
+		l.maxLines = 0
+		l.maxDiffLines = 0
+	}
+	// the default limits are meant to protect interactive terminals, while CI logs and redirected output should be
+	// complete unless limits are explicitly requested
+	if !l.terminal {
+		if !isFlagExplicitlySet(ctx, "max-output-lines") {
+			l.maxLines = 0
+		}
+		if !isFlagExplicitlySet(ctx, "max-diff-lines") {
+			l.maxDiffLines = 0
+		}
+	}
+
+	switch flags.Color {
+	case "", "auto":
+		l.color = l.terminal && os.Getenv("NO_COLOR") == "" && !isNoColor(ctx)
+	case "always":
+		l.color = true
+	case "never":
+		l.color = false
+	default:
+		return nil, fmt.Errorf("invalid --color '%s', must be one of auto, always or never", flags.Color)
+	}
+	return l, nil
+}
+
+// getTableWidth returns the total width available for tables. It is safe to call on nil limits, which is the case
+// for output written to files.
+func (l *textOutputLimits) getTableWidth() int {
+	if l != nil && l.tableWidth > 0 {
+		return l.tableWidth
+	}
+	if l != nil && l.terminal {
+		return term.GetWidth()
+	}
+	return defaultTableWidth
+}
+
+func truncatedMarker(n int) string {
+	return fmt.Sprintf("(truncated, %d more lines — see yaml output or use --full)\n", n)
+}
+
+// truncateLines keeps the first maxLines lines of s and replaces the remaining lines with a marker
+func truncateLines(s string, maxLines int) string {
+	return truncateLinesWithMarker(s, maxLines, truncatedMarker)
+}
+
+// truncateLinesWithMarker is the same as truncateLines, but with a custom marker
+func truncateLinesWithMarker(s string, maxLines int, marker func(n int) string) string {
+	if maxLines <= 0 {
+		return s
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= maxLines {
+		return s
+	}
+	ret := strings.Join(lines[:maxLines], "")
+	if !strings.HasSuffix(ret, "\n") {
+		ret += "\n"
+	}
+	return ret + marker(len(lines)-maxLines)
+}
+
+func (l *textOutputLimits) usePager(ctx context.Context, s string) bool {
+	if l == nil || l.noPager || !l.terminal {
+		return false
+	}
+	return strings.Count(s, "\n") >= term.GetHeight()
+}
+
+// outputWithPager pipes s through $PAGER (or less if not set). It falls back to printing directly to stdout if the
+// pager can't be started.
+func outputWithPager(ctx context.Context, s string) error {
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -R"
+	}
+	pagerArgs := strings.Fields(pager)
+	if len(pagerArgs) == 0 {
+		status.Warningf(ctx, "Invalid PAGER '%s', printing output directly", pager)
+		return outputResult(ctx, nil, s, false)
+	}
+
+	cmd := exec.CommandContext(ctx, pagerArgs[0], pagerArgs[1:]...)
+	cmd.Stdin = strings.NewReader(s)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = origStderr
+	err := cmd.Start()
+	if err != nil {
+		status.Warningf(ctx, "Failed to start pager '%s', printing output directly: %s", pager, err.Error())
+		return outputResult(ctx, nil, s, false)
+	}
+
+	stdStreamsMutex.Lock()
+	defer stdStreamsMutex.Unlock()
+	return cmd.Wait()
+}
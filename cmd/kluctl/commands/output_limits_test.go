@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateLines(t *testing.T) {
+	assert.Equal(t, "a\nb\nc\n", truncateLines("a\nb\nc\n", 0))
+	assert.Equal(t, "a\nb\nc\n", truncateLines("a\nb\nc\n", 3))
+	assert.Equal(t, "a\nb\nc", truncateLines("a\nb\nc", 3))
+	assert.Equal(t, "a\n"+truncatedMarker(2), truncateLines("a\nb\nc\n", 1))
+	// a missing trailing newline is not counted as additional line
+	assert.Equal(t, "a\nb\n"+truncatedMarker(1), truncateLines("a\nb\nc", 2))
+	assert.Equal(t, "", truncateLines("", 1))
+
+	assert.Equal(t, "a\n...\n", truncateLinesWithMarker("a\nb\n", 1, func(n int) string {
+		return "...\n"
+	}))
+}
+
+type outputLimitsTestCmd struct {
+	args.OutputFormatFlags
+}
+
+func (cmd *outputLimitsTestCmd) Run(ctx context.Context) error {
+	return nil
+}
+
+func newOutputLimitsTestCtx(t *testing.T, terminal bool, flagArgs ...string) (context.Context, args.OutputFormatFlags) {
+	oldTerminal := isStdoutTerminal
+	isStdoutTerminal = terminal
+	t.Cleanup(func() {
+		isStdoutTerminal = oldTerminal
+	})
+
+	c := &outputLimitsTestCmd{}
+	cmd, err := buildRootCobraCmd(c, "kluctl", "", "", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, cmd.ParseFlags(flagArgs))
+	return context.WithValue(context.Background(), cobraCmdContextKey{}, cmd), c.OutputFormatFlags
+}
+
+func TestNewTextOutputLimits(t *testing.T) {
+	ctx, flags := newOutputLimitsTestCtx(t, true)
+	l, err := newTextOutputLimits(ctx, flags)
+	assert.NoError(t, err)
+	assert.Equal(t, 10000, l.maxLines)
+	assert.Equal(t, 1000, l.maxDiffLines)
+
+	ctx, flags = newOutputLimitsTestCtx(t, true, "--full")
+	l, err = newTextOutputLimits(ctx, flags)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, l.maxLines)
+	assert.Equal(t, 0, l.maxDiffLines)
+
+	// the default limits don't apply to CI logs and redirected output
+	ctx, flags = newOutputLimitsTestCtx(t, false)
+	l, err = newTextOutputLimits(ctx, flags)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, l.maxLines)
+	assert.Equal(t, 0, l.maxDiffLines)
+
+	ctx, flags = newOutputLimitsTestCtx(t, false, "--max-output-lines=50", "--max-diff-lines=5")
+	l, err = newTextOutputLimits(ctx, flags)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, l.maxLines)
+	assert.Equal(t, 5, l.maxDiffLines)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"os"
+	"sync"
 )
 
 type overrideStdStreamsKey int
@@ -14,6 +15,10 @@ type overrideStdStreamsValue struct {
 
 var overrideStdStreamsKeyInst overrideStdStreamsKey
 
+// stdStreamsMutex serializes command output and status output, so that late status writes can't interleave with
+// command output when both are written to the same stream
+var stdStreamsMutex sync.Mutex
+
 func WithStdStreams(ctx context.Context, stdout io.Writer, stderr io.Writer) context.Context {
 	return context.WithValue(ctx, overrideStdStreamsKeyInst, &overrideStdStreamsValue{
 		stdout: stdout,
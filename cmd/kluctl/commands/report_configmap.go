@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/results/clusternotify"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+// getConfigMapNotificationConfig returns the ConfigMap notification config of the target, falling back to the one of
+// the project
+func getConfigMapNotificationConfig(cmdCtx *commandCtx) *types.ConfigMapNotificationConfig {
+	if cmdCtx.targetCtx == nil {
+		return nil
+	}
+	if c := cmdCtx.targetCtx.Target.Notifications; c != nil && c.ConfigMap != nil {
+		return c.ConfigMap
+	}
+	if c := cmdCtx.targetCtx.KluctlProject.Config.Notifications; c != nil && c.ConfigMap != nil {
+		return c.ConfigMap
+	}
+	return nil
+}
+
+// writeConfigMapNotification writes a compact notification about the command result as ConfigMap into the target
+// cluster. Like notifySlack, it must be called before the result is written and output, as failures are added as
+// warnings to the command result instead of failing the command.
+func writeConfigMapNotification(ctx context.Context, cmdCtx *commandCtx, cr *result.CommandResult) {
+	err := doWriteConfigMapNotification(ctx, cmdCtx, cr)
+	if err != nil {
+		err = fmt.Errorf("failed to write deploy notification: %w", err)
+		status.Warning(ctx, err.Error())
+		cr.Warnings = append(cr.Warnings, result.DeploymentError{Message: err.Error()})
+	}
+}
+
+func doWriteConfigMapNotification(ctx context.Context, cmdCtx *commandCtx, cr *result.CommandResult) error {
+	if cmdCtx.notifyFlags == nil || cr.Command.DryRun || cr.Command.ReadOnly {
+		return nil
+	}
+	c := getConfigMapNotificationConfig(cmdCtx)
+	if c == nil {
+		return nil
+	}
+	k := cmdCtx.targetCtx.SharedContext.K
+	if k == nil {
+		return nil
+	}
+
+	namespace := c.Namespace
+	if namespace == "" {
+		namespace = results.DefaultResultsNamespace
+	}
+	keep := clusternotify.DefaultKeep
+	if c.Keep != nil {
+		keep = *c.Keep
+	}
+
+	cm, err := clusternotify.BuildConfigMap(cr, namespace)
+	if err != nil {
+		return err
+	}
+	client, err := k.ToClient()
+	if err != nil {
+		return err
+	}
+	err = clusternotify.Write(ctx, client, cm, keep)
+	if err != nil {
+		return err
+	}
+	status.Infof(ctx, "Wrote deploy notification %s/%s", cm.Namespace, cm.Name)
+	return nil
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/results/github"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+// reportGithubPR posts the command result as pull request comment. It must be called before the result is output,
+// as failures are added as warnings to the command result instead of failing the command.
+func reportGithubPR(ctx context.Context, flags args.GithubPRReportFlags, obfuscator *diff.Obfuscator, cr *result.CommandResult) {
+	if !flags.GithubComment {
+		return
+	}
+	err := doReportGithubPR(ctx, flags, obfuscator, cr)
+	if err != nil {
+		err = fmt.Errorf("failed to post command result to GitHub pull request: %w", err)
+		status.Warning(ctx, err.Error())
+		cr.Warnings = append(cr.Warnings, result.DeploymentError{Message: err.Error()})
+	}
+}
+
+// getGithubPRFromRef extracts the pull request number from refs in the form 'refs/pull/<number>/merge', which is what
+// GitHub Actions sets GITHUB_REF to for pull request events
+func getGithubPRFromRef(ref string) (int, bool) {
+	s, ok := strings.CutPrefix(ref, "refs/pull/")
+	if !ok {
+		return 0, false
+	}
+	s, _, _ = strings.Cut(s, "/")
+	pr, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return pr, true
+}
+
+func doReportGithubPR(ctx context.Context, flags args.GithubPRReportFlags, obfuscator *diff.Obfuscator, cr *result.CommandResult) error {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return fmt.Errorf("GITHUB_TOKEN is not set")
+	}
+	repo := getEnvDefault(flags.GithubRepo, "GITHUB_REPOSITORY")
+	if repo == "" {
+		return fmt.Errorf("no GitHub repository specified, use --github-repo")
+	}
+	pr := flags.GithubPR
+	if pr == 0 {
+		x, ok := getGithubPRFromRef(os.Getenv("GITHUB_REF"))
+		if !ok {
+			return fmt.Errorf("no pull request specified, use --github-pr")
+		}
+		pr = x
+	}
+
+	cr2, err := obfuscatedResultCopy(obfuscator, cr)
+	if err != nil {
+		return err
+	}
+
+	marker := github.Marker(cr.Target.Name)
+	body := github.BuildComment(cr2, marker, github.MaxCommentLength)
+
+	c := github.NewClient(getEnvDefault(flags.GithubApiURL, "GITHUB_API_URL"), token)
+	_, err = c.UpsertComment(ctx, repo, pr, marker, body)
+	if err != nil {
+		return err
+	}
+	status.Infof(ctx, "Posted command result to pull request #%d of %s", pr, repo)
+	return nil
+}
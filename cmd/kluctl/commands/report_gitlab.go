@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/results/gitlab"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+func getEnvDefault(v string, envName string) string {
+	if v != "" {
+		return v
+	}
+	return os.Getenv(envName)
+}
+
+// obfuscatedResultCopy returns an obfuscated copy of the command result. Merge/pull request comments are always
+// obfuscated, as these are usually visible to a much larger audience than the command output itself.
+func obfuscatedResultCopy(obfuscator *diff.Obfuscator, cr *result.CommandResult) (*result.CommandResult, error) {
+	cr2 := cr.DeepCopy()
+	err := obfuscator.ObfuscateResult(cr2)
+	if err != nil {
+		return nil, err
+	}
+	return cr2, nil
+}
+
+// reportGitlabMR posts the command result as merge request note. It must be called before the result is output, as
+// failures are added as warnings to the command result instead of failing the command.
+func reportGitlabMR(ctx context.Context, flags args.GitlabMRReportFlags, obfuscator *diff.Obfuscator, cr *result.CommandResult) {
+	if !flags.GitlabComment {
+		return
+	}
+	err := doReportGitlabMR(ctx, flags, obfuscator, cr)
+	if err != nil {
+		err = fmt.Errorf("failed to post command result to GitLab merge request: %w", err)
+		status.Warning(ctx, err.Error())
+		cr.Warnings = append(cr.Warnings, result.DeploymentError{Message: err.Error()})
+	}
+}
+
+func doReportGitlabMR(ctx context.Context, flags args.GitlabMRReportFlags, obfuscator *diff.Obfuscator, cr *result.CommandResult) error {
+	gitlabURL := getEnvDefault(flags.GitlabURL, "CI_SERVER_URL")
+	var c *gitlab.Client
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		c = gitlab.NewClient(gitlabURL, token)
+	} else if jobToken := os.Getenv("CI_JOB_TOKEN"); jobToken != "" {
+		c = gitlab.NewJobTokenClient(gitlabURL, jobToken)
+	} else {
+		return fmt.Errorf("neither GITLAB_TOKEN nor CI_JOB_TOKEN is set")
+	}
+
+	project := getEnvDefault(flags.GitlabProject, "CI_PROJECT_ID")
+	if project == "" {
+		return fmt.Errorf("no GitLab project specified, use --gitlab-project")
+	}
+	mrIID := flags.GitlabMR
+	if mrIID == 0 {
+		s := os.Getenv("CI_MERGE_REQUEST_IID")
+		if s == "" {
+			return fmt.Errorf("no merge request specified, use --gitlab-mr")
+		}
+		x, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid CI_MERGE_REQUEST_IID: %w", err)
+		}
+		mrIID = x
+	}
+
+	cr2, err := obfuscatedResultCopy(obfuscator, cr)
+	if err != nil {
+		return err
+	}
+
+	marker := gitlab.Marker(cr.Target.Name, cr.TargetKey.Discriminator)
+	body := gitlab.BuildNote(cr2, marker, gitlab.MaxNoteLength, getEnvDefault(flags.GitlabArtifactURL, "CI_JOB_URL"))
+
+	_, err = c.UpsertNote(ctx, project, mrIID, marker, body)
+	if err != nil {
+		return err
+	}
+	status.Infof(ctx, "Posted command result to merge request !%d of %s", mrIID, project)
+	return nil
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/results/slack"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+// getSlackNotificationConfig returns the Slack notification config of the target, falling back to the one of the
+// project
+func getSlackNotificationConfig(cmdCtx *commandCtx) *types.SlackNotificationConfig {
+	if cmdCtx.targetCtx == nil {
+		return nil
+	}
+	if c := cmdCtx.targetCtx.Target.Notifications; c != nil && c.Slack != nil {
+		return c.Slack
+	}
+	if c := cmdCtx.targetCtx.KluctlProject.Config.Notifications; c != nil && c.Slack != nil {
+		return c.Slack
+	}
+	return nil
+}
+
+// resolveSlackNotification returns the webhook and webui URLs to use for Slack notifications. Flags take precedence
+// over the configuration found in .kluctl.yaml. An empty webhook URL means that notifications are disabled.
+func resolveSlackNotification(cmdCtx *commandCtx) (string, string, error) {
+	if cmdCtx.notifyFlags == nil {
+		return "", "", nil
+	}
+	webhookURL := cmdCtx.notifyFlags.NotifySlackWebhook
+	webuiURL := cmdCtx.notifyFlags.NotifyWebuiURL
+
+	c := getSlackNotificationConfig(cmdCtx)
+	if c != nil {
+		if webhookURL == "" {
+			webhookURL = os.Getenv(c.WebhookUrlEnv)
+			if webhookURL == "" {
+				return "", "", fmt.Errorf("environment variable %s is not set", c.WebhookUrlEnv)
+			}
+		}
+		if webuiURL == "" {
+			webuiURL = c.WebuiUrl
+		}
+	}
+	return webhookURL, webuiURL, nil
+}
+
+// notifySlack posts a notification about the command result to Slack. It must be called before the result is
+// written and output, as failures are added as warnings to the command result instead of failing the command.
+func notifySlack(ctx context.Context, cmdCtx *commandCtx, obfuscator *diff.Obfuscator, cr *result.CommandResult) {
+	err := doNotifySlack(ctx, cmdCtx, obfuscator, cr)
+	if err != nil {
+		err = fmt.Errorf("failed to send Slack notification: %w", err)
+		status.Warning(ctx, err.Error())
+		cr.Warnings = append(cr.Warnings, result.DeploymentError{Message: err.Error()})
+	}
+}
+
+func doNotifySlack(ctx context.Context, cmdCtx *commandCtx, obfuscator *diff.Obfuscator, cr *result.CommandResult) error {
+	webhookURL, webuiURL, err := resolveSlackNotification(cmdCtx)
+	if err != nil {
+		return err
+	}
+	if webhookURL == "" {
+		return nil
+	}
+
+	// notifications are always obfuscated, even with --no-obfuscate
+	cr2, err := obfuscatedResultCopy(obfuscator, cr)
+	if err != nil {
+		return err
+	}
+
+	err = slack.PostMessage(ctx, webhookURL, slack.BuildMessage(cr2, webuiURL))
+	if err != nil {
+		return err
+	}
+	status.Info(ctx, "Sent Slack notification")
+	return nil
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/results/webhook"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+// getResultWebhookName returns a name for the webhook that is safe to be printed, as webhook URLs might contain
+// credentials in the path or query
+func getResultWebhookName(webhookURL string, idx int) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Host == "" {
+		return fmt.Sprintf("#%d", idx+1)
+	}
+	return u.Host
+}
+
+// sendResultWebhooks posts the event to all webhooks passed via --result-webhook and returns the errors of all failed
+// webhooks
+func sendResultWebhooks(ctx context.Context, flags *args.ResultWebhookFlags, e *webhook.Event) []error {
+	headers := http.Header{}
+	for _, h := range flags.ResultWebhookHeader {
+		n, v, err := webhook.ParseHeader(h)
+		if err != nil {
+			return []error{fmt.Errorf("failed to post result to webhooks: %w", err)}
+		}
+		headers.Add(n, v)
+	}
+
+	c := webhook.NewClient(headers, flags.ResultWebhookTimeout, flags.ResultWebhookRetries)
+	var errs []error
+	for i, u := range flags.ResultWebhook {
+		name := getResultWebhookName(u, i)
+		err := c.Post(ctx, u, e)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to post result to webhook %s: %w", name, err))
+			continue
+		}
+		status.Infof(ctx, "Posted result to webhook %s", name)
+	}
+	return errs
+}
+
+func hasResultWebhooks(cmdCtx *commandCtx) bool {
+	return cmdCtx.resultWebhookFlags != nil && len(cmdCtx.resultWebhookFlags.ResultWebhook) != 0
+}
+
+// postCommandResultWebhooks posts the obfuscated command result to all result webhooks. It must be called before the
+// result is written and output, as failures are added as warnings to the command result instead of failing the
+// command.
+func postCommandResultWebhooks(ctx context.Context, cmdCtx *commandCtx, obfuscator *diff.Obfuscator, cr *result.CommandResult) {
+	if !hasResultWebhooks(cmdCtx) {
+		return
+	}
+
+	var errs []error
+	// webhook receivers always get obfuscated results, even with --no-obfuscate
+	cr2, err := obfuscatedResultCopy(obfuscator, cr)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to post result to webhooks: %w", err))
+	} else {
+		errs = sendResultWebhooks(ctx, cmdCtx.resultWebhookFlags, webhook.NewCommandResultEvent(cr2))
+	}
+	for _, err := range errs {
+		status.Warning(ctx, err.Error())
+		cr.Warnings = append(cr.Warnings, result.DeploymentError{Message: err.Error()})
+	}
+}
+
+// postValidateResultWebhooks is the same as postCommandResultWebhooks, but for validate results
+func postValidateResultWebhooks(ctx context.Context, cmdCtx *commandCtx, vr *result.ValidateResult) {
+	if !hasResultWebhooks(cmdCtx) {
+		return
+	}
+
+	errs := sendResultWebhooks(ctx, cmdCtx.resultWebhookFlags, webhook.NewValidateResultEvent(vr))
+	for _, err := range errs {
+		status.Warning(ctx, err.Error())
+		vr.Warnings = append(vr.Warnings, result.DeploymentError{Message: err.Error()})
+	}
+}
@@ -0,0 +1,314 @@
+package commands
+
+import (
+	"context"
+	errors2 "errors"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	"net"
+	"path"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+type namedResultWriter struct {
+	name string
+	// namespace is only set for in-cluster result stores
+	namespace string
+	writer    results.ResultWriter
+}
+
+// newAwsClientFactory is used to create the S3 clients of per-target result stores and is replaced in tests
+var newAwsClientFactory = aws.NewClientFactory
+
+// buildResultWriters builds all result stores that command results of the given target are written to. Targets that
+// don't configure result stores write to the result store given via defaultStore, which is either the in-cluster
+// result store or the object storage result store configured via --command-result-s3-bucket.
+func buildResultWriters(ctx context.Context, restConfig *rest.Config, mapper meta.RESTMapper, k *k8s.K8sCluster, target *types.Target, flags *args.CommandResultFlags, defaultStore results.ResultStore) ([]namedResultWriter, error) {
+	if flags == nil || !flags.WriteCommandResult {
+		return nil, nil
+	}
+
+	if target.Results == nil {
+		if defaultStore == nil {
+			return nil, nil
+		}
+		if flags.CommandResultS3Bucket != "" {
+			return []namedResultWriter{{name: "s3://" + path.Join(flags.CommandResultS3Bucket, flags.CommandResultS3Prefix), writer: defaultStore}}, nil
+		}
+		return []namedResultWriter{{name: "cluster", namespace: flags.CommandResultNamespace, writer: defaultStore}}, nil
+	}
+
+	var ret []namedResultWriter
+	for _, sc := range target.Results.Stores {
+		switch sc.Type {
+		case types.ResultStoreTypeCluster:
+			namespace := flags.CommandResultNamespace
+			if sc.Cluster != nil && sc.Cluster.Namespace != "" {
+				namespace = sc.Cluster.Namespace
+			}
+			name := sc.Name
+			if name == "" {
+				name = "cluster:" + namespace
+			}
+
+			if namespace == flags.CommandResultNamespace && flags.CommandResultS3Bucket == "" && defaultStore != nil {
+				ret = append(ret, namedResultWriter{name: name, namespace: namespace, writer: defaultStore})
+				continue
+			}
+			if restConfig == nil {
+				return nil, fmt.Errorf("result store %s requires a Kubernetes cluster", name)
+			}
+			flags2 := *flags
+			flags2.CommandResultNamespace = namespace
+			flags2.CommandResultS3Flags = args.CommandResultS3Flags{}
+			rs, err := buildResultStoreRW(ctx, restConfig, mapper, &flags2, false)
+			if err != nil {
+				if !errors.IsForbidden(err) {
+					return nil, err
+				}
+				status.Warningf(ctx, "Not enough permissions to write to the result store %s.", name)
+				continue
+			}
+			ret = append(ret, namedResultWriter{name: name, namespace: namespace, writer: rs})
+		case types.ResultStoreTypeS3:
+			name := sc.Name
+			if name == "" {
+				name = "s3://" + path.Join(sc.S3.Bucket, sc.S3.Prefix)
+			}
+
+			var c client.Client
+			if k != nil {
+				var err error
+				c, err = k.ToClient()
+				if err != nil {
+					return nil, err
+				}
+			}
+			s3Client, err := newAwsClientFactory(c, target.Aws).S3Client(ctx, sc.S3.Profile, sc.S3.Region)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create S3 client for result store %s: %w", name, err)
+			}
+			rs := results.NewResultStoreS3(ctx, s3Client, sc.S3.Bucket, sc.S3.Prefix, true, flags.KeepCommandResultsCount, flags.KeepValidateResultsCount)
+			rs.SetMaxCommandResultAge(time.Duration(flags.KeepCommandResultsMaxAge))
+			ret = append(ret, namedResultWriter{name: name, writer: rs})
+		default:
+			return nil, fmt.Errorf("unknown result store type %s", sc.Type)
+		}
+	}
+	return ret, nil
+}
+
+const (
+	resultStoreWriteStrict     = "strict"
+	resultStoreWriteBestEffort = "best-effort"
+)
+
+// resultStoreWriteAttempts and resultStoreWriteBackoff control the retries when writing to a result store. The backoff
+// is doubled after each failed attempt.
+var (
+	resultStoreWriteAttempts = 5
+	resultStoreWriteBackoff  = time.Second
+)
+
+// parseResultStoreWriteMode parses the value of --result-store-write and returns true for best-effort mode
+func parseResultStoreWriteMode(mode string) (bool, error) {
+	switch mode {
+	case "", resultStoreWriteStrict:
+		return false, nil
+	case resultStoreWriteBestEffort:
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid --result-store-write '%s', must be one of %s or %s", mode, resultStoreWriteStrict, resultStoreWriteBestEffort)
+	}
+}
+
+// isRetryableResultStoreError returns true for transient errors, e.g. rate limiting, server side timeouts and network
+// errors. Permanent errors (e.g. Forbidden or Invalid) are not retried, as retrying them only delays the command.
+func isRetryableResultStoreError(err error) bool {
+	if errors.IsTooManyRequests(err) || errors.IsServerTimeout(err) || errors.IsTimeout(err) ||
+		errors.IsInternalError(err) || errors.IsServiceUnavailable(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors2.As(err, &netErr)
+}
+
+// writeCommandResultWithRetries writes the command result to a single result store, retrying transient failures with
+// exponential backoff. The status line is updated to reflect the retries.
+func writeCommandResultWithRetries(ctx context.Context, s *status.StatusContext, w namedResultWriter, cr *result.CommandResult) error {
+	backoff := resultStoreWriteBackoff
+	for i := 1; ; i++ {
+		err := w.writer.WriteCommandResult(cr)
+		if err == nil || i >= resultStoreWriteAttempts || !isRetryableResultStoreError(err) {
+			return err
+		}
+		s.Updatef("Writing command result to %s (retrying %d/%d)", w.name, i+1, resultStoreWriteAttempts)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// writeCommandResult writes the command result to all given result stores. Failing stores don't prevent writing
+// to the remaining ones. Results that failed to be written are spooled locally, so that writing can be retried later.
+// Failures are added as warnings to the command result if at least one store succeeded or the result got spooled,
+// otherwise all errors are returned. In best-effort mode, these errors are added as warnings as well. If a signer is
+// given, the result is signed before it is written. In read-only mode, results are only spooled.
+func writeCommandResult(ctx context.Context, writers []namedResultWriter, signer *results.ResultSigner, cr *result.CommandResult, bestEffort bool) error {
+	if len(writers) == 0 {
+		return nil
+	}
+
+	if cr.ClusterInfo.ClusterId == "" {
+		warning := fmt.Sprintf("failed to determine cluster ID, as neither the kube-system namespace nor the %s/%s ConfigMap could be read and no API server URL was available. This might result in follow up issues in regard to cluster differentiation stored command results. Set 'clusterId' in the target to fix this", k8s.ClusterIdConfigMapNamespace, k8s.ClusterIdConfigMapName)
+		cr.Warnings = append(cr.Warnings, result.DeploymentError{
+			Message: warning,
+		})
+		status.Warning(ctx, warning)
+	}
+
+	if signer != nil {
+		err := signer.Sign(cr)
+		if err != nil {
+			return err
+		}
+	}
+
+	spool := results.NewResultSpool(results.DefaultSpoolDir(ctx))
+
+	if isReadOnly(ctx) {
+		return spoolCommandResult(ctx, spool, writers, cr)
+	}
+
+	var errs *multierror.Error
+	var spooled []string
+	succeeded := 0
+	for _, w := range writers {
+		s := status.Startf(ctx, "Writing command result to %s", w.name)
+		err := writeCommandResultWithRetries(ctx, s, w, cr)
+		if err != nil {
+			s.FailedWithMessagef("Failed to write result to %s: %s", w.name, err.Error())
+			err = fmt.Errorf("failed to write command result to %s: %w", w.name, err)
+
+			spoolPath, err2 := spool.Add(w.name, w.namespace, cr)
+			if err2 != nil {
+				errs = multierror.Append(errs, err, fmt.Errorf("failed to spool command result: %w", err2))
+				continue
+			}
+			status.Warningf(ctx, "The command result was spooled to %s. It will be written on the next command against the same cluster or via 'kluctl results flush-spool'.", spoolPath)
+			spooled = append(spooled, fmt.Sprintf("%s (spooled to %s)", err.Error(), spoolPath))
+			continue
+		}
+		s.Success()
+		succeeded++
+	}
+
+	for _, w := range spooled {
+		cr.Warnings = append(cr.Warnings, result.DeploymentError{
+			Message: w,
+		})
+	}
+	if errs == nil {
+		return nil
+	}
+	failed := succeeded == 0 && len(spooled) == 0
+	if failed && !bestEffort {
+		return errs.ErrorOrNil()
+	}
+	for _, err := range errs.Errors {
+		cr.Warnings = append(cr.Warnings, result.DeploymentError{
+			Message: err.Error(),
+		})
+		if failed {
+			status.Warning(ctx, err.Error())
+		}
+	}
+	return nil
+}
+
+// spoolCommandResult spools the command result for all given result stores without trying to write it, which is
+// used in read-only mode
+func spoolCommandResult(ctx context.Context, spool *results.ResultSpool, writers []namedResultWriter, cr *result.CommandResult) error {
+	var errs *multierror.Error
+	for _, w := range writers {
+		spoolPath, err := spool.Add(w.name, w.namespace, cr)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("failed to spool command result for %s: %w", w.name, err))
+			continue
+		}
+		status.Infof(ctx, "The command result for %s was spooled to %s due to read-only mode.", w.name, spoolPath)
+	}
+	return errs.ErrorOrNil()
+}
+
+// flushResultSpool retries writing all spooled command results of the given cluster. Results are only written to the
+// writer with the same name as the one that originally failed. Entries without matching writer are kept.
+func flushResultSpool(ctx context.Context, clusterId string, getWriter func(e *results.SpoolEntry) (results.ResultWriter, error)) (int, error) {
+	spool := results.NewResultSpool(results.DefaultSpoolDir(ctx))
+	entries, err := spool.List(clusterId)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs *multierror.Error
+	flushed := 0
+	for _, e := range entries {
+		w, err := getWriter(e)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		if w == nil {
+			continue
+		}
+		s := status.Startf(ctx, "Writing spooled command result %s to %s", e.Result.Id, e.WriterName)
+		err = w.WriteCommandResult(e.Result)
+		if err != nil {
+			s.FailedWithMessagef("Failed to write spooled result to %s: %s", e.WriterName, err.Error())
+			errs = multierror.Append(errs, fmt.Errorf("failed to write spooled command result %s to %s: %w", e.Result.Id, e.WriterName, err))
+			continue
+		}
+		s.Success()
+		err = spool.Remove(e)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		flushed++
+	}
+	return flushed, errs.ErrorOrNil()
+}
+
+// flushResultSpoolToWriters retries spooled command results of the given cluster with the result writers of the
+// current command. Entries are only flushed to writers with the same name and namespace, so that results spooled for
+// another in-cluster result store namespace are kept in the spool. Failures are only reported as warnings.
+func flushResultSpoolToWriters(ctx context.Context, clusterId string, writers []namedResultWriter) {
+	if clusterId == "" || len(writers) == 0 {
+		return
+	}
+	_, err := flushResultSpool(ctx, clusterId, func(e *results.SpoolEntry) (results.ResultWriter, error) {
+		for _, w := range writers {
+			if w.name == e.WriterName && w.namespace == e.Namespace {
+				return w.writer, nil
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		status.Warningf(ctx, "Failed to flush spooled command results: %s", err.Error())
+	}
+}
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type failingResultWriter struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (w *failingResultWriter) WriteCommandResult(cr *result.CommandResult) error {
+	w.calls++
+	if w.calls <= w.failures {
+		return w.err
+	}
+	return nil
+}
+
+type recordingResultWriter struct {
+	ids []string
+}
+
+func (w *recordingResultWriter) WriteCommandResult(cr *result.CommandResult) error {
+	w.ids = append(w.ids, cr.Id)
+	return nil
+}
+
+func TestWriteCommandResultWithRetries(t *testing.T) {
+	oldBackoff := resultStoreWriteBackoff
+	resultStoreWriteBackoff = time.Millisecond
+	t.Cleanup(func() {
+		resultStoreWriteBackoff = oldBackoff
+	})
+
+	transientErr := apierrors.NewServiceUnavailable("write failed")
+	w := &failingResultWriter{failures: resultStoreWriteAttempts - 1, err: transientErr}
+	err := writeCommandResultWithRetries(context.Background(), nil, namedResultWriter{name: "test", writer: w}, &result.CommandResult{})
+	assert.NoError(t, err)
+	assert.Equal(t, resultStoreWriteAttempts, w.calls)
+
+	w = &failingResultWriter{failures: resultStoreWriteAttempts, err: transientErr}
+	err = writeCommandResultWithRetries(context.Background(), nil, namedResultWriter{name: "test", writer: w}, &result.CommandResult{})
+	assert.EqualError(t, err, "write failed")
+	assert.Equal(t, resultStoreWriteAttempts, w.calls)
+
+	// permanent errors are not retried
+	forbiddenErr := apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "result", fmt.Errorf("denied"))
+	w = &failingResultWriter{failures: resultStoreWriteAttempts, err: forbiddenErr}
+	err = writeCommandResultWithRetries(context.Background(), nil, namedResultWriter{name: "test", writer: w}, &result.CommandResult{})
+	assert.Equal(t, forbiddenErr, err)
+	assert.Equal(t, 1, w.calls)
+}
+
+func TestIsRetryableResultStoreError(t *testing.T) {
+	gr := schema.GroupResource{Resource: "secrets"}
+	assert.True(t, isRetryableResultStoreError(apierrors.NewTooManyRequests("slow down", 1)))
+	assert.True(t, isRetryableResultStoreError(apierrors.NewServerTimeout(gr, "create", 1)))
+	assert.True(t, isRetryableResultStoreError(apierrors.NewTimeoutError("timeout", 1)))
+	assert.True(t, isRetryableResultStoreError(apierrors.NewInternalError(fmt.Errorf("internal"))))
+	assert.True(t, isRetryableResultStoreError(apierrors.NewServiceUnavailable("unavailable")))
+	assert.True(t, isRetryableResultStoreError(fmt.Errorf("wrapped: %w", &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")})))
+
+	assert.False(t, isRetryableResultStoreError(apierrors.NewForbidden(gr, "result", fmt.Errorf("denied"))))
+	assert.False(t, isRetryableResultStoreError(apierrors.NewInvalid(schema.GroupKind{Kind: "Secret"}, "result", nil)))
+	assert.False(t, isRetryableResultStoreError(&meta.NoKindMatchError{GroupKind: schema.GroupKind{Kind: "Secret"}}))
+	assert.False(t, isRetryableResultStoreError(fmt.Errorf("write failed")))
+}
+
+func TestParseResultStoreWriteMode(t *testing.T) {
+	bestEffort, err := parseResultStoreWriteMode("strict")
+	assert.NoError(t, err)
+	assert.False(t, bestEffort)
+
+	bestEffort, err = parseResultStoreWriteMode("best-effort")
+	assert.NoError(t, err)
+	assert.True(t, bestEffort)
+
+	_, err = parseResultStoreWriteMode("lenient")
+	assert.ErrorContains(t, err, "invalid --result-store-write")
+}
+
+func TestBuildResultWritersS3(t *testing.T) {
+	fakeAws := aws.NewFakeClientFactory()
+	oldFactory := newAwsClientFactory
+	newAwsClientFactory = func(c client.Client, awsConfig *types.AwsConfig) aws.AwsClientFactory {
+		return fakeAws
+	}
+	t.Cleanup(func() {
+		newAwsClientFactory = oldFactory
+	})
+
+	target := &types.Target{
+		Name: "prod",
+		Results: &types.ResultsConfig{
+			Stores: []types.ResultStoreConfig{
+				{Type: types.ResultStoreTypeS3, S3: &types.S3ResultStoreConfig{Bucket: "my-bucket", Prefix: "prod/results"}},
+			},
+		},
+	}
+	flags := &args.CommandResultFlags{
+		CommandResultWriteFlags: args.CommandResultWriteFlags{
+			WriteCommandResult:      true,
+			KeepCommandResultsCount: 5,
+		},
+	}
+	writers, err := buildResultWriters(context.Background(), nil, nil, nil, target, flags, nil)
+	assert.NoError(t, err)
+	assert.Len(t, writers, 1)
+	assert.Equal(t, "s3://my-bucket/prod/results", writers[0].name)
+
+	cr := &result.CommandResult{
+		Id: "my-id",
+		Command: result.CommandInfo{
+			Initiator: result.CommandInititiator_CommandLine,
+			Command:   "deploy",
+			StartTime: metav1.Now(),
+		},
+		TargetKey: result.TargetKey{TargetName: "prod"},
+	}
+	assert.NoError(t, writers[0].writer.WriteCommandResult(cr))
+
+	// results written by per-target stores must be readable via --command-result-s3-bucket
+	store := results.NewResultStoreS3(context.Background(), fakeAws, "my-bucket", "prod/results", false, 0, 0)
+	summaries, err := store.ListCommandResultSummaries(results.ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, "my-id", summaries[0].Id)
+
+	cr2, err := store.GetCommandResult(results.GetCommandResultOptions{Id: "my-id"})
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", cr2.TargetKey.TargetName)
+}
+
+func TestFlushResultSpoolToWritersMatchesNamespace(t *testing.T) {
+	ctx := utils.WithCacheDir(context.Background(), t.TempDir())
+	spool := results.NewResultSpool(results.DefaultSpoolDir(ctx))
+
+	newResult := func(id string) *result.CommandResult {
+		return &result.CommandResult{
+			Id:          id,
+			ClusterInfo: result.ClusterInfo{ClusterId: "cluster-1"},
+			Command:     result.CommandInfo{StartTime: metav1.Now()},
+		}
+	}
+	_, err := spool.Add("cluster", "ns-1", newResult("id-1"))
+	assert.NoError(t, err)
+	_, err = spool.Add("cluster", "ns-2", newResult("id-2"))
+	assert.NoError(t, err)
+	_, err = spool.Add("other", "", newResult("id-3"))
+	assert.NoError(t, err)
+
+	w := &recordingResultWriter{}
+	flushResultSpoolToWriters(ctx, "cluster-1", []namedResultWriter{{name: "cluster", namespace: "ns-1", writer: w}})
+	assert.Equal(t, []string{"id-1"}, w.ids)
+
+	entries, err := spool.List("cluster-1")
+	assert.NoError(t, err)
+	var remaining []string
+	for _, e := range entries {
+		remaining = append(remaining, e.Result.Id)
+	}
+	assert.ElementsMatch(t, []string{"id-2", "id-3"}, remaining)
+}
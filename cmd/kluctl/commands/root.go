@@ -21,22 +21,19 @@ import (
 	go_container_logs "github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/gops/agent"
 	status2 "github.com/kluctl/kluctl/lib/status"
-	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/i18n"
 	"github.com/kluctl/kluctl/v2/pkg/prompts"
 	flag "github.com/spf13/pflag"
 	"io"
 	"log"
-	"net/http"
 	"os"
-	"path/filepath"
 	"runtime/pprof"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"strings"
-	"time"
 
-	"github.com/Masterminds/semver/v3"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/selfupdate"
+	"github.com/kluctl/kluctl/v2/pkg/stats"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
-	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/kluctl/kluctl/v2/pkg/version"
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
@@ -45,40 +42,58 @@ import (
 	"k8s.io/klog/v2"
 )
 
-const latestReleaseUrl = "https://api.github.com/repos/kluctl/kluctl/releases/latest"
-
 type GlobalFlags struct {
-	Debug         bool `group:"global" help:"Enable debug logging"`
-	NoUpdateCheck bool `group:"global" help:"Disable update check on startup"`
-	NoColor       bool `group:"global" help:"Disable colored output"`
+	Debug         args.DebugType `group:"global" help:"Enable debug logging. Optionally, a comma separated list of debug topics can be passed to enable additional tracing, e.g. '--debug=vars' to trace the merge order of vars sources."`
+	NoUpdateCheck bool           `group:"global" help:"Disable update check on startup. Can also be disabled via KLUCTL_NO_UPDATE_CHECK=true."`
+	NoColor       bool           `group:"global" help:"Disable colored output"`
+
+	Quiet        bool   `group:"global" help:"Suppress all status output except errors and prompts."`
+	StatusOutput string `group:"global" help:"Specify where status output is written to. Can be 'stderr', 'stdout' or 'none'. When 'stdout' is used, status output is written line by line without progress animation. Errors are still printed to stderr when 'none' is used." default:"stderr"`
 
 	CpuProfile    string `group:"global" help:"Enable CPU profiling and write the result to the given path"`
 	GopsAgent     bool   `group:"global" help:"Start gops agent in the background"`
 	GopsAgentAddr string `group:"global" help:"Specify the address:port to use for the gops agent" default:"127.0.0.1:0"`
 
 	UseSystemPython bool `group:"global" help:"Use the system Python instead of the embedded Python."`
+
+	Lang string `group:"global" help:"Language of the human-readable text output of command results." default:"en"`
+
+	Stats bool `group:"global" help:"Print performance metrics (wall time per phase, Kubernetes API calls, fetched bytes, cache hit rates, rendered templates and peak memory) after the command has finished. The metrics are also stored in the command result."`
+
+	ReadOnly bool `group:"global" help:"Run in read-only mode. All requests that would modify the cluster are rejected before they are sent, commands that modify the cluster refuse to start and all other commands are forced to run in dry-run mode. Command results are spooled locally instead of being written to result stores."`
 }
 
 type cli struct {
 	GlobalFlags
 
-	Delete      deleteCmd      `cmd:"" help:"Delete a target (or parts of it) from the corresponding cluster"`
-	Deploy      deployCmd      `cmd:"" help:"Deploys a target to the corresponding cluster"`
-	Diff        diffCmd        `cmd:"" help:"Perform a diff between the locally rendered target and the already deployed target"`
-	HelmPull    helmPullCmd    `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and pre-pulls the specified Helm charts"`
-	HelmUpdate  helmUpdateCmd  `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and checks for new available versions"`
-	ListImages  listImagesCmd  `cmd:"" help:"Renders the target and outputs all images used via 'images.get_image(...)"`
-	ListTargets listTargetsCmd `cmd:"" help:"Outputs a yaml list with all targets"`
-	PokeImages  pokeImagesCmd  `cmd:"" help:"Replace all images in target"`
-	Prune       pruneCmd       `cmd:"" help:"Searches the target cluster for prunable objects and deletes them"`
-	Render      renderCmd      `cmd:"" help:"Renders all resources and configuration files"`
-	Validate    validateCmd    `cmd:"" help:"Validates the already deployed deployment"`
-	Controller  controllerCmd  `cmd:"" help:"Kluctl controller sub-commands"`
-	Gitops      gitopsCmd      `cmd:"" help:"GitOps sub-commands"`
-	Webui       webuiCmd       `cmd:"" help:"Kluctl Webui sub-commands"`
-	Oci         ociCmd         `cmd:"" help:"Oci sub-commands"`
-
-	Version versionCmd `cmd:"" help:"Print kluctl version"`
+	CleanupHooks cleanupHooksCmd `cmd:"" help:"Deletes hooks that were left behind by interrupted deployments"`
+	Delete       deleteCmd       `cmd:"" help:"Delete a target (or parts of it) from the corresponding cluster"`
+	Deploy       deployCmd       `cmd:"" help:"Deploys a target to the corresponding cluster"`
+	Diff         diffCmd         `cmd:"" help:"Perform a diff between the locally rendered target and the already deployed target"`
+	FixOwnership fixOwnershipCmd `cmd:"" help:"Migrates field ownership of stale field managers to the current field manager"`
+	HelmAdopt    helmAdoptCmd    `cmd:"" help:"Adopts an existing Helm release into a deployment item"`
+	HelmPull     helmPullCmd     `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and pre-pulls the specified Helm charts"`
+	HelmUpdate   helmUpdateCmd   `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and checks for new available versions"`
+	ListImages   listImagesCmd   `cmd:"" help:"Renders the target and outputs all images used via 'images.get_image(...)"`
+	ListTargets  listTargetsCmd  `cmd:"" help:"Outputs a yaml list with all targets"`
+	Lint         lintCmd         `cmd:"" help:"Reports usages of deprecated features and other configuration warnings"`
+	Plan         planCmd         `cmd:"" help:"Shows the ordered sequence of operations a deploy would perform"`
+	PokeImages   pokeImagesCmd   `cmd:"" help:"Replace all images in target"`
+	Prune        pruneCmd        `cmd:"" help:"Searches the target cluster for prunable objects and deletes them"`
+	Render       renderCmd       `cmd:"" help:"Renders all resources and configuration files"`
+	Rollback     rollbackCmd     `cmd:"" help:"Rolls back a target to the state recorded in a stored command result"`
+	Validate     validateCmd     `cmd:"" help:"Validates the already deployed deployment"`
+	Controller   controllerCmd   `cmd:"" help:"Kluctl controller sub-commands"`
+	Gitops       gitopsCmd       `cmd:"" help:"GitOps sub-commands"`
+	Webui        webuiCmd        `cmd:"" help:"Kluctl Webui sub-commands"`
+	Oci          ociCmd          `cmd:"" help:"Oci sub-commands"`
+	Preview      previewCmd      `cmd:"" help:"Preview environment sub-commands"`
+	Results      resultsCmd      `cmd:"" help:"Command results sub-commands"`
+	Lock         lockCmd         `cmd:"" help:"Project lock sub-commands"`
+	Cache        cacheCmd        `cmd:"" help:"Local cache sub-commands"`
+
+	SelfUpdate selfUpdateCmd `cmd:"" help:"Update kluctl to the latest release"`
+	Version    versionCmd    `cmd:"" help:"Print kluctl version"`
 }
 
 var flagGroups = []groupInfo{
@@ -102,22 +117,43 @@ var origStderr = os.Stderr
 // we must determine isTerminal before we override os.Stderr
 var isTerminal = isatty.IsTerminal(os.Stderr.Fd())
 
-func initStatusHandlerAndPrompts(ctx context.Context, debug bool, noColor bool) context.Context {
+func initStatusHandlerAndPrompts(ctx context.Context, flags *GlobalFlags) (context.Context, error) {
+	var out io.Writer
+	quiet := flags.Quiet
+	switch flags.StatusOutput {
+	case "", "stderr":
+		out = origStderr
+	case "stdout":
+		out = os.Stdout
+	case "none":
+		// errors must still be visible somewhere
+		out = origStderr
+		quiet = true
+	default:
+		return ctx, fmt.Errorf("invalid --status-output '%s', must be one of stderr, stdout or none", flags.StatusOutput)
+	}
+
 	var sh status2.StatusHandler
 	var pp prompts.PromptProvider
-	if !debug && isTerminal {
-		sh = status2.NewMultiLineStatusHandler(ctx, origStderr, isTerminal && !noColor, false)
+	if !flags.Debug.Enabled && !quiet && isTerminal && out == origStderr {
+		sh = status2.NewMultiLineStatusHandler(ctx, out, !flags.NoColor, false)
 		pp = &prompts.StatusAndStdinPromptProvider{}
 	} else {
 		sh = status2.NewSimpleStatusHandler(func(level status2.Level, message string) {
-			_, _ = fmt.Fprintf(origStderr, "%s\n", message)
-		}, debug)
+			// status lines written to stdout must not interleave with command output
+			stdStreamsMutex.Lock()
+			defer stdStreamsMutex.Unlock()
+			_, _ = fmt.Fprintf(out, "%s\n", message)
+		}, flags.Debug.Enabled && !quiet)
 		pp = &prompts.SimplePromptProvider{Out: origStderr}
 	}
+	if quiet {
+		sh = status2.NewQuietStatusHandler(sh)
+	}
 	ctx = status2.NewContext(ctx, sh)
 	ctx = prompts.NewContext(ctx, pp)
 
-	return ctx
+	return ctx, nil
 }
 
 func redirectLogsAndStderr(ctx context.Context) {
@@ -165,6 +201,8 @@ func setupGops(flags *GlobalFlags) error {
 
 var cpuProfileFile *os.File
 
+var statsCollector *stats.Collector
+
 func setupProfiling(cpuProfile string) error {
 	var err error
 	if cpuProfile != "" {
@@ -180,61 +218,26 @@ func setupProfiling(cpuProfile string) error {
 	return nil
 }
 
-type VersionCheckState struct {
-	LastVersionCheck time.Time `json:"lastVersionCheck"`
-}
-
+// checkNewVersion prints a notice if a newer kluctl release is available. The latest version is cached for 24 hours.
 func checkNewVersion(ctx context.Context) {
 	if version.GetVersion() == "0.0.0" {
 		return
 	}
 
-	versionCheckPath := filepath.Join(utils.GetCacheDir(ctx), "version_check.yaml")
-	var versionCheckState VersionCheckState
-	err := yaml.ReadYamlFile(versionCheckPath, &versionCheckState)
-	if err == nil {
-		if time.Now().Sub(versionCheckState.LastVersionCheck) < time.Hour {
-			return
-		}
-	}
-
-	versionCheckState.LastVersionCheck = time.Now()
-	_ = yaml.WriteYamlFile(versionCheckPath, &versionCheckState)
-
 	s := status2.Start(ctx, "Checking for new kluctl version")
 	defer s.Failed()
 
-	r, err := http.Get(latestReleaseUrl)
+	r, err := selfupdate.NewClient("").CheckForUpdate(ctx, utils.GetCacheDir(ctx), version.GetVersion(), false)
 	if err != nil {
 		return
 	}
-	defer r.Body.Close()
-
-	var release uo.UnstructuredObject
-	err = yaml.ReadYamlStream(r.Body, &release)
-	if err != nil {
-		return
-	}
-
-	latestVersionStr, ok, _ := release.GetNestedString("tag_name")
-	if !ok {
-		return
-	}
-	if strings.HasPrefix(latestVersionStr, "v") {
-		latestVersionStr = latestVersionStr[1:]
-	}
-	latestVersion, err := semver.NewVersion(latestVersionStr)
-	if err != nil {
-		s.FailedWithMessagef("Failed to parse latest version: %v", err)
+	if r == nil {
+		s.Update("Skipped, as the last check failed recently")
+		s.Success()
 		return
 	}
-	localVersion, err := semver.NewVersion(version.GetVersion())
-	if err != nil {
-		s.FailedWithMessagef("Failed to parse local version: %v", err)
-		return
-	}
-	if localVersion.LessThan(latestVersion) {
-		s.Updatef("You are using an outdated version (%v) of kluctl. You should update soon to version %v", localVersion.String(), latestVersion.String())
+	if r.UpdateAvailable() {
+		s.Updatef("You are using an outdated version (%v) of kluctl. You should update soon to version %v, e.g. via 'kluctl self-update'", r.CurrentVersion.String(), r.LatestVersion.String())
 	} else {
 		s.Update("Your kluctl version is up-to-date")
 	}
@@ -272,12 +275,23 @@ func Main() {
 		if err != nil {
 			return ctx, err
 		}
+		_, err = i18n.GetCatalog(flags.Lang)
+		if err != nil {
+			return ctx, err
+		}
 		err = setupProfiling(flags.CpuProfile)
 		if err != nil {
 			return ctx, err
 		}
+		if flags.Stats {
+			statsCollector = stats.NewCollector()
+			ctxIn = stats.NewContext(ctxIn, statsCollector)
+		}
 
-		ctx = initStatusHandlerAndPrompts(ctxIn, flags.Debug, flags.NoColor)
+		ctx, err = initStatusHandlerAndPrompts(ctxIn, flags)
+		if err != nil {
+			return ctx, err
+		}
 		didSetupStatusHandler = true
 
 		if cmd.Parent() == nil || (cmd.Name() != "run" && cmd.Parent().Name() != "controller") {
@@ -311,6 +325,10 @@ func Main() {
 		sh.Stop()
 	}
 
+	if statsCollector != nil {
+		_, _ = fmt.Fprint(origStderr, formatCommandStats(statsCollector.Build(), statsCollector.GetTimings()))
+	}
+
 	if err != nil {
 		os.Exit(1)
 	}
@@ -335,6 +353,45 @@ func getCobraGlobalFlags(ctx context.Context) *GlobalFlags {
 	panic("missing global flags")
 }
 
+// isNoColor returns true if --no-color was passed
+func isNoColor(ctx context.Context) bool {
+	v := ctx.Value(cobraGlobalFlagsKey{})
+	if x, ok := v.(*GlobalFlags); ok {
+		return x.NoColor
+	}
+	return false
+}
+
+// isReadOnly returns true if --read-only was passed
+func isReadOnly(ctx context.Context) bool {
+	v := ctx.Value(cobraGlobalFlagsKey{})
+	if x, ok := v.(*GlobalFlags); ok {
+		return x.ReadOnly
+	}
+	return false
+}
+
+// getMessageCatalog returns the message catalog selected via --lang. nil is returned if no catalog was selected, which
+// causes the default catalog to be used.
+func getMessageCatalog(ctx context.Context) i18n.Catalog {
+	v := ctx.Value(cobraGlobalFlagsKey{})
+	if x, ok := v.(*GlobalFlags); ok {
+		c, err := i18n.GetCatalog(x.Lang)
+		if err == nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// checkNotReadOnly returns an error if the given command is not allowed to run due to --read-only
+func checkNotReadOnly(ctx context.Context, command string) error {
+	if isReadOnly(ctx) {
+		return fmt.Errorf("the %s command can not be used in read-only mode", command)
+	}
+	return nil
+}
+
 func Execute(ctx context.Context, args []string, preRun func(ctx context.Context) (context.Context, error)) error {
 	root := cli{}
 	rootCmd, err := buildRootCobraCmd(&root, "kluctl",
@@ -365,6 +422,7 @@ composed of multiple smaller parts (Helm/Kustomize/...) in a manageable and unif
 		}
 
 		ctx = context.WithValue(ctx, cobraGlobalFlagsKey{}, &root.GlobalFlags)
+		ctx = utils.WithDebugTopics(ctx, root.GlobalFlags.Debug.Topics)
 		for c := cmd; c != nil; c = c.Parent() {
 			c.SetContext(ctx)
 		}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/status"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+)
+
+// showOrdering prints the apply order of all deployment items to stderr, followed by all warnings and errors that were
+// found while determining the order
+func showOrdering(ctx context.Context, cmdCtx *commandCtx) {
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+	buf := strings.Builder{}
+	buf.WriteString("\nApply ordering:\n")
+	buf.WriteString(utils2.DescribeOrdering(ctx, dew, cmdCtx.targetCtx.DeploymentCollection.Deployments))
+
+	if warnings := dew.GetWarningsList(); len(warnings) != 0 {
+		buf.WriteString("\nOrdering warnings:\n")
+		prettyErrors(&buf, warnings)
+	}
+	if errors := dew.GetErrorsList(); len(errors) != 0 {
+		buf.WriteString("\nOrdering errors:\n")
+		prettyErrors(&buf, errors)
+	}
+
+	status.Flush(ctx)
+	_, _ = getStderr(ctx).WriteString(buf.String())
+}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/status"
+)
+
+// showSelection prints the effective prune exclusion rules to stderr
+func showSelection(ctx context.Context, cmdCtx *commandCtx) {
+	buf := strings.Builder{}
+	buf.WriteString("\nPrune exclusions:\n")
+
+	rules := cmdCtx.targetCtx.DeploymentCollection.PruneExclude()
+	if len(rules) == 0 {
+		buf.WriteString("  <none>\n")
+	}
+	for _, r := range rules {
+		if r.Reason != "" {
+			buf.WriteString(fmt.Sprintf("  %s: %s\n", r.String(), r.Reason))
+		} else {
+			buf.WriteString(fmt.Sprintf("  %s\n", r.String()))
+		}
+	}
+
+	status.Flush(ctx)
+	_, _ = getStderr(ctx).WriteString(buf.String())
+}
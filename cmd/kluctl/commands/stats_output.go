@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// formatCommandStats renders the table printed after the command has finished when --stats is passed
+func formatCommandStats(s *result.CommandStats, timings *result.CommandTimings) string {
+	var t utils.PrettyTable
+	t.AddRow("STAT", "VALUE")
+	t.AddRow("wall time", s.WallTime.Round(time.Millisecond).String())
+
+	if timings != nil {
+		addPhase := func(name string, d *metav1.Duration) {
+			if d != nil {
+				t.AddRow("phase "+name, d.Round(time.Millisecond).String())
+			}
+		}
+		addPhase("render", timings.Render)
+		addPhase("diff", timings.Diff)
+		addPhase("apply", timings.Apply)
+		addPhase("hooks", timings.Hooks)
+		addPhase("prune", timings.Prune)
+	}
+
+	totalApiCalls := 0
+	for _, n := range s.ApiCalls {
+		totalApiCalls += n
+	}
+	t.AddRow("api calls", fmt.Sprint(totalApiCalls))
+	for _, verb := range slices.Sorted(maps.Keys(s.ApiCalls)) {
+		t.AddRow(fmt.Sprintf("api calls (%s)", verb), fmt.Sprint(s.ApiCalls[verb]))
+	}
+
+	for _, st := range slices.Sorted(maps.Keys(s.FetchedBytes)) {
+		t.AddRow(fmt.Sprintf("fetched (%s)", st), formatByteSize(s.FetchedBytes[st]))
+	}
+	for _, st := range slices.Sorted(maps.Keys(s.Caches)) {
+		c := s.Caches[st]
+		t.AddRow(fmt.Sprintf("cache hits (%s)", st), fmt.Sprintf("%d/%d (%.0f%%)", c.Hits, c.Hits+c.Misses, c.HitRate()*100))
+	}
+
+	t.AddRow("rendered templates", fmt.Sprint(s.RenderedTemplates))
+	if s.PeakMemory != 0 {
+		t.AddRow("peak memory", formatByteSize(s.PeakMemory))
+	}
+	return t.Render([]int{-1, -1})
+}
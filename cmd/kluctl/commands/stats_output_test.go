@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFormatCommandStats(t *testing.T) {
+	s := &result.CommandStats{
+		WallTime:          metav1.Duration{Duration: 3*time.Second + 1234*time.Microsecond},
+		ApiCalls:          map[string]int{"patch": 2, "get": 5},
+		FetchedBytes:      map[string]int64{"git": 2048},
+		Caches:            map[string]result.CacheStats{"git": {Hits: 3, Misses: 1}},
+		RenderedTemplates: 12,
+		PeakMemory:        100 * 1024 * 1024,
+	}
+	timings := &result.CommandTimings{
+		Render: &metav1.Duration{Duration: time.Second},
+		Apply:  &metav1.Duration{Duration: 2 * time.Second},
+	}
+
+	out := formatCommandStats(s, timings)
+	for _, l := range []string{
+		"| wall time          | 3.001s    |",
+		"| phase render       | 1s        |",
+		"| phase apply        | 2s        |",
+		"| api calls          | 7         |",
+		"| api calls (get)    | 5         |",
+		"| api calls (patch)  | 2         |",
+		"| fetched (git)      | 2.0KiB    |",
+		"| cache hits (git)   | 3/4 (75%) |",
+		"| rendered templates | 12        |",
+		"| peak memory        | 100.0MiB  |",
+	} {
+		assert.Contains(t, out, l)
+	}
+	assert.NotContains(t, out, "phase diff")
+}
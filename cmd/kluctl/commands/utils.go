@@ -10,17 +10,23 @@ import (
 	ssh_pool "github.com/kluctl/kluctl/lib/git/ssh-pool"
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
 	helm_auth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
 	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	"github.com/kluctl/kluctl/v2/pkg/projectlock"
 	"github.com/kluctl/kluctl/v2/pkg/prompts"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
 	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/stats"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/rest"
@@ -28,6 +34,8 @@ import (
 	"k8s.io/client-go/tools/clientcmd/api"
 	"os"
 	client2 "sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
 )
 
 func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.KubeconfigFlags, projectFlags args.ProjectFlags,
@@ -43,6 +51,7 @@ func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.Kubeco
 		return err
 	}
 	defer j2.Close()
+	stats.AddTemplateCounter(ctx, j2.RenderedTemplates)
 
 	projectDir, err := projectFlags.ProjectDir.GetProjectDir()
 	if err != nil {
@@ -63,6 +72,8 @@ func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.Kubeco
 
 	ctx, cancel := context.WithTimeout(ctx, projectFlags.Timeout)
 	defer cancel()
+	ctx = repocache.WithFetchScheduler(ctx, repocache.NewFetchScheduler(projectFlags.FetchConcurrency))
+	ctx = projectlock.WithRecorder(ctx, projectlock.NewRecorder())
 
 	sshPool := &ssh_pool.SshPool{}
 
@@ -102,21 +113,35 @@ func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.Kubeco
 	ociRp := repocache.NewOciRepoCache(ctx, ociAuth, sourceOverrides, projectFlags.GitCacheUpdateInterval)
 	defer gitRp.Clear()
 
-	externalArgs, err := argsFlags.LoadArgs()
+	externalArgs, sensitiveArgs, err := argsFlags.LoadArgs()
 	if err != nil {
 		return err
 	}
 
+	var kubeconfigFromOverride *types.KubeconfigSource
+	if kubeconfigFlags != nil && kubeconfigFlags.KubeconfigFrom != "" {
+		kubeconfigFromOverride, err = k8s.ParseKubeconfigSource(kubeconfigFlags.KubeconfigFrom)
+		if err != nil {
+			return err
+		}
+	}
+
 	loadArgs := kluctl_project.LoadKluctlProjectArgs{
 		RepoRoot:           repoRoot,
 		ProjectDir:         projectDir,
 		ProjectConfig:      projectFlags.ProjectConfig.String(),
 		ExternalArgs:       externalArgs,
+		SensitiveArgs:      sensitiveArgs,
+		Lenient:            projectFlags.Lenient,
 		GitRP:              gitRp,
 		OciRP:              ociRp,
 		OciAuthProvider:    ociAuth,
 		HelmAuthProvider:   helmAuth,
-		ClientConfigGetter: clientConfigGetter(kubeconfigFlags, forCompletion),
+		ClientConfigGetter: clientConfigGetter(ctx, kubeconfigFlags, forCompletion),
+	}
+	if !forCompletion {
+		loadArgs.KubeconfigResolver = k8s.NewKubeconfigResolver(kubeconfigSecretGetter(ctx, kubeconfigFlags))
+		loadArgs.KubeconfigFromOverride = kubeconfigFromOverride
 	}
 
 	p, err := kluctl_project.LoadKluctlProject(ctx, loadArgs, j2)
@@ -134,27 +159,49 @@ type projectTargetCommandArgs struct {
 	argsFlags            args.ArgsFlags
 	imageFlags           args.ImageFlags
 	inclusionFlags       args.InclusionFlags
+	pruneExcludeFlags    args.PruneExcludeFlags
+	objectPatchFlags     args.ObjectPatchFlags
 	gitCredentials       args.GitCredentials
 	helmCredentials      args.HelmCredentials
 	registryCredentials  args.RegistryCredentials
 	dryRunArgs           *args.DryRunFlags
 	renderOutputDirFlags args.RenderOutputDirFlags
 	commandResultFlags   *args.CommandResultFlags
+	lockFlags            *args.LockFlags
+	changedFilesFlags    *args.ChangedFilesFlags
+	notifyFlags          *args.NotifyFlags
+	resultWebhookFlags   *args.ResultWebhookFlags
 
-	discriminator string
+	discriminator     string
+	namespaceOverride string
 
 	internalDeploy    bool
 	forCompletion     bool
 	offlineKubernetes bool
 	kubernetesVersion string
+
+	// skipDeprecationErrors disables enforcement of deprecations.errors from .kluctl.yaml, so that usages can be
+	// reported instead
+	skipDeprecationErrors bool
 }
 
 type commandCtx struct {
 	targetCtx *target_context.TargetContext
 	images    *deployment.Images
 
-	resultId    string
-	resultStore results.ResultStore
+	resultId      string
+	resultStore   results.ResultStore
+	resultWriters []namedResultWriter
+	resultSigner  *results.ResultSigner
+	// resultStoreBestEffort downgrades failures to write the command result to warnings
+	resultStoreBestEffort bool
+
+	// notifyFlags and resultWebhookFlags are nil for commands that don't support notifications
+	notifyFlags        *args.NotifyFlags
+	resultWebhookFlags *args.ResultWebhookFlags
+
+	fetchScheduler *repocache.FetchScheduler
+	lockRecorder   *projectlock.Recorder
 }
 
 func withProjectCommandContext(ctx context.Context, args projectTargetCommandArgs, cb func(cmdCtx *commandCtx) error) error {
@@ -185,6 +232,16 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 		return err
 	}
 
+	pruneExclude, err := args.pruneExcludeFlags.ParsePruneExcludeFromArgs()
+	if err != nil {
+		return err
+	}
+
+	objectPatches, err := args.objectPatchFlags.ParseObjectPatchesFromArgs()
+	if err != nil {
+		return err
+	}
+
 	renderOutputDir := args.renderOutputDirFlags.RenderOutputDir
 	if renderOutputDir == "" {
 		tmpDir, err := os.MkdirTemp(tmpDir, "rendered")
@@ -202,12 +259,17 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 		Discriminator:      args.discriminator,
 		OfflineK8s:         args.offlineKubernetes,
 		K8sVersion:         args.kubernetesVersion,
-		DryRun:             args.dryRunArgs == nil || args.dryRunArgs.DryRun || args.forCompletion,
+		DryRun:             args.dryRunArgs == nil || args.dryRunArgs.DryRun || args.forCompletion || isReadOnly(ctx),
 		Images:             images,
 		Inclusion:          inclusion,
 		OciAuthProvider:    p.LoadArgs.OciAuthProvider,
 		HelmAuthProvider:   p.LoadArgs.HelmAuthProvider,
 		RenderOutputDir:    renderOutputDir,
+		PruneExclude:       pruneExclude,
+		ObjectPatches:      objectPatches,
+
+		NamespaceOverride:       args.namespaceOverride,
+		StrictNamespaceOverride: args.targetFlags.StrictNamespaceOverride,
 	}
 
 	commandResultId := uuid.NewString()
@@ -216,14 +278,23 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 	if err != nil {
 		return err
 	}
+	if clientConfig != nil {
+		kubeconfigSource, err := p.GetKubeconfigSource(targetParams.TargetName)
+		if err != nil {
+			return err
+		}
+		targetParams.KubeconfigSource = k8s.DescribeKubeconfigSource(kubeconfigSource)
+	}
 
 	var k *k8s.K8sCluster
+	var mapper meta.RESTMapper
 	var resultStore results.ResultStore
 	if clientConfig != nil {
-		discovery, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, clientConfig)
+		discovery, mapper2, err := k8s.CreateDiscoveryAndMapper(ctx, clientConfig)
 		if err != nil {
 			return err
 		}
+		mapper = mapper2
 
 		s := status.Start(ctx, fmt.Sprintf("Initializing k8s client"))
 		k, err = k8s.NewK8sCluster(ctx, clientConfig, discovery, mapper, targetParams.DryRun)
@@ -231,6 +302,7 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 			s.Failed()
 			return err
 		}
+		k.ReadOnly = isReadOnly(ctx)
 		s.Success()
 
 		resultStore, err = buildResultStoreRW(ctx, clientConfig, mapper, args.commandResultFlags, false)
@@ -248,22 +320,107 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 	}
 
 	if !args.forCompletion {
+		if args.changedFilesFlags != nil && args.changedFilesFlags.OnlyChangedFiles {
+			err = restrictToChangedFiles(ctx, targetCtx, args.changedFilesFlags)
+			if err != nil {
+				return err
+			}
+		}
 		err = targetCtx.DeploymentCollection.Prepare()
 		if err != nil {
 			return err
 		}
+		if !args.skipDeprecationErrors {
+			err = targetCtx.SharedContext.Deprecations.CheckErrors(p.GetDeprecationErrors())
+			if err != nil {
+				return err
+			}
+		}
+		if args.lockFlags != nil && args.lockFlags.Locked {
+			err = checkProjectLock(ctx, args.lockFlags, p.LoadArgs.ProjectDir, args.targetFlags.Target)
+			if err != nil {
+				return err
+			}
+		}
 	}
+	var resultWriters []namedResultWriter
+	var resultSigner *results.ResultSigner
+	resultStoreBestEffort := false
+	if !args.forCompletion {
+		if args.commandResultFlags != nil {
+			resultStoreBestEffort, err = parseResultStoreWriteMode(args.commandResultFlags.ResultStoreWrite)
+			if err != nil {
+				return err
+			}
+		}
+		resultWriters, err = buildResultWriters(ctx, clientConfig, mapper, k, &targetCtx.Target, args.commandResultFlags, resultStore)
+		if err != nil {
+			return err
+		}
+		if len(resultWriters) != 0 && args.commandResultFlags.SignCommandResultKey != "" {
+			resultSigner, err = results.LoadResultSigner(args.commandResultFlags.SignCommandResultKey.String(), []byte(os.Getenv("COSIGN_PASSWORD")))
+			if err != nil {
+				return err
+			}
+		}
+		if k != nil && len(resultWriters) != 0 && !isReadOnly(ctx) {
+			// errors are ignored here, as they are already reported when the command result is built
+			clusterId, _, _ := k.DetermineClusterId(targetCtx.Target.ClusterId)
+			flushResultSpoolToWriters(ctx, clusterId, resultWriters)
+		}
+	}
+
 	cmdCtx := &commandCtx{
-		targetCtx:   targetCtx,
-		images:      images,
-		resultId:    commandResultId,
-		resultStore: resultStore,
+		targetCtx:     targetCtx,
+		images:        images,
+		resultId:      commandResultId,
+		resultStore:   resultStore,
+		resultWriters: resultWriters,
+		resultSigner:  resultSigner,
+
+		resultStoreBestEffort: resultStoreBestEffort,
+
+		notifyFlags:        args.notifyFlags,
+		resultWebhookFlags: args.resultWebhookFlags,
+
+		fetchScheduler: repocache.GetFetchScheduler(ctx),
+		lockRecorder:   projectlock.GetRecorder(ctx),
 	}
 
 	return cb(cmdCtx)
 }
 
-func clientConfigGetter(kubeconfigFlags *args.KubeconfigFlags, forCompletion bool) func(context *string) (*rest.Config, *api.Config, error) {
+// checkProjectLock compares everything resolved while loading and rendering the target against the lock file
+func buildDeletedManifestsOptions(flags args.DeletedManifestsFlags) commands.DeletedManifestsOptions {
+	return commands.DeletedManifestsOptions{
+		Skip:    flags.NoCaptureDeletedManifests,
+		MaxSize: flags.MaxDeletedManifestSize,
+	}
+}
+
+func checkProjectLock(ctx context.Context, lockFlags *args.LockFlags, projectDir string, target string) error {
+	lf, err := projectlock.LoadLockFile(lockFlags.GetLockFile(projectDir))
+	if err != nil {
+		return err
+	}
+	locked, err := projectlock.GetTargetLock(lf, target)
+	if err != nil {
+		return err
+	}
+
+	drifts := projectlock.Compare(locked, projectlock.GetRecorder(ctx).GetLock())
+	if len(drifts) == 0 {
+		return nil
+	}
+	var lines []string
+	for _, d := range drifts {
+		lines = append(lines, "  - "+d.String())
+	}
+	return fmt.Errorf("%d inputs differ from the lock file:\n%s", len(drifts), strings.Join(lines, "\n"))
+}
+
+func clientConfigGetter(ctx context.Context, kubeconfigFlags *args.KubeconfigFlags, forCompletion bool) func(context *string) (*rest.Config, *api.Config, error) {
+	readOnly := isReadOnly(ctx)
 	return func(context *string) (*rest.Config, *api.Config, error) {
 		if forCompletion {
 			return nil, nil, nil
@@ -289,14 +446,43 @@ func clientConfigGetter(kubeconfigFlags *args.KubeconfigFlags, forCompletion boo
 		if err != nil {
 			return nil, nil, err
 		}
+		if readOnly {
+			restConfig = k8s.WrapReadOnly(restConfig)
+		}
+		restConfig = stats.WrapRestConfig(ctx, restConfig)
 		return restConfig, &rawConfig, nil
 	}
 }
 
+// kubeconfigSecretGetter returns a k8s.SecretGetter that reads kubeconfig secrets via the default kubeconfig (or the
+// one passed via --kubeconfig)
+func kubeconfigSecretGetter(ctx context.Context, kubeconfigFlags *args.KubeconfigFlags) k8s.SecretGetter {
+	getter := clientConfigGetter(ctx, kubeconfigFlags, false)
+	return func(ctx context.Context, kubeContext *string, namespace string, name string) (*corev1.Secret, error) {
+		restConfig, _, err := getter(kubeContext)
+		if err != nil {
+			return nil, err
+		}
+		c, err := client2.New(restConfig, client2.Options{})
+		if err != nil {
+			return nil, err
+		}
+		var secret corev1.Secret
+		err = c.Get(ctx, client2.ObjectKey{Namespace: namespace, Name: name}, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kubeconfig secret %s/%s: %w", namespace, name, err)
+		}
+		return &secret, nil
+	}
+}
+
 func buildResultStoreRO(ctx context.Context, restConfig *rest.Config, mapper meta.RESTMapper, flags *args.CommandResultReadOnlyFlags) (results.ResultStore, error) {
 	if flags == nil {
 		return nil, nil
 	}
+	if flags.CommandResultS3Bucket != "" {
+		return buildResultStoreS3(ctx, &flags.CommandResultS3Flags, false, 0, 0)
+	}
 
 	c, err := client2.NewWithWatch(restConfig, client2.Options{
 		Mapper: mapper,
@@ -317,6 +503,14 @@ func buildResultStoreRW(ctx context.Context, restConfig *rest.Config, mapper met
 	if flags == nil || !flags.WriteCommandResult {
 		return nil, nil
 	}
+	if flags.CommandResultS3Bucket != "" {
+		resultStore, err := buildResultStoreS3(ctx, &flags.CommandResultS3Flags, true, flags.KeepCommandResultsCount, flags.KeepValidateResultsCount)
+		if err != nil {
+			return nil, err
+		}
+		resultStore.SetMaxCommandResultAge(time.Duration(flags.KeepCommandResultsMaxAge))
+		return resultStore, nil
+	}
 
 	c, err := client2.NewWithWatch(restConfig, client2.Options{
 		Mapper: mapper,
@@ -329,6 +523,7 @@ func buildResultStoreRW(ctx context.Context, restConfig *rest.Config, mapper met
 	if err != nil {
 		return nil, err
 	}
+	resultStore.SetMaxCommandResultAge(time.Duration(flags.KeepCommandResultsMaxAge))
 
 	if startCleanup {
 		err = resultStore.StartCleanupOrphans()
@@ -339,3 +534,11 @@ func buildResultStoreRW(ctx context.Context, restConfig *rest.Config, mapper met
 
 	return resultStore, nil
 }
+
+func buildResultStoreS3(ctx context.Context, flags *args.CommandResultS3Flags, allowWrite bool, keepCommandResultsCount int, keepValidateResultsCount int) (*results.ResultStoreS3, error) {
+	s3Client, err := aws.NewS3Client(ctx, flags.CommandResultS3Region, flags.CommandResultS3Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client for bucket %s: %w", flags.CommandResultS3Bucket, err)
+	}
+	return results.NewResultStoreS3(ctx, s3Client, flags.CommandResultS3Bucket, flags.CommandResultS3Prefix, allowWrite, keepCommandResultsCount, keepValidateResultsCount), nil
+}
@@ -0,0 +1,132 @@
+package e2e
+
+import (
+	"context"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type GitOpsArgsFromSuite struct {
+	GitopsTestSuite
+}
+
+func TestGitOpsArgsFrom(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(GitOpsArgsFromSuite))
+}
+
+func (suite *GitOpsArgsFromSuite) waitForConfigMapValue(namespace string, name string, key string, value string) {
+	g := NewWithT(suite.T())
+	g.Eventually(func() string {
+		var cm corev1.ConfigMap
+		err := suite.k.Client.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: name}, &cm)
+		if err != nil {
+			return ""
+		}
+		return cm.Data[key]
+	}, timeout, time.Second).Should(Equal(value))
+}
+
+func (suite *GitOpsArgsFromSuite) TestArgsFrom() {
+	g := NewWithT(suite.T())
+
+	p := test_project.NewTestProject(suite.T())
+	createNamespace(suite.T(), suite.k, p.TestSlug())
+
+	p.UpdateTarget("target1", nil)
+	p.AddKustomizeDeployment("d1", []test_project.KustomizeResource{
+		{Name: "cm1.yaml", Content: uo.FromStringMust(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+  namespace: "{{ args.namespace }}"
+data:
+  region: "{{ args.cluster.region }}"
+  inline: "{{ args.inline }}"
+  secret: "{{ args.password }}"
+`)},
+	}, nil)
+
+	cmName := p.TestSlug() + "-args"
+	secretName := p.TestSlug() + "-secret-args"
+
+	key := suite.createKluctlDeployment2(p, "target1", map[string]any{
+		"namespace": p.TestSlug(),
+		"inline":    "inline",
+	}, func(kd *kluctlv1.KluctlDeployment) {
+		kd.Spec.Source.Git = &kluctlv1.ProjectSourceGit{
+			URL: p.GitUrl(),
+		}
+		kd.Spec.ArgsFrom = []kluctlv1.ArgsFromReference{
+			{Kind: "ConfigMap", Name: cmName, Items: []kluctlv1.ArgsFromItem{
+				{Key: "region", Arg: "cluster.region"},
+				{Key: "inline"},
+			}},
+			{Kind: "Secret", Name: secretName},
+		}
+	})
+
+	suite.Run("missing reference", func() {
+		g.Eventually(func() bool {
+			c := suite.getReadiness(suite.getKluctlDeployment(key))
+			return c != nil && c.Status == metav1.ConditionFalse && c.Reason == kluctlv1.ArgsFromNotFoundReason &&
+				strings.Contains(c.Message, cmName)
+		}, timeout, time.Second).Should(BeTrue())
+		assertConfigMapNotExists(suite.T(), suite.k, p.TestSlug(), "cm1")
+	})
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: suite.gitopsNamespace, Name: cmName},
+		Data: map[string]string{
+			"region": "eu-west-1",
+			"inline": "from-cm",
+		},
+	}
+	g.Expect(suite.k.Client.Create(context.TODO(), cm)).To(Succeed())
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: suite.gitopsNamespace, Name: secretName},
+		Data: map[string][]byte{
+			"password": []byte("secret1"),
+		},
+	}
+	g.Expect(suite.k.Client.Create(context.TODO(), secret)).To(Succeed())
+
+	suite.Run("references created", func() {
+		suite.waitForConfigMapValue(p.TestSlug(), "cm1", "region", "eu-west-1")
+		o := assertConfigMapExists(suite.T(), suite.k, p.TestSlug(), "cm1")
+		// inline args take precedence
+		v, _, _ := o.GetNestedString("data", "inline")
+		g.Expect(v).To(Equal("inline"))
+		v, _, _ = o.GetNestedString("data", "secret")
+		g.Expect(v).To(Equal("secret1"))
+	})
+
+	suite.Run("secret args are redacted", func() {
+		kd := suite.getKluctlDeployment(key)
+		lastDeployResult, err := kd.Status.GetLastDeployResult()
+		g.Expect(err).To(Succeed())
+		cr := suite.getCommandResult(lastDeployResult.Id)
+		g.Expect(cr).ToNot(BeNil())
+		v, _, _ := cr.Command.Args.GetNestedString("password")
+		g.Expect(v).To(Equal("*****"))
+		v, _, _ = cr.Command.Args.GetNestedString("cluster", "region")
+		g.Expect(v).To(Equal("eu-west-1"))
+	})
+
+	suite.Run("changes are reconciled", func() {
+		patch := client.MergeFrom(cm.DeepCopy())
+		cm.Data["region"] = "eu-central-1"
+		g.Expect(suite.k.Client.Patch(context.TODO(), cm, patch)).To(Succeed())
+		suite.waitForConfigMapValue(p.TestSlug(), "cm1", "region", "eu-central-1")
+	})
+}
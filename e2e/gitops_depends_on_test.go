@@ -0,0 +1,93 @@
+package e2e
+
+import (
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/stretchr/testify/suite"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type GitOpsDependsOnSuite struct {
+	GitopsTestSuite
+}
+
+func TestGitOpsDependsOn(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(GitOpsDependsOnSuite))
+}
+
+func (suite *GitOpsDependsOnSuite) createDependsOnDeployment(p *test_project.TestProject, name string, target string, dependsOn ...string) client.ObjectKey {
+	return suite.createKluctlDeployment2(p, target, nil, func(kd *kluctlv1.KluctlDeployment) {
+		kd.Name = name
+		kd.Spec.Source.Git = &kluctlv1.ProjectSourceGit{
+			URL: p.GitUrl(),
+		}
+		for _, d := range dependsOn {
+			kd.Spec.DependsOn = append(kd.Spec.DependsOn, kluctlv1.DependsOnReference{Name: d})
+		}
+	})
+}
+
+func (suite *GitOpsDependsOnSuite) waitForReadyReason(key client.ObjectKey, status metav1.ConditionStatus, reason string, msgContains string) {
+	g := NewWithT(suite.T())
+	g.Eventually(func() bool {
+		kd := suite.getKluctlDeployment(key)
+		c := suite.getReadiness(kd)
+		if c == nil || c.Status != status || c.Reason != reason {
+			return false
+		}
+		return strings.Contains(c.Message, msgContains)
+	}, timeout, time.Second).Should(BeTrue())
+}
+
+func (suite *GitOpsDependsOnSuite) TestDependsOn() {
+	p1 := test_project.NewTestProject(suite.T(), test_project.WithRepoName("repo1"))
+	p2 := test_project.NewTestProject(suite.T(), test_project.WithRepoName("repo2"))
+	createNamespace(suite.T(), suite.k, p1.TestSlug())
+
+	p1.UpdateTarget("target1", nil)
+	addConfigMapDeployment(p1, "d1", nil, resourceOpts{
+		name:      "cm1",
+		namespace: p1.TestSlug(),
+	})
+	p2.UpdateTarget("target2", nil)
+	addConfigMapDeployment(p2, "d1", nil, resourceOpts{
+		name:      "cm2",
+		namespace: p1.TestSlug(),
+	})
+
+	name1 := p1.TestSlug() + "-1"
+	name2 := p1.TestSlug() + "-2"
+
+	key2 := suite.createDependsOnDeployment(p2, name2, "target2", name1)
+
+	suite.Run("dependency does not exist", func() {
+		suite.waitForReadyReason(key2, metav1.ConditionFalse, kluctlv1.DependencyNotReadyReason, name1)
+		assertConfigMapNotExists(suite.T(), suite.k, p1.TestSlug(), "cm2")
+	})
+
+	key1 := suite.createDependsOnDeployment(p1, name1, "target1")
+
+	suite.Run("dependency becomes ready", func() {
+		suite.waitForReadyReason(key1, metav1.ConditionTrue, kluctlv1.ReconciliationSucceededReason, "")
+		suite.waitForReadyReason(key2, metav1.ConditionTrue, kluctlv1.ReconciliationSucceededReason, "")
+		assertConfigMapExists(suite.T(), suite.k, p1.TestSlug(), "cm1")
+		assertConfigMapExists(suite.T(), suite.k, p1.TestSlug(), "cm2")
+	})
+
+	suite.updateKluctlDeployment(key1, func(kd *kluctlv1.KluctlDeployment) {
+		kd.Spec.DependsOn = []kluctlv1.DependsOnReference{{Name: name2}}
+	})
+
+	suite.Run("cycle is reported on all participants", func() {
+		suite.waitForReadyReason(key1, metav1.ConditionFalse, kluctlv1.DependencyCycleReason, name2)
+		suite.triggerReconcile(key2)
+		suite.waitForReadyReason(key2, metav1.ConditionFalse, kluctlv1.DependencyCycleReason, name1)
+	})
+}
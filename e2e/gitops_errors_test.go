@@ -136,7 +136,7 @@ data:
 			return "a: b", nil
 		}, "")
 		kd := suite.waitForReconcile(key)
-		suite.assertErrors(kd, metav1.ConditionFalse, kluctlv1.PrepareFailedReason, "prepare failed. Check status.lastPrepareError for details", ".kluctl.yml failed: error unmarshaling JSON: while decoding JSON: json: unknown field \"a\"", nil, nil)
+		suite.assertErrors(kd, metav1.ConditionFalse, kluctlv1.PrepareFailedReason, "prepare failed. Check status.lastPrepareError for details", ".kluctl.yml:1:1: unknown field \"a\"", nil, nil)
 		p.UpdateFile(".kluctl.yml", func(f string) (string, error) {
 			return kluctlBackup, nil
 		}, "")
@@ -150,7 +150,7 @@ data:
 			return "a: b", nil
 		}, "")
 		kd := suite.waitForReconcile(key)
-		suite.assertErrors(kd, metav1.ConditionFalse, kluctlv1.PrepareFailedReason, "prepare failed. Check status.lastPrepareError for details", "failed to load deployment.yml: error unmarshaling JSON: while decoding JSON: json: unknown field \"a\"", nil, nil)
+		suite.assertErrors(kd, metav1.ConditionFalse, kluctlv1.PrepareFailedReason, "prepare failed. Check status.lastPrepareError for details", "failed to load deployment.yml: deployment.yml:1:1: unknown field \"a\"", nil, nil)
 		p.UpdateFile("deployment.yml", func(f string) (string, error) {
 			return deploymentBackup, nil
 		}, "")
@@ -6,11 +6,13 @@ import (
 	"github.com/kluctl/kluctl/v2/e2e/test_project"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/meta"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"testing"
@@ -424,3 +426,68 @@ func (suite *GitOpsManualRequestsSuite) TestOverrides() {
 		assertNestedFieldEquals(suite.T(), cm1, "via_target", "data", "k3")
 	})
 }
+
+func (suite *GitOpsManualRequestsSuite) TestCancel() {
+	g := NewWithT(suite.T())
+
+	p := test_project.NewTestProject(suite.T())
+	p.AddExtraArgs("--controller-namespace", suite.gitopsNamespace+"-system")
+	createNamespace(suite.T(), suite.k, p.TestSlug())
+
+	p.UpdateTarget("target1", nil)
+	addConfigMapDeployment(p, "d1", nil, resourceOpts{
+		name:      "cm1",
+		namespace: p.TestSlug(),
+		annotations: map[string]string{
+			"kluctl.io/is-ready":       "false",
+			"kluctl.io/wait-readiness": "true",
+		},
+	})
+
+	key := suite.createKluctlDeployment(p, "target1", nil)
+
+	suite.Run("cancel in-progress reconciliation", func() {
+		// wait for the controller to hang while waiting for readiness of cm1
+		g.Eventually(func() bool {
+			kd := suite.getKluctlDeployment(key)
+			c := apimeta.FindStatusCondition(kd.Status.Conditions, meta.ReconcilingCondition)
+			if c == nil || c.Status != metav1.ConditionTrue || c.Message != "Performing reconcile" {
+				return false
+			}
+			var cm1 corev1.ConfigMap
+			err := suite.k.Client.Get(context.Background(), client.ObjectKey{Name: "cm1", Namespace: p.TestSlug()}, &cm1)
+			return err == nil
+		}, timeout, time.Second).Should(BeTrue())
+
+		p.KluctlMust(suite.T(), "gitops", "cancel", "--context", suite.k.Context, "--namespace", key.Namespace, "--name", key.Name)
+
+		kd := suite.getKluctlDeployment(key)
+		rr := kd.Status.CancelRequestResult
+		g.Expect(rr).ToNot(BeNil())
+		assert.NotEmpty(suite.T(), rr.Request.RequestedBy)
+		assert.NotEmpty(suite.T(), rr.ReconcileId)
+		assert.Equal(suite.T(), "Performing reconcile", rr.Phase)
+		assert.NotNil(suite.T(), rr.EndTime)
+		assert.False(suite.T(), rr.Stuck)
+		assert.Equal(suite.T(), "reconciliation was cancelled", rr.Message)
+
+		ldr, err := kd.Status.GetLastDeployResult()
+		g.Expect(err).To(Succeed())
+		g.Expect(ldr).ToNot(BeNil())
+		assert.NotEmpty(suite.T(), ldr.Errors)
+	})
+
+	suite.Run("cancel without in-progress reconciliation", func() {
+		// a manual reconcile would hang again, so we only wait for the suspension to be observed
+		p.KluctlMust(suite.T(), "gitops", "suspend", "--context", suite.k.Context, "--namespace", key.Namespace, "--name", key.Name)
+
+		p.KluctlMust(suite.T(), "gitops", "cancel", "--context", suite.k.Context, "--namespace", key.Namespace, "--name", key.Name)
+
+		kd := suite.getKluctlDeployment(key)
+		rr := kd.Status.CancelRequestResult
+		g.Expect(rr).ToNot(BeNil())
+		assert.Empty(suite.T(), rr.ReconcileId)
+		assert.Equal(suite.T(), "no reconciliation was in progress", rr.Message)
+		assert.NotContains(suite.T(), kd.GetAnnotations(), kluctlv1.KluctlRequestCancelAnnotation)
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/stretchr/testify/suite"
 	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"testing"
 	"time"
@@ -192,3 +194,119 @@ func (suite *GitOpsPruneDeleteSuite) Test_Delete_True() {
 func (suite *GitOpsPruneDeleteSuite) Test_Delete_False() {
 	suite.doTestDelete(false)
 }
+
+func (suite *GitOpsPruneDeleteSuite) deployBlockedConfigMaps(deletionPolicy string, deletionTimeout time.Duration) (*test_project.TestProject, client.ObjectKey) {
+	g := NewWithT(suite.T())
+
+	p := test_project.NewTestProject(suite.T())
+	createNamespace(suite.T(), suite.k, p.TestSlug())
+
+	p.UpdateTarget("target1", nil)
+
+	p.AddKustomizeDeployment("d1", []test_project.KustomizeResource{
+		{Name: "cm1.yaml", Content: uo.FromStringMust(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+  namespace: "{{ args.namespace }}"
+data:
+  k1: v1
+`)},
+		{Name: "cm2.yaml", Content: uo.FromStringMust(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm2
+  namespace: "{{ args.namespace }}"
+  annotations:
+    kluctl.io/skip-delete: "true"
+data:
+  k1: v1
+`)},
+	}, nil)
+
+	key := suite.createKluctlDeployment(p, "target1", map[string]any{
+		"namespace": p.TestSlug(),
+	})
+
+	suite.updateKluctlDeployment(key, func(kd *kluctlv1.KluctlDeployment) {
+		kd.Spec.DeletionPolicy = deletionPolicy
+		kd.Spec.DeletionTimeout = &metav1.Duration{Duration: deletionTimeout}
+	})
+
+	suite.waitForCommit(key, getHeadRevision(suite.T(), p))
+
+	// block deletion of cm1 until we remove the finalizer
+	cm := &corev1.ConfigMap{}
+	err := suite.k.Client.Get(context.TODO(), client.ObjectKey{Name: "cm1", Namespace: p.TestSlug()}, cm)
+	g.Expect(err).To(Succeed())
+	cm.Finalizers = append(cm.Finalizers, "e2e.kluctl.io/block")
+	err = suite.k.Client.Update(context.TODO(), cm)
+	g.Expect(err).To(Succeed())
+
+	return p, key
+}
+
+func (suite *GitOpsPruneDeleteSuite) removeBlockingFinalizer(namespace string) {
+	g := NewWithT(suite.T())
+
+	cm := &corev1.ConfigMap{}
+	err := suite.k.Client.Get(context.TODO(), client.ObjectKey{Name: "cm1", Namespace: namespace}, cm)
+	g.Expect(err).To(Succeed())
+	cm.Finalizers = nil
+	err = suite.k.Client.Update(context.TODO(), cm)
+	g.Expect(err).To(Succeed())
+}
+
+func (suite *GitOpsPruneDeleteSuite) Test_Delete_And_Wait() {
+	g := NewWithT(suite.T())
+
+	p, key := suite.deployBlockedConfigMaps(kluctlv1.DeletionPolicyDeleteAndWait, 10*time.Minute)
+
+	suite.deleteKluctlDeployment(key)
+
+	suite.Run("terminating condition reports remaining objects", func() {
+		g.Eventually(func() string {
+			kd := suite.getKluctlDeploymentAllowNil(key)
+			g.Expect(kd).ToNot(BeNil())
+			c := apimeta.FindStatusCondition(kd.Status.Conditions, kluctlv1.TerminatingCondition)
+			if c == nil {
+				return ""
+			}
+			return c.Message
+		}, timeout, time.Second).Should(Equal("Waiting for objects to be deleted: 1 ConfigMap"))
+	})
+
+	suite.removeBlockingFinalizer(p.TestSlug())
+
+	g.Eventually(func() bool {
+		return suite.getKluctlDeploymentAllowNil(key) == nil
+	}, timeout, time.Second).Should(BeTrue())
+
+	cm := &corev1.ConfigMap{}
+	suite.Run("cm1 was deleted and protected cm2 was not", func() {
+		err := suite.k.Client.Get(context.TODO(), client.ObjectKey{Name: "cm1", Namespace: p.TestSlug()}, cm)
+		g.Expect(err).To(MatchError("configmaps \"cm1\" not found"))
+		err = suite.k.Client.Get(context.TODO(), client.ObjectKey{Name: "cm2", Namespace: p.TestSlug()}, cm)
+		g.Expect(err).To(Succeed())
+	})
+}
+
+func (suite *GitOpsPruneDeleteSuite) Test_Delete_Timeout() {
+	g := NewWithT(suite.T())
+
+	p, key := suite.deployBlockedConfigMaps(kluctlv1.DeletionPolicyDeleteAndWait, 10*time.Second)
+	defer suite.removeBlockingFinalizer(p.TestSlug())
+
+	suite.deleteKluctlDeployment(key)
+
+	g.Eventually(func() bool {
+		return suite.getKluctlDeploymentAllowNil(key) == nil
+	}, timeout, time.Second).Should(BeTrue())
+
+	suite.Run("cm1 is still terminating", func() {
+		cm := &corev1.ConfigMap{}
+		err := suite.k.Client.Get(context.TODO(), client.ObjectKey{Name: "cm1", Namespace: p.TestSlug()}, cm)
+		g.Expect(err).To(Succeed())
+		g.Expect(cm.DeletionTimestamp).ToNot(BeNil())
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/kluctl/kluctl/v2/e2e/test-utils"
 	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -320,3 +322,79 @@ func TestHooksWait(t *testing.T) {
 	_, err = s.ensureHookExecuted2(t, 5*time.Second, "cm1", "hook1", "hook2", "hook3")
 	assert.NoError(t, err)
 }
+
+func TestHooksDryRunSafe(t *testing.T) {
+	t.Parallel()
+
+	s := prepareHookTestProjectBase(t)
+
+	s.p.AddKustomizeDeployment("hook", nil, nil)
+
+	s.addConfigMap("hook", resourceOpts{name: "cm1", namespace: s.p.TestSlug()})
+	s.addHookConfigMap("hook", resourceOpts{name: "hook1", namespace: s.p.TestSlug(), annotations: map[string]string{
+		"kluctl.io/hook-dry-run-safe": "true",
+	}}, false, "pre-deploy", "")
+	s.addHookConfigMap("hook", resourceOpts{name: "hook2", namespace: s.p.TestSlug()}, false, "pre-deploy", "")
+
+	// diffs must not execute dry-run-safe hooks
+	s.p.KluctlMust(t, "diff", "-t", "test")
+	assertConfigMapNotExists(t, s.k, s.p.TestSlug(), "hook1")
+
+	stdout, _ := s.p.KluctlMust(t, "deploy", "--yes", "--dry-run", "-t", "test")
+	assertConfigMapExists(t, s.k, s.p.TestSlug(), "hook1")
+	assertConfigMapNotExists(t, s.k, s.p.TestSlug(), "hook2")
+	assertConfigMapNotExists(t, s.k, s.p.TestSlug(), "cm1")
+	assert.Contains(t, stdout, "hook2")
+
+	s.p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assertConfigMapExists(t, s.k, s.p.TestSlug(), "hook2")
+	assertConfigMapExists(t, s.k, s.p.TestSlug(), "cm1")
+}
+
+func TestHooksPhases(t *testing.T) {
+	t.Parallel()
+
+	s := prepareHookTestProjectBase(t)
+
+	s.p.AddKustomizeDeployment("a", nil, nil)
+	s.addConfigMap("a", resourceOpts{name: "cm1", namespace: s.p.TestSlug()})
+	s.addHookConfigMap("a", resourceOpts{name: "hook1", namespace: s.p.TestSlug()}, false, "pre-deploy", "")
+	s.p.AddDeploymentItem(".", uo.FromMap(map[string]interface{}{
+		"barrier": true,
+	}))
+	s.p.AddKustomizeDeployment("b", nil, nil)
+	s.addConfigMap("b", resourceOpts{name: "cm2", namespace: s.p.TestSlug()})
+	s.addHookConfigMap("b", resourceOpts{name: "hook2", namespace: s.p.TestSlug()}, false, "post-deploy", "")
+
+	cr, _ := s.p.KluctlMustCommandResult(t, "deploy", "--yes", "-t", "test", "-oyaml")
+
+	cmRef := func(name string) k8s.ObjectRef {
+		return k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: name, Namespace: s.p.TestSlug()}
+	}
+	type phase struct {
+		Type           result.PhaseType
+		DeploymentItem string
+		Objects        []k8s.ObjectRef
+	}
+	var phases []phase
+	for _, x := range cr.Phases {
+		phases = append(phases, phase{Type: x.Type, DeploymentItem: x.DeploymentItem, Objects: x.Objects})
+	}
+	assert.Equal(t, []phase{
+		{Type: result.PhasePreDeployHooks, DeploymentItem: "a", Objects: []k8s.ObjectRef{cmRef("hook1")}},
+		{Type: result.PhaseApply, DeploymentItem: "a", Objects: []k8s.ObjectRef{cmRef("cm1")}},
+		{Type: result.PhaseApply, DeploymentItem: "b", Objects: []k8s.ObjectRef{cmRef("cm2")}},
+		{Type: result.PhasePostDeployHooks, DeploymentItem: "b", Objects: []k8s.ObjectRef{cmRef("hook2")}},
+	}, phases)
+
+	for i, x := range cr.Phases {
+		assert.False(t, x.StartTime.IsZero())
+		assert.False(t, x.EndTime.IsZero())
+		assert.False(t, x.EndTime.Before(&x.StartTime))
+		if i != 0 {
+			assert.False(t, x.StartTime.Before(&cr.Phases[i-1].StartTime))
+		}
+	}
+	// the barrier ensures that the phases of "b" only start after all phases of "a" have finished
+	assert.False(t, cr.Phases[2].StartTime.Before(&cr.Phases[1].EndTime))
+}
@@ -1,8 +1,12 @@
 package e2e
 
 import (
+	"context"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
 	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -125,3 +129,67 @@ func TestPruneForceManaged(t *testing.T) {
 	p.KluctlMust(t, "prune", "--yes", "-t", "test")
 	assertConfigMapNotExists(t, k, p.TestSlug(), "cm2")
 }
+
+func TestDeployWithPruneSafetyThreshold(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	for _, name := range []string{"cm1", "cm2", "cm3"} {
+		addConfigMapDeployment(p, name, map[string]string{}, resourceOpts{
+			name:      name,
+			namespace: p.TestSlug(),
+		})
+	}
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm3")
+
+	// we must ensure that at least a second passes between deployments, as otherwise command result sorting becomes
+	// unstable
+	b := newSecondPassedBarrier(t)
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+		_ = target.SetNestedField(int64(1), "safetyThreshold", "maxDeletedObjects")
+	})
+	p.DeleteKustomizeDeployment("cm2")
+	p.DeleteKustomizeDeployment("cm3")
+	addConfigMapDeployment(p, "cm4", map[string]string{}, resourceOpts{
+		name:      "cm4",
+		namespace: p.TestSlug(),
+	})
+
+	b.Wait()
+	_, _, err := p.Kluctl(t, "deploy", "--yes", "-t", "test", "--prune")
+	assert.ErrorContains(t, err, "maxDeletedObjects=1")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm3")
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm4")
+
+	rs, err := results.NewResultStoreSecrets(context.Background(), k.RESTConfig(), k.Client, false, "kluctl-results", 0, 0)
+	assert.NoError(t, err)
+	summaries, err := rs.ListCommandResultSummaries(results.ListResultSummariesOptions{
+		ProjectFilter: &gittypes.ProjectKey{
+			RepoKey: gittypes.ParseGitUrlMust(p.GitUrl()).RepoKey(),
+		},
+	})
+	assert.NoError(t, err)
+	if !assert.Len(t, summaries, 2) {
+		return
+	}
+	assert.Equal(t, 1, summaries[0].Errors)
+	assert.Equal(t, 0, summaries[0].DeletedObjects)
+
+	cr, err := rs.GetCommandResult(results.GetCommandResultOptions{Id: summaries[0].Id})
+	assert.NoError(t, err)
+	if assert.Len(t, cr.Errors, 1) {
+		assert.Contains(t, cr.Errors[0].Message, "exceeds the safety threshold of the target (maxDeletedObjects=1)")
+	}
+}
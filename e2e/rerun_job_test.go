@@ -0,0 +1,125 @@
+package e2e
+
+import (
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+)
+
+var jobGvr = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+
+func createJobObject(image string, opts resourceOpts) *uo.UnstructuredObject {
+	o := uo.New()
+	o.SetK8sGVKs("batch", "v1", "Job")
+	mergeMetadata(o, opts)
+	_ = o.SetNestedField(map[string]any{
+		"template": map[string]any{
+			"spec": map[string]any{
+				"restartPolicy": "Never",
+				"containers": []any{
+					map[string]any{
+						"name":  "job",
+						"image": image,
+					},
+				},
+			},
+		},
+	}, "spec")
+	return o
+}
+
+func testRerunJob(t *testing.T, mode string) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	o := createJobObject("busybox:1", resourceOpts{
+		name:      "job",
+		namespace: p.TestSlug(),
+		annotations: map[string]string{
+			"kluctl.io/rerun-job": mode,
+		},
+	})
+	p.AddKustomizeDeployment("job", []test_project.KustomizeResource{
+		{Name: "job.yml", Content: o},
+	}, nil)
+
+	getUid := func() string {
+		o := assertObjectExists(t, k, jobGvr, p.TestSlug(), "job")
+		uid, _, _ := o.GetNestedString("metadata", "uid")
+		return uid
+	}
+	assertRerun := func(cr *result.CommandResult, reason string) {
+		if reason == "" {
+			assert.Empty(t, cr.RerunJobs)
+			return
+		}
+		if assert.Len(t, cr.RerunJobs, 1) {
+			assert.Equal(t, "job", cr.RerunJobs[0].Ref.Name)
+			assert.Equal(t, reason, cr.RerunJobs[0].Reason)
+			assert.Equal(t, result.RerunJobStatusStarted, cr.RerunJobs[0].Status)
+		}
+	}
+
+	cr, _ := p.KluctlMustCommandResult(t, "deploy", "--yes", "-t", "test", "-oyaml")
+	assertRerun(cr, "created")
+	uid1 := getUid()
+
+	cr, _ = p.KluctlMustCommandResult(t, "deploy", "--yes", "-t", "test", "-oyaml")
+	uid2 := getUid()
+	assertRerun(cr, "")
+	assert.Equal(t, uid1, uid2)
+
+	p.UpdateYaml("job/job.yml", func(o *uo.UnstructuredObject) error {
+		return o.SetNestedField("busybox:2", "spec", "template", "spec", "containers", 0, "image")
+	}, "")
+	cr, _ = p.KluctlMustCommandResult(t, "deploy", "--yes", "-t", "test", "-oyaml")
+	assertRerun(cr, "changed")
+	assert.NotEqual(t, uid2, getUid())
+
+	o = assertObjectExists(t, k, jobGvr, p.TestSlug(), "job")
+	assertNestedFieldEquals(t, o, "busybox:2", "spec", "template", "spec", "containers", 0, "image")
+}
+
+func TestRerunJobOnChange(t *testing.T) {
+	testRerunJob(t, "on-change")
+}
+
+func TestRerunJobTrue(t *testing.T) {
+	testRerunJob(t, "true")
+}
+
+func TestRerunJobAlwaysUnsupported(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	o := createJobObject("busybox:1", resourceOpts{
+		name:      "job",
+		namespace: p.TestSlug(),
+		annotations: map[string]string{
+			"kluctl.io/rerun-job": "always",
+		},
+	})
+	p.AddKustomizeDeployment("job", []test_project.KustomizeResource{
+		{Name: "job.yml", Content: o},
+	}, nil)
+
+	_, stderr, err := p.Kluctl(t, "deploy", "--yes", "-t", "test")
+	assert.Error(t, err)
+	assert.Contains(t, stderr, "kluctl.io/rerun-job value 'always' is not supported")
+	assertObjectNotExists(t, k, jobGvr, p.TestSlug(), "job")
+}
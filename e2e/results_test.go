@@ -116,3 +116,67 @@ func TestWriteResult(t *testing.T) {
 		DeletedObjects: 1,
 	}, summaries[0])
 }
+
+func TestWriteResultToMultipleStores(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	resultsNs := p.TestSlug() + "-results"
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+		_ = target.SetNestedField([]any{
+			map[string]any{
+				"type": "cluster",
+				"cluster": map[string]any{
+					"namespace": resultsNs,
+				},
+			},
+			map[string]any{
+				"type": "cluster",
+				"name": "audit",
+				"cluster": map[string]any{
+					"namespace": resultsNs + "-audit",
+				},
+			},
+		}, "results", "stores")
+	})
+	p.UpdateTarget("dev", func(target *uo.UnstructuredObject) {
+		_ = target.SetNestedField([]any{}, "results", "stores")
+	})
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"d1": "v1",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := results.ListResultSummariesOptions{
+		ProjectFilter: &gittypes.ProjectKey{
+			RepoKey: gittypes.ParseGitUrlMust(p.GitUrl()).RepoKey(),
+		},
+	}
+
+	_, stderr := p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assert.Contains(t, stderr, "Writing command result to cluster:"+resultsNs)
+	assert.Contains(t, stderr, "Writing command result to audit")
+
+	for _, ns := range []string{resultsNs, resultsNs + "-audit"} {
+		rs, err := results.NewResultStoreSecrets(ctx, k.RESTConfig(), k.Client, false, ns, 0, 0)
+		assert.NoError(t, err)
+		summaries, err := rs.ListCommandResultSummaries(opts)
+		assert.NoError(t, err)
+		assert.Len(t, summaries, 1, ns)
+	}
+
+	// the dev target does not write results at all
+	_, stderr = p.KluctlMust(t, "deploy", "--yes", "-t", "dev")
+	assert.NotContains(t, stderr, "Writing command result")
+}
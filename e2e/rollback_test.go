@@ -0,0 +1,89 @@
+package e2e
+
+import (
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func prepareRollbackTest(t *testing.T) (*test_utils.TestProject, secondPassedBarrier) {
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"d1": "v1",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm")
+
+	// ensure stable sorting of command results
+	b := newSecondPassedBarrier(t)
+
+	addConfigMapDeployment(p, "cm2", nil, resourceOpts{
+		name:      "cm2",
+		namespace: p.TestSlug(),
+	})
+	p.UpdateYaml("cm/configmap-cm.yml", func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField("v2", "data", "d1")
+		return nil
+	}, "")
+
+	b.Wait()
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+
+	return p, b
+}
+
+func TestRollback(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+	p, b := prepareRollbackTest(t)
+
+	b.Wait()
+	stdout, _ := p.KluctlMust(t, "rollback", "--yes", "--prune", "-t", "test")
+	assert.Contains(t, stdout, "Rolled back to command result")
+
+	cm := assertConfigMapExists(t, k, p.TestSlug(), "cm")
+	assertNestedFieldEquals(t, cm, "v1", "data", "d1")
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm2")
+}
+
+func TestRollbackDryRun(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+	p, b := prepareRollbackTest(t)
+
+	b.Wait()
+	p.KluctlMust(t, "rollback", "--yes", "--prune", "--dry-run", "-t", "test")
+
+	cm := assertConfigMapExists(t, k, p.TestSlug(), "cm")
+	assertNestedFieldEquals(t, cm, "v2", "data", "d1")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+}
+
+func TestRollbackDiscriminatorChange(t *testing.T) {
+	t.Parallel()
+
+	p, b := prepareRollbackTest(t)
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+		_ = target.SetNestedField("changed-discriminator", "discriminator")
+	})
+
+	b.Wait()
+	stdout, _, err := p.Kluctl(t, "rollback", "--yes", "-t", "test")
+	assert.Error(t, err)
+	assert.Contains(t, stdout, "refusing to roll back across discriminator changes")
+}
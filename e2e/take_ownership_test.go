@@ -0,0 +1,88 @@
+package e2e
+
+import (
+	"context"
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+func TestTakeOwnershipFromKubectl(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	// simulate an object that was previously applied via "kubectl apply"
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cm",
+			Namespace: p.TestSlug(),
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": `{"apiVersion":"v1","kind":"ConfigMap"}`,
+			},
+		},
+		Data: map[string]string{
+			"d1": "v1",
+			"d2": "v2",
+		},
+	}
+	err := k.Client.Create(context.Background(), cm, client.FieldOwner("kubectl-client-side-apply"))
+	assert.NoError(t, err)
+
+	// and let another manager co-own a field
+	patchConfigMap(t, k, p.TestSlug(), "cm", func(o *uo.UnstructuredObject) {
+		_ = o.SetNestedField("v3", "data", "d3")
+	})
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"d1": "v1-new",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	r, _ := p.KluctlMustCommandResult(t, "deploy", "--yes", "-t", "test", "--take-ownership-from", "kubectl-client-side-apply", "-oyaml")
+	assert.Empty(t, r.Errors)
+	assert.Empty(t, r.Warnings)
+
+	var migrated bool
+	for _, o := range r.Objects {
+		if o.Ref.Kind != "ConfigMap" || o.Ref.Name != "cm" {
+			continue
+		}
+		if assert.NotNil(t, o.OwnershipMigration) {
+			migrated = true
+			assert.Equal(t, []string{"kubectl-client-side-apply"}, o.OwnershipMigration.From)
+			assert.NotEmpty(t, o.OwnershipMigration.CoOwners)
+		}
+	}
+	assert.True(t, migrated)
+
+	o := assertConfigMapExists(t, k, p.TestSlug(), "cm")
+	assert.Nil(t, o.GetK8sAnnotation("kubectl.kubernetes.io/last-applied-configuration"))
+	assertNestedFieldEquals(t, o, "v1-new", "data", "d1")
+	// d2 was owned by kubectl, which means that kluctl owns it now and thus removes it as it's not part of the project
+	_, found, _ := o.GetNestedField("data", "d2")
+	assert.False(t, found)
+	// d3 is owned by another manager
+	assertNestedFieldEquals(t, o, "v3", "data", "d3")
+	for _, mf := range o.GetK8sManagedFields() {
+		manager, _, _ := mf.GetNestedString("manager")
+		assert.NotEqual(t, "kubectl-client-side-apply", manager)
+	}
+
+	r, _ = p.KluctlMustCommandResult(t, "diff", "-t", "test", "-oyaml")
+	for _, o := range r.Objects {
+		assert.Empty(t, o.Changes, o.Ref.String())
+	}
+}
@@ -0,0 +1,50 @@
+package e2e
+
+import (
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+	addConfigMapDeployment(p, "cm", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	p.UpdateDeploymentYaml(".", func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField([]any{"tag1"}, "tagz")
+		return nil
+	})
+	p.UpdateKluctlYaml(func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField("x", "discriminatr")
+		return nil
+	})
+
+	_, _, err := p.Kluctl(t, "deploy", "--yes", "-t", "test")
+	assert.ErrorContains(t, err, `.kluctl.yml:`)
+	assert.ErrorContains(t, err, `unknown field "discriminatr", did you mean "discriminator"?`)
+
+	r, _ := p.KluctlMustCommandResult(t, "deploy", "--yes", "-t", "test", "--lenient", "-oyaml")
+	assert.Empty(t, r.Errors)
+	var messages []string
+	for _, w := range r.Warnings {
+		messages = append(messages, w.Message)
+	}
+	if assert.Len(t, messages, 2) {
+		assert.Contains(t, messages[0], `.kluctl.yml:`)
+		assert.Contains(t, messages[0], `unknown field "discriminatr", did you mean "discriminator"?`)
+		assert.Contains(t, messages[1], `deployment.yml:`)
+		assert.Contains(t, messages[1], `unknown field "tagz", did you mean "tags"?`)
+	}
+	assertConfigMapExists(t, k, p.TestSlug(), "cm")
+}
@@ -3,10 +3,12 @@ package e2e
 import (
 	"context"
 	"fmt"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
 	"github.com/kluctl/kluctl/lib/yaml"
 	test_utils "github.com/kluctl/kluctl/v2/e2e/test-utils"
 	"github.com/kluctl/kluctl/v2/e2e/test_project"
 	"github.com/kluctl/kluctl/v2/e2e/test_resources"
+	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
@@ -298,3 +300,91 @@ func TestValidateWithoutPermissions(t *testing.T) {
 		{Group: "stable.example.com", Resource: "crontabstatuses/status"},
 	})
 }
+
+func getLatestCommandResultId(t *testing.T, k *test_utils.EnvTestCluster, p *test_project.TestProject) string {
+	rs, err := results.NewResultStoreSecrets(context.Background(), k.RESTConfig(), k.Client, false, "kluctl-results", 0, 0)
+	assert.NoError(t, err)
+	summaries, err := rs.ListCommandResultSummaries(results.ListResultSummariesOptions{
+		ProjectFilter: &gittypes.ProjectKey{
+			RepoKey: gittypes.ParseGitUrlMust(p.GitUrl()).RepoKey(),
+		},
+	})
+	assert.NoError(t, err)
+	if !assert.NotEmpty(t, summaries) {
+		t.FailNow()
+	}
+	return summaries[0].Id
+}
+
+func TestValidateStoredResult(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := prepareValidateTest(t, k, nil)
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	resultId := getLatestCommandResultId(t, k, p)
+
+	// the project is not needed at all
+	validateArgs := []string{"validate", "--result", resultId, "--context", k.Context, "--project-dir", t.TempDir()}
+
+	stdout, _, err := p.Kluctl(t, validateArgs...)
+	assert.ErrorContains(t, err, "Validation failed")
+	assert.Contains(t, stdout, fmt.Sprintf("%s/Deployment/d1: readyReplicas field not in status or empty", p.TestSlug()))
+
+	readyDeployment := buildDeployment("d1", p.TestSlug(), true, nil)
+	_, err = k.DynamicClient.Resource(appsv1.SchemeGroupVersion.WithResource("deployments")).Namespace(p.TestSlug()).
+		Patch(context.Background(), "d1", types.ApplyPatchType, []byte(yaml.WriteJsonStringMust(readyDeployment)), metav1.PatchOptions{
+			FieldManager: "test",
+		}, "status")
+	assert.NoError(t, err)
+
+	stdout, _, err = p.Kluctl(t, validateArgs...)
+	assert.NoError(t, err)
+	assert.NotContains(t, stdout, "readyReplicas field not in status or empty")
+
+	err = k.DynamicClient.Resource(appsv1.SchemeGroupVersion.WithResource("deployments")).Namespace(p.TestSlug()).
+		Delete(context.Background(), "d1", metav1.DeleteOptions{})
+	assert.NoError(t, err)
+
+	stdout, _, err = p.Kluctl(t, validateArgs...)
+	assert.NoError(t, err)
+	assert.Contains(t, stdout, fmt.Sprintf("object recorded in command result %s does not exist anymore", resultId))
+}
+
+func TestValidateStoredResultUnknownId(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+
+	_, _, err := p.Kluctl(t, "validate", "--result", "00000000-0000-0000-0000-000000000000", "--context", k.Context)
+	assert.ErrorContains(t, err, "command result with id 00000000-0000-0000-0000-000000000000 not found")
+}
+
+func TestValidateStoredResultOtherCluster(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := prepareValidateTest(t, k, nil)
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	resultId := getLatestCommandResultId(t, k, p)
+
+	rs, err := results.NewResultStoreSecrets(context.Background(), k.RESTConfig(), k.Client, false, "kluctl-results", 0, 0)
+	assert.NoError(t, err)
+	cr, err := rs.GetCommandResult(results.GetCommandResultOptions{Id: resultId})
+	assert.NoError(t, err)
+
+	// copy the result into the result store of another cluster, e.g. as done by 'kluctl results import'
+	resultsNs := p.TestSlug() + "-results"
+	rs2, err := results.NewResultStoreSecrets(context.Background(), defaultCluster2.RESTConfig(), defaultCluster2.Client, true, resultsNs, 5, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, rs2.WriteCommandResult(cr))
+
+	_, _, err = p.Kluctl(t, "validate", "--result", resultId, "--context", defaultCluster2.Context, "--command-result-namespace", resultsNs)
+	assert.ErrorContains(t, err, fmt.Sprintf("command result %s was recorded on cluster %s", resultId, cr.ClusterInfo.ClusterId))
+}
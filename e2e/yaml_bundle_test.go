@@ -0,0 +1,101 @@
+package e2e
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+const testYamlBundle = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: bundle-cm1
+data:
+  a: "{{ 1 + 1 }}"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: bundle-cm2
+`
+
+func TestYamlBundle(t *testing.T) {
+	t.Parallel()
+
+	p := test_project.NewTestProject(t)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testYamlBundle))
+	}))
+	defer s.Close()
+
+	h := sha256.Sum256([]byte(testYamlBundle))
+	digest := hex.EncodeToString(h[:])
+
+	p.UpdateTarget("test", nil)
+	addConfigMapDeployment(p, "bundle", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	p.AddKustomizeResources("bundle", []test_project.KustomizeResource{
+		{Name: "yaml-bundle-rendered.yaml"},
+	})
+	p.UpdateKustomizeDeployment("bundle", func(o *uo.UnstructuredObject, wt *git.Worktree) error {
+		_ = o.SetNestedField(p.TestSlug(), "namespace")
+		return nil
+	})
+
+	setBundle := func(sha256 string, template bool) {
+		p.UpdateDeploymentItems(".", func(items []*uo.UnstructuredObject) []*uo.UnstructuredObject {
+			_ = items[0].SetNestedField(map[string]any{
+				"urls": []any{
+					map[string]any{
+						"url":    s.URL + "/install.yaml",
+						"sha256": sha256,
+					},
+				},
+				"template": template,
+			}, "yamlBundle")
+			return items
+		})
+	}
+
+	getNames := func(stdout string) map[string]map[string]any {
+		y, err := yaml.ReadYamlAllString(stdout)
+		assert.NoError(t, err)
+		ret := map[string]map[string]any{}
+		for _, x := range y {
+			o := uo.FromMap(x.(map[string]any))
+			data, _, _ := o.GetNestedField("data")
+			m, _ := data.(map[string]any)
+			ret[o.GetK8sName()] = m
+			assert.Equal(t, p.TestSlug(), o.GetK8sNamespace())
+		}
+		return ret
+	}
+
+	setBundle(digest, false)
+	stdout, _ := p.KluctlMust(t, "render", "-t", "test", "--print-all")
+	assert.Equal(t, map[string]map[string]any{
+		"cm":         nil,
+		"bundle-cm1": {"a": "{{ 1 + 1 }}"},
+		"bundle-cm2": nil,
+	}, getNames(stdout))
+
+	setBundle(digest, true)
+	stdout, _ = p.KluctlMust(t, "render", "-t", "test", "--print-all")
+	assert.Equal(t, map[string]any{"a": "2"}, getNames(stdout)["bundle-cm1"])
+
+	wrongDigest := hex.EncodeToString(make([]byte, 32))
+	setBundle(wrongDigest, false)
+	_, _, err := p.Kluctl(t, "render", "-t", "test", "--print-all")
+	assert.ErrorContains(t, err, "checksum mismatch for yaml bundle "+s.URL+"/install.yaml: expected sha256 "+wrongDigest+", got sha256 "+digest)
+}
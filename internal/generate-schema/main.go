@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const schemaBaseUrl = "https://raw.githubusercontent.com/kluctl/kluctl/main/schemas/"
+
+type schema map[string]any
+
+var stringSchema = schema{"type": "string"}
+
+// managedTypes contains types that implement custom unmarshalling and thus can't be described via reflection
+var managedTypes = map[reflect.Type]schema{
+	reflect.TypeOf(gittypes.GitUrl{}):  stringSchema,
+	reflect.TypeOf(gittypes.RepoKey{}): stringSchema,
+	reflect.TypeOf(types.YamlUrl{}):    stringSchema,
+	reflect.TypeOf(types.SingleStringOrList{}): {
+		"oneOf": []any{stringSchema, schema{"type": "array", "items": stringSchema}},
+	},
+	reflect.TypeOf(uo.UnstructuredObject{}): {"type": "object"},
+	reflect.TypeOf(apiextensionsv1.JSON{}):  {},
+	reflect.TypeOf(metav1.Duration{}):       stringSchema,
+}
+
+// stringOrObjectTypes contains types that can be specified either as a simple string or as the full object
+var stringOrObjectTypes = map[reflect.Type]bool{
+	reflect.TypeOf(types.GitProject{}): true,
+}
+
+// skippedFields contains fields that are only used internally and that must not be specified by users
+var skippedFields = map[reflect.Type][]string{
+	reflect.TypeOf(types.DeploymentItemConfig{}): {"renderedHelmChartConfig", "renderedObjects", "renderedInclude"},
+}
+
+type generator struct {
+	defs  map[string]schema
+	names map[reflect.Type]string
+}
+
+func (g *generator) defName(t reflect.Type) string {
+	if n, ok := g.names[t]; ok {
+		return n
+	}
+	n := t.Name()
+	if _, ok := g.defs[n]; ok {
+		n = filepath.Base(t.PkgPath()) + "." + n
+	}
+	g.names[t] = n
+	return n
+}
+
+func (g *generator) schemaForType(t reflect.Type) schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if s, ok := managedTypes[t]; ok {
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return stringSchema
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return schema{"type": "array", "items": g.schemaForType(t.Elem())}
+	case reflect.Map:
+		return schema{"type": "object", "additionalProperties": g.schemaForType(t.Elem())}
+	case reflect.Struct:
+		ref := schema{"$ref": "#/$defs/" + g.defStruct(t)}
+		if stringOrObjectTypes[t] {
+			return schema{"oneOf": []any{stringSchema, ref}}
+		}
+		return ref
+	default:
+		return schema{}
+	}
+}
+
+func (g *generator) defStruct(t reflect.Type) string {
+	if n, ok := g.names[t]; ok {
+		return n
+	}
+	n := g.defName(t)
+	s := schema{
+		"type":                 "object",
+		"additionalProperties": false,
+	}
+	// register before visiting fields so that recursive types terminate
+	g.defs[n] = s
+
+	properties := schema{}
+	var required []string
+	g.addStructFields(t, properties, &required)
+	s["properties"] = properties
+	if len(required) != 0 {
+		slices.Sort(required)
+		s["required"] = required
+	}
+	return n
+}
+
+func (g *generator) addStructFields(t reflect.Type, properties schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if name == "" && (f.Anonymous || strings.Contains(opts, "inline")) && ft.Kind() == reflect.Struct {
+			g.addStructFields(ft, properties, required)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if slices.Contains(skippedFields[t], name) {
+			continue
+		}
+		properties[name] = g.schemaForType(f.Type)
+		if slices.Contains(strings.Split(f.Tag.Get("validate"), ","), "required") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func generateSchema(fileName string, title string, o any) error {
+	g := &generator{
+		defs:  map[string]schema{},
+		names: map[reflect.Type]string{},
+	}
+	root := g.defStruct(reflect.TypeOf(o))
+
+	s := schema{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     schemaBaseUrl + fileName,
+		"title":   title,
+		"$ref":    "#/$defs/" + root,
+		"$defs":   g.defs,
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	return os.WriteFile(filepath.Join("../schemas", fileName), b, 0o644)
+}
+
+func main() {
+	err := os.MkdirAll("../schemas", 0o755)
+	if err != nil {
+		panic(err)
+	}
+
+	schemas := []struct {
+		fileName string
+		title    string
+		o        any
+	}{
+		{"kluctl-project.schema.json", "kluctl project (.kluctl.yaml)", types.KluctlProject{}},
+		{"deployment.schema.json", "kluctl deployment project (deployment.yaml)", types.DeploymentProjectConfig{}},
+		{"kluctl-library.schema.json", "kluctl library project (.kluctl-library.yaml)", types.KluctlLibraryProject{}},
+	}
+	for _, x := range schemas {
+		err = generateSchema(x.fileName, x.title, x.o)
+		if err != nil {
+			panic(fmt.Sprintf("failed to generate %s: %v", x.fileName, err))
+		}
+	}
+}
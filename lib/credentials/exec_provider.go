@@ -0,0 +1,106 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// ExecCredentials is what credential exec plugins are expected to print to stdout (as JSON). Printing nothing or an
+// empty object means that the plugin has no credentials for the requested host.
+type ExecCredentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+
+	IdentityToken string `json:"identityToken,omitempty"`
+	SshKey        string `json:"sshKey,omitempty"`
+}
+
+func (c *ExecCredentials) IsEmpty() bool {
+	return *c == ExecCredentials{}
+}
+
+type execCacheEntry struct {
+	creds *ExecCredentials
+	err   error
+}
+
+// ExecProvider invokes an external executable to retrieve credentials for a host. The executable is invoked with
+// the credentials type (e.g. "git" or "oci"), the host and the path as arguments. The same information is also
+// passed via the KLUCTL_CREDENTIALS_TYPE, KLUCTL_CREDENTIALS_HOST and KLUCTL_CREDENTIALS_PATH environment variables.
+// Results are cached per host for the lifetime of the provider.
+type ExecProvider struct {
+	Command string
+
+	mutex sync.Mutex
+	cache map[string]*execCacheEntry
+}
+
+func NewExecProvider(command string) *ExecProvider {
+	return &ExecProvider{
+		Command: command,
+		cache:   map[string]*execCacheEntry{},
+	}
+}
+
+// GetCredentials returns the credentials for the given host or nil if the plugin has no credentials for it.
+// Returned errors identify the host, but never contain the output of the plugin.
+func (p *ExecProvider) GetCredentials(ctx context.Context, credType string, host string, path string) (*ExecCredentials, error) {
+	cacheKey := credType + "|" + host
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if e, ok := p.cache[cacheKey]; ok {
+		return e.creds, e.err
+	}
+
+	creds, err := p.run(ctx, credType, host, path)
+	p.cache[cacheKey] = &execCacheEntry{creds: creds, err: err}
+	return creds, err
+}
+
+func (p *ExecProvider) run(ctx context.Context, credType string, host string, path string) (*ExecCredentials, error) {
+	cmd := exec.CommandContext(ctx, p.Command, credType, host, path)
+	cmd.Env = append(os.Environ(),
+		"KLUCTL_CREDENTIALS_TYPE="+credType,
+		"KLUCTL_CREDENTIALS_HOST="+host,
+		"KLUCTL_CREDENTIALS_PATH="+path,
+	)
+
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("credentials provider %s failed for %s host %s: %w: %s", p.Command, credType, host, err, msg)
+		}
+		return nil, fmt.Errorf("credentials provider %s failed for %s host %s: %w", p.Command, credType, host, err)
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var creds ExecCredentials
+	err = json.Unmarshal(out, &creds)
+	if err != nil {
+		// don't include the error or output, as it might contain parts of the secret
+		return nil, fmt.Errorf("credentials provider %s returned invalid output for %s host %s, expected a JSON object", p.Command, credType, host)
+	}
+	if creds.IsEmpty() {
+		return nil, nil
+	}
+	return &creds, nil
+}
@@ -0,0 +1,66 @@
+package credentials
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeScript(t *testing.T, content string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec provider tests require a posix shell")
+	}
+	dir := t.TempDir()
+	p := filepath.Join(dir, "provider.sh")
+	err := os.WriteFile(p, []byte("#!/bin/sh\n"+content), 0o700)
+	assert.NoError(t, err)
+	return p
+}
+
+func TestExecProvider(t *testing.T) {
+	countFile := filepath.Join(t.TempDir(), "count")
+	p := writeScript(t, `echo x >> `+countFile+`
+if [ "$2" = "example.com" ]; then
+  echo '{"username": "user", "password": "'$KLUCTL_CREDENTIALS_TYPE-$3'"}'
+fi
+`)
+
+	ep := NewExecProvider(p)
+
+	c, err := ep.GetCredentials(context.Background(), "git", "example.com", "org/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, &ExecCredentials{Username: "user", Password: "git-org/repo"}, c)
+
+	// cached per host
+	c, err = ep.GetCredentials(context.Background(), "git", "example.com", "org/other")
+	assert.NoError(t, err)
+	assert.Equal(t, &ExecCredentials{Username: "user", Password: "git-org/repo"}, c)
+
+	c, err = ep.GetCredentials(context.Background(), "git", "other.com", "org/repo")
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+
+	b, err := os.ReadFile(countFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(b), "x"))
+}
+
+func TestExecProviderErrors(t *testing.T) {
+	p := writeScript(t, `echo "vault is sealed" >&2
+exit 1
+`)
+	_, err := NewExecProvider(p).GetCredentials(context.Background(), "oci", "ghcr.io", "org/repo")
+	assert.ErrorContains(t, err, "ghcr.io")
+	assert.ErrorContains(t, err, "vault is sealed")
+
+	p = writeScript(t, `echo 'password: my-secret-value'
+`)
+	_, err = NewExecProvider(p).GetCredentials(context.Background(), "git", "example.com", "org/repo")
+	assert.ErrorContains(t, err, "example.com")
+	assert.NotContains(t, err.Error(), "my-secret-value")
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/lib/credentials"
+	"github.com/kluctl/kluctl/lib/git/messages"
+	"github.com/kluctl/kluctl/lib/git/types"
+	"strings"
+)
+
+// GitExecAuthProvider retrieves credentials from an external executable, see credentials.ExecProvider for details.
+type GitExecAuthProvider struct {
+	MessageCallbacks messages.MessageCallbacks
+
+	Exec *credentials.ExecProvider
+}
+
+func (a *GitExecAuthProvider) BuildAuth(ctx context.Context, gitUrlIn types.GitUrl) (AuthMethodAndCA, error) {
+	gitUrl := gitUrlIn.Normalize()
+
+	a.MessageCallbacks.Trace("GitExecAuthProvider: BuildAuth for %s", gitUrl.String())
+
+	creds, err := a.Exec.GetCredentials(ctx, "git", gitUrl.Host, strings.TrimPrefix(gitUrl.Path, "/"))
+	if err != nil {
+		return AuthMethodAndCA{}, err
+	}
+	if creds == nil {
+		a.MessageCallbacks.Trace("GitExecAuthProvider: no credentials returned")
+		return AuthMethodAndCA{}, nil
+	}
+
+	e := AuthEntry{
+		Host:     gitUrl.Host,
+		Username: creds.Username,
+		Password: creds.Password,
+	}
+	if e.Password == "" {
+		e.Password = creds.Token
+	}
+	if creds.SshKey != "" {
+		e.SshKey = []byte(creds.SshKey)
+	}
+	if e.Username == "" {
+		if gitUrl.IsSsh() || e.Password == "" {
+			e.Username = "*"
+		} else {
+			// most git providers accept any non-empty username when a token is used
+			e.Username = "token"
+		}
+	}
+
+	la := ListAuthProvider{MessageCallbacks: a.MessageCallbacks}
+	la.AddEntry(e)
+	return la.BuildAuth(ctx, gitUrlIn)
+}
@@ -89,6 +89,11 @@ func (g *MirroredGitRepo) Url() types.GitUrl {
 	return g.url
 }
 
+// MirrorDir returns the directory that contains the mirrored repository
+func (g *MirroredGitRepo) MirrorDir() string {
+	return g.mirrorDir
+}
+
 func (g *MirroredGitRepo) HasUpdated() bool {
 	return g.hasUpdated
 }
@@ -15,6 +15,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 var minimumPythonVersion = semver.MustParse("3.10.0")
@@ -31,6 +32,8 @@ type Jinja2 struct {
 	globCache   map[string]interface{}
 	mutex       sync.Mutex
 
+	renderedTemplates atomic.Int64
+
 	defaultOptions jinja2Options
 }
 
@@ -157,7 +160,13 @@ func (j *Jinja2) Cleanup() {
 	}
 }
 
+// RenderedTemplates returns the number of templates (strings and files) rendered so far
+func (j *Jinja2) RenderedTemplates() int64 {
+	return j.renderedTemplates.Load()
+}
+
 func (j *Jinja2) RenderStrings(jobs []*RenderJob, opts ...Jinja2Opt) error {
+	j.renderedTemplates.Add(int64(len(jobs)))
 	pj := <-j.pj
 	defer func() { j.pj <- pj }()
 	return pj.renderHelper(jobs, true, opts)
@@ -178,6 +187,7 @@ func (j *Jinja2) RenderString(template string, opts ...Jinja2Opt) (string, error
 }
 
 func (j *Jinja2) RenderFiles(jobs []*RenderJob, opts ...Jinja2Opt) error {
+	j.renderedTemplates.Add(int64(len(jobs)))
 	pj := <-j.pj
 	defer func() { j.pj <- pj }()
 	return pj.renderHelper(jobs, false, opts)
@@ -0,0 +1,50 @@
+package status
+
+// quietStatusHandler forwards only errors and prompts to the wrapped handler. Progress lines, infos and warnings
+// are dropped.
+type quietStatusHandler struct {
+	sh StatusHandler
+}
+
+func NewQuietStatusHandler(sh StatusHandler) StatusHandler {
+	return &quietStatusHandler{
+		sh: sh,
+	}
+}
+
+func (s *quietStatusHandler) isPassed(level Level) bool {
+	return level == LevelError || level == LevelPrompt
+}
+
+func (s *quietStatusHandler) IsTraceEnabled() bool {
+	return false
+}
+
+func (s *quietStatusHandler) Stop() {
+	s.sh.Stop()
+}
+
+func (s *quietStatusHandler) Flush() {
+	s.sh.Flush()
+}
+
+func (s *quietStatusHandler) StartStatus(level Level, total int, message string) StatusLine {
+	if !s.isPassed(level) {
+		return &NoopStatusLine{}
+	}
+	return s.sh.StartStatus(level, total, message)
+}
+
+func (s *quietStatusHandler) Message(level Level, message string) {
+	if !s.isPassed(level) {
+		return
+	}
+	s.sh.Message(level, message)
+}
+
+func (s *quietStatusHandler) MessageFallback(level Level, message string) {
+	if !s.isPassed(level) {
+		return
+	}
+	s.sh.MessageFallback(level, message)
+}
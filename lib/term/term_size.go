@@ -20,3 +20,17 @@ func GetWidth() int {
 	}
 	return w
 }
+
+func GetHeight() int {
+	if c, ok := os.LookupEnv("LINES"); ok {
+		th, err := strconv.ParseInt(c, 10, 32)
+		if err == nil {
+			return int(th)
+		}
+	}
+	_, h, err := GetSize(int(origStdout.Fd()))
+	if err != nil || h == 0 {
+		return 24
+	}
+	return h
+}
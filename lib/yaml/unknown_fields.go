@@ -0,0 +1,251 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// UnknownField describes a field that was found while decoding YAML, but that has no corresponding field in the
+// Go type that the YAML is decoded into.
+type UnknownField struct {
+	File   string
+	Line   int
+	Column int
+
+	// Path is the full path to the unknown field, e.g. "deployments[0].barriers"
+	Path string
+	// Suggestion is the name of a similarly named known field, if one exists
+	Suggestion string
+}
+
+func (f UnknownField) String() string {
+	var loc string
+	if f.File != "" {
+		loc = fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+	} else {
+		loc = fmt.Sprintf("line %d, column %d", f.Line, f.Column)
+	}
+	s := fmt.Sprintf("%s: unknown field \"%s\"", loc, f.Path)
+	if f.Suggestion != "" {
+		s += fmt.Sprintf(", did you mean \"%s\"?", f.Suggestion)
+	}
+	return s
+}
+
+// UnknownFieldsError is returned when strict decoding fails due to unknown fields
+type UnknownFieldsError struct {
+	Fields []UnknownField
+}
+
+func (e *UnknownFieldsError) Error() string {
+	if len(e.Fields) == 1 {
+		return e.Fields[0].String()
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("found %d unknown fields:", len(e.Fields)))
+	for _, f := range e.Fields {
+		sb.WriteString("\n  ")
+		sb.WriteString(f.String())
+	}
+	return sb.String()
+}
+
+// FindUnknownFields parses the given YAML and returns all fields that are not known to the Go type of o
+func FindUnknownFields(b []byte, o any) ([]UnknownField, error) {
+	var n yaml.Node
+	err := yaml.Unmarshal(b, &n)
+	if err != nil {
+		return nil, err
+	}
+	var ret []UnknownField
+	findUnknownFields(&n, reflect.TypeOf(o), "", false, &ret)
+	return ret, nil
+}
+
+// removeUnknownFields parses the given YAML, removes all fields that are not known to the Go type of o and returns
+// the resulting YAML together with the removed fields
+func removeUnknownFields(b []byte, o any) ([]byte, []UnknownField, error) {
+	var n yaml.Node
+	err := yaml.Unmarshal(b, &n)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ret []UnknownField
+	findUnknownFields(&n, reflect.TypeOf(o), "", true, &ret)
+	if len(ret) == 0 {
+		return b, nil, nil
+	}
+	b, err = yaml.Marshal(&n)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, ret, nil
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+type knownField struct {
+	name string
+	t    reflect.Type
+}
+
+// getKnownFields returns all fields that are decoded from JSON, including the fields of embedded structs
+func getKnownFields(t reflect.Type) []knownField {
+	var ret []knownField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if name == "" && (f.Anonymous || strings.Contains(opts, "inline")) && ft.Kind() == reflect.Struct {
+			ret = append(ret, getKnownFields(ft)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		ret = append(ret, knownField{name: name, t: f.Type})
+	}
+	return ret
+}
+
+func findUnknownFields(n *yaml.Node, t reflect.Type, path string, remove bool, ret *[]UnknownField) {
+	if t == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			findUnknownFields(c, t, path, remove, ret)
+		}
+		return
+	case yaml.AliasNode:
+		if n.Alias != nil && !remove {
+			findUnknownFields(n.Alias, t, path, remove, ret)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		known := getKnownFields(t)
+		if len(known) == 0 && reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+			// custom unmarshalling into an opaque type (e.g. arbitrary objects)
+			return
+		}
+		var newContent []*yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			kf := findKnownField(known, k.Value)
+			if kf == nil {
+				*ret = append(*ret, UnknownField{
+					Line:       k.Line,
+					Column:     k.Column,
+					Path:       joinFieldPath(path, k.Value),
+					Suggestion: findSuggestion(k.Value, known),
+				})
+				continue
+			}
+			findUnknownFields(v, kf.t, joinFieldPath(path, k.Value), remove, ret)
+			newContent = append(newContent, k, v)
+		}
+		if remove {
+			n.Content = newContent
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			findUnknownFields(n.Content[i+1], t.Elem(), joinFieldPath(path, n.Content[i].Value), remove, ret)
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, c := range n.Content {
+			findUnknownFields(c, t.Elem(), path+"["+strconv.Itoa(i)+"]", remove, ret)
+		}
+	}
+}
+
+// findKnownField finds the field with the given name, falling back to a case-insensitive match in the same way
+// encoding/json does
+func findKnownField(known []knownField, name string) *knownField {
+	for i := range known {
+		if known[i].name == name {
+			return &known[i]
+		}
+	}
+	for i := range known {
+		if strings.EqualFold(known[i].name, name) {
+			return &known[i]
+		}
+	}
+	return nil
+}
+
+func joinFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// findSuggestion returns the known field that is most similar to name, or an empty string if no field is similar
+// enough
+func findSuggestion(name string, known []knownField) string {
+	best := ""
+	bestDist := -1
+	for _, kf := range known {
+		d := levenshtein(strings.ToLower(name), strings.ToLower(kf.name))
+		if d > 2 || d >= len(kf.name) {
+			continue
+		}
+		if bestDist == -1 || d < bestDist {
+			best = kf.name
+			bestDist = d
+		}
+	}
+	return best
+}
+
+func levenshtein(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,116 @@
+package yaml
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type opaqueYamlValue struct {
+	v any
+}
+
+func (o *opaqueYamlValue) UnmarshalJSON(b []byte) error {
+	return nil
+}
+
+type EmbeddedYamlConfig struct {
+	Name string `json:"name"`
+}
+
+type ItemYamlConfig struct {
+	EmbeddedYamlConfig `json:",inline"`
+	Path               string   `json:"path,omitempty"`
+	Barrier            bool     `json:"barrier,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
+}
+
+type NestedYamlConfig struct {
+	Items  []*ItemYamlConfig           `json:"items,omitempty"`
+	ByName map[string]ItemYamlConfig   `json:"byName,omitempty"`
+	Opaque *opaqueYamlValue            `json:"opaque,omitempty"`
+	Any    map[string]any              `json:"any,omitempty"`
+	Extra  map[string]*opaqueYamlValue `json:"extra,omitempty"`
+}
+
+const unknownFieldsTestYaml = `
+items:
+- name: a
+  barriers: true
+- path: p
+  Barrier: true
+  tag: x
+byName:
+  x:
+    nmae: b
+opaque:
+  anything: goes
+any:
+  anything: goes
+unknown: 1
+`
+
+func TestFindUnknownFields(t *testing.T) {
+	var c NestedYamlConfig
+	uf, err := FindUnknownFields([]byte(unknownFieldsTestYaml), &c)
+	assert.NoError(t, err)
+	assert.Equal(t, []UnknownField{
+		{Line: 4, Column: 3, Path: "items[0].barriers", Suggestion: "barrier"},
+		{Line: 7, Column: 3, Path: "items[1].tag", Suggestion: "tags"},
+		{Line: 10, Column: 5, Path: "byName.x.nmae", Suggestion: "name"},
+		{Line: 15, Column: 1, Path: "unknown"},
+	}, uf)
+}
+
+func TestReadYamlUnknownFields(t *testing.T) {
+	var c NestedYamlConfig
+	err := ReadYamlString(unknownFieldsTestYaml, &c)
+	var ufe *UnknownFieldsError
+	if assert.True(t, errors.As(err, &ufe)) {
+		assert.Len(t, ufe.Fields, 4)
+	}
+	assert.Contains(t, err.Error(), `line 4, column 3: unknown field "items[0].barriers", did you mean "barrier"?`)
+
+	c = NestedYamlConfig{}
+	_, err = ReadYamlStringWithOptions("items:\n- barriers: true\n", &c, ReadOptions{FileName: "deployment.yaml"})
+	assert.EqualError(t, err, `deployment.yaml:2:3: unknown field "items[0].barriers", did you mean "barrier"?`)
+}
+
+func TestReadYamlLenient(t *testing.T) {
+	var c NestedYamlConfig
+	uf, err := ReadYamlStringWithOptions(unknownFieldsTestYaml, &c, ReadOptions{FileName: "f.yaml", Lenient: true})
+	assert.NoError(t, err)
+	assert.Len(t, uf, 4)
+	for _, f := range uf {
+		assert.Equal(t, "f.yaml", f.File)
+	}
+	assert.Len(t, c.Items, 2)
+	assert.Equal(t, "a", c.Items[0].Name)
+	assert.Equal(t, "p", c.Items[1].Path)
+	assert.True(t, c.Items[1].Barrier)
+	assert.Equal(t, "goes", c.Any["anything"])
+
+	// no warnings for valid yaml
+	c = NestedYamlConfig{}
+	uf, err = ReadYamlStringWithOptions("items:\n- path: p\n", &c, ReadOptions{Lenient: true})
+	assert.NoError(t, err)
+	assert.Nil(t, uf)
+}
+
+func TestFindSuggestion(t *testing.T) {
+	known := []knownField{{name: "barrier"}, {name: "path"}, {name: "vars"}}
+	for _, tc := range []struct {
+		name     string
+		expected string
+	}{
+		{"barriers", "barrier"},
+		{"var", "vars"},
+		{"pth", "path"},
+		{"x", ""},
+		{"completelyDifferent", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, findSuggestion(tc.name, known))
+		})
+	}
+}
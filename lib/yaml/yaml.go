@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
@@ -44,19 +45,79 @@ func ReadYamlBytes(b []byte, o interface{}) error {
 }
 
 func ReadYamlStream(r io.Reader, o interface{}) error {
+	_, err := ReadYamlStreamWithOptions(r, o, ReadOptions{})
+	return err
+}
+
+// ReadOptions controls the behavior of the ReadYaml*WithOptions functions
+type ReadOptions struct {
+	// FileName is used in error messages and warnings about unknown fields
+	FileName string
+	// Lenient causes unknown fields to be ignored. The ignored fields are returned instead of failing.
+	Lenient bool
+}
+
+func ReadYamlFileWithOptions(p string, o interface{}, opts ReadOptions) ([]UnknownField, error) {
+	r, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("opening %v failed: %w", p, err)
+	}
+	defer r.Close()
+
+	if opts.FileName == "" {
+		opts.FileName = p
+	}
+	unknownFields, err := ReadYamlStreamWithOptions(r, o, opts)
+	if err != nil {
+		var ufe *UnknownFieldsError
+		if errors.As(err, &ufe) {
+			// already contains the file name
+			return nil, err
+		}
+		return nil, fmt.Errorf("unmarshalling %v failed: %w", p, err)
+	}
+	return unknownFields, nil
+}
+
+func ReadYamlStringWithOptions(s string, o interface{}, opts ReadOptions) ([]UnknownField, error) {
+	return ReadYamlStreamWithOptions(strings.NewReader(s), o, opts)
+}
+
+func ReadYamlStreamWithOptions(r io.Reader, o interface{}, opts ReadOptions) ([]UnknownField, error) {
 	r = newUnicodeReader(r)
 
 	b, err := io.ReadAll(r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var unknownFields []UnknownField
 	err = yaml.UnmarshalStrict(b, o)
 	if err != nil {
-		return err
+		// the error returned by UnmarshalStrict has no location information and only mentions the first unknown
+		// field, so we try to find all unknown fields ourselves
+		b2, uf, err2 := removeUnknownFields(b, o)
+		if err2 != nil || len(uf) == 0 {
+			return nil, err
+		}
+		for i := range uf {
+			uf[i].File = opts.FileName
+		}
+		if !opts.Lenient {
+			return nil, &UnknownFieldsError{Fields: uf}
+		}
+		err = yaml.UnmarshalStrict(b2, o)
+		if err != nil {
+			return nil, err
+		}
+		unknownFields = uf
 	}
 
-	return ValidateStructs(o)
+	err = ValidateStructs(o)
+	if err != nil {
+		return nil, err
+	}
+	return unknownFields, nil
 }
 
 func ReadYamlAllFile(p string) ([]interface{}, error) {
@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -12,8 +13,21 @@ type GetSecretValueInterface interface {
 	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
 }
 
+type PutObjectInterface interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3Interface contains all S3 operations required to read and write stored objects
+type S3Interface interface {
+	PutObjectInterface
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
 type AwsClientFactory interface {
 	SecretsManagerClient(ctx context.Context, profile *string, region *string) (GetSecretValueInterface, error)
+	S3Client(ctx context.Context, profile *string, region *string) (S3Interface, error)
 }
 
 type awsClientFactory struct {
@@ -35,6 +49,20 @@ func (a *awsClientFactory) SecretsManagerClient(ctx context.Context, profile *st
 	return secretsmanager.NewFromConfig(cfg), nil
 }
 
+func (a *awsClientFactory) S3Client(ctx context.Context, profile *string, region *string) (S3Interface, error) {
+	var configOpts []func(*config.LoadOptions) error
+
+	if region != nil {
+		configOpts = append(configOpts, config.WithRegion(*region))
+	}
+
+	cfg, err := LoadAwsConfigHelper(ctx, a.client, a.awsConfig, profile, configOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return s3.NewFromConfig(cfg), nil
+}
+
 func NewClientFactory(c client.Client, awsConfig *types.AwsConfig) AwsClientFactory {
 	return &awsClientFactory{
 		client:    c,
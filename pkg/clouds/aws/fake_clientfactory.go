@@ -1,17 +1,28 @@
 package aws
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	arn2 "github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"io"
+	"sort"
+	"strings"
+	"sync"
 )
 
 type FakeAwsClientFactory struct {
 	GetSecretValueInterface
 
 	Secrets map[string]string
+
+	// S3Objects contains all objects written via PutObject, with "bucket/key" as map key
+	S3Objects map[string][]byte
+	s3Mutex   sync.Mutex
 }
 
 func (f *FakeAwsClientFactory) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
@@ -41,6 +52,97 @@ func (f *FakeAwsClientFactory) SecretsManagerClient(ctx context.Context, profile
 	return f, nil
 }
 
+func (f *FakeAwsClientFactory) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	b, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.s3Mutex.Lock()
+	defer f.s3Mutex.Unlock()
+	f.S3Objects[*params.Bucket+"/"+*params.Key] = b
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *FakeAwsClientFactory) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.s3Mutex.Lock()
+	defer f.s3Mutex.Unlock()
+	b, ok := f.S3Objects[*params.Bucket+"/"+*params.Key]
+	if !ok {
+		errMsg := fmt.Sprintf("object %s not found", *params.Key)
+		return nil, &s3types.NoSuchKey{Message: &errMsg}
+	}
+	return &s3.GetObjectOutput{
+		Body: io.NopCloser(bytes.NewReader(b)),
+	}, nil
+}
+
+func (f *FakeAwsClientFactory) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	f.s3Mutex.Lock()
+	defer f.s3Mutex.Unlock()
+	delete(f.S3Objects, *params.Bucket+"/"+*params.Key)
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+// ListObjectsV2 supports Prefix and Delimiter, but returns all results in a single page
+func (f *FakeAwsClientFactory) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.s3Mutex.Lock()
+	defer f.s3Mutex.Unlock()
+
+	bucketPrefix := *params.Bucket + "/"
+	prefix := ""
+	if params.Prefix != nil {
+		prefix = *params.Prefix
+	}
+	delimiter := ""
+	if params.Delimiter != nil {
+		delimiter = *params.Delimiter
+	}
+
+	var keys []string
+	commonPrefixes := map[string]bool{}
+	for k := range f.S3Objects {
+		if !strings.HasPrefix(k, bucketPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(k, bucketPrefix)
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if delimiter != "" {
+			rest := strings.TrimPrefix(key, prefix)
+			if i := strings.Index(rest, delimiter); i != -1 {
+				commonPrefixes[prefix+rest[:i+len(delimiter)]] = true
+				continue
+			}
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ret := &s3.ListObjectsV2Output{}
+	for _, k := range keys {
+		size := int64(len(f.S3Objects[bucketPrefix+k]))
+		ret.Contents = append(ret.Contents, s3types.Object{Key: &k, Size: &size})
+	}
+	var cps []string
+	for cp := range commonPrefixes {
+		cps = append(cps, cp)
+	}
+	sort.Strings(cps)
+	for _, cp := range cps {
+		ret.CommonPrefixes = append(ret.CommonPrefixes, s3types.CommonPrefix{Prefix: &cp})
+	}
+	return ret, nil
+}
+
+func (f *FakeAwsClientFactory) S3Client(ctx context.Context, profile *string, region *string) (S3Interface, error) {
+	return f, nil
+}
+
 func NewFakeClientFactory() *FakeAwsClientFactory {
-	return &FakeAwsClientFactory{}
+	return &FakeAwsClientFactory{
+		S3Objects: map[string][]byte{},
+	}
 }
+
+var _ S3Interface = &FakeAwsClientFactory{}
@@ -0,0 +1,40 @@
+package aws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// NewS3Client creates a S3 client with credentials from the standard AWS environment variables and shared config
+// files. If endpoint is set, requests are sent to the given endpoint with path style addressing, which allows using
+// S3 compatible object storage, e.g. MinIO or Google Cloud Storage via its XML API.
+func NewS3Client(ctx context.Context, region string, endpoint string) (S3Interface, error) {
+	var configOpts []func(*config.LoadOptions) error
+	if region != "" {
+		configOpts = append(configOpts, config.WithRegion(region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, configOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = &endpoint
+			o.UsePathStyle = true
+		}
+	}), nil
+}
+
+// IsS3NotFound returns true if the error was caused by a missing object
+func IsS3NotFound(err error) bool {
+	var nsk *types.NoSuchKey
+	if errors.As(err, &nsk) {
+		return true
+	}
+	var nf *types.NotFound
+	return errors.As(err, &nf)
+}
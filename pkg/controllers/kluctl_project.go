@@ -20,6 +20,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
 	intkeyservice "github.com/kluctl/kluctl/v2/pkg/sops/keyservice"
 	"github.com/kluctl/kluctl/v2/pkg/sourceoverride"
+	types2 "github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/prometheus/client_golang/prometheus"
@@ -27,6 +28,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -41,7 +43,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -66,6 +67,8 @@ type preparedProject struct {
 	projectDir string
 
 	soClients []*sourceoverride.ProxyClientController
+
+	kubeconfigResolver *k8s2.KubeconfigResolver
 }
 
 type preparedTarget struct {
@@ -84,6 +87,7 @@ func prepareProject(ctx context.Context,
 		obj:       obj,
 		startTime: time.Now(),
 	}
+	pp.kubeconfigResolver = k8s2.NewKubeconfigResolver(pp.getKubeconfigSecret)
 	defer func() {
 		if !cleanup {
 			return
@@ -154,34 +158,19 @@ func prepareProject(ctx context.Context,
 		pth := ""
 		if pp.obj.Spec.Source.Git != nil {
 			pth = pp.obj.Spec.Source.Git.Path
-			rpEntry, err := pp.gitRP.GetEntry(pp.obj.Spec.Source.Git.URL)
-			if err != nil {
-				return nil, fmt.Errorf("failed to clone git source: %w", err)
-			}
-
-			pp.repoDir, pp.co, err = rpEntry.GetClonedDir(pp.obj.Spec.Source.Git.Ref)
+			err = pp.cloneGitSource(ctx, gitSecrets, pp.obj.Spec.Source.Git.URL, pp.obj.Spec.Source.Git.Ref)
 			if err != nil {
 				return nil, err
 			}
 		} else if pp.obj.Spec.Source.Oci != nil {
 			pth = pp.obj.Spec.Source.Oci.Path
-			rpEntry, err := pp.ociRP.GetEntry(pp.obj.Spec.Source.Oci.URL)
-			if err != nil {
-				return nil, fmt.Errorf("failed to pull OCI source: %w", err)
-			}
-
-			pp.repoDir, pp.co, err = rpEntry.GetExtractedDir(pp.obj.Spec.Source.Oci.Ref)
+			err = pp.pullOciSource(ctx, ociSecrets, pp.obj.Spec.Source.Oci.URL, pp.obj.Spec.Source.Oci.Ref)
 			if err != nil {
 				return nil, err
 			}
 		} else if pp.obj.Spec.Source.URL != nil {
 			pth = pp.obj.Spec.Source.Path
-			rpEntry, err := pp.gitRP.GetEntry(*pp.obj.Spec.Source.URL)
-			if err != nil {
-				return nil, fmt.Errorf("failed to clone git source: %w", err)
-			}
-
-			pp.repoDir, pp.co, err = rpEntry.GetClonedDir(pp.obj.Spec.Source.Ref)
+			err = pp.cloneGitSource(ctx, gitSecrets, *pp.obj.Spec.Source.URL, pp.obj.Spec.Source.Ref)
 			if err != nil {
 				return nil, err
 			}
@@ -203,6 +192,57 @@ func prepareProject(ctx context.Context,
 	return pp, nil
 }
 
+// sourceCache returns the shared source cache or nil if the project must not use it. Source overrides are local to
+// a single deployment, so sharing sources is disabled in that case.
+func (pp *preparedProject) sourceCache() *SourceCache {
+	if len(pp.soClients) != 0 {
+		return nil
+	}
+	return pp.r.SourceCache
+}
+
+func (pp *preparedProject) cloneGitSource(ctx context.Context, secrets []gitRepoSecrets, url string, ref *gittypes.GitRef) error {
+	credentialsHash, err := pp.r.buildGitRepoCacheKey(secrets)
+	if err != nil {
+		return err
+	}
+	key := SourceCacheKey{
+		Type:            "git",
+		Url:             url,
+		Ref:             ref,
+		CredentialsHash: credentialsHash,
+	}
+	pp.repoDir, pp.co, err = pp.sourceCache().Get(ctx, key, filepath.Join(pp.tmpDir, "source"), func() (string, git.CheckoutInfo, error) {
+		rpEntry, err := pp.gitRP.GetEntry(url)
+		if err != nil {
+			return "", git.CheckoutInfo{}, fmt.Errorf("failed to clone git source: %w", err)
+		}
+		return rpEntry.GetClonedDir(ref)
+	})
+	return err
+}
+
+func (pp *preparedProject) pullOciSource(ctx context.Context, secrets []ociRepoSecrets, url string, ref *types2.OciRef) error {
+	credentialsHash, err := pp.r.buildOciRepoCacheKey(secrets)
+	if err != nil {
+		return err
+	}
+	key := SourceCacheKey{
+		Type:            "oci",
+		Url:             url,
+		Ref:             ref,
+		CredentialsHash: credentialsHash,
+	}
+	pp.repoDir, pp.co, err = pp.sourceCache().Get(ctx, key, filepath.Join(pp.tmpDir, "source"), func() (string, git.CheckoutInfo, error) {
+		rpEntry, err := pp.ociRP.GetEntry(url)
+		if err != nil {
+			return "", git.CheckoutInfo{}, fmt.Errorf("failed to pull OCI source: %w", err)
+		}
+		return rpEntry.GetExtractedDir(ref)
+	})
+	return err
+}
+
 func (pp *preparedProject) cleanup(ctx context.Context) {
 	if pp.gnuPGHome != "" {
 		pp.cleanupGpgAgent(ctx)
@@ -239,70 +279,37 @@ func (pp *preparedProject) newTarget() *preparedTarget {
 	return &pt
 }
 
-func (pt *preparedTarget) restConfigToKubeconfig(restConfig *rest.Config) *api.Config {
-	kubeConfig := api.NewConfig()
-	cluster := api.NewCluster()
-	cluster.Server = restConfig.Host
-	cluster.CertificateAuthority = restConfig.TLSClientConfig.CAFile
-	cluster.CertificateAuthorityData = restConfig.TLSClientConfig.CAData
-	cluster.InsecureSkipTLSVerify = restConfig.TLSClientConfig.Insecure
-	kubeConfig.Clusters["default"] = cluster
-
-	user := api.NewAuthInfo()
-	user.ClientKey = restConfig.KeyFile
-	user.ClientKeyData = restConfig.KeyData
-	user.ClientCertificate = restConfig.CertFile
-	user.ClientCertificateData = restConfig.CertData
-	user.TokenFile = restConfig.BearerTokenFile
-	user.Token = restConfig.BearerToken
-	user.Impersonate = restConfig.Impersonate.UserName
-	user.ImpersonateUID = restConfig.Impersonate.UID
-	user.ImpersonateUserExtra = restConfig.Impersonate.Extra
-	user.ImpersonateGroups = restConfig.Impersonate.Groups
-	user.Username = restConfig.Username
-	user.Password = restConfig.Password
-	user.AuthProvider = restConfig.AuthProvider
-	user.Exec = restConfig.ExecProvider
-	kubeConfig.AuthInfos["default"] = user
-
-	kctx := api.NewContext()
-	kctx.Cluster = "default"
-	kctx.AuthInfo = "default"
-	kubeConfig.Contexts["default"] = kctx
-	kubeConfig.CurrentContext = "default"
-
-	return kubeConfig
-}
-
-func (pt *preparedTarget) getKubeconfigFromSecret(ctx context.Context) ([]byte, error) {
+// getKubeconfigSecret reads kubeconfig secrets for the kubeconfig resolver. Only secrets from the namespace of the
+// KluctlDeployment are allowed, as otherwise the controller would leak secrets across namespaces.
+func (pp *preparedProject) getKubeconfigSecret(ctx context.Context, kubeContext *string, namespace string, name string) (*corev1.Secret, error) {
 	secretName := types.NamespacedName{
-		Namespace: pt.pp.obj.GetNamespace(),
-		Name:      pt.pp.obj.Spec.KubeConfig.SecretRef.Name,
+		Namespace: namespace,
+		Name:      name,
+	}
+	if kubeContext != nil || namespace != pp.obj.GetNamespace() {
+		return nil, fmt.Errorf("KubeConfig secret '%s' must be in the namespace of the KluctlDeployment", secretName.String())
 	}
 
 	var secret corev1.Secret
-	if err := pt.pp.r.Client.Get(ctx, secretName, &secret); err != nil {
+	if err := pp.r.Client.Get(ctx, secretName, &secret); err != nil {
 		return nil, fmt.Errorf("unable to read KubeConfig secret '%s' error: %w", secretName.String(), err)
 	}
+	return &secret, nil
+}
 
-	var kubeConfig []byte
-	switch {
-	case pt.pp.obj.Spec.KubeConfig.SecretRef.Key != "":
-		key := pt.pp.obj.Spec.KubeConfig.SecretRef.Key
-		kubeConfig = secret.Data[key]
-		if kubeConfig == nil {
-			return nil, fmt.Errorf("KubeConfig secret '%s' does not contain a '%s' key with a kubeconfig", secretName, key)
-		}
-	case secret.Data["value"] != nil:
-		kubeConfig = secret.Data["value"]
-	case secret.Data["value.yaml"] != nil:
-		kubeConfig = secret.Data["value.yaml"]
-	default:
-		// User did not specify a key, and the 'value' key was not defined.
-		return nil, fmt.Errorf("KubeConfig secret '%s' does not contain a 'value' key with a kubeconfig", secretName)
+// kubeconfigSource returns the kubeconfig source configured via spec.kubeConfig or nil if the controller's own
+// config should be used. The kubeconfigFrom field of targets is ignored by the controller.
+func (pt *preparedTarget) kubeconfigSource() *types2.KubeconfigSource {
+	if pt.pp.obj.Spec.KubeConfig == nil {
+		return nil
+	}
+	return &types2.KubeconfigSource{
+		Secret: &types2.KubeconfigSecretSource{
+			Namespace: pt.pp.obj.GetNamespace(),
+			Name:      pt.pp.obj.Spec.KubeConfig.SecretRef.Name,
+			Key:       pt.pp.obj.Spec.KubeConfig.SecretRef.Key,
+		},
 	}
-
-	return kubeConfig, nil
 }
 
 func (pt *preparedTarget) setImpersonationConfig(restConfig *rest.Config) {
@@ -319,12 +326,16 @@ func (pt *preparedTarget) setImpersonationConfig(restConfig *rest.Config) {
 func (pt *preparedTarget) buildRestConfig(ctx context.Context) (*rest.Config, error) {
 	var restConfig *rest.Config
 
-	if pt.pp.obj.Spec.KubeConfig != nil {
-		kubeConfig, err := pt.getKubeconfigFromSecret(ctx)
+	if src := pt.kubeconfigSource(); src != nil {
+		var targetName string
+		if pt.pp.obj.Spec.Target != nil {
+			targetName = *pt.pp.obj.Spec.Target
+		}
+		kubeConfig, err := pt.pp.kubeconfigResolver.Resolve(ctx, targetName, src)
 		if err != nil {
 			return nil, err
 		}
-		restConfig, err = clientcmd.RESTConfigFromKubeConfig(kubeConfig)
+		restConfig, _, err = k8s2.ClientConfigFromKubeconfig(kubeConfig, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -343,7 +354,7 @@ func (pt *preparedTarget) buildKubeconfig(ctx context.Context) (*api.Config, err
 		return nil, err
 	}
 
-	kubeConfig := pt.restConfigToKubeconfig(restConfig)
+	kubeConfig := k8s2.RestConfigToKubeconfig(restConfig)
 	return kubeConfig, nil
 }
 
@@ -495,24 +506,7 @@ func (pt *preparedTarget) clientConfigGetter(ctx context.Context) func(context *
 		if err != nil {
 			return nil, nil, err
 		}
-
-		configOverrides := &clientcmd.ConfigOverrides{}
-		if context != nil {
-			configOverrides.CurrentContext = *context
-		}
-		clientConfig := clientcmd.NewDefaultClientConfig(*kubeConfig, configOverrides)
-		rawConfig, err := clientConfig.RawConfig()
-		if err != nil {
-			return nil, nil, err
-		}
-		if context != nil {
-			rawConfig.CurrentContext = *context
-		}
-		restConfig, err := clientConfig.ClientConfig()
-		if err != nil {
-			return nil, nil, err
-		}
-		return restConfig, &rawConfig, nil
+		return k8s2.ClientConfigFromKubeconfig(kubeConfig, context)
 	}
 }
 
@@ -588,20 +582,29 @@ func (pp *preparedProject) addServiceAccountBasedKeyServers(ctx context.Context,
 func (pp *preparedProject) loadKluctlProject(ctx context.Context, pt *preparedTarget) (*kluctl_project.LoadedKluctlProject, error) {
 	var err error
 
-	var externalArgs *uo.UnstructuredObject
-	if pt.pp.obj.Spec.Args != nil {
-		externalArgs, err = uo.FromString(string(pt.pp.obj.Spec.Args.Raw))
+	externalArgs, sensitiveArgs, err := pp.r.loadArgsFrom(ctx, pp.obj)
+	if err != nil {
+		return nil, err
+	}
+	if pp.obj.Spec.Args != nil {
+		inlineArgs, err := uo.FromString(string(pp.obj.Spec.Args.Raw))
 		if err != nil {
 			return nil, err
 		}
+		if externalArgs == nil {
+			externalArgs = inlineArgs
+		} else {
+			externalArgs.Merge(inlineArgs)
+		}
 	}
 
 	loadArgs := kluctl_project.LoadKluctlProjectArgs{
-		RepoRoot:     pp.repoDir,
-		ExternalArgs: externalArgs,
-		ProjectDir:   pp.projectDir,
-		GitRP:        pp.gitRP,
-		OciRP:        pp.ociRP,
+		RepoRoot:      pp.repoDir,
+		ExternalArgs:  externalArgs,
+		SensitiveArgs: sensitiveArgs,
+		ProjectDir:    pp.projectDir,
+		GitRP:         pp.gitRP,
+		OciRP:         pp.ociRP,
 		AddKeyServersFunc: func(ctx context.Context, d *decryptor.Decryptor) error {
 			return pp.addKeyServers(ctx, d)
 		},
@@ -647,6 +650,7 @@ func (pt *preparedTarget) loadTarget(ctx context.Context, p *kluctl_project.Load
 	if pt.pp.obj.Spec.Context != nil {
 		props.ContextOverride = *pt.pp.obj.Spec.Context
 	}
+	props.KubeconfigSource = k8s2.DescribeKubeconfigSource(pt.kubeconfigSource())
 
 	restConfig, contextName, err := p.LoadK8sConfig(ctx, props.TargetName, props.ContextOverride, props.OfflineK8s)
 	if err != nil {
@@ -671,6 +675,10 @@ func (pt *preparedTarget) loadTarget(ctx context.Context, p *kluctl_project.Load
 	if err != nil {
 		return targetContext, err
 	}
+	err = targetContext.SharedContext.Deprecations.CheckErrors(p.GetDeprecationErrors())
+	if err != nil {
+		return targetContext, err
+	}
 	return targetContext, nil
 }
 
@@ -856,7 +864,7 @@ func (pt *preparedTarget) kluctlValidate(targetContext *target_context.TargetCon
 }
 
 func (pt *preparedTarget) kluctlDelete(ctx context.Context, discriminator string) (*result.CommandResult, error) {
-	if !pt.pp.obj.Spec.Delete {
+	if pt.pp.obj.Spec.GetDeletionPolicy() == kluctlv1.DeletionPolicyOrphan {
 		return nil, nil
 	}
 
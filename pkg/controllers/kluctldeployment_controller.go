@@ -40,7 +40,8 @@ type KluctlDeploymentReconciler struct {
 	UseSystemPython       bool
 	DryRun                bool
 
-	SshPool *ssh_pool.SshPool
+	SshPool     *ssh_pool.SshPool
+	SourceCache *SourceCache
 
 	ResultStore results.ResultStore
 
@@ -163,10 +164,18 @@ func (r *KluctlDeploymentReconciler) doReconcile(
 		return nil, patchErr
 	}
 
+	err := r.handleStaleCancelRequest(ctx, obj)
+	if err != nil {
+		return nil, r.patchFailPrepare(ctx, obj, err)
+	}
+
 	timeoutCtx, cancel := context.WithTimeout(ctx, r.calcTimeout(obj))
 	defer cancel()
 
-	err := r.patchProjectKey(ctx, obj)
+	timeoutCtx, cw := r.startCancelWatcher(ctx, timeoutCtx, obj, reconcileId)
+	defer cw.stop()
+
+	err = r.patchProjectKey(ctx, obj)
 	if err != nil {
 		return nil, r.patchFailPrepare(ctx, obj, err)
 	}
@@ -180,10 +189,45 @@ func (r *KluctlDeploymentReconciler) doReconcile(
 		return nil, err
 	}
 	if processed {
+		if cw.isCancelled() {
+			// don't immediately retry what just got cancelled
+			return &ctrl.Result{RequeueAfter: obj.Spec.GetRetryInterval()}, nil
+		}
 		// immediately cause another reconcile loop to ensure we didn't miss regular reconciliation
 		return &ctrl.Result{Requeue: true}, nil
 	}
 
+	if !obj.Spec.Suspend {
+		reason, msg, err := r.checkDependencies(ctx, obj)
+		if err != nil {
+			return nil, r.patchFailPrepare(ctx, obj, err)
+		}
+		if msg != "" {
+			// we get requeued as soon as the dependency becomes ready, but also retry periodically for the case where
+			// the dependency does not exist yet or a cycle got resolved
+			err = fmt.Errorf("%s", msg)
+			patchErr := r.patchReadyCondition(ctx, obj, metav1.ConditionFalse, reason, msg)
+			if patchErr != nil {
+				return nil, multierror.Append(err, patchErr)
+			}
+			return &ctrl.Result{RequeueAfter: obj.Spec.GetRetryInterval()}, err
+		}
+
+		reason, msg, err = r.checkArgsFrom(ctx, obj)
+		if err != nil {
+			return nil, r.patchFailPrepare(ctx, obj, err)
+		}
+		if msg != "" {
+			// we get requeued as soon as the referenced object gets created
+			err = fmt.Errorf("%s", msg)
+			patchErr := r.patchReadyCondition(ctx, obj, metav1.ConditionFalse, reason, msg)
+			if patchErr != nil {
+				return nil, multierror.Append(err, patchErr)
+			}
+			return &ctrl.Result{RequeueAfter: obj.Spec.GetRetryInterval()}, err
+		}
+	}
+
 	_, err = r.reconcileFullRequest(ctx, timeoutCtx, obj, reconcileId)
 	if err != nil {
 		return nil, err
@@ -417,60 +461,6 @@ func (r *KluctlDeploymentReconciler) nextValidateTime(obj *kluctlv1.KluctlDeploy
 	return &t
 }
 
-func (r *KluctlDeploymentReconciler) finalize(ctx context.Context, obj *kluctlv1.KluctlDeployment, reconcileId string) (ctrl.Result, error) {
-	r.doFinalize(ctx, obj, reconcileId)
-
-	r.mutex.Lock()
-	delete(r.resourceVersionsMap, client.ObjectKeyFromObject(obj))
-	r.mutex.Unlock()
-
-	// Remove our finalizer from the list and update it
-	patch := client.MergeFrom(obj.DeepCopy())
-	controllerutil.RemoveFinalizer(obj, kluctlv1.KluctlDeploymentFinalizer)
-	if err := r.Client.Patch(ctx, obj, patch, client.FieldOwner(r.ControllerName)); err != nil {
-		return ctrl.Result{}, err
-	}
-
-	// Stop reconciliation as the object is being deleted
-	return ctrl.Result{}, nil
-}
-
-func (r *KluctlDeploymentReconciler) doFinalize(ctx context.Context, obj *kluctlv1.KluctlDeployment, reconcileId string) {
-	log := ctrl.LoggerFrom(ctx)
-
-	log.Info("Finalizing")
-
-	if !obj.Spec.Delete || obj.Spec.Suspend {
-		return
-	}
-
-	if obj.Status.ProjectKey == nil || obj.Status.TargetKey == nil {
-		log.V(1).Info("No project/target key set, skipping deletion")
-		return
-	}
-
-	log.Info(fmt.Sprintf("Deleting objects with discriminator '%s'", obj.Status.TargetKey.Discriminator))
-
-	pp, err := prepareProject(ctx, r, obj, false)
-	if err != nil {
-		return
-	}
-	defer pp.cleanup(ctx)
-
-	pt := pp.newTarget()
-
-	cmdResult, err := pt.kluctlDelete(ctx, obj.Status.TargetKey.Discriminator)
-	if err != nil {
-		log.Error(err, "delete failed with an error")
-	}
-	if cmdResult != nil {
-		err = pt.writeCommandResult(ctx, cmdResult, nil, "delete", reconcileId, "", false)
-		if err != nil {
-			log.Error(err, "write delete command result failed")
-		}
-	}
-}
-
 func (r *KluctlDeploymentReconciler) exportDeploymentObjectToProm(obj *kluctlv1.KluctlDeployment) {
 	pruneEnabled := 0.0
 	deleteEnabled := 0.0
@@ -480,7 +470,7 @@ func (r *KluctlDeploymentReconciler) exportDeploymentObjectToProm(obj *kluctlv1.
 	if obj.Spec.Prune {
 		pruneEnabled = 1.0
 	}
-	if obj.Spec.Delete {
+	if obj.Spec.GetDeletionPolicy() != kluctlv1.DeletionPolicyOrphan {
 		deleteEnabled = 1.0
 	}
 	if obj.Spec.DryRun {
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sort"
+)
+
+const argsFromIndexKey = ".spec.argsFrom"
+
+func buildArgsFromKey(kind string, name string) string {
+	return kind + "/" + name
+}
+
+// indexArgsFrom is used as field indexer so that we can find all KluctlDeployments that reference a given ConfigMap
+// or Secret via spec.argsFrom
+func indexArgsFrom(o client.Object) []string {
+	obj, ok := o.(*kluctlv1.KluctlDeployment)
+	if !ok {
+		return nil
+	}
+	var ret []string
+	for _, ref := range obj.Spec.ArgsFrom {
+		ret = append(ret, buildArgsFromKey(ref.Kind, ref.Name))
+	}
+	return ret
+}
+
+// requestsForArgsFrom returns a map function that returns reconcile requests for all KluctlDeployments that reference
+// the given object via spec.argsFrom
+func (r *KluctlDeploymentReconciler) requestsForArgsFrom(kind string) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		var list kluctlv1.KluctlDeploymentList
+		err := r.Client.List(ctx, &list, client.InNamespace(o.GetNamespace()), client.MatchingFields{argsFromIndexKey: buildArgsFromKey(kind, o.GetName())})
+		if err != nil {
+			return nil
+		}
+		var ret []reconcile.Request
+		for _, x := range list.Items {
+			ret = append(ret, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&x)})
+		}
+		return ret
+	}
+}
+
+// argsFromNotFoundError is returned when a ConfigMap, Secret or key referenced via spec.argsFrom does not exist
+type argsFromNotFoundError struct {
+	ref kluctlv1.ArgsFromReference
+	key types.NamespacedName
+	// item is empty if the whole object does not exist
+	item string
+}
+
+func (e *argsFromNotFoundError) Error() string {
+	if e.item == "" {
+		return fmt.Sprintf("argsFrom references %s %s, which does not exist", e.ref.Kind, e.key.String())
+	}
+	return fmt.Sprintf("argsFrom references key '%s' of %s %s, which does not exist", e.item, e.ref.Kind, e.key.String())
+}
+
+func (r *KluctlDeploymentReconciler) getArgsFromData(ctx context.Context, obj *kluctlv1.KluctlDeployment, ref kluctlv1.ArgsFromReference) (map[string]string, error) {
+	key := types.NamespacedName{Namespace: obj.Namespace, Name: ref.Name}
+
+	ret := map[string]string{}
+	var err error
+	switch ref.Kind {
+	case "ConfigMap":
+		var cm corev1.ConfigMap
+		err = r.Client.Get(ctx, key, &cm)
+		for k, v := range cm.Data {
+			ret[k] = v
+		}
+		for k, v := range cm.BinaryData {
+			ret[k] = string(v)
+		}
+	case "Secret":
+		var secret corev1.Secret
+		err = r.Client.Get(ctx, key, &secret)
+		for k, v := range secret.Data {
+			ret[k] = string(v)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported argsFrom kind %s", ref.Kind)
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, &argsFromNotFoundError{ref: ref, key: key}
+		}
+		return nil, fmt.Errorf("failed to get argsFrom %s %s: %w", ref.Kind, key.String(), err)
+	}
+	return ret, nil
+}
+
+// loadArgsFrom loads and merges the args of all ConfigMaps and Secrets referenced via spec.argsFrom. The names of
+// all args loaded from Secrets are returned as well, so that they can be treated as sensitive. It returns nil args
+// if no argsFrom references are specified.
+func (r *KluctlDeploymentReconciler) loadArgsFrom(ctx context.Context, obj *kluctlv1.KluctlDeployment) (*uo.UnstructuredObject, []string, error) {
+	if len(obj.Spec.ArgsFrom) == 0 {
+		return nil, nil, nil
+	}
+
+	ret := uo.New()
+	var sensitiveArgs []string
+	for _, ref := range obj.Spec.ArgsFrom {
+		data, err := r.getArgsFromData(ctx, obj, ref)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		args := map[string]string{}
+		if len(ref.Items) == 0 {
+			for k, v := range data {
+				args[k] = v
+			}
+		}
+		for _, item := range ref.Items {
+			v, ok := data[item.Key]
+			if !ok {
+				return nil, nil, &argsFromNotFoundError{
+					ref:  ref,
+					key:  types.NamespacedName{Namespace: obj.Namespace, Name: ref.Name},
+					item: item.Key,
+				}
+			}
+			argName := item.Arg
+			if argName == "" {
+				argName = item.Key
+			}
+			args[argName] = v
+		}
+
+		vars, _, err := kluctl_project.ConvertArgsToVars(args, false, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse args from %s %s: %w", ref.Kind, ref.Name, err)
+		}
+		ret.Merge(vars)
+
+		if ref.Kind == "Secret" {
+			for n := range args {
+				sensitiveArgs = append(sensitiveArgs, n)
+			}
+		}
+	}
+	sort.Strings(sensitiveArgs)
+	return ret, sensitiveArgs, nil
+}
+
+// checkArgsFrom verifies that all ConfigMaps, Secrets and keys referenced via spec.argsFrom exist. It returns the
+// reason and message to be used in the Ready condition in case a reference is missing, or empty strings otherwise.
+func (r *KluctlDeploymentReconciler) checkArgsFrom(ctx context.Context, obj *kluctlv1.KluctlDeployment) (string, string, error) {
+	_, _, err := r.loadArgsFrom(ctx, obj)
+	if err != nil {
+		var nfErr *argsFromNotFoundError
+		if errors.As(err, &nfErr) {
+			return kluctlv1.ArgsFromNotFoundReason, nfErr.Error(), nil
+		}
+		return "", "", err
+	}
+	return "", "", nil
+}
@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/meta"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	cancelPollInterval = 2 * time.Second
+	cancelGracePeriod  = time.Minute
+)
+
+// cancelWatcher watches for the kluctl.io/request-cancel annotation while a reconciliation is in progress. The
+// controller does not reconcile the same object concurrently, so the annotation can not be handled by a separate
+// reconciliation.
+type cancelWatcher struct {
+	r           *KluctlDeploymentReconciler
+	key         client.ObjectKey
+	reconcileId string
+	cancel      context.CancelCauseFunc
+
+	cancelled atomic.Bool
+	doneCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// startCancelWatcher returns a child context of cmdCtx that gets cancelled when a cancel request is received. ctx is
+// used for all status updates and must not be cancelled by the watcher itself.
+func (r *KluctlDeploymentReconciler) startCancelWatcher(ctx context.Context, cmdCtx context.Context, obj *kluctlv1.KluctlDeployment, reconcileId string) (context.Context, *cancelWatcher) {
+	cmdCtx, cancel := context.WithCancelCause(cmdCtx)
+
+	w := &cancelWatcher{
+		r:           r,
+		key:         client.ObjectKeyFromObject(obj),
+		reconcileId: reconcileId,
+		cancel:      cancel,
+		doneCh:      make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.run(ctx)
+	}()
+	return cmdCtx, w
+}
+
+// stop stops watching and records the end time of a handled cancel request
+func (w *cancelWatcher) stop() {
+	close(w.doneCh)
+	w.wg.Wait()
+	w.cancel(nil)
+}
+
+func (w *cancelWatcher) isCancelled() bool {
+	return w.cancelled.Load()
+}
+
+func (w *cancelWatcher) run(ctx context.Context) {
+	log := ctrl.LoggerFrom(ctx)
+
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+
+	var rr *kluctlv1.CancelRequestResult
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.doneCh:
+			if rr != nil {
+				t := metav1.Now()
+				rr.EndTime = &t
+				if rr.Stuck {
+					rr.Message = fmt.Sprintf("reconciliation finished %s after it was cancelled", t.Sub(rr.HandledTime.Time).Round(time.Second).String())
+				} else {
+					rr.Message = "reconciliation was cancelled"
+				}
+				w.patchResult(ctx, rr)
+			}
+			return
+		case <-ticker.C:
+		}
+
+		if rr == nil {
+			cr, phase, err := w.r.takeCancelRequest(ctx, w.key)
+			if err != nil {
+				log.Error(err, "failed to check for cancel request")
+				continue
+			}
+			if cr == nil {
+				continue
+			}
+
+			log.Info(fmt.Sprintf("Cancelling reconciliation in phase '%s' as requested by %s", phase, cr.RequestedBy))
+
+			rr = &kluctlv1.CancelRequestResult{
+				Request:     *cr,
+				HandledTime: metav1.Now(),
+				ReconcileId: w.reconcileId,
+				Phase:       phase,
+				Message:     "reconciliation is being cancelled",
+			}
+			w.patchResult(ctx, rr)
+
+			w.cancelled.Store(true)
+			w.cancel(fmt.Errorf("reconciliation cancelled by request from %s", cr.RequestedBy))
+		} else if !rr.Stuck && time.Since(rr.HandledTime.Time) >= cancelGracePeriod {
+			// we can't do anything about it, but we can at least tell the user
+			var obj kluctlv1.KluctlDeployment
+			err := w.r.Client.Get(ctx, w.key, &obj)
+			if err != nil {
+				log.Error(err, "failed to get current phase")
+				continue
+			}
+			rr.Stuck = true
+			rr.Phase = buildPhase(&obj)
+			rr.Message = fmt.Sprintf("reconciliation did not stop within %s after it was cancelled, phase '%s' can not be cancelled", cancelGracePeriod.String(), rr.Phase)
+			log.Info(rr.Message)
+			w.r.event(ctx, &obj, true, rr.Message, nil)
+			w.patchResult(ctx, rr)
+		}
+	}
+}
+
+func (w *cancelWatcher) patchResult(ctx context.Context, rr *kluctlv1.CancelRequestResult) {
+	err := w.r.patchStatus(ctx, w.key, func(status *kluctlv1.KluctlDeploymentStatus) error {
+		status.CancelRequestResult = rr.DeepCopy()
+		return nil
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to patch cancel request result")
+	}
+}
+
+// takeCancelRequest removes the cancel request annotation and returns the parsed request, together with the phase
+// the reconciliation is currently in. It returns nil if no cancel request is present.
+func (r *KluctlDeploymentReconciler) takeCancelRequest(ctx context.Context, key client.ObjectKey) (*kluctlv1.CancelRequest, string, error) {
+	var obj kluctlv1.KluctlDeployment
+	err := r.Client.Get(ctx, key, &obj)
+	if err != nil {
+		return nil, "", err
+	}
+
+	v, _ := obj.GetAnnotations()[kluctlv1.KluctlRequestCancelAnnotation]
+	if v == "" {
+		return nil, "", nil
+	}
+
+	err = r.patch(ctx, key, false, func(obj *kluctlv1.KluctlDeployment) error {
+		a := obj.GetAnnotations()
+		if a == nil || a[kluctlv1.KluctlRequestCancelAnnotation] != v {
+			return nil
+		}
+		delete(a, kluctlv1.KluctlRequestCancelAnnotation)
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var cr kluctlv1.CancelRequest
+	err = yaml.ReadYamlString(v, &cr)
+	if err != nil {
+		return nil, "", err
+	}
+	if cr.RequestValue == "" {
+		return nil, "", fmt.Errorf("missing requestValue in cancel request annotation")
+	}
+	if cr.RequestedBy == "" {
+		cr.RequestedBy = "unknown"
+	}
+
+	return &cr, buildPhase(&obj), nil
+}
+
+// handleStaleCancelRequest handles a cancel request that was received while no reconciliation was in progress, so
+// that it does not cancel the next reconciliation.
+func (r *KluctlDeploymentReconciler) handleStaleCancelRequest(ctx context.Context, obj *kluctlv1.KluctlDeployment) error {
+	key := client.ObjectKeyFromObject(obj)
+
+	cr, _, err := r.takeCancelRequest(ctx, key)
+	if err != nil {
+		return err
+	}
+	if cr == nil {
+		return nil
+	}
+
+	ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("Ignoring cancel request from %s as no reconciliation is in progress", cr.RequestedBy))
+
+	t := metav1.Now()
+	return r.patchStatus(ctx, key, func(status *kluctlv1.KluctlDeploymentStatus) error {
+		status.CancelRequestResult = &kluctlv1.CancelRequestResult{
+			Request:     *cr,
+			HandledTime: t,
+			EndTime:     &t,
+			Message:     "no reconciliation was in progress",
+		}
+		return nil
+	})
+}
+
+func buildPhase(obj *kluctlv1.KluctlDeployment) string {
+	c := apimeta.FindStatusCondition(obj.GetConditions(), meta.ReconcilingCondition)
+	if c == nil || c.Status != metav1.ConditionTrue {
+		return "unknown"
+	}
+	return c.Message
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/meta"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"strings"
+)
+
+const dependsOnIndexKey = ".spec.dependsOn"
+
+func buildDependsOnKey(obj *kluctlv1.KluctlDeployment, ref kluctlv1.DependsOnReference) client.ObjectKey {
+	ns := ref.Namespace
+	if ns == "" {
+		ns = obj.Namespace
+	}
+	return client.ObjectKey{Namespace: ns, Name: ref.Name}
+}
+
+// indexDependsOn is used as field indexer so that we can find all KluctlDeployments that depend on a given
+// KluctlDeployment
+func indexDependsOn(o client.Object) []string {
+	obj, ok := o.(*kluctlv1.KluctlDeployment)
+	if !ok {
+		return nil
+	}
+	var ret []string
+	for _, ref := range obj.Spec.DependsOn {
+		ret = append(ret, buildDependsOnKey(obj, ref).String())
+	}
+	return ret
+}
+
+// requestsForDependency returns reconcile requests for all KluctlDeployments that depend on the given object
+func (r *KluctlDeploymentReconciler) requestsForDependency(ctx context.Context, o client.Object) []reconcile.Request {
+	var list kluctlv1.KluctlDeploymentList
+	err := r.Client.List(ctx, &list, client.MatchingFields{dependsOnIndexKey: client.ObjectKeyFromObject(o).String()})
+	if err != nil {
+		return nil
+	}
+	var ret []reconcile.Request
+	for _, x := range list.Items {
+		ret = append(ret, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&x)})
+	}
+	return ret
+}
+
+func isDependencyReady(dep *kluctlv1.KluctlDeployment, ref kluctlv1.DependsOnReference) (bool, string) {
+	c := apimeta.FindStatusCondition(dep.GetConditions(), meta.ReadyCondition)
+	if c == nil || c.Status != metav1.ConditionTrue {
+		return false, "is not ready"
+	}
+	minGeneration := dep.Generation
+	if ref.MinRevision != nil {
+		minGeneration = *ref.MinRevision
+	}
+	if c.ObservedGeneration < minGeneration || dep.Status.ObservedGeneration < minGeneration {
+		return false, fmt.Sprintf("did not reconcile revision %d yet", minGeneration)
+	}
+	return true, ""
+}
+
+// findDependsOnCycle walks the dependency graph starting at obj and returns the path of the first cycle that leads
+// back to obj. Cycles that don't include obj are ignored here, as they are reported by the participating objects.
+func (r *KluctlDeploymentReconciler) findDependsOnCycle(ctx context.Context, obj *kluctlv1.KluctlDeployment) ([]client.ObjectKey, error) {
+	start := client.ObjectKeyFromObject(obj)
+	visited := map[client.ObjectKey]bool{}
+
+	var walk func(o *kluctlv1.KluctlDeployment, path []client.ObjectKey) ([]client.ObjectKey, error)
+	walk = func(o *kluctlv1.KluctlDeployment, path []client.ObjectKey) ([]client.ObjectKey, error) {
+		for _, ref := range o.Spec.DependsOn {
+			key := buildDependsOnKey(o, ref)
+			if key == start {
+				return append(path, key), nil
+			}
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+
+			var dep kluctlv1.KluctlDeployment
+			err := r.Client.Get(ctx, key, &dep)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			cycle, err := walk(&dep, append(path, key))
+			if err != nil || cycle != nil {
+				return cycle, err
+			}
+		}
+		return nil, nil
+	}
+
+	return walk(obj, []client.ObjectKey{start})
+}
+
+// checkDependencies verifies that all KluctlDeployments referenced via spec.dependsOn are ready. It returns the reason
+// and message to be used in the Ready condition in case the deployment is blocked, or empty strings otherwise.
+func (r *KluctlDeploymentReconciler) checkDependencies(ctx context.Context, obj *kluctlv1.KluctlDeployment) (string, string, error) {
+	if len(obj.Spec.DependsOn) == 0 {
+		return "", "", nil
+	}
+
+	cycle, err := r.findDependsOnCycle(ctx, obj)
+	if err != nil {
+		return "", "", err
+	}
+	if cycle != nil {
+		var s []string
+		for _, key := range cycle {
+			s = append(s, key.String())
+		}
+		return kluctlv1.DependencyCycleReason, fmt.Sprintf("dependency cycle detected: %s", strings.Join(s, " -> ")), nil
+	}
+
+	for _, ref := range obj.Spec.DependsOn {
+		key := buildDependsOnKey(obj, ref)
+
+		var dep kluctlv1.KluctlDeployment
+		err := r.Client.Get(ctx, key, &dep)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return kluctlv1.DependencyNotReadyReason, fmt.Sprintf("dependency %s does not exist", key.String()), nil
+			}
+			return "", "", err
+		}
+		ready, reason := isDependencyReady(&dep, ref)
+		if !ready {
+			return kluctlv1.DependencyNotReadyReason, fmt.Sprintf("dependency %s %s", key.String(), reason), nil
+		}
+	}
+	return "", "", nil
+}
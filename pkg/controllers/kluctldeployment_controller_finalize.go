@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+// interval used to check for remaining objects when the deletion policy is delete-and-wait
+const deleteAndWaitInterval = 5 * time.Second
+
+func (r *KluctlDeploymentReconciler) finalize(ctx context.Context, obj *kluctlv1.KluctlDeployment, reconcileId string) (ctrl.Result, error) {
+	done, requeueAfter := r.doFinalize(ctx, obj, reconcileId)
+	if !done {
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	r.mutex.Lock()
+	delete(r.resourceVersionsMap, client.ObjectKeyFromObject(obj))
+	r.mutex.Unlock()
+
+	// Remove our finalizer from the list and update it
+	patch := client.MergeFrom(obj.DeepCopy())
+	controllerutil.RemoveFinalizer(obj, kluctlv1.KluctlDeploymentFinalizer)
+	if err := r.Client.Patch(ctx, obj, patch, client.FieldOwner(r.ControllerName)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Stop reconciliation as the object is being deleted
+	return ctrl.Result{}, nil
+}
+
+// doFinalize performs one deletion pass. It returns true if the finalizer can be removed, otherwise it returns the
+// duration after which the next pass should be performed.
+func (r *KluctlDeploymentReconciler) doFinalize(ctx context.Context, obj *kluctlv1.KluctlDeployment, reconcileId string) (bool, time.Duration) {
+	log := ctrl.LoggerFrom(ctx)
+
+	log.Info("Finalizing")
+
+	policy := obj.Spec.GetDeletionPolicy()
+	if policy == kluctlv1.DeletionPolicyOrphan || obj.Spec.Suspend {
+		return true, 0
+	}
+
+	if obj.Status.ProjectKey == nil || obj.Status.TargetKey == nil {
+		log.V(1).Info("No project/target key set, skipping deletion")
+		return true, 0
+	}
+
+	deadline := obj.GetDeletionTimestamp().Add(obj.Spec.GetDeletionTimeout())
+	if !time.Now().Before(deadline) {
+		r.handleDeletionTimeout(ctx, obj, reconcileId)
+		return true, 0
+	}
+	requeueAfter := func(d time.Duration) time.Duration {
+		if untilDeadline := time.Until(deadline); untilDeadline < d {
+			return untilDeadline
+		}
+		return d
+	}
+
+	oldCondition := apimeta.FindStatusCondition(obj.GetConditions(), kluctlv1.TerminatingCondition)
+	if oldCondition == nil {
+		msg := fmt.Sprintf("Deleting objects with discriminator '%s' (deletion policy %s)", obj.Status.TargetKey.Discriminator, policy)
+		log.Info(msg)
+		r.event(ctx, obj, false, msg, nil)
+	}
+
+	pp, err := prepareProject(ctx, r, obj, false)
+	if err != nil {
+		log.Error(err, "failed to prepare deletion")
+		r.patchTerminatingCondition(ctx, obj, kluctlv1.DeleteFailedReason, fmt.Sprintf("failed to prepare deletion: %s", err.Error()))
+		return false, requeueAfter(obj.Spec.GetRetryInterval())
+	}
+	defer pp.cleanup(ctx)
+
+	pt := pp.newTarget()
+
+	cmdResult, err := pt.kluctlDelete(ctx, obj.Status.TargetKey.Discriminator)
+	if err != nil {
+		log.Error(err, "delete failed with an error")
+	}
+	if cmdResult == nil {
+		r.patchTerminatingCondition(ctx, obj, kluctlv1.DeleteFailedReason, fmt.Sprintf("delete failed: %s", err.Error()))
+		return false, requeueAfter(obj.Spec.GetRetryInterval())
+	}
+
+	// only store the result of the first successful pass, following passes would only repeat deletion of objects
+	// which are still terminating
+	if oldCondition == nil || oldCondition.Reason != kluctlv1.DeletingReason || len(cmdResult.Errors) != 0 {
+		err = pt.writeCommandResult(ctx, cmdResult, nil, "delete", reconcileId, "", false)
+		if err != nil {
+			log.Error(err, "write delete command result failed")
+		}
+	}
+
+	if len(cmdResult.Errors) != 0 {
+		msg := r.buildBaseResultMessage(cmdResult.Errors, cmdResult.Warnings, "delete")
+		r.patchTerminatingCondition(ctx, obj, kluctlv1.DeleteFailedReason, msg)
+		return false, requeueAfter(obj.Spec.GetRetryInterval())
+	}
+
+	if protected := countProtectedObjects(cmdResult); protected != 0 && oldCondition == nil {
+		r.event(ctx, obj, true, fmt.Sprintf("Skipped deletion of %d protected objects", protected), nil)
+	}
+
+	if policy != kluctlv1.DeletionPolicyDeleteAndWait {
+		return true, 0
+	}
+
+	remaining := buildRemainingObjectsMessage(cmdResult)
+	if remaining == "" {
+		log.Info("All objects have been deleted")
+		return true, 0
+	}
+
+	msg := fmt.Sprintf("Waiting for objects to be deleted: %s", remaining)
+	if oldCondition == nil || oldCondition.Message != msg {
+		log.Info(msg)
+		r.event(ctx, obj, false, msg, nil)
+	}
+	r.patchTerminatingCondition(ctx, obj, kluctlv1.DeletingReason, msg)
+
+	return false, requeueAfter(deleteAndWaitInterval)
+}
+
+// handleDeletionTimeout stores a command result with a warning that lists what was left behind
+func (r *KluctlDeploymentReconciler) handleDeletionTimeout(ctx context.Context, obj *kluctlv1.KluctlDeployment, reconcileId string) {
+	log := ctrl.LoggerFrom(ctx)
+
+	msg := fmt.Sprintf("deletion did not finish within %s, removing finalizer and leaving remaining objects behind", obj.Spec.GetDeletionTimeout().String())
+	if c := apimeta.FindStatusCondition(obj.GetConditions(), kluctlv1.TerminatingCondition); c != nil && c.Message != "" {
+		msg += fmt.Sprintf(". Last status: %s", c.Message)
+	}
+	log.Info(msg)
+	r.event(ctx, obj, true, msg, nil)
+
+	now := metav1.Now()
+	cmdResult := &result.CommandResult{
+		ProjectKey: *obj.Status.ProjectKey,
+		TargetKey:  *obj.Status.TargetKey,
+		Command: result.CommandInfo{
+			StartTime: now,
+			EndTime:   now,
+			Command:   "delete",
+		},
+		Warnings: []result.DeploymentError{{Message: msg}},
+	}
+
+	// we don't need a fully prepared project to store the result
+	pt := (&preparedProject{r: r, obj: obj}).newTarget()
+	err := pt.writeCommandResult(ctx, cmdResult, nil, "delete", reconcileId, "", true)
+	if err != nil {
+		log.Error(err, "write delete command result failed")
+	}
+}
+
+func countProtectedObjects(cmdResult *result.CommandResult) int {
+	cnt := 0
+	for _, w := range cmdResult.Warnings {
+		if w.Message == utils2.ErrProtectedFromDeletion.Error() {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// buildRemainingObjectsMessage returns per-kind counts of all objects that still existed in this deletion pass
+func buildRemainingObjectsMessage(cmdResult *result.CommandResult) string {
+	counts := map[string]int{}
+	for _, o := range cmdResult.Objects {
+		if o.Deleted {
+			counts[o.Ref.Kind]++
+		}
+	}
+	var kinds []string
+	for k := range counts {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+
+	var parts []string
+	for _, k := range kinds {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[k], k))
+	}
+	return strings.Join(parts, ", ")
+}
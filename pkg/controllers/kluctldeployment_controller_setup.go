@@ -4,10 +4,12 @@ import (
 	"context"
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
@@ -15,6 +17,16 @@ import (
 func (r *KluctlDeploymentReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts KluctlDeploymentReconcilerOpts) error {
 	r.resourceVersionsMap = map[client.ObjectKey]map[k8s.ObjectRef]string{}
 
+	err := mgr.GetFieldIndexer().IndexField(ctx, &kluctlv1.KluctlDeployment{}, dependsOnIndexKey, indexDependsOn)
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(ctx, &kluctlv1.KluctlDeployment{}, argsFromIndexKey, indexArgsFrom)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(r.ControllerName).
 		WithOptions(controller.Options{
@@ -23,5 +35,15 @@ func (r *KluctlDeploymentReconciler) SetupWithManager(ctx context.Context, mgr c
 		For(&kluctlv1.KluctlDeployment{}, builder.WithPredicates(
 			predicate.Or(predicate.GenerationChangedPredicate{}, ReconcileRequestedPredicate{}),
 		)).
+		Watches(&kluctlv1.KluctlDeployment{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForDependency),
+			builder.WithPredicates(DependencyReadyPredicate{}),
+		).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForArgsFrom("ConfigMap")),
+		).
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForArgsFrom("Secret")),
+		).
 		Complete(r)
 }
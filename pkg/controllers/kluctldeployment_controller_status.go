@@ -101,6 +101,25 @@ func (r *KluctlDeploymentReconciler) patchProgressingCondition(ctx context.Conte
 	})
 }
 
+func (r *KluctlDeploymentReconciler) patchTerminatingCondition(ctx context.Context, obj *kluctlv1.KluctlDeployment, reason, message string) {
+	log := ctrl.LoggerFrom(ctx)
+	key := client.ObjectKeyFromObject(obj)
+
+	err := r.patchCondition(ctx, key, func(c *[]metav1.Condition) error {
+		apimeta.SetStatusCondition(c, metav1.Condition{
+			Type:               kluctlv1.TerminatingCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            trimString(message, kluctlv1.MaxConditionMessageLength),
+			ObservedGeneration: obj.Generation,
+		})
+		return nil
+	})
+	if err != nil {
+		log.Error(err, "failed to patch terminating condition")
+	}
+}
+
 func (r *KluctlDeploymentReconciler) patchProjectKey(ctx context.Context, obj *kluctlv1.KluctlDeployment) error {
 	key := client.ObjectKeyFromObject(obj)
 
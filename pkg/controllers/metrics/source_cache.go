@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	SourceCacheHitsKey         = "source_cache_hits_total"
+	SourceCacheMissesKey       = "source_cache_misses_total"
+	SourceCacheFetchedBytesKey = "source_cache_fetched_bytes_total"
+	SourceCacheEvictionsKey    = "source_cache_evictions_total"
+	SourceCacheSizeKey         = "source_cache_size_bytes"
+)
+
+var (
+	sourceCacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KluctlDeploymentControllerSubsystem,
+		Name:      SourceCacheHitsKey,
+		Help:      "How many source requests were served from the shared source cache.",
+	}, []string{"type"})
+
+	sourceCacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KluctlDeploymentControllerSubsystem,
+		Name:      SourceCacheMissesKey,
+		Help:      "How many source requests required a fetch.",
+	}, []string{"type"})
+
+	sourceCacheFetchedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KluctlDeploymentControllerSubsystem,
+		Name:      SourceCacheFetchedBytesKey,
+		Help:      "How many bytes of sources have been fetched into the shared source cache.",
+	}, []string{"type"})
+
+	sourceCacheEvictions = prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: KluctlDeploymentControllerSubsystem,
+		Name:      SourceCacheEvictionsKey,
+		Help:      "How many entries have been evicted from the shared source cache.",
+	})
+
+	sourceCacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: KluctlDeploymentControllerSubsystem,
+		Name:      SourceCacheSizeKey,
+		Help:      "The current size of the shared source cache in bytes.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(sourceCacheHits)
+	metrics.Registry.MustRegister(sourceCacheMisses)
+	metrics.Registry.MustRegister(sourceCacheFetchedBytes)
+	metrics.Registry.MustRegister(sourceCacheEvictions)
+	metrics.Registry.MustRegister(sourceCacheSize)
+}
+
+func NewKluctlSourceCacheHits(sourceType string) prometheus.Counter {
+	return sourceCacheHits.WithLabelValues(sourceType)
+}
+
+func NewKluctlSourceCacheMisses(sourceType string) prometheus.Counter {
+	return sourceCacheMisses.WithLabelValues(sourceType)
+}
+
+func NewKluctlSourceCacheFetchedBytes(sourceType string) prometheus.Counter {
+	return sourceCacheFetchedBytes.WithLabelValues(sourceType)
+}
+
+func NewKluctlSourceCacheEvictions() prometheus.Counter {
+	return sourceCacheEvictions
+}
+
+func NewKluctlSourceCacheSize() prometheus.Gauge {
+	return sourceCacheSize
+}
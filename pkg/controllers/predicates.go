@@ -2,6 +2,9 @@ package controllers
 
 import (
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/meta"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -32,5 +35,45 @@ func (ReconcileRequestedPredicate) Update(e event.UpdateEvent) bool {
 		checkManualRequest(kluctlv1.KluctlRequestDiffAnnotation) ||
 		checkManualRequest(kluctlv1.KluctlRequestDeployAnnotation) ||
 		checkManualRequest(kluctlv1.KluctlRequestPruneAnnotation) ||
-		checkManualRequest(kluctlv1.KluctlRequestValidateAnnotation)
+		checkManualRequest(kluctlv1.KluctlRequestValidateAnnotation) ||
+		checkManualRequest(kluctlv1.KluctlRequestCancelAnnotation)
+}
+
+// DependencyReadyPredicate filters for KluctlDeployments that became ready, so that KluctlDeployments depending on
+// them via spec.dependsOn can be reconciled.
+type DependencyReadyPredicate struct {
+	predicate.Funcs
+}
+
+func (DependencyReadyPredicate) Create(e event.CreateEvent) bool {
+	return false
+}
+
+func (DependencyReadyPredicate) Delete(e event.DeleteEvent) bool {
+	return false
+}
+
+func (DependencyReadyPredicate) Generic(e event.GenericEvent) bool {
+	return false
+}
+
+func (DependencyReadyPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+	oldObj, ok1 := e.ObjectOld.(*kluctlv1.KluctlDeployment)
+	newObj, ok2 := e.ObjectNew.(*kluctlv1.KluctlDeployment)
+	if !ok1 || !ok2 {
+		return false
+	}
+
+	newReady := apimeta.FindStatusCondition(newObj.GetConditions(), meta.ReadyCondition)
+	if newReady == nil || newReady.Status != metav1.ConditionTrue {
+		return false
+	}
+	oldReady := apimeta.FindStatusCondition(oldObj.GetConditions(), meta.ReadyCondition)
+	if oldReady == nil || oldReady.Status != metav1.ConditionTrue {
+		return true
+	}
+	return oldReady.ObservedGeneration != newReady.ObservedGeneration
 }
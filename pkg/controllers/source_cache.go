@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/git"
+	"github.com/kluctl/kluctl/v2/pkg/controllers/metrics"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	cp "github.com/otiai10/copy"
+)
+
+// SourceCache is a size-bounded cache of fetched KluctlDeployment sources (git and OCI) that is shared between all
+// reconciliations of the controller. Entries are keyed by source type, url, ref and a hash of the credentials that were
+// used to fetch the source, so that a cached source is only ever served to deployments that have the exact same
+// credentials available. Concurrent requests for the same key are merged into a single fetch.
+// A nil cache performs all fetches directly.
+type SourceCache struct {
+	baseDir string
+	maxSize int64
+	maxAge  time.Duration
+
+	mutex   sync.Mutex
+	entries map[string]*sourceCacheEntry
+	lru     *list.List
+	size    int64
+}
+
+type SourceCacheKey struct {
+	Type string `json:"type"`
+	Url  string `json:"url"`
+	Ref  any    `json:"ref,omitempty"`
+	// CredentialsHash must be a hash of all credentials that were made available to the fetch
+	CredentialsHash string `json:"credentialsHash"`
+}
+
+type sourceCacheEntry struct {
+	key       string
+	typ       string
+	dir       string
+	co        git.CheckoutInfo
+	size      int64
+	fetchTime time.Time
+
+	done chan struct{}
+	err  error
+
+	users   int
+	evicted bool
+	elem    *list.Element
+}
+
+// SourceFetchFunc performs the actual fetch and returns the directory of the fetched source. The returned directory is
+// copied into the cache and not used afterward.
+type SourceFetchFunc func() (string, git.CheckoutInfo, error)
+
+func NewSourceCache(baseDir string, maxSize int64, maxAge time.Duration) *SourceCache {
+	// entries from previous runs are not known to the index and would never be evicted
+	_ = os.RemoveAll(baseDir)
+
+	return &SourceCache{
+		baseDir: baseDir,
+		maxSize: maxSize,
+		maxAge:  maxAge,
+		entries: map[string]*sourceCacheEntry{},
+		lru:     list.New(),
+	}
+}
+
+func (k SourceCacheKey) hash() (string, error) {
+	b, err := json.Marshal(k)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// Get copies the source identified by key into targetDir. If the cache holds a fresh entry for the key, it is used,
+// otherwise fetch is invoked. If another reconciliation is already fetching the same key, Get waits for it to finish
+// and uses its result.
+func (c *SourceCache) Get(ctx context.Context, key SourceCacheKey, targetDir string, fetch SourceFetchFunc) (string, git.CheckoutInfo, error) {
+	if c == nil {
+		return fetch()
+	}
+
+	k, err := key.hash()
+	if err != nil {
+		return "", git.CheckoutInfo{}, err
+	}
+
+	e, hit := c.acquire(k, key.Type)
+	if hit {
+		metrics.NewKluctlSourceCacheHits(key.Type).Inc()
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			c.release(e)
+			return "", git.CheckoutInfo{}, ctx.Err()
+		}
+	} else {
+		metrics.NewKluctlSourceCacheMisses(key.Type).Inc()
+		c.doFetch(e, fetch)
+	}
+	defer c.release(e)
+
+	if e.err != nil {
+		return "", git.CheckoutInfo{}, e.err
+	}
+
+	err = cp.Copy(e.dir, targetDir)
+	if err != nil {
+		return "", git.CheckoutInfo{}, err
+	}
+	return targetDir, e.co, nil
+}
+
+// acquire returns the entry for the given key and marks it as being in use. If no usable entry exists, a new one is
+// created and false is returned, in which case the caller is responsible for fetching it.
+func (c *SourceCache) acquire(k string, typ string) (*sourceCacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.entries[k]
+	if ok {
+		select {
+		case <-e.done:
+			if e.err != nil || time.Since(e.fetchTime) > c.maxAge {
+				c.removeEntry(e)
+				ok = false
+			}
+		default:
+			// fetch is still in progress
+		}
+	}
+	if ok {
+		e.users++
+		c.lru.MoveToFront(e.elem)
+		return e, true
+	}
+
+	e = &sourceCacheEntry{
+		key:   k,
+		typ:   typ,
+		done:  make(chan struct{}),
+		users: 1,
+	}
+	e.elem = c.lru.PushFront(e)
+	c.entries[k] = e
+	return e, false
+}
+
+func (c *SourceCache) doFetch(e *sourceCacheEntry, fetch SourceFetchFunc) {
+	defer close(e.done)
+
+	dir, co, err := fetch()
+	if err != nil {
+		e.err = err
+		return
+	}
+
+	err = os.MkdirAll(c.baseDir, 0o700)
+	if err != nil {
+		e.err = err
+		return
+	}
+	cacheDir, err := os.MkdirTemp(c.baseDir, "source-")
+	if err != nil {
+		e.err = err
+		return
+	}
+	err = cp.Copy(dir, cacheDir)
+	if err != nil {
+		_ = os.RemoveAll(cacheDir)
+		e.err = err
+		return
+	}
+	size, err := utils.DirSize(cacheDir)
+	if err != nil {
+		_ = os.RemoveAll(cacheDir)
+		e.err = err
+		return
+	}
+
+	metrics.NewKluctlSourceCacheFetchedBytes(e.typ).Add(float64(size))
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e.dir = cacheDir
+	e.co = co
+	e.size = size
+	e.fetchTime = time.Now()
+	if !e.evicted {
+		c.size += size
+		metrics.NewKluctlSourceCacheSize().Set(float64(c.size))
+	}
+}
+
+func (c *SourceCache) release(e *sourceCacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e.users--
+	if e.err != nil {
+		c.removeEntry(e)
+	}
+	if e.evicted && e.users == 0 && e.dir != "" {
+		_ = os.RemoveAll(e.dir)
+		e.dir = ""
+	}
+	c.evict()
+}
+
+// evict removes the least recently used entries until the cache fits into maxSize again. Entries that are currently
+// in use are only removed from the index and deleted from disk by the last user.
+func (c *SourceCache) evict() {
+	for elem := c.lru.Back(); elem != nil && c.size > c.maxSize; {
+		e := elem.Value.(*sourceCacheEntry)
+		elem = elem.Prev()
+		if e.dir == "" {
+			// still fetching
+			continue
+		}
+		c.removeEntry(e)
+		metrics.NewKluctlSourceCacheEvictions().Inc()
+		if e.users == 0 {
+			_ = os.RemoveAll(e.dir)
+			e.dir = ""
+		}
+	}
+}
+
+func (c *SourceCache) removeEntry(e *sourceCacheEntry) {
+	if e.evicted {
+		return
+	}
+	e.evicted = true
+	if c.entries[e.key] == e {
+		delete(c.entries, e.key)
+	}
+	c.lru.Remove(e.elem)
+	if e.dir != "" {
+		c.size -= e.size
+		metrics.NewKluctlSourceCacheSize().Set(float64(c.size))
+	}
+	if e.users == 0 && e.dir != "" {
+		_ = os.RemoveAll(e.dir)
+		e.dir = ""
+	}
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSourceFetch(t *testing.T, cnt *atomic.Int32, content string, delay time.Duration) SourceFetchFunc {
+	return func() (string, git.CheckoutInfo, error) {
+		cnt.Add(1)
+		time.Sleep(delay)
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, "file"), []byte(content), 0o600)
+		return dir, git.CheckoutInfo{CheckedOutCommit: content}, err
+	}
+}
+
+func TestSourceCacheSingleFlight(t *testing.T) {
+	c := NewSourceCache(t.TempDir(), 1024*1024, time.Minute)
+	key := SourceCacheKey{Type: "git", Url: "https://example.com/repo.git", CredentialsHash: "a"}
+
+	var cnt atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dir, co, err := c.Get(context.Background(), key, filepath.Join(t.TempDir(), "source"), newTestSourceFetch(t, &cnt, "a", 100*time.Millisecond))
+			assert.NoError(t, err)
+			assert.Equal(t, "a", co.CheckedOutCommit)
+			assert.FileExists(t, filepath.Join(dir, "file"))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), cnt.Load())
+}
+
+func TestSourceCacheCredentialsIsolation(t *testing.T) {
+	c := NewSourceCache(t.TempDir(), 1024*1024, time.Minute)
+	key := SourceCacheKey{Type: "git", Url: "https://example.com/repo.git", CredentialsHash: "a"}
+
+	var cnt atomic.Int32
+	_, _, err := c.Get(context.Background(), key, filepath.Join(t.TempDir(), "source"), newTestSourceFetch(t, &cnt, "a", 0))
+	assert.NoError(t, err)
+
+	key.CredentialsHash = "b"
+	_, co, err := c.Get(context.Background(), key, filepath.Join(t.TempDir(), "source"), newTestSourceFetch(t, &cnt, "b", 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "b", co.CheckedOutCommit)
+	assert.Equal(t, int32(2), cnt.Load())
+}
+
+func TestSourceCacheEviction(t *testing.T) {
+	baseDir := t.TempDir()
+	c := NewSourceCache(baseDir, 2, time.Minute)
+
+	var cnt atomic.Int32
+	get := func(url string) {
+		key := SourceCacheKey{Type: "git", Url: url}
+		_, _, err := c.Get(context.Background(), key, filepath.Join(t.TempDir(), "source"), newTestSourceFetch(t, &cnt, "a", 0))
+		assert.NoError(t, err)
+	}
+
+	get("a")
+	get("b")
+	get("a")
+	assert.Equal(t, int32(2), cnt.Load())
+
+	// exceeds the max size and evicts the least recently used entry (b)
+	get("c")
+	get("a")
+	assert.Equal(t, int32(3), cnt.Load())
+	get("b")
+	assert.Equal(t, int32(4), cnt.Load())
+
+	l, err := os.ReadDir(baseDir)
+	assert.NoError(t, err)
+	assert.Len(t, l, 2)
+	assert.Equal(t, int64(2), c.size)
+}
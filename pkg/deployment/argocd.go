@@ -0,0 +1,116 @@
+package deployment
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+const (
+	ArgoCDSyncWaveAnnotation         = "argocd.argoproj.io/sync-wave"
+	ArgoCDHookAnnotation             = "argocd.argoproj.io/hook"
+	ArgoCDHookDeletePolicyAnnotation = "argocd.argoproj.io/hook-delete-policy"
+	ArgoCDSyncOptionsAnnotation      = "argocd.argoproj.io/sync-options"
+	ArgoCDCompareOptionsAnnotation   = "argocd.argoproj.io/compare-options"
+)
+
+// ArgoCDSyncWaveWeightFactor is multiplied with the sync-wave to get the object order weight. This keeps the kind based
+// priority table effective inside a single wave, similar to how Argo CD orders objects of the same wave.
+const ArgoCDSyncWaveWeightFactor = 1000
+
+// argoCDHooks maps Argo CD hooks to kluctl hooks. Hooks mapped to an empty string have no kluctl equivalent. Hooks mapped
+// to names that are not executed by kluctl (e.g. "post-delete") cause the object to never be applied.
+var argoCDHooks = map[string]string{
+	"PreSync":    "pre-deploy",
+	"PostSync":   "post-deploy",
+	"Sync":       "",
+	"SyncFail":   "sync-fail",
+	"PostDelete": "post-delete",
+	"Skip":       "skip",
+}
+
+var argoCDDeletePolicies = map[string]string{
+	"HookSucceeded":      "hook-succeeded",
+	"HookFailed":         "hook-failed",
+	"BeforeHookCreation": "before-hook-creation",
+}
+
+func splitArgoCDAnnotation(o *uo.UnstructuredObject, name string) []string {
+	a := o.GetK8sAnnotation(name)
+	if a == nil {
+		return nil
+	}
+	var ret []string
+	for _, x := range strings.Split(*a, ",") {
+		x = strings.TrimSpace(x)
+		if x != "" {
+			ret = append(ret, x)
+		}
+	}
+	return ret
+}
+
+// GetArgoCDSyncWave returns the value of the sync-wave annotation, or nil if the annotation is not set
+func GetArgoCDSyncWave(o *uo.UnstructuredObject) (*int, error) {
+	s := o.GetK8sAnnotation(ArgoCDSyncWaveAnnotation)
+	if s == nil {
+		return nil, nil
+	}
+	w, err := strconv.ParseInt(strings.TrimSpace(*s), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", ArgoCDSyncWaveAnnotation, err)
+	}
+	w2 := int(w)
+	return &w2, nil
+}
+
+// GetArgoCDHooks maps the Argo CD hook annotation to kluctl hooks. Warnings are returned for hooks without a kluctl
+// equivalent.
+func GetArgoCDHooks(o *uo.UnstructuredObject) ([]string, []error) {
+	var hooks []string
+	var warnings []error
+	for _, h := range splitArgoCDAnnotation(o, ArgoCDHookAnnotation) {
+		kh, ok := argoCDHooks[h]
+		switch {
+		case !ok:
+			warnings = append(warnings, fmt.Errorf("unsupported %s '%s'", ArgoCDHookAnnotation, h))
+		case h == "Sync":
+			warnings = append(warnings, fmt.Errorf("%s '%s' has no kluctl equivalent, the object is applied as a regular object", ArgoCDHookAnnotation, h))
+		case h == "SyncFail" || h == "PostDelete":
+			warnings = append(warnings, fmt.Errorf("%s '%s' has no kluctl equivalent, the hook is never executed", ArgoCDHookAnnotation, h))
+			hooks = append(hooks, kh)
+		default:
+			hooks = append(hooks, kh)
+		}
+	}
+	return hooks, warnings
+}
+
+// GetArgoCDHookDeletePolicies maps the Argo CD hook-delete-policy annotation to kluctl delete policies
+func GetArgoCDHookDeletePolicies(o *uo.UnstructuredObject) ([]string, []error) {
+	var policies []string
+	var warnings []error
+	for _, p := range splitArgoCDAnnotation(o, ArgoCDHookDeletePolicyAnnotation) {
+		kp, ok := argoCDDeletePolicies[p]
+		if !ok {
+			warnings = append(warnings, fmt.Errorf("unsupported %s '%s'", ArgoCDHookDeletePolicyAnnotation, p))
+			continue
+		}
+		policies = append(policies, kp)
+	}
+	return policies, warnings
+}
+
+// GetArgoCDUnsupportedWarnings returns warnings for all Argo CD sync and compare options found on the object, as
+// these have no kluctl equivalent and are ignored.
+func GetArgoCDUnsupportedWarnings(o *uo.UnstructuredObject) []error {
+	var warnings []error
+	for _, a := range []string{ArgoCDSyncOptionsAnnotation, ArgoCDCompareOptionsAnnotation} {
+		for _, x := range splitArgoCDAnnotation(o, a) {
+			warnings = append(warnings, fmt.Errorf("%s '%s' has no kluctl equivalent and is ignored", a, x))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,29 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgoCDHooks(t *testing.T) {
+	o := newOrderTestObject("batch/v1", "Job", "job", "")
+	o.SetK8sAnnotation(ArgoCDHookAnnotation, "PreSync, PostSync,SyncFail,Unknown")
+	o.SetK8sAnnotation(ArgoCDHookDeletePolicyAnnotation, "HookSucceeded,BeforeHookCreation,Other")
+	o.SetK8sAnnotation(ArgoCDSyncOptionsAnnotation, "Prune=false,Replace=true")
+
+	hooks, warnings := GetArgoCDHooks(o)
+	assert.Equal(t, []string{"pre-deploy", "post-deploy", "sync-fail"}, hooks)
+	assert.Len(t, warnings, 2)
+
+	policies, warnings := GetArgoCDHookDeletePolicies(o)
+	assert.Equal(t, []string{"hook-succeeded", "before-hook-creation"}, policies)
+	assert.Len(t, warnings, 1)
+
+	assert.Len(t, GetArgoCDUnsupportedWarnings(o), 2)
+
+	hooks, warnings = GetArgoCDHooks(uo.New())
+	assert.Empty(t, hooks)
+	assert.Empty(t, warnings)
+}
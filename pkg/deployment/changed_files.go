@@ -0,0 +1,178 @@
+package deployment
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+)
+
+// ChangedFilesSelection is the result of mapping changed files to the affected deployment items
+type ChangedFilesSelection struct {
+	// Full is true if at least one changed file might affect all deployment items
+	Full bool
+	// Reason explains why a full render is required
+	Reason string
+	// DeploymentItemDirs contains the dirs of all affected deployment items, relative to the root source
+	DeploymentItemDirs []string
+}
+
+// SelectByChangedFiles maps the given changed files to the deployment items that are affected by them. Files must be
+// relative to the root of the source (usually the git repository) that contains the root deployment project.
+//
+// A file affects a deployment item if it is located inside the item's directory or if it is a vars file that is loaded
+// by the item. Files that belong to an included deployment project but not to one of its items (e.g. the
+// deployment.yaml or project level vars files) affect all items of the included project. Files that belong to the root
+// deployment project but not to one of its items, including .kluctl.yaml, affect all deployment items.
+func (c *DeploymentCollection) SelectByChangedFiles(changedFiles []string) ChangedFilesSelection {
+	rootDir := c.Project.source.dir
+
+	selected := map[*DeploymentItem]bool{}
+	for _, f := range changedFiles {
+		f = filepath.Clean(filepath.FromSlash(f))
+		absPath := filepath.Join(rootDir, f)
+		slashPath := filepath.ToSlash(f)
+
+		base := filepath.Base(f)
+		if base == ".kluctl.yaml" || base == ".kluctl.yml" {
+			return ChangedFilesSelection{Full: true, Reason: fmt.Sprintf("%s changed, which might affect all deployment items", slashPath)}
+		}
+
+		found := false
+		for _, di := range c.Deployments {
+			if di.dir != nil && isInDir(*di.dir, absPath) {
+				selected[di] = true
+				found = true
+			} else if matchesVarsFile(di.Project, di.Config.Vars, absPath) {
+				selected[di] = true
+				found = true
+			}
+		}
+		for _, p := range c.Project.getChildren(true, true) {
+			if !matchesVarsFile(p, p.Config.Vars, absPath) &&
+				(p.parentProjectInclude == nil || !matchesVarsFile(p.parentProject, p.parentProjectInclude.Vars, absPath)) {
+				continue
+			}
+			if p.parentProject == nil {
+				return ChangedFilesSelection{Full: true, Reason: fmt.Sprintf("%s is a shared vars file and might affect all deployment items", slashPath)}
+			}
+			for _, di := range c.Deployments {
+				if di.Project.isChildOf(p) {
+					selected[di] = true
+					found = true
+				}
+			}
+		}
+		if found {
+			continue
+		}
+
+		p := c.findInnermostProject(c.Project, absPath)
+		if p == nil {
+			// not part of the deployment project at all
+			continue
+		}
+		if p.parentProject == nil {
+			return ChangedFilesSelection{Full: true, Reason: fmt.Sprintf("%s is not part of a single deployment item and might affect all deployment items", slashPath)}
+		}
+		for _, di := range c.Deployments {
+			if di.Project.isChildOf(p) {
+				selected[di] = true
+			}
+		}
+	}
+
+	var ret ChangedFilesSelection
+	dirs := map[string]bool{}
+	for di := range selected {
+		if di.dir == nil {
+			continue
+		}
+		dirs[filepath.ToSlash(di.RelToSourceItemDir)] = true
+	}
+	for d := range dirs {
+		ret.DeploymentItemDirs = append(ret.DeploymentItemDirs, d)
+	}
+	sort.Strings(ret.DeploymentItemDirs)
+	return ret
+}
+
+// RestrictToDeploymentItemDirs removes all deployment items that are not part of the given dirs and replaces the
+// inclusion so that orphan detection is restricted to the same items.
+func (c *DeploymentCollection) RestrictToDeploymentItemDirs(dirs []string) {
+	inclusion := utils.NewInclusion()
+	for _, d := range c.Inclusion.GetExcludes("tag") {
+		inclusion.AddExclude("tag", d)
+	}
+	for _, d := range c.Inclusion.GetExcludes("deploymentItemDir") {
+		inclusion.AddExclude("deploymentItemDir", d)
+	}
+	for _, d := range dirs {
+		inclusion.AddInclude("deploymentItemDir", d)
+	}
+	if len(dirs) == 0 {
+		// nothing is affected, so make sure nothing is included
+		inclusion.AddInclude("deploymentItemDir", "")
+	}
+
+	c.Inclusion = inclusion
+	c.Partial = true
+	var deployments []*DeploymentItem
+	for _, di := range c.Deployments {
+		di.Inclusion = inclusion
+		if di.dir != nil && !di.CheckInclusionForDeploy() {
+			continue
+		}
+		deployments = append(deployments, di)
+	}
+	c.Deployments = deployments
+}
+
+func (c *DeploymentCollection) findInnermostProject(p *DeploymentProject, absPath string) *DeploymentProject {
+	if p.source != c.Project.source || !isInDir(p.absDir, absPath) {
+		return nil
+	}
+	for _, child := range p.includes {
+		if x := c.findInnermostProject(child, absPath); x != nil {
+			return x
+		}
+	}
+	return p
+}
+
+func (p *DeploymentProject) isChildOf(parent *DeploymentProject) bool {
+	for _, x := range p.getParents() {
+		if x.p == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesVarsFile checks if absPath is loaded by one of the file vars sources. Rendered (templated) file names can not
+// be resolved and are ignored.
+func matchesVarsFile(p *DeploymentProject, varsList []types.VarsSource, absPath string) bool {
+	for _, vs := range varsList {
+		if vs.File == nil || strings.Contains(*vs.File, "{{") {
+			continue
+		}
+		for _, dir := range p.getRenderSearchDirs() {
+			m, err := filepath.Match(filepath.Join(dir, *vs.File), absPath)
+			if err == nil && m {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isInDir(dir string, absPath string) bool {
+	rel, err := filepath.Rel(dir, absPath)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
@@ -0,0 +1,101 @@
+package deployment
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newChangedFilesTestCollection() *DeploymentCollection {
+	source := Source{id: "0", dir: "/repo"}
+	fileVars := func(f string) []types.VarsSource {
+		return []types.VarsSource{{File: utils.Ptr(f)}}
+	}
+
+	root := &DeploymentProject{
+		source:   source,
+		absDir:   "/repo/project",
+		includes: map[int]*DeploymentProject{},
+		Config: types.DeploymentProjectConfig{
+			Vars: fileVars("vars/common.yaml"),
+		},
+	}
+	sub := &DeploymentProject{
+		source:               source,
+		absDir:               "/repo/project/sub",
+		includes:             map[int]*DeploymentProject{},
+		parentProject:        root,
+		parentProjectInclude: &types.DeploymentItemConfig{Vars: fileVars("vars/sub.yaml")},
+	}
+	root.includes[2] = sub
+
+	item := func(p *DeploymentProject, dir string, vars []types.VarsSource) *DeploymentItem {
+		absDir := filepath.Join("/repo", dir)
+		return &DeploymentItem{
+			Project:            p,
+			Config:             &types.DeploymentItemConfig{Vars: vars},
+			dir:                &absDir,
+			RelToSourceItemDir: dir,
+			Tags:               &utils.OrderedMap[string, bool]{},
+		}
+	}
+
+	return &DeploymentCollection{
+		Project:   root,
+		Inclusion: utils.NewInclusion(),
+		Deployments: []*DeploymentItem{
+			item(root, "project/app-a", nil),
+			item(root, "project/app-b", fileVars("vars/app-b.yaml")),
+			item(sub, "project/sub/x", nil),
+			item(sub, "project/sub/y", nil),
+		},
+	}
+}
+
+func TestSelectByChangedFiles(t *testing.T) {
+	type testCase struct {
+		files        []string
+		expectedDirs []string
+		expectedFull string
+	}
+	tests := []testCase{
+		{files: []string{"project/app-a/deploy.yaml"}, expectedDirs: []string{"project/app-a"}},
+		{files: []string{"project/vars/app-b.yaml"}, expectedDirs: []string{"project/app-b"}},
+		{files: []string{"project/app-a/deploy.yaml", "project/sub/x/cm.yaml"}, expectedDirs: []string{"project/app-a", "project/sub/x"}},
+		{files: []string{"project/sub/deployment.yaml"}, expectedDirs: []string{"project/sub/x", "project/sub/y"}},
+		{files: []string{"project/vars/sub.yaml"}, expectedDirs: []string{"project/sub/x", "project/sub/y"}},
+		{files: []string{"other/README.md"}},
+		{files: []string{"project/vars/common.yaml"}, expectedFull: "project/vars/common.yaml is a shared vars file"},
+		{files: []string{"project/app-a/deploy.yaml", "project/.kluctl.yaml"}, expectedFull: "project/.kluctl.yaml changed"},
+		{files: []string{"project/deployment.yaml"}, expectedFull: "project/deployment.yaml is not part of a single deployment item"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.files[len(tc.files)-1], func(t *testing.T) {
+			c := newChangedFilesTestCollection()
+			sel := c.SelectByChangedFiles(tc.files)
+			if tc.expectedFull != "" {
+				assert.True(t, sel.Full)
+				assert.Contains(t, sel.Reason, tc.expectedFull)
+			} else {
+				assert.False(t, sel.Full)
+				assert.Equal(t, tc.expectedDirs, sel.DeploymentItemDirs)
+			}
+		})
+	}
+}
+
+func TestRestrictToDeploymentItemDirs(t *testing.T) {
+	c := newChangedFilesTestCollection()
+	c.Deployments = append(c.Deployments, &DeploymentItem{Config: &types.DeploymentItemConfig{Barrier: true}})
+	c.RestrictToDeploymentItemDirs([]string{"project/app-b"})
+
+	assert.True(t, c.Partial)
+	assert.Len(t, c.Deployments, 2)
+	assert.Equal(t, "project/app-b", c.Deployments[0].RelToSourceItemDir)
+	assert.True(t, c.Deployments[1].Config.Barrier)
+	assert.Equal(t, []string{"project/app-b"}, c.Inclusion.GetIncludes("deploymentItemDir"))
+}
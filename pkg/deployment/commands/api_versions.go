@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// warnUnservedApiVersions adds a warning for every rendered object with an API version that is not served by the
+// cluster, so that these are reported before any apply attempt instead of failing late or causing confusing diffs
+func warnUnservedApiVersions(ctx context.Context, k *k8s.K8sCluster, c *deployment.DeploymentCollection, dew *utils2.DeploymentErrorsAndWarnings) {
+	if k == nil {
+		return
+	}
+
+	ars, err := k.GetAllAPIResources()
+	if err != nil {
+		status.Warningf(ctx, "Failed to check for API versions not served by the cluster: %s", err.Error())
+		return
+	}
+	served := map[schema.GroupVersionKind]bool{}
+	for _, ar := range ars {
+		served[schema.GroupVersionKind{Group: ar.Group, Version: ar.Version, Kind: ar.Kind}] = true
+	}
+
+	unserved := utils2.FindUnservedApiVersions(c.LocalObjects(), served, c.IgnoreUnservedApiVersions())
+	if len(unserved) == 0 {
+		return
+	}
+
+	apiVersions := map[string]bool{}
+	for _, o := range unserved {
+		gvk := o.GetK8sGVK()
+		apiVersion := fmt.Sprintf("%s/%s", gvk.GroupVersion().String(), gvk.Kind)
+		apiVersions[apiVersion] = true
+		dew.AddWarning(o.GetK8sRef(), fmt.Errorf("apiVersion %s of kind %s is not served by this cluster", gvk.GroupVersion().String(), gvk.Kind))
+	}
+	var l []string
+	for x := range apiVersions {
+		l = append(l, x)
+	}
+	sort.Strings(l)
+	status.Warningf(ctx, "%d objects use API versions that are not served by this cluster: %s. Use ignoreUnservedApiVersions in .kluctl.yaml to silence known cases.", len(unserved), strings.Join(l, ", "))
+}
+
+// checkMissingKinds adds a single error listing all kinds that are neither served by the cluster nor defined by a CRD
+// of the same deployment, together with the deployment items that need them. Such objects would otherwise fail
+// midway through the deployment with NotFound errors. Returns true if the deployment must be aborted.
+func checkMissingKinds(ctx context.Context, k *k8s.K8sCluster, c *deployment.DeploymentCollection, dew *utils2.DeploymentErrorsAndWarnings) bool {
+	if k == nil {
+		return false
+	}
+
+	ars, err := k.GetAllAPIResources()
+	if err != nil {
+		status.Warningf(ctx, "Failed to check for kinds not served by the cluster: %s", err.Error())
+		return false
+	}
+	served := map[schema.GroupKind]bool{}
+	for _, ar := range ars {
+		served[schema.GroupKind{Group: ar.Group, Kind: ar.Kind}] = true
+	}
+
+	var objects []*uo.UnstructuredObject
+	itemsByObject := map[*uo.UnstructuredObject]string{}
+	for _, di := range c.Deployments {
+		for _, o := range di.Objects {
+			objects = append(objects, o)
+			itemsByObject[o] = di.RelToProjectItemDir
+		}
+	}
+
+	missing := utils2.FindMissingKinds(objects, served, c.DeferredKinds())
+	if len(missing) == 0 {
+		return false
+	}
+
+	itemsByKind := map[string]map[string]bool{}
+	for _, o := range missing {
+		gk := o.GetK8sGVK().GroupKind().String()
+		if itemsByKind[gk] == nil {
+			itemsByKind[gk] = map[string]bool{}
+		}
+		item := itemsByObject[o]
+		if item == "" {
+			item = "."
+		}
+		itemsByKind[gk][item] = true
+	}
+
+	var l []string
+	for gk, items := range itemsByKind {
+		var itemList []string
+		for item := range items {
+			itemList = append(itemList, item)
+		}
+		sort.Strings(itemList)
+		l = append(l, fmt.Sprintf("%s (needed by %s)", gk, strings.Join(itemList, ", ")))
+	}
+	sort.Strings(l)
+
+	dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("%d objects use kinds that are neither served by this cluster nor defined by a CRD of this deployment: %s. Install the missing CRDs first, or use the %s annotation or deferredKinds in .kluctl.yaml if the CRDs are installed by hooks",
+		len(missing), strings.Join(l, "; "), utils2.DeferCrdCheckAnnotation))
+	return true
+}
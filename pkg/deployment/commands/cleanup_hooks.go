@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"time"
+)
+
+// CleanupHooksCommand deletes hooks that were left behind by runs that got interrupted while executing hooks
+type CleanupHooksCommand struct {
+	discriminator string
+	targetCtx     *target_context.TargetContext
+	wait          bool
+
+	// OlderThan specifies how long ago a run must have been started before its hooks are considered to be left over.
+	// Hooks of more recent runs are not touched, as the run might still be active.
+	OlderThan time.Duration
+}
+
+func NewCleanupHooksCommand(discriminator string, targetCtx *target_context.TargetContext, wait bool) *CleanupHooksCommand {
+	return &CleanupHooksCommand{
+		discriminator: discriminator,
+		targetCtx:     targetCtx,
+		wait:          wait,
+	}
+}
+
+func (cmd *CleanupHooksCommand) Run(confirmCb func(refs []k8s2.ObjectRef) error) *result.CommandResult {
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+
+	r := newCommandResult(cmd.targetCtx, cmd.targetCtx.KluctlProject.LoadTime, "cleanup-hooks")
+
+	defer func() {
+		finishCommandResult(r, cmd.targetCtx, dew)
+	}()
+
+	discriminator := cmd.discriminator
+	if discriminator == "" && cmd.targetCtx != nil {
+		discriminator = cmd.targetCtx.Target.Discriminator
+	}
+	if discriminator == "" {
+		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("cleaning up hooks without a discriminator is not supported"))
+		return r
+	}
+
+	ru := utils2.NewRemoteObjectsUtil(cmd.targetCtx.SharedContext.Ctx, dew)
+	err := ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, &discriminator, cmd.targetCtx.DeploymentCollection.LocalObjectRefs(), false)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
+	leftovers := utils2.FindLeftoverHooks(ru.GetFilteredRemoteObjects(nil), "", time.Now().Add(-cmd.OlderThan))
+	var refs []k8s2.ObjectRef
+	for _, h := range leftovers {
+		refs = append(refs, h.Ref)
+	}
+
+	if confirmCb != nil {
+		err = confirmCb(refs)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
+	pruneStartTime := time.Now()
+	deleted := utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, refs, dew, cmd.wait)
+
+	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, nil, nil, nil, deleted)
+	r.Phases = newPrunePhase(pruneStartTime, deleted)
+
+	return r
+}
+
+// warnLeftoverHooks warns about hooks that were left behind by previous runs, which were most likely interrupted
+func warnLeftoverHooks(ctx context.Context, ru *utils2.RemoteObjectUtils, runId string, runStartTime time.Time, dew *utils2.DeploymentErrorsAndWarnings) {
+	leftovers := utils2.FindLeftoverHooks(ru.GetFilteredRemoteObjects(nil), runId, runStartTime)
+	for _, h := range leftovers {
+		what := "hook"
+		if h.HookOwned {
+			what = "object created by hook"
+		}
+		err := fmt.Errorf("%s %s was left behind by run %s (started at %s), which was probably interrupted. Use 'kluctl cleanup-hooks' to delete leftover hooks", what, h.Ref.String(), h.RunId, h.RunStarted.Format(time.RFC3339))
+		status.Warning(ctx, err.Error())
+		dew.AddWarning(h.Ref, err)
+	}
+}
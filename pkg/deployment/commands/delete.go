@@ -18,6 +18,9 @@ type DeleteCommand struct {
 	targetCtx     *target_context.TargetContext
 	inclusion     *utils.Inclusion
 	wait          bool
+
+	// DeletedManifests controls capturing of the manifests of deleted objects
+	DeletedManifests DeletedManifestsOptions
 }
 
 func NewDeleteCommand(discriminator string, targetCtx *target_context.TargetContext, inclusion *utils.Inclusion, wait bool) *DeleteCommand {
@@ -67,12 +70,25 @@ func (cmd *DeleteCommand) Run(ctx context.Context, k *k8s.K8sCluster, confirmCb
 		return r
 	}
 
-	deleteRefs, err := utils2.FindObjectsForDelete(k, ru.GetFilteredRemoteObjects(inclusion), inclusion.HasType("tags"), nil)
+	var order *deployment.ObjectOrder
+	if cmd.targetCtx != nil {
+		order = cmd.targetCtx.DeploymentCollection.Project.GetObjectOrder()
+	}
+
+	filteredObjects := ru.GetFilteredRemoteObjects(inclusion)
+	if cmd.targetCtx != nil {
+		filteredObjects = utils2.FilterOtherNamespaces(filteredObjects, cmd.targetCtx.DeploymentCollection.NamespaceOverride())
+	}
+	deleteRefs, err := utils2.FindObjectsForDelete(k, filteredObjects, inclusion.HasType("tags"), nil, order)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
 	}
 
+	for _, ref := range utils2.FindProtectedObjects(filteredObjects) {
+		dew.AddWarning(ref, utils2.ErrProtectedFromDeletion)
+	}
+
 	if confirmCb != nil {
 		err = confirmCb(deleteRefs)
 		if err != nil {
@@ -89,6 +105,9 @@ func (cmd *DeleteCommand) Run(ctx context.Context, k *k8s.K8sCluster, confirmCb
 	}
 
 	r.Objects = collectObjects(c, ru, nil, nil, nil, deleted)
+	if !k.DryRun {
+		captureDeletedManifests(r.Objects, ru, cmd.DeletedManifests, dew)
+	}
 
 	return r
 }
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+const DefaultMaxDeletedManifestSize = 256 * 1024
+
+// DeletedManifestsOptions controls how the last live manifests of deleted/pruned objects are captured into the
+// command result
+type DeletedManifestsOptions struct {
+	// Skip disables capturing completely
+	Skip bool
+	// MaxSize is the maximum size in bytes of a single captured manifest. Defaults to DefaultMaxDeletedManifestSize.
+	MaxSize int
+}
+
+// captureDeletedManifests stores the last live manifest of all deleted objects in the given result objects. Manifests
+// exceeding the size limit are not captured and a warning is added instead.
+func captureDeletedManifests(objects []result.ResultObject, ru *utils2.RemoteObjectUtils, opts DeletedManifestsOptions, dew *utils2.DeploymentErrorsAndWarnings) {
+	if opts.Skip {
+		return
+	}
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxDeletedManifestSize
+	}
+
+	for i := range objects {
+		o := &objects[i]
+		if !o.Deleted {
+			continue
+		}
+		live := o.Remote
+		if live == nil && ru != nil {
+			live = ru.GetRemoteObject(o.Ref)
+		}
+		if live == nil {
+			continue
+		}
+
+		m := buildDeletedManifest(live)
+		j, err := yaml.WriteJsonString(m)
+		if err != nil {
+			dew.AddWarning(o.Ref, fmt.Errorf("failed to capture manifest of deleted object: %w", err))
+			continue
+		}
+		if len(j) > maxSize {
+			dew.AddWarning(o.Ref, fmt.Errorf("manifest of deleted object was not captured as its size of %d bytes exceeds the limit of %d bytes", len(j), maxSize))
+			continue
+		}
+		o.DeletedManifest = m
+	}
+}
+
+// buildDeletedManifest returns a copy of the live object without all fields that are populated by the api server, so
+// that it can be re-applied later
+func buildDeletedManifest(live *uo.UnstructuredObject) *uo.UnstructuredObject {
+	m := live.Clone()
+	for _, f := range []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation", "selfLink",
+		"deletionTimestamp", "deletionGracePeriodSeconds", "ownerReferences"} {
+		_ = m.RemoveNestedField("metadata", f)
+	}
+	_ = m.RemoveNestedField("status")
+	return m
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildDeletedTestObjects() []result.ResultObject {
+	live := uo.FromStringMust(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  namespace: ns
+  uid: 1234
+  resourceVersion: "42"
+  creationTimestamp: "2024-01-01T00:00:00Z"
+  managedFields:
+  - manager: kluctl
+  ownerReferences:
+  - kind: Deployment
+    name: owner
+  labels:
+    a: b
+data:
+  a: b
+`)
+	return []result.ResultObject{
+		{BaseObject: result.BaseObject{Ref: live.GetK8sRef(), Deleted: true}, Remote: live},
+		{BaseObject: result.BaseObject{Ref: live.GetK8sRef(), Orphan: true}, Remote: live},
+	}
+}
+
+func TestCaptureDeletedManifests(t *testing.T) {
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+	objects := buildDeletedTestObjects()
+	captureDeletedManifests(objects, nil, DeletedManifestsOptions{}, dew)
+
+	assert.Empty(t, dew.GetWarningsList())
+	assert.Nil(t, objects[1].DeletedManifest)
+	assert.Equal(t, uo.FromStringMust(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  namespace: ns
+  labels:
+    a: b
+data:
+  a: b
+`), objects[0].DeletedManifest)
+
+	// the live object is left untouched
+	_, found, _ := objects[0].Remote.GetNestedField("metadata", "managedFields")
+	assert.True(t, found)
+}
+
+func TestCaptureDeletedManifestsLimits(t *testing.T) {
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+	objects := buildDeletedTestObjects()
+	captureDeletedManifests(objects, nil, DeletedManifestsOptions{Skip: true}, dew)
+	assert.Nil(t, objects[0].DeletedManifest)
+	assert.Empty(t, dew.GetWarningsList())
+
+	_ = objects[0].Remote.SetNestedField(strings.Repeat("x", 1000), "data", "large")
+	captureDeletedManifests(objects, nil, DeletedManifestsOptions{MaxSize: 500}, dew)
+	assert.Nil(t, objects[0].DeletedManifest)
+	warnings := dew.GetWarningsList()
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, "ns/ConfigMap/cm", warnings[0].Ref.String())
+		assert.Contains(t, warnings[0].Message, "exceeds the limit of 500 bytes")
+	}
+}
@@ -2,9 +2,12 @@ package commands
 
 import (
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/events"
 	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/results"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"time"
@@ -19,8 +22,25 @@ type DeployCommand struct {
 	AbortOnError        bool
 	ReadinessTimeout    time.Duration
 	NoWait              bool
+	NoProbes            bool
+	TakeOwnershipFrom   []string
 	Prune               bool
 	WaitPrune           bool
+	NoListNormalization bool
+
+	// ResultStore is used to detect discriminator collisions with other projects/targets. Detection is skipped when nil.
+	ResultStore results.ResultStore
+	// Force disables aborting on discriminator collisions
+	Force bool
+	// FailOnOwnershipConflict reports ownership conflicts with other targets as errors instead of warnings and aborts
+	// the deployment before anything is applied
+	FailOnOwnershipConflict bool
+	// OverrideSafetyThreshold disables aborting when the safety threshold of the target is exceeded
+	OverrideSafetyThreshold bool
+	// DeletedManifests controls capturing of the manifests of pruned objects
+	DeletedManifests DeletedManifestsOptions
+	// StaleFieldManagers specifies additional field managers that are considered stale
+	StaleFieldManagers []string
 }
 
 func NewDeployCommand(targetCtx *target_context.TargetContext) *DeployCommand {
@@ -38,6 +58,8 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 	r.Command.ForceReplaceOnError = cmd.ForceReplaceOnError
 	r.Command.AbortOnError = cmd.AbortOnError
 	r.Command.NoWait = cmd.NoWait
+	r.Command.OverrideSafetyThreshold = cmd.OverrideSafetyThreshold
+	r.Command.HookRunId = uuid.NewString()
 
 	defer func() {
 		finishCommandResult(r, cmd.targetCtx, dew)
@@ -55,6 +77,27 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 		return r
 	}
 
+	err = checkDiscriminatorCollision(cmd.ResultStore, ru, r)
+	if err != nil {
+		if !cmd.Force {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+		status.Warning(cmd.targetCtx.SharedContext.Ctx, err.Error())
+		dew.AddWarning(k8s2.ObjectRef{}, err)
+	}
+
+	if checkOwnershipConflicts(cmd.ResultStore, cmd.targetCtx.DeploymentCollection.LocalObjects(), ru, r, cmd.FailOnOwnershipConflict, dew) {
+		return r
+	}
+
+	warnLeftoverHooks(cmd.targetCtx.SharedContext.Ctx, ru, r.Command.HookRunId, r.Command.StartTime.Time, dew)
+	warnUnservedApiVersions(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, cmd.targetCtx.DeploymentCollection, dew)
+	if checkMissingKinds(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, cmd.targetCtx.DeploymentCollection, dew) {
+		return r
+	}
+	warnStaleOwnership(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.DeploymentCollection.LocalObjects(), ru, cmd.StaleFieldManagers, dew)
+
 	// prepare for a diff
 	o := &utils2.ApplyUtilOptions{
 		ForceApply:          cmd.ForceApply,
@@ -64,45 +107,91 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 		AbortOnError:        false,
 		ReadinessTimeout:    cmd.ReadinessTimeout,
 		NoWait:              cmd.NoWait,
+		NoProbes:            cmd.NoProbes,
+		TakeOwnershipFrom:   cmd.TakeOwnershipFrom,
+		HookRunId:           r.Command.HookRunId,
+		HookRunStartTime:    r.Command.StartTime.Time,
 	}
 
-	if diffResultCb != nil {
+	// the safety threshold requires a diff even if no diff callback is given
+	safetyThreshold := cmd.targetCtx.Target.SafetyThreshold
+	if cmd.OverrideSafetyThreshold {
+		safetyThreshold = nil
+	}
+
+	if diffResultCb != nil || safetyThreshold != nil {
+		diffStartTime := time.Now()
 		diffDew := dew.Clone()
-		au := utils2.NewApplyDeploymentsUtil(cmd.targetCtx.SharedContext.Ctx, diffDew, ru, cmd.targetCtx.SharedContext.K, o)
+		// the diff is only simulated, so it must not be reported as deployment events
+		au := utils2.NewApplyDeploymentsUtil(events.WithoutEvents(cmd.targetCtx.SharedContext.Ctx), diffDew, ru, cmd.targetCtx.SharedContext.K, o)
 		au.ApplyDeployments(cmd.targetCtx.DeploymentCollection.Deployments)
 
-		du := utils2.NewDiffUtil(diffDew, ru, au.GetAppliedObjectsMap())
+		du := utils2.NewDiffUtil(diffDew, ru, cmd.targetCtx.SharedContext.K, au.GetAppliedObjectsMap())
+		du.NoListNormalization = cmd.NoListNormalization
 		du.DiffDeploymentItems(cmd.targetCtx.DeploymentCollection.Deployments)
 
-		orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+		orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+		if err != nil {
+			// without the orphan objects, the safety threshold can't be checked reliably
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+		diffObjects := collectObjects(cmd.targetCtx.DeploymentCollection, ru, au, du, orphanObjects, nil)
+		resourceDelta := buildResourceDelta(diffObjects, diffDew)
 		diffResult := &result.CommandResult{
-			Objects:    collectObjects(cmd.targetCtx.DeploymentCollection, ru, au, du, orphanObjects, nil),
-			Errors:     diffDew.GetErrorsList(),
-			Warnings:   diffDew.GetWarningsList(),
-			SeenImages: cmd.targetCtx.DeploymentCollection.Images.SeenImages(false),
+			Objects:       diffObjects,
+			Errors:        diffDew.GetErrorsList(),
+			Warnings:      diffDew.GetWarningsList(),
+			SeenImages:    cmd.targetCtx.DeploymentCollection.Images.SeenImages(false),
+			RerunJobs:     au.GetRerunJobs(),
+			ResourceDelta: resourceDelta,
+
+			OwnershipConflicts: r.OwnershipConflicts,
 		}
+		r.Timings.Diff = durationSince(diffStartTime)
 
-		err = diffResultCb(diffResult)
+		deleteCount := 0
+		if cmd.Prune {
+			deleteCount = len(orphanObjects)
+		}
+		err = checkSafetyThreshold(safetyThreshold, countChangedObjects(diffResult.Objects), deleteCount)
 		if err != nil {
 			dew.AddError(k8s2.ObjectRef{}, err)
 			return r
 		}
+
+		if diffResultCb != nil {
+			err = diffResultCb(diffResult)
+			if err != nil {
+				dew.AddError(k8s2.ObjectRef{}, err)
+				return r
+			}
+		}
 	}
 
 	// modify options to become a deploy
 	o.DryRun = cmd.targetCtx.SharedContext.K.DryRun
 	o.AbortOnError = cmd.AbortOnError
+	o.RunDryRunSafeHooks = o.DryRun
 
+	applyStartTime := time.Now()
 	au := utils2.NewApplyDeploymentsUtil(cmd.targetCtx.SharedContext.Ctx, dew, ru, cmd.targetCtx.SharedContext.K, o)
 	au.ApplyDeployments(cmd.targetCtx.DeploymentCollection.Deployments)
+	r.Timings.Apply = durationSince(applyStartTime)
 
-	du := utils2.NewDiffUtil(dew, ru, au.GetAppliedObjectsMap())
+	du := utils2.NewDiffUtil(dew, ru, cmd.targetCtx.SharedContext.K, au.GetAppliedObjectsMap())
+	du.NoListNormalization = cmd.NoListNormalization
 	du.DiffDeploymentItems(cmd.targetCtx.DeploymentCollection.Deployments)
+	for _, co := range du.ChangedObjects {
+		ref := co.Ref
+		events.Emit(cmd.targetCtx.SharedContext.Ctx, events.Event{Type: events.ObjectChanged, Ref: &ref, Changes: len(co.Changes)})
+	}
 
 	var orphanObjects []k8s2.ObjectRef
 	var deleted []k8s2.ObjectRef
+	phases := au.GetPhases()
 
-	orphanObjects, err = FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+	orphanObjects, err = FindOrphanObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 	}
@@ -110,13 +199,22 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 	if cmd.Prune && cmd.targetCtx.Target.Discriminator == "" {
 		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("pruning without a discriminator is not supported"))
 	} else if cmd.Prune {
+		pruneStartTime := time.Now()
 		deleted = utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, orphanObjects, dew, cmd.WaitPrune)
+		phases = append(phases, newPrunePhase(pruneStartTime, deleted)...)
+		r.Timings.Prune = durationSince(pruneStartTime)
 
 		// now clean up the list of orphan objects (remove the ones that got deleted)
 		orphanObjects = filterDeletedOrphans(orphanObjects, deleted)
 	}
 
 	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, au, du, orphanObjects, deleted)
+	if !r.Command.DryRun {
+		captureDeletedManifests(r.Objects, ru, cmd.DeletedManifests, dew)
+	}
+	r.RerunJobs = au.GetRerunJobs()
+	r.Phases = phases
+	r.Timings.Hooks = sumPhaseDurations(phases, result.PhasePreDeployHooks, result.PhasePostDeployHooks)
 
 	return r
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"time"
 )
 
 type DiffCommand struct {
@@ -19,8 +20,12 @@ type DiffCommand struct {
 	IgnoreLabels         bool
 	IgnoreAnnotations    bool
 	IgnoreKluctlMetadata bool
+	NoListNormalization  bool
 
 	SkipResourceVersions map[k8s2.ObjectRef]string
+
+	// StaleFieldManagers specifies additional field managers that are considered stale
+	StaleFieldManagers []string
 }
 
 func NewDiffCommand(targetCtx *target_context.TargetContext) *DiffCommand {
@@ -53,6 +58,10 @@ func (cmd *DiffCommand) Run() *result.CommandResult {
 		return r
 	}
 
+	warnUnservedApiVersions(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, cmd.targetCtx.DeploymentCollection, dew)
+	warnStaleOwnership(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.DeploymentCollection.LocalObjects(), ru, cmd.StaleFieldManagers, dew)
+
+	diffStartTime := time.Now()
 	o := &utils.ApplyUtilOptions{
 		ForceApply:           cmd.ForceApply,
 		ReplaceOnError:       cmd.ReplaceOnError,
@@ -65,19 +74,22 @@ func (cmd *DiffCommand) Run() *result.CommandResult {
 	au := utils.NewApplyDeploymentsUtil(cmd.targetCtx.SharedContext.Ctx, dew, ru, cmd.targetCtx.SharedContext.K, o)
 	au.ApplyDeployments(cmd.targetCtx.DeploymentCollection.Deployments)
 
-	du := utils.NewDiffUtil(dew, ru, au.GetAppliedObjectsMap())
+	du := utils.NewDiffUtil(dew, ru, cmd.targetCtx.SharedContext.K, au.GetAppliedObjectsMap())
 	du.IgnoreTags = cmd.IgnoreTags
 	du.IgnoreLabels = cmd.IgnoreLabels
 	du.IgnoreAnnotations = cmd.IgnoreAnnotations
 	du.IgnoreKluctlMetadata = cmd.IgnoreKluctlMetadata
+	du.NoListNormalization = cmd.NoListNormalization
 	du.DiffDeploymentItems(cmd.targetCtx.DeploymentCollection.Deployments)
 
-	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
 	}
 	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, au, du, orphanObjects, nil)
+	r.RerunJobs = au.GetRerunJobs()
+	r.Timings.Diff = durationSince(diffStartTime)
 
 	return r
 }
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+// findDiscriminatorCollision returns the most recent stored command result that deployed objects with the same
+// discriminator to the same cluster, but from a different project or target. Only the most recent result is
+// considered, so that a collision that was resolved (e.g. by renaming a target and forcing a deployment) is not
+// reported again.
+func findDiscriminatorCollision(summaries []result.CommandResultSummary, r *result.CommandResult) *result.CommandResultSummary {
+	// summaries are sorted by start time, newest first
+	for _, s := range summaries {
+		if s.Command.DryRun || s.Id == r.Id {
+			continue
+		}
+		if s.TargetKey.Discriminator != r.TargetKey.Discriminator || s.TargetKey.ClusterId != r.TargetKey.ClusterId {
+			continue
+		}
+		if s.ProjectKey == r.ProjectKey && s.TargetKey.TargetName == r.TargetKey.TargetName {
+			return nil
+		}
+		return &s
+	}
+	return nil
+}
+
+// checkDiscriminatorCollision checks if live objects carrying the target's discriminator were deployed by a different
+// project/target combination, based on the command results found in the result store
+func checkDiscriminatorCollision(resultStore results.ResultStore, ru *utils2.RemoteObjectUtils, r *result.CommandResult) error {
+	if resultStore == nil || r.TargetKey.Discriminator == "" || r.TargetKey.ClusterId == "" {
+		return nil
+	}
+
+	liveObjects := 0
+	for _, o := range ru.GetFilteredRemoteObjects(nil) {
+		l := o.GetK8sLabel("kluctl.io/discriminator")
+		if l != nil && *l == r.TargetKey.Discriminator {
+			liveObjects++
+		}
+	}
+	if liveObjects == 0 {
+		return nil
+	}
+
+	summaries, err := resultStore.ListCommandResultSummaries(results.ListResultSummariesOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list command results for discriminator collision detection: %w", err)
+	}
+	s := findDiscriminatorCollision(summaries, r)
+	if s == nil {
+		return nil
+	}
+
+	project := s.ProjectKey.RepoKey.String()
+	if s.ProjectKey.SubDir != "" {
+		project += ":" + s.ProjectKey.SubDir
+	}
+	if project == "" {
+		project = "<unknown>"
+	}
+	return fmt.Errorf("discriminator collision: %d live objects carry the discriminator '%s', which was last deployed by project '%s' and target '%s' (command result %s). Deploying would cause both deployments to prune each other's objects. Use --force to deploy anyway",
+		liveObjects, r.TargetKey.Discriminator, project, s.TargetKey.TargetName, s.Id)
+}
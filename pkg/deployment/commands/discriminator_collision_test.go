@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"testing"
+
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDiscriminatorCollision(t *testing.T) {
+	project := gittypes.ProjectKey{RepoKey: gittypes.RepoKey{Host: "github.com", Path: "org/project"}}
+	otherProject := gittypes.ProjectKey{RepoKey: gittypes.RepoKey{Host: "github.com", Path: "org/other"}}
+
+	summary := func(id string, projectKey gittypes.ProjectKey, targetName string, discriminator string, dryRun bool) result.CommandResultSummary {
+		return result.CommandResultSummary{
+			Id:         id,
+			ProjectKey: projectKey,
+			TargetKey:  result.TargetKey{TargetName: targetName, ClusterId: "cluster", Discriminator: discriminator},
+			Command:    result.CommandInfo{DryRun: dryRun},
+		}
+	}
+
+	r := &result.CommandResult{
+		ProjectKey: project,
+		TargetKey:  result.TargetKey{TargetName: "prod", ClusterId: "cluster", Discriminator: "d"},
+	}
+
+	testCases := []struct {
+		name      string
+		summaries []result.CommandResultSummary
+		expected  string
+	}{
+		{name: "empty"},
+		{name: "same target", summaries: []result.CommandResultSummary{summary("1", project, "prod", "d", false)}},
+		{name: "other discriminator", summaries: []result.CommandResultSummary{summary("1", otherProject, "prod", "other", false)}},
+		{name: "other project", summaries: []result.CommandResultSummary{summary("1", otherProject, "prod", "d", false)}, expected: "1"},
+		{name: "other target", summaries: []result.CommandResultSummary{summary("1", project, "test", "d", false)}, expected: "1"},
+		{name: "dry-run ignored", summaries: []result.CommandResultSummary{summary("1", project, "test", "d", true)}},
+		{name: "resolved collision", summaries: []result.CommandResultSummary{
+			summary("2", project, "prod", "d", false),
+			summary("1", project, "test", "d", false),
+		}},
+		{name: "newest wins", summaries: []result.CommandResultSummary{
+			summary("2", project, "test", "d", false),
+			summary("1", project, "prod", "d", false),
+		}, expected: "2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := findDiscriminatorCollision(tc.summaries, r)
+			if tc.expected == "" {
+				assert.Nil(t, s)
+			} else {
+				assert.NotNil(t, s)
+				assert.Equal(t, tc.expected, s.Id)
+			}
+		})
+	}
+}
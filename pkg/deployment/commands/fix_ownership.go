@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/status"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StaleOwnership describes the fields of a single object that are owned by stale field managers
+type StaleOwnership struct {
+	Ref k8s2.ObjectRef
+	*diff.StaleFieldOwnership
+}
+
+// FixOwnershipCommand migrates ownership of fields that are owned by stale field managers to the kluctl field manager
+// and removes fields that are not rendered anymore
+type FixOwnershipCommand struct {
+	targetCtx *target_context.TargetContext
+	dryRun    bool
+
+	// StaleFieldManagers specifies additional field managers that are considered stale
+	StaleFieldManagers []string
+}
+
+func NewFixOwnershipCommand(targetCtx *target_context.TargetContext, dryRun bool) *FixOwnershipCommand {
+	return &FixOwnershipCommand{
+		targetCtx: targetCtx,
+		dryRun:    dryRun,
+	}
+}
+
+func (cmd *FixOwnershipCommand) Run(confirmCb func(findings []StaleOwnership) error) *result.CommandResult {
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+
+	r := newCommandResult(cmd.targetCtx, cmd.targetCtx.KluctlProject.LoadTime, "fix-ownership")
+
+	defer func() {
+		finishCommandResult(r, cmd.targetCtx, dew)
+	}()
+
+	ru := utils2.NewRemoteObjectsUtil(cmd.targetCtx.SharedContext.Ctx, dew)
+	err := ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, &cmd.targetCtx.Target.Discriminator, cmd.targetCtx.DeploymentCollection.LocalObjectRefs(), false)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
+	findings := findStaleOwnership(cmd.targetCtx.DeploymentCollection.LocalObjects(), ru, cmd.StaleFieldManagers, dew)
+
+	if confirmCb != nil {
+		err = confirmCb(findings)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
+	startTime := time.Now()
+	var fixed []k8s2.ObjectRef
+	for _, f := range findings {
+		patch, err := f.BuildPatch(startTime)
+		if err != nil {
+			dew.AddError(f.Ref, fmt.Errorf("failed to build ownership fix patch: %w", err))
+			continue
+		}
+		_, apiWarnings, err := cmd.targetCtx.SharedContext.K.JsonPatchObject(ru.GetRemoteObject(f.Ref), patch, k8s.PatchOptions{ForceDryRun: cmd.dryRun})
+		dew.AddApiWarnings(f.Ref, apiWarnings)
+		if err != nil {
+			dew.AddError(f.Ref, fmt.Errorf("failed to fix ownership: %w", err))
+			continue
+		}
+		fixed = append(fixed, f.Ref)
+	}
+
+	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, nil, nil, nil, nil)
+	if len(fixed) != 0 {
+		r.Phases = []result.Phase{{
+			Type:      result.PhaseApply,
+			StartTime: metav1.NewMicroTime(startTime),
+			EndTime:   metav1.NowMicro(),
+			Objects:   fixed,
+		}}
+	}
+
+	return r
+}
+
+// findStaleOwnership returns all local objects for which the live object has fields owned by stale field managers
+func findStaleOwnership(localObjects []*uo.UnstructuredObject, ru *utils2.RemoteObjectUtils, extraManagers []string, dew *utils2.DeploymentErrorsAndWarnings) []StaleOwnership {
+	var ret []StaleOwnership
+	for _, o := range localObjects {
+		ref := o.GetK8sRef()
+		remote := ru.GetRemoteObject(ref)
+		if remote == nil {
+			continue
+		}
+		s, err := diff.FindStaleFieldOwnership(o, remote, utils2.KluctlFieldManager, extraManagers)
+		if err != nil {
+			dew.AddWarning(ref, fmt.Errorf("failed to check for stale field ownership: %w", err))
+			continue
+		}
+		if s == nil {
+			continue
+		}
+		ret = append(ret, StaleOwnership{Ref: ref, StaleFieldOwnership: s})
+	}
+	return ret
+}
+
+// warnStaleOwnership warns about objects with fields owned by stale field managers, which are not pruned by
+// server-side apply anymore
+func warnStaleOwnership(ctx context.Context, localObjects []*uo.UnstructuredObject, ru *utils2.RemoteObjectUtils, extraManagers []string, dew *utils2.DeploymentErrorsAndWarnings) {
+	for _, f := range findStaleOwnership(localObjects, ru, extraManagers, dew) {
+		err := fmt.Errorf("%d fields are owned by the stale field managers %s, of which %d are not rendered anymore. Removed fields are not pruned until ownership is fixed via 'kluctl fix-ownership'",
+			len(f.MigratedFields)+len(f.RemovedFields), strings.Join(f.Managers, ", "), len(f.RemovedFields))
+		status.Warningf(ctx, "%s: %s", f.Ref.String(), err.Error())
+		dew.AddWarning(f.Ref, err)
+	}
+}
@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/helm"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"helm.sh/helm/v3/pkg/registry"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// HelmAdoptCommand takes over a Helm release that was installed via the Helm CLI into a deployment item
+type HelmAdoptCommand struct {
+	targetCtx *target_context.TargetContext
+
+	ReleaseName string
+	Namespace   string
+	// ItemDir is the directory of the deployment item, relative to the project dir
+	ItemDir string
+	// Repo is the Helm repository of the chart, which is not recorded by Helm
+	Repo string
+	// DeleteReleaseMetadata causes the Helm storage Secrets of the release to be deleted
+	DeleteReleaseMetadata bool
+}
+
+type HelmAdoptFile struct {
+	Path    string
+	Content *uo.UnstructuredObject
+}
+
+// HelmAdoptPlan describes all changes that are performed when adopting a Helm release
+type HelmAdoptPlan struct {
+	Files []HelmAdoptFile
+
+	Objects     []k8s.ObjectRef
+	Labels      map[string]string
+	Annotations map[string]string
+
+	DeleteRefs []k8s.ObjectRef
+
+	Warnings []string
+}
+
+func NewHelmAdoptCommand(targetCtx *target_context.TargetContext) *HelmAdoptCommand {
+	return &HelmAdoptCommand{
+		targetCtx: targetCtx,
+	}
+}
+
+func (cmd *HelmAdoptCommand) findDeploymentItem() (*deployment.DeploymentItem, string, error) {
+	repoRoot, err := filepath.Abs(cmd.targetCtx.KluctlProject.LoadArgs.RepoRoot)
+	if err != nil {
+		return nil, "", err
+	}
+	projectDir, err := filepath.Abs(cmd.targetCtx.KluctlProject.LoadArgs.ProjectDir)
+	if err != nil {
+		return nil, "", err
+	}
+	absItemDir := filepath.Join(projectDir, cmd.ItemDir)
+	relItemDir, err := filepath.Rel(repoRoot, absItemDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, di := range cmd.targetCtx.DeploymentCollection.Deployments {
+		if di.Config.Path != nil && di.RelToSourceItemDir == relItemDir {
+			return di, absItemDir, nil
+		}
+	}
+	return nil, "", fmt.Errorf("deployment item '%s' not found, make sure it is referenced from a deployment.yaml", cmd.ItemDir)
+}
+
+// Plan reads the Helm release and determines all changes required to adopt it
+func (cmd *HelmAdoptCommand) Plan() (*HelmAdoptPlan, error) {
+	k := cmd.targetCtx.SharedContext.K
+	if k == nil {
+		return nil, fmt.Errorf("adopting Helm releases requires a Kubernetes cluster")
+	}
+	if cmd.Repo == "" {
+		return nil, fmt.Errorf("the chart repository must be specified, as Helm does not record it in the release")
+	}
+
+	di, absItemDir, err := cmd.findDeploymentItem()
+	if err != nil {
+		return nil, err
+	}
+	if yaml.Exists(filepath.Join(absItemDir, "helm-chart.yaml")) {
+		return nil, fmt.Errorf("deployment item '%s' already contains a helm-chart.yaml", cmd.ItemDir)
+	}
+
+	ir, err := helm.LoadInstalledRelease(k, cmd.Namespace, cmd.ReleaseName)
+	if err != nil {
+		return nil, err
+	}
+	if ir.Release.Chart == nil || ir.Release.Chart.Metadata == nil {
+		return nil, fmt.Errorf("helm release %s/%s contains no chart metadata", cmd.Namespace, cmd.ReleaseName)
+	}
+
+	plan := &HelmAdoptPlan{
+		Labels:      di.GetCommonLabels(),
+		Annotations: di.GetCommonAnnotations(),
+	}
+
+	helmChart := map[string]interface{}{
+		"repo":         cmd.Repo,
+		"chartVersion": ir.Release.Chart.Metadata.Version,
+		"releaseName":  ir.Release.Name,
+		"namespace":    ir.Release.Namespace,
+	}
+	if !registry.IsOCI(cmd.Repo) {
+		helmChart["chartName"] = ir.Release.Chart.Metadata.Name
+	}
+	plan.Files = append(plan.Files, HelmAdoptFile{
+		Path:    filepath.Join(absItemDir, "helm-chart.yaml"),
+		Content: uo.FromMap(map[string]interface{}{"helmChart": helmChart}),
+	})
+	if len(ir.Release.Config) != 0 {
+		plan.Files = append(plan.Files, HelmAdoptFile{
+			Path:    filepath.Join(absItemDir, "helm-values.yaml"),
+			Content: uo.FromMap(ir.Release.Config),
+		})
+	}
+
+	ky := yaml.FixPathExt(filepath.Join(absItemDir, "kustomization.yml"))
+	if utils.IsFile(ky) {
+		o, err := uo.FromFile(ky)
+		if err != nil {
+			return nil, err
+		}
+		resources, _, _ := o.GetNestedStringList("resources")
+		found := false
+		for _, r := range resources {
+			if r == "helm-rendered.yaml" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s does not include helm-rendered.yaml, make sure to add it to the resources list", ky))
+		}
+	}
+
+	plan.Objects, err = ir.ObjectRefs(k)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.DeleteReleaseMetadata {
+		plan.DeleteRefs = ir.StorageRefs
+	}
+	return plan, nil
+}
+
+// Apply writes the files of the plan, adds the tracking metadata to the live objects and optionally deletes the Helm
+// release metadata. Objects that do not exist anymore are skipped with a warning.
+func (cmd *HelmAdoptCommand) Apply(plan *HelmAdoptPlan) error {
+	ctx := cmd.targetCtx.SharedContext.Ctx
+	k := cmd.targetCtx.SharedContext.K
+
+	for _, f := range plan.Files {
+		err := yaml.WriteYamlFile(f.Path, f.Content)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ref := range plan.Objects {
+		// check for existence first, as applying would otherwise create an empty object
+		_, _, err := k.GetSingleObjectMetadata(ref)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				status.Warningf(ctx, "%s does not exist anymore, skipping it", ref.String())
+				continue
+			}
+			return err
+		}
+
+		o := uo.New()
+		o.SetK8sGVK(ref.GroupVersionKind())
+		o.SetK8sName(ref.Name)
+		o.SetK8sNamespace(ref.Namespace)
+		o.SetK8sLabels(plan.Labels)
+		o.SetK8sAnnotations(plan.Annotations)
+
+		_, apiWarnings, err := k.ApplyObject(o, k8s2.PatchOptions{})
+		for _, w := range apiWarnings {
+			status.Warningf(ctx, "%s: %s", ref.String(), w.Text)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ref := range plan.DeleteRefs {
+		_, err := k.DeleteSingleObject(ref, k8s2.DeleteOptions{NoWait: true, IgnoreNotFoundError: true})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
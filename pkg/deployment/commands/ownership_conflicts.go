@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"fmt"
+
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// ownershipTakeoverAnnotation marks objects that are intentionally taken over from other targets, which silences
+// ownership conflicts for these objects
+const ownershipTakeoverAnnotation = "kluctl.io/ownership-takeover"
+
+// findOwnershipConflicts returns all local objects for which the live object carries the discriminator of a different
+// target
+func findOwnershipConflicts(localObjects []*uo.UnstructuredObject, ru *utils2.RemoteObjectUtils, discriminator string) []result.OwnershipConflict {
+	if discriminator == "" {
+		return nil
+	}
+
+	var ret []result.OwnershipConflict
+	for _, o := range localObjects {
+		if o.GetK8sAnnotationBoolNoError(ownershipTakeoverAnnotation, false) {
+			continue
+		}
+		ref := o.GetK8sRef()
+		remote := ru.GetRemoteObject(ref)
+		if remote == nil {
+			continue
+		}
+		l := remote.GetK8sLabel("kluctl.io/discriminator")
+		if l == nil || *l == "" || *l == discriminator {
+			continue
+		}
+		ret = append(ret, result.OwnershipConflict{
+			Ref:                ref,
+			OtherDiscriminator: *l,
+		})
+	}
+	return ret
+}
+
+// resolveOwnershipConflictTargets fills in the other target and its most recent command result by looking up the
+// most recent stored command result that used the other discriminator on the same cluster
+func resolveOwnershipConflictTargets(summaries []result.CommandResultSummary, clusterId string, conflicts []result.OwnershipConflict) {
+	for i := range conflicts {
+		c := &conflicts[i]
+		// summaries are sorted by start time, newest first
+		for _, s := range summaries {
+			if s.Command.DryRun || s.TargetKey.ClusterId != clusterId || s.TargetKey.Discriminator != c.OtherDiscriminator {
+				continue
+			}
+			c.OtherTarget = s.TargetKey.TargetName
+			c.OtherResultId = s.Id
+			break
+		}
+	}
+}
+
+// checkOwnershipConflicts records all ownership conflicts in the command result and reports them as warnings, or as
+// errors if failOnConflict is set. It returns true if errors were reported.
+func checkOwnershipConflicts(resultStore results.ResultStore, localObjects []*uo.UnstructuredObject, ru *utils2.RemoteObjectUtils, r *result.CommandResult, failOnConflict bool, dew *utils2.DeploymentErrorsAndWarnings) bool {
+	conflicts := findOwnershipConflicts(localObjects, ru, r.TargetKey.Discriminator)
+	if len(conflicts) == 0 {
+		return false
+	}
+
+	if resultStore != nil && r.TargetKey.ClusterId != "" {
+		summaries, err := resultStore.ListCommandResultSummaries(results.ListResultSummariesOptions{})
+		if err != nil {
+			dew.AddWarning(conflicts[0].Ref, fmt.Errorf("failed to list command results to determine the other targets of ownership conflicts: %w", err))
+		} else {
+			resolveOwnershipConflictTargets(summaries, r.TargetKey.ClusterId, conflicts)
+		}
+	}
+
+	r.OwnershipConflicts = conflicts
+	for _, c := range conflicts {
+		err := buildOwnershipConflictError(c)
+		if failOnConflict {
+			dew.AddError(c.Ref, err)
+		} else {
+			dew.AddWarning(c.Ref, err)
+		}
+	}
+	return failOnConflict
+}
+
+func buildOwnershipConflictError(c result.OwnershipConflict) error {
+	other := fmt.Sprintf("the target with discriminator '%s'", c.OtherDiscriminator)
+	if c.OtherTarget != "" {
+		other = fmt.Sprintf("target '%s' (discriminator '%s', command result %s)", c.OtherTarget, c.OtherDiscriminator, c.OtherResultId)
+	}
+	return fmt.Errorf("ownership conflict: the object is also managed by %s. Deploying both targets causes the object to flip-flop between them. Add the %s annotation to take over ownership intentionally",
+		other, ownershipTakeoverAnnotation)
+}
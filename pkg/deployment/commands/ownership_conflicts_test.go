@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildOwnershipTestObject(name string, discriminator string, takeover bool) *uo.UnstructuredObject {
+	o := uo.New()
+	o.SetK8sGVKs("", "v1", "Namespace")
+	o.SetK8sName(name)
+	if discriminator != "" {
+		o.SetK8sLabel("kluctl.io/discriminator", discriminator)
+	}
+	if takeover {
+		o.SetK8sAnnotation(ownershipTakeoverAnnotation, "true")
+	}
+	return o
+}
+
+func TestFindOwnershipConflicts(t *testing.T) {
+	ru := utils2.NewRemoteObjectsUtil(context.Background(), utils2.NewDeploymentErrorsAndWarnings())
+	ru.AddRemoteObjects([]*uo.UnstructuredObject{
+		buildOwnershipTestObject("same", "staging", false),
+		buildOwnershipTestObject("other", "staging-eu", false),
+		buildOwnershipTestObject("takeover", "staging-eu", false),
+		buildOwnershipTestObject("unlabeled", "", false),
+	})
+	local := []*uo.UnstructuredObject{
+		buildOwnershipTestObject("same", "staging", false),
+		buildOwnershipTestObject("other", "staging", false),
+		buildOwnershipTestObject("takeover", "staging", true),
+		buildOwnershipTestObject("unlabeled", "staging", false),
+		buildOwnershipTestObject("new", "staging", false),
+	}
+
+	conflicts := findOwnershipConflicts(local, ru, "staging")
+	assert.Equal(t, []result.OwnershipConflict{
+		{Ref: local[1].GetK8sRef(), OtherDiscriminator: "staging-eu"},
+	}, conflicts)
+
+	assert.Empty(t, findOwnershipConflicts(local, ru, ""))
+
+	summaries := []result.CommandResultSummary{
+		{Id: "3", TargetKey: result.TargetKey{TargetName: "staging-eu", ClusterId: "cluster", Discriminator: "staging-eu"}, Command: result.CommandInfo{DryRun: true}},
+		{Id: "2", TargetKey: result.TargetKey{TargetName: "staging-eu", ClusterId: "other-cluster", Discriminator: "staging-eu"}},
+		{Id: "1", TargetKey: result.TargetKey{TargetName: "staging-eu", ClusterId: "cluster", Discriminator: "staging-eu"}},
+	}
+	resolveOwnershipConflictTargets(summaries, "cluster", conflicts)
+	assert.Equal(t, "staging-eu", conflicts[0].OtherTarget)
+	assert.Equal(t, "1", conflicts[0].OtherResultId)
+}
+
+func TestCheckOwnershipConflicts(t *testing.T) {
+	ru := utils2.NewRemoteObjectsUtil(context.Background(), utils2.NewDeploymentErrorsAndWarnings())
+	ru.AddRemoteObjects([]*uo.UnstructuredObject{buildOwnershipTestObject("other", "staging-eu", false)})
+	local := []*uo.UnstructuredObject{buildOwnershipTestObject("other", "staging", false)}
+
+	r := &result.CommandResult{TargetKey: result.TargetKey{Discriminator: "staging"}}
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+	assert.False(t, checkOwnershipConflicts(nil, local, ru, r, false, dew))
+	assert.Len(t, r.OwnershipConflicts, 1)
+	assert.Empty(t, dew.GetErrorsList())
+	if assert.Len(t, dew.GetWarningsList(), 1) {
+		assert.Contains(t, dew.GetWarningsList()[0].Message, "also managed by the target with discriminator 'staging-eu'")
+	}
+
+	r = &result.CommandResult{TargetKey: result.TargetKey{Discriminator: "staging"}}
+	dew = utils2.NewDeploymentErrorsAndWarnings()
+	assert.True(t, checkOwnershipConflicts(nil, local, ru, r, true, dew))
+	assert.Len(t, dew.GetErrorsList(), 1)
+	assert.Empty(t, dew.GetWarningsList())
+}
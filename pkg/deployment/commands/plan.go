@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+// PlanResult holds the plan of a deployment together with all warnings and errors found while building it
+type PlanResult struct {
+	Plan     *utils.ApplyPlan         `json:"plan"`
+	Warnings []result.DeploymentError `json:"warnings,omitempty"`
+	Errors   []result.DeploymentError `json:"errors,omitempty"`
+}
+
+// PlanCommand determines the ordered sequence of operations a deploy would perform, without applying or diffing
+// anything
+type PlanCommand struct {
+	targetCtx *target_context.TargetContext
+
+	NoWait bool
+	Prune  bool
+}
+
+func NewPlanCommand(targetCtx *target_context.TargetContext) *PlanCommand {
+	return &PlanCommand{
+		targetCtx: targetCtx,
+	}
+}
+
+func (cmd *PlanCommand) Run() *PlanResult {
+	dew := utils.NewDeploymentErrorsAndWarnings()
+	r := &PlanResult{}
+
+	defer func() {
+		r.Warnings = dew.GetWarningsList()
+		r.Errors = dew.GetErrorsList()
+	}()
+
+	if cmd.Prune && cmd.targetCtx.Target.Discriminator == "" {
+		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("pruning without a discriminator is not supported"))
+		return r
+	}
+	if cmd.targetCtx.SharedContext.K == nil {
+		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("can not plan a deployment without a Kubernetes API client"))
+		return r
+	}
+
+	ru := utils.NewRemoteObjectsUtil(cmd.targetCtx.SharedContext.Ctx, dew)
+	err := ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, &cmd.targetCtx.Target.Discriminator, cmd.targetCtx.DeploymentCollection.LocalObjectRefs(), false)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
+	o := &utils.ApplyUtilOptions{
+		DryRun: true,
+		NoWait: cmd.NoWait,
+	}
+	au := utils.NewApplyDeploymentsUtil(cmd.targetCtx.SharedContext.Ctx, dew, ru, cmd.targetCtx.SharedContext.K, o)
+	r.Plan = au.BuildPlan(cmd.targetCtx.DeploymentCollection.Deployments)
+
+	if cmd.Prune {
+		orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+		r.Plan.AddPruneStep(orphanObjects)
+	}
+
+	return r
+}
@@ -97,10 +97,10 @@ func (cmd *PokeImagesCommand) Run() *result.CommandResult {
 	}
 	wg.Wait()
 
-	du := utils2.NewDiffUtil(dew, ru, au.GetAppliedObjectsMap())
+	du := utils2.NewDiffUtil(dew, ru, cmd.targetCtx.SharedContext.K, au.GetAppliedObjectsMap())
 	du.DiffDeploymentItems(cmd.targetCtx.DeploymentCollection.Deployments)
 
-	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
 	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
@@ -8,12 +9,18 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"time"
 )
 
 type PruneCommand struct {
 	discriminator string
 	targetCtx     *target_context.TargetContext
 	wait          bool
+
+	// OverrideSafetyThreshold disables aborting when the safety threshold of the target is exceeded
+	OverrideSafetyThreshold bool
+	// DeletedManifests controls capturing of the manifests of pruned objects
+	DeletedManifests DeletedManifestsOptions
 }
 
 func NewPruneCommand(discriminator string, targetCtx *target_context.TargetContext, wait bool) *PruneCommand {
@@ -28,6 +35,7 @@ func (cmd *PruneCommand) Run(confirmCb func(refs []k8s2.ObjectRef) error) *resul
 	dew := utils2.NewDeploymentErrorsAndWarnings()
 
 	r := newCommandResult(cmd.targetCtx, cmd.targetCtx.KluctlProject.LoadTime, "prune")
+	r.Command.OverrideSafetyThreshold = cmd.OverrideSafetyThreshold
 
 	defer func() {
 		finishCommandResult(r, cmd.targetCtx, dew)
@@ -49,12 +57,20 @@ func (cmd *PruneCommand) Run(confirmCb func(refs []k8s2.ObjectRef) error) *resul
 		return r
 	}
 
-	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
 	}
 
+	if !cmd.OverrideSafetyThreshold {
+		err = checkSafetyThreshold(cmd.targetCtx.Target.SafetyThreshold, 0, len(orphanObjects))
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
 	if confirmCb != nil {
 		err = confirmCb(orphanObjects)
 		if err != nil {
@@ -63,14 +79,26 @@ func (cmd *PruneCommand) Run(confirmCb func(refs []k8s2.ObjectRef) error) *resul
 		}
 	}
 
+	pruneStartTime := time.Now()
 	deleted := utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, orphanObjects, dew, cmd.wait)
 	orphanObjects = filterDeletedOrphans(orphanObjects, deleted)
 
 	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, nil, nil, orphanObjects, deleted)
+	if !r.Command.DryRun {
+		captureDeletedManifests(r.Objects, ru, cmd.DeletedManifests, dew)
+	}
+	r.Phases = newPrunePhase(pruneStartTime, deleted)
+	r.Timings.Prune = durationSince(pruneStartTime)
 
 	return r
 }
 
-func FindOrphanObjects(k *k8s.K8sCluster, ru *utils2.RemoteObjectUtils, c *deployment.DeploymentCollection) ([]k8s2.ObjectRef, error) {
-	return utils2.FindObjectsForDelete(k, ru.GetFilteredRemoteObjects(c.Inclusion), c.Inclusion.HasType("tags"), c.LocalObjectRefs())
+// FindOrphanObjects returns all remote objects that are not part of the deployment collection anymore. Objects matching
+// a prune exclusion rule are never considered to be orphans. When the target has a namespace override, only namespaced
+// objects in the override namespace are considered.
+func FindOrphanObjects(ctx context.Context, k *k8s.K8sCluster, ru *utils2.RemoteObjectUtils, c *deployment.DeploymentCollection) ([]k8s2.ObjectRef, error) {
+	objects := utils2.FilterPruneExcluded(ctx, ru.GetFilteredRemoteObjects(c.Inclusion), c.PruneExclude())
+	objects = utils2.FilterOtherNamespaces(objects, c.NamespaceOverride())
+	objects = utils2.FilterHookOwned(objects)
+	return utils2.FindObjectsForDelete(k, objects, c.Inclusion.HasType("tags"), c.LocalObjectRefs(), c.Project.GetObjectOrder())
 }
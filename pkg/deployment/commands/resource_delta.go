@@ -0,0 +1,296 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+)
+
+// workloadPodSpecPaths contains the paths to the pod specs of all workload kinds that are considered when calculating
+// resource deltas
+var workloadPodSpecPaths = map[schema.GroupKind][]any{
+	{Kind: "Pod"}:                        {"spec"},
+	{Kind: "ReplicationController"}:      {"spec", "template", "spec"},
+	{Group: "apps", Kind: "Deployment"}:  {"spec", "template", "spec"},
+	{Group: "apps", Kind: "ReplicaSet"}:  {"spec", "template", "spec"},
+	{Group: "apps", Kind: "StatefulSet"}: {"spec", "template", "spec"},
+	{Group: "apps", Kind: "DaemonSet"}:   {"spec", "template", "spec"},
+	{Group: "batch", Kind: "Job"}:        {"spec", "template", "spec"},
+	{Group: "batch", Kind: "CronJob"}:    {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// resourceQuantities holds CPU quantities in milli cores and memory quantities in bytes
+type resourceQuantities struct {
+	cpuRequests    int64
+	cpuLimits      int64
+	memoryRequests int64
+	memoryLimits   int64
+}
+
+func (q *resourceQuantities) add(o resourceQuantities, factor int64) {
+	q.cpuRequests += o.cpuRequests * factor
+	q.cpuLimits += o.cpuLimits * factor
+	q.memoryRequests += o.memoryRequests * factor
+	q.memoryLimits += o.memoryLimits * factor
+}
+
+// max sets each quantity to the maximum of q and o
+func (q *resourceQuantities) max(o resourceQuantities) {
+	q.cpuRequests = max(q.cpuRequests, o.cpuRequests)
+	q.cpuLimits = max(q.cpuLimits, o.cpuLimits)
+	q.memoryRequests = max(q.memoryRequests, o.memoryRequests)
+	q.memoryLimits = max(q.memoryLimits, o.memoryLimits)
+}
+
+func (q resourceQuantities) toResult() result.ResourceQuantities {
+	return result.ResourceQuantities{
+		CpuRequests:    *resource.NewMilliQuantity(q.cpuRequests, resource.DecimalSI),
+		CpuLimits:      *resource.NewMilliQuantity(q.cpuLimits, resource.DecimalSI),
+		MemoryRequests: *resource.NewQuantity(q.memoryRequests, resource.BinarySI),
+		MemoryLimits:   *resource.NewQuantity(q.memoryLimits, resource.BinarySI),
+	}
+}
+
+// buildHpaMinReplicas returns the minimum replicas of all objects scaled by one of the given HorizontalPodAutoscalers,
+// keyed by the version-less refs of the scaled objects
+func buildHpaMinReplicas(objects []*uo.UnstructuredObject) map[k8s.ObjectRef]int64 {
+	ret := map[k8s.ObjectRef]int64{}
+	for _, o := range objects {
+		ref, ok := utils.GetHpaTargetRef(o)
+		if !ok {
+			continue
+		}
+		minReplicas, found, err := o.GetNestedInt("spec", "minReplicas")
+		if err != nil || !found {
+			minReplicas = 1
+		}
+		ret[ref] = minReplicas
+	}
+	return ret
+}
+
+func getWorkloadReplicas(o *uo.UnstructuredObject, hpaMinReplicas map[k8s.ObjectRef]int64) (int64, error) {
+	ref := o.GetK8sRef()
+	ref.Version = ""
+	if minReplicas, ok := hpaMinReplicas[ref]; ok {
+		return minReplicas, nil
+	}
+
+	var path []any
+	switch ref.GroupKind() {
+	case schema.GroupKind{Kind: "ReplicationController"},
+		schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		schema.GroupKind{Group: "apps", Kind: "ReplicaSet"},
+		schema.GroupKind{Group: "apps", Kind: "StatefulSet"}:
+		path = []any{"spec", "replicas"}
+	case schema.GroupKind{Group: "batch", Kind: "Job"}:
+		path = []any{"spec", "parallelism"}
+	case schema.GroupKind{Group: "batch", Kind: "CronJob"}:
+		path = []any{"spec", "jobTemplate", "spec", "parallelism"}
+	default:
+		// Pods and DaemonSets, the number of nodes of DaemonSets is unknown
+		return 1, nil
+	}
+	replicas, found, err := o.GetNestedInt(path...)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 1, nil
+	}
+	return replicas, nil
+}
+
+func parseResourceQuantity(v any) (resource.Quantity, error) {
+	switch v2 := v.(type) {
+	case string:
+		return resource.ParseQuantity(v2)
+	default:
+		// plain numbers
+		return resource.ParseQuantity(fmt.Sprint(v2))
+	}
+}
+
+func getContainerResources(c *uo.UnstructuredObject) (resourceQuantities, error) {
+	var ret resourceQuantities
+	for _, x := range []struct {
+		path     []any
+		target   *int64
+		milliCpu bool
+	}{
+		{[]any{"resources", "requests", "cpu"}, &ret.cpuRequests, true},
+		{[]any{"resources", "limits", "cpu"}, &ret.cpuLimits, true},
+		{[]any{"resources", "requests", "memory"}, &ret.memoryRequests, false},
+		{[]any{"resources", "limits", "memory"}, &ret.memoryLimits, false},
+	} {
+		v, found, err := c.GetNestedField(x.path...)
+		if err != nil {
+			return ret, err
+		}
+		if !found || v == nil {
+			continue
+		}
+		q, err := parseResourceQuantity(v)
+		if err != nil {
+			return ret, fmt.Errorf("invalid quantity at %s of container: %w", uo.KeyPath(x.path).ToJsonPath(), err)
+		}
+		if x.milliCpu {
+			*x.target = q.MilliValue()
+		} else {
+			*x.target = q.Value()
+		}
+	}
+	return ret, nil
+}
+
+// getPodResources returns the effective resources of a single pod, which is the maximum of the summed up resources of
+// all containers and the resources of each init container
+func getPodResources(podSpec *uo.UnstructuredObject) (resourceQuantities, error) {
+	var ret resourceQuantities
+	containers, _, err := podSpec.GetNestedObjectList("containers")
+	if err != nil {
+		return ret, err
+	}
+	for _, c := range containers {
+		r, err := getContainerResources(c)
+		if err != nil {
+			return ret, err
+		}
+		ret.add(r, 1)
+	}
+	initContainers, _, err := podSpec.GetNestedObjectList("initContainers")
+	if err != nil {
+		return ret, err
+	}
+	for _, c := range initContainers {
+		r, err := getContainerResources(c)
+		if err != nil {
+			return ret, err
+		}
+		ret.max(r)
+	}
+	return ret, nil
+}
+
+// getWorkloadResources returns the resources of all replicas of the given workload. Objects that are not workloads
+// have no resources.
+func getWorkloadResources(o *uo.UnstructuredObject, hpaMinReplicas map[k8s.ObjectRef]int64) (resourceQuantities, error) {
+	var ret resourceQuantities
+	if o == nil {
+		return ret, nil
+	}
+	path, ok := workloadPodSpecPaths[o.GetK8sGVK().GroupKind()]
+	if !ok {
+		return ret, nil
+	}
+	podSpec, found, err := o.GetNestedObject(path...)
+	if err != nil {
+		return ret, err
+	}
+	if !found || podSpec == nil {
+		return ret, nil
+	}
+	replicas, err := getWorkloadReplicas(o, hpaMinReplicas)
+	if err != nil {
+		return ret, err
+	}
+	r, err := getPodResources(podSpec)
+	if err != nil {
+		return ret, err
+	}
+	ret.add(r, replicas)
+	return ret, nil
+}
+
+func getWorkloadResourcesDelta(oldObject *uo.UnstructuredObject, newObject *uo.UnstructuredObject, oldHpaMinReplicas map[k8s.ObjectRef]int64, newHpaMinReplicas map[k8s.ObjectRef]int64) (resourceQuantities, error) {
+	var ret resourceQuantities
+	oldQ, err := getWorkloadResources(oldObject, oldHpaMinReplicas)
+	if err != nil {
+		return ret, err
+	}
+	newQ, err := getWorkloadResources(newObject, newHpaMinReplicas)
+	if err != nil {
+		return ret, err
+	}
+	ret.add(newQ, 1)
+	ret.add(oldQ, -1)
+	return ret, nil
+}
+
+// buildResourceDelta calculates how the CPU and memory requests and limits of all workloads change when the remote
+// objects are replaced by the applied (or rendered) objects. Hooks are ignored, as these are only temporary. Objects
+// with unparseable quantities are excluded and reported as warnings. It returns nil if no workload resources change.
+func buildResourceDelta(objects []result.ResultObject, dew *utils.DeploymentErrorsAndWarnings) *result.ResourceDelta {
+	getNewObject := func(o *result.ResultObject) *uo.UnstructuredObject {
+		switch {
+		case o.Deleted:
+			return nil
+		case o.Applied != nil:
+			return o.Applied
+		case o.Rendered != nil:
+			return o.Rendered
+		default:
+			// orphan objects stay as they are
+			return o.Remote
+		}
+	}
+
+	var oldObjects, newObjects []*uo.UnstructuredObject
+	for _, o := range objects {
+		if o.Remote != nil {
+			oldObjects = append(oldObjects, o.Remote)
+		}
+		if x := getNewObject(&o); x != nil {
+			newObjects = append(newObjects, x)
+		}
+	}
+	oldHpaMinReplicas := buildHpaMinReplicas(oldObjects)
+	newHpaMinReplicas := buildHpaMinReplicas(newObjects)
+
+	var total resourceQuantities
+	byNamespace := map[string]*resourceQuantities{}
+	for _, o := range objects {
+		if o.Hook {
+			continue
+		}
+		d, err := getWorkloadResourcesDelta(o.Remote, getNewObject(&o), oldHpaMinReplicas, newHpaMinReplicas)
+		if err != nil {
+			dew.AddWarning(o.Ref, fmt.Errorf("failed to evaluate resources for the resource delta: %w", err))
+			continue
+		}
+		if d == (resourceQuantities{}) {
+			continue
+		}
+		total.add(d, 1)
+		nq, ok := byNamespace[o.Ref.Namespace]
+		if !ok {
+			nq = &resourceQuantities{}
+			byNamespace[o.Ref.Namespace] = nq
+		}
+		nq.add(d, 1)
+	}
+	ret := &result.ResourceDelta{
+		Total: total.toResult(),
+	}
+	for ns, q := range byNamespace {
+		if *q == (resourceQuantities{}) {
+			continue
+		}
+		ret.Namespaces = append(ret.Namespaces, result.NamespaceResourceDelta{
+			Namespace: ns,
+			Delta:     q.toResult(),
+		})
+	}
+	if len(ret.Namespaces) == 0 {
+		return nil
+	}
+	sort.Slice(ret.Namespaces, func(i, j int) bool {
+		return ret.Namespaces[i].Namespace < ret.Namespaces[j].Namespace
+	})
+	return ret
+}
@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"testing"
+
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildResourceDeltaTestDeployment(namespace string, name string, replicas any, cpu string, memory string) *uo.UnstructuredObject {
+	o := uo.FromMap(map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": map[string]any{
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{
+							"name": "c",
+							"resources": map[string]any{
+								"requests": map[string]any{"cpu": cpu, "memory": memory},
+								"limits":   map[string]any{"memory": memory},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	if replicas != nil {
+		_ = o.SetNestedField(replicas, "spec", "replicas")
+	}
+	return o
+}
+
+func buildResourceDeltaTestHpa(namespace string, target string, minReplicas int64) *uo.UnstructuredObject {
+	return uo.FromMap(map[string]any{
+		"apiVersion": "autoscaling/v2",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata": map[string]any{
+			"name":      target,
+			"namespace": namespace,
+		},
+		"spec": map[string]any{
+			"minReplicas": minReplicas,
+			"scaleTargetRef": map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"name":       target,
+			},
+		},
+	})
+}
+
+func buildResourceDeltaTestObject(remote *uo.UnstructuredObject, applied *uo.UnstructuredObject) result.ResultObject {
+	o := result.ResultObject{
+		Remote:  remote,
+		Applied: applied,
+	}
+	if applied != nil {
+		o.Ref = applied.GetK8sRef()
+	} else {
+		o.Ref = remote.GetK8sRef()
+	}
+	return o
+}
+
+func TestBuildResourceDelta(t *testing.T) {
+	deleted := buildResourceDeltaTestObject(buildResourceDeltaTestDeployment("ns2", "deleted", nil, "1", "1Gi"), nil)
+	deleted.Deleted = true
+	orphan := buildResourceDeltaTestObject(buildResourceDeltaTestDeployment("ns2", "orphan", nil, "1", "1Gi"), nil)
+	orphan.Orphan = true
+	hook := buildResourceDeltaTestObject(nil, buildResourceDeltaTestDeployment("ns1", "hook", nil, "1", "1Gi"))
+	hook.Hook = true
+
+	objects := []result.ResultObject{
+		// +2 * 250m, +2 * 128Mi
+		buildResourceDeltaTestObject(nil, buildResourceDeltaTestDeployment("ns1", "new", int64(2), "250m", "128Mi")),
+		// replicas 1 -> 3, +2 * 100m, +2 * 64Mi
+		buildResourceDeltaTestObject(
+			buildResourceDeltaTestDeployment("ns1", "scaled", nil, "100m", "64Mi"),
+			buildResourceDeltaTestDeployment("ns1", "scaled", int64(3), "100m", "64Mi")),
+		// scaled by a HPA with 4 min replicas, replicas are ignored, 4 * (500m - 250m)
+		buildResourceDeltaTestObject(
+			buildResourceDeltaTestDeployment("ns1", "hpa", int64(10), "250m", "64Mi"),
+			buildResourceDeltaTestDeployment("ns1", "hpa", int64(1), "500m", "64Mi")),
+		buildResourceDeltaTestObject(buildResourceDeltaTestHpa("ns1", "hpa", 4), buildResourceDeltaTestHpa("ns1", "hpa", 4)),
+		// -1, -1Gi
+		deleted,
+		orphan,
+		hook,
+	}
+
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+	d := buildResourceDelta(objects, dew)
+	assert.Empty(t, dew.GetWarningsList())
+	assert.NotNil(t, d)
+
+	assert.Equal(t, "700m", d.Total.CpuRequests.String())
+	assert.Equal(t, "0", d.Total.CpuLimits.String())
+	assert.Equal(t, "-640Mi", d.Total.MemoryRequests.String())
+	assert.Equal(t, "-640Mi", d.Total.MemoryLimits.String())
+
+	assert.Len(t, d.Namespaces, 2)
+	assert.Equal(t, "ns1", d.Namespaces[0].Namespace)
+	assert.Equal(t, "1700m", d.Namespaces[0].Delta.CpuRequests.String())
+	assert.Equal(t, "384Mi", d.Namespaces[0].Delta.MemoryRequests.String())
+	assert.Equal(t, "ns2", d.Namespaces[1].Namespace)
+	assert.Equal(t, "-1", d.Namespaces[1].Delta.CpuRequests.String())
+	assert.Equal(t, "-1Gi", d.Namespaces[1].Delta.MemoryRequests.String())
+}
+
+func TestBuildResourceDeltaNoChanges(t *testing.T) {
+	o := buildResourceDeltaTestDeployment("ns1", "d", int64(2), "1", "1Gi")
+	objects := []result.ResultObject{
+		buildResourceDeltaTestObject(o, o.Clone()),
+		buildResourceDeltaTestObject(nil, uo.FromMap(map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]any{"name": "cm", "namespace": "ns1"},
+		})),
+	}
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+	assert.Nil(t, buildResourceDelta(objects, dew))
+	assert.Empty(t, dew.GetWarningsList())
+}
+
+func TestBuildResourceDeltaInvalidQuantity(t *testing.T) {
+	invalid := buildResourceDeltaTestObject(nil, buildResourceDeltaTestDeployment("ns1", "invalid", nil, "1 core", "1Gi"))
+	objects := []result.ResultObject{
+		invalid,
+		buildResourceDeltaTestObject(nil, buildResourceDeltaTestDeployment("ns1", "valid", nil, "1", "1Gi")),
+	}
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+	d := buildResourceDelta(objects, dew)
+	assert.Equal(t, "1", d.Total.CpuRequests.String())
+
+	warnings := dew.GetWarningsList()
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, invalid.Ref, warnings[0].Ref)
+	assert.Contains(t, warnings[0].Message, "failed to evaluate resources for the resource delta")
+}
+
+func TestBuildResourceDeltaInitContainers(t *testing.T) {
+	o := buildResourceDeltaTestDeployment("ns1", "d", nil, "100m", "64Mi")
+	_ = o.SetNestedField([]any{
+		map[string]any{
+			"name": "init",
+			"resources": map[string]any{
+				"requests": map[string]any{"cpu": "1", "memory": "32Mi"},
+			},
+		},
+	}, "spec", "template", "spec", "initContainers")
+
+	d := buildResourceDelta([]result.ResultObject{buildResourceDeltaTestObject(nil, o)}, utils2.NewDeploymentErrorsAndWarnings())
+	assert.Equal(t, "1", d.Total.CpuRequests.String())
+	assert.Equal(t, "64Mi", d.Total.MemoryRequests.String())
+}
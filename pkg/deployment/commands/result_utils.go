@@ -1,10 +1,12 @@
 package commands
 
 import (
+	errors2 "errors"
 	"github.com/kluctl/kluctl/lib/git"
 	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -19,7 +21,7 @@ func newCommandResult(targetCtx *target_context.TargetContext, startTime time.Ti
 	r.Command = result.CommandInfo{
 		StartTime: metav1.NewTime(startTime),
 		Command:   command,
-		Args:      targetCtx.KluctlProject.LoadArgs.ExternalArgs,
+		Args:      targetCtx.KluctlProject.GetRedactedExternalArgs(),
 	}
 	r.Command.TargetNameOverride = targetCtx.Params.TargetNameOverride
 	r.Command.ContextOverride = targetCtx.Params.ContextOverride
@@ -29,6 +31,15 @@ func newCommandResult(targetCtx *target_context.TargetContext, startTime time.Ti
 	r.Command.IncludeDeploymentDirs = targetCtx.Params.Inclusion.GetIncludes("deploymentItemDir")
 	r.Command.ExcludeDeploymentDirs = targetCtx.Params.Inclusion.GetExcludes("deploymentItemDir")
 	r.Command.DryRun = targetCtx.Params.DryRun
+	if targetCtx.DeploymentCollection != nil {
+		r.Command.Partial = targetCtx.DeploymentCollection.Partial
+		r.Command.PartialNote = targetCtx.DeploymentCollection.PartialNote
+	}
+
+	r.Timings = &result.CommandTimings{}
+	if targetCtx.DeploymentCollection != nil && targetCtx.DeploymentCollection.RenderDuration != 0 {
+		r.Timings.Render = &metav1.Duration{Duration: targetCtx.DeploymentCollection.RenderDuration}
+	}
 
 	r.Deployment = &targetCtx.DeploymentProject.Config
 
@@ -42,7 +53,8 @@ func newCommandResult(targetCtx *target_context.TargetContext, startTime time.Ti
 	}
 
 	if targetCtx.SharedContext.K != nil {
-		r.ClusterInfo = buildClusterInfo(targetCtx.SharedContext.K, &r.Warnings)
+		r.ClusterInfo = buildClusterInfo(targetCtx.SharedContext.K, targetCtx.Target.ClusterId, &r.Warnings)
+		r.ClusterInfo.KubeconfigSource = targetCtx.Params.KubeconfigSource
 	}
 
 	r.TargetKey.TargetName = targetCtx.Target.Name
@@ -70,7 +82,7 @@ func newValidateCommandResult(targetCtx *target_context.TargetContext, startTime
 	r.TargetKey.Discriminator = targetCtx.Target.Discriminator
 
 	if targetCtx.SharedContext.K != nil {
-		clusterInfo := buildClusterInfo(targetCtx.SharedContext.K, &r.Warnings)
+		clusterInfo := buildClusterInfo(targetCtx.SharedContext.K, targetCtx.Target.ClusterId, &r.Warnings)
 		r.TargetKey.ClusterId = clusterInfo.ClusterId
 	}
 
@@ -91,15 +103,19 @@ func newDeleteCommandResult(k *k8s2.K8sCluster, startTime time.Time, inclusion *
 	r.Command.ExcludeDeploymentDirs = inclusion.GetExcludes("deploymentItemDir")
 
 	if k != nil {
-		r.ClusterInfo = buildClusterInfo(k, &r.Warnings)
+		r.ClusterInfo = buildClusterInfo(k, "", &r.Warnings)
 	}
 	return r
 }
 
 func finishCommandResult(r *result.CommandResult, targetCtx *target_context.TargetContext, dew *utils2.DeploymentErrorsAndWarnings) {
+	r.ResourceDelta = buildResourceDelta(r.Objects, dew)
 	r.Errors = append(r.Errors, dew.GetErrorsList()...)
 	r.Warnings = append(r.Warnings, dew.GetWarningsList()...)
 	if targetCtx != nil {
+		r.Warnings = append(r.Warnings, targetCtx.SharedContext.VarsLoader.GetWarnings()...)
+		r.Warnings = append(r.Warnings, targetCtx.SharedContext.ConfigWarnings.GetWarnings()...)
+		r.Warnings = append(r.Warnings, targetCtx.SharedContext.Deprecations.GetWarnings()...)
 		r.SeenImages = targetCtx.DeploymentCollection.Images.SeenImages(false)
 	}
 	r.Command.EndTime = metav1.Now()
@@ -108,19 +124,61 @@ func finishCommandResult(r *result.CommandResult, targetCtx *target_context.Targ
 func finishValidateResult(r *result.ValidateResult, targetCtx *target_context.TargetContext, dew *utils2.DeploymentErrorsAndWarnings) {
 	r.Errors = append(r.Errors, dew.GetErrorsList()...)
 	r.Warnings = append(r.Warnings, dew.GetWarningsList()...)
+	if targetCtx != nil {
+		r.Warnings = append(r.Warnings, targetCtx.SharedContext.VarsLoader.GetWarnings()...)
+		r.Warnings = append(r.Warnings, targetCtx.SharedContext.ConfigWarnings.GetWarnings()...)
+		r.Warnings = append(r.Warnings, targetCtx.SharedContext.Deprecations.GetWarnings()...)
+	}
 	r.EndTime = metav1.Now()
 }
 
-func buildClusterInfo(k *k8s2.K8sCluster, warnings *[]result.DeploymentError) result.ClusterInfo {
+// newPrunePhase returns the prune phase for the given deleted objects or nothing if nothing got deleted
+func newPrunePhase(startTime time.Time, deleted []k8s.ObjectRef) []result.Phase {
+	if len(deleted) == 0 {
+		return nil
+	}
+	return []result.Phase{{
+		Type:      result.PhasePrune,
+		StartTime: metav1.NewMicroTime(startTime),
+		EndTime:   metav1.NowMicro(),
+		Objects:   deleted,
+	}}
+}
+
+// durationSince returns the time passed since startTime
+func durationSince(startTime time.Time) *metav1.Duration {
+	return &metav1.Duration{Duration: time.Since(startTime)}
+}
+
+// sumPhaseDurations returns the summed up durations of all phases of the given types or nil if there are none
+func sumPhaseDurations(phases []result.Phase, types ...result.PhaseType) *metav1.Duration {
+	var ret *metav1.Duration
+	for _, p := range phases {
+		for _, t := range types {
+			if p.Type != t {
+				continue
+			}
+			if ret == nil {
+				ret = &metav1.Duration{}
+			}
+			ret.Duration += p.EndTime.Sub(p.StartTime.Time)
+		}
+	}
+	return ret
+}
+
+func buildClusterInfo(k *k8s2.K8sCluster, clusterIdOverride string, warnings *[]result.DeploymentError) result.ClusterInfo {
 	var clusterInfo result.ClusterInfo
-	clusterId, err := k.GetClusterId()
+	clusterId, source, err := k.DetermineClusterId(clusterIdOverride)
 	clusterInfo = result.ClusterInfo{
-		ClusterId: clusterId,
+		ClusterId:       clusterId,
+		ClusterIdSource: string(source),
 	}
 	if err == nil {
 		return clusterInfo
 	}
-	if !errors.IsForbidden(err) {
+	// results without cluster ID are reported with a dedicated warning when written to result stores
+	if !errors.IsForbidden(err) && !errors2.Is(err, k8s2.ErrClusterIdUnavailable) {
 		*warnings = append(*warnings, result.DeploymentError{
 			Message: err.Error(),
 		})
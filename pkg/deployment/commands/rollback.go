@@ -0,0 +1,316 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/version"
+	"time"
+)
+
+// RollbackCommand re-applies the rendered objects recorded in a previously stored command result. The project is
+// only used to determine the target, discriminator and project specific configuration (e.g. conflict resolution),
+// the objects themselves are taken from the stored result.
+type RollbackCommand struct {
+	targetCtx   *target_context.TargetContext
+	resultStore results.ResultStore
+
+	// SourceResultId specifies the command result to roll back to. If empty, the previous successful deployment
+	// result is used.
+	SourceResultId string
+
+	ForceApply          bool
+	ReplaceOnError      bool
+	ForceReplaceOnError bool
+	AbortOnError        bool
+	ReadinessTimeout    time.Duration
+	NoWait              bool
+	WaitPrune           bool
+	NoListNormalization bool
+
+	// DeletedManifests controls capturing of the manifests of pruned objects
+	DeletedManifests DeletedManifestsOptions
+}
+
+func NewRollbackCommand(targetCtx *target_context.TargetContext, resultStore results.ResultStore) *RollbackCommand {
+	return &RollbackCommand{
+		targetCtx:   targetCtx,
+		resultStore: resultStore,
+	}
+}
+
+// Run performs the rollback. diffResultCb is called with the result of a dry-run rollback before anything is changed.
+// pruneCb is called with all objects that were added after the source result was created and must return true if
+// these objects should be pruned.
+func (cmd *RollbackCommand) Run(diffResultCb func(diffResult *result.CommandResult) error, pruneCb func(refs []k8s2.ObjectRef) bool) *result.CommandResult {
+	ctx := cmd.targetCtx.SharedContext.Ctx
+	k := cmd.targetCtx.SharedContext.K
+
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+
+	r := newCommandResult(cmd.targetCtx, cmd.targetCtx.KluctlProject.LoadTime, "rollback")
+	r.Command.ForceApply = cmd.ForceApply
+	r.Command.ReplaceOnError = cmd.ReplaceOnError
+	r.Command.ForceReplaceOnError = cmd.ForceReplaceOnError
+	r.Command.AbortOnError = cmd.AbortOnError
+	r.Command.NoWait = cmd.NoWait
+
+	var source *result.CommandResult
+	defer func() {
+		// the rendered objects of the current project are not relevant for the rollback
+		finishCommandResult(r, nil, dew)
+		if source != nil {
+			r.SeenImages = source.SeenImages
+		}
+	}()
+
+	if k == nil {
+		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("rollback requires a Kubernetes API client"))
+		return r
+	}
+	if cmd.resultStore == nil {
+		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("rollback requires access to the result store"))
+		return r
+	}
+	if cmd.targetCtx.Target.Discriminator == "" {
+		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("rollback without a discriminator is not supported"))
+		return r
+	}
+
+	var err error
+	source, err = cmd.findSourceResult(r)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+	status.Infof(ctx, "Rolling back to command result %s from %s", source.Id, source.Command.StartTime.Format(time.RFC3339))
+
+	r.Command.RollbackSourceResultId = source.Id
+	r.GitInfo = source.GitInfo
+	r.Deployment = source.Deployment
+	r.RenderedObjectsHash = source.RenderedObjectsHash
+
+	cmd.checkKluctlVersion(source, dew)
+
+	c := cmd.buildDeploymentCollection(source, dew)
+
+	ru := utils2.NewRemoteObjectsUtil(ctx, dew)
+	err = ru.UpdateRemoteObjects(k, &cmd.targetCtx.Target.Discriminator, c.LocalObjectRefs(), false)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
+	o := &utils2.ApplyUtilOptions{
+		ForceApply:          cmd.ForceApply,
+		ReplaceOnError:      cmd.ReplaceOnError,
+		ForceReplaceOnError: cmd.ForceReplaceOnError,
+		DryRun:              true,
+		AbortOnError:        false,
+		ReadinessTimeout:    cmd.ReadinessTimeout,
+		NoWait:              cmd.NoWait,
+	}
+
+	if diffResultCb != nil {
+		diffStartTime := time.Now()
+		diffDew := dew.Clone()
+		au := utils2.NewApplyDeploymentsUtil(ctx, diffDew, ru, k, o)
+		au.ApplyDeployments(c.Deployments)
+
+		du := utils2.NewDiffUtil(diffDew, ru, cmd.targetCtx.SharedContext.K, au.GetAppliedObjectsMap())
+		du.NoListNormalization = cmd.NoListNormalization
+		du.DiffDeploymentItems(c.Deployments)
+
+		added, _ := FindOrphanObjects(ctx, k, ru, c)
+		diffObjects := collectObjects(c, ru, au, du, added, nil)
+		resourceDelta := buildResourceDelta(diffObjects, diffDew)
+		diffResult := &result.CommandResult{
+			Objects:       diffObjects,
+			Errors:        diffDew.GetErrorsList(),
+			Warnings:      diffDew.GetWarningsList(),
+			SeenImages:    source.SeenImages,
+			ResourceDelta: resourceDelta,
+		}
+		r.Timings.Diff = durationSince(diffStartTime)
+
+		err = diffResultCb(diffResult)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
+	// modify options to perform the real rollback
+	o.DryRun = k.DryRun
+	o.AbortOnError = cmd.AbortOnError
+
+	applyStartTime := time.Now()
+	au := utils2.NewApplyDeploymentsUtil(ctx, dew, ru, k, o)
+	au.ApplyDeployments(c.Deployments)
+	r.Timings.Apply = durationSince(applyStartTime)
+
+	du := utils2.NewDiffUtil(dew, ru, cmd.targetCtx.SharedContext.K, au.GetAppliedObjectsMap())
+	du.NoListNormalization = cmd.NoListNormalization
+	du.DiffDeploymentItems(c.Deployments)
+
+	phases := au.GetPhases()
+
+	added, err := FindOrphanObjects(ctx, k, ru, c)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+	}
+
+	var deleted []k8s2.ObjectRef
+	if len(added) != 0 && pruneCb != nil && pruneCb(added) {
+		pruneStartTime := time.Now()
+		deleted = utils2.DeleteObjects(ctx, k, added, dew, cmd.WaitPrune)
+		phases = append(phases, newPrunePhase(pruneStartTime, deleted)...)
+		r.Timings.Prune = durationSince(pruneStartTime)
+		added = filterDeletedOrphans(added, deleted)
+	}
+
+	r.Objects = collectObjects(c, ru, au, du, added, deleted)
+	if !k.DryRun {
+		captureDeletedManifests(r.Objects, ru, cmd.DeletedManifests, dew)
+	}
+	r.RerunJobs = au.GetRerunJobs()
+	r.Phases = phases
+	r.Timings.Hooks = sumPhaseDurations(phases, result.PhasePreDeployHooks, result.PhasePostDeployHooks)
+
+	return r
+}
+
+// findSourceResult loads the result to roll back to and verifies that it belongs to the same project and target
+func (cmd *RollbackCommand) findSourceResult(r *result.CommandResult) (*result.CommandResult, error) {
+	id := cmd.SourceResultId
+	if id == "" {
+		summary, err := cmd.findPreviousSuccessfulResult(r)
+		if err != nil {
+			return nil, err
+		}
+		id = summary.Id
+	}
+
+	source, err := cmd.resultStore.GetCommandResult(results.GetCommandResultOptions{
+		Id: id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get command result %s: %w", id, err)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("command result %s not found", id)
+	}
+
+	if !isRollbackSourceCommand(source.Command) {
+		return nil, fmt.Errorf("command result %s was produced by a '%s' command in dry-run=%v mode, only results of real deployments can be rolled back to", source.Id, source.Command.Command, source.Command.DryRun)
+	}
+	if source.ProjectKey != r.ProjectKey {
+		return nil, fmt.Errorf("command result %s belongs to a different project (%s)", source.Id, source.ProjectKey.RepoKey.String())
+	}
+	if source.TargetKey.TargetName != r.TargetKey.TargetName || source.TargetKey.ClusterId != r.TargetKey.ClusterId {
+		return nil, fmt.Errorf("command result %s belongs to a different target or cluster", source.Id)
+	}
+	if source.TargetKey.Discriminator != r.TargetKey.Discriminator {
+		return nil, fmt.Errorf("refusing to roll back across discriminator changes: command result %s was deployed with discriminator '%s', while the current discriminator is '%s'", source.Id, source.TargetKey.Discriminator, r.TargetKey.Discriminator)
+	}
+	return source, nil
+}
+
+// findPreviousSuccessfulResult returns the newest successful deployment that precedes the most recent deployment of
+// the target. The most recent deployment is skipped as it represents the state to roll back from.
+func (cmd *RollbackCommand) findPreviousSuccessfulResult(r *result.CommandResult) (*result.CommandResultSummary, error) {
+	summaries, err := cmd.resultStore.ListCommandResultSummaries(results.ListResultSummariesOptions{
+		ProjectFilter: &r.ProjectKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// summaries are sorted by start time, newest first
+	foundCurrent := false
+	for _, s := range summaries {
+		// the discriminator is intentionally not compared here, so that discriminator changes are properly reported
+		if s.TargetKey.TargetName != r.TargetKey.TargetName || s.TargetKey.ClusterId != r.TargetKey.ClusterId {
+			continue
+		}
+		if !isRollbackSourceCommand(s.Command) {
+			continue
+		}
+		if !foundCurrent {
+			foundCurrent = true
+			continue
+		}
+		if len(s.Errors) == 0 {
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("no previous successful deployment found for target %s", r.TargetKey.TargetName)
+}
+
+func isRollbackSourceCommand(c result.CommandInfo) bool {
+	return (c.Command == "deploy" || c.Command == "rollback") && !c.DryRun
+}
+
+func (cmd *RollbackCommand) checkKluctlVersion(source *result.CommandResult, dew *utils2.DeploymentErrorsAndWarnings) {
+	if source.Command.Invocation == nil || source.Command.Invocation.KluctlVersion == "" {
+		return
+	}
+	sourceVersion, err := semver.NewVersion(source.Command.Invocation.KluctlVersion)
+	if err != nil {
+		return
+	}
+	currentVersion, err := semver.NewVersion(version.GetVersion())
+	if err != nil {
+		return
+	}
+	if sourceVersion.Major() != currentVersion.Major() {
+		dew.AddWarning(k8s2.ObjectRef{}, fmt.Errorf("command result %s was produced by kluctl %s, which is a different major version than the current version %s", source.Id, sourceVersion.String(), currentVersion.String()))
+	}
+}
+
+// buildDeploymentCollection builds a collection with a single deployment item that holds all rendered objects
+// of the source result. Hooks are not part of the collection, as rolling back must not re-run hooks.
+func (cmd *RollbackCommand) buildDeploymentCollection(source *result.CommandResult, dew *utils2.DeploymentErrorsAndWarnings) *deployment.DeploymentCollection {
+	var objects []*uo.UnstructuredObject
+	for _, o := range source.Objects {
+		if o.Rendered == nil || o.Hook {
+			continue
+		}
+		if o.Rendered.GetK8sAnnotation("kluctl.io/hook") != nil || o.Rendered.GetK8sAnnotation("helm.sh/hook") != nil {
+			continue
+		}
+		if diff.IsObfuscatedSecret(o.Rendered) {
+			dew.AddWarning(o.Ref, fmt.Errorf("secret data was obfuscated in the stored command result, not rolling back this secret"))
+			continue
+		}
+		if o.ObfuscatedByRules {
+			dew.AddWarning(o.Ref, fmt.Errorf("fields were obfuscated by obfuscation rules in the stored command result, not rolling back this object"))
+			continue
+		}
+		objects = append(objects, o.Rendered)
+	}
+
+	project := cmd.targetCtx.DeploymentCollection.Project
+	return &deployment.DeploymentCollection{
+		Project:   project,
+		Images:    cmd.targetCtx.DeploymentCollection.Images,
+		Inclusion: utils.NewInclusion(),
+		Deployments: []*deployment.DeploymentItem{{
+			Project:             project,
+			Inclusion:           utils.NewInclusion(),
+			Config:              &types.DeploymentItemConfig{},
+			Objects:             objects,
+			RelToProjectItemDir: "<rollback>",
+		}},
+	}
+}
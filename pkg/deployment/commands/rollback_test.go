@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbackSkipsObfuscatedObjects(t *testing.T) {
+	newObject := func(kind string, name string, data string) *uo.UnstructuredObject {
+		o := uo.New()
+		o.SetK8sGVKs("", "v1", kind)
+		o.SetK8sName(name)
+		o.SetK8sNamespace("ns")
+		_ = o.SetNestedField(map[string]any{"a": data}, "data")
+		return o
+	}
+	newResultObject := func(o *uo.UnstructuredObject, obfuscatedByRules bool) result.ResultObject {
+		return result.ResultObject{
+			BaseObject: result.BaseObject{Ref: o.GetK8sRef(), ObfuscatedByRules: obfuscatedByRules},
+			Rendered:   o,
+		}
+	}
+
+	source := &result.CommandResult{
+		Objects: []result.ResultObject{
+			newResultObject(newObject("ConfigMap", "plain", "v"), false),
+			newResultObject(newObject("ConfigMap", "by-rules", "*****"), true),
+			newResultObject(newObject("Secret", "secret", "KioqKio="), false),
+		},
+	}
+
+	cmd := &RollbackCommand{
+		targetCtx: &target_context.TargetContext{
+			DeploymentCollection: &deployment.DeploymentCollection{},
+		},
+	}
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+	dc := cmd.buildDeploymentCollection(source, dew)
+
+	assert.Len(t, dc.Deployments[0].Objects, 1)
+	assert.Equal(t, "plain", dc.Deployments[0].Objects[0].GetK8sName())
+
+	warnings := dew.GetWarningsList()
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, "by-rules", warnings[0].Ref.Name)
+	assert.Contains(t, warnings[0].Message, "obfuscated by obfuscation rules")
+	assert.Equal(t, "secret", warnings[1].Ref.Name)
+}
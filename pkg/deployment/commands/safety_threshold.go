@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+// checkSafetyThreshold returns an error if the number of changed or deleted objects exceeds the safety threshold.
+// changed is the number of new and changed objects and deleted the number of objects that would be deleted.
+func checkSafetyThreshold(t *types.SafetyThresholdConfig, changed int, deleted int) error {
+	if t == nil {
+		return nil
+	}
+
+	var exceeded []string
+	if t.MaxChangedObjects != nil && changed > *t.MaxChangedObjects {
+		exceeded = append(exceeded, fmt.Sprintf("maxChangedObjects=%d", *t.MaxChangedObjects))
+	}
+	if t.MaxDeletedObjects != nil && deleted > *t.MaxDeletedObjects {
+		exceeded = append(exceeded, fmt.Sprintf("maxDeletedObjects=%d", *t.MaxDeletedObjects))
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	return fmt.Errorf("aborted before applying anything, as %d objects would be changed and %d objects would be deleted, which exceeds the safety threshold of the target (%s). Use --override-safety-threshold to proceed anyway",
+		changed, deleted, strings.Join(exceeded, ", "))
+}
+
+// countChangedObjects returns the number of new and changed objects
+func countChangedObjects(objects []result.ResultObject) int {
+	cnt := 0
+	for _, o := range objects {
+		if o.New || len(o.Changes) != 0 {
+			cnt++
+		}
+	}
+	return cnt
+}
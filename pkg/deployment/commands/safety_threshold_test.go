@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSafetyThreshold(t *testing.T) {
+	intPtr := func(i int) *int {
+		return &i
+	}
+
+	assert.NoError(t, checkSafetyThreshold(nil, 1000, 1000))
+
+	st := &types.SafetyThresholdConfig{MaxChangedObjects: intPtr(10)}
+	assert.NoError(t, checkSafetyThreshold(st, 10, 1000))
+	assert.EqualError(t, checkSafetyThreshold(st, 11, 0), "aborted before applying anything, as 11 objects would be changed and 0 objects would be deleted, which exceeds the safety threshold of the target (maxChangedObjects=10). Use --override-safety-threshold to proceed anyway")
+
+	st = &types.SafetyThresholdConfig{MaxChangedObjects: intPtr(10), MaxDeletedObjects: intPtr(0)}
+	assert.NoError(t, checkSafetyThreshold(st, 0, 0))
+	assert.ErrorContains(t, checkSafetyThreshold(st, 0, 1), "(maxDeletedObjects=0)")
+	assert.ErrorContains(t, checkSafetyThreshold(st, 11, 1), "(maxChangedObjects=10, maxDeletedObjects=0)")
+}
+
+func TestCountChangedObjects(t *testing.T) {
+	objects := []result.ResultObject{
+		{BaseObject: result.BaseObject{New: true}},
+		{BaseObject: result.BaseObject{Changes: []result.Change{{Type: "update", JsonPath: "data.a"}}}},
+		{BaseObject: result.BaseObject{}},
+		{BaseObject: result.BaseObject{Orphan: true}},
+	}
+	assert.Equal(t, 2, countChangedObjects(objects))
+}
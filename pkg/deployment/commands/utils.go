@@ -62,6 +62,22 @@ func collectObjects(c *deployment.DeploymentCollection, ru *utils.RemoteObjectUt
 			o := getOrCreate(dn)
 			o.Deleted = true
 		}
+		for ref, om := range au.GetOwnershipMigrations() {
+			dn, ok := appliedDiffNames[ref]
+			if !ok {
+				dn = ref
+			}
+			o := getOrCreate(dn)
+			o.OwnershipMigration = om
+		}
+		for ref, t := range au.GetObjectTimings() {
+			dn, ok := appliedDiffNames[ref]
+			if !ok {
+				dn = ref
+			}
+			o := getOrCreate(dn)
+			o.Timings = t
+		}
 	}
 	if du != nil {
 		for _, x := range du.ChangedObjects {
@@ -72,6 +88,18 @@ func collectObjects(c *deployment.DeploymentCollection, ru *utils.RemoteObjectUt
 			o := getOrCreate(dn)
 			o.Changes = x.Changes
 		}
+		for _, x := range du.IgnoredChangedObjects {
+			dn, ok := appliedDiffNames[x.Ref]
+			if !ok {
+				dn = x.Ref
+			}
+			o := getOrCreate(dn)
+			o.IgnoredChanges = x.Changes
+		}
+		for ref, move := range du.MovedObjects {
+			o := getOrCreate(ref)
+			o.Moved = move
+		}
 	}
 	if au != nil {
 		for _, x := range au.GetNewObjectRefs() {
@@ -80,7 +108,7 @@ func collectObjects(c *deployment.DeploymentCollection, ru *utils.RemoteObjectUt
 				dn = x
 			}
 			o := getOrCreate(dn)
-			if len(o.Changes) != 0 {
+			if len(o.Changes) != 0 || o.Moved != nil {
 				continue
 			}
 			o.New = true
@@ -100,6 +128,10 @@ func collectObjects(c *deployment.DeploymentCollection, ru *utils.RemoteObjectUt
 			continue
 		}
 		o := getOrCreate(x)
+		if o.Moved != nil {
+			// moved objects are still managed by us, just by another deployment item
+			continue
+		}
 		o.Orphan = true
 
 	}
@@ -116,7 +148,26 @@ func collectObjects(c *deployment.DeploymentCollection, ru *utils.RemoteObjectUt
 	for _, o := range m {
 		ret = append(ret, *o)
 	}
+
+	var order *deployment.ObjectOrder
+	if c != nil {
+		order = c.Project.GetObjectOrder()
+	} else {
+		order = deployment.NewObjectOrder(nil)
+	}
+	weights := make(map[k8s.ObjectRef]int, len(ret))
+	for _, o := range ret {
+		weights[o.Ref] = order.GetWeightForRef(o.Ref)
+		if o.Rendered != nil {
+			// errors were already reported while applying
+			weights[o.Ref], _ = order.GetWeight(o.Rendered)
+		}
+	}
 	sort.Slice(ret, func(i, j int) bool {
+		wi, wj := weights[ret[i].Ref], weights[ret[j].Ref]
+		if wi != wj {
+			return wi < wj
+		}
 		return ret[i].Ref.GroupVersionKind().String() < ret[j].Ref.GroupVersionKind().String()
 	})
 	return ret
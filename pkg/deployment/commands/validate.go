@@ -3,17 +3,22 @@ package commands
 import (
 	"context"
 	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
 	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/validation"
+	"k8s.io/apimachinery/pkg/api/errors"
 )
 
 type ValidateCommand struct {
 	targetCtx     *target_context.TargetContext
 	discriminator string
 
+	// NoProbes disables execution of the HTTP probes declared via the kluctl.io/validate-probe-url annotation
+	NoProbes bool
+
 	dew *utils2.DeploymentErrorsAndWarnings
 	ru  *utils2.RemoteObjectUtils
 }
@@ -76,7 +81,7 @@ func (cmd *ValidateCommand) Run(ctx context.Context) *result.ValidateResult {
 					// the hook is not expected to exist after deployment
 					continue
 				}
-				if hook.IsOnlyDelete() || hook.IsOnlyRollback() {
+				if hook.IsNeverExecuted() {
 					// the hook is not expected to be executed
 					continue
 				}
@@ -90,18 +95,45 @@ func (cmd *ValidateCommand) Run(ctx context.Context) *result.ValidateResult {
 				continue
 			}
 			r := validation.ValidateObject(ctx, cmd.targetCtx.SharedContext.K, remoteObject, true, false)
-			if !r.Ready {
-				ret.Ready = false
+			appendValidateResult(ret, r)
+			if !cmd.NoProbes {
+				appendValidateResult(ret, validation.ValidateProbe(ctx, remoteObject, true))
 			}
-			ret.Errors = append(ret.Errors, r.Errors...)
-			ret.Warnings = append(ret.Warnings, r.Warnings...)
-			ret.Results = append(ret.Results, r.Results...)
+		}
+
+		if len(d.Config.PostDeployWaits) != 0 {
+			cmd.validatePostDeployWaits(ctx, ad.NewApplyUtil(ctx, nil), d, ret)
 		}
 	}
 
 	return ret
 }
 
+func (cmd *ValidateCommand) validatePostDeployWaits(ctx context.Context, au *utils2.ApplyUtil, d *deployment.DeploymentItem, ret *result.ValidateResult) {
+	k := cmd.targetCtx.SharedContext.K
+	for i := range d.Config.PostDeployWaits {
+		c := &d.Config.PostDeployWaits[i]
+		for _, ref := range au.ResolveObjectRefItem(c.ObjectRefItem) {
+			o, apiWarnings, err := k.GetSingleObject(ref)
+			cmd.dew.AddApiWarnings(ref, apiWarnings)
+			if err != nil && !errors.IsNotFound(err) {
+				cmd.dew.AddError(ref, err)
+				continue
+			}
+			appendValidateResult(ret, validation.ValidatePostDeployWait(ctx, ref, o, c, true))
+		}
+	}
+}
+
+func appendValidateResult(ret *result.ValidateResult, r result.ValidateResult) {
+	if !r.Ready {
+		ret.Ready = false
+	}
+	ret.Errors = append(ret.Errors, r.Errors...)
+	ret.Warnings = append(ret.Warnings, r.Warnings...)
+	ret.Results = append(ret.Results, r.Results...)
+}
+
 func (cmd *ValidateCommand) ForgetRemoteObject(ref k8s2.ObjectRef) {
 	cmd.ru.ForgetRemoteObject(ref)
 }
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// ValidateStoredResultCommand validates the objects recorded in a previously stored command result against the live
+// cluster. In contrast to ValidateCommand, it does not require the project to be loaded/rendered.
+type ValidateStoredResultCommand struct {
+	k  *k8s.K8sCluster
+	cr *result.CommandResult
+
+	// NoProbes disables execution of the HTTP probes declared via the kluctl.io/validate-probe-url annotation
+	NoProbes bool
+
+	dew *utils2.DeploymentErrorsAndWarnings
+	ru  *utils2.RemoteObjectUtils
+}
+
+func NewValidateStoredResultCommand(ctx context.Context, k *k8s.K8sCluster, cr *result.CommandResult) *ValidateStoredResultCommand {
+	cmd := &ValidateStoredResultCommand{
+		k:   k,
+		cr:  cr,
+		dew: utils2.NewDeploymentErrorsAndWarnings(),
+	}
+	cmd.ru = utils2.NewRemoteObjectsUtil(ctx, cmd.dew)
+	return cmd
+}
+
+func (cmd *ValidateStoredResultCommand) Run(ctx context.Context) *result.ValidateResult {
+	cmd.dew.Init()
+
+	ret := &result.ValidateResult{
+		ProjectKey:          cmd.cr.ProjectKey,
+		TargetKey:           cmd.cr.TargetKey,
+		KluctlDeployment:    cmd.cr.KluctlDeployment,
+		RenderedObjectsHash: cmd.cr.RenderedObjectsHash,
+		SourceResultId:      cmd.cr.Id,
+		StartTime:           metav1.NewTime(time.Now()),
+		Ready:               true,
+	}
+
+	defer func() {
+		finishValidateResult(ret, nil, cmd.dew)
+	}()
+
+	var objects []result.ResultObject
+	var refs []k8s2.ObjectRef
+	for _, o := range cmd.cr.Objects {
+		if o.Deleted || o.Orphan || o.Hook {
+			// deleted and orphaned objects are not expected to be ready and hooks are not necessarily persistent
+			continue
+		}
+		if o.Rendered == nil {
+			// not part of the deployment at all (e.g. only remote)
+			continue
+		}
+		if o.Rendered.GetK8sAnnotationBoolNoError("kluctl.io/delete", false) {
+			continue
+		}
+		objects = append(objects, o)
+		refs = append(refs, o.Ref)
+	}
+
+	discriminator := cmd.cr.TargetKey.Discriminator
+	err := cmd.ru.UpdateRemoteObjects(cmd.k, &discriminator, refs, true)
+	if err != nil {
+		cmd.dew.AddError(k8s2.ObjectRef{}, err)
+		return ret
+	}
+
+	for _, o := range objects {
+		remoteObject := cmd.ru.GetRemoteObject(o.Ref)
+		if remoteObject == nil {
+			ret.Warnings = append(ret.Warnings, result.DeploymentError{
+				Ref:     o.Ref,
+				Message: fmt.Sprintf("object recorded in command result %s does not exist anymore", cmd.cr.Id),
+			})
+			continue
+		}
+		r := validation.ValidateObject(ctx, cmd.k, remoteObject, true, false)
+		appendValidateResult(ret, r)
+		if !cmd.NoProbes {
+			appendValidateResult(ret, validation.ValidateProbe(ctx, remoteObject, true))
+		}
+	}
+
+	return ret
+}
+
+func (cmd *ValidateStoredResultCommand) ForgetRemoteObject(ref k8s2.ObjectRef) {
+	cmd.ru.ForgetRemoteObject(ref)
+}
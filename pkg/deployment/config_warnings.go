@@ -0,0 +1,42 @@
+package deployment
+
+import (
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"sync"
+)
+
+// ConfigWarnings collects unknown fields that were found in project config files while loading in lenient mode and
+// other problems found while loading the project
+type ConfigWarnings struct {
+	warnings []result.DeploymentError
+	mutex    sync.Mutex
+}
+
+func NewConfigWarnings() *ConfigWarnings {
+	return &ConfigWarnings{}
+}
+
+func (w *ConfigWarnings) AddUnknownFields(fields []yaml.UnknownField) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, f := range fields {
+		w.warnings = append(w.warnings, result.DeploymentError{Message: f.String()})
+	}
+}
+
+func (w *ConfigWarnings) Add(message string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.warnings = append(w.warnings, result.DeploymentError{Message: message})
+}
+
+// GetWarnings returns all collected warnings
+func (w *ConfigWarnings) GetWarnings() []result.DeploymentError {
+	if w == nil {
+		return nil
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return append([]result.DeploymentError{}, w.warnings...)
+}
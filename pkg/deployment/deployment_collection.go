@@ -15,7 +15,9 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 )
 
 type DeploymentCollection struct {
@@ -27,6 +29,14 @@ type DeploymentCollection struct {
 
 	Deployments []*DeploymentItem
 	mutex       sync.Mutex
+
+	// Partial is true if the collection was restricted to the deployment items that are affected by changed files
+	Partial bool
+	// PartialNote explains why the collection is partial or why restricting it was not possible
+	PartialNote string
+
+	// RenderDuration is the time it took to render all deployment items in Prepare
+	RenderDuration time.Duration
 }
 
 func NewDeploymentCollection(ctx SharedContext, project *DeploymentProject, images *Images, inclusion *utils.Inclusion) (*DeploymentCollection, error) {
@@ -147,6 +157,29 @@ func (c *DeploymentCollection) RenderDeployments() error {
 	return g.ErrorOrNil()
 }
 
+func (c *DeploymentCollection) renderYamlBundles() error {
+	if !slices.ContainsFunc(c.Deployments, func(d *DeploymentItem) bool { return d.Config.YamlBundle != nil }) {
+		return nil
+	}
+
+	s := status.Start(c.ctx.Ctx, "Fetching YAML bundles")
+	defer s.Failed()
+
+	g := utils.NewGoHelper(c.ctx.Ctx, 8)
+
+	for _, d := range c.Deployments {
+		d := d
+		g.RunE(func() error {
+			return d.renderYamlBundle()
+		})
+	}
+	g.Wait()
+	if g.ErrorOrNil() == nil {
+		s.Success()
+	}
+	return g.ErrorOrNil()
+}
+
 func (c *DeploymentCollection) renderHelmCharts() error {
 	s := status.Start(c.ctx.Ctx, "Rendering Helm Charts")
 	defer s.Failed()
@@ -251,6 +284,9 @@ func (c *DeploymentCollection) fixNamespaces() error {
 	for _, d := range c.Deployments {
 		for _, o := range d.Objects {
 			def := "default"
+			if c.ctx.NamespaceOverride != "" {
+				def = c.ctx.NamespaceOverride
+			}
 			helmNs := o.GetK8sAnnotation(helm.InstallNamespaceAnnotation)
 			if helmNs != nil {
 				def = *helmNs
@@ -329,10 +365,19 @@ func (c *DeploymentCollection) LocalObjectRefs() []k8s2.ObjectRef {
 }
 
 func (c *DeploymentCollection) Prepare() error {
+	startTime := time.Now()
+	defer func() {
+		c.RenderDuration = time.Since(startTime)
+	}()
+
 	err := c.RenderDeployments()
 	if err != nil {
 		return err
 	}
+	err = c.renderYamlBundles()
+	if err != nil {
+		return err
+	}
 	err = c.renderHelmCharts()
 	if err != nil {
 		return err
@@ -353,6 +398,14 @@ func (c *DeploymentCollection) Prepare() error {
 	if err != nil {
 		return err
 	}
+	err = c.applyNamespaceOverride()
+	if err != nil {
+		return err
+	}
+	err = c.applyObjectPatches()
+	if err != nil {
+		return err
+	}
 	err = c.collectResultObjects()
 	if err != nil {
 		return err
@@ -417,3 +470,23 @@ func (c *DeploymentCollection) CalcObjectsHash() (string, error) {
 
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
+
+// PruneExclude returns the effective prune exclusion rules
+func (c *DeploymentCollection) PruneExclude() []types.PruneExcludeRule {
+	return c.ctx.PruneExclude
+}
+
+// IgnoreUnservedApiVersions returns the patterns of API versions that are not reported when not served by the cluster
+func (c *DeploymentCollection) IgnoreUnservedApiVersions() []string {
+	return c.ctx.IgnoreUnservedApiVersions
+}
+
+// DeferredKinds returns the patterns of group/kinds that are excluded from the missing kinds preflight check
+func (c *DeploymentCollection) DeferredKinds() []string {
+	return c.ctx.DeferredKinds
+}
+
+// NamespaceOverride returns the namespace that all namespaced objects are moved into, or an empty string
+func (c *DeploymentCollection) NamespaceOverride() string {
+	return c.ctx.NamespaceOverride
+}
@@ -1,6 +1,7 @@
 package deployment
 
 import (
+	"bytes"
 	"fmt"
 	"io/fs"
 	"os"
@@ -12,7 +13,6 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/helm"
-	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/sops"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
@@ -20,6 +20,7 @@ import (
 	securefs "github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/kustomize/filesys"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"github.com/kluctl/kluctl/v2/pkg/yamlbundle"
 )
 
 type DeploymentItem struct {
@@ -93,7 +94,7 @@ func NewDeploymentItem(ctx SharedContext, project *DeploymentProject, collection
 	return di, nil
 }
 
-func (di *DeploymentItem) getCommonLabels() map[string]string {
+func (di *DeploymentItem) GetCommonLabels() map[string]string {
 	l := di.Project.GetCommonLabels()
 	if di.ctx.Discriminator != "" {
 		l["kluctl.io/discriminator"] = di.ctx.Discriminator
@@ -106,7 +107,7 @@ func (di *DeploymentItem) getCommonLabels() map[string]string {
 	return l
 }
 
-func (di *DeploymentItem) getCommonAnnotations() map[string]string {
+func (di *DeploymentItem) GetCommonAnnotations() map[string]string {
 	a := di.Project.GetCommonAnnotations()
 	a["kluctl.io/deployment-item-dir"] = filepath.ToSlash(di.RelToSourceItemDir)
 	if di.Config.SkipDeleteIfTags {
@@ -245,6 +246,47 @@ func (di *DeploymentItem) renderHelmCharts() error {
 	return nil
 }
 
+func (di *DeploymentItem) renderYamlBundle() error {
+	if di.dir == nil || di.Config.YamlBundle == nil {
+		return nil
+	}
+
+	searchDirs := di.Project.getRenderSearchDirs()
+	searchDirs = append([]string{*di.dir}, searchDirs...)
+
+	buf := bytes.NewBuffer(nil)
+	for _, u := range di.Config.YamlBundle.Urls {
+		b, err := yamlbundle.Fetch(di.ctx.Ctx, u)
+		if err != nil {
+			return err
+		}
+		s := string(b)
+		if di.Config.YamlBundle.Template {
+			s, err = di.VarsCtx.RenderString(s, searchDirs)
+			if err != nil {
+				return fmt.Errorf("rendering yaml bundle %s failed: %w", u.Url.Redacted(), err)
+			}
+		}
+		if buf.Len() != 0 {
+			buf.WriteString("---\n")
+		}
+		buf.WriteString(s)
+		if !strings.HasSuffix(s, "\n") {
+			buf.WriteString("\n")
+		}
+	}
+
+	ky, err := di.readKustomizationYaml("")
+	if err == nil && ky != nil {
+		resources, _, _ := ky.GetNestedStringList("resources")
+		if !slices.Contains(resources, yamlbundle.OutputFileName) {
+			return fmt.Errorf("%s/kustomization.yaml does not include the rendered yaml bundle: %s", di.RelRenderedDir, yamlbundle.OutputFileName)
+		}
+	}
+
+	return os.WriteFile(filepath.Join(di.RenderedDir, yamlbundle.OutputFileName), buf.Bytes(), 0o600)
+}
+
 func (di *DeploymentItem) buildInclusionEntries() []utils.InclusionEntry {
 	var values []utils.InclusionEntry
 	for _, t := range di.Tags.ListKeys() {
@@ -401,6 +443,11 @@ func (di *DeploymentItem) buildKustomize() error {
 		return err
 	}
 
+	err = di.flattenListsInResources("", ky)
+	if err != nil {
+		return err
+	}
+
 	fs, err := securefs.MakeFsOnDiskSecureBuild(di.RenderedSourceRootDir)
 	if err != nil {
 		return err
@@ -422,6 +469,12 @@ func (di *DeploymentItem) buildKustomize() error {
 		di.Objects = append(di.Objects, o)
 	}
 
+	// kustomize inlines Lists on its own, but generators and plugins might still produce them
+	di.Objects, _, err = flattenListObjects(di.Objects, di.RelRenderedDir)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -432,8 +485,8 @@ func (di *DeploymentItem) postprocessObjects(images *Images) error {
 
 	var errs *multierror.Error
 
-	commonLabels := di.getCommonLabels()
-	commonAnnotations := di.getCommonAnnotations()
+	commonLabels := di.GetCommonLabels()
+	commonAnnotations := di.GetCommonAnnotations()
 
 	checkIgnore := func(o *uo.UnstructuredObject) bool {
 		ignore, _ := o.GetK8sAnnotationBool("kluctl.io/ignore", false)
@@ -460,20 +513,6 @@ func (di *DeploymentItem) postprocessObjects(images *Images) error {
 
 	for _, o := range di.Objects {
 		handleObject(o)
-
-		if k8s.IsListGVK(o.GetK8sGVK()) {
-			items, ok, err := o.GetNestedObjectList("items")
-			if err != nil {
-				errs = multierror.Append(errs, err)
-				continue
-			} else if ok {
-				items = slices.DeleteFunc(items, checkIgnore)
-				for _, o := range items {
-					handleObject(o)
-				}
-				_ = o.SetNestedObjectList(items, "items")
-			}
-		}
 	}
 
 	return errs.ErrorOrNil()
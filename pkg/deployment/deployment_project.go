@@ -3,6 +3,7 @@ package deployment
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	securejoin "github.com/cyphar/filepath-securejoin"
@@ -88,7 +89,9 @@ func (p *DeploymentProject) loadConfig() error {
 	}
 	configPath = yaml.FixPathExt(configPath)
 
-	err := p.VarsCtx.RenderYamlFile(configPath, p.getRenderSearchDirs(), &p.Config)
+	err := p.readConfigFile(configPath, func(opts yaml.ReadOptions) ([]yaml.UnknownField, error) {
+		return p.VarsCtx.RenderYamlFileWithOptions(configPath, p.getRenderSearchDirs(), &p.Config, opts)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to load deployment.yml: %w", err)
 	}
@@ -96,6 +99,26 @@ func (p *DeploymentProject) loadConfig() error {
 	return p.processConfig()
 }
 
+// readConfigFile invokes read with the options that are required to report unknown fields relative to the project
+// source and to honor lenient mode
+func (p *DeploymentProject) readConfigFile(configPath string, read func(opts yaml.ReadOptions) ([]yaml.UnknownField, error)) error {
+	fileName, err := filepath.Rel(p.source.dir, configPath)
+	if err != nil {
+		fileName = configPath
+	}
+	unknownFields, err := read(yaml.ReadOptions{
+		FileName: fileName,
+		Lenient:  p.ctx.Lenient,
+	})
+	if err != nil {
+		return err
+	}
+	if len(unknownFields) != 0 && p.ctx.ConfigWarnings != nil {
+		p.ctx.ConfigWarnings.AddUnknownFields(unknownFields)
+	}
+	return nil
+}
+
 func (p *DeploymentProject) generateSingleKustomizeProject() error {
 	p.Config.Deployments = append(p.Config.Deployments, types.DeploymentItemConfig{
 		Path: utils.Ptr("."),
@@ -249,7 +272,9 @@ func (p *DeploymentProject) loadLocalInclude(source Source, incDir string, inc *
 	libraryFile := yaml.FixPathExt(filepath.Join(source.dir, incDir, ".kluctl-library.yaml"))
 	if yaml.Exists(libraryFile) {
 		var lib types.KluctlLibraryProject
-		err := yaml.ReadYamlFile(libraryFile, &lib)
+		err := p.readConfigFile(libraryFile, func(opts yaml.ReadOptions) ([]yaml.UnknownField, error) {
+			return yaml.ReadYamlFileWithOptions(libraryFile, &lib, opts)
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -398,6 +423,16 @@ func (p *DeploymentProject) GetIgnoreForDiffs(ignoreTags, ignoreLabels, ignoreAn
 	return ret
 }
 
+// GetListMergeKeys returns the list merge keys of this project and all its parents, with the configs of child projects
+// coming first
+func (p *DeploymentProject) GetListMergeKeys() []types.ListMergeKeysConfig {
+	var ret []types.ListMergeKeysConfig
+	for _, e := range p.getParents() {
+		ret = append(ret, e.p.Config.ListMergeKeys...)
+	}
+	return ret
+}
+
 func (p *DeploymentProject) GetConflictResolutionConfigs() []types.ConflictResolutionConfig {
 	var ret []types.ConflictResolutionConfig
 	for _, e := range p.getParents() {
@@ -405,3 +440,42 @@ func (p *DeploymentProject) GetConflictResolutionConfigs() []types.ConflictResol
 	}
 	return ret
 }
+
+// GetObfuscationRules returns the obfuscation rules of this project and all included projects. Obfuscation is
+// applied to whole command results, so the rules of included projects are not restricted to their own objects.
+func (p *DeploymentProject) GetObfuscationRules() []types.ObfuscationRule {
+	ret := append([]types.ObfuscationRule{}, p.Config.Obfuscate...)
+	indexes := make([]int, 0, len(p.includes))
+	for i := range p.includes {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		ret = append(ret, p.includes[i].GetObfuscationRules()...)
+	}
+	return ret
+}
+
+// GetObjectOrder returns the object order built from the priority tables of this project and all its parents, with
+// the tables of child projects having precedence
+func (p *DeploymentProject) GetObjectOrder() *ObjectOrder {
+	var configs []types.ObjectOrderConfig
+	parents := p.getParents()
+	for i := len(parents) - 1; i >= 0; i-- {
+		configs = append(configs, parents[i].p.Config.ObjectOrder...)
+	}
+	ret := NewObjectOrder(configs)
+	ret.argoCDSyncWaves = p.IsArgoCDCompatibility()
+	return ret
+}
+
+// IsArgoCDCompatibility returns true if Argo CD annotations should be mapped to kluctl ordering and hooks. The setting
+// is inherited from parent projects unless overridden.
+func (p *DeploymentProject) IsArgoCDCompatibility() bool {
+	for _, e := range p.getParents() {
+		if e.p.Config.ArgoCDCompatibility != nil {
+			return *e.p.Config.ArgoCDCompatibility
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+// Package events implements streaming of deployment events, e.g. applied objects and executed hooks, while a
+// deployment is still running. Events are routed through the context, so that the places that actually perform the
+// deployment can emit them without knowing if and where they are streamed to.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+type EventType string
+
+const (
+	ObjectApplied EventType = "objectApplied"
+	ObjectChanged EventType = "objectChanged"
+	HookStarted   EventType = "hookStarted"
+	HookFinished  EventType = "hookFinished"
+	Error         EventType = "error"
+	Warning       EventType = "warning"
+	PruneDeleted  EventType = "pruneDeleted"
+	Summary       EventType = "summary"
+)
+
+type Event struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+
+	Ref     *k8s2.ObjectRef `json:"ref,omitempty"`
+	Message string          `json:"message,omitempty"`
+
+	// New is set on objectApplied events for objects that did not exist before
+	New bool `json:"new,omitempty"`
+	// Changes is the number of changes of objectChanged events
+	Changes int `json:"changes,omitempty"`
+	// Hooks contains the hook types (e.g. pre-deploy) of hookStarted and hookFinished events
+	Hooks []string `json:"hooks,omitempty"`
+	// Success is set on hookFinished events
+	Success *bool `json:"success,omitempty"`
+
+	// Summary is only set on the summary event, which is always the last event
+	Summary *result.CommandResultSummary `json:"summary,omitempty"`
+}
+
+// Sink receives all emitted events. Implementations must be safe for concurrent use, as deployment items are
+// deployed in parallel.
+type Sink interface {
+	Emit(e Event)
+}
+
+type contextKey struct{}
+
+// NewContext returns a context that routes all emitted events to the given sink. A nil sink disables events, which
+// is for example used for the dry-run that precedes a deployment.
+func NewContext(ctx context.Context, sink Sink) context.Context {
+	return context.WithValue(ctx, contextKey{}, sink)
+}
+
+// WithoutEvents returns a context in which emitted events are dropped
+func WithoutEvents(ctx context.Context) context.Context {
+	return NewContext(ctx, nil)
+}
+
+func FromContext(ctx context.Context) Sink {
+	v, _ := ctx.Value(contextKey{}).(Sink)
+	return v
+}
+
+// Emit sends the event to the sink of the context, if any
+func Emit(ctx context.Context, e Event) {
+	sink := FromContext(ctx)
+	if sink == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	sink.Emit(e)
+}
+
+// EmitForRef is a shortcut for events that only carry an object ref and an optional message
+func EmitForRef(ctx context.Context, t EventType, ref k8s2.ObjectRef, message string) {
+	e := Event{
+		Type:    t,
+		Message: message,
+	}
+	if ref != (k8s2.ObjectRef{}) {
+		e.Ref = &ref
+	}
+	Emit(ctx, e)
+}
+
+// JsonLinesSink writes one JSON document per line and event
+type JsonLinesSink struct {
+	w     io.Writer
+	mutex sync.Mutex
+	err   error
+}
+
+func NewJsonLinesSink(w io.Writer) *JsonLinesSink {
+	return &JsonLinesSink{w: w}
+}
+
+func (s *JsonLinesSink) Emit(e Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		s.err = err
+		return
+	}
+	b = append(b, '\n')
+	_, s.err = s.w.Write(b)
+}
+
+// Err returns the first error that happened while writing events. Events are dropped after the first error.
+func (s *JsonLinesSink) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
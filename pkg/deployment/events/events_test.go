@@ -0,0 +1,79 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmitWithoutSink(t *testing.T) {
+	// must not panic
+	Emit(context.Background(), Event{Type: ObjectApplied})
+	assert.Nil(t, FromContext(context.Background()))
+}
+
+func TestJsonLinesSink(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	sink := NewJsonLinesSink(buf)
+	ctx := NewContext(context.Background(), sink)
+
+	ref := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: "cm"}
+	EmitForRef(ctx, ObjectApplied, ref, "")
+	EmitForRef(ctx, Error, k8s2.ObjectRef{}, "failed")
+	Emit(ctx, Event{Type: Summary, Summary: &result.CommandResultSummary{NewObjects: 1}})
+
+	// the dry-run preceding a deployment must not emit events
+	EmitForRef(WithoutEvents(ctx), ObjectApplied, ref, "")
+
+	assert.NoError(t, sink.Err())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+
+	var events []Event
+	for _, l := range lines {
+		var e Event
+		assert.NoError(t, json.Unmarshal([]byte(l), &e))
+		assert.False(t, e.Time.IsZero())
+		events = append(events, e)
+	}
+
+	assert.Equal(t, ObjectApplied, events[0].Type)
+	assert.Equal(t, &ref, events[0].Ref)
+	assert.Equal(t, Error, events[1].Type)
+	assert.Nil(t, events[1].Ref)
+	assert.Equal(t, "failed", events[1].Message)
+	assert.Equal(t, Summary, events[2].Type)
+	assert.Equal(t, 1, events[2].Summary.NewObjects)
+	assert.NotContains(t, lines[0], "summary")
+}
+
+func TestJsonLinesSinkConcurrent(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	ctx := NewContext(context.Background(), NewJsonLinesSink(buf))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				Emit(ctx, Event{Type: Warning, Message: "w"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 100)
+	for _, l := range lines {
+		assert.True(t, json.Valid([]byte(l)), l)
+	}
+}
@@ -5,9 +5,10 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
-	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/deprecations"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/projectlock"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
@@ -287,7 +288,7 @@ func (images *Images) ResolvePlaceholders(ctx context.Context, k *k8s.K8sCluster
 
 func (images *Images) resolveImage(ctx context.Context, ph placeHolder, ref k8s2.ObjectRef, deployment string, deployed *string, deploymentDir string, tags []string, vars *uo.UnstructuredObject) (*string, error) {
 	if ph.HasLatestVersion {
-		status.Deprecation(ctx, "latest-version-filter", "latest_version is deprecated when using images.get_image() and is completely ignored. Please remove usages of latest_version as it will fail to render in a future kluctl release.")
+		deprecations.Report(ctx, deprecations.LatestVersionFilter)
 	}
 
 	result, err := images.getFixedImage(ph.Image, ref.Namespace, deployment, ph.Container, vars)
@@ -308,6 +309,12 @@ func (images *Images) resolveImage(ctx context.Context, ph placeHolder, ref k8s2
 	if result != nil {
 		si.ResultImage = *result
 	}
+	projectlock.GetRecorder(ctx).RecordImage(types.ImageLock{
+		Image:       ph.Image,
+		Object:      ref.String(),
+		Container:   ph.Container,
+		ResultImage: si.ResultImage,
+	})
 	images.mutex.Lock()
 	images.seenImages = append(images.seenImages, si)
 	images.mutex.Unlock()
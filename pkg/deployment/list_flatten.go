@@ -0,0 +1,126 @@
+package deployment
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// flattenListObjects replaces all v1/List objects with their items, so that the items are handled as first-class
+// objects in diff, orphan detection and pruning. The returned bool tells if any List was found. source is used in
+// error messages to tell where the List originates from.
+func flattenListObjects(objects []*uo.UnstructuredObject, source string) ([]*uo.UnstructuredObject, bool, error) {
+	var ret []*uo.UnstructuredObject
+	found := false
+	for docIdx, o := range objects {
+		if !k8s.IsListGVK(o.GetK8sGVK()) {
+			ret = append(ret, o)
+			continue
+		}
+		found = true
+
+		listSource := source
+		if len(objects) > 1 {
+			listSource = fmt.Sprintf("%s, document %d", source, docIdx)
+		}
+
+		items, _, err := o.GetNestedObjectList("items")
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: invalid List: %w", listSource, err)
+		}
+		for i, item := range items {
+			gvk := item.GetK8sGVK()
+			if gvk.Kind == "" || gvk.Version == "" {
+				return nil, false, fmt.Errorf("%s: item %d of List is missing apiVersion or kind", listSource, i)
+			}
+			if gvk.Kind == "List" {
+				return nil, false, fmt.Errorf("%s: item %d of List is a List itself, nested Lists are not supported", listSource, i)
+			}
+			ret = append(ret, item)
+		}
+	}
+	return ret, found, nil
+}
+
+// flattenListsInResources flattens Lists inside all local resource files referenced by the kustomization.yml found
+// in subDir, recursing into sub-directories. This happens before kustomize is invoked, so that errors can point to
+// the file containing the List and so that nested Lists are rejected instead of being silently inlined by kustomize.
+// Files are only rewritten if they actually contain a List.
+func (di *DeploymentItem) flattenListsInResources(subDir string, ky *uo.UnstructuredObject) error {
+	resources, _, err := ky.GetNestedList("resources")
+	if err != nil {
+		return err
+	}
+
+	for _, r := range resources {
+		s, ok := r.(string)
+		if !ok {
+			continue
+		}
+		relPath := path.Join(filepath.ToSlash(subDir), s)
+		if path.IsAbs(s) || relPath == ".." || strings.HasPrefix(relPath, "../") {
+			// leave it to kustomize to handle or reject it
+			continue
+		}
+		p := filepath.Join(di.RenderedDir, filepath.FromSlash(relPath))
+
+		if utils.IsDirectory(p) {
+			subKy, err := di.readKustomizationYaml(relPath)
+			if err != nil {
+				return err
+			}
+			if subKy != nil {
+				err = di.flattenListsInResources(relPath, subKy)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		lname := strings.ToLower(s)
+		if !utils.IsFile(p) || !(strings.HasSuffix(lname, ".yml") || strings.HasSuffix(lname, ".yaml")) {
+			continue
+		}
+
+		err = di.flattenListsInFile(p, relPath)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (di *DeploymentItem) flattenListsInFile(p string, relPath string) error {
+	objects, err := uo.FromFileMulti(p)
+	if err != nil {
+		// let kustomize report the actual error
+		return nil
+	}
+	for _, o := range objects {
+		if _, ok := o.Object["sops"]; ok {
+			// rewriting would break the MAC of encrypted files
+			return nil
+		}
+	}
+
+	objects, found, err := flattenListObjects(objects, relPath)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+
+	l := make([]any, 0, len(objects))
+	for _, o := range objects {
+		l = append(l, o.Object)
+	}
+	return yaml.WriteYamlAllFile(p, l)
+}
@@ -0,0 +1,99 @@
+package deployment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlattenListObjects(t *testing.T) {
+	objects, err := uo.FromStringMulti(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: cm2
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    name: s1
+`)
+	assert.NoError(t, err)
+
+	flattened, found, err := flattenListObjects(objects, "test.yaml")
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	var names []string
+	for _, o := range flattened {
+		names = append(names, o.GetK8sRef().String())
+	}
+	assert.Equal(t, []string{"ConfigMap/cm1", "ConfigMap/cm2", "Secret/s1"}, names)
+
+	flattened, found, err = flattenListObjects(objects[:1], "test.yaml")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.Len(t, flattened, 1)
+}
+
+func TestFlattenListObjectsErrors(t *testing.T) {
+	nested := uo.FromStringMust(`{"apiVersion": "v1", "kind": "List", "items": [
+		{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm"}},
+		{"apiVersion": "v1", "kind": "List", "items": []}]}`)
+	_, _, err := flattenListObjects([]*uo.UnstructuredObject{nested}, "test.yaml")
+	assert.EqualError(t, err, "test.yaml: item 1 of List is a List itself, nested Lists are not supported")
+
+	invalid := uo.FromStringMust(`{"apiVersion": "v1", "kind": "List", "items": [{"metadata": {"name": "cm"}}]}`)
+	cm := uo.FromStringMust(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm"}}`)
+	_, _, err = flattenListObjects([]*uo.UnstructuredObject{cm, invalid}, "test.yaml")
+	assert.EqualError(t, err, "test.yaml, document 1: item 0 of List is missing apiVersion or kind")
+}
+
+func TestFlattenListsInResources(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+
+	listYaml := `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: cm1
+`
+	plainYaml := `# must stay untouched
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm2
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "list.yaml"), []byte(listYaml), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "plain.yaml"), []byte(plainYaml), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "kustomization.yml"), []byte("resources:\n- list.yaml\n"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "list.yaml"), []byte(listYaml), 0o600))
+
+	di := &DeploymentItem{dir: &dir, RenderedDir: dir}
+	ky := uo.FromStringMust(`{"resources": ["list.yaml", "plain.yaml", "sub", "../outside.yaml"]}`)
+	assert.NoError(t, di.flattenListsInResources("", ky))
+
+	for _, p := range []string{"list.yaml", "sub/list.yaml"} {
+		objects, err := uo.FromFileMulti(filepath.Join(dir, p))
+		assert.NoError(t, err)
+		assert.Len(t, objects, 1)
+		assert.Equal(t, "ConfigMap/cm1", objects[0].GetK8sRef().String())
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "plain.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, plainYaml, string(b))
+}
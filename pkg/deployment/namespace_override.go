@@ -0,0 +1,106 @@
+package deployment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// namespaceRefFields contains the fields of well known kinds that reference namespaces of other objects. The first
+// element of each path may point to a list, in which case the rest of the path is applied to each list item.
+var namespaceRefFields = map[string][][]string{
+	"RoleBinding.rbac.authorization.k8s.io":                       {{"subjects", "namespace"}},
+	"ClusterRoleBinding.rbac.authorization.k8s.io":                {{"subjects", "namespace"}},
+	"MutatingWebhookConfiguration.admissionregistration.k8s.io":   {{"webhooks", "clientConfig", "service", "namespace"}},
+	"ValidatingWebhookConfiguration.admissionregistration.k8s.io": {{"webhooks", "clientConfig", "service", "namespace"}},
+	"APIService.apiregistration.k8s.io":                           {{"spec", "service", "namespace"}},
+	"CustomResourceDefinition.apiextensions.k8s.io":               {{"spec", "conversion", "webhook", "clientConfig", "service", "namespace"}},
+}
+
+// applyNamespaceOverride moves all namespaced objects into the override namespace. References to the original
+// namespaces in well known fields (e.g. RoleBinding subjects) and Namespace objects of the original namespaces are
+// rewritten as well. Objects from multiple namespaces being moved into the same namespace is reported as conflict.
+func (c *DeploymentCollection) applyNamespaceOverride() error {
+	override := c.ctx.NamespaceOverride
+	if override == "" {
+		return nil
+	}
+
+	originals := map[string]int{}
+	for _, d := range c.Deployments {
+		for _, o := range d.Objects {
+			ns := o.GetK8sNamespace()
+			if ns != "" && ns != override {
+				originals[ns]++
+			}
+		}
+	}
+
+	if len(originals) > 1 {
+		var l []string
+		for ns, cnt := range originals {
+			l = append(l, fmt.Sprintf("%s (%d objects)", ns, cnt))
+		}
+		sort.Strings(l)
+		msg := fmt.Sprintf("namespaceOverride moves objects from multiple namespaces into namespace %s: %s", override, strings.Join(l, ", "))
+		if c.ctx.StrictNamespaceOverride {
+			return fmt.Errorf("%s", msg)
+		}
+		status.Warning(c.ctx.Ctx, msg)
+	}
+
+	for _, d := range c.Deployments {
+		for _, o := range d.Objects {
+			ref := o.GetK8sRef()
+			if ref.Namespace != "" {
+				o.SetK8sNamespace(override)
+			} else if ref.GroupKind().String() == "Namespace" {
+				if _, ok := originals[ref.Name]; ok {
+					o.SetK8sName(override)
+				}
+			}
+
+			for _, p := range namespaceRefFields[ref.GroupKind().String()] {
+				err := rewriteNamespaceRef(o, p, originals, override)
+				if err != nil {
+					return fmt.Errorf("failed to override namespace in %s: %w", ref.String(), err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func rewriteNamespaceRef(o *uo.UnstructuredObject, path []string, originals map[string]int, override string) error {
+	keys := make([]any, len(path))
+	for i, k := range path {
+		keys[i] = k
+	}
+
+	items := []*uo.UnstructuredObject{o}
+	if l, ok, _ := o.GetNestedObjectList(keys[0]); ok {
+		items = l
+		keys = keys[1:]
+	}
+
+	for _, item := range items {
+		ns, ok, err := item.GetNestedString(keys...)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if _, ok := originals[ns]; !ok {
+			continue
+		}
+		err = item.SetNestedField(override, keys...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package deployment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newNamespaceOverrideTestCollection(strict bool, objects ...*uo.UnstructuredObject) *DeploymentCollection {
+	return &DeploymentCollection{
+		ctx: SharedContext{
+			Ctx:                     context.Background(),
+			NamespaceOverride:       "preview-1",
+			StrictNamespaceOverride: strict,
+		},
+		Deployments: []*DeploymentItem{{Objects: objects}},
+	}
+}
+
+func mustNestedString(o *uo.UnstructuredObject, keys ...any) string {
+	s, _, _ := o.GetNestedString(keys...)
+	return s
+}
+
+func TestApplyNamespaceOverride(t *testing.T) {
+	ns := uo.FromStringMust(`{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "app"}}`)
+	cm := uo.FromStringMust(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm", "namespace": "app"}}`)
+	crb := uo.FromStringMust(`{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRoleBinding", "metadata": {"name": "crb"},
+		"subjects": [{"kind": "ServiceAccount", "name": "sa", "namespace": "app"}, {"kind": "ServiceAccount", "name": "sa", "namespace": "kube-system"}]}`)
+	webhook := uo.FromStringMust(`{"apiVersion": "admissionregistration.k8s.io/v1", "kind": "ValidatingWebhookConfiguration", "metadata": {"name": "webhook"},
+		"webhooks": [{"name": "w", "clientConfig": {"service": {"name": "svc", "namespace": "app"}}}]}`)
+
+	c := newNamespaceOverrideTestCollection(true, ns, cm, crb, webhook)
+	assert.NoError(t, c.applyNamespaceOverride())
+
+	assert.Equal(t, "preview-1", ns.GetK8sName())
+	assert.Equal(t, "preview-1", cm.GetK8sNamespace())
+
+	subjects, _, _ := crb.GetNestedObjectList("subjects")
+	assert.Equal(t, "preview-1", mustNestedString(subjects[0], "namespace"))
+	assert.Equal(t, "kube-system", mustNestedString(subjects[1], "namespace"))
+
+	assert.Equal(t, "preview-1", mustNestedString(webhook, "webhooks", 0, "clientConfig", "service", "namespace"))
+}
+
+func TestApplyNamespaceOverrideConflict(t *testing.T) {
+	newObjects := func() []*uo.UnstructuredObject {
+		return []*uo.UnstructuredObject{
+			uo.FromStringMust(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm", "namespace": "a"}}`),
+			uo.FromStringMust(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm", "namespace": "b"}}`),
+		}
+	}
+
+	objects := newObjects()
+	c := newNamespaceOverrideTestCollection(false, objects...)
+	assert.NoError(t, c.applyNamespaceOverride())
+	assert.Equal(t, "preview-1", objects[0].GetK8sNamespace())
+	assert.Equal(t, "preview-1", objects[1].GetK8sNamespace())
+
+	c = newNamespaceOverrideTestCollection(true, newObjects()...)
+	assert.ErrorContains(t, c.applyNamespaceOverride(), "namespaceOverride moves objects from multiple namespaces into namespace preview-1: a (1 objects), b (1 objects)")
+}
@@ -0,0 +1,143 @@
+package deployment
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"sort"
+	"strconv"
+)
+
+const ObjectOrderWeightAnnotation = "kluctl.io/order-weight"
+
+// these are the same kinds and the same order as used by kustomize's legacy ordering, which is what objects are
+// sorted by when they are rendered
+var defaultOrderFirst = []string{
+	"Namespace",
+	"ResourceQuota",
+	"StorageClass",
+	"CustomResourceDefinition",
+	"ServiceAccount",
+	"PodSecurityPolicy",
+	"Role",
+	"ClusterRole",
+	"RoleBinding",
+	"ClusterRoleBinding",
+	"ConfigMap",
+	"Secret",
+	"Endpoints",
+	"Service",
+	"LimitRange",
+	"PriorityClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"Deployment",
+	"StatefulSet",
+	"CronJob",
+	"PodDisruptionBudget",
+}
+
+var defaultOrderLast = []string{
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+}
+
+// DefaultObjectOrder returns the built-in priority table. Kinds not listed get a weight of 0.
+func DefaultObjectOrder() []types.ObjectOrderConfig {
+	var ret []types.ObjectOrderConfig
+	add := func(kind string, weight int) {
+		ret = append(ret, types.ObjectOrderConfig{Kind: &kind, Weight: weight})
+	}
+	for i, k := range defaultOrderFirst {
+		add(k, (i-len(defaultOrderFirst))*10)
+	}
+	for i, k := range defaultOrderLast {
+		add(k, (i+1)*10)
+	}
+	return ret
+}
+
+// ObjectOrder determines the order in which objects are applied, deleted (reversed) and listed
+type ObjectOrder struct {
+	configs         []types.ObjectOrderConfig
+	argoCDSyncWaves bool
+}
+
+// NewObjectOrder creates an ObjectOrder on top of the built-in priority table. Later entries have precedence over
+// earlier entries.
+func NewObjectOrder(configs []types.ObjectOrderConfig) *ObjectOrder {
+	return &ObjectOrder{
+		configs: append(DefaultObjectOrder(), configs...),
+	}
+}
+
+func (o *ObjectOrder) GetWeightForRef(ref k8s2.ObjectRef) int {
+	if o == nil {
+		return 0
+	}
+	for i := len(o.configs) - 1; i >= 0; i-- {
+		c := o.configs[i]
+		if c.Group != nil && *c.Group != ref.Group {
+			continue
+		}
+		if c.Kind != nil && *c.Kind != ref.Kind {
+			continue
+		}
+		return c.Weight
+	}
+	return 0
+}
+
+// GetWeight returns the weight of the given object. The weight annotation has precedence over the priority table.
+// If the annotation is invalid, the table weight and an error are returned.
+func (o *ObjectOrder) GetWeight(x *uo.UnstructuredObject) (int, error) {
+	w, _, err := o.GetWeightAndSource(x)
+	return w, err
+}
+
+// GetWeightAndSource is like GetWeight, but additionally describes where the weight originates from. When Argo CD
+// compatibility is enabled, the sync-wave annotation is used if no weight annotation is present.
+func (o *ObjectOrder) GetWeightAndSource(x *uo.UnstructuredObject) (int, string, error) {
+	ref := x.GetK8sRef()
+	w := o.GetWeightForRef(ref)
+	source := "priority table"
+	s := x.GetK8sAnnotation(ObjectOrderWeightAnnotation)
+	if s != nil {
+		w2, err := strconv.ParseInt(*s, 10, 32)
+		if err != nil {
+			return w, source, fmt.Errorf("failed to parse %s annotation: %w", ObjectOrderWeightAnnotation, err)
+		}
+		return int(w2), ObjectOrderWeightAnnotation, nil
+	}
+	if o != nil && o.argoCDSyncWaves {
+		wave, err := GetArgoCDSyncWave(x)
+		if err != nil {
+			return w, source, err
+		}
+		if wave != nil {
+			return *wave*ArgoCDSyncWaveWeightFactor + w, ArgoCDSyncWaveAnnotation, nil
+		}
+	}
+	return w, source, nil
+}
+
+// SortObjects sorts the given objects by ascending weight. Objects with the same weight keep their order.
+func (o *ObjectOrder) SortObjects(objects []*uo.UnstructuredObject, onError func(ref k8s2.ObjectRef, err error)) {
+	weights := make(map[*uo.UnstructuredObject]int, len(objects))
+	for _, x := range objects {
+		w, err := o.GetWeight(x)
+		if err != nil && onError != nil {
+			onError(x.GetK8sRef(), err)
+		}
+		weights[x] = w
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return weights[objects[i]] < weights[objects[j]]
+	})
+}
+
+// GetConfigs returns all entries of the priority table that were explicitly configured
+func (o *ObjectOrder) GetConfigs() []types.ObjectOrderConfig {
+	return o.configs[len(DefaultObjectOrder()):]
+}
@@ -0,0 +1,107 @@
+package deployment
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newOrderTestObject(apiVersion string, kind string, name string, weight string) *uo.UnstructuredObject {
+	o := uo.New()
+	_ = o.SetNestedField(apiVersion, "apiVersion")
+	_ = o.SetNestedField(kind, "kind")
+	o.SetK8sName(name)
+	if weight != "" {
+		o.SetK8sAnnotation(ObjectOrderWeightAnnotation, weight)
+	}
+	return o
+}
+
+func orderTestNames(objects []*uo.UnstructuredObject) []string {
+	var ret []string
+	for _, o := range objects {
+		ret = append(ret, o.GetK8sName())
+	}
+	return ret
+}
+
+func TestObjectOrderDefaults(t *testing.T) {
+	objects := []*uo.UnstructuredObject{
+		newOrderTestObject("admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", "webhook", ""),
+		newOrderTestObject("apps/v1", "Deployment", "deployment", ""),
+		newOrderTestObject("example.com/v1", "MyResource", "custom", ""),
+		newOrderTestObject("v1", "Secret", "secret", ""),
+		newOrderTestObject("v1", "Namespace", "namespace", ""),
+	}
+	NewObjectOrder(nil).SortObjects(objects, nil)
+	assert.Equal(t, []string{"namespace", "secret", "deployment", "custom", "webhook"}, orderTestNames(objects))
+}
+
+func TestObjectOrderConfigs(t *testing.T) {
+	secret := "Secret"
+	group := "example.com"
+	objects := []*uo.UnstructuredObject{
+		newOrderTestObject("apps/v1", "Deployment", "deployment", ""),
+		newOrderTestObject("example.com/v1", "MyResource", "custom", ""),
+		newOrderTestObject("v1", "Namespace", "namespace", ""),
+		newOrderTestObject("v1", "Secret", "secret", ""),
+	}
+	NewObjectOrder([]types.ObjectOrderConfig{
+		{Kind: &secret, Weight: -1000},
+		{Group: &group, Weight: -500},
+	}).SortObjects(objects, nil)
+	assert.Equal(t, []string{"secret", "custom", "namespace", "deployment"}, orderTestNames(objects))
+}
+
+func TestObjectOrderAnnotation(t *testing.T) {
+	objects := []*uo.UnstructuredObject{
+		newOrderTestObject("v1", "ConfigMap", "cm1", ""),
+		newOrderTestObject("v1", "ConfigMap", "cm2", "-1000"),
+		newOrderTestObject("v1", "ConfigMap", "cm3", "invalid"),
+		newOrderTestObject("v1", "Namespace", "namespace", ""),
+	}
+	var errors []string
+	NewObjectOrder(nil).SortObjects(objects, func(ref k8s2.ObjectRef, err error) {
+		errors = append(errors, ref.Name)
+	})
+	assert.Equal(t, []string{"cm2", "namespace", "cm1", "cm3"}, orderTestNames(objects))
+	assert.Equal(t, []string{"cm3"}, errors)
+}
+
+func TestObjectOrderArgoCDSyncWaves(t *testing.T) {
+	newWaveObject := func(kind string, name string, wave string, weight string) *uo.UnstructuredObject {
+		o := newOrderTestObject("v1", kind, name, weight)
+		if wave != "" {
+			o.SetK8sAnnotation(ArgoCDSyncWaveAnnotation, wave)
+		}
+		return o
+	}
+	build := func() []*uo.UnstructuredObject {
+		return []*uo.UnstructuredObject{
+			newWaveObject("ConfigMap", "cm-wave1", "1", ""),
+			newWaveObject("Secret", "secret-wave1", "1", ""),
+			newWaveObject("ConfigMap", "cm", "", ""),
+			newWaveObject("Namespace", "ns-wave2", "2", ""),
+			newWaveObject("ConfigMap", "cm-wave-1", "-1", ""),
+			newWaveObject("ConfigMap", "cm-weight", "-5", "5000"),
+		}
+	}
+
+	// sync-waves are ignored when compatibility is disabled
+	objects := build()
+	NewObjectOrder(nil).SortObjects(objects, nil)
+	assert.Equal(t, []string{"ns-wave2", "cm-wave1", "cm", "cm-wave-1", "secret-wave1", "cm-weight"}, orderTestNames(objects))
+
+	objects = build()
+	o := NewObjectOrder(nil)
+	o.argoCDSyncWaves = true
+	o.SortObjects(objects, nil)
+	assert.Equal(t, []string{"cm-wave-1", "cm", "cm-wave1", "secret-wave1", "ns-wave2", "cm-weight"}, orderTestNames(objects))
+
+	w, source, err := o.GetWeightAndSource(objects[0])
+	assert.NoError(t, err)
+	assert.Equal(t, -1000+o.GetWeightForRef(objects[0].GetK8sRef()), w)
+	assert.Equal(t, ArgoCDSyncWaveAnnotation, source)
+}
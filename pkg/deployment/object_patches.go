@@ -0,0 +1,110 @@
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// applyObjectPatches applies all object patches to the matching rendered objects. Patches are applied in the order
+// they are specified, so later patches see the result of earlier patches.
+func (c *DeploymentCollection) applyObjectPatches() error {
+	if len(c.ctx.ObjectPatches) == 0 {
+		return nil
+	}
+
+	matched := make([]int, len(c.ctx.ObjectPatches))
+	for _, d := range c.Deployments {
+		var tags []string
+		if d.Tags != nil {
+			tags = d.Tags.ListKeys()
+		}
+		for i, o := range d.Objects {
+			for j, p := range c.ctx.ObjectPatches {
+				if !p.Selector.Matches(o, tags) {
+					continue
+				}
+				patched, err := applyObjectPatch(o, p)
+				if err != nil {
+					return fmt.Errorf("failed to apply patch with selector '%s' to %s: %w", p.Selector.String(), o.GetK8sRef().String(), err)
+				}
+				o = patched
+				d.Objects[i] = patched
+				matched[j]++
+			}
+		}
+	}
+	for i, p := range c.ctx.ObjectPatches {
+		if matched[i] == 0 {
+			status.Warningf(c.ctx.Ctx, "Patch with selector '%s' did not match any objects", p.Selector.String())
+		}
+	}
+	return nil
+}
+
+// applyObjectPatch applies the given patch to the object and returns the patched object. The original object is not
+// modified.
+func applyObjectPatch(o *uo.UnstructuredObject, p types.ObjectPatch) (*uo.UnstructuredObject, error) {
+	js, err := json.Marshal(o.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Patch != nil {
+		js, err = applyMergePatch(o, js, p.Patch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i, op := range p.JsonPatch {
+		// operations are applied one by one, so that the failing operation can be reported
+		opJs, err := json.Marshal([]any{op.Object})
+		if err != nil {
+			return nil, err
+		}
+		jp, err := jsonpatch.DecodePatch(opJs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operation %d (%s): %w", i, string(opJs[1:len(opJs)-1]), err)
+		}
+		js, err = jp.Apply(js)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s) failed: %w", i, string(opJs[1:len(opJs)-1]), err)
+		}
+	}
+
+	ret := uo.New()
+	err = json.Unmarshal(js, &ret.Object)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// applyMergePatch applies a strategic merge patch for kinds known to the client-go scheme and a JSON merge patch for
+// all other kinds
+func applyMergePatch(o *uo.UnstructuredObject, js []byte, patch *uo.UnstructuredObject) ([]byte, error) {
+	patchJs, err := json.Marshal(patch.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	dataStruct, err := scheme.Scheme.New(o.GetK8sGVK())
+	if err != nil {
+		ret, err := jsonpatch.MergePatch(js, patchJs)
+		if err != nil {
+			return nil, fmt.Errorf("merge patch failed: %w", err)
+		}
+		return ret, nil
+	}
+	ret, err := strategicpatch.StrategicMergePatch(js, patchJs, dataStruct)
+	if err != nil {
+		return nil, fmt.Errorf("strategic merge patch failed: %w", err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,130 @@
+package deployment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newObjectPatchTestCollection(patches []types.ObjectPatch, objects ...*uo.UnstructuredObject) *DeploymentCollection {
+	tags := &utils.OrderedMap[string, bool]{}
+	tags.Set("t1", true)
+	return &DeploymentCollection{
+		ctx: SharedContext{
+			Ctx:           context.Background(),
+			ObjectPatches: patches,
+		},
+		Deployments: []*DeploymentItem{{Objects: objects, Tags: tags}},
+	}
+}
+
+func TestApplyObjectPatches(t *testing.T) {
+	deployment := uo.FromStringMust(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: d1
+  namespace: ns
+  labels:
+    app: a
+spec:
+  template:
+    spec:
+      containers:
+      - name: c1
+        image: i1
+`)
+	cm := uo.FromStringMust(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+  namespace: ns
+data:
+  a: b
+`)
+	crd := uo.FromStringMust(`apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+  namespace: ns
+spec:
+  list:
+  - a
+`)
+
+	kind := func(s string) *string {
+		return &s
+	}
+	patches := []types.ObjectPatch{
+		{
+			// strategic merge patch, containers are merged by name
+			Selector: types.ObjectPatchSelector{Kind: kind("Deployment"), Labels: map[string]string{"app": "a"}},
+			Patch: uo.FromStringMust(`spec:
+  template:
+    spec:
+      containers:
+      - name: c2
+        image: i2
+`),
+		},
+		{
+			Selector: types.ObjectPatchSelector{Kind: kind("ConfigMap"), Tags: []string{"t1"}},
+			JsonPatch: []*uo.UnstructuredObject{
+				uo.FromMap(map[string]any{"op": "add", "path": "/data/c", "value": "d"}),
+			},
+		},
+		{
+			// unknown kinds get a JSON merge patch
+			Selector: types.ObjectPatchSelector{Kind: kind("Foo")},
+			Patch:    uo.FromStringMust(`{"spec": {"list": ["b"]}}`),
+		},
+		{
+			Selector: types.ObjectPatchSelector{Kind: kind("ConfigMap"), Tags: []string{"other"}},
+			Patch:    uo.FromStringMust(`{"data": {"x": "y"}}`),
+		},
+	}
+
+	c := newObjectPatchTestCollection(patches, deployment, cm, crd)
+	assert.NoError(t, c.applyObjectPatches())
+
+	objs := c.Deployments[0].Objects
+	containers, _, _ := objs[0].GetNestedObjectList("spec", "template", "spec", "containers")
+	assert.Len(t, containers, 2)
+
+	assert.Equal(t, map[string]any{"a": "b", "c": "d"}, objs[1].Object["data"])
+	l, _, _ := objs[2].GetNestedList("spec", "list")
+	assert.Equal(t, []any{"b"}, l)
+
+	// the original objects are not modified
+	_, ok, _ := cm.GetNestedString("data", "c")
+	assert.False(t, ok)
+}
+
+func TestApplyObjectPatchesError(t *testing.T) {
+	kind := "ConfigMap"
+	patches := []types.ObjectPatch{
+		{
+			Selector: types.ObjectPatchSelector{Kind: &kind},
+			JsonPatch: []*uo.UnstructuredObject{
+				uo.FromMap(map[string]any{"op": "add", "path": "/data/c", "value": "d"}),
+				uo.FromMap(map[string]any{"op": "remove", "path": "/data/missing"}),
+			},
+		},
+	}
+	cm := uo.FromStringMust(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+  namespace: ns
+data:
+  a: b
+`)
+
+	c := newObjectPatchTestCollection(patches, cm)
+	err := c.applyObjectPatches()
+	assert.ErrorContains(t, err, "failed to apply patch with selector 'kind=ConfigMap' to ns/ConfigMap/cm1: operation 1")
+	assert.ErrorContains(t, err, `"path":"/data/missing"`)
+}
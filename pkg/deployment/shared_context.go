@@ -2,11 +2,13 @@ package deployment
 
 import (
 	"context"
+	"github.com/kluctl/kluctl/v2/pkg/deprecations"
 	helm_auth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
 	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/vars"
 )
 
@@ -23,4 +25,26 @@ type SharedContext struct {
 
 	Discriminator string
 	RenderDir     string
+
+	// Lenient causes unknown fields in project config files to be reported as warnings instead of errors
+	Lenient        bool
+	ConfigWarnings *ConfigWarnings
+	// Deprecations records the deprecated features used while loading and rendering the project. It is also
+	// available via Ctx.
+	Deprecations *deprecations.Collector
+
+	// PruneExclude contains the effective prune exclusion rules, merged from the project config and the command line
+	PruneExclude []types.PruneExcludeRule
+	// IgnoreUnservedApiVersions contains patterns of API versions that are not reported when not served by the cluster
+	IgnoreUnservedApiVersions []string
+	// DeferredKinds contains patterns of group/kinds that are excluded from the missing kinds preflight check
+	DeferredKinds []string
+
+	// NamespaceOverride is the namespace that all namespaced objects are moved into, see types.Target
+	NamespaceOverride string
+	// StrictNamespaceOverride causes conflicting namespaces to fail rendering instead of producing a warning
+	StrictNamespaceOverride bool
+
+	// ObjectPatches are applied to all matching rendered objects, merged from the project config and the command line
+	ObjectPatches []types.ObjectPatch
 }
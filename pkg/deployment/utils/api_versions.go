@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"path"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// FindUnservedApiVersions returns all objects with a group/version/kind that is not served by the cluster. Kinds
+// defined by CRDs found in the same list of objects are treated as served, as these CRDs are applied before the
+// custom resources. Objects matching one of the ignore patterns are skipped, see matchesApiVersionPattern.
+func FindUnservedApiVersions(objects []*uo.UnstructuredObject, served map[schema.GroupVersionKind]bool, ignore []string) []*uo.UnstructuredObject {
+	fromCRDs := collectCrdKinds(objects)
+
+	var ret []*uo.UnstructuredObject
+	for _, o := range objects {
+		gvk := o.GetK8sGVK()
+		if served[gvk] || fromCRDs[gvk] {
+			continue
+		}
+		if matchesApiVersionPattern(gvk, ignore) {
+			continue
+		}
+		ret = append(ret, o)
+	}
+	return ret
+}
+
+// DeferCrdCheckAnnotation excludes an object from the missing kinds preflight check, e.g. because its CRD is installed
+// by a hook of the same deployment
+const DeferCrdCheckAnnotation = "kluctl.io/defer-crd-check"
+
+// FindMissingKinds returns all objects with a group/kind that is neither served by the cluster in any version nor
+// defined by a CRD found in the same list of objects. Objects with the DeferCrdCheckAnnotation and objects matching one
+// of the deferred patterns are skipped, see matchesKindPattern.
+func FindMissingKinds(objects []*uo.UnstructuredObject, served map[schema.GroupKind]bool, deferred []string) []*uo.UnstructuredObject {
+	fromCRDs := map[schema.GroupKind]bool{}
+	for gvk := range collectCrdKinds(objects) {
+		fromCRDs[gvk.GroupKind()] = true
+	}
+
+	var ret []*uo.UnstructuredObject
+	for _, o := range objects {
+		gk := o.GetK8sGVK().GroupKind()
+		if served[gk] || fromCRDs[gk] {
+			continue
+		}
+		if o.GetK8sAnnotationBoolNoError(DeferCrdCheckAnnotation, false) || matchesKindPattern(gk, deferred) {
+			continue
+		}
+		ret = append(ret, o)
+	}
+	return ret
+}
+
+// collectCrdKinds returns all group/version/kinds defined by the CRDs found in the list of objects
+func collectCrdKinds(objects []*uo.UnstructuredObject) map[schema.GroupVersionKind]bool {
+	ret := map[schema.GroupVersionKind]bool{}
+	for _, o := range objects {
+		if o.GetK8sGVK().GroupKind().String() != "CustomResourceDefinition.apiextensions.k8s.io" {
+			continue
+		}
+		group, _, _ := o.GetNestedString("spec", "group")
+		kind, _, _ := o.GetNestedString("spec", "names", "kind")
+		versions, _, _ := o.GetNestedObjectList("spec", "versions")
+		for _, v := range versions {
+			name, _, _ := v.GetNestedString("name")
+			ret[schema.GroupVersionKind{Group: group, Version: name, Kind: kind}] = true
+		}
+	}
+	return ret
+}
+
+// matchesKindPattern matches the glob patterns against "<group>/<kind>", or "<kind>" for the core group
+func matchesKindPattern(gk schema.GroupKind, patterns []string) bool {
+	s := gk.Kind
+	if gk.Group != "" {
+		s = gk.Group + "/" + gk.Kind
+	}
+	for _, p := range patterns {
+		if m, _ := path.Match(p, s); m {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesApiVersionPattern matches the glob patterns against "<apiVersion>" and "<apiVersion>/<kind>"
+func matchesApiVersionPattern(gvk schema.GroupVersionKind, patterns []string) bool {
+	apiVersion := gvk.GroupVersion().String()
+	for _, p := range patterns {
+		if m, _ := path.Match(p, apiVersion); m {
+			return true
+		}
+		if m, _ := path.Match(p, apiVersion+"/"+gvk.Kind); m {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestFindUnservedApiVersions(t *testing.T) {
+	newObject := func(apiVersion string, kind string, name string) *uo.UnstructuredObject {
+		return uo.FromMap(map[string]any{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]any{
+				"name": name,
+			},
+		})
+	}
+
+	crd := newObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "crs.example.com")
+	_ = crd.SetNestedField("example.com", "spec", "group")
+	_ = crd.SetNestedField("CR", "spec", "names", "kind")
+	_ = crd.SetNestedField([]any{map[string]any{"name": "v1"}}, "spec", "versions")
+
+	objects := []*uo.UnstructuredObject{
+		crd,
+		newObject("v1", "ConfigMap", "cm"),
+		newObject("autoscaling/v2", "HorizontalPodAutoscaler", "hpa"),
+		newObject("example.com/v1", "CR", "cr"),
+		newObject("example.com/v2", "CR", "cr2"),
+		newObject("monitoring.coreos.com/v1", "ServiceMonitor", "sm"),
+		newObject("monitoring.coreos.com/v1", "PodMonitor", "pm"),
+	}
+	served := map[schema.GroupVersionKind]bool{
+		{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}: true,
+		{Group: "", Version: "v1", Kind: "ConfigMap"}:                                    true,
+		{Group: "autoscaling", Version: "v1", Kind: "HorizontalPodAutoscaler"}:           true,
+	}
+
+	names := func(l []*uo.UnstructuredObject) []string {
+		var ret []string
+		for _, o := range l {
+			ret = append(ret, o.GetK8sName())
+		}
+		return ret
+	}
+
+	assert.Equal(t, []string{"hpa", "cr2", "sm", "pm"}, names(FindUnservedApiVersions(objects, served, nil)))
+	assert.Equal(t, []string{"hpa", "cr2"}, names(FindUnservedApiVersions(objects, served, []string{"monitoring.coreos.com/*"})))
+	assert.Equal(t, []string{"hpa", "cr2", "pm"}, names(FindUnservedApiVersions(objects, served, []string{"monitoring.coreos.com/v1/ServiceMonitor"})))
+}
+
+func TestFindMissingKinds(t *testing.T) {
+	newObject := func(apiVersion string, kind string, name string) *uo.UnstructuredObject {
+		return uo.FromMap(map[string]any{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]any{
+				"name": name,
+			},
+		})
+	}
+
+	crd := newObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "crs.example.com")
+	_ = crd.SetNestedField("example.com", "spec", "group")
+	_ = crd.SetNestedField("CR", "spec", "names", "kind")
+	_ = crd.SetNestedField([]any{map[string]any{"name": "v1"}}, "spec", "versions")
+
+	deferredByAnnotation := newObject("other.example.com/v1", "Foo", "foo")
+	deferredByAnnotation.SetK8sAnnotation(DeferCrdCheckAnnotation, "true")
+
+	objects := []*uo.UnstructuredObject{
+		crd,
+		newObject("v1", "ConfigMap", "cm"),
+		newObject("autoscaling/v2", "HorizontalPodAutoscaler", "hpa"),
+		newObject("example.com/v2", "CR", "cr"),
+		newObject("monitoring.coreos.com/v1", "ServiceMonitor", "sm"),
+		newObject("monitoring.coreos.com/v1", "PodMonitor", "pm"),
+		deferredByAnnotation,
+	}
+	served := map[schema.GroupKind]bool{
+		{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: true,
+		{Group: "", Kind: "ConfigMap"}:                                    true,
+		{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}:           true,
+	}
+
+	names := func(l []*uo.UnstructuredObject) []string {
+		var ret []string
+		for _, o := range l {
+			ret = append(ret, o.GetK8sName())
+		}
+		return ret
+	}
+
+	// kinds served in other versions and kinds defined by CRDs in other versions are not missing
+	assert.Equal(t, []string{"sm", "pm"}, names(FindMissingKinds(objects, served, nil)))
+	assert.Empty(t, FindMissingKinds(objects, served, []string{"monitoring.coreos.com/*"}))
+	assert.Equal(t, []string{"pm"}, names(FindMissingKinds(objects, served, []string{"monitoring.coreos.com/ServiceMonitor"})))
+}
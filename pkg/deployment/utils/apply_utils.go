@@ -7,10 +7,12 @@ import (
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/events"
 	"github.com/kluctl/kluctl/v2/pkg/diff"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	types2 "github.com/kluctl/kluctl/v2/pkg/types"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/kluctl/kluctl/v2/pkg/validation"
@@ -21,6 +23,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -36,6 +39,20 @@ type ApplyUtilOptions struct {
 	AbortOnError        bool
 	ReadinessTimeout    time.Duration
 	NoWait              bool
+	NoProbes            bool
+
+	// TakeOwnershipFrom specifies field managers (e.g. kubectl-client-side-apply) from which field ownership is
+	// transferred to kluctl before applying objects
+	TakeOwnershipFrom []string
+
+	// HookRunId and HookRunStartTime are added to all applied hooks, so that hooks left behind by interrupted runs
+	// can be detected and cleaned up later
+	HookRunId        string
+	HookRunStartTime time.Time
+
+	// RunDryRunSafeHooks causes hooks annotated with kluctl.io/hook-dry-run-safe to be actually executed while in
+	// dry-run mode
+	RunDryRunSafeHooks bool
 
 	SkipResourceVersions map[k8s2.ObjectRef]string
 }
@@ -43,15 +60,19 @@ type ApplyUtilOptions struct {
 type ApplyUtil struct {
 	ctx context.Context
 
-	dew                *DeploymentErrorsAndWarnings
-	errorCount         int
-	warningCount       int
-	newObjects         map[k8s2.ObjectRef]*uo.UnstructuredObject
-	appliedObjects     map[k8s2.ObjectRef]*uo.UnstructuredObject
-	appliedHookObjects map[k8s2.ObjectRef]*uo.UnstructuredObject
-	deletedObjects     map[k8s2.ObjectRef]bool
-	deletedHookObjects map[k8s2.ObjectRef]bool
-	mutex              sync.Mutex
+	dew                 *DeploymentErrorsAndWarnings
+	errorCount          int
+	warningCount        int
+	newObjects          map[k8s2.ObjectRef]*uo.UnstructuredObject
+	appliedObjects      map[k8s2.ObjectRef]*uo.UnstructuredObject
+	appliedHookObjects  map[k8s2.ObjectRef]*uo.UnstructuredObject
+	deletedObjects      map[k8s2.ObjectRef]bool
+	deletedHookObjects  map[k8s2.ObjectRef]bool
+	rerunJobs           map[k8s2.ObjectRef]*result.RerunJob
+	ownershipMigrations map[k8s2.ObjectRef]*result.OwnershipMigration
+	objectTimings       map[k8s2.ObjectRef]*result.ObjectTimings
+	phases              []result.Phase
+	mutex               sync.Mutex
 
 	abortSignal   *atomic.Value
 	allNamespaces *sync.Map
@@ -104,21 +125,24 @@ func (ad *ApplyDeploymentsUtil) NewApplyUtil(ctx context.Context, statusCtx *sta
 	defer ad.resultsMutex.Unlock()
 
 	ret := &ApplyUtil{
-		ctx:                ctx,
-		dew:                ad.dew,
-		newObjects:         map[k8s2.ObjectRef]*uo.UnstructuredObject{},
-		appliedObjects:     map[k8s2.ObjectRef]*uo.UnstructuredObject{},
-		appliedHookObjects: map[k8s2.ObjectRef]*uo.UnstructuredObject{},
-		deletedObjects:     map[k8s2.ObjectRef]bool{},
-		deletedHookObjects: map[k8s2.ObjectRef]bool{},
-		abortSignal:        &ad.abortSignal,
-		allNamespaces:      &ad.allNamespaces,
-		allCRDs:            &ad.allCRDs,
-		crdCache:           &ad.crdCache,
-		ru:                 ad.ru,
-		k:                  ad.k,
-		o:                  ad.o,
-		sctx:               statusCtx,
+		ctx:                 ctx,
+		dew:                 ad.dew,
+		newObjects:          map[k8s2.ObjectRef]*uo.UnstructuredObject{},
+		appliedObjects:      map[k8s2.ObjectRef]*uo.UnstructuredObject{},
+		appliedHookObjects:  map[k8s2.ObjectRef]*uo.UnstructuredObject{},
+		deletedObjects:      map[k8s2.ObjectRef]bool{},
+		deletedHookObjects:  map[k8s2.ObjectRef]bool{},
+		rerunJobs:           map[k8s2.ObjectRef]*result.RerunJob{},
+		ownershipMigrations: map[k8s2.ObjectRef]*result.OwnershipMigration{},
+		objectTimings:       map[k8s2.ObjectRef]*result.ObjectTimings{},
+		abortSignal:         &ad.abortSignal,
+		allNamespaces:       &ad.allNamespaces,
+		allCRDs:             &ad.allCRDs,
+		crdCache:            &ad.crdCache,
+		ru:                  ad.ru,
+		k:                   ad.k,
+		o:                   ad.o,
+		sctx:                statusCtx,
 	}
 	ad.results = append(ad.results, ret)
 	return ret
@@ -134,9 +158,38 @@ func (a *ApplyUtil) handleResult(appliedObject *uo.UnstructuredObject, hook bool
 	}
 	a.appliedObjects[ref] = appliedObject
 
-	if !hook && a.ru.GetRemoteObject(ref) == nil {
+	if om, ok := a.ownershipMigrations[ref]; ok {
+		om.CoOwners = getCoOwners(appliedObject, om.From)
+	}
+
+	isNew := !hook && a.ru.GetRemoteObject(ref) == nil
+	if isNew {
 		a.newObjects[ref] = appliedObject
 	}
+
+	if !hook {
+		// hooks are reported via hookStarted/hookFinished
+		events.Emit(a.ctx, events.Event{Type: events.ObjectApplied, Ref: &ref, New: isNew})
+	}
+}
+
+// recordTiming adds the time passed since startTime to the timing selected by f
+func (a *ApplyUtil) recordTiming(ref k8s2.ObjectRef, startTime time.Time, f func(t *result.ObjectTimings) **metav1.Duration) {
+	d := time.Since(startTime)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	t, ok := a.objectTimings[ref]
+	if !ok {
+		t = &result.ObjectTimings{}
+		a.objectTimings[ref] = t
+	}
+	p := f(t)
+	if *p == nil {
+		*p = &metav1.Duration{}
+	}
+	(*p).Duration += d
 }
 
 func (a *ApplyUtil) handleApiWarnings(ref k8s2.ObjectRef, warnings []k8s.ApiWarning) {
@@ -145,6 +198,10 @@ func (a *ApplyUtil) handleApiWarnings(ref k8s2.ObjectRef, warnings []k8s.ApiWarn
 
 	a.dew.AddApiWarnings(ref, warnings)
 	a.warningCount += len(warnings)
+
+	for _, w := range warnings {
+		events.EmitForRef(a.ctx, events.Warning, ref, w.Text)
+	}
 }
 
 func (a *ApplyUtil) HandleWarning(ref k8s2.ObjectRef, warning error) {
@@ -153,6 +210,8 @@ func (a *ApplyUtil) HandleWarning(ref k8s2.ObjectRef, warning error) {
 
 	a.dew.AddWarning(ref, warning)
 	a.warningCount++
+
+	events.EmitForRef(a.ctx, events.Warning, ref, warning.Error())
 }
 
 func (a *ApplyUtil) HandleError(ref k8s2.ObjectRef, err error) {
@@ -169,6 +228,8 @@ func (a *ApplyUtil) HandleError(ref k8s2.ObjectRef, err error) {
 
 	a.dew.AddError(ref, err)
 	a.errorCount++
+
+	events.EmitForRef(a.ctx, events.Error, ref, err.Error())
 }
 
 func (a *ApplyUtil) HadError(ref k8s2.ObjectRef) bool {
@@ -349,6 +410,10 @@ func (a *ApplyUtil) retryApplyWithConflicts(d *deployment.DeploymentItem, x *uo.
 func (a *ApplyUtil) ApplyObject(d *deployment.DeploymentItem, x *uo.UnstructuredObject, replaced bool, hook bool) {
 	ref := x.GetK8sRef()
 
+	defer a.recordTiming(ref, time.Now(), func(t *result.ObjectTimings) **metav1.Duration {
+		return &t.Apply
+	})
+
 	x = a.k.FixObjectForPatch(x)
 	remoteObject := a.ru.GetRemoteObject(ref)
 
@@ -404,6 +469,15 @@ func (a *ApplyUtil) ApplyObject(d *deployment.DeploymentItem, x *uo.Unstructured
 	options := k8s.PatchOptions{
 		ForceDryRun: a.o.DryRun,
 	}
+	if len(a.o.TakeOwnershipFrom) != 0 && remoteObject != nil && !usesDummyName {
+		if a.takeOwnership(ref, remoteObject) && a.o.DryRun {
+			// the migration was only simulated, so the previous owners would still cause conflicts
+			options.ForceApply = true
+		}
+		if a.HadError(ref) {
+			return
+		}
+	}
 	r, apiWarnings, err := a.k.ApplyObject(x, options)
 
 	retryWhenCRDExists := meta.IsNoMatchError(err)
@@ -487,11 +561,20 @@ func (a *ApplyUtil) handleObservedCRD(r *uo.UnstructuredObject) {
 	}
 }
 
-func (a *ApplyUtil) WaitReadiness(ref k8s2.ObjectRef, timeout time.Duration) bool {
+// WaitReadiness waits for the given object to become ready. The time spent waiting is recorded as hook wait time if
+// hook is true, and as readiness wait time otherwise.
+func (a *ApplyUtil) WaitReadiness(ref k8s2.ObjectRef, timeout time.Duration, hook bool) bool {
 	if a.o.DryRun {
 		return true
 	}
 
+	defer a.recordTiming(ref, time.Now(), func(t *result.ObjectTimings) **metav1.Duration {
+		if hook {
+			return &t.WaitHook
+		}
+		return &t.WaitReadiness
+	})
+
 	if timeout == 0 {
 		timeout = a.o.ReadinessTimeout
 	}
@@ -528,6 +611,11 @@ func (a *ApplyUtil) WaitReadiness(ref k8s2.ObjectRef, timeout time.Duration) boo
 			seen = true
 
 			v := validation.ValidateObject(a.ctx, a.k, o, false, false)
+			if v.Ready && len(v.Errors) == 0 && !a.o.NoProbes && validation.HasProbe(o) {
+				pv := validation.ValidateProbe(a.ctx, o, false)
+				v.Ready = pv.Ready
+				v.Errors = append(v.Errors, pv.Errors...)
+			}
 			if v.Ready {
 				if didLog {
 					a.sctx.InfoFallbackf("Finished waiting for %s (%ds elapsed)", ref.String(), elapsed)
@@ -626,20 +714,39 @@ func (a *ApplyUtil) convertObjectRef(x types2.ObjectRefItem, refs map[k8s2.Objec
 	}
 }
 
-func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
+// deploymentItemPlan holds everything applyDeploymentItem is going to do for a single deployment item, in the order
+// it is going to be done
+type deploymentItemPlan struct {
+	toDelete        map[k8s2.ObjectRef]bool
+	toWaitReadiness map[k8s2.ObjectRef]bool
+	initialDeploy   bool
+	applyObjects    []*uo.UnstructuredObject
+	preHooks        []*hook
+	postHooks       []*hook
+}
+
+// estimatedSteps returns the number of progress steps the deployment item will perform
+func (p *deploymentItemPlan) estimatedSteps() int {
+	// +1 to ensure that we don't prematurely complete the bar (which would happen as we don't count for waiting)
+	return len(p.applyObjects) + len(p.preHooks) + len(p.postHooks) + 1
+}
+
+func (a *ApplyUtil) planDeploymentItem(d *deployment.DeploymentItem) *deploymentItemPlan {
 	h := HooksUtil{a: a}
 
-	toDelete := map[k8s2.ObjectRef]bool{}
-	toWaitReadiness := map[k8s2.ObjectRef]bool{}
+	p := &deploymentItemPlan{
+		toDelete:        map[k8s2.ObjectRef]bool{},
+		toWaitReadiness: map[k8s2.ObjectRef]bool{},
+	}
 	for _, x := range d.Config.DeleteObjects {
-		a.convertObjectRef(x.ObjectRefItem, toDelete)
+		a.convertObjectRef(x.ObjectRefItem, p.toDelete)
 	}
 	for _, x := range d.Config.WaitReadinessObjects {
-		a.convertObjectRef(x.ObjectRefItem, toWaitReadiness)
+		a.convertObjectRef(x.ObjectRefItem, p.toWaitReadiness)
 	}
 	for _, x := range d.Objects {
 		if x.GetK8sAnnotationBoolNoError("kluctl.io/delete", false) {
-			toDelete[x.GetK8sRef()] = true
+			p.toDelete[x.GetK8sRef()] = true
 		}
 
 		// hooks have their own waitReadiness logic, so we must skip them here. Otherwise we'd wait for an object
@@ -647,42 +754,50 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 		if h.GetHook(d, x) == nil {
 			waitReadiness := d.Config.WaitReadiness || d.WaitReadiness || x.GetK8sAnnotationBoolNoError("kluctl.io/wait-readiness", false)
 			if waitReadiness {
-				toWaitReadiness[x.GetK8sRef()] = true
+				p.toWaitReadiness[x.GetK8sRef()] = true
 			}
 		}
 	}
 
-	initialDeploy := true
+	p.initialDeploy = true
 	for _, o := range d.Objects {
 		if a.ru.GetRemoteObject(o.GetK8sRef()) != nil {
-			initialDeploy = false
+			p.initialDeploy = false
 		}
 	}
 
-	var applyObjects []*uo.UnstructuredObject
 	for _, o := range d.Objects {
 		if h.GetHook(d, o) != nil {
 			continue
 		}
-		if _, ok := toDelete[o.GetK8sRef()]; ok {
+		if _, ok := p.toDelete[o.GetK8sRef()]; ok {
 			continue
 		}
-		applyObjects = append(applyObjects, o)
+		p.applyObjects = append(p.applyObjects, o)
 	}
+	d.Project.GetObjectOrder().SortObjects(p.applyObjects, a.HandleWarning)
 
-	var preHooks []*hook
-	var postHooks []*hook
-	if initialDeploy {
-		preHooks = h.DetermineHooks(d, []string{"pre-deploy-initial", "pre-deploy"})
-		postHooks = h.DetermineHooks(d, []string{"post-deploy-initial", "post-deploy"})
+	if p.initialDeploy {
+		p.preHooks = h.DetermineHooks(d, []string{"pre-deploy-initial", "pre-deploy"})
+		p.postHooks = h.DetermineHooks(d, []string{"post-deploy-initial", "post-deploy"})
 	} else {
-		preHooks = h.DetermineHooks(d, []string{"pre-deploy-upgrade", "pre-deploy"})
-		postHooks = h.DetermineHooks(d, []string{"post-deploy-upgrade", "post-deploy"})
+		p.preHooks = h.DetermineHooks(d, []string{"pre-deploy-upgrade", "pre-deploy"})
+		p.postHooks = h.DetermineHooks(d, []string{"post-deploy-upgrade", "post-deploy"})
 	}
+	return p
+}
 
-	// +1 to ensure that we don't prematurely complete the bar (which would happen as we don't count for waiting)
-	total := len(applyObjects) + len(preHooks) + len(postHooks) + 1
-	a.sctx.SetTotal(total)
+func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
+	h := HooksUtil{a: a}
+
+	p := a.planDeploymentItem(d)
+	toDelete := p.toDelete
+	toWaitReadiness := p.toWaitReadiness
+	applyObjects := p.applyObjects
+	preHooks := p.preHooks
+	postHooks := p.postHooks
+
+	a.sctx.SetTotal(p.estimatedSteps())
 
 	if len(toDelete) != 0 {
 		a.sctx.InfoFallbackf("Deleting %d objects", len(toDelete))
@@ -695,11 +810,15 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 		}
 	}
 
+	phaseStartTime := time.Now()
 	h.RunHooks(preHooks)
+	a.addPhase(result.PhasePreDeployHooks, d, phaseStartTime, hookRefs(preHooks))
 
 	if len(applyObjects) != 0 {
 		a.sctx.InfoFallbackf("Applying %d objects", len(applyObjects))
 	}
+	phaseStartTime = time.Now()
+	var appliedRefs []k8s2.ObjectRef
 	startTime := time.Now()
 	didLog := false
 	for i, o := range applyObjects {
@@ -708,8 +827,19 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 		}
 
 		ref := o.GetK8sRef()
+		if !a.waitForDependencies(o) {
+			a.sctx.Increment()
+			continue
+		}
+		appliedRefs = append(appliedRefs, ref)
 		a.sctx.Updatef("Applying object %s (%d of %d)", ref.String(), i+1, len(applyObjects))
-		a.ApplyObject(d, o, false, false)
+		if rj, err := getRerunJob(o); err != nil {
+			a.HandleError(ref, err)
+		} else if rj != nil {
+			a.applyRerunJob(d, o, rj)
+		} else {
+			a.ApplyObject(d, o, false, false)
+		}
 		a.sctx.Increment()
 		if time.Now().Sub(startTime) >= 10*time.Second || (didLog && i == len(applyObjects)-1) {
 			a.sctx.InfoFallbackf("...applied %d of %d objects", i+1, len(applyObjects))
@@ -724,14 +854,24 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 		}
 
 		if !a.o.NoWait {
-			a.WaitReadiness(ref, 0)
+			a.WaitReadiness(ref, 0, false)
 		}
 	}
+	a.addPhase(result.PhaseApply, d, phaseStartTime, appliedRefs)
 	if a.abortSignal.Load().(bool) {
 		return
 	}
 
+	phaseStartTime = time.Now()
 	h.RunHooks(postHooks)
+	a.addPhase(result.PhasePostDeployHooks, d, phaseStartTime, hookRefs(postHooks))
+	if a.abortSignal.Load().(bool) {
+		return
+	}
+
+	phaseStartTime = time.Now()
+	postDeployWaitRefs := a.runPostDeployWaits(d)
+	a.addPhase(result.PhasePostDeployWaits, d, phaseStartTime, postDeployWaitRefs)
 
 	finalStatus := ""
 	if len(a.appliedObjects) != 0 {
@@ -765,6 +905,30 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 	}
 }
 
+// addPhase records an execution phase of the deployment item. Empty phases are not recorded.
+func (a *ApplyUtil) addPhase(phaseType result.PhaseType, d *deployment.DeploymentItem, startTime time.Time, refs []k8s2.ObjectRef) {
+	if len(refs) == 0 {
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.phases = append(a.phases, result.Phase{
+		Type:           phaseType,
+		DeploymentItem: d.RelToProjectItemDir,
+		StartTime:      metav1.NewMicroTime(startTime),
+		EndTime:        metav1.NowMicro(),
+		Objects:        refs,
+	})
+}
+
+func hookRefs(hooks []*hook) []k8s2.ObjectRef {
+	var ret []k8s2.ObjectRef
+	for _, h := range hooks {
+		ret = append(ret, h.object.GetK8sRef())
+	}
+	return ret
+}
+
 func (a *ApplyDeploymentsUtil) buildProgressName(d *deployment.DeploymentItem) *string {
 	if d.RelToProjectItemDir != "" {
 		return &d.RelToProjectItemDir
@@ -786,6 +950,8 @@ func (a *ApplyDeploymentsUtil) ApplyDeployments(deployments []*deployment.Deploy
 		return
 	}
 
+	WarnUnknownObjectOrderKinds(a.k, deployments, a.dew)
+
 	var wg sync.WaitGroup
 	sem := semaphore.NewWeighted(8)
 
@@ -966,3 +1132,73 @@ func (ad *ApplyDeploymentsUtil) GetDeletedObjects() []k8s2.ObjectRef {
 	}
 	return ret
 }
+
+// GetOwnershipMigrations returns all objects for which field ownership was taken over from other field managers
+func (ad *ApplyDeploymentsUtil) GetOwnershipMigrations() map[k8s2.ObjectRef]*result.OwnershipMigration {
+	ad.resultsMutex.Lock()
+	defer ad.resultsMutex.Unlock()
+
+	ret := make(map[k8s2.ObjectRef]*result.OwnershipMigration)
+	for _, a := range ad.results {
+		a.mutex.Lock()
+		for ref, om := range a.ownershipMigrations {
+			ret[ref] = om
+		}
+		a.mutex.Unlock()
+	}
+	return ret
+}
+
+// GetPhases returns the execution phases of all deployment items, sorted by start time
+func (ad *ApplyDeploymentsUtil) GetPhases() []result.Phase {
+	ad.resultsMutex.Lock()
+	defer ad.resultsMutex.Unlock()
+
+	var ret []result.Phase
+	for _, a := range ad.results {
+		a.mutex.Lock()
+		ret = append(ret, a.phases...)
+		a.mutex.Unlock()
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].StartTime.Before(&ret[j].StartTime)
+	})
+	return ret
+}
+
+// GetObjectTimings returns the recorded timings of all objects
+func (ad *ApplyDeploymentsUtil) GetObjectTimings() map[k8s2.ObjectRef]*result.ObjectTimings {
+	ad.resultsMutex.Lock()
+	defer ad.resultsMutex.Unlock()
+
+	ret := make(map[k8s2.ObjectRef]*result.ObjectTimings)
+	for _, a := range ad.results {
+		a.mutex.Lock()
+		for ref, t := range a.objectTimings {
+			if t2, ok := ret[ref]; ok {
+				// the same object might be touched by multiple deployment items, e.g. shared namespaces
+				t = mergeObjectTimings(t2, t)
+			}
+			ret[ref] = t
+		}
+		a.mutex.Unlock()
+	}
+	return ret
+}
+
+func mergeObjectTimings(a *result.ObjectTimings, b *result.ObjectTimings) *result.ObjectTimings {
+	add := func(x *metav1.Duration, y *metav1.Duration) *metav1.Duration {
+		if x == nil {
+			return y
+		}
+		if y == nil {
+			return x
+		}
+		return &metav1.Duration{Duration: x.Duration + y.Duration}
+	}
+	return &result.ObjectTimings{
+		Apply:         add(a.Apply, b.Apply),
+		WaitHook:      add(a.WaitHook, b.WaitHook),
+		WaitReadiness: add(a.WaitReadiness, b.WaitReadiness),
+	}
+}
@@ -3,12 +3,17 @@ package utils
 import (
 	"context"
 	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/events"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"slices"
 	"sync"
 )
 
@@ -161,7 +166,9 @@ func filterObjectsForDelete(k *k8s.K8sCluster, objects []*uo.UnstructuredObject,
 	return ret, nil
 }
 
-func FindObjectsForDelete(k *k8s.K8sCluster, allClusterObjects []*uo.UnstructuredObject, inclusionHasTags bool, excludedObjects []k8s2.ObjectRef) ([]k8s2.ObjectRef, error) {
+// FindObjectsForDelete returns all objects that need to be deleted. If order is non-nil, the result is sorted in
+// reverse apply order, otherwise the built-in delete order is used.
+func FindObjectsForDelete(k *k8s.K8sCluster, allClusterObjects []*uo.UnstructuredObject, inclusionHasTags bool, excludedObjects []k8s2.ObjectRef, order *deployment.ObjectOrder) ([]k8s2.ObjectRef, error) {
 	if k == nil {
 		return nil, fmt.Errorf("can not determine orphan objects without a Kubernetes API client")
 	}
@@ -171,7 +178,7 @@ func FindObjectsForDelete(k *k8s.K8sCluster, allClusterObjects []*uo.Unstructure
 		excludedObjectsMap[objectRefForExclusion(k, ref)] = true
 	}
 
-	var ret []k8s2.ObjectRef
+	var objects []*uo.UnstructuredObject
 
 	for _, filter := range deleteOrder {
 		l, err := filterObjectsForDelete(k, allClusterObjects, filter, inclusionHasTags, excludedObjectsMap)
@@ -181,13 +188,82 @@ func FindObjectsForDelete(k *k8s.K8sCluster, allClusterObjects []*uo.Unstructure
 		for _, o := range l {
 			ref := o.GetK8sRef()
 			excludedObjectsMap[objectRefForExclusion(k, ref)] = true
-			ret = append(ret, ref)
+			objects = append(objects, o)
 		}
 	}
 
+	if order != nil {
+		// invalid weight annotations were already reported while applying
+		order.SortObjects(objects, nil)
+		slices.Reverse(objects)
+	}
+
+	ret := make([]k8s2.ObjectRef, 0, len(objects))
+	for _, o := range objects {
+		ret = append(ret, o.GetK8sRef())
+	}
 	return ret, nil
 }
 
+// ErrProtectedFromDeletion is reported as warning for objects that were skipped due to being protected
+var ErrProtectedFromDeletion = fmt.Errorf("object is protected from deletion via annotation, skipping it")
+
+// FindProtectedObjects returns all objects that are managed by kluctl but protected from deletion via the
+// 'kluctl.io/skip-delete' or 'helm.sh/resource-policy' annotations
+func FindProtectedObjects(allClusterObjects []*uo.UnstructuredObject) []k8s2.ObjectRef {
+	var ret []k8s2.ObjectRef
+	for _, o := range allClusterObjects {
+		if isSkipDelete(o) && isManagedByKluctl(o) {
+			ret = append(ret, o.GetK8sRef())
+		}
+	}
+	return ret
+}
+
+// FilterPruneExcluded removes all objects matching one of the given prune exclusion rules. Excluded objects and the
+// reason for the exclusion are reported via debug output.
+func FilterPruneExcluded(ctx context.Context, objects []*uo.UnstructuredObject, rules []types.PruneExcludeRule) []*uo.UnstructuredObject {
+	if len(rules) == 0 {
+		return objects
+	}
+
+	ret := make([]*uo.UnstructuredObject, 0, len(objects))
+outer:
+	for _, o := range objects {
+		ref := o.GetK8sRef()
+		for _, r := range rules {
+			if r.Matches(ref.Group, ref.Kind, ref.Namespace) {
+				reason := r.Reason
+				if reason == "" {
+					reason = "no reason given"
+				}
+				status.Tracef(ctx, "Excluding %s from orphan detection due to prune exclusion rule '%s': %s", ref.String(), r.String(), reason)
+				continue outer
+			}
+		}
+		ret = append(ret, o)
+	}
+	return ret
+}
+
+// FilterOtherNamespaces removes all namespaced objects that are not in the given namespace. Cluster scoped objects are
+// kept. Nothing is removed if namespace is empty.
+func FilterOtherNamespaces(objects []*uo.UnstructuredObject, namespace string) []*uo.UnstructuredObject {
+	if namespace == "" {
+		return objects
+	}
+
+	ret := make([]*uo.UnstructuredObject, 0, len(objects))
+	for _, o := range objects {
+		ns := o.GetK8sNamespace()
+		if ns != "" && ns != namespace {
+			continue
+		}
+		ret = append(ret, o)
+	}
+	return ret
+}
+
 func DeleteObjects(ctx context.Context, k *k8s.K8sCluster, refs []k8s2.ObjectRef, dew *DeploymentErrorsAndWarnings, doWait bool) []k8s2.ObjectRef {
 	g := utils.NewGoHelper(ctx, 8)
 
@@ -201,10 +277,15 @@ func DeleteObjects(ctx context.Context, k *k8s.K8sCluster, refs []k8s2.ObjectRef
 
 		if err == nil {
 			ret = append(ret, ref)
+			events.EmitForRef(ctx, events.PruneDeleted, ref, "")
 		} else {
 			dew.AddError(ref, err)
+			events.EmitForRef(ctx, events.Error, ref, err.Error())
 		}
 		dew.AddApiWarnings(ref, apiWarnings)
+		for _, w := range apiWarnings {
+			events.EmitForRef(ctx, events.Warning, ref, w.Text)
+		}
 	}
 
 	for _, ref_ := range refs {
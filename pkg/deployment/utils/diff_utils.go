@@ -3,10 +3,12 @@ package utils
 import (
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
 	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sort"
 	"sync"
 )
@@ -22,16 +24,102 @@ type DiffUtil struct {
 	IgnoreKluctlMetadata bool
 	Swapped              bool
 
+	// NoListNormalization disables matching of list elements by their merge keys, causing lists to be diffed by index
+	NoListNormalization bool
+
+	listMergeKeys *diff.ListMergeKeys
+
+	// hpaTargets contains the objects that are scaled by a HorizontalPodAutoscaler, without versions
+	hpaTargets map[k8s2.ObjectRef]bool
+
 	remoteDiffObjects map[k8s2.ObjectRef]*uo.UnstructuredObject
 	ChangedObjects    []result.ChangedObject
-	mutex             sync.Mutex
+	// IgnoredChangedObjects contains the changes that were suppressed by object level ignore rules
+	IgnoredChangedObjects []result.ChangedObject
+	// MovedObjects contains all objects that were moved from one deployment item to another
+	MovedObjects map[k8s2.ObjectRef]*result.ObjectMove
+	mutex        sync.Mutex
+}
+
+const deploymentItemDirAnnotation = "kluctl.io/deployment-item-dir"
+
+// trackingMetadataIgnoreForDiffs ignores the metadata that tracks the deployment item of an object. It is used for moved
+// objects, as the tracking metadata is expected to change when an object moves between deployment items.
+var trackingMetadataIgnoreForDiffs = []types.IgnoreForDiffItemConfig{
+	{FieldPathRegex: []string{`metadata\.labels\["kluctl\.io/tag-.*"\]`}},
+	{FieldPathRegex: []string{`metadata\.annotations\["kluctl\.io/deployment-item-dir"\]`}},
+}
+
+// hpaTargetIgnoreForDiffs ignores the replicas of objects that are scaled by a HorizontalPodAutoscaler, as these are
+// expected to be modified by the autoscaler
+var hpaTargetIgnoreForDiffs = []types.IgnoreForDiffItemConfig{
+	{FieldPath: []string{"spec.replicas"}},
 }
 
-func NewDiffUtil(dew *DeploymentErrorsAndWarnings, ru *RemoteObjectUtils, appliedObjects map[k8s2.ObjectRef]*uo.UnstructuredObject) *DiffUtil {
+// GetHpaTargetRef returns the (version-less) ref of the object that is scaled by the given HorizontalPodAutoscaler. It
+// returns false if the object is not a HorizontalPodAutoscaler or has no valid scaleTargetRef.
+func GetHpaTargetRef(o *uo.UnstructuredObject) (k8s2.ObjectRef, bool) {
+	gvk := o.GetK8sGVK()
+	if gvk.Group != "autoscaling" || gvk.Kind != "HorizontalPodAutoscaler" {
+		return k8s2.ObjectRef{}, false
+	}
+	apiVersion, _, _ := o.GetNestedString("spec", "scaleTargetRef", "apiVersion")
+	kind, _, _ := o.GetNestedString("spec", "scaleTargetRef", "kind")
+	name, _, _ := o.GetNestedString("spec", "scaleTargetRef", "name")
+	if kind == "" || name == "" {
+		return k8s2.ObjectRef{}, false
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return k8s2.ObjectRef{}, false
+	}
+	return k8s2.ObjectRef{
+		Group:     gv.Group,
+		Kind:      kind,
+		Name:      name,
+		Namespace: o.GetK8sNamespace(),
+	}, true
+}
+
+// findHpaTargets returns the (version-less) refs of all objects that are scaled by one of the HorizontalPodAutoscalers
+// found in the given deployment items
+func findHpaTargets(deployments []*deployment.DeploymentItem) map[k8s2.ObjectRef]bool {
+	ret := map[k8s2.ObjectRef]bool{}
+	for _, d := range deployments {
+		for _, o := range d.Objects {
+			if ref, ok := GetHpaTargetRef(o); ok {
+				ret[ref] = true
+			}
+		}
+	}
+	return ret
+}
+
+// getObjectMove returns the move of an object between deployment items, detected via the deployment-item-dir
+// annotation of the old and the new version of the object
+func getObjectMove(oldObject *uo.UnstructuredObject, newObject *uo.UnstructuredObject) *result.ObjectMove {
+	from := oldObject.GetK8sAnnotation(deploymentItemDirAnnotation)
+	to := newObject.GetK8sAnnotation(deploymentItemDirAnnotation)
+	if from == nil || to == nil || *from == *to {
+		return nil
+	}
+	return &result.ObjectMove{
+		FromItem: *from,
+		ToItem:   *to,
+	}
+}
+
+func NewDiffUtil(dew *DeploymentErrorsAndWarnings, ru *RemoteObjectUtils, k *k8s.K8sCluster, appliedObjects map[k8s2.ObjectRef]*uo.UnstructuredObject) *DiffUtil {
+	var getSchema diff.SchemaProvider
+	if k != nil {
+		getSchema = k.GetSchemaForGVK
+	}
 	u := &DiffUtil{
 		dew:            dew,
 		ru:             ru,
 		appliedObjects: appliedObjects,
+		listMergeKeys:  diff.NewListMergeKeys(getSchema),
+		MovedObjects:   map[k8s2.ObjectRef]*result.ObjectMove{},
 	}
 	u.calcRemoteObjectsForDiff()
 	return u
@@ -40,9 +128,10 @@ func NewDiffUtil(dew *DeploymentErrorsAndWarnings, ru *RemoteObjectUtils, applie
 func (u *DiffUtil) DiffDeploymentItems(deployments []*deployment.DeploymentItem) {
 	var wg sync.WaitGroup
 
+	u.hpaTargets = findHpaTargets(deployments)
 	for _, d := range deployments {
 		ignoreForDiffs := d.Project.GetIgnoreForDiffs(u.IgnoreTags, u.IgnoreLabels, u.IgnoreAnnotations, u.IgnoreKluctlMetadata)
-		u.diffObjects(d.Objects, ignoreForDiffs, &wg)
+		u.diffObjects(d.Objects, ignoreForDiffs, d.Project.GetListMergeKeys(), &wg)
 	}
 	wg.Wait()
 
@@ -51,7 +140,7 @@ func (u *DiffUtil) DiffDeploymentItems(deployments []*deployment.DeploymentItem)
 
 func (u *DiffUtil) DiffObjects(objects []*uo.UnstructuredObject) {
 	var wg sync.WaitGroup
-	u.diffObjects(objects, nil, &wg)
+	u.diffObjects(objects, nil, nil, &wg)
 	wg.Wait()
 	u.sortChanges()
 }
@@ -60,9 +149,12 @@ func (u *DiffUtil) sortChanges() {
 	sort.Slice(u.ChangedObjects, func(i, j int) bool {
 		return u.ChangedObjects[i].Ref.String() < u.ChangedObjects[j].Ref.String()
 	})
+	sort.Slice(u.IgnoredChangedObjects, func(i, j int) bool {
+		return u.IgnoredChangedObjects[i].Ref.String() < u.IgnoredChangedObjects[j].Ref.String()
+	})
 }
 
-func (u *DiffUtil) diffObjects(objects []*uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, wg *sync.WaitGroup) {
+func (u *DiffUtil) diffObjects(objects []*uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, listMergeKeys []types.ListMergeKeysConfig, wg *sync.WaitGroup) {
 	for _, o := range objects {
 		o := o
 		ref := o.GetK8sRef()
@@ -77,15 +169,15 @@ func (u *DiffUtil) diffObjects(objects []*uo.UnstructuredObject, ignoreForDiffs
 		go func() {
 			defer wg.Done()
 			if u.Swapped {
-				u.diffObject(o, diffRef, ro, ao, ignoreForDiffs)
+				u.diffObject(o, diffRef, ro, ao, ignoreForDiffs, listMergeKeys)
 			} else {
-				u.diffObject(o, diffRef, ao, ro, ignoreForDiffs)
+				u.diffObject(o, diffRef, ao, ro, ignoreForDiffs, listMergeKeys)
 			}
 		}()
 	}
 }
 
-func (u *DiffUtil) diffObject(lo *uo.UnstructuredObject, diffRef k8s2.ObjectRef, ao *uo.UnstructuredObject, ro *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig) {
+func (u *DiffUtil) diffObject(lo *uo.UnstructuredObject, diffRef k8s2.ObjectRef, ao *uo.UnstructuredObject, ro *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, listMergeKeys []types.ListMergeKeysConfig) {
 	if ao != nil && ro == nil {
 		// new?
 		return
@@ -96,32 +188,98 @@ func (u *DiffUtil) diffObject(lo *uo.UnstructuredObject, diffRef k8s2.ObjectRef,
 		// did not apply? (e.g. in downscale command)
 		return
 	} else {
-		nao, err := diff.NormalizeObject(ao, ignoreForDiffs, lo)
-		if err != nil {
-			u.dew.AddError(lo.GetK8sRef(), err)
-			return
+		if move := getObjectMove(ro, ao); move != nil {
+			// changed tracking metadata is expected for moved objects and should not show up as change
+			ignoreForDiffs = append(append([]types.IgnoreForDiffItemConfig{}, ignoreForDiffs...), trackingMetadataIgnoreForDiffs...)
+
+			u.mutex.Lock()
+			u.MovedObjects[diffRef] = move
+			u.mutex.Unlock()
 		}
-		nro, err := diff.NormalizeObject(ro, ignoreForDiffs, lo)
-		if err != nil {
-			u.dew.AddError(lo.GetK8sRef(), err)
-			return
+
+		var objectIgnoreForDiffs []types.IgnoreForDiffItemConfig
+		if u.isHpaTarget(lo) {
+			objectIgnoreForDiffs = hpaTargetIgnoreForDiffs
 		}
-		changes, err := diff.Diff(nro, nao)
+		hasObjectIgnores := len(objectIgnoreForDiffs) != 0 || len(diff.ObjectIgnoreForDiffs(lo)) != 0
+
+		changes, err := u.diffNormalized(lo, ao, ro, append(append([]types.IgnoreForDiffItemConfig{}, ignoreForDiffs...), objectIgnoreForDiffs...), listMergeKeys, diff.NormalizeObject)
 		if err != nil {
 			u.dew.AddError(lo.GetK8sRef(), err)
 			return
 		}
-		if len(changes) == 0 {
-			return
+
+		var ignoredChanges []result.Change
+		if hasObjectIgnores {
+			// diff again without object level ignores, so that suppressed changes can be shown on request
+			allChanges, err := u.diffNormalized(lo, ao, ro, ignoreForDiffs, listMergeKeys, diff.NormalizeObjectWithoutObjectIgnores)
+			if err != nil {
+				u.dew.AddError(lo.GetK8sRef(), err)
+				return
+			}
+			ignoredChanges = subtractChanges(allChanges, changes)
 		}
 
 		u.mutex.Lock()
 		defer u.mutex.Unlock()
-		u.ChangedObjects = append(u.ChangedObjects, result.ChangedObject{
-			Ref:     diffRef,
-			Changes: changes,
-		})
+		if len(changes) != 0 {
+			u.ChangedObjects = append(u.ChangedObjects, result.ChangedObject{
+				Ref:     diffRef,
+				Changes: changes,
+			})
+		}
+		if len(ignoredChanges) != 0 {
+			u.IgnoredChangedObjects = append(u.IgnoredChangedObjects, result.ChangedObject{
+				Ref:     diffRef,
+				Changes: ignoredChanges,
+			})
+		}
+	}
+}
+
+type normalizeFunc func(o *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, localObject *uo.UnstructuredObject) (*uo.UnstructuredObject, error)
+
+func (u *DiffUtil) diffNormalized(lo *uo.UnstructuredObject, ao *uo.UnstructuredObject, ro *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, listMergeKeys []types.ListMergeKeysConfig, normalize normalizeFunc) ([]result.Change, error) {
+	nao, err := normalize(ao, ignoreForDiffs, lo)
+	if err != nil {
+		return nil, err
+	}
+	nro, err := normalize(ro, ignoreForDiffs, lo)
+	if err != nil {
+		return nil, err
+	}
+	if !u.NoListNormalization {
+		err = u.listMergeKeys.NormalizeLists(nao, listMergeKeys)
+		if err != nil {
+			return nil, err
+		}
+		err = u.listMergeKeys.NormalizeLists(nro, listMergeKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return diff.Diff(nro, nao)
+}
+
+func (u *DiffUtil) isHpaTarget(o *uo.UnstructuredObject) bool {
+	ref := o.GetK8sRef()
+	ref.Version = ""
+	return u.hpaTargets[ref]
+}
+
+// subtractChanges returns all changes from a that have no change with the same path in b
+func subtractChanges(a []result.Change, b []result.Change) []result.Change {
+	paths := map[string]bool{}
+	for _, c := range b {
+		paths[c.JsonPath] = true
+	}
+	var ret []result.Change
+	for _, c := range a {
+		if !paths[c.JsonPath] {
+			ret = append(ret, c)
+		}
 	}
+	return ret
 }
 
 func (u *DiffUtil) calcRemoteObjectsForDiff() {
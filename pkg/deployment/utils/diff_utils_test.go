@@ -58,6 +58,29 @@ func newTestConfigMap(name string, data map[string]interface{}, annotations map[
 	return o
 }
 
+func newTestDeployment(name string, replicas int64, annotations map[string]string) *uo.UnstructuredObject {
+	o := uo.New()
+	o.SetK8sGVKs("apps", "v1", "Deployment")
+	o.SetK8sName(name)
+	o.SetK8sNamespace("default")
+	o.SetNestedField(replicas, "spec", "replicas")
+	o.SetK8sAnnotations(annotations)
+	return o
+}
+
+func newTestHpa(name string, target string) *uo.UnstructuredObject {
+	o := uo.New()
+	o.SetK8sGVKs("autoscaling", "v2", "HorizontalPodAutoscaler")
+	o.SetK8sName(name)
+	o.SetK8sNamespace("default")
+	o.SetNestedField(map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"name":       target,
+	}, "spec", "scaleTargetRef")
+	return o
+}
+
 func TestDiff(t *testing.T) {
 	buildRaw := func(x any) *apiextensionsv1.JSON {
 		if x == nil {
@@ -158,13 +181,70 @@ func TestDiff(t *testing.T) {
 				}, dtc.du.ChangedObjects[0].Changes)
 			},
 		},
+		{
+			name: "Moved object",
+			ro:   []*uo.UnstructuredObject{newTestConfigMap("test", map[string]interface{}{"d1": "v1"}, map[string]string{"kluctl.io/deployment-item-dir": "a"})},
+			lo:   []*uo.UnstructuredObject{newTestConfigMap("test", map[string]interface{}{"d1": "v1"}, map[string]string{"kluctl.io/deployment-item-dir": "b"})},
+			ao:   []*uo.UnstructuredObject{newTestConfigMap("test", map[string]interface{}{"d1": "v1"}, map[string]string{"kluctl.io/deployment-item-dir": "b"})},
+			a: func(t *testing.T, dtc *diffTestConfig) {
+				assert.Len(t, dtc.du.ChangedObjects, 0)
+				assert.Equal(t, map[k8s2.ObjectRef]*result.ObjectMove{
+					dtc.lo[0].GetK8sRef(): {FromItem: "a", ToItem: "b"},
+				}, dtc.du.MovedObjects)
+			},
+		},
+		{
+			name: "Moved and changed object",
+			ro:   []*uo.UnstructuredObject{newTestConfigMap("test", map[string]interface{}{"d1": "v1"}, map[string]string{"kluctl.io/deployment-item-dir": "a"})},
+			lo:   []*uo.UnstructuredObject{newTestConfigMap("test", map[string]interface{}{"d1": "v2"}, map[string]string{"kluctl.io/deployment-item-dir": "b"})},
+			ao:   []*uo.UnstructuredObject{newTestConfigMap("test", map[string]interface{}{"d1": "v2"}, map[string]string{"kluctl.io/deployment-item-dir": "b"})},
+			a: func(t *testing.T, dtc *diffTestConfig) {
+				assert.Len(t, dtc.du.ChangedObjects, 1)
+				assert.Equal(t, []result.Change{
+					buildChange("update", "data.d1", buildRaw("v1"), buildRaw("v2"), "-v1\n+v2"),
+				}, dtc.du.ChangedObjects[0].Changes)
+				assert.Len(t, dtc.du.MovedObjects, 1)
+			},
+		},
+		{
+			name: "Fields ignored by annotation",
+			ro:   []*uo.UnstructuredObject{newTestConfigMap("test", map[string]interface{}{"d1": "v1", "d2": "v1", "d3": "v1"}, map[string]string{"kluctl.io/ignore-diff-fields": "data.d1,data.d2"})},
+			lo:   []*uo.UnstructuredObject{newTestConfigMap("test", map[string]interface{}{"d1": "v2", "d2": "v2", "d3": "v2"}, map[string]string{"kluctl.io/ignore-diff-fields": "data.d1,data.d2"})},
+			ao:   []*uo.UnstructuredObject{newTestConfigMap("test", map[string]interface{}{"d1": "v2", "d2": "v2", "d3": "v2"}, map[string]string{"kluctl.io/ignore-diff-fields": "data.d1,data.d2"})},
+			a: func(t *testing.T, dtc *diffTestConfig) {
+				assert.Len(t, dtc.du.ChangedObjects, 1)
+				assert.Equal(t, []result.Change{
+					buildChange("update", "data.d3", buildRaw("v1"), buildRaw("v2"), "-v1\n+v2"),
+				}, dtc.du.ChangedObjects[0].Changes)
+				assert.Len(t, dtc.du.IgnoredChangedObjects, 1)
+				assert.Equal(t, []result.Change{
+					buildChange("update", "data.d1", buildRaw("v1"), buildRaw("v2"), "-v1\n+v2"),
+					buildChange("update", "data.d2", buildRaw("v1"), buildRaw("v2"), "-v1\n+v2"),
+				}, dtc.du.IgnoredChangedObjects[0].Changes)
+			},
+		},
+		{
+			name: "Replicas of HPA targets",
+			ro:   []*uo.UnstructuredObject{newTestDeployment("d1", 5, nil), newTestDeployment("d2", 5, nil)},
+			lo:   []*uo.UnstructuredObject{newTestDeployment("d1", 1, nil), newTestDeployment("d2", 1, nil), newTestHpa("hpa", "d1")},
+			ao:   []*uo.UnstructuredObject{newTestDeployment("d1", 1, nil), newTestDeployment("d2", 1, nil)},
+			a: func(t *testing.T, dtc *diffTestConfig) {
+				assert.Len(t, dtc.du.ChangedObjects, 1)
+				assert.Equal(t, "d2", dtc.du.ChangedObjects[0].Ref.Name)
+				assert.Len(t, dtc.du.IgnoredChangedObjects, 1)
+				assert.Equal(t, "d1", dtc.du.IgnoredChangedObjects[0].Ref.Name)
+				assert.Equal(t, []result.Change{
+					buildChange("update", "spec.replicas", buildRaw(5), buildRaw(1), "-5\n+1"),
+				}, dtc.du.IgnoredChangedObjects[0].Changes)
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			test.dew = NewDeploymentErrorsAndWarnings()
 			test.ru = test.newRemoteObjects(test.dew)
-			test.du = NewDiffUtil(test.dew, test.ru, test.appliedObjectsMap())
+			test.du = NewDiffUtil(test.dew, test.ru, nil, test.appliedObjectsMap())
 			test.du.DiffDeploymentItems(test.newDeploymentItems())
 			test.a(t, test)
 		})
@@ -7,12 +7,13 @@ import (
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"sync"
 )
 
 type DeploymentErrorsAndWarnings struct {
-	errors   map[k8s.ObjectRef]map[result.DeploymentError]bool
-	warnings map[k8s.ObjectRef]map[result.DeploymentError]bool
+	errors   map[k8s.ObjectRef]map[string]result.DeploymentError
+	warnings map[k8s.ObjectRef]map[string]result.DeploymentError
 	mutex    sync.Mutex
 }
 
@@ -25,8 +26,8 @@ func NewDeploymentErrorsAndWarnings() *DeploymentErrorsAndWarnings {
 func (dew *DeploymentErrorsAndWarnings) Init() {
 	dew.mutex.Lock()
 	defer dew.mutex.Unlock()
-	dew.warnings = map[k8s.ObjectRef]map[result.DeploymentError]bool{}
-	dew.errors = map[k8s.ObjectRef]map[result.DeploymentError]bool{}
+	dew.warnings = map[k8s.ObjectRef]map[string]result.DeploymentError{}
+	dew.errors = map[k8s.ObjectRef]map[string]result.DeploymentError{}
 }
 
 func (dew *DeploymentErrorsAndWarnings) Clone() *DeploymentErrorsAndWarnings {
@@ -44,34 +45,50 @@ func (dew *DeploymentErrorsAndWarnings) Clone() *DeploymentErrorsAndWarnings {
 	return c
 }
 
-func (dew *DeploymentErrorsAndWarnings) AddWarning(ref k8s.ObjectRef, warning error) {
+// newDeploymentError converts err into a DeploymentError. If err is (or wraps) a Kubernetes Status error, the causes
+// found in its details are preserved.
+func newDeploymentError(ref k8s.ObjectRef, err error) result.DeploymentError {
 	de := result.DeploymentError{
 		Ref:     ref,
-		Message: warning.Error(),
+		Message: err.Error(),
+	}
+	var statusErr apierrors.APIStatus
+	if errors.As(err, &statusErr) {
+		if details := statusErr.Status().Details; details != nil {
+			for _, c := range details.Causes {
+				de.Causes = append(de.Causes, result.ErrorCause{
+					Field:   c.Field,
+					Reason:  string(c.Type),
+					Message: c.Message,
+				})
+			}
+		}
 	}
+	return de
+}
+
+func (dew *DeploymentErrorsAndWarnings) AddWarning(ref k8s.ObjectRef, warning error) {
+	de := newDeploymentError(ref, warning)
 	dew.mutex.Lock()
 	defer dew.mutex.Unlock()
 	m, ok := dew.warnings[ref]
 	if !ok {
-		m = make(map[result.DeploymentError]bool)
+		m = make(map[string]result.DeploymentError)
 		dew.warnings[ref] = m
 	}
-	m[de] = true
+	m[de.Message] = de
 }
 
 func (dew *DeploymentErrorsAndWarnings) AddError(ref k8s.ObjectRef, err error) {
-	de := result.DeploymentError{
-		Ref:     ref,
-		Message: err.Error(),
-	}
+	de := newDeploymentError(ref, err)
 	dew.mutex.Lock()
 	defer dew.mutex.Unlock()
 	m, ok := dew.errors[ref]
 	if !ok {
-		m = make(map[result.DeploymentError]bool)
+		m = make(map[string]result.DeploymentError)
 		dew.errors[ref] = m
 	}
-	m[de] = true
+	m[de.Message] = de
 }
 
 func (dew *DeploymentErrorsAndWarnings) AddApiWarnings(ref k8s.ObjectRef, warnings []k8s2.ApiWarning) {
@@ -92,7 +109,7 @@ func (dew *DeploymentErrorsAndWarnings) GetErrorsList() []result.DeploymentError
 	defer dew.mutex.Unlock()
 	var ret []result.DeploymentError
 	for _, m := range dew.errors {
-		for e := range m {
+		for _, e := range m {
 			ret = append(ret, e)
 		}
 	}
@@ -104,7 +121,7 @@ func (dew *DeploymentErrorsAndWarnings) GetWarningsList() []result.DeploymentErr
 	defer dew.mutex.Unlock()
 	var ret []result.DeploymentError
 	for _, m := range dew.warnings {
-		for e := range m {
+		for _, e := range m {
 			ret = append(ret, e)
 		}
 	}
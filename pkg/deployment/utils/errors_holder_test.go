@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestDeploymentErrorCauses(t *testing.T) {
+	ref := k8s.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "ns", Name: "d"}
+	statusErr := apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "d", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+	})
+
+	dew := NewDeploymentErrorsAndWarnings()
+	dew.AddError(ref, fmt.Errorf("failed to apply: %w", statusErr))
+	dew.AddError(ref, fmt.Errorf("plain error"))
+	dew.AddError(ref, fmt.Errorf("plain error"))
+
+	errors := dew.GetErrorsList()
+	assert.Len(t, errors, 2)
+	for _, e := range errors {
+		if e.Message == "plain error" {
+			assert.Nil(t, e.Causes)
+			continue
+		}
+		assert.Equal(t, []result.ErrorCause{
+			{Field: "spec.replicas", Reason: "FieldValueInvalid", Message: "Invalid value: -1: must be greater than or equal to 0"},
+		}, e.Causes)
+	}
+}
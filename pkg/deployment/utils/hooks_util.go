@@ -2,10 +2,16 @@ package utils
 
 import (
 	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/events"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/k8s"
+	types2 "github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"sort"
 	"strconv"
 	"strings"
@@ -24,6 +30,20 @@ var supportedKluctlDeletePolicies = []string{
 	"hook-failed",
 }
 
+const (
+	// HookRunIdLabel is set on all applied hooks and identifies the deployment run that created the hook
+	HookRunIdLabel = "kluctl.io/hook-run-id"
+	// HookRunStartedAnnotation is set on all applied hooks and contains the start time of the deployment run
+	HookRunStartedAnnotation = "kluctl.io/hook-run-started"
+	// HookDryRunSafeAnnotation marks hooks that are actually executed in dry-run deployments
+	HookDryRunSafeAnnotation = "kluctl.io/hook-dry-run-safe"
+	// HookCreatesAnnotation declares the objects that are created by a hook, e.g. by a Job
+	HookCreatesAnnotation = "kluctl.io/hook-creates"
+	// HookOwnedLabel is set on objects declared via HookCreatesAnnotation after the hook was executed. It contains the
+	// run id of the deployment run that executed the hook.
+	HookOwnedLabel = "kluctl.io/hook-owned"
+)
+
 var supportedHelmHooks = []string{
 	"pre-install", "post-install",
 	"pre-upgrade", "post-upgrade",
@@ -46,9 +66,12 @@ type hook struct {
 	object         *uo.UnstructuredObject
 	hooks          map[string]bool
 	weight         int
+	weightSource   string
 	deletePolicies map[string]bool
 	wait           bool
 	timeout        time.Duration
+	dryRunSafe     bool
+	creates        []types2.ObjectRefItem
 }
 
 func (u *HooksUtil) DetermineHooks(d *deployment.DeploymentItem, hooks []string) []*hook {
@@ -78,14 +101,17 @@ func (u *HooksUtil) RunHooks(hooks []*hook) {
 		applyObjects = append(applyObjects, h)
 	}
 
-	doDeleteForPolicy := func(h *hook, i int, cnt int) bool {
+	doDeleteForPolicy := func(h *hook, i int, cnt int) {
 		ref := h.object.GetK8sRef()
 		var dpStr []string
 		for p := range h.deletePolicies {
 			dpStr = append(dpStr, p)
 		}
 		u.a.sctx.UpdateAndInfoFallbackf("Deleting hook %s due to hook-delete-policy %s (%d of %d)", ref.String(), strings.Join(dpStr, ","), i+1, cnt)
-		return u.a.DeleteObject(ref, true)
+		u.withHookDryRunMode(h, func() {
+			u.a.DeleteObject(ref, true)
+			u.deleteHookOwnedObjects(h)
+		})
 	}
 
 	if len(deleteBeforeObjects) != 0 {
@@ -102,17 +128,30 @@ func (u *HooksUtil) RunHooks(hooks []*hook) {
 	for i, h := range applyObjects {
 		ref := h.object.GetK8sRef()
 		_, replaced := h.deletePolicies["before-hook-creation"]
-		u.a.sctx.UpdateAndInfoFallbackf("Applying hook %s (%d of %d)", ref.String(), i+1, len(applyObjects))
-		u.a.ApplyObject(h.di, h.object, replaced, true)
-		u.a.sctx.Increment()
-
-		if u.a.HadError(ref) {
-			continue
+		if u.isDryRunExecuted(h) {
+			u.a.sctx.UpdateAndInfoFallbackf("Executing dry-run-safe hook %s (%d of %d)", ref.String(), i+1, len(applyObjects))
+		} else {
+			u.a.sctx.UpdateAndInfoFallbackf("Applying hook %s (%d of %d)", ref.String(), i+1, len(applyObjects))
 		}
-		if !h.wait || u.a.o.NoWait {
-			continue
+		events.Emit(u.a.ctx, events.Event{Type: events.HookStarted, Ref: &ref, Hooks: h.hookTypes()})
+		u.withHookDryRunMode(h, func() {
+			u.a.ApplyObject(h.di, u.addRunTracking(h.object), replaced, true)
+			u.a.sctx.Increment()
+
+			if u.a.HadError(ref) {
+				return
+			}
+			if !h.wait || u.a.o.NoWait {
+				return
+			}
+			waitResults[ref] = u.a.WaitReadiness(ref, h.timeout, true)
+			u.markHookOwnedObjects(h)
+		})
+		success := !u.a.HadError(ref)
+		if waitResult, ok := waitResults[ref]; ok {
+			success = success && waitResult
 		}
-		waitResults[ref] = u.a.WaitReadiness(ref, h.timeout)
+		events.Emit(u.a.ctx, events.Event{Type: events.HookFinished, Ref: &ref, Hooks: h.hookTypes(), Success: &success})
 	}
 
 	var deleteAfterObjects []*hook
@@ -142,22 +181,214 @@ func (u *HooksUtil) RunHooks(hooks []*hook) {
 	}
 }
 
-func (u *HooksUtil) GetHook(di *deployment.DeploymentItem, o *uo.UnstructuredObject) *hook {
-	ref := o.GetK8sRef()
-	getSet := func(name string) map[string]bool {
-		ret := make(map[string]bool)
-		a := o.GetK8sAnnotation(name)
-		if a == nil {
-			return ret
-		}
-		for _, x := range strings.Split(*a, ",") {
-			x = strings.TrimSpace(x)
-			if x != "" {
-				ret[x] = true
+// hookTypes returns the sorted hook types (e.g. pre-deploy) of the hook
+func (h *hook) hookTypes() []string {
+	var ret []string
+	for t, ok := range h.hooks {
+		if ok {
+			ret = append(ret, t)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// isDryRunExecuted returns true if the hook is marked as dry-run-safe and thus actually executed in a dry-run deployment
+func (u *HooksUtil) isDryRunExecuted(h *hook) bool {
+	return u.a.o.DryRun && u.a.o.RunDryRunSafeHooks && h.dryRunSafe
+}
+
+// withHookDryRunMode temporarily disables dry-run mode for hooks that are actually executed in dry-run deployments.
+// Hooks of a single deployment item are executed sequentially, so it's safe to swap the cluster and options here.
+func (u *HooksUtil) withHookDryRunMode(h *hook, f func()) {
+	if !u.isDryRunExecuted(h) {
+		f()
+		return
+	}
+
+	oldK, oldO := u.a.k, u.a.o
+	o := *oldO
+	o.DryRun = false
+	u.a.k = oldK.ReadWrite()
+	u.a.o = &o
+	defer func() {
+		u.a.k = oldK
+		u.a.o = oldO
+	}()
+	f()
+}
+
+// addRunTracking adds the labels and annotations that allow to find hooks left behind by interrupted runs
+func (u *HooksUtil) addRunTracking(o *uo.UnstructuredObject) *uo.UnstructuredObject {
+	if u.a.o.HookRunId == "" {
+		return o
+	}
+	o = o.Clone()
+	o.SetK8sLabel(HookRunIdLabel, u.a.o.HookRunId)
+	o.SetK8sAnnotation(HookRunStartedAnnotation, u.a.o.HookRunStartTime.UTC().Format(time.RFC3339))
+	return o
+}
+
+// ParseHookCreatesAnnotation parses the kluctl.io/hook-creates annotation of the given hook, which contains a YAML/JSON
+// list of object references. The namespace of each reference defaults to the namespace of the hook.
+func ParseHookCreatesAnnotation(o *uo.UnstructuredObject) ([]types2.ObjectRefItem, error) {
+	a := o.GetK8sAnnotation(HookCreatesAnnotation)
+	if a == nil {
+		return nil, nil
+	}
+	var ret []types2.ObjectRefItem
+	err := yaml.ReadYamlString(*a, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", HookCreatesAnnotation, err)
+	}
+	for i := range ret {
+		if ret[i].Kind == nil || ret[i].Name == "" {
+			return nil, fmt.Errorf("invalid %s annotation: kind and name must be set", HookCreatesAnnotation)
+		}
+		if ret[i].Namespace == "" {
+			ret[i].Namespace = o.GetK8sNamespace()
+		}
+	}
+	return ret, nil
+}
+
+// resolveHookCreates returns the refs of all objects declared via the kluctl.io/hook-creates annotation of the hook
+func (u *HooksUtil) resolveHookCreates(h *hook) []k8s.ObjectRef {
+	var ret []k8s.ObjectRef
+	for _, x := range h.creates {
+		for _, ref := range u.a.ResolveObjectRefItem(x) {
+			if ref.Namespace != "" {
+				// the namespace defaults to the namespace of the hook, which is wrong for cluster scoped objects
+				if namespaced := u.a.k.IsNamespaced(ref.GroupVersionKind()); namespaced != nil && !*namespaced {
+					ref.Namespace = ""
+				}
 			}
+			ret = append(ret, ref)
+		}
+	}
+	return ret
+}
+
+// buildHookOwnedPatch builds the partial object that is applied to objects created by the given hook. It marks the
+// objects with the run id and the discriminator, so that they are found by cleanup-hooks, and copies the delete
+// policies of the hook, so that they are handled the same way as the hook itself.
+func buildHookOwnedPatch(hookObject *uo.UnstructuredObject, ref k8s.ObjectRef, deletePolicies map[string]bool, runId string, runStarted time.Time) *uo.UnstructuredObject {
+	o := uo.New()
+	o.SetK8sGVK(ref.GroupVersionKind())
+	o.SetK8sName(ref.Name)
+	if ref.Namespace != "" {
+		o.SetK8sNamespace(ref.Namespace)
+	}
+	o.SetK8sLabel(HookOwnedLabel, runId)
+	if d := hookObject.GetK8sLabel("kluctl.io/discriminator"); d != nil {
+		o.SetK8sLabel("kluctl.io/discriminator", *d)
+	}
+	var policies []string
+	for p := range deletePolicies {
+		policies = append(policies, p)
+	}
+	sort.Strings(policies)
+	o.SetK8sAnnotation("kluctl.io/hook-delete-policy", strings.Join(policies, ","))
+	o.SetK8sAnnotation(HookRunStartedAnnotation, runStarted.UTC().Format(time.RFC3339))
+	return o
+}
+
+// markHookOwnedObjects marks all objects declared via kluctl.io/hook-creates as owned by the current run of the hook
+func (u *HooksUtil) markHookOwnedObjects(h *hook) {
+	if u.a.o.DryRun || u.a.o.HookRunId == "" {
+		return
+	}
+	hookRef := h.object.GetK8sRef()
+	for _, ref := range u.resolveHookCreates(h) {
+		_, apiWarnings, err := u.a.k.GetSingleObjectMetadata(ref)
+		u.a.handleApiWarnings(ref, apiWarnings)
+		if errors.IsNotFound(err) {
+			u.a.HandleWarning(ref, fmt.Errorf("object was declared via %s of hook %s, but was not created by the hook", HookCreatesAnnotation, hookRef.String()))
+			continue
+		} else if err != nil {
+			u.a.HandleError(ref, err)
+			continue
+		}
+
+		status.Tracef(u.a.ctx, "Marking %s as owned by hook %s", ref.String(), hookRef.String())
+		patch := buildHookOwnedPatch(h.object, ref, h.deletePolicies, u.a.o.HookRunId, u.a.o.HookRunStartTime)
+		_, apiWarnings, err = u.a.k.ApplyObject(patch, k8s2.PatchOptions{})
+		u.a.handleApiWarnings(ref, apiWarnings)
+		if err != nil {
+			u.a.HandleError(ref, fmt.Errorf("failed to mark object as owned by hook %s: %w", hookRef.String(), err))
+		}
+	}
+}
+
+// deleteHookOwnedObjects deletes all objects declared via kluctl.io/hook-creates which were marked as owned by a
+// previous run of the hook. Objects that are not marked are never deleted.
+func (u *HooksUtil) deleteHookOwnedObjects(h *hook) {
+	for _, ref := range u.resolveHookCreates(h) {
+		o, apiWarnings, err := u.a.k.GetSingleObjectMetadata(ref)
+		u.a.handleApiWarnings(ref, apiWarnings)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			u.a.HandleError(ref, err)
+			continue
+		}
+		if o.GetK8sLabel(HookOwnedLabel) == nil {
+			continue
+		}
+		u.a.DeleteObject(ref, true)
+	}
+}
+
+// FilterHookOwned removes all objects that were created by hooks, as these are cleaned up together with the hooks
+// and must never be treated as orphans
+func FilterHookOwned(objects []*uo.UnstructuredObject) []*uo.UnstructuredObject {
+	var ret []*uo.UnstructuredObject
+	for _, o := range objects {
+		if o.GetK8sLabel(HookOwnedLabel) != nil {
+			continue
 		}
+		ret = append(ret, o)
+	}
+	return ret
+}
+
+func getAnnotationSet(o *uo.UnstructuredObject, name string) map[string]bool {
+	ret := make(map[string]bool)
+	a := o.GetK8sAnnotation(name)
+	if a == nil {
 		return ret
 	}
+	for _, x := range strings.Split(*a, ",") {
+		x = strings.TrimSpace(x)
+		if x != "" {
+			ret[x] = true
+		}
+	}
+	return ret
+}
+
+func getHookDeletePolicies(o *uo.UnstructuredObject, argoCD bool) map[string]bool {
+	deletePolicy := getAnnotationSet(o, "kluctl.io/hook-delete-policy")
+	for d := range getAnnotationSet(o, "helm.sh/hook-delete-policy") {
+		deletePolicy[d] = true
+	}
+	if argoCD {
+		policies, _ := deployment.GetArgoCDHookDeletePolicies(o)
+		for _, d := range policies {
+			deletePolicy[d] = true
+		}
+	}
+	if len(deletePolicy) == 0 {
+		deletePolicy["before-hook-creation"] = true
+	}
+	return deletePolicy
+}
+
+func (u *HooksUtil) GetHook(di *deployment.DeploymentItem, o *uo.UnstructuredObject) *hook {
+	ref := o.GetK8sRef()
+	getSet := func(name string) map[string]bool {
+		return getAnnotationSet(o, name)
+	}
 
 	if o.GetK8sAnnotationBoolNoError("kluctl.io/delete", false) {
 		return nil
@@ -192,26 +423,42 @@ func (u *HooksUtil) GetHook(di *deployment.DeploymentItem, o *uo.UnstructuredObj
 	helmCompatibility("pre-rollback", "pre-rollback")   // actually not implemented, so it will be ignored
 	helmCompatibility("post-rollback", "post-rollback") // actually not implemented, so it will be ignored
 
-	weightStr := o.GetK8sAnnotation("kluctl.io/hook-weight")
+	argoCD := di != nil && di.Project != nil && di.Project.IsArgoCDCompatibility()
+	if argoCD {
+		argoHooks, warnings := deployment.GetArgoCDHooks(o)
+		for _, h := range argoHooks {
+			hooks[h] = true
+		}
+		_, policyWarnings := deployment.GetArgoCDHookDeletePolicies(o)
+		warnings = append(warnings, policyWarnings...)
+		warnings = append(warnings, deployment.GetArgoCDUnsupportedWarnings(o)...)
+		for _, w := range warnings {
+			u.a.HandleWarning(ref, w)
+		}
+	}
+
+	weightSource := "kluctl.io/hook-weight"
+	weightStr := o.GetK8sAnnotation(weightSource)
 	if weightStr == nil {
-		weightStr = o.GetK8sAnnotation("helm.sh/hook-weight")
+		weightSource = "helm.sh/hook-weight"
+		weightStr = o.GetK8sAnnotation(weightSource)
+	}
+	if weightStr == nil && argoCD {
+		// Argo CD orders hooks of the same phase by their sync-wave
+		weightSource = deployment.ArgoCDSyncWaveAnnotation
+		weightStr = o.GetK8sAnnotation(weightSource)
 	}
 	if weightStr == nil {
 		x := "0"
 		weightStr = &x
+		weightSource = "default"
 	}
-	weight, err := strconv.ParseInt(*weightStr, 10, 32)
+	weight, err := strconv.ParseInt(strings.TrimSpace(*weightStr), 10, 32)
 	if err != nil {
 		u.a.HandleError(ref, fmt.Errorf("failed to parse hook weight: %w", err))
 	}
 
-	deletePolicy := getSet("kluctl.io/hook-delete-policy")
-	for d := range getSet("helm.sh/hook-delete-policy") {
-		deletePolicy[d] = true
-	}
-	if len(deletePolicy) == 0 {
-		deletePolicy["before-hook-creation"] = true
-	}
+	deletePolicy := getHookDeletePolicies(o, argoCD)
 
 	for p := range deletePolicy {
 		if utils.FindStrInSlice(supportedKluctlDeletePolicies, p) == -1 {
@@ -224,6 +471,16 @@ func (u *HooksUtil) GetHook(di *deployment.DeploymentItem, o *uo.UnstructuredObj
 		u.a.HandleError(ref, err)
 	}
 
+	dryRunSafe, err := o.GetK8sAnnotationBool(HookDryRunSafeAnnotation, false)
+	if err != nil {
+		u.a.HandleError(ref, err)
+	}
+
+	creates, err := ParseHookCreatesAnnotation(o)
+	if err != nil {
+		u.a.HandleError(ref, err)
+	}
+
 	timeoutStr := o.GetK8sAnnotation("kluctl.io/hook-timeout")
 	var timeout time.Duration
 	if timeoutStr != nil {
@@ -244,9 +501,12 @@ func (u *HooksUtil) GetHook(di *deployment.DeploymentItem, o *uo.UnstructuredObj
 		object:         o,
 		hooks:          hooks,
 		weight:         int(weight),
+		weightSource:   weightSource,
 		deletePolicies: deletePolicy,
 		wait:           wait,
 		timeout:        timeout,
+		dryRunSafe:     dryRunSafe,
+		creates:        creates,
 	}
 }
 
@@ -266,7 +526,11 @@ func (u *HooksUtil) getSortedHooksList(d *deployment.DeploymentItem) []*hook {
 }
 
 func (h *hook) IsPersistent() bool {
-	for p := range h.deletePolicies {
+	return isPersistentDeletePolicy(h.deletePolicies)
+}
+
+func isPersistentDeletePolicy(deletePolicies map[string]bool) bool {
+	for p := range deletePolicies {
 		if p != "before-hook-creation" && p != "hook-failed" {
 			return false
 		}
@@ -297,3 +561,69 @@ func (h *hook) IsOnlyRollback() bool {
 	}
 	return found
 }
+
+// IsNeverExecuted returns true if none of the hooks is executed by kluctl, e.g. for delete and rollback hooks or for
+// Argo CD hooks without kluctl equivalent
+func (h *hook) IsNeverExecuted() bool {
+	for x := range h.hooks {
+		if utils.FindStrInSlice(supportedKluctlHooks, x) != -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// LeftoverHook describes a hook that was not deleted by the run that created it, even though its delete policy
+// requires deletion after execution. This usually happens when a run gets interrupted while executing hooks.
+type LeftoverHook struct {
+	Ref        k8s.ObjectRef
+	RunId      string
+	RunStarted time.Time
+	// HookOwned is true if the object is not a hook itself, but was created by a hook
+	HookOwned bool
+}
+
+// FindLeftoverHooks returns all hooks and objects created by hooks found in objects which were applied by runs other
+// than currentRunId that started before startedBefore, and which should have been deleted after execution.
+func FindLeftoverHooks(objects []*uo.UnstructuredObject, currentRunId string, startedBefore time.Time) []LeftoverHook {
+	var ret []LeftoverHook
+	for _, o := range objects {
+		hookOwned := false
+		runId := o.GetK8sLabel(HookRunIdLabel)
+		if runId == nil {
+			runId = o.GetK8sLabel(HookOwnedLabel)
+			hookOwned = true
+		}
+		if runId == nil || *runId == currentRunId {
+			continue
+		}
+		// only objects that were applied as hooks are considered here, so Argo CD delete policies can be honored
+		// unconditionally
+		deletePolicies := getHookDeletePolicies(o, true)
+		if !deletePolicies["hook-succeeded"] && !deletePolicies["hook-failed"] {
+			// hooks are intentionally kept until the next run
+			continue
+		}
+
+		runStarted := o.GetK8sCreationTime()
+		if s := o.GetK8sAnnotation(HookRunStartedAnnotation); s != nil {
+			if t, err := time.Parse(time.RFC3339, *s); err == nil {
+				runStarted = t
+			}
+		}
+		if !runStarted.Before(startedBefore) {
+			continue
+		}
+
+		ret = append(ret, LeftoverHook{
+			Ref:        o.GetK8sRef(),
+			RunId:      *runId,
+			RunStarted: runStarted,
+			HookOwned:  hookOwned,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Ref.String() < ret[j].Ref.String()
+	})
+	return ret
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newLeftoverHookTestObject(name string, runId string, started time.Time, deletePolicy string) *uo.UnstructuredObject {
+	o := uo.New()
+	_ = o.SetNestedField("batch/v1", "apiVersion")
+	_ = o.SetNestedField("Job", "kind")
+	o.SetK8sName(name)
+	o.SetK8sNamespace("ns")
+	o.SetK8sAnnotation("kluctl.io/hook", "post-deploy")
+	if runId != "" {
+		o.SetK8sLabel(HookRunIdLabel, runId)
+		o.SetK8sAnnotation(HookRunStartedAnnotation, started.UTC().Format(time.RFC3339))
+	}
+	if deletePolicy != "" {
+		o.SetK8sAnnotation("kluctl.io/hook-delete-policy", deletePolicy)
+	}
+	return o
+}
+
+func TestFindLeftoverHooks(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+
+	objects := []*uo.UnstructuredObject{
+		newLeftoverHookTestObject("untracked", "", old, "hook-succeeded"),
+		newLeftoverHookTestObject("persistent", "run1", old, ""),
+		newLeftoverHookTestObject("succeeded", "run1", old, "hook-succeeded"),
+		newLeftoverHookTestObject("failed", "run1", old, "before-hook-creation,hook-failed"),
+		newLeftoverHookTestObject("recent", "run2", now, "hook-succeeded"),
+		newLeftoverHookTestObject("current", "run3", old, "hook-succeeded"),
+	}
+
+	l := FindLeftoverHooks(objects, "run3", now.Add(-time.Hour))
+	var names []string
+	for _, h := range l {
+		names = append(names, h.Ref.Name)
+		assert.Equal(t, "run1", h.RunId)
+		assert.Equal(t, old.Unix(), h.RunStarted.Unix())
+	}
+	assert.Equal(t, []string{"failed", "succeeded"}, names)
+
+	l = FindLeftoverHooks(objects, "", now.Add(time.Minute))
+	assert.Len(t, l, 4)
+}
+
+func TestFindLeftoverHookOwnedObjects(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+
+	hook := newLeftoverHookTestObject("hook", "", old, "")
+	ref := k8s.ObjectRef{Version: "v1", Kind: "Secret", Name: "created", Namespace: "ns"}
+	objects := []*uo.UnstructuredObject{
+		buildHookOwnedPatch(hook, ref, map[string]bool{"hook-succeeded": true}, "run1", old),
+		buildHookOwnedPatch(hook, ref, map[string]bool{"before-hook-creation": true}, "run1", old),
+	}
+	assert.Equal(t, "run1", *objects[0].GetK8sLabel(HookOwnedLabel))
+
+	l := FindLeftoverHooks(objects, "run2", now)
+	if assert.Len(t, l, 1) {
+		assert.Equal(t, ref, l[0].Ref)
+		assert.Equal(t, "run1", l[0].RunId)
+		assert.True(t, l[0].HookOwned)
+	}
+
+	assert.Empty(t, FilterHookOwned(objects))
+	assert.Len(t, FilterHookOwned([]*uo.UnstructuredObject{hook}), 1)
+}
+
+func TestParseHookCreatesAnnotation(t *testing.T) {
+	o := newLeftoverHookTestObject("hook", "", time.Time{}, "")
+	l, err := ParseHookCreatesAnnotation(o)
+	assert.NoError(t, err)
+	assert.Nil(t, l)
+
+	o.SetK8sAnnotation(HookCreatesAnnotation, `
+- kind: Secret
+  name: s1
+- group: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: r1
+  namespace: other
+`)
+	l, err = ParseHookCreatesAnnotation(o)
+	assert.NoError(t, err)
+	if assert.Len(t, l, 2) {
+		assert.Equal(t, "Secret", *l[0].Kind)
+		assert.Equal(t, "s1", l[0].Name)
+		assert.Equal(t, "ns", l[0].Namespace)
+		assert.Equal(t, "rbac.authorization.k8s.io", *l[1].Group)
+		assert.Equal(t, "other", l[1].Namespace)
+	}
+
+	o.SetK8sAnnotation(HookCreatesAnnotation, `- name: s1`)
+	_, err = ParseHookCreatesAnnotation(o)
+	assert.ErrorContains(t, err, "kind and name must be set")
+
+	o.SetK8sAnnotation(HookCreatesAnnotation, `{`)
+	_, err = ParseHookCreatesAnnotation(o)
+	assert.Error(t, err)
+}
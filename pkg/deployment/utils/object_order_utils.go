@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func describeObjectOrderConfig(c types.ObjectOrderConfig) string {
+	group := "*"
+	kind := "*"
+	if c.Group != nil {
+		group = *c.Group
+	}
+	if c.Kind != nil {
+		kind = *c.Kind
+	}
+	return fmt.Sprintf("group=%s, kind=%s", group, kind)
+}
+
+// WarnUnknownObjectOrderKinds adds warnings for all objectOrder entries that neither match a kind known to the
+// cluster nor any of the rendered objects, including kinds defined by rendered CRDs
+func WarnUnknownObjectOrderKinds(k *k8s.K8sCluster, deployments []*deployment.DeploymentItem, dew *DeploymentErrorsAndWarnings) {
+	known := map[schema.GroupKind]bool{}
+	if k != nil {
+		ars, err := k.GetAllAPIResources()
+		if err != nil {
+			dew.AddWarning(k8s2.ObjectRef{}, fmt.Errorf("failed to check objectOrder entries: %w", err))
+			return
+		}
+		for _, ar := range ars {
+			known[schema.GroupKind{Group: ar.Group, Kind: ar.Kind}] = true
+		}
+	}
+
+	var configs []types.ObjectOrderConfig
+	seenProjects := map[*deployment.DeploymentProject]bool{}
+	for _, d := range deployments {
+		if d.Project != nil && !seenProjects[d.Project] {
+			seenProjects[d.Project] = true
+			configs = append(configs, d.Project.GetObjectOrder().GetConfigs()...)
+		}
+		for _, o := range d.Objects {
+			ref := o.GetK8sRef()
+			known[ref.GroupKind()] = true
+			if ref.Group == "apiextensions.k8s.io" && ref.Kind == "CustomResourceDefinition" {
+				group, _, _ := o.GetNestedString("spec", "group")
+				kind, _, _ := o.GetNestedString("spec", "names", "kind")
+				known[schema.GroupKind{Group: group, Kind: kind}] = true
+			}
+		}
+	}
+
+	warned := map[string]bool{}
+	for _, c := range configs {
+		found := false
+		for gk := range known {
+			if (c.Group == nil || *c.Group == gk.Group) && (c.Kind == nil || *c.Kind == gk.Kind) {
+				found = true
+				break
+			}
+		}
+		desc := describeObjectOrderConfig(c)
+		if !found && !warned[desc] {
+			warned[desc] = true
+			dew.AddWarning(k8s2.ObjectRef{}, fmt.Errorf("objectOrder entry (%s) does not match any known kind", desc))
+		}
+	}
+}
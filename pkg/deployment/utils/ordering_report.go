@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+func describeHook(h *hook) string {
+	var hooks []string
+	for x := range h.hooks {
+		hooks = append(hooks, x)
+	}
+	sort.Strings(hooks)
+	return fmt.Sprintf("%s (weight %d from %s, hooks: %s)", h.object.GetK8sRef().String(), h.weight, h.weightSource, strings.Join(hooks, ","))
+}
+
+func hasAnyHook(h *hook, names ...string) bool {
+	for _, n := range names {
+		if h.hooks[n] {
+			return true
+		}
+	}
+	return false
+}
+
+// DescribeOrdering renders a human readable description of the order in which hooks and objects of the given
+// deployment items are applied, including where the individual weights originate from. Warnings and errors found
+// while determining the order (e.g. Argo CD annotations without kluctl equivalent) are added to dew.
+func DescribeOrdering(ctx context.Context, dew *DeploymentErrorsAndWarnings, deployments []*deployment.DeploymentItem) string {
+	ad := NewApplyDeploymentsUtil(ctx, dew, nil, nil, &ApplyUtilOptions{})
+	a := ad.NewApplyUtil(ctx, nil)
+	h := NewHooksUtil(a)
+
+	buf := strings.Builder{}
+	for _, d := range deployments {
+		name := ad.buildProgressName(d)
+		if name != nil {
+			buf.WriteString(fmt.Sprintf("Deployment item %s", *name))
+			if d.Project != nil && d.Project.IsArgoCDCompatibility() {
+				buf.WriteString(" (Argo CD compatibility enabled)")
+			}
+			buf.WriteString(":\n")
+
+			var preHooks, postHooks, otherHooks []string
+			for _, x := range h.getSortedHooksList(d) {
+				switch {
+				case hasAnyHook(x, "pre-deploy", "pre-deploy-initial", "pre-deploy-upgrade"):
+					preHooks = append(preHooks, describeHook(x))
+				case hasAnyHook(x, "post-deploy", "post-deploy-initial", "post-deploy-upgrade"):
+					postHooks = append(postHooks, describeHook(x))
+				default:
+					otherHooks = append(otherHooks, describeHook(x))
+				}
+			}
+
+			var objects []*uo.UnstructuredObject
+			for _, o := range d.Objects {
+				if h.GetHook(d, o) == nil && !o.GetK8sAnnotationBoolNoError("kluctl.io/delete", false) {
+					objects = append(objects, o)
+				}
+			}
+			order := d.Project.GetObjectOrder()
+			order.SortObjects(objects, nil)
+			var objectLines []string
+			for _, o := range objects {
+				w, source, err := order.GetWeightAndSource(o)
+				if err != nil {
+					a.HandleWarning(o.GetK8sRef(), err)
+				}
+				objectLines = append(objectLines, fmt.Sprintf("%s (weight %d from %s)", o.GetK8sRef().String(), w, source))
+			}
+
+			writeSection := func(title string, lines []string) {
+				if len(lines) == 0 {
+					return
+				}
+				buf.WriteString(fmt.Sprintf("  %s:\n", title))
+				for _, l := range lines {
+					buf.WriteString(fmt.Sprintf("    %s\n", l))
+				}
+			}
+			writeSection("Pre-deploy hooks", preHooks)
+			writeSection("Objects", objectLines)
+			writeSection("Post-deploy hooks", postHooks)
+			writeSection("Hooks that are never executed", otherHooks)
+		}
+
+		if d.Config.Barrier || d.Barrier {
+			buf.WriteString("Barrier: waiting for all previous deployment items to finish\n")
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"strings"
+)
+
+const (
+	LastAppliedConfigurationAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+	KubectlClientSideApplyManager      = "kubectl-client-side-apply"
+
+	// KluctlFieldManager is the field manager used for all server-side applies
+	KluctlFieldManager = "kluctl"
+)
+
+// takeOwnership transfers ownership of all fields owned by the field managers listed in TakeOwnershipFrom to kluctl
+// and removes the last-applied-configuration annotation left behind by kubectl. It returns true if the object was
+// migrated, in which case the following apply must not run into conflicts with the previous owners.
+func (a *ApplyUtil) takeOwnership(ref k8s2.ObjectRef, remoteObject *uo.UnstructuredObject) bool {
+	managers := sets.New(a.o.TakeOwnershipFrom...)
+
+	migrated, patch, err := buildTakeOwnershipPatch(remoteObject, managers)
+	if err != nil {
+		a.HandleError(ref, fmt.Errorf("failed to build ownership migration patch: %w", err))
+		return false
+	}
+	if patch == nil {
+		return false
+	}
+
+	status.Tracef(a.ctx, "taking ownership of %s from %s", ref.String(), strings.Join(migrated, ", "))
+
+	options := k8s.PatchOptions{
+		ForceDryRun: a.o.DryRun,
+	}
+	_, apiWarnings, err := a.k.JsonPatchObject(remoteObject, patch, options)
+	a.handleApiWarnings(ref, apiWarnings)
+	if err != nil {
+		a.HandleError(ref, fmt.Errorf("failed to take ownership: %w", err))
+		return false
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.ownershipMigrations[ref] = &result.OwnershipMigration{
+		From: migrated,
+	}
+	return true
+}
+
+// buildTakeOwnershipPatch builds a JSON patch that upgrades the managed fields of the given managers to kluctl owned
+// server-side apply fields and removes the last-applied-configuration annotation. It returns the managers that
+// actually owned fields of the object and nil if nothing needs to be migrated.
+func buildTakeOwnershipPatch(o *uo.UnstructuredObject, managers sets.Set[string]) ([]string, []byte, error) {
+	var migrated []string
+	for _, mf := range o.GetK8sManagedFields() {
+		manager, _, _ := mf.GetNestedString("manager")
+		operation, _, _ := mf.GetNestedString("operation")
+		if operation == "Update" && managers.Has(manager) {
+			migrated = append(migrated, manager)
+		}
+	}
+	hasLastApplied := o.GetK8sAnnotation(LastAppliedConfigurationAnnotation) != nil
+	if len(migrated) == 0 && !hasLastApplied {
+		return nil, nil, nil
+	}
+
+	var ops []map[string]any
+	upgradePatch, err := csaupgrade.UpgradeManagedFieldsPatch(o.ToUnstructured(), managers, KluctlFieldManager)
+	if err != nil {
+		return nil, nil, err
+	}
+	if upgradePatch != nil {
+		err = json.Unmarshal(upgradePatch, &ops)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if hasLastApplied {
+		ops = append(ops, map[string]any{
+			"op":   "remove",
+			"path": "/metadata/annotations/" + strings.ReplaceAll(LastAppliedConfigurationAnnotation, "/", "~1"),
+		})
+	}
+
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(migrated) != 0 {
+		migrated = sets.List(sets.New(migrated...))
+	}
+	return migrated, patch, nil
+}
+
+// getCoOwners returns all field managers except kluctl and the given managers that still own fields of the main
+// resource (status and other subresources are ignored)
+func getCoOwners(o *uo.UnstructuredObject, ignoreManagers []string) []string {
+	ignore := sets.New(ignoreManagers...)
+	ignore.Insert(KluctlFieldManager)
+
+	m := sets.New[string]()
+	for _, mf := range o.GetK8sManagedFields() {
+		manager, _, _ := mf.GetNestedString("manager")
+		subresource, _, _ := mf.GetNestedString("subresource")
+		if subresource != "" || ignore.Has(manager) {
+			continue
+		}
+		m.Insert(manager)
+	}
+	return sets.List(m)
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"encoding/json"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"testing"
+)
+
+func newOwnershipTestObject(t *testing.T, lastApplied bool, managedFields ...map[string]any) *uo.UnstructuredObject {
+	o := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":            "cm",
+			"namespace":       "ns",
+			"resourceVersion": "1",
+		},
+		"data": map[string]any{
+			"a": "b",
+		},
+	})
+	if lastApplied {
+		o.SetK8sAnnotation(LastAppliedConfigurationAnnotation, "{}")
+	}
+	var l []any
+	for _, mf := range managedFields {
+		l = append(l, mf)
+	}
+	if l != nil {
+		assert.NoError(t, o.SetNestedField(l, "metadata", "managedFields"))
+	}
+	return o
+}
+
+func newManagedFieldsEntry(manager string, operation string, subresource string) map[string]any {
+	ret := map[string]any{
+		"manager":    manager,
+		"operation":  operation,
+		"apiVersion": "v1",
+		"fieldsType": "FieldsV1",
+		"fieldsV1": map[string]any{
+			"f:data": map[string]any{
+				"f:a": map[string]any{},
+			},
+		},
+	}
+	if subresource != "" {
+		ret["subresource"] = subresource
+	}
+	return ret
+}
+
+func TestBuildTakeOwnershipPatch(t *testing.T) {
+	managers := sets.New(KubectlClientSideApplyManager)
+
+	o := newOwnershipTestObject(t, false, newManagedFieldsEntry("kluctl", "Apply", ""))
+	migrated, patch, err := buildTakeOwnershipPatch(o, managers)
+	assert.NoError(t, err)
+	assert.Nil(t, migrated)
+	assert.Nil(t, patch)
+
+	o = newOwnershipTestObject(t, true, newManagedFieldsEntry(KubectlClientSideApplyManager, "Update", ""))
+	migrated, patch, err = buildTakeOwnershipPatch(o, managers)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{KubectlClientSideApplyManager}, migrated)
+
+	var ops []map[string]any
+	assert.NoError(t, json.Unmarshal(patch, &ops))
+	var paths []string
+	for _, op := range ops {
+		paths = append(paths, op["path"].(string))
+	}
+	assert.Equal(t, []string{
+		"/metadata/managedFields",
+		"/metadata/resourceVersion",
+		"/metadata/annotations/kubectl.kubernetes.io~1last-applied-configuration",
+	}, paths)
+
+	mfs := ops[0]["value"].([]any)
+	assert.Len(t, mfs, 1)
+	assert.Equal(t, "kluctl", mfs[0].(map[string]any)["manager"])
+	assert.Equal(t, "Apply", mfs[0].(map[string]any)["operation"])
+
+	// only the annotation needs to be removed
+	o = newOwnershipTestObject(t, true, newManagedFieldsEntry("kluctl", "Apply", ""))
+	migrated, patch, err = buildTakeOwnershipPatch(o, managers)
+	assert.NoError(t, err)
+	assert.Nil(t, migrated)
+	assert.JSONEq(t, `[{"op": "remove", "path": "/metadata/annotations/kubectl.kubernetes.io~1last-applied-configuration"}]`, string(patch))
+}
+
+func TestGetCoOwners(t *testing.T) {
+	o := newOwnershipTestObject(t, false,
+		newManagedFieldsEntry("kluctl", "Apply", ""),
+		newManagedFieldsEntry(KubectlClientSideApplyManager, "Update", ""),
+		newManagedFieldsEntry("kubectl-edit", "Update", ""),
+		newManagedFieldsEntry("controller", "Update", "status"),
+		newManagedFieldsEntry("helm", "Update", ""),
+	)
+	assert.Equal(t, []string{"helm", "kubectl-edit"}, getCoOwners(o, []string{KubectlClientSideApplyManager}))
+}
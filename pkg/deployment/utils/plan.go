@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+)
+
+type ApplyPlanStepType string
+
+const (
+	ApplyPlanStepDeploymentItem ApplyPlanStepType = "deploymentItem"
+	ApplyPlanStepBarrier        ApplyPlanStepType = "barrier"
+	ApplyPlanStepPrune          ApplyPlanStepType = "prune"
+)
+
+type ApplyPlanOperationType string
+
+const (
+	ApplyPlanOperationDelete          ApplyPlanOperationType = "delete"
+	ApplyPlanOperationPreDeployHooks  ApplyPlanOperationType = "preDeployHooks"
+	ApplyPlanOperationApply           ApplyPlanOperationType = "apply"
+	ApplyPlanOperationWaitReadiness   ApplyPlanOperationType = "waitReadiness"
+	ApplyPlanOperationPostDeployHooks ApplyPlanOperationType = "postDeployHooks"
+	ApplyPlanOperationPostDeployWaits ApplyPlanOperationType = "postDeployWaits"
+	ApplyPlanOperationPrune           ApplyPlanOperationType = "prune"
+)
+
+// ApplyPlan is the ordered sequence of steps that ApplyDeployments would perform. Deployment item steps between two
+// barriers are executed in parallel.
+type ApplyPlan struct {
+	Steps []ApplyPlanStep `json:"steps"`
+
+	// EstimatedSteps is the sum of all progress steps that are reported while executing the plan
+	EstimatedSteps int `json:"estimatedSteps"`
+}
+
+type ApplyPlanStep struct {
+	Type           ApplyPlanStepType `json:"type"`
+	DeploymentItem string            `json:"deploymentItem,omitempty"`
+	InitialDeploy  bool              `json:"initialDeploy,omitempty"`
+	Message        string            `json:"message,omitempty"`
+
+	Operations     []ApplyPlanOperation `json:"operations,omitempty"`
+	EstimatedSteps int                  `json:"estimatedSteps"`
+}
+
+type ApplyPlanOperation struct {
+	Type    ApplyPlanOperationType `json:"type"`
+	Count   int                    `json:"count"`
+	Objects []k8s2.ObjectRef       `json:"objects,omitempty"`
+}
+
+// BuildPlan determines the ordered operations that ApplyDeployments would perform for the given deployment items,
+// without performing any of them. Remote objects must already be loaded, as they determine whether the initial or the
+// upgrade hooks are used.
+func (ad *ApplyDeploymentsUtil) BuildPlan(deployments []*deployment.DeploymentItem) *ApplyPlan {
+	a := ad.NewApplyUtil(ad.ctx, nil)
+
+	plan := &ApplyPlan{}
+	for _, d := range deployments {
+		name := ad.buildProgressName(d)
+		if name != nil {
+			p := a.planDeploymentItem(d)
+
+			step := ApplyPlanStep{
+				Type:           ApplyPlanStepDeploymentItem,
+				DeploymentItem: *name,
+				InitialDeploy:  p.initialDeploy,
+				EstimatedSteps: p.estimatedSteps(),
+			}
+			addOp := func(t ApplyPlanOperationType, refs []k8s2.ObjectRef) {
+				if len(refs) != 0 {
+					step.Operations = append(step.Operations, ApplyPlanOperation{Type: t, Count: len(refs), Objects: refs})
+				}
+			}
+
+			addOp(ApplyPlanOperationDelete, sortedRefs(p.toDelete))
+			addOp(ApplyPlanOperationPreDeployHooks, hookRefs(p.preHooks))
+			var applyRefs []k8s2.ObjectRef
+			for _, o := range p.applyObjects {
+				applyRefs = append(applyRefs, o.GetK8sRef())
+			}
+			addOp(ApplyPlanOperationApply, applyRefs)
+			if !ad.o.NoWait {
+				addOp(ApplyPlanOperationWaitReadiness, sortedRefs(p.toWaitReadiness))
+			}
+			addOp(ApplyPlanOperationPostDeployHooks, hookRefs(p.postHooks))
+			if !ad.o.NoWait {
+				var waitRefs []k8s2.ObjectRef
+				for _, c := range d.Config.PostDeployWaits {
+					waitRefs = append(waitRefs, a.ResolveObjectRefItem(c.ObjectRefItem)...)
+				}
+				addOp(ApplyPlanOperationPostDeployWaits, waitRefs)
+			}
+
+			plan.Steps = append(plan.Steps, step)
+			plan.EstimatedSteps += step.EstimatedSteps
+		}
+
+		if d.Config.Barrier || d.Barrier {
+			step := ApplyPlanStep{
+				Type:           ApplyPlanStepBarrier,
+				EstimatedSteps: 1,
+			}
+			if d.Config.Message != nil {
+				step.Message = *d.Config.Message
+			}
+			plan.Steps = append(plan.Steps, step)
+			plan.EstimatedSteps += step.EstimatedSteps
+		}
+	}
+	return plan
+}
+
+// AddPruneStep appends the deletion of the given orphan objects to the plan
+func (p *ApplyPlan) AddPruneStep(orphanObjects []k8s2.ObjectRef) {
+	if len(orphanObjects) == 0 {
+		return
+	}
+	p.Steps = append(p.Steps, ApplyPlanStep{
+		Type: ApplyPlanStepPrune,
+		Operations: []ApplyPlanOperation{
+			{Type: ApplyPlanOperationPrune, Count: len(orphanObjects), Objects: orphanObjects},
+		},
+		EstimatedSteps: len(orphanObjects),
+	})
+	p.EstimatedSteps += len(orphanObjects)
+}
+
+var applyPlanOperationTitles = map[ApplyPlanOperationType]string{
+	ApplyPlanOperationDelete:          "Delete %d objects",
+	ApplyPlanOperationPreDeployHooks:  "Run %d pre-deploy hooks",
+	ApplyPlanOperationApply:           "Apply %d objects",
+	ApplyPlanOperationWaitReadiness:   "Wait for readiness of %d objects",
+	ApplyPlanOperationPostDeployHooks: "Run %d post-deploy hooks",
+	ApplyPlanOperationPostDeployWaits: "Wait for post-deploy conditions of %d objects",
+	ApplyPlanOperationPrune:           "Delete %d orphan objects",
+}
+
+// DescribeApplyPlan renders a human readable description of the plan
+func DescribeApplyPlan(plan *ApplyPlan) string {
+	buf := strings.Builder{}
+	buf.WriteString("Deployment items between barriers are applied in parallel.\n\n")
+	for i, s := range plan.Steps {
+		buf.WriteString(fmt.Sprintf("%d. ", i+1))
+		switch s.Type {
+		case ApplyPlanStepDeploymentItem:
+			mode := "upgrade"
+			if s.InitialDeploy {
+				mode = "initial deploy"
+			}
+			buf.WriteString(fmt.Sprintf("Deployment item %s (%s, %d estimated steps)", s.DeploymentItem, mode, s.EstimatedSteps))
+			if len(s.Operations) == 0 {
+				buf.WriteString(": nothing to apply")
+			}
+		case ApplyPlanStepBarrier:
+			buf.WriteString("Barrier: waiting for all previous deployment items to finish")
+			if s.Message != "" {
+				buf.WriteString(fmt.Sprintf(" (%s)", s.Message))
+			}
+		case ApplyPlanStepPrune:
+			buf.WriteString(fmt.Sprintf("Prune (%d estimated steps)", s.EstimatedSteps))
+		}
+		buf.WriteString("\n")
+
+		for _, op := range s.Operations {
+			buf.WriteString(fmt.Sprintf("   "+applyPlanOperationTitles[op.Type]+":\n", op.Count))
+			for _, ref := range op.Objects {
+				buf.WriteString(fmt.Sprintf("     %s\n", ref.String()))
+			}
+		}
+	}
+	if len(plan.Steps) == 0 {
+		buf.WriteString("Nothing to do.\n")
+	}
+	buf.WriteString(fmt.Sprintf("\nEstimated steps: %d\n", plan.EstimatedSteps))
+	return buf.String()
+}
+
+func sortedRefs(m map[k8s2.ObjectRef]bool) []k8s2.ObjectRef {
+	var ret []k8s2.ObjectRef
+	for ref := range m {
+		ret = append(ret, ref)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
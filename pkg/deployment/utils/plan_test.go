@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeApplyPlan(t *testing.T) {
+	cm := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm", Namespace: "ns"}
+	job := k8s2.ObjectRef{Group: "batch", Version: "v1", Kind: "Job", Name: "migrate", Namespace: "ns"}
+	orphan := k8s2.ObjectRef{Version: "v1", Kind: "Secret", Name: "old", Namespace: "ns"}
+
+	plan := &ApplyPlan{
+		Steps: []ApplyPlanStep{
+			{
+				Type:           ApplyPlanStepDeploymentItem,
+				DeploymentItem: "app",
+				InitialDeploy:  true,
+				Operations: []ApplyPlanOperation{
+					{Type: ApplyPlanOperationPreDeployHooks, Count: 1, Objects: []k8s2.ObjectRef{job}},
+					{Type: ApplyPlanOperationApply, Count: 1, Objects: []k8s2.ObjectRef{cm}},
+				},
+				EstimatedSteps: 3,
+			},
+			{Type: ApplyPlanStepBarrier, Message: "app is ready", EstimatedSteps: 1},
+			{Type: ApplyPlanStepDeploymentItem, DeploymentItem: "empty", EstimatedSteps: 1},
+		},
+		EstimatedSteps: 5,
+	}
+	plan.AddPruneStep(nil)
+	assert.Len(t, plan.Steps, 3)
+	plan.AddPruneStep([]k8s2.ObjectRef{orphan})
+	assert.Len(t, plan.Steps, 4)
+	assert.Equal(t, 6, plan.EstimatedSteps)
+
+	expected := `Deployment items between barriers are applied in parallel.
+
+1. Deployment item app (initial deploy, 3 estimated steps)
+   Run 1 pre-deploy hooks:
+     ns/Job/migrate
+   Apply 1 objects:
+     ns/ConfigMap/cm
+2. Barrier: waiting for all previous deployment items to finish (app is ready)
+3. Deployment item empty (upgrade, 1 estimated steps): nothing to apply
+4. Prune (1 estimated steps)
+   Delete 1 orphan objects:
+     ns/Secret/old
+
+Estimated steps: 6
+`
+	assert.Equal(t, expected, DescribeApplyPlan(plan))
+}
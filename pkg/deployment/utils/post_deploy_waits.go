@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	types2 "github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/validation"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ResolveObjectRefItem resolves the group/kind of the given item to all matching object refs
+func (a *ApplyUtil) ResolveObjectRefItem(x types2.ObjectRefItem) []k8s2.ObjectRef {
+	m := map[k8s2.ObjectRef]bool{}
+	a.convertObjectRef(x, m)
+	var refs []k8s2.ObjectRef
+	for ref := range m {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].String() < refs[j].String()
+	})
+	return refs
+}
+
+// runPostDeployWaits waits for all postDeployWaits of the deployment item to be fulfilled and returns the refs of all
+// objects that were waited for
+func (a *ApplyUtil) runPostDeployWaits(d *deployment.DeploymentItem) []k8s2.ObjectRef {
+	if a.o.DryRun || a.o.NoWait {
+		return nil
+	}
+
+	var refs []k8s2.ObjectRef
+	for i := range d.Config.PostDeployWaits {
+		c := &d.Config.PostDeployWaits[i]
+		for _, ref := range a.ResolveObjectRefItem(c.ObjectRefItem) {
+			if a.abortSignal.Load().(bool) {
+				return refs
+			}
+			refs = append(refs, ref)
+			a.waitPostDeploy(ref, c)
+		}
+	}
+	return refs
+}
+
+// waitPostDeploy polls the given object until the post-deploy wait is fulfilled or the timeout is reached. The wait is
+// rendered as its own status line, showing the elapsed time while waiting and the observed values when done.
+func (a *ApplyUtil) waitPostDeploy(ref k8s2.ObjectRef, c *types2.PostDeployWaitConfig) bool {
+	timeout := a.o.ReadinessTimeout
+	if c.Timeout != nil {
+		timeout = c.Timeout.Duration
+	}
+	timeoutTimer := time.NewTimer(timeout)
+	defer timeoutTimer.Stop()
+
+	s := status.Startf(a.ctx, "Waiting for post-deploy conditions of %s", ref.String())
+	defer s.Failed()
+
+	startTime := time.Now()
+	for true {
+		elapsed := int(time.Now().Sub(startTime).Seconds())
+
+		o, apiWarnings, err := a.k.GetSingleObject(ref)
+		a.handleApiWarnings(ref, apiWarnings)
+		if err != nil && !errors.IsNotFound(err) {
+			s.FailedWithMessagef("Failed to get %s: %s (%ds elapsed)", ref.String(), err.Error(), elapsed)
+			a.HandleError(ref, err)
+			return false
+		}
+
+		r, err := validation.EvaluatePostDeployWait(a.ctx, o, c)
+		if err != nil {
+			s.FailedWithMessagef("Invalid post-deploy wait for %s: %s", ref.String(), err.Error())
+			a.HandleError(ref, fmt.Errorf("invalid post-deploy wait: %w", err))
+			return false
+		}
+		if r.Fulfilled {
+			s.UpdateAndInfoFallbackf("Finished waiting for post-deploy conditions of %s (%ds elapsed): %s", ref.String(), elapsed, r.ObservedString())
+			s.Success()
+			return true
+		}
+		s.Updatef("Waiting for post-deploy conditions of %s: %s (%ds elapsed)", ref.String(), r.Reason, elapsed)
+
+		var cancelErr error
+		select {
+		case <-time.After(500 * time.Millisecond):
+			continue
+		case <-timeoutTimer.C:
+			cancelErr = fmt.Errorf("timed out while waiting for post-deploy conditions of %s: %s (observed: %s)", ref.String(), r.Reason, r.ObservedString())
+		case <-a.ctx.Done():
+			cancelErr = fmt.Errorf("context cancelled while waiting for post-deploy conditions of %s", ref.String())
+		}
+
+		s.UpdateAndInfoFallbackf("%s (%ds elapsed)", cancelErr.Error(), elapsed)
+		if c.Severity == types2.PostDeployWaitSeverityWarning && a.ctx.Err() == nil {
+			s.Warning()
+			a.HandleWarning(ref, cancelErr)
+		} else {
+			s.Failed()
+			a.HandleError(ref, cancelErr)
+		}
+		return false
+	}
+	return false
+}
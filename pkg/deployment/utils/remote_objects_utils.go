@@ -12,9 +12,34 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
 	"sync"
+	"time"
 )
 
+// remoteObjectsConcurrency bounds the number of parallel list/get requests
+const remoteObjectsConcurrency = 16
+
+// bulkListThreshold is the number of missing objects in the same group/kind and namespace at which a single LIST
+// request is performed instead of individual GET requests
+const bulkListThreshold = 5
+
+// RemoteObjectsStats contains statistics about how remote objects were retrieved. Discriminator lists and bulk lists
+// are the fast path, while gets are the slow path of retrieving each object individually.
+type RemoteObjectsStats struct {
+	DiscriminatorLists    int
+	DiscriminatorObjects  int
+	DiscriminatorDuration time.Duration
+
+	BulkLists        int
+	BulkListObjects  int
+	BulkListDuration time.Duration
+
+	Gets        int
+	GetObjects  int
+	GetDuration time.Duration
+}
+
 type RemoteObjectUtils struct {
 	ctx           context.Context
 	dew           *DeploymentErrorsAndWarnings
@@ -22,6 +47,8 @@ type RemoteObjectUtils struct {
 
 	remoteNamespacesOk bool
 	remoteNamespaces   map[string]*uo.UnstructuredObject
+
+	stats RemoteObjectsStats
 }
 
 func NewRemoteObjectsUtil(ctx context.Context, dew *DeploymentErrorsAndWarnings) *RemoteObjectUtils {
@@ -70,7 +97,12 @@ func (u *RemoteObjectUtils) getAllByDiscriminator(k *k8s.K8sCluster, discriminat
 		return err
 	}
 
-	g := utils.NewGoHelper(u.ctx, 0)
+	startTime := time.Now()
+	defer func() {
+		u.stats.DiscriminatorDuration += time.Since(startTime)
+	}()
+
+	g := utils.NewGoHelper(u.ctx, remoteObjectsConcurrency)
 	for _, ar := range ars {
 		ar := ar
 		gvk := schema.GroupVersionKind{
@@ -85,6 +117,7 @@ func (u *RemoteObjectUtils) getAllByDiscriminator(k *k8s.K8sCluster, discriminat
 			}
 			mutex.Lock()
 			defer mutex.Unlock()
+			u.stats.DiscriminatorLists++
 			if errors2.IsNotFound(err) {
 				// ignore this error
 				return
@@ -95,6 +128,8 @@ func (u *RemoteObjectUtils) getAllByDiscriminator(k *k8s.K8sCluster, discriminat
 				// fall back to ListObjects per namespace
 				for ns, _ := range usedNamespaces {
 					l2, _, err2 := k.ListObjects(gvk, ns, labels)
+					u.stats.DiscriminatorLists++
+					u.stats.DiscriminatorObjects += len(l2)
 					if err2 == nil && len(l2) != 0 {
 						u.dew.AddWarning(k8s2.ObjectRef{}, fmt.Errorf("listing objects by discriminator on global level failed due to permission errors, so Kluctl reverted to listing on namespace level. "+
 							"This is not realiable and might end up missing detection for some orphan object"))
@@ -112,6 +147,7 @@ func (u *RemoteObjectUtils) getAllByDiscriminator(k *k8s.K8sCluster, discriminat
 				}, err)
 			} else {
 				// no error
+				u.stats.DiscriminatorObjects += len(l)
 				for _, o := range l {
 					u.remoteObjects[o.GetK8sRef()] = o
 				}
@@ -133,34 +169,134 @@ func (u *RemoteObjectUtils) getAllByDiscriminator(k *k8s.K8sCluster, discriminat
 	return g.ErrorOrNil()
 }
 
+type bulkListKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+}
+
+// groupMissingRefs groups the given refs by group/kind and namespace. Groups with at least threshold refs are returned
+// as bulk groups, which are retrieved via a single LIST request. All other refs are returned as single refs, which are
+// retrieved via individual GET requests.
+func groupMissingRefs(refs []k8s2.ObjectRef, threshold int) (map[bulkListKey][]k8s2.ObjectRef, []k8s2.ObjectRef) {
+	groups := map[bulkListKey][]k8s2.ObjectRef{}
+	for _, ref := range refs {
+		key := bulkListKey{gvk: ref.GroupVersionKind(), namespace: ref.Namespace}
+		groups[key] = append(groups[key], ref)
+	}
+
+	bulk := map[bulkListKey][]k8s2.ObjectRef{}
+	var single []k8s2.ObjectRef
+	for key, l := range groups {
+		if threshold > 0 && len(l) >= threshold {
+			bulk[key] = l
+		} else {
+			single = append(single, l...)
+		}
+	}
+	sort.Slice(single, func(i, j int) bool {
+		return single[i].Less(single[j])
+	})
+	return bulk, single
+}
+
+// bulkListMissingObjects retrieves the given groups of refs with one LIST request per group. It returns all refs that
+// could not be retrieved this way (e.g. because listing is forbidden) and must be retrieved via GET requests.
+func (u *RemoteObjectUtils) bulkListMissingObjects(k *k8s.K8sCluster, groups map[bulkListKey][]k8s2.ObjectRef) []k8s2.ObjectRef {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	startTime := time.Now()
+	defer func() {
+		u.stats.BulkListDuration += time.Since(startTime)
+	}()
+
+	var mutex sync.Mutex
+	var fallback []k8s2.ObjectRef
+
+	g := utils.NewGoHelper(u.ctx, remoteObjectsConcurrency)
+	for key, refs := range groups {
+		key := key
+		refs := refs
+		g.Run(func() {
+			l, apiWarnings, err := k.ListObjects(key.gvk, key.namespace, nil)
+			for _, w := range apiWarnings {
+				status.Tracef(u.ctx, "API warning while listing %s: code=%d, agent=%s, text=%s", key.gvk.String(), w.Code, w.Agent, w.Text)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			u.stats.BulkLists++
+			if errors2.IsNotFound(err) || meta.IsNoMatchError(err) {
+				// none of the objects exist
+				return
+			} else if err != nil {
+				status.Tracef(u.ctx, "Listing %s in namespace '%s' failed, falling back to get requests: %s", key.gvk.String(), key.namespace, err.Error())
+				fallback = append(fallback, refs...)
+				return
+			}
+
+			wanted := map[k8s2.ObjectRef]bool{}
+			for _, ref := range refs {
+				wanted[ref] = true
+			}
+			for _, o := range l {
+				ref := o.GetK8sRef()
+				if wanted[ref] {
+					u.remoteObjects[ref] = o
+					u.stats.BulkListObjects++
+				}
+			}
+		})
+	}
+	g.Wait()
+
+	return fallback
+}
+
 func (u *RemoteObjectUtils) getMissingObjects(k *k8s.K8sCluster, refs []k8s2.ObjectRef) error {
 	notFoundRefsMap := make(map[k8s2.ObjectRef]bool)
+	var notFoundRefs []k8s2.ObjectRef
 	for _, ref := range refs {
 		if _, ok := u.remoteObjects[ref]; !ok {
 			if _, ok = notFoundRefsMap[ref]; !ok {
 				notFoundRefsMap[ref] = true
+				notFoundRefs = append(notFoundRefs, ref)
 			}
 		}
 	}
 
 	var mutex sync.Mutex
-	if len(notFoundRefsMap) == 0 {
+	if len(notFoundRefs) == 0 {
 		return nil
 	}
 
 	errCount := 0
 	permissionErrCount := 0
 
-	baseStatus := fmt.Sprintf("Getting %d additional remote objects", len(notFoundRefsMap))
+	baseStatus := fmt.Sprintf("Getting %d additional remote objects", len(notFoundRefs))
 	s := status.Start(u.ctx, baseStatus)
 	defer s.Failed()
 
-	g := utils.NewGoHelper(u.ctx, 0)
-	for ref, _ := range notFoundRefsMap {
+	// objects that do not carry the discriminator (e.g. objects that are about to be adopted) or targets without a
+	// discriminator would otherwise require one GET per object, which is very slow on high-latency clusters
+	bulk, single := groupMissingRefs(notFoundRefs, bulkListThreshold)
+	single = append(single, u.bulkListMissingObjects(k, bulk)...)
+
+	startTime := time.Now()
+	defer func() {
+		u.stats.GetDuration += time.Since(startTime)
+	}()
+
+	g := utils.NewGoHelper(u.ctx, remoteObjectsConcurrency)
+	for _, ref := range single {
 		ref := ref
 		g.Run(func() {
 			r, apiWarnings, err := k.GetSingleObject(ref)
 			u.dew.AddApiWarnings(ref, apiWarnings)
+			mutex.Lock()
+			u.stats.Gets++
+			mutex.Unlock()
 			if err != nil {
 				if errors2.IsNotFound(err) || meta.IsNoMatchError(err) {
 					return
@@ -172,11 +308,14 @@ func (u *RemoteObjectUtils) getMissingObjects(k *k8s.K8sCluster, refs []k8s2.Obj
 					return
 				}
 				u.dew.AddError(ref, err)
+				mutex.Lock()
 				errCount += 1
+				mutex.Unlock()
 				return
 			}
 			mutex.Lock()
 			defer mutex.Unlock()
+			u.stats.GetObjects++
 			u.remoteObjects[r.GetK8sRef()] = r
 			return
 		})
@@ -232,6 +371,12 @@ func (u *RemoteObjectUtils) UpdateRemoteObjects(k *k8s.K8sCluster, discriminator
 		return err
 	}
 
+	st := u.stats
+	status.Tracef(u.ctx, "Retrieved remote objects: %d via %d discriminator lists (%s), %d via %d bulk lists (%s), %d via %d gets (%s)",
+		st.DiscriminatorObjects, st.DiscriminatorLists, st.DiscriminatorDuration.Round(time.Millisecond),
+		st.BulkListObjects, st.BulkLists, st.BulkListDuration.Round(time.Millisecond),
+		st.GetObjects, st.Gets, st.GetDuration.Round(time.Millisecond))
+
 	s := status.Start(u.ctx, "Getting namespaces")
 	defer s.Failed()
 
@@ -258,6 +403,18 @@ func (u *RemoteObjectUtils) UpdateRemoteObjects(k *k8s.K8sCluster, discriminator
 	return nil
 }
 
+// GetStats returns statistics about how remote objects were retrieved so far
+func (u *RemoteObjectUtils) GetStats() RemoteObjectsStats {
+	return u.stats
+}
+
+// AddRemoteObjects adds objects as if they were retrieved from the cluster, e.g. to diff against a fake cluster state
+func (u *RemoteObjectUtils) AddRemoteObjects(objects []*uo.UnstructuredObject) {
+	for _, o := range objects {
+		u.remoteObjects[o.GetK8sRef()] = o
+	}
+}
+
 func (u *RemoteObjectUtils) GetRemoteObject(ref k8s2.ObjectRef) *uo.UnstructuredObject {
 	return u.remoteObjects[ref]
 }
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupMissingRefs(t *testing.T) {
+	var refs []k8s2.ObjectRef
+	for i := 0; i < 3; i++ {
+		refs = append(refs, k8s2.NewObjectRef("apps", "v1", "Deployment", fmt.Sprintf("d%d", i), "ns1"))
+	}
+	refs = append(refs, k8s2.NewObjectRef("apps", "v1", "Deployment", "d0", "ns2"))
+	refs = append(refs, k8s2.NewObjectRef("", "v1", "ConfigMap", "cm", "ns1"))
+	refs = append(refs, k8s2.NewObjectRef("", "v1", "Namespace", "ns1", ""))
+
+	bulk, single := groupMissingRefs(refs, 3)
+	assert.Len(t, bulk, 1)
+	assert.Equal(t, refs[:3], bulk[bulkListKey{gvk: refs[0].GroupVersionKind(), namespace: "ns1"}])
+	assert.ElementsMatch(t, refs[3:], single)
+
+	// without a threshold, everything is retrieved via gets
+	bulk, single = groupMissingRefs(refs, 0)
+	assert.Empty(t, bulk)
+	assert.ElementsMatch(t, refs, single)
+}
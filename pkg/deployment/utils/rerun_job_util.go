@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+	"time"
+)
+
+const (
+	rerunJobAnnotation        = "kluctl.io/rerun-job"
+	rerunJobWaitAnnotation    = "kluctl.io/rerun-job-wait"
+	rerunJobTimeoutAnnotation = "kluctl.io/rerun-job-timeout"
+	rerunJobHashAnnotation    = "kluctl.io/rerun-job-hash"
+)
+
+var jobGk = schema.GroupKind{Group: "batch", Kind: "Job"}
+
+type rerunJob struct {
+	wait    bool
+	timeout time.Duration
+}
+
+// getRerunJob returns the re-run configuration of the given object or nil if the object is not a Job or is not
+// annotated with kluctl.io/rerun-job. CronJobs are intentionally not handled here, as they can be patched normally.
+func getRerunJob(o *uo.UnstructuredObject) (*rerunJob, error) {
+	ref := o.GetK8sRef()
+	if ref.GroupKind() != jobGk {
+		return nil, nil
+	}
+	mode := o.GetK8sAnnotation(rerunJobAnnotation)
+	if mode == nil {
+		return nil, nil
+	}
+
+	var ret rerunJob
+	switch *mode {
+	case "true", "on-change":
+	case "false":
+		return nil, nil
+	case "always":
+		// re-running Jobs on every deployment would require generation-suffixed names and a cleanup of older
+		// generations, which is not supported
+		return nil, fmt.Errorf("%s value 'always' is not supported, use 'on-change' and change the Job spec (e.g. an annotation of the pod template) to re-run the Job", rerunJobAnnotation)
+	default:
+		return nil, fmt.Errorf("unsupported %s value '%s'", rerunJobAnnotation, *mode)
+	}
+
+	var err error
+	ret.wait, err = o.GetK8sAnnotationBool(rerunJobWaitAnnotation, false)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutStr := o.GetK8sAnnotation(rerunJobTimeoutAnnotation)
+	if timeoutStr != nil {
+		ret.timeout, err = time.ParseDuration(*timeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse duration: %w", err)
+		}
+	}
+	return &ret, nil
+}
+
+func buildJobSpecHash(o *uo.UnstructuredObject) (string, error) {
+	spec, _, err := o.GetNestedField("spec")
+	if err != nil {
+		return "", err
+	}
+	b, err := yaml.WriteJsonString(spec)
+	if err != nil {
+		return "", err
+	}
+	return utils.Sha256String(b), nil
+}
+
+// applyRerunJob applies a Job that is annotated with kluctl.io/rerun-job. As most fields of a Job are immutable, it is
+// deleted and re-created when its spec changed.
+func (a *ApplyUtil) applyRerunJob(d *deployment.DeploymentItem, o *uo.UnstructuredObject, rj *rerunJob) {
+	ref := o.GetK8sRef()
+
+	hash, err := buildJobSpecHash(o)
+	if err != nil {
+		a.HandleError(ref, err)
+		return
+	}
+	o = o.Clone()
+	o.SetK8sAnnotation(rerunJobHashAnnotation, hash)
+
+	reason := ""
+	remote := a.ru.GetRemoteObject(ref)
+	if remote == nil {
+		reason = "created"
+	} else if h := remote.GetK8sAnnotation(rerunJobHashAnnotation); h == nil || *h != hash {
+		reason = "changed"
+	}
+
+	if reason == "" {
+		// spec did not change, so we can safely patch metadata (e.g. labels) without re-running the Job
+		a.ApplyObject(d, o, false, false)
+		return
+	}
+
+	replaced := false
+	if remote != nil {
+		a.sctx.InfoFallbackf("Re-creating job %s (reason: %s)", ref.String(), reason)
+		apiWarnings, err := a.k.DeleteSingleObject(ref, k8s.DeleteOptions{
+			ForceDryRun:         a.o.DryRun,
+			IgnoreNotFoundError: true,
+		})
+		a.handleApiWarnings(ref, apiWarnings)
+		if err != nil {
+			a.HandleError(ref, err)
+			return
+		}
+		replaced = true
+	}
+
+	a.ApplyObject(d, o, replaced, false)
+
+	rr := &result.RerunJob{
+		Ref:    ref,
+		Reason: reason,
+	}
+	if a.HadError(ref) {
+		rr.Status = result.RerunJobStatusFailed
+	} else if a.o.DryRun {
+		rr.Status = result.RerunJobStatusPending
+	} else if !rj.wait || a.o.NoWait {
+		rr.Status = result.RerunJobStatusStarted
+	} else if a.WaitReadiness(ref, rj.timeout, false) {
+		rr.Status = result.RerunJobStatusCompleted
+	} else {
+		rr.Status = result.RerunJobStatusFailed
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.rerunJobs[ref] = rr
+}
+
+func (ad *ApplyDeploymentsUtil) GetRerunJobs() []result.RerunJob {
+	ad.resultsMutex.Lock()
+	defer ad.resultsMutex.Unlock()
+
+	var ret []result.RerunJob
+	for _, a := range ad.results {
+		for _, rr := range a.rerunJobs {
+			ret = append(ret, *rr)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Ref.Less(ret[j].Ref)
+	})
+	return ret
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRerunJobTestObject(kind string, annotations map[string]string) *uo.UnstructuredObject {
+	o := uo.New()
+	o.SetK8sGVKs("batch", "v1", kind)
+	o.SetK8sName("job")
+	o.SetK8sNamespace("ns")
+	for k, v := range annotations {
+		o.SetK8sAnnotation(k, v)
+	}
+	return o
+}
+
+func TestGetRerunJob(t *testing.T) {
+	rj, err := getRerunJob(newRerunJobTestObject("Job", nil))
+	assert.NoError(t, err)
+	assert.Nil(t, rj)
+
+	// CronJobs are never re-created
+	rj, err = getRerunJob(newRerunJobTestObject("CronJob", map[string]string{rerunJobAnnotation: "true"}))
+	assert.NoError(t, err)
+	assert.Nil(t, rj)
+
+	rj, err = getRerunJob(newRerunJobTestObject("Job", map[string]string{rerunJobAnnotation: "false"}))
+	assert.NoError(t, err)
+	assert.Nil(t, rj)
+
+	for _, mode := range []string{"true", "on-change"} {
+		rj, err = getRerunJob(newRerunJobTestObject("Job", map[string]string{rerunJobAnnotation: mode}))
+		assert.NoError(t, err)
+		assert.Equal(t, &rerunJob{}, rj)
+	}
+
+	rj, err = getRerunJob(newRerunJobTestObject("Job", map[string]string{
+		rerunJobAnnotation:        "on-change",
+		rerunJobWaitAnnotation:    "true",
+		rerunJobTimeoutAnnotation: "5m",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, &rerunJob{wait: true, timeout: 5 * time.Minute}, rj)
+
+	_, err = getRerunJob(newRerunJobTestObject("Job", map[string]string{rerunJobAnnotation: "always"}))
+	assert.EqualError(t, err, "kluctl.io/rerun-job value 'always' is not supported, use 'on-change' and change the Job spec (e.g. an annotation of the pod template) to re-run the Job")
+
+	_, err = getRerunJob(newRerunJobTestObject("Job", map[string]string{rerunJobAnnotation: "invalid"}))
+	assert.EqualError(t, err, "unsupported kluctl.io/rerun-job value 'invalid'")
+
+	_, err = getRerunJob(newRerunJobTestObject("Job", map[string]string{
+		rerunJobAnnotation:        "on-change",
+		rerunJobTimeoutAnnotation: "invalid",
+	}))
+	assert.ErrorContains(t, err, "failed to parse duration")
+}
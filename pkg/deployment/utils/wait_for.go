@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/lib/yaml"
+	types2 "github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/validation"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var waitForAnnotationRegex = regexp.MustCompile(`^kluctl.io/wait-for(-\d*)?$`)
+
+// WaitForConfig is the value of a kluctl.io/wait-for annotation. Unlike postDeployWaits, fields, conditions and
+// dnsLookups are optional.
+type WaitForConfig struct {
+	types2.ObjectRefItem
+
+	Fields     []string                               `json:"fields,omitempty"`
+	Conditions []string                               `json:"conditions,omitempty"`
+	DnsLookups []types2.PostDeployWaitDnsLookupConfig `json:"dnsLookups,omitempty"`
+
+	Timeout  *metav1.Duration              `json:"timeout,omitempty"`
+	Severity types2.PostDeployWaitSeverity `json:"severity,omitempty" validate:"omitempty,oneof=error warning"`
+}
+
+// WaitReadiness returns true if the referenced object must become ready, as no other checks are specified
+func (c *WaitForConfig) WaitReadiness() bool {
+	return len(c.Fields)+len(c.Conditions)+len(c.DnsLookups) == 0
+}
+
+func (c *WaitForConfig) toPostDeployWaitConfig() *types2.PostDeployWaitConfig {
+	return &types2.PostDeployWaitConfig{
+		ObjectRefItem: c.ObjectRefItem,
+		Fields:        c.Fields,
+		Conditions:    c.Conditions,
+		DnsLookups:    c.DnsLookups,
+		Timeout:       c.Timeout,
+		Severity:      c.Severity,
+	}
+}
+
+// ParseWaitForAnnotations parses all kluctl.io/wait-for annotations of the given object. The namespace of each wait
+// defaults to the namespace of the given object.
+func ParseWaitForAnnotations(o *uo.UnstructuredObject) ([]WaitForConfig, error) {
+	anns := o.GetK8sAnnotationsWithRegex(waitForAnnotationRegex)
+	var keys []string
+	for k := range anns {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var ret []WaitForConfig
+	for _, k := range keys {
+		var c WaitForConfig
+		err := yaml.ReadYamlString(anns[k], &c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", k, err)
+		}
+		if c.Kind == nil {
+			return nil, fmt.Errorf("invalid %s annotation: kind must be set", k)
+		}
+		if c.Namespace == "" {
+			c.Namespace = o.GetK8sNamespace()
+		}
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
+
+// waitForDependencies holds back the given object until all its kluctl.io/wait-for annotations are fulfilled. In
+// dry-run mode, the dependencies are only noted. Returns false if the object must not be applied.
+func (a *ApplyUtil) waitForDependencies(o *uo.UnstructuredObject) bool {
+	ref := o.GetK8sRef()
+	waits, err := ParseWaitForAnnotations(o)
+	if err != nil {
+		a.HandleError(ref, err)
+		return false
+	}
+
+	for i := range waits {
+		c := &waits[i]
+		for _, depRef := range a.ResolveObjectRefItem(c.ObjectRefItem) {
+			if depRef.Namespace != "" {
+				// the namespace defaults to the namespace of the annotated object, which is wrong for cluster scoped objects
+				if namespaced := a.k.IsNamespaced(depRef.GroupVersionKind()); namespaced != nil && !*namespaced {
+					depRef.Namespace = ""
+				}
+			}
+			if a.o.DryRun {
+				status.Infof(a.ctx, "%s waits for %s before being applied", ref.String(), depRef.String())
+				continue
+			}
+			if a.abortSignal.Load().(bool) {
+				return false
+			}
+			if !a.waitFor(ref, depRef, c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (a *ApplyUtil) waitFor(ref k8s2.ObjectRef, depRef k8s2.ObjectRef, c *WaitForConfig) bool {
+	timeout := a.o.ReadinessTimeout
+	if c.Timeout != nil {
+		timeout = c.Timeout.Duration
+	}
+	timeoutTimer := time.NewTimer(timeout)
+	defer timeoutTimer.Stop()
+
+	s := status.Startf(a.ctx, "Waiting for %s before applying %s", depRef.String(), ref.String())
+	defer s.Failed()
+
+	startTime := time.Now()
+	for true {
+		elapsed := int(time.Now().Sub(startTime).Seconds())
+
+		o, apiWarnings, err := a.k.GetSingleObject(depRef)
+		a.handleApiWarnings(depRef, apiWarnings)
+		if err != nil && !errors.IsNotFound(err) {
+			s.FailedWithMessagef("Failed to get %s: %s (%ds elapsed)", depRef.String(), err.Error(), elapsed)
+			a.HandleError(ref, err)
+			return false
+		}
+
+		var fulfilled bool
+		var reason string
+		if c.WaitReadiness() {
+			if o == nil {
+				reason = "object does not exist"
+			} else {
+				v := validation.ValidateObject(a.ctx, a.k, o, false, false)
+				fulfilled = v.Ready
+				reason = "object is not ready"
+			}
+		} else {
+			r, err := validation.EvaluatePostDeployWait(a.ctx, o, c.toPostDeployWaitConfig())
+			if err != nil {
+				s.FailedWithMessagef("Invalid wait for %s: %s", depRef.String(), err.Error())
+				a.HandleError(ref, fmt.Errorf("invalid kluctl.io/wait-for annotation: %w", err))
+				return false
+			}
+			fulfilled = r.Fulfilled
+			reason = r.Reason
+		}
+		if fulfilled {
+			s.UpdateAndInfoFallbackf("Finished waiting for %s before applying %s (%ds elapsed)", depRef.String(), ref.String(), elapsed)
+			s.Success()
+			return true
+		}
+		s.Updatef("Waiting for %s before applying %s: %s (%ds elapsed)", depRef.String(), ref.String(), reason, elapsed)
+
+		var cancelErr error
+		select {
+		case <-time.After(500 * time.Millisecond):
+			continue
+		case <-timeoutTimer.C:
+			cancelErr = fmt.Errorf("timed out after %s while waiting for %s before applying %s: %s", timeout.String(), depRef.String(), ref.String(), reason)
+		case <-a.ctx.Done():
+			cancelErr = fmt.Errorf("context cancelled while waiting for %s before applying %s", depRef.String(), ref.String())
+		}
+
+		s.UpdateAndInfoFallbackf("%s (%ds elapsed)", cancelErr.Error(), elapsed)
+		if c.Severity == types2.PostDeployWaitSeverityWarning && a.ctx.Err() == nil {
+			s.Warning()
+			a.HandleWarning(ref, cancelErr)
+			return true
+		}
+		s.Failed()
+		a.HandleError(ref, cancelErr)
+		return false
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWaitForAnnotations(t *testing.T) {
+	o := uo.New()
+	o.SetK8sGVKs("example.com", "v1", "MyResource")
+	o.SetK8sNamespace("ns")
+	o.SetK8sName("cr")
+	o.SetK8sAnnotation("kluctl.io/wait-for", `{"kind": "Endpoints", "name": "operator-webhook"}`)
+	o.SetK8sAnnotation("kluctl.io/wait-for-2", `
+group: apps
+kind: Deployment
+name: operator
+namespace: operator
+conditions: [Available]
+timeout: 1m
+`)
+
+	waits, err := ParseWaitForAnnotations(o)
+	assert.NoError(t, err)
+	assert.Len(t, waits, 2)
+
+	assert.Equal(t, "Endpoints", *waits[0].Kind)
+	assert.Equal(t, "operator-webhook", waits[0].Name)
+	assert.Equal(t, "ns", waits[0].Namespace)
+	assert.Empty(t, waits[0].Conditions)
+
+	assert.Equal(t, "apps", *waits[1].Group)
+	assert.Equal(t, "operator", waits[1].Namespace)
+	assert.Equal(t, []string{"Available"}, waits[1].Conditions)
+	assert.Equal(t, time.Minute, waits[1].Timeout.Duration)
+
+	o.SetK8sAnnotation("kluctl.io/wait-for", `{"name": "operator-webhook"}`)
+	_, err = ParseWaitForAnnotations(o)
+	assert.ErrorContains(t, err, "kind must be set")
+
+	o.SetK8sAnnotation("kluctl.io/wait-for", `{"kind": "Endpoints", "name": "x", "unknown": true}`)
+	_, err = ParseWaitForAnnotations(o)
+	assert.ErrorContains(t, err, "invalid kluctl.io/wait-for annotation")
+}
@@ -0,0 +1,195 @@
+// Package deprecations contains the registry of deprecated kluctl project features.
+//
+// Deprecated features are reported via Report, which prints a warning (once per feature) and records the usage in the
+// Collector found in the context. Recorded usages end up as structured warnings in command results and can be turned
+// into errors via the 'deprecations' field in .kluctl.yaml.
+package deprecations
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	CmOrSecretTargetPath     = "cm-or-secret-target-path"
+	LatestVersionFilter      = "latest-version-filter"
+	HelmReleaseCredentialsId = "helm-release-credentials-id"
+)
+
+// Deprecation describes a deprecated project feature
+type Deprecation struct {
+	Id string
+	// Message describes what exactly is deprecated
+	Message string
+	// Replacement describes what to use instead. It is empty if the feature is removed without replacement.
+	Replacement string
+	// RemovedIn is the kluctl version in which the feature stops working
+	RemovedIn string
+}
+
+var registry = map[string]Deprecation{
+	CmOrSecretTargetPath: {
+		Id:          CmOrSecretTargetPath,
+		Message:     "'targetPath' in clusterConfigMap and clusterSecret vars sources is deprecated",
+		Replacement: "the common 'targetPath' property of the vars source",
+		RemovedIn:   "v3.0.0",
+	},
+	LatestVersionFilter: {
+		Id:        LatestVersionFilter,
+		Message:   "latest_version is deprecated when using images.get_image() and is completely ignored",
+		RemovedIn: "v3.0.0",
+	},
+	HelmReleaseCredentialsId: {
+		Id:          HelmReleaseCredentialsId,
+		Message:     "'credentialsId' in helm-chart.yaml is deprecated",
+		Replacement: "credentials matched by host and path, e.g. via --helm-username=<host>/<path>=<username>",
+		RemovedIn:   "v3.0.0",
+	},
+}
+
+// String returns a human-readable description of the deprecation, including the replacement and the version in
+// which the feature stops working
+func (d Deprecation) String() string {
+	s := d.Message
+	if d.Replacement != "" {
+		s += fmt.Sprintf(", use %s instead", d.Replacement)
+	} else {
+		s += ", please remove all usages"
+	}
+	return s + fmt.Sprintf(". It will stop working in kluctl %s", d.RemovedIn)
+}
+
+// Get returns the registered deprecation with the given id
+func Get(id string) (Deprecation, bool) {
+	d, ok := registry[id]
+	return d, ok
+}
+
+// List returns all registered deprecations, sorted by id
+func List() []Deprecation {
+	ret := make([]Deprecation, 0, len(registry))
+	for _, d := range registry {
+		ret = append(ret, d)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Id < ret[j].Id
+	})
+	return ret
+}
+
+// ValidateIds returns an error if any of the given ids is not a registered deprecation
+func ValidateIds(ids []string) error {
+	var unknown []string
+	for _, id := range ids {
+		if _, ok := registry[id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) != 0 {
+		var known []string
+		for _, d := range List() {
+			known = append(known, d.Id)
+		}
+		return fmt.Errorf("unknown deprecation ids %s, must be one of %s", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// Collector records the deprecated features that were used while loading and rendering a project
+type Collector struct {
+	used  map[string]bool
+	mutex sync.Mutex
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		used: map[string]bool{},
+	}
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, c *Collector) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+func FromContext(ctx context.Context) *Collector {
+	v := ctx.Value(contextKey{})
+	if v == nil {
+		return nil
+	}
+	return v.(*Collector)
+}
+
+// Report prints a warning about the usage of the given deprecated feature and records the usage in the collector of
+// the context, if there is one
+func Report(ctx context.Context, id string) {
+	d, ok := registry[id]
+	if !ok {
+		panic(fmt.Sprintf("unknown deprecation %s", id))
+	}
+	status.Deprecation(ctx, id, d.String())
+
+	c := FromContext(ctx)
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.used[id] = true
+}
+
+// GetUsed returns all deprecations that were used, sorted by id
+func (c *Collector) GetUsed() []Deprecation {
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var ret []Deprecation
+	for _, d := range List() {
+		if c.used[d.Id] {
+			ret = append(ret, d)
+		}
+	}
+	return ret
+}
+
+// GetWarnings returns one structured warning per used deprecation
+func (c *Collector) GetWarnings() []result.DeploymentError {
+	var ret []result.DeploymentError
+	for _, d := range c.GetUsed() {
+		ret = append(ret, result.DeploymentError{
+			Message: d.String(),
+			Deprecation: &result.DeprecationInfo{
+				Id:          d.Id,
+				Replacement: d.Replacement,
+				RemovedIn:   d.RemovedIn,
+			},
+		})
+	}
+	return ret
+}
+
+// CheckErrors returns an error if any of the used deprecations is contained in errorIds
+func (c *Collector) CheckErrors(errorIds []string) error {
+	var msgs []string
+	for _, d := range c.GetUsed() {
+		for _, id := range errorIds {
+			if d.Id == id {
+				msgs = append(msgs, fmt.Sprintf("%s: %s", d.Id, d.String()))
+				break
+			}
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("deprecated features are configured to be treated as errors (see deprecations.errors in .kluctl.yaml): %s", strings.Join(msgs, "; "))
+}
@@ -0,0 +1,59 @@
+package deprecations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportAndCollect(t *testing.T) {
+	c := NewCollector()
+	ctx := NewContext(context.Background(), c)
+
+	Report(ctx, LatestVersionFilter)
+	Report(ctx, CmOrSecretTargetPath)
+	Report(ctx, LatestVersionFilter)
+	// reporting without a collector must not fail
+	Report(context.Background(), HelmReleaseCredentialsId)
+
+	used := c.GetUsed()
+	assert.Len(t, used, 2)
+	assert.Equal(t, CmOrSecretTargetPath, used[0].Id)
+	assert.Equal(t, LatestVersionFilter, used[1].Id)
+
+	w := c.GetWarnings()
+	assert.Len(t, w, 2)
+	assert.Equal(t, CmOrSecretTargetPath, w[0].Deprecation.Id)
+	assert.Equal(t, "v3.0.0", w[0].Deprecation.RemovedIn)
+	assert.NotEmpty(t, w[0].Deprecation.Replacement)
+	assert.Contains(t, w[0].Message, "It will stop working in kluctl v3.0.0")
+	assert.Empty(t, w[1].Deprecation.Replacement)
+	assert.Contains(t, w[1].Message, "please remove all usages")
+
+	assert.Panics(t, func() {
+		Report(ctx, "unknown")
+	})
+}
+
+func TestCheckErrors(t *testing.T) {
+	var nilCollector *Collector
+	assert.NoError(t, nilCollector.CheckErrors([]string{LatestVersionFilter}))
+	assert.Nil(t, nilCollector.GetWarnings())
+
+	c := NewCollector()
+	ctx := NewContext(context.Background(), c)
+	Report(ctx, LatestVersionFilter)
+
+	assert.NoError(t, c.CheckErrors(nil))
+	assert.NoError(t, c.CheckErrors([]string{HelmReleaseCredentialsId}))
+	err := c.CheckErrors([]string{HelmReleaseCredentialsId, LatestVersionFilter})
+	assert.ErrorContains(t, err, LatestVersionFilter)
+	assert.NotContains(t, err.Error(), HelmReleaseCredentialsId)
+}
+
+func TestValidateIds(t *testing.T) {
+	assert.NoError(t, ValidateIds(nil))
+	assert.NoError(t, ValidateIds([]string{LatestVersionFilter, CmOrSecretTargetPath}))
+	assert.ErrorContains(t, ValidateIds([]string{LatestVersionFilter, "foo"}), "unknown deprecation ids foo")
+}
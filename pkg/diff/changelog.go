@@ -0,0 +1,202 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"regexp"
+	"strings"
+)
+
+const podSpecPathRegex = `^(spec\.template\.spec|spec\.jobTemplate\.spec\.template\.spec|spec)\.(initContainers|containers)\[\d+\]`
+
+// DefaultChangelogRules are used for all changes that are not matched by rules from the project config
+var DefaultChangelogRules = []types.ChangelogRule{
+	{
+		ChangeType:     utils.Ptr("update"),
+		FieldPathRegex: podSpecPathRegex + `\.image$`,
+		Message:        "updated image of ${object} from ${old} to ${new}",
+	},
+	{
+		ChangeType:     utils.Ptr("update"),
+		FieldPathRegex: `^spec\.replicas$`,
+		Message:        "scaled ${object} from ${old} to ${new} replicas",
+	},
+	{
+		ChangeType:     utils.Ptr("insert"),
+		FieldPathRegex: podSpecPathRegex + `\.env\[\d+\]$`,
+		Message:        "added env var ${new.name} to ${object}",
+	},
+	{
+		ChangeType:     utils.Ptr("delete"),
+		FieldPathRegex: podSpecPathRegex + `\.env\[\d+\]$`,
+		Message:        "removed env var ${old.name} from ${object}",
+	},
+	{
+		ChangeType:     utils.Ptr("update"),
+		FieldPathRegex: podSpecPathRegex + `\.env\[\d+\]\.value$`,
+		Message:        "changed value of an env var of ${object}",
+	},
+}
+
+var changelogPlaceholderRegex = regexp.MustCompile(`\$\{([a-zA-Z0-9_.]+)}`)
+
+type compiledChangelogRule struct {
+	rule  types.ChangelogRule
+	regex *regexp.Regexp
+}
+
+// ChangelogEntry contains all sentences that describe the changes of a single object
+type ChangelogEntry struct {
+	Ref       k8s.ObjectRef
+	Sentences []string
+}
+
+func (e *ChangelogEntry) String() string {
+	s := strings.Join(e.Sentences, "; ")
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// BuildChangelog builds a concise, human-readable description of all changes found in the command result. The given
+// rules are tried in order, followed by DefaultChangelogRules. Changes that are not matched by any rule are only
+// counted.
+func BuildChangelog(cr *result.CommandResult, rules []types.ChangelogRule) ([]ChangelogEntry, error) {
+	var compiled []compiledChangelogRule
+	for _, r := range append(append([]types.ChangelogRule{}, rules...), DefaultChangelogRules...) {
+		re, err := regexp.Compile(r.FieldPathRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fieldPathRegex %s in changelog rule: %w", r.FieldPathRegex, err)
+		}
+		compiled = append(compiled, compiledChangelogRule{rule: r, regex: re})
+	}
+
+	var ret []ChangelogEntry
+	for _, o := range cr.Objects {
+		if o.Hook {
+			// hooks are re-created on every deployment and thus not worth mentioning
+			continue
+		}
+
+		objectStr := o.Ref.Kind + " " + o.Ref.Name
+		if o.Ref.Namespace != "" {
+			objectStr = o.Ref.Kind + " " + o.Ref.Namespace + "/" + o.Ref.Name
+		}
+
+		e := ChangelogEntry{Ref: o.Ref}
+		if o.New {
+			e.Sentences = append(e.Sentences, "added "+objectStr)
+		}
+		if o.Deleted {
+			e.Sentences = append(e.Sentences, "deleted "+objectStr)
+		}
+
+		unmatched := 0
+		for _, c := range o.Changes {
+			s, ok := describeChange(compiled, o.Ref, objectStr, c)
+			if !ok {
+				unmatched++
+				continue
+			}
+			if utils.FindStrInSlice(e.Sentences, s) == -1 {
+				e.Sentences = append(e.Sentences, s)
+			}
+		}
+		if unmatched != 0 {
+			other := ""
+			if len(e.Sentences) != 0 {
+				other = "other "
+			}
+			fields := "fields"
+			if unmatched == 1 {
+				fields = "field"
+			}
+			e.Sentences = append(e.Sentences, fmt.Sprintf("changed %d %s%s of %s", unmatched, other, fields, objectStr))
+		}
+
+		if len(e.Sentences) != 0 {
+			ret = append(ret, e)
+		}
+	}
+	return ret, nil
+}
+
+func describeChange(rules []compiledChangelogRule, ref k8s.ObjectRef, objectStr string, c result.Change) (string, bool) {
+	for _, r := range rules {
+		if r.rule.Group != nil && *r.rule.Group != ref.Group {
+			continue
+		}
+		if r.rule.Kind != nil && *r.rule.Kind != ref.Kind {
+			continue
+		}
+		if r.rule.ChangeType != nil && *r.rule.ChangeType != c.Type {
+			continue
+		}
+		m := r.regex.FindStringSubmatch(c.JsonPath)
+		if m == nil {
+			continue
+		}
+
+		vars := map[string]string{
+			"object":    objectStr,
+			"kind":      ref.Kind,
+			"name":      ref.Name,
+			"namespace": ref.Namespace,
+			"path":      c.JsonPath,
+		}
+		for i, n := range r.regex.SubexpNames() {
+			if n != "" {
+				vars[n] = m[i]
+			}
+		}
+		addChangelogValueVars(vars, "old", c.OldValue)
+		addChangelogValueVars(vars, "new", c.NewValue)
+
+		return changelogPlaceholderRegex.ReplaceAllStringFunc(r.rule.Message, func(s string) string {
+			n := changelogPlaceholderRegex.FindStringSubmatch(s)[1]
+			if v, ok := vars[n]; ok {
+				return v
+			}
+			return s
+		}), true
+	}
+	return "", false
+}
+
+// addChangelogValueVars adds the value itself and, in case it is an object, all of its direct fields
+func addChangelogValueVars(vars map[string]string, prefix string, v *apiextensionsv1.JSON) {
+	if v == nil {
+		return
+	}
+	var x any
+	if err := json.Unmarshal(v.Raw, &x); err != nil {
+		return
+	}
+	vars[prefix] = formatChangelogValue(x)
+	if m, ok := x.(map[string]any); ok {
+		for k, v2 := range m {
+			vars[prefix+"."+k] = formatChangelogValue(v2)
+		}
+	}
+}
+
+func formatChangelogValue(v any) string {
+	switch v2 := v.(type) {
+	case string:
+		return v2
+	case nil:
+		return "null"
+	default:
+		b, err := json.Marshal(v2)
+		if err != nil {
+			return fmt.Sprintf("%v", v2)
+		}
+		return string(b)
+	}
+}
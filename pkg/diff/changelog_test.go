@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"testing"
+)
+
+func buildChange(t string, p string, oldValue string, newValue string) result.Change {
+	c := result.Change{Type: t, JsonPath: p}
+	if oldValue != "" {
+		c.OldValue = &v1.JSON{Raw: []byte(oldValue)}
+	}
+	if newValue != "" {
+		c.NewValue = &v1.JSON{Raw: []byte(newValue)}
+	}
+	return c
+}
+
+func buildChangelogEntries(t *testing.T, rules []types.ChangelogRule, objects ...result.ResultObject) []string {
+	entries, err := BuildChangelog(&result.CommandResult{Objects: objects}, rules)
+	assert.NoError(t, err)
+	var ret []string
+	for _, e := range entries {
+		ret = append(ret, e.String())
+	}
+	return ret
+}
+
+func TestChangelogDefaultRules(t *testing.T) {
+	deployRef := k8s.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Name: "app", Namespace: "ns"}
+	cmRef := k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm", Namespace: "ns"}
+
+	s := buildChangelogEntries(t, nil,
+		result.ResultObject{BaseObject: result.BaseObject{Ref: deployRef, Changes: []result.Change{
+			buildChange("update", "spec.template.spec.containers[0].image", `"app:1"`, `"app:2"`),
+			buildChange("update", "spec.replicas", `1`, `3`),
+			buildChange("insert", "spec.template.spec.containers[0].env[1]", "", `{"name":"FOO","value":"bar"}`),
+			buildChange("delete", "spec.template.spec.containers[0].env[2]", `{"name":"OLD","value":"x"}`, ""),
+			buildChange("update", "metadata.labels.x", `"a"`, `"b"`),
+		}}},
+		result.ResultObject{BaseObject: result.BaseObject{Ref: cmRef, New: true}},
+		result.ResultObject{BaseObject: result.BaseObject{Ref: cmRef, Changes: []result.Change{
+			buildChange("update", "data.a", `"a"`, `"b"`),
+			buildChange("update", "data.b", `"a"`, `"b"`),
+		}}},
+		result.ResultObject{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "Namespace", Name: "old"}, Deleted: true}},
+		result.ResultObject{BaseObject: result.BaseObject{Ref: cmRef, Hook: true, New: true}},
+	)
+	assert.Equal(t, []string{
+		"Updated image of Deployment ns/app from app:1 to app:2; scaled Deployment ns/app from 1 to 3 replicas; added env var FOO to Deployment ns/app; removed env var OLD from Deployment ns/app; changed 1 other field of Deployment ns/app",
+		"Added ConfigMap ns/cm",
+		"Changed 2 fields of ConfigMap ns/cm",
+		"Deleted Namespace old",
+	}, s)
+}
+
+func TestChangelogCustomRules(t *testing.T) {
+	cmRef := k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm", Namespace: "ns"}
+	deployRef := k8s.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Name: "app", Namespace: "ns"}
+
+	rules := []types.ChangelogRule{
+		{
+			Kind:           utils.Ptr("ConfigMap"),
+			FieldPathRegex: `^data\.(?P<key>.*)$`,
+			Message:        "changed ${key} of ${object} to ${new}",
+		},
+		{
+			// overrides the built-in rule
+			FieldPathRegex: `^spec\.replicas$`,
+			Message:        "${name} now has ${new} replicas",
+		},
+	}
+
+	s := buildChangelogEntries(t, rules,
+		result.ResultObject{BaseObject: result.BaseObject{Ref: cmRef, Changes: []result.Change{
+			buildChange("update", "data.a", `"a"`, `"b"`),
+		}}},
+		result.ResultObject{BaseObject: result.BaseObject{Ref: deployRef, Changes: []result.Change{
+			buildChange("update", "spec.replicas", `1`, `3`),
+			buildChange("update", "data.a", `"a"`, `"b"`),
+		}}},
+	)
+	assert.Equal(t, []string{
+		"Changed a of ConfigMap ns/cm to b",
+		"App now has 3 replicas; changed 1 other field of Deployment ns/app",
+	}, s)
+
+	_, err := BuildChangelog(&result.CommandResult{}, []types.ChangelogRule{{FieldPathRegex: "(", Message: "x"}})
+	assert.Error(t, err)
+}
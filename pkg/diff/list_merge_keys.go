@@ -0,0 +1,257 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type SchemaProvider func(gvk schema.GroupVersionKind) (*uo.UnstructuredObject, error)
+
+// ListMergeKeys normalizes lists of maps into maps keyed by the values of the list's merge keys, so that diffing matches
+// list elements by key instead of by index. Merge keys are taken from the project configuration, from the
+// x-kubernetes-list-map-keys of CRD schemas and from a built-in table for the core Kubernetes types (in that order).
+type ListMergeKeys struct {
+	getSchema SchemaProvider
+
+	mutex       sync.Mutex
+	schemaCache map[schema.GroupVersionKind][]types.ListMergeKeysConfig
+}
+
+func NewListMergeKeys(getSchema SchemaProvider) *ListMergeKeys {
+	return &ListMergeKeys{
+		getSchema:   getSchema,
+		schemaCache: map[schema.GroupVersionKind][]types.ListMergeKeysConfig{},
+	}
+}
+
+var defaultListMergeKeys = buildDefaultListMergeKeys()
+
+func buildDefaultListMergeKeys() []types.ListMergeKeysConfig {
+	var ret []types.ListMergeKeysConfig
+	add := func(group string, kind string, fieldPath string, keys ...string) {
+		ret = append(ret, types.ListMergeKeysConfig{
+			Group:     &group,
+			Kind:      &kind,
+			FieldPath: fieldPath,
+			Keys:      keys,
+		})
+	}
+
+	podSpecs := []struct {
+		group string
+		kind  string
+		path  string
+	}{
+		{"", "Pod", "spec"},
+		{"", "PodTemplate", "template.spec"},
+		{"", "ReplicationController", "spec.template.spec"},
+		{"apps", "Deployment", "spec.template.spec"},
+		{"apps", "ReplicaSet", "spec.template.spec"},
+		{"apps", "StatefulSet", "spec.template.spec"},
+		{"apps", "DaemonSet", "spec.template.spec"},
+		{"batch", "Job", "spec.template.spec"},
+		{"batch", "CronJob", "spec.jobTemplate.spec.template.spec"},
+	}
+	for _, ps := range podSpecs {
+		for _, c := range []string{"containers", "initContainers", "ephemeralContainers"} {
+			p := ps.path + "." + c
+			add(ps.group, ps.kind, p, "name")
+			add(ps.group, ps.kind, p+"[*].env", "name")
+			add(ps.group, ps.kind, p+"[*].ports", "containerPort", "protocol")
+			add(ps.group, ps.kind, p+"[*].volumeMounts", "mountPath")
+			add(ps.group, ps.kind, p+"[*].volumeDevices", "devicePath")
+		}
+		add(ps.group, ps.kind, ps.path+".volumes", "name")
+		add(ps.group, ps.kind, ps.path+".imagePullSecrets", "name")
+		add(ps.group, ps.kind, ps.path+".hostAliases", "ip")
+	}
+	add("", "Service", "spec.ports", "port", "protocol")
+
+	return ret
+}
+
+func buildSchemaFieldPath(parent string, name string) string {
+	if isSimpleFieldName(name) {
+		return parent + "." + name
+	}
+	return fmt.Sprintf("%s[%q]", parent, name)
+}
+
+func isSimpleFieldName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i != 0 && c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// listMergeKeysFromSchema collects all lists with x-kubernetes-list-type=map from the given OpenAPI v3 schema
+func listMergeKeysFromSchema(s *uo.UnstructuredObject, fieldPath string, ret *[]types.ListMergeKeysConfig) {
+	if s == nil {
+		return
+	}
+
+	props, _, _ := s.GetNestedObject("properties")
+	if props != nil {
+		var names []string
+		for k := range props.Object {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			child, _, _ := props.GetNestedObject(k)
+			listMergeKeysFromSchema(child, buildSchemaFieldPath(fieldPath, k), ret)
+		}
+	}
+
+	ap, _, _ := s.GetNestedObject("additionalProperties")
+	if ap != nil {
+		listMergeKeysFromSchema(ap, fieldPath+".*", ret)
+	}
+
+	t, _, _ := s.GetNestedString("type")
+	if t == "array" {
+		listType, _, _ := s.GetNestedString("x-kubernetes-list-type")
+		keys, _, _ := s.GetNestedStringList("x-kubernetes-list-map-keys")
+		if listType == "map" && len(keys) != 0 {
+			*ret = append(*ret, types.ListMergeKeysConfig{
+				FieldPath: fieldPath,
+				Keys:      keys,
+			})
+		}
+		items, _, _ := s.GetNestedObject("items")
+		listMergeKeysFromSchema(items, fieldPath+"[*]", ret)
+	}
+}
+
+func (l *ListMergeKeys) getSchemaListMergeKeys(gvk schema.GroupVersionKind) []types.ListMergeKeysConfig {
+	if l.getSchema == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if x, ok := l.schemaCache[gvk]; ok {
+		return x
+	}
+
+	var ret []types.ListMergeKeysConfig
+	s, err := l.getSchema(gvk)
+	if err == nil {
+		listMergeKeysFromSchema(s, "$", &ret)
+	}
+	l.schemaCache[gvk] = ret
+	return ret
+}
+
+func listToMergeKeyMap(l []any, keys []string) (map[string]any, bool) {
+	m := make(map[string]any, len(l))
+	for _, e := range l {
+		em, ok := e.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		var kvs []string
+		for _, k := range keys {
+			v, ok := em[k]
+			if !ok || v == nil {
+				return nil, false
+			}
+			kvs = append(kvs, fmt.Sprint(v))
+		}
+		kv := strings.Join(kvs, "/")
+		if _, ok := m[kv]; ok {
+			// duplicate keys can't be matched reliably
+			return nil, false
+		}
+		m[kv] = e
+	}
+	return m, true
+}
+
+// NormalizeLists converts all lists of maps with known merge keys into maps keyed by the merge key values. Lists
+// with elements that miss a merge key or with duplicate keys are left untouched. The given configs take precedence
+// over merge keys from the schema and from the built-in table.
+func (l *ListMergeKeys) NormalizeLists(o *uo.UnstructuredObject, configs []types.ListMergeKeysConfig) error {
+	if len(o.Object) == 0 {
+		return nil
+	}
+
+	gvk := o.GetK8sGVK()
+
+	var allConfigs []types.ListMergeKeysConfig
+	allConfigs = append(allConfigs, configs...)
+	allConfigs = append(allConfigs, l.getSchemaListMergeKeys(gvk)...)
+	allConfigs = append(allConfigs, defaultListMergeKeys...)
+
+	checkMatch := func(v string, m *string) bool {
+		if m == nil {
+			return true
+		}
+		return v == *m
+	}
+
+	type foundList struct {
+		kp   uo.KeyPath
+		keys []string
+	}
+	seen := map[string]bool{}
+	var lists []foundList
+	for _, c := range allConfigs {
+		if !checkMatch(gvk.Group, c.Group) || !checkMatch(gvk.Kind, c.Kind) {
+			continue
+		}
+		jp, err := uo.NewMyJsonPath(c.FieldPath)
+		if err != nil {
+			return fmt.Errorf("invalid list merge keys field path %s: %w", c.FieldPath, err)
+		}
+		kps, err := jp.ListMatchingFields(o)
+		if err != nil {
+			return err
+		}
+		for _, kp := range kps {
+			s := kp.ToJsonPath()
+			if seen[s] {
+				continue
+			}
+			seen[s] = true
+			lists = append(lists, foundList{kp: kp, keys: c.Keys})
+		}
+	}
+
+	// convert inner lists first, so that the key paths of outer lists (which contain indexes) stay valid
+	sort.SliceStable(lists, func(i, j int) bool {
+		return len(lists[i].kp) > len(lists[j].kp)
+	})
+
+	for _, fl := range lists {
+		v, found, err := o.GetNestedField(fl.kp...)
+		if err != nil || !found {
+			continue
+		}
+		lv, ok := v.([]any)
+		if !ok || len(lv) == 0 {
+			continue
+		}
+		m, ok := listToMergeKeyMap(lv, fl.keys)
+		if !ok {
+			continue
+		}
+		err = o.SetNestedField(m, fl.kp...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func diffNormalizedLists(t *testing.T, l *ListMergeKeys, configs []types.ListMergeKeysConfig, oldObject *uo.UnstructuredObject, newObject *uo.UnstructuredObject) []string {
+	oldObject = oldObject.Clone()
+	newObject = newObject.Clone()
+	assert.NoError(t, l.NormalizeLists(oldObject, configs))
+	assert.NoError(t, l.NormalizeLists(newObject, configs))
+	changes, err := Diff(oldObject, newObject)
+	assert.NoError(t, err)
+	var ret []string
+	for _, c := range changes {
+		ret = append(ret, fmt.Sprintf("%s %s", c.Type, c.JsonPath))
+	}
+	return ret
+}
+
+func TestListMergeKeysBuiltin(t *testing.T) {
+	l := NewListMergeKeys(nil)
+
+	oldObject := buildObject(`{"spec": {"template": {"spec": {
+  "containers": [
+    {"name": "c1", "ports": [{"containerPort": 80, "protocol": "TCP"}, {"containerPort": 443, "protocol": "TCP"}]},
+    {"name": "c2", "image": "i2"}
+  ],
+  "tolerations": [{"key": "k1"}, {"key": "k2"}]
+}}}}`)
+	reordered := buildObject(`{"spec": {"template": {"spec": {
+  "containers": [
+    {"name": "c2", "image": "i2"},
+    {"name": "c1", "ports": [{"containerPort": 443, "protocol": "TCP"}, {"containerPort": 80, "protocol": "TCP"}]}
+  ],
+  "tolerations": [{"key": "k1"}, {"key": "k2"}]
+}}}}`)
+	assert.Empty(t, diffNormalizedLists(t, l, nil, oldObject, reordered))
+
+	added := buildObject(`{"spec": {"template": {"spec": {
+  "containers": [
+    {"name": "c3", "image": "i3"},
+    {"name": "c1", "ports": [{"containerPort": 80, "protocol": "TCP"}, {"containerPort": 443, "protocol": "TCP"}]},
+    {"name": "c2", "image": "i2-new"}
+  ],
+  "tolerations": [{"key": "k1"}, {"key": "k2"}]
+}}}}`)
+	assert.Equal(t, []string{
+		`update spec.template.spec.containers.c2.image`,
+		`insert spec.template.spec.containers.c3`,
+	}, diffNormalizedLists(t, l, nil, oldObject, added))
+
+	// tolerations have no merge keys, so a reorder combined with a modification is diffed by index
+	oldTolerations := buildObject(`{"spec": {"template": {"spec": {"tolerations": [{"key": "k1", "value": "a"}, {"key": "k2", "value": "b"}]}}}}`)
+	newTolerations := buildObject(`{"spec": {"template": {"spec": {"tolerations": [{"key": "k2", "value": "b"}, {"key": "k1", "value": "c"}]}}}}`)
+	changes := diffNormalizedLists(t, l, nil, oldTolerations, newTolerations)
+	assert.NotEmpty(t, changes)
+	for _, c := range changes {
+		assert.Contains(t, c, "spec.template.spec.tolerations[")
+	}
+
+	// ...unless configured by the project
+	configs := []types.ListMergeKeysConfig{
+		{FieldPath: "spec.template.spec.tolerations", Keys: []string{"key"}},
+	}
+	assert.Equal(t, []string{
+		`update spec.template.spec.tolerations.k1.value`,
+	}, diffNormalizedLists(t, l, configs, oldTolerations, newTolerations))
+}
+
+func TestListMergeKeysFromSchema(t *testing.T) {
+	crdSchema := uo.FromStringMust(`{
+  "type": "object",
+  "properties": {
+    "spec": {
+      "type": "object",
+      "properties": {
+        "items": {
+          "type": "array",
+          "x-kubernetes-list-type": "map",
+          "x-kubernetes-list-map-keys": ["id"],
+          "items": {"type": "object", "properties": {"id": {"type": "string"}, "value": {"type": "string"}}}
+        },
+        "plain": {
+          "type": "array",
+          "items": {"type": "object"}
+        }
+      }
+    }
+  }
+}`)
+	schemaCalls := 0
+	l := NewListMergeKeys(func(gvk schema.GroupVersionKind) (*uo.UnstructuredObject, error) {
+		schemaCalls++
+		if gvk.Kind != "MyKind" {
+			return nil, fmt.Errorf("not found")
+		}
+		return crdSchema, nil
+	})
+
+	build := func(s string) *uo.UnstructuredObject {
+		o := uo.FromStringMust(s)
+		o.SetK8sGVK(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "MyKind"})
+		o.SetK8sName("test")
+		return o
+	}
+
+	oldObject := build(`{"spec": {"items": [{"id": "a", "value": "1"}, {"id": "b", "value": "2"}], "plain": [{"x": 1, "y": "a"}, {"x": 2}]}}`)
+	newObject := build(`{"spec": {"items": [{"id": "b", "value": "2"}, {"id": "a", "value": "3"}], "plain": [{"x": 2}, {"x": 1, "y": "b"}]}}`)
+	changes := diffNormalizedLists(t, l, nil, oldObject, newObject)
+	assert.Contains(t, changes, `update spec.items["a"].value`)
+	for _, c := range changes {
+		if c != `update spec.items["a"].value` {
+			assert.Contains(t, c, "spec.plain[")
+		}
+	}
+
+	removed := build(`{"spec": {"items": [{"id": "b", "value": "2"}], "plain": [{"x": 1, "y": "a"}, {"x": 2}]}}`)
+	assert.Equal(t, []string{
+		`delete spec.items["a"]`,
+	}, diffNormalizedLists(t, l, nil, oldObject, removed))
+
+	// the schema is only requested once per GVK
+	assert.Equal(t, 1, schemaCalls)
+}
+
+func TestListMergeKeysMissingKeys(t *testing.T) {
+	l := NewListMergeKeys(nil)
+
+	o := buildObject(`{"spec": {"template": {"spec": {"containers": [{"name": "c1"}, {"image": "i2"}], "volumes": [{"name": "v"}, {"name": "v"}]}}}}`)
+	expected := o.Clone()
+	assert.NoError(t, l.NormalizeLists(o, nil))
+	assert.Equal(t, expected, o)
+}
@@ -92,20 +92,28 @@ func normalizeServiceAccount(o *uo.UnstructuredObject) {
 }
 
 func normalizeMetadata(o *uo.UnstructuredObject) {
+	removeNoisyMetadata(o)
+
+	// Ensure empty labels/metadata exist
+	_ = o.SetNestedFieldDefault(map[string]any{}, "metadata", "labels")
+	_ = o.SetNestedFieldDefault(map[string]any{}, "metadata", "annotations")
+}
+
+func removeNoisyMetadata(o *uo.UnstructuredObject) {
 	// We don't care about managedFields when diffing (they just produce noise)
 	_ = o.RemoveNestedField("metadata", "managedFields")
 	_ = o.RemoveNestedField("metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration")
 
+	// These change on every run and are only meant to track hooks of interrupted runs
+	_ = o.RemoveNestedField("metadata", "labels", "kluctl.io/hook-run-id")
+	_ = o.RemoveNestedField("metadata", "annotations", "kluctl.io/hook-run-started")
+
 	// We don't want to see this in diffs
 	_ = o.RemoveNestedField("metadata", "creationTimestamp")
 	_ = o.RemoveNestedField("metadata", "generation")
 	_ = o.RemoveNestedField("metadata", "resourceVersion")
 	_ = o.RemoveNestedField("metadata", "selfLink")
 	_ = o.RemoveNestedField("metadata", "uid")
-
-	// Ensure empty labels/metadata exist
-	_ = o.SetNestedFieldDefault(map[string]any{}, "metadata", "labels")
-	_ = o.SetNestedFieldDefault(map[string]any{}, "metadata", "annotations")
 }
 
 func normalizeMisc(o *uo.UnstructuredObject) {
@@ -132,11 +140,63 @@ func normalizeFloats(o *uo.UnstructuredObject) {
 	})
 }
 
+// StripServerFields returns a copy of the object without the fields that are populated by the API server or only
+// used internally by kluctl. Unlike NormalizeObject, it keeps the structure of the object intact.
+func StripServerFields(o_ *uo.UnstructuredObject) *uo.UnstructuredObject {
+	o := o_.Clone()
+	normalizeFloats(o)
+	removeNoisyMetadata(o)
+	normalizeMisc(o)
+	return o
+}
+
 var ignoreDiffFieldAnnotationRegex = regexp.MustCompile(`^kluctl.io/ignore-diff-field(-\d*)?$`)
 var ignoreDiffFieldRegexAnnotationRegex = regexp.MustCompile(`^kluctl.io/ignore-diff-field-regex(-\d*)?$`)
 
+const ignoreDiffFieldsAnnotation = "kluctl.io/ignore-diff-fields"
+
+// ObjectIgnoreForDiffs returns the ignore rules that are specified via annotations on the given local object
+func ObjectIgnoreForDiffs(localObject *uo.UnstructuredObject) []types.IgnoreForDiffItemConfig {
+	var ret []types.IgnoreForDiffItemConfig
+	for _, v := range localObject.GetK8sAnnotationsWithRegex(ignoreDiffFieldAnnotationRegex) {
+		ret = append(ret, types.IgnoreForDiffItemConfig{
+			FieldPath: []string{v},
+		})
+	}
+	for _, v := range localObject.GetK8sAnnotationsWithRegex(ignoreDiffFieldRegexAnnotationRegex) {
+		ret = append(ret, types.IgnoreForDiffItemConfig{
+			FieldPathRegex: []string{v},
+		})
+	}
+	if v := localObject.GetK8sAnnotation(ignoreDiffFieldsAnnotation); v != nil {
+		// the annotation contains a comma or newline separated list of field paths
+		var fieldPaths []string
+		for _, fp := range strings.FieldsFunc(*v, func(r rune) bool {
+			return r == ',' || r == '\n'
+		}) {
+			fp = strings.TrimSpace(fp)
+			if fp != "" {
+				fieldPaths = append(fieldPaths, fp)
+			}
+		}
+		if len(fieldPaths) != 0 {
+			ret = append(ret, types.IgnoreForDiffItemConfig{
+				FieldPath: fieldPaths,
+			})
+		}
+	}
+	return ret
+}
+
 // NormalizeObject Performs some deterministic sorting and other normalizations to avoid ugly diffs due to order changes
 func NormalizeObject(o_ *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, localObject *uo.UnstructuredObject) (*uo.UnstructuredObject, error) {
+	ignoreForDiffs = append(append([]types.IgnoreForDiffItemConfig{}, ignoreForDiffs...), ObjectIgnoreForDiffs(localObject)...)
+	return NormalizeObjectWithoutObjectIgnores(o_, ignoreForDiffs, localObject)
+}
+
+// NormalizeObjectWithoutObjectIgnores is the same as NormalizeObject, except that it does not apply the ignore rules
+// returned by ObjectIgnoreForDiffs. It is used to find changes that were only ignored due to annotations.
+func NormalizeObjectWithoutObjectIgnores(o_ *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, localObject *uo.UnstructuredObject) (*uo.UnstructuredObject, error) {
 	gvk := o_.GetK8sGVK()
 	name := o_.GetK8sName()
 	ns := o_.GetK8sNamespace()
@@ -167,18 +227,6 @@ func NormalizeObject(o_ *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreFor
 		return v == *m
 	}
 
-	ignoreForDiffs = append([]types.IgnoreForDiffItemConfig{}, ignoreForDiffs...)
-	for _, v := range localObject.GetK8sAnnotationsWithRegex(ignoreDiffFieldAnnotationRegex) {
-		ignoreForDiffs = append(ignoreForDiffs, types.IgnoreForDiffItemConfig{
-			FieldPath: []string{v},
-		})
-	}
-	for _, v := range localObject.GetK8sAnnotationsWithRegex(ignoreDiffFieldRegexAnnotationRegex) {
-		ignoreForDiffs = append(ignoreForDiffs, types.IgnoreForDiffItemConfig{
-			FieldPathRegex: []string{v},
-		})
-	}
-
 	for _, ifd := range ignoreForDiffs {
 		if !checkMatch(gvk.Group, ifd.Group) {
 			continue
@@ -200,6 +200,11 @@ func TestNormalizeIgnoreForDiffsByAnnotations(t *testing.T) {
 			local:  buildObject(`{"metadata": {"annotations": {"kluctl.io/ignore-diff-field-regex": "metadata\\.labels\\.l[12]", "kluctl.io/ignore-diff-field-regex-1": "metadata\\.labels\\.l3"}}}`),
 			result: buildResultObject(`{"metadata": {"labels": {"good": "keep"}}}`),
 		},
+		{
+			remote: buildObject(`{"metadata": {"labels": {"l1": "v1", "l2": "l2", "l3": "l3", "good": "keep"}}}`),
+			local:  buildObject(`{"metadata": {"annotations": {"kluctl.io/ignore-diff-fields": "metadata.labels.l1, metadata.labels.l2\nmetadata.labels.l3\n"}}}`),
+			result: buildResultObject(`{"metadata": {"labels": {"good": "keep"}}}`),
+		},
 	}
 	runTests(t, testCases)
 }
@@ -1,9 +1,14 @@
 package diff
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
@@ -15,25 +20,124 @@ import (
 
 var secretGk = schema.GroupKind{Group: "", Kind: "Secret"}
 
+type ObfuscateMode string
+
+const (
+	// ObfuscateModeRedact replaces all sensitive values with a constant placeholder
+	ObfuscateModeRedact ObfuscateMode = "redact"
+	// ObfuscateModeHash replaces sensitive values with salted hashes, so that identical values lead to identical
+	// tokens and changed values can be told apart from unchanged values
+	ObfuscateModeHash ObfuscateMode = "hash"
+)
+
+const (
+	obfuscatedValue  = "*****"
+	hashTokenPrefix  = obfuscatedValue + "-"
+	hashTokenHexSize = 16
+)
+
+// Obfuscator replaces sensitive values in command results. The data of Secrets is always obfuscated, Rules specify
+// additional fields to obfuscate.
 type Obfuscator struct {
+	Rules []types.ObfuscationRule
+	Mode  ObfuscateMode
+
+	// RedactHashTokens replaces values that were already obfuscated in hash mode with the constant placeholder. This is
+	// required when comparing results that were obfuscated with different salts.
+	RedactHashTokens bool
+
+	// salt is generated randomly on first use in hash mode and never stored, so that hashes of low-entropy values
+	// can't be brute-forced offline. Create a new Obfuscator per command result.
+	salt []byte
 }
 
-func (o *Obfuscator) ObfuscateResult(r *result.CommandResult) error {
-	for _, x := range r.Objects {
-		var err error
-		x.Rendered, err = o.ObfuscateObject(x.Rendered)
+func ParseObfuscateMode(s string) (ObfuscateMode, error) {
+	switch ObfuscateMode(s) {
+	case "", ObfuscateModeRedact:
+		return ObfuscateModeRedact, nil
+	case ObfuscateModeHash:
+		return ObfuscateModeHash, nil
+	default:
+		return "", fmt.Errorf("invalid obfuscate mode '%s', must be one of redact or hash", s)
+	}
+}
+
+func (o *Obfuscator) isHashMode() bool {
+	return o.Mode == ObfuscateModeHash
+}
+
+// hashToken returns a token that is stable for identical values within the lifetime of the Obfuscator
+func (o *Obfuscator) hashToken(v any) string {
+	if o.salt == nil {
+		o.salt = make([]byte, 32)
+		_, err := rand.Read(o.salt)
 		if err != nil {
-			return err
+			// crypto/rand never fails on supported platforms
+			panic(err)
 		}
-		x.Remote, err = o.ObfuscateObject(x.Remote)
-		if err != nil {
-			return err
+	}
+	b, _ := json.Marshal(v)
+	h := hmac.New(sha256.New, o.salt)
+	h.Write(b)
+	return hashTokenPrefix + hex.EncodeToString(h.Sum(nil))[:hashTokenHexSize]
+}
+
+// replaceValue returns the placeholder for the given sensitive value
+func (o *Obfuscator) replaceValue(v any) any {
+	if s, ok := v.(string); ok && isObfuscatedValue(s) {
+		// already obfuscated, e.g. when copies of obfuscated results are obfuscated again
+		if o.RedactHashTokens {
+			return obfuscatedValue
+		}
+		return s
+	}
+	if o.isHashMode() {
+		return o.hashToken(v)
+	}
+	return obfuscatedValue
+}
+
+// replaceSecretDataValue is the same as replaceValue, but for base64 encoded values found in the data of Secrets. The
+// decoded value is hashed, so that identical values in data and stringData lead to identical tokens.
+func (o *Obfuscator) replaceSecretDataValue(v any) any {
+	if s, ok := v.(string); ok && o.isHashMode() {
+		if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+			return o.replaceValue(string(b))
+		}
+	}
+	return o.replaceValue(v)
+}
+
+func isObfuscatedValue(s string) bool {
+	if s == obfuscatedValue {
+		return true
+	}
+	if !strings.HasPrefix(s, hashTokenPrefix) || len(s) != len(hashTokenPrefix)+hashTokenHexSize {
+		return false
+	}
+	_, err := hex.DecodeString(s[len(hashTokenPrefix):])
+	return err == nil
+}
+
+func (o *Obfuscator) ObfuscateResult(r *result.CommandResult) error {
+	for i := range r.Objects {
+		x := &r.Objects[i]
+		for _, m := range []**uo.UnstructuredObject{&x.Rendered, &x.Remote, &x.Applied, &x.DeletedManifest} {
+			var byRules bool
+			var err error
+			*m, byRules, err = o.obfuscateObject(*m)
+			if err != nil {
+				return err
+			}
+			if byRules {
+				x.ObfuscatedByRules = true
+			}
 		}
-		x.Applied, err = o.ObfuscateObject(x.Applied)
+		err := o.ObfuscateChanges(x.Ref, x.Changes)
 		if err != nil {
 			return err
 		}
-		err = o.ObfuscateChanges(x.Ref, x.Changes)
+		err = o.ObfuscateChanges(x.Ref, x.IgnoredChanges)
 		if err != nil {
 			return err
 		}
@@ -48,26 +152,32 @@ func (o *Obfuscator) ObfuscateChanges(ref k8s.ObjectRef, changes []result.Change
 			return err
 		}
 	}
-	return nil
+	return o.obfuscateChangesByRules(ref, changes)
 }
 
 func (o *Obfuscator) ObfuscateObject(x *uo.UnstructuredObject) (*uo.UnstructuredObject, error) {
+	x, _, err := o.obfuscateObject(x)
+	return x, err
+}
+
+// obfuscateObject is like ObfuscateObject, but additionally returns whether obfuscation rules replaced any values
+func (o *Obfuscator) obfuscateObject(x *uo.UnstructuredObject) (*uo.UnstructuredObject, bool, error) {
 	if x == nil {
-		return nil, nil
+		return nil, false, nil
 	}
 	ref := x.GetK8sRef()
 	if ref.GroupKind() == secretGk {
 		var err error
 		x, err = o.obfuscateSecret(x)
 		if err != nil {
-			return x, err
+			return x, false, err
 		}
 	}
-	return x, nil
+	return o.obfuscateObjectByRules(x)
 }
 
 func (o *Obfuscator) obfuscateSecretChanges(ref k8s.ObjectRef, changes []result.Change) error {
-	replaceValues := func(j *apiextensionsv1.JSON, v string) *apiextensionsv1.JSON {
+	replaceValuesFn := func(j *apiextensionsv1.JSON, replace func(v any) any) *apiextensionsv1.JSON {
 		if j == nil {
 			return nil
 		}
@@ -79,15 +189,15 @@ func (o *Obfuscator) obfuscateSecretChanges(ref k8s.ObjectRef, changes []result.
 		}
 
 		if m, ok := x.(map[string]any); ok {
-			for k, _ := range m {
-				m[k] = v
+			for k, v := range m {
+				m[k] = replace(v)
 			}
 		} else if a, ok := x.([]any); ok {
-			for i, _ := range a {
-				a[i] = v
+			for i, v := range a {
+				a[i] = replace(v)
 			}
 		} else {
-			x = v
+			x = replace(x)
 		}
 
 		b, err := json.Marshal(x)
@@ -97,6 +207,11 @@ func (o *Obfuscator) obfuscateSecretChanges(ref k8s.ObjectRef, changes []result.
 
 		return &apiextensionsv1.JSON{Raw: b}
 	}
+	replaceValues := func(j *apiextensionsv1.JSON, v string) *apiextensionsv1.JSON {
+		return replaceValuesFn(j, func(any) any {
+			return v
+		})
+	}
 
 	for i, _ := range changes {
 		c := &changes[i]
@@ -112,7 +227,15 @@ func (o *Obfuscator) obfuscateSecretChanges(ref k8s.ObjectRef, changes []result.
 			return fmt.Errorf("unexpected jsonPath fragment: %s", c.JsonPath)
 		}
 
-		if child == "data" || child == "stringData" {
+		if o.isHashMode() && (child == "data" || child == "stringData") {
+			replace := o.replaceValue
+			if child == "data" {
+				replace = o.replaceSecretDataValue
+			}
+			c.NewValue = replaceValuesFn(c.NewValue, replace)
+			c.OldValue = replaceValuesFn(c.OldValue, replace)
+			_ = updateUnifiedDiff(c)
+		} else if child == "data" || child == "stringData" {
 			c.NewValue = replaceValues(c.NewValue, "*****a")
 			c.OldValue = replaceValues(c.OldValue, "*****b")
 			_ = updateUnifiedDiff(c)
@@ -125,14 +248,42 @@ func (o *Obfuscator) obfuscateSecretChanges(ref k8s.ObjectRef, changes []result.
 	return nil
 }
 
+// IsObfuscatedSecret returns true if the given object is a secret with data that was obfuscated by the Obfuscator
+func IsObfuscatedSecret(x *uo.UnstructuredObject) bool {
+	if x == nil || x.GetK8sRef().GroupKind() != secretGk {
+		return false
+	}
+	found := false
+	for _, f := range []string{"data", "stringData"} {
+		m, ok, _ := x.GetNestedStringMapCopy(f)
+		if !ok {
+			continue
+		}
+		for _, v := range m {
+			if f == "data" {
+				b, err := base64.StdEncoding.DecodeString(v)
+				if err != nil {
+					return false
+				}
+				v = string(b)
+			}
+			if !isObfuscatedValue(v) {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
 func (o *Obfuscator) obfuscateSecret(x *uo.UnstructuredObject) (*uo.UnstructuredObject, error) {
 	data, ok, _ := x.GetNestedField("data")
 	if ok && data != nil {
 		x = x.Clone()
 		data, _, _ = x.GetNestedField("data")
 		if m, ok := data.(map[string]any); ok {
-			for k, _ := range m {
-				m[k] = base64.StdEncoding.EncodeToString([]byte("*****"))
+			for k, v := range m {
+				m[k] = base64.StdEncoding.EncodeToString([]byte(o.replaceSecretDataValue(v).(string)))
 			}
 		} else {
 			return x, fmt.Errorf("'data' is not a map of strings")
@@ -143,8 +294,8 @@ func (o *Obfuscator) obfuscateSecret(x *uo.UnstructuredObject) (*uo.Unstructured
 		x = x.Clone()
 		data, _, _ = x.GetNestedField("stringData")
 		if m, ok := data.(map[string]any); ok {
-			for k, _ := range m {
-				m[k] = "*****"
+			for k, v := range m {
+				m[k] = o.replaceValue(v)
 			}
 		} else {
 			return x, fmt.Errorf("'data' is not a map of strings")
@@ -0,0 +1,250 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/ohler55/ojg/jp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var obfuscationTokenRegex = regexp.MustCompile(`\*\*\*\*\*[0-9]+`)
+
+// parseObfuscationPath parses a field path of an obfuscation rule. Only plain children, indexes and wildcards are
+// supported, as paths must also be matched against the json paths of changes.
+func parseObfuscationPath(p string) (jp.Expr, error) {
+	e, err := jp.ParseString(p)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(jp.Expr, 0, len(e))
+	for i, f := range e {
+		switch f.(type) {
+		case jp.Root:
+			if i != 0 {
+				return nil, fmt.Errorf("unexpected root element in obfuscation fieldPath %s", p)
+			}
+		case jp.Child, jp.Nth, jp.Wildcard:
+			ret = append(ret, f)
+		default:
+			return nil, fmt.Errorf("unsupported element in obfuscation fieldPath %s", p)
+		}
+	}
+	return ret, nil
+}
+
+func matchObfuscationFrag(f jp.Frag, k any) bool {
+	switch tf := f.(type) {
+	case jp.Wildcard:
+		return true
+	case jp.Child:
+		s, ok := k.(string)
+		return ok && s == string(tf)
+	case jp.Nth:
+		i, ok := k.(int)
+		return ok && i == int(tf)
+	}
+	return false
+}
+
+func (o *Obfuscator) getRulePaths(gk schema.GroupKind) ([]jp.Expr, error) {
+	var ret []jp.Expr
+	for _, r := range o.Rules {
+		if r.Group != nil && *r.Group != gk.Group {
+			continue
+		}
+		if r.Kind != nil && *r.Kind != gk.Kind {
+			continue
+		}
+		for _, fp := range r.FieldPath {
+			e, err := parseObfuscationPath(fp)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, e)
+		}
+	}
+	return ret, nil
+}
+
+// obfuscateValue replaces all leaf values of v with the values returned by replace. Maps and lists are kept, so
+// that keys still show up in results and diffs.
+func obfuscateValue(v any, replace func(v any) any) any {
+	switch tv := v.(type) {
+	case map[string]any:
+		for k, x := range tv {
+			tv[k] = obfuscateValue(x, replace)
+		}
+		return tv
+	case []any:
+		for i, x := range tv {
+			tv[i] = obfuscateValue(x, replace)
+		}
+		return tv
+	default:
+		return replace(v)
+	}
+}
+
+// obfuscatePath obfuscates all values found at path, relative to v. The passed value is modified in-place.
+func obfuscatePath(v any, path jp.Expr, replace func(v any) any) any {
+	if len(path) == 0 {
+		return obfuscateValue(v, replace)
+	}
+	switch tv := v.(type) {
+	case map[string]any:
+		for k, x := range tv {
+			if matchObfuscationFrag(path[0], k) {
+				tv[k] = obfuscatePath(x, path[1:], replace)
+			}
+		}
+	case []any:
+		for i, x := range tv {
+			if matchObfuscationFrag(path[0], i) {
+				tv[i] = obfuscatePath(x, path[1:], replace)
+			}
+		}
+	}
+	return v
+}
+
+// obfuscateObjectByRules returns a copy of x with all values obfuscated that match the rules. The returned bool is true
+// if any value was replaced.
+func (o *Obfuscator) obfuscateObjectByRules(x *uo.UnstructuredObject) (*uo.UnstructuredObject, bool, error) {
+	paths, err := o.getRulePaths(x.GetK8sGVK().GroupKind())
+	if err != nil {
+		return x, false, err
+	}
+	if len(paths) == 0 {
+		return x, false, nil
+	}
+	replaced := false
+	replace := func(v any) any {
+		replaced = true
+		return o.replaceValue(v)
+	}
+	x = x.Clone()
+	for _, p := range paths {
+		obfuscatePath(x.Object, p, replace)
+	}
+	return x, replaced, nil
+}
+
+// changeKeyPath converts the json path of a change into a list of keys and indexes
+func changeKeyPath(jsonPath string) ([]any, error) {
+	e, err := jp.ParseString(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]any, 0, len(e))
+	for _, f := range e {
+		switch tf := f.(type) {
+		case jp.Root:
+		case jp.Child:
+			ret = append(ret, string(tf))
+		case jp.Nth:
+			ret = append(ret, int(tf))
+		default:
+			return nil, fmt.Errorf("unexpected jsonPath fragment: %s", jsonPath)
+		}
+	}
+	return ret, nil
+}
+
+// relativeObfuscationPaths returns the rule paths relative to the value of a change at keyPath. A change below a rule
+// path results in an empty relative path, which means that the whole value is obfuscated.
+func relativeObfuscationPaths(keyPath []any, paths []jp.Expr) []jp.Expr {
+	var ret []jp.Expr
+outer:
+	for _, p := range paths {
+		for i := 0; i < len(p) && i < len(keyPath); i++ {
+			if !matchObfuscationFrag(p[i], keyPath[i]) {
+				continue outer
+			}
+		}
+		if len(keyPath) >= len(p) {
+			ret = append(ret, jp.Expr{})
+		} else {
+			ret = append(ret, p[len(keyPath):])
+		}
+	}
+	return ret
+}
+
+func obfuscateJSON(j *apiextensionsv1.JSON, paths []jp.Expr, replace func(v any) any) *apiextensionsv1.JSON {
+	if j == nil {
+		return nil
+	}
+	var x any
+	err := json.Unmarshal(j.Raw, &x)
+	if err != nil {
+		return nil
+	}
+	for _, p := range paths {
+		x = obfuscatePath(x, p, replace)
+	}
+	b, err := json.Marshal(x)
+	if err != nil {
+		return nil
+	}
+	return &apiextensionsv1.JSON{Raw: b}
+}
+
+func (o *Obfuscator) obfuscateChangesByRules(ref k8s.ObjectRef, changes []result.Change) error {
+	paths, err := o.getRulePaths(ref.GroupKind())
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	for i := range changes {
+		c := &changes[i]
+		keyPath, err := changeKeyPath(c.JsonPath)
+		if err != nil {
+			return err
+		}
+		relPaths := relativeObfuscationPaths(keyPath, paths)
+		if len(relPaths) == 0 {
+			continue
+		}
+
+		// equal values get equal tokens, so that the regenerated diff only shows obfuscated values that actually
+		// changed
+		tokens := map[string]string{}
+		tokenize := func(v any) any {
+			b, _ := json.Marshal(v)
+			t, ok := tokens[string(b)]
+			if !ok {
+				t = fmt.Sprintf("*****%d", len(tokens))
+				tokens[string(b)] = t
+			}
+			return t
+		}
+		plain := func(v any) any {
+			return "*****"
+		}
+
+		if o.isHashMode() {
+			c.OldValue = obfuscateJSON(c.OldValue, relPaths, o.replaceValue)
+			c.NewValue = obfuscateJSON(c.NewValue, relPaths, o.replaceValue)
+			_ = updateUnifiedDiff(c)
+			continue
+		}
+
+		oldValue, newValue := c.OldValue, c.NewValue
+		c.OldValue = obfuscateJSON(oldValue, relPaths, tokenize)
+		c.NewValue = obfuscateJSON(newValue, relPaths, tokenize)
+		_ = updateUnifiedDiff(c)
+		c.UnifiedDiff = obfuscationTokenRegex.ReplaceAllString(c.UnifiedDiff, "***** (obfuscated)")
+		c.OldValue = obfuscateJSON(oldValue, relPaths, plain)
+		c.NewValue = obfuscateJSON(newValue, relPaths, plain)
+	}
+	return nil
+}
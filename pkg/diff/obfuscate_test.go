@@ -0,0 +1,246 @@
+package diff
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func newObfuscationTestObject() *uo.UnstructuredObject {
+	return uo.FromMap(map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Config",
+		"metadata": map[string]any{
+			"name":      "cfg",
+			"namespace": "ns",
+		},
+		"spec": map[string]any{
+			"template": map[string]any{
+				"db": map[string]any{
+					"user":     "admin",
+					"password": "secret1",
+				},
+				"cache": map[string]any{
+					"password": map[string]any{"a": "secret2", "b": []any{"secret3"}},
+				},
+			},
+			"users": []any{
+				map[string]any{"name": "u1", "token": "t1"},
+				map[string]any{"name": "u2", "token": "t2"},
+			},
+		},
+	})
+}
+
+func newObfuscationTestRules() []types.ObfuscationRule {
+	group := "example.com"
+	kind := "Config"
+	return []types.ObfuscationRule{
+		{Group: &group, Kind: &kind, FieldPath: []string{"spec.template.*.password", "spec.users[*].token"}},
+	}
+}
+
+func TestObfuscateObjectRules(t *testing.T) {
+	x := newObfuscationTestObject()
+	o := Obfuscator{Rules: newObfuscationTestRules()}
+
+	x2, err := o.ObfuscateObject(x)
+	assert.NoError(t, err)
+
+	// the original object must stay untouched
+	assert.Equal(t, newObfuscationTestObject(), x)
+
+	v, _, _ := x2.GetNestedField("spec", "template", "db")
+	assert.Equal(t, map[string]any{"user": "admin", "password": "*****"}, v)
+	v, _, _ = x2.GetNestedField("spec", "template", "cache", "password")
+	assert.Equal(t, map[string]any{"a": "*****", "b": []any{"*****"}}, v)
+	v, _, _ = x2.GetNestedField("spec", "users")
+	assert.Equal(t, []any{
+		map[string]any{"name": "u1", "token": "*****"},
+		map[string]any{"name": "u2", "token": "*****"},
+	}, v)
+
+	// rules only apply to matching kinds
+	x.SetK8sGVKs("example.com", "v1", "Other")
+	x2, err = o.ObfuscateObject(x)
+	assert.NoError(t, err)
+	assert.Equal(t, x, x2)
+
+	o.Rules[0].FieldPath = []string{"spec..password"}
+	_, err = o.ObfuscateObject(newObfuscationTestObject())
+	assert.ErrorContains(t, err, "unsupported element")
+}
+
+func TestObfuscateChangesRules(t *testing.T) {
+	ref := k8s.ObjectRef{Group: "example.com", Version: "v1", Kind: "Config", Name: "cfg", Namespace: "ns"}
+	o := Obfuscator{Rules: newObfuscationTestRules()}
+
+	j := func(s string) *apiextensionsv1.JSON {
+		return &apiextensionsv1.JSON{Raw: []byte(s)}
+	}
+	changes := []result.Change{
+		// exactly at the rule path
+		{Type: "update", JsonPath: "spec.template.db.password", OldValue: j(`"old"`), NewValue: j(`"new"`)},
+		// below the rule path
+		{Type: "insert", JsonPath: "spec.template.cache.password.a", NewValue: j(`"new"`)},
+		// above the rule path, only the matching fields must be obfuscated
+		{Type: "update", JsonPath: "spec.template", OldValue: j(`{"db":{"user":"a","password":"same"}}`), NewValue: j(`{"db":{"user":"b","password":"same"}}`)},
+		{Type: "delete", JsonPath: "spec.users[1]", OldValue: j(`{"name":"u2","token":"t2"}`)},
+		// not matching
+		{Type: "update", JsonPath: "spec.template.db.user", OldValue: j(`"a"`), NewValue: j(`"b"`)},
+	}
+	for i := range changes {
+		assert.NoError(t, updateUnifiedDiff(&changes[i]))
+	}
+
+	assert.NoError(t, o.ObfuscateChanges(ref, changes))
+
+	assert.Equal(t, `"*****"`, string(changes[0].OldValue.Raw))
+	assert.Equal(t, `"*****"`, string(changes[0].NewValue.Raw))
+	assert.Equal(t, "-***** (obfuscated)\n+***** (obfuscated)", changes[0].UnifiedDiff)
+
+	assert.Equal(t, `"*****"`, string(changes[1].NewValue.Raw))
+	assert.NotContains(t, changes[1].UnifiedDiff, "new")
+
+	assert.Equal(t, `{"db":{"password":"*****","user":"a"}}`, string(changes[2].OldValue.Raw))
+	assert.Equal(t, `{"db":{"password":"*****","user":"b"}}`, string(changes[2].NewValue.Raw))
+	assert.NotContains(t, changes[2].UnifiedDiff, "same")
+	// unchanged obfuscated values must not show up as changed lines
+	for _, l := range strings.Split(changes[2].UnifiedDiff, "\n") {
+		if strings.Contains(l, "password") {
+			assert.True(t, strings.HasPrefix(l, " "), l)
+		}
+	}
+
+	assert.Equal(t, `{"name":"u2","token":"*****"}`, string(changes[3].OldValue.Raw))
+	assert.NotContains(t, changes[3].UnifiedDiff, "t2")
+	assert.Contains(t, changes[3].UnifiedDiff, "u2")
+
+	assert.Equal(t, `"b"`, string(changes[4].NewValue.Raw))
+	assert.Equal(t, "-a\n+b", changes[4].UnifiedDiff)
+}
+
+func TestObfuscateHashMode(t *testing.T) {
+	b64 := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	newSecret := func() *uo.UnstructuredObject {
+		return uo.FromMap(map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]any{"name": "s", "namespace": "ns"},
+			"data":       map[string]any{"a": b64("secret1"), "b": b64("secret1"), "c": b64("secret2")},
+			"stringData": map[string]any{"d": "secret1"},
+		})
+	}
+
+	o := Obfuscator{Mode: ObfuscateModeHash}
+	x, err := o.ObfuscateObject(newSecret())
+	assert.NoError(t, err)
+	data, _, _ := x.GetNestedStringMapCopy("data")
+	stringData, _, _ := x.GetNestedStringMapCopy("stringData")
+
+	decoded := map[string]string{}
+	for k, v := range data {
+		b, err := base64.StdEncoding.DecodeString(v)
+		assert.NoError(t, err)
+		decoded[k] = string(b)
+		assert.True(t, isObfuscatedValue(decoded[k]), decoded[k])
+		assert.NotContains(t, decoded[k], "secret")
+	}
+	// identical values lead to identical tokens, independent of data and stringData
+	assert.Equal(t, decoded["a"], decoded["b"])
+	assert.Equal(t, decoded["a"], stringData["d"])
+	assert.NotEqual(t, decoded["a"], decoded["c"])
+	assert.True(t, IsObfuscatedSecret(x))
+	assert.False(t, IsObfuscatedSecret(newSecret()))
+
+	// obfuscating again keeps the tokens
+	x2, err := o.ObfuscateObject(x)
+	assert.NoError(t, err)
+	assert.Equal(t, x, x2)
+
+	// the salt is random per Obfuscator
+	o2 := Obfuscator{Mode: ObfuscateModeHash}
+	x3, err := o2.ObfuscateObject(newSecret())
+	assert.NoError(t, err)
+	stringData3, _, _ := x3.GetNestedStringMapCopy("stringData")
+	assert.NotEqual(t, stringData["d"], stringData3["d"])
+
+	// hash tokens of different salts can be normalized to the placeholder
+	o4 := Obfuscator{Mode: ObfuscateModeRedact, RedactHashTokens: true}
+	x4, err := o4.ObfuscateObject(x3)
+	assert.NoError(t, err)
+	stringData4, _, _ := x4.GetNestedStringMapCopy("stringData")
+	assert.Equal(t, obfuscatedValue, stringData4["d"])
+
+	j := func(s string) *apiextensionsv1.JSON {
+		return &apiextensionsv1.JSON{Raw: []byte(s)}
+	}
+	ref := k8s.ObjectRef{Version: "v1", Kind: "Secret", Name: "s", Namespace: "ns"}
+	changes := []result.Change{
+		{Type: "update", JsonPath: "data.a", OldValue: j(`"` + b64("secret1") + `"`), NewValue: j(`"` + b64("secret2") + `"`)},
+		{Type: "update", JsonPath: "data", OldValue: j(`{"a":"` + b64("secret1") + `","b":"` + b64("x") + `"}`), NewValue: j(`{"a":"` + b64("secret1") + `","b":"` + b64("y") + `"}`)},
+	}
+	assert.NoError(t, o.ObfuscateChanges(ref, changes))
+	assert.Equal(t, `"`+decoded["a"]+`"`, string(changes[0].OldValue.Raw))
+	assert.Equal(t, `"`+decoded["c"]+`"`, string(changes[0].NewValue.Raw))
+	assert.Equal(t, "-"+decoded["a"]+"\n+"+decoded["c"], changes[0].UnifiedDiff)
+	assert.NotContains(t, changes[1].UnifiedDiff, "secret")
+	// the unchanged value shows up as unchanged line, the changed value as changed lines
+	for _, l := range strings.Split(changes[1].UnifiedDiff, "\n") {
+		if strings.Contains(l, decoded["a"]) {
+			assert.True(t, strings.HasPrefix(l, " "), l)
+		}
+	}
+	assert.Contains(t, changes[1].UnifiedDiff, "\n-b: '"+o.hashToken("x")+"'\n+b: '"+o.hashToken("y")+"'\n")
+
+	ruleChanges := []result.Change{
+		{Type: "update", JsonPath: "spec.template.db.password", OldValue: j(`"old"`), NewValue: j(`"new"`)},
+	}
+	o3 := Obfuscator{Mode: ObfuscateModeHash, Rules: newObfuscationTestRules()}
+	assert.NoError(t, o3.ObfuscateChanges(k8s.ObjectRef{Group: "example.com", Version: "v1", Kind: "Config", Name: "cfg", Namespace: "ns"}, ruleChanges))
+	assert.Equal(t, "-"+o3.hashToken("old")+"\n+"+o3.hashToken("new"), ruleChanges[0].UnifiedDiff)
+}
+
+func TestParseObfuscateMode(t *testing.T) {
+	m, err := ParseObfuscateMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, ObfuscateModeRedact, m)
+	m, err = ParseObfuscateMode("hash")
+	assert.NoError(t, err)
+	assert.Equal(t, ObfuscateModeHash, m)
+	_, err = ParseObfuscateMode("plain")
+	assert.EqualError(t, err, "invalid obfuscate mode 'plain', must be one of redact or hash")
+}
+
+func TestObfuscateResultMarksObjectsObfuscatedByRules(t *testing.T) {
+	other := newObfuscationTestObject()
+	other.SetK8sName("other")
+	other.SetK8sGVKs("example.com", "v1", "Other")
+
+	r := &result.CommandResult{
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: newObfuscationTestObject().GetK8sRef()}, Rendered: newObfuscationTestObject()},
+			{BaseObject: result.BaseObject{Ref: newObfuscationTestObject().GetK8sRef(), Deleted: true}, DeletedManifest: newObfuscationTestObject()},
+			{BaseObject: result.BaseObject{Ref: other.GetK8sRef()}, Rendered: other},
+		},
+	}
+	o := Obfuscator{Rules: newObfuscationTestRules()}
+	assert.NoError(t, o.ObfuscateResult(r))
+	assert.True(t, r.Objects[0].ObfuscatedByRules)
+	assert.True(t, r.Objects[1].ObfuscatedByRules)
+	assert.False(t, r.Objects[2].ObfuscatedByRules)
+
+	// obfuscating an already obfuscated result keeps the mark
+	r.Objects[0].ObfuscatedByRules = false
+	assert.NoError(t, o.ObfuscateResult(r))
+	assert.True(t, r.Objects[0].ObfuscatedByRules)
+}
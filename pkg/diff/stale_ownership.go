@@ -0,0 +1,289 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// StaleFieldOwnership describes the fields of a live object that are owned by stale field managers, e.g. the field
+// managers of previous kluctl versions or field managers that were renamed. Server-side apply does not prune fields
+// owned by stale managers, so these fields stay on the object even when they are removed from the rendered object.
+type StaleFieldOwnership struct {
+	Managers []string
+	// MigratedFields are still present in the rendered object, ownership of these is moved to the current manager
+	MigratedFields []string
+	// RemovedFields are not present in the rendered object anymore and not owned by any other manager, these are
+	// removed from the live object
+	RemovedFields []string
+
+	remote         *uo.UnstructuredObject
+	currentManager string
+	staleIndexes   sets.Set[int]
+	migrated       *fieldpath.Set
+	removed        []uo.KeyPath
+}
+
+// IsStaleFieldManager returns true if the given managed fields entry belongs to a stale field manager. All kluctl
+// related managers that use server-side apply and are not the current manager are considered stale, as kluctl only
+// ever applies objects with a single manager. extraManagers specifies additional managers that are considered stale
+// independent of the operation.
+func IsStaleFieldManager(manager string, operation string, currentManager string, extraManagers []string) bool {
+	if manager == currentManager {
+		return false
+	}
+	for _, m := range extraManagers {
+		if m == manager {
+			return true
+		}
+	}
+	return operation == string(metav1.ManagedFieldsOperationApply) && strings.HasPrefix(manager, "kluctl")
+}
+
+// FindStaleFieldOwnership compares the fields owned by stale field managers of the remote object with the local
+// (rendered) object. It returns nil if no stale managers own fields of the main resource.
+func FindStaleFieldOwnership(local *uo.UnstructuredObject, remote *uo.UnstructuredObject, currentManager string, extraManagers []string) (*StaleFieldOwnership, error) {
+	ret := &StaleFieldOwnership{
+		remote:         remote,
+		currentManager: currentManager,
+		staleIndexes:   sets.New[int](),
+		migrated:       &fieldpath.Set{},
+	}
+
+	stale := &fieldpath.Set{}
+	others := &fieldpath.Set{}
+	managers := sets.New[string]()
+	for i, mf := range remote.GetK8sManagedFields() {
+		manager, _, _ := mf.GetNestedString("manager")
+		operation, _, _ := mf.GetNestedString("operation")
+		subresource, _, _ := mf.GetNestedString("subresource")
+		if subresource != "" {
+			continue
+		}
+		fields, ok, err := mf.GetNestedObject("fieldsV1")
+		if err != nil {
+			return nil, err
+		}
+		fieldSet, err := parseManagedFieldsSet(fields, ok)
+		if err != nil {
+			return nil, err
+		}
+
+		if IsStaleFieldManager(manager, operation, currentManager, extraManagers) {
+			ret.staleIndexes.Insert(i)
+			managers.Insert(manager)
+			stale = stale.Union(fieldSet)
+		} else {
+			others = others.Union(fieldSet)
+		}
+	}
+	if ret.staleIndexes.Len() == 0 {
+		return nil, nil
+	}
+	ret.Managers = sets.List(managers)
+
+	var otherPaths []fieldpath.Path
+	others.Iterate(func(path fieldpath.Path) {
+		otherPaths = append(otherPaths, path.Copy())
+	})
+	isOwnedByOthers := func(path fieldpath.Path) bool {
+		for _, p := range otherPaths {
+			if len(path) <= len(p) && path.Equals(p[:len(path)]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var removed []fieldpath.Path
+	var retErr error
+	stale.Iterate(func(path fieldpath.Path) {
+		if retErr != nil {
+			return
+		}
+		_, found, err := convertToKeyList(local, path)
+		if err != nil {
+			retErr = err
+			return
+		}
+		if found {
+			ret.migrated.Insert(path.Copy())
+			return
+		}
+		if !isOwnedByOthers(path) {
+			removed = append(removed, path.Copy())
+		}
+	})
+	if retErr != nil {
+		return nil, retErr
+	}
+
+	ret.migrated.Iterate(func(path fieldpath.Path) {
+		ret.MigratedFields = append(ret.MigratedFields, path.String())
+	})
+	sort.Strings(ret.MigratedFields)
+
+	// parents are removed together with their children, so children must not be removed separately
+	sort.SliceStable(removed, func(i, j int) bool {
+		return len(removed[i]) < len(removed[j])
+	})
+	var removedPaths []fieldpath.Path
+outer:
+	for _, path := range removed {
+		for _, p := range removedPaths {
+			if len(p) <= len(path) && p.Equals(path[:len(p)]) {
+				continue outer
+			}
+		}
+		kl, found, err := convertToKeyList(remote, path)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		removedPaths = append(removedPaths, path)
+		ret.removed = append(ret.removed, kl)
+		ret.RemovedFields = append(ret.RemovedFields, path.String())
+	}
+	sort.Strings(ret.RemovedFields)
+
+	return ret, nil
+}
+
+func jsonPointer(kl uo.KeyPath) string {
+	var ret strings.Builder
+	for _, k := range kl {
+		ret.WriteString("/")
+		switch tk := k.(type) {
+		case int:
+			ret.WriteString(strconv.Itoa(tk))
+		default:
+			s := fmt.Sprint(tk)
+			s = strings.ReplaceAll(s, "~", "~0")
+			s = strings.ReplaceAll(s, "/", "~1")
+			ret.WriteString(s)
+		}
+	}
+	return ret.String()
+}
+
+// compareKeyPaths compares key paths element by element, with list indexes being compared numerically
+func compareKeyPaths(a uo.KeyPath, b uo.KeyPath) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		ai, aIsInt := a[i].(int)
+		bi, bIsInt := b[i].(int)
+		if aIsInt && bIsInt {
+			if ai != bi {
+				return ai - bi
+			}
+			continue
+		}
+		if c := strings.Compare(fmt.Sprint(a[i]), fmt.Sprint(b[i])); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+// BuildPatch builds a JSON patch that removes the managed fields entries of all stale managers, moves ownership of
+// the migrated fields to the current manager and removes all fields that are not rendered anymore. The patch fails
+// if the object was modified in-between.
+func (s *StaleFieldOwnership) BuildPatch(now time.Time) ([]byte, error) {
+	var managedFields []any
+	foundCurrent := false
+	for i, mf := range s.remote.GetK8sManagedFields() {
+		if s.staleIndexes.Has(i) {
+			continue
+		}
+		mf = mf.Clone()
+		manager, _, _ := mf.GetNestedString("manager")
+		operation, _, _ := mf.GetNestedString("operation")
+		subresource, _, _ := mf.GetNestedString("subresource")
+		if !foundCurrent && manager == s.currentManager && operation == string(metav1.ManagedFieldsOperationApply) && subresource == "" {
+			foundCurrent = true
+			fields, ok, err := mf.GetNestedObject("fieldsV1")
+			if err != nil {
+				return nil, err
+			}
+			fieldSet, err := parseManagedFieldsSet(fields, ok)
+			if err != nil {
+				return nil, err
+			}
+			err = setManagedFieldsSet(mf, fieldSet.Union(s.migrated))
+			if err != nil {
+				return nil, err
+			}
+		}
+		managedFields = append(managedFields, mf.Object)
+	}
+	if !foundCurrent && !s.migrated.Empty() {
+		mf := uo.FromMap(map[string]any{
+			"apiVersion": s.remote.GetK8sGVK().GroupVersion().String(),
+			"fieldsType": "FieldsV1",
+			"manager":    s.currentManager,
+			"operation":  string(metav1.ManagedFieldsOperationApply),
+			"time":       now.UTC().Format(time.RFC3339),
+		})
+		err := setManagedFieldsSet(mf, s.migrated)
+		if err != nil {
+			return nil, err
+		}
+		managedFields = append(managedFields, mf.Object)
+	}
+	if len(managedFields) == 0 {
+		// an empty list would leave the managed fields untouched, while a single empty entry clears them
+		managedFields = []any{map[string]any{}}
+	}
+
+	ops := []map[string]any{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": s.remote.GetK8sResourceVersion()},
+		{"op": "replace", "path": "/metadata/managedFields", "value": managedFields},
+	}
+
+	// remove in reverse order, so that list indexes stay valid
+	removed := append([]uo.KeyPath{}, s.removed...)
+	sort.SliceStable(removed, func(i, j int) bool {
+		return compareKeyPaths(removed[i], removed[j]) > 0
+	})
+	for _, kl := range removed {
+		ops = append(ops, map[string]any{"op": "remove", "path": jsonPointer(kl)})
+	}
+
+	return json.Marshal(ops)
+}
+
+func parseManagedFieldsSet(fields *uo.UnstructuredObject, ok bool) (*fieldpath.Set, error) {
+	if !ok {
+		return &fieldpath.Set{}, nil
+	}
+	fieldSet, _, err := convertManagedFields(fields.Object)
+	if err != nil {
+		return nil, err
+	}
+	if fieldSet == nil {
+		fieldSet = &fieldpath.Set{}
+	}
+	return fieldSet, nil
+}
+
+func setManagedFieldsSet(mf *uo.UnstructuredObject, s *fieldpath.Set) error {
+	b, err := s.ToJSON()
+	if err != nil {
+		return err
+	}
+	var m map[string]any
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+	return mf.SetNestedField(m, "fieldsV1")
+}
@@ -0,0 +1,160 @@
+package diff
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStaleOwnershipTestObjects(managedFields ...map[string]any) (*uo.UnstructuredObject, *uo.UnstructuredObject) {
+	local := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":      "p",
+			"namespace": "ns",
+		},
+		"spec": map[string]any{
+			"containers": []any{
+				map[string]any{"name": "a", "image": "a"},
+			},
+		},
+	})
+	remote := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":            "p",
+			"namespace":       "ns",
+			"resourceVersion": "1",
+			"labels": map[string]any{
+				"old":   "x",
+				"other": "y",
+			},
+		},
+		"spec": map[string]any{
+			"containers": []any{
+				map[string]any{"name": "a", "image": "a"},
+				map[string]any{"name": "b", "image": "b"},
+				map[string]any{"name": "c", "image": "c"},
+			},
+		},
+	})
+	var l []any
+	for _, mf := range managedFields {
+		l = append(l, mf)
+	}
+	_ = remote.SetNestedField(l, "metadata", "managedFields")
+	return local, remote
+}
+
+func newStaleOwnershipEntry(manager string, operation string, fields map[string]any) map[string]any {
+	return map[string]any{
+		"manager":    manager,
+		"operation":  operation,
+		"apiVersion": "v1",
+		"fieldsType": "FieldsV1",
+		"fieldsV1":   fields,
+	}
+}
+
+func containerFields(names ...string) map[string]any {
+	m := map[string]any{}
+	for _, n := range names {
+		m[`k:{"name":"`+n+`"}`] = map[string]any{
+			".":       map[string]any{},
+			"f:name":  map[string]any{},
+			"f:image": map[string]any{},
+		}
+	}
+	return map[string]any{"f:spec": map[string]any{"f:containers": m}}
+}
+
+func TestIsStaleFieldManager(t *testing.T) {
+	assert.False(t, IsStaleFieldManager("kluctl", "Apply", "kluctl", nil))
+	assert.True(t, IsStaleFieldManager("kluctl-v1", "Apply", "kluctl", nil))
+	// the controller only patches finalizers and annotations of KluctlDeployments
+	assert.False(t, IsStaleFieldManager("kluctl-controller", "Update", "kluctl", nil))
+	assert.False(t, IsStaleFieldManager("my-deployer", "Apply", "kluctl", nil))
+	assert.True(t, IsStaleFieldManager("my-deployer", "Update", "kluctl", []string{"my-deployer"}))
+}
+
+func TestFindStaleFieldOwnership(t *testing.T) {
+	local, remote := newStaleOwnershipTestObjects(
+		newStaleOwnershipEntry("kluctl", "Apply", containerFields("a")),
+		newStaleOwnershipEntry("kluctl-old", "Apply", map[string]any{
+			"f:metadata": map[string]any{"f:labels": map[string]any{"f:old": map[string]any{}, "f:other": map[string]any{}}},
+			"f:spec":     containerFields("a", "b", "c")["f:spec"],
+		}),
+		newStaleOwnershipEntry("kubectl-edit", "Update", map[string]any{
+			"f:metadata": map[string]any{"f:labels": map[string]any{"f:other": map[string]any{}}},
+		}),
+	)
+
+	s, err := FindStaleFieldOwnership(local, local, "kluctl", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	s, err = FindStaleFieldOwnership(local, remote, "kluctl", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kluctl-old"}, s.Managers)
+	assert.Equal(t, []string{
+		`.spec.containers[name="a"]`,
+		`.spec.containers[name="a"].image`,
+		`.spec.containers[name="a"].name`,
+	}, s.MigratedFields)
+	// children of removed list items and fields owned by other managers are not removed separately
+	assert.Equal(t, []string{
+		`.metadata.labels.old`,
+		`.spec.containers[name="b"]`,
+		`.spec.containers[name="c"]`,
+	}, s.RemovedFields)
+
+	patch, err := s.BuildPatch(time.Now())
+	assert.NoError(t, err)
+	var ops []map[string]any
+	assert.NoError(t, json.Unmarshal(patch, &ops))
+	assert.Equal(t, map[string]any{"op": "test", "path": "/metadata/resourceVersion", "value": "1"}, ops[0])
+	assert.Equal(t, "/metadata/managedFields", ops[1]["path"])
+
+	var managers []string
+	for _, mf := range ops[1]["value"].([]any) {
+		managers = append(managers, mf.(map[string]any)["manager"].(string))
+	}
+	assert.Equal(t, []string{"kluctl", "kubectl-edit"}, managers)
+
+	var removes []string
+	for _, op := range ops[2:] {
+		assert.Equal(t, "remove", op["op"])
+		removes = append(removes, op["path"].(string))
+	}
+	// list items are removed from the end, so that indexes stay valid
+	assert.Equal(t, []string{"/spec/containers/2", "/spec/containers/1", "/metadata/labels/old"}, removes)
+}
+
+func TestStaleFieldOwnershipNewManagerEntry(t *testing.T) {
+	local, remote := newStaleOwnershipTestObjects(
+		newStaleOwnershipEntry("renamed", "Apply", containerFields("a")),
+	)
+
+	s, err := FindStaleFieldOwnership(local, remote, "kluctl", []string{"renamed"})
+	assert.NoError(t, err)
+	assert.Empty(t, s.RemovedFields)
+
+	patch, err := s.BuildPatch(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	var ops []map[string]any
+	assert.NoError(t, json.Unmarshal(patch, &ops))
+	assert.Len(t, ops, 2)
+	assert.Equal(t, []any{map[string]any{
+		"apiVersion": "v1",
+		"fieldsType": "FieldsV1",
+		"fieldsV1":   containerFields("a"),
+		"manager":    "kluctl",
+		"operation":  "Apply",
+		"time":       "2024-01-01T00:00:00Z",
+	}}, ops[1]["value"])
+}
@@ -20,6 +20,7 @@ import (
 	helmauth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
 	ociauth "github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
+	"github.com/kluctl/kluctl/v2/pkg/stats"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	cp "github.com/otiai10/copy"
@@ -490,11 +491,26 @@ func (c *Chart) doPullCached(ctx context.Context, version ChartVersion) (*Pulled
 		_ = lock.Close()
 		return nil, nil, err
 	}
+	stats.RecordCacheLookup(ctx, "helm", !needsPull)
 	if !needsPull {
 		return cached, lock, nil
 	}
 
-	err = c.Pull(ctx, cached)
+	pull := func() error {
+		return c.Pull(ctx, cached)
+	}
+	if c.IsRegistryChart() {
+		// git based charts are scheduled by the git cache, so we must not schedule them twice
+		err = repocache.GetFetchScheduler(ctx).Run(ctx, repocache.FetchRequest{
+			Type:     "helm",
+			Source:   fmt.Sprintf("%s (%s)", c.GetChartName(), version.String()),
+			Key:      "helm:" + cacheDir,
+			Priority: repocache.FetchPriorityNormal,
+			Dir:      cacheDir,
+		}, pull)
+	} else {
+		err = pull()
+	}
 	if err != nil {
 		_ = lock.Close()
 		return nil, nil, err
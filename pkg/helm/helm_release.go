@@ -10,9 +10,11 @@ import (
 	securejoin "github.com/cyphar/filepath-securejoin"
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/deprecations"
 	helmauth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	ociauth "github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	"github.com/kluctl/kluctl/v2/pkg/projectlock"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
 	"github.com/kluctl/kluctl/v2/pkg/sops"
 	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
@@ -60,7 +62,7 @@ func NewRelease(ctx context.Context, projectRoot string, relDirInProject string,
 	}
 	credentialsIdValue := ""
 	if config.CredentialsId != nil {
-		status.Deprecation(ctx, "helm-release-credentials-id", "'credentialsId' in helm-chart.yaml is deprecated and support for it will be removed in a future version of Kluctl.")
+		deprecations.Report(ctx, deprecations.HelmReleaseCredentialsId)
 		credentialsIdValue = *config.CredentialsId
 	}
 
@@ -171,11 +173,38 @@ func (hr *Release) getPulledChart(ctx context.Context) (*PulledChart, error) {
 	}
 }
 
+// recordLock records the pulled chart in the project lock. Local charts are part of the project and not recorded.
+func (hr *Release) recordLock(ctx context.Context, pc *PulledChart) error {
+	r := projectlock.GetRecorder(ctx)
+	if r == nil || hr.Chart.IsLocalChart() {
+		return nil
+	}
+	digest, err := projectlock.HashDir(pc.dir)
+	if err != nil {
+		return err
+	}
+	repo := hr.Chart.GetRepo()
+	if hr.Chart.IsGitRepositoryChart() {
+		repo = hr.Chart.gitUrl.String()
+	}
+	r.RecordHelmChart(types.HelmChartLock{
+		Repo:    repo,
+		Chart:   hr.Chart.GetChartName(),
+		Version: pc.version.String(),
+		Digest:  digest,
+	})
+	return nil
+}
+
 func (hr *Release) doRender(ctx context.Context, k *k8s.K8sCluster, k8sVersion string, sopsDecrypter *decryptor.Decryptor) error {
 	pc, err := hr.getPulledChart(ctx)
 	if err != nil {
 		return err
 	}
+	err = hr.recordLock(ctx, pc)
+	if err != nil {
+		return err
+	}
 
 	outputPath, err := hr.GetFullOutputPath()
 	if err != nil {
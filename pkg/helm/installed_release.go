@@ -0,0 +1,141 @@
+package helm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const helmReleaseSecretType = "helm.sh/release.v1"
+
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// InstalledRelease is a Helm release that was installed by the Helm CLI, read from the Helm storage Secrets
+type InstalledRelease struct {
+	Release *release.Release
+
+	// StorageRefs contains the storage Secrets of all revisions of the release
+	StorageRefs []k8s2.ObjectRef
+}
+
+// LoadInstalledRelease reads the latest deployed revision of the given release. Only the default Secret storage
+// driver of Helm is supported.
+func LoadInstalledRelease(k *k8s.K8sCluster, namespace string, name string) (*InstalledRelease, error) {
+	secrets, _, err := k.ListObjects(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, namespace, map[string]string{
+		"owner": "helm",
+		"name":  name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &InstalledRelease{}
+	latestVersion := -1
+	var latest *uo.UnstructuredObject
+	for _, s := range secrets {
+		t, _, _ := s.GetNestedString("type")
+		if t != helmReleaseSecretType {
+			continue
+		}
+		ret.StorageRefs = append(ret.StorageRefs, s.GetK8sRef())
+
+		labels := s.GetK8sLabels()
+		if labels["status"] != release.StatusDeployed.String() {
+			continue
+		}
+		version, err := strconv.Atoi(labels["version"])
+		if err != nil {
+			continue
+		}
+		if version > latestVersion {
+			latestVersion = version
+			latest = s
+		}
+	}
+	if len(ret.StorageRefs) == 0 {
+		return nil, fmt.Errorf("helm release %s/%s not found. Only releases stored in Secrets are supported", namespace, name)
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("helm release %s/%s has no deployed revision", namespace, name)
+	}
+	sort.Slice(ret.StorageRefs, func(i, j int) bool {
+		return ret.StorageRefs[i].Name < ret.StorageRefs[j].Name
+	})
+
+	data, _, _ := latest.GetNestedString("data", "release")
+	ret.Release, err = decodeHelmRelease(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode helm release %s: %w", latest.GetK8sRef().String(), err)
+	}
+	return ret, nil
+}
+
+// decodeHelmRelease decodes the release from the data of the storage Secret. The Secret data itself is base64
+// encoded, containing the base64 encoded and optionally gzipped release JSON.
+func decodeHelmRelease(data string) (*release.Release, error) {
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	b, err = base64.StdEncoding.DecodeString(string(b))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(b, gzipMagic) {
+		b, err = utils.UncompressGzip(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var rel release.Release
+	err = json.Unmarshal(b, &rel)
+	if err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// ObjectRefs returns the refs of all objects of the release manifest. Hooks are not included, as Helm does not
+// manage them as part of the release. Namespaced objects without a namespace get the namespace of the release.
+func (r *InstalledRelease) ObjectRefs(k *k8s.K8sCluster) ([]k8s2.ObjectRef, error) {
+	manifests := releaseutil.SplitManifests(r.Release.Manifest)
+	keys := make([]string, 0, len(manifests))
+	for key := range manifests {
+		keys = append(keys, key)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	var ret []k8s2.ObjectRef
+	for _, key := range keys {
+		o, err := uo.FromString(manifests[key])
+		if err != nil {
+			return nil, err
+		}
+		if o.GetK8sGVK().Kind == "" {
+			continue
+		}
+		ref := o.GetK8sRef()
+		if ref.Namespace == "" {
+			namespaced := k.IsNamespaced(ref.GroupVersionKind())
+			if namespaced == nil {
+				return nil, fmt.Errorf("failed to determine if %s is namespaced", ref.GroupVersionKind().String())
+			}
+			if *namespaced {
+				ref.Namespace = r.Release.Namespace
+			}
+		}
+		ret = append(ret, ref)
+	}
+	return ret, nil
+}
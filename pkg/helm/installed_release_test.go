@@ -0,0 +1,45 @@
+package helm
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestDecodeHelmRelease(t *testing.T) {
+	rel := release.Release{
+		Name:      "test",
+		Namespace: "ns",
+		Version:   2,
+		Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "chart", Version: "1.2.3"}},
+		Config:    map[string]interface{}{"replicas": float64(2)},
+	}
+	b, err := json.Marshal(&rel)
+	assert.NoError(t, err)
+
+	encode := func(b []byte) string {
+		// helm encodes the release with base64, which is then encoded again as Secret data
+		s := base64.StdEncoding.EncodeToString(b)
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	gz, err := utils.CompressGzip(b, gzip.BestCompression)
+	assert.NoError(t, err)
+
+	for _, data := range []string{encode(b), encode(gz)} {
+		decoded, err := decodeHelmRelease(data)
+		assert.NoError(t, err)
+		assert.Equal(t, "test", decoded.Name)
+		assert.Equal(t, "1.2.3", decoded.Chart.Metadata.Version)
+		assert.Equal(t, rel.Config, decoded.Config)
+	}
+
+	_, err = decodeHelmRelease("invalid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+// Package i18n contains the message catalogs used for the human-readable text output of kluctl.
+//
+// Every user-facing string is identified by a MessageId. Messages with arguments must use explicit argument indexes
+// (e.g. "%[1]s"), so that translations are free to reorder arguments.
+//
+// Additional catalogs can be registered at build time by adding a file to this package (or to any other package that
+// is linked into the kluctl binary) that calls RegisterCatalog from an init function, e.g.:
+//
+//	func init() {
+//		i18n.RegisterCatalog("de", i18n.Catalog{
+//			i18n.MsgNewObjects: "Neue Objekte",
+//			...
+//		})
+//	}
+//
+// Registered catalogs must contain all message ids found in AllMessageIds. The catalog is then selectable via
+// --lang.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultLang is the language of the built-in catalog
+const DefaultLang = "en"
+
+// Catalog maps message ids to format strings
+type Catalog map[MessageId]string
+
+var (
+	catalogsMutex sync.Mutex
+	catalogs      = map[string]Catalog{}
+)
+
+// RegisterCatalog registers the catalog for the given language. Registering the same language twice replaces the
+// previous catalog.
+func RegisterCatalog(lang string, c Catalog) {
+	catalogsMutex.Lock()
+	defer catalogsMutex.Unlock()
+	catalogs[lang] = c
+}
+
+// GetCatalog returns the catalog for the given language. An empty language returns the default catalog.
+func GetCatalog(lang string) (Catalog, error) {
+	if lang == "" {
+		lang = DefaultLang
+	}
+	catalogsMutex.Lock()
+	defer catalogsMutex.Unlock()
+	c, ok := catalogs[lang]
+	if !ok {
+		return nil, fmt.Errorf("unknown language '%s', must be one of: %v", lang, languagesLocked())
+	}
+	return c, nil
+}
+
+// Languages returns the sorted list of all registered languages
+func Languages() []string {
+	catalogsMutex.Lock()
+	defer catalogsMutex.Unlock()
+	return languagesLocked()
+}
+
+func languagesLocked() []string {
+	var ret []string
+	for l := range catalogs {
+		ret = append(ret, l)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Sprintf formats the message with the given id. A nil catalog and messages missing in the catalog fall back to the
+// default catalog.
+func (c Catalog) Sprintf(id MessageId, args ...any) string {
+	f, ok := c[id]
+	if !ok {
+		f, ok = defaultCatalog[id]
+		if !ok {
+			return string(id)
+		}
+	}
+	return fmt.Sprintf(f, args...)
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var verbRegex = regexp.MustCompile(`%(\[(\d+)])?[a-zA-Z%]`)
+
+// argIndexes returns the explicit argument indexes used by the given format string. Verbs without explicit index are
+// returned as 0.
+func argIndexes(f string) []int {
+	var ret []int
+	for _, m := range verbRegex.FindAllStringSubmatch(f, -1) {
+		if m[0] == "%%" {
+			continue
+		}
+		i, _ := strconv.Atoi(m[2])
+		ret = append(ret, i)
+	}
+	return ret
+}
+
+// testArg formats as "x" for all verbs, so that messages with non-string verbs can be checked as well
+type testArg struct{}
+
+func (testArg) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte("x"))
+}
+
+func argCount(f string) int {
+	n := 0
+	for _, i := range argIndexes(f) {
+		if i > n {
+			n = i
+		}
+	}
+	return n
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	ids := AllMessageIds()
+	assert.NotEmpty(t, ids)
+
+	for _, lang := range Languages() {
+		c, err := GetCatalog(lang)
+		assert.NoError(t, err)
+
+		for _, id := range ids {
+			f, ok := c[id]
+			if !assert.Truef(t, ok, "catalog %s is missing message %s", lang, id) {
+				continue
+			}
+
+			expectedArgs := argCount(defaultCatalog[id])
+			for _, i := range argIndexes(f) {
+				assert.NotZerof(t, i, "message %s in catalog %s must use explicit argument indexes", id, lang)
+				assert.LessOrEqualf(t, i, expectedArgs, "message %s in catalog %s references an unknown argument", id, lang)
+			}
+
+			var args []any
+			for i := 0; i < expectedArgs; i++ {
+				args = append(args, testArg{})
+			}
+			s := c.Sprintf(id, args...)
+			assert.Falsef(t, strings.Contains(s, "%!"), "message %s in catalog %s is malformed: %s", id, lang, s)
+		}
+		for id := range c {
+			_, ok := defaultCatalog[id]
+			assert.Truef(t, ok, "catalog %s contains unknown message %s", lang, id)
+		}
+	}
+}
+
+func TestCatalogPositionalArgs(t *testing.T) {
+	RegisterCatalog("test", Catalog{
+		MsgMovedObject: "%[3]s <- %[2]s (%[1]s)",
+	})
+	defer func() {
+		catalogsMutex.Lock()
+		delete(catalogs, "test")
+		catalogsMutex.Unlock()
+	}()
+
+	c, err := GetCatalog("test")
+	assert.NoError(t, err)
+	assert.Equal(t, "to <- from (obj)", c.Sprintf(MsgMovedObject, "obj", "from", "to"))
+	// missing messages fall back to the default catalog
+	assert.Equal(t, "New objects", c.Sprintf(MsgNewObjects))
+
+	_, err = GetCatalog("unknown")
+	assert.ErrorContains(t, err, "unknown language 'unknown'")
+}
@@ -0,0 +1,145 @@
+package i18n
+
+import "sort"
+
+type MessageId string
+
+const (
+	MsgInvocation         MessageId = "result.invocation"
+	MsgPartialResult      MessageId = "result.partialResult"
+	MsgPartialNote        MessageId = "result.partialNote"
+	MsgRolledBack         MessageId = "result.rolledBack"
+	MsgNewObjects         MessageId = "result.newObjects"
+	MsgChangedObjects     MessageId = "result.changedObjects"
+	MsgIgnoredChanges     MessageId = "result.ignoredChanges"
+	MsgMovedObjects       MessageId = "result.movedObjects"
+	MsgMovedObject        MessageId = "result.movedObject"
+	MsgWouldDeleteObjects MessageId = "result.wouldDeleteObjects"
+	MsgDeletedObjects     MessageId = "result.deletedObjects"
+	MsgPhases             MessageId = "result.phases"
+	MsgAppliedHooks       MessageId = "result.appliedHooks"
+	MsgOrphanObjects      MessageId = "result.orphanObjects"
+	MsgTookOwnership      MessageId = "result.tookOwnership"
+	MsgTookOwnershipFrom  MessageId = "result.tookOwnershipFrom"
+	MsgTookOwnershipAnn   MessageId = "result.tookOwnershipRemovedAnnotation"
+	MsgCoOwnedObjects     MessageId = "result.coOwnedObjects"
+	MsgCoOwnedObject      MessageId = "result.coOwnedObject"
+	MsgFetches            MessageId = "result.fetches"
+	MsgTimings            MessageId = "result.timings"
+	MsgSlowestObjects     MessageId = "result.slowestObjects"
+	MsgRerunJobs          MessageId = "result.rerunJobs"
+	MsgRerunJob           MessageId = "result.rerunJob"
+	MsgResourceDelta      MessageId = "result.resourceDelta"
+	MsgOwnershipConflicts MessageId = "result.ownershipConflicts"
+	MsgOwnershipConflict  MessageId = "result.ownershipConflict"
+	MsgConflictWithTarget MessageId = "result.ownershipConflictWithTarget"
+	MsgWarnings           MessageId = "result.warnings"
+	MsgErrors             MessageId = "result.errors"
+	MsgDiffForObject      MessageId = "result.diffForObject"
+	MsgDiffColumnPath     MessageId = "result.diffColumnPath"
+	MsgDiffColumnDiff     MessageId = "result.diffColumnDiff"
+	MsgManifestForObject  MessageId = "result.manifestForObject"
+	MsgManifestTruncated  MessageId = "result.manifestTruncated"
+	MsgSummary            MessageId = "result.summary"
+	MsgNoChanges          MessageId = "result.noChanges"
+
+	MsgSummarySecurityRelevant MessageId = "result.summarySecurityRelevant"
+	MsgSecurityRelevantChanges MessageId = "result.securityRelevantChanges"
+	MsgSecurityRelevantNew     MessageId = "result.securityRelevantNew"
+	MsgSecurityRelevantDeleted MessageId = "result.securityRelevantDeleted"
+
+	MsgResourceDeltaColumnResource MessageId = "result.resourceDeltaColumnResource"
+	MsgResourceDeltaColumnRequests MessageId = "result.resourceDeltaColumnRequests"
+	MsgResourceDeltaColumnLimits   MessageId = "result.resourceDeltaColumnLimits"
+
+	MsgPhasePreDeployHooks  MessageId = "phase.preDeployHooks"
+	MsgPhaseApply           MessageId = "phase.apply"
+	MsgPhasePostDeployHooks MessageId = "phase.postDeployHooks"
+	MsgPhasePostDeployWaits MessageId = "phase.postDeployWaits"
+	MsgPhasePrune           MessageId = "phase.prune"
+
+	MsgValidatedFromResult     MessageId = "validate.validatedFromResult"
+	MsgValidationWarnings      MessageId = "validate.warnings"
+	MsgValidationErrors        MessageId = "validate.errors"
+	MsgValidationResults       MessageId = "validate.results"
+	MsgValidationColumnObject  MessageId = "validate.columnObject"
+	MsgValidationColumnMessage MessageId = "validate.columnMessage"
+)
+
+var defaultCatalog = Catalog{
+	MsgInvocation:         "Invocation: %[1]s",
+	MsgPartialResult:      "Partial result: %[1]s",
+	MsgPartialNote:        "Note: %[1]s",
+	MsgRolledBack:         "Rolled back to command result: %[1]s",
+	MsgNewObjects:         "New objects",
+	MsgChangedObjects:     "Changed objects",
+	MsgIgnoredChanges:     "Ignored changes",
+	MsgMovedObjects:       "Moved objects",
+	MsgMovedObject:        "%[1]s (from: %[2]s, to: %[3]s)",
+	MsgWouldDeleteObjects: "Would delete objects",
+	MsgDeletedObjects:     "Deleted objects",
+	MsgPhases:             "Phases",
+	MsgAppliedHooks:       "Applied hooks",
+	MsgOrphanObjects:      "Orphan objects",
+	MsgTookOwnership:      "Took ownership of objects",
+	MsgTookOwnershipFrom:  "%[1]s (from: %[2]s)",
+	MsgTookOwnershipAnn:   "%[1]s (removed %[2]s annotation)",
+	MsgCoOwnedObjects:     "Objects still co-owned by other field managers",
+	MsgCoOwnedObject:      "%[1]s (co-owners: %[2]s)",
+	MsgFetches:            "Fetches",
+	MsgTimings:            "Timings",
+	MsgSlowestObjects:     "Slowest objects",
+	MsgRerunJobs:          "Re-run jobs",
+	MsgRerunJob:           "%[1]s (reason: %[2]s, status: %[3]s)",
+	MsgResourceDelta:      "Resource delta",
+	MsgOwnershipConflicts: "Ownership conflicts",
+	MsgOwnershipConflict:  "%[1]s (discriminator: %[2]s)",
+	MsgConflictWithTarget: "%[1]s (target: %[3]s, discriminator: %[2]s, command result: %[4]s)",
+	MsgWarnings:           "Warnings",
+	MsgErrors:             "Errors",
+	MsgDiffForObject:      "Diff for object %[1]s",
+	MsgDiffColumnPath:     "Path",
+	MsgDiffColumnDiff:     "Diff",
+	MsgManifestForObject:  "Manifest of object %[1]s",
+	MsgManifestTruncated:  "(truncated, %[1]d more lines — use --max-object-yaml-lines or --full to show more)",
+	MsgSummary:            "Summary: %[1]d new, %[2]d changed, %[3]d deleted, %[4]d orphan, %[5]d hooks applied, %[6]d errors, %[7]d warnings",
+	MsgNoChanges:          "No changes",
+
+	MsgSummarySecurityRelevant: "%[1]d security-relevant",
+	MsgSecurityRelevantChanges: "Security-relevant changes",
+	MsgSecurityRelevantNew:     "%[1]s (new)",
+	MsgSecurityRelevantDeleted: "%[1]s (deleted)",
+
+	MsgResourceDeltaColumnResource: "Resource",
+	MsgResourceDeltaColumnRequests: "Requests",
+	MsgResourceDeltaColumnLimits:   "Limits",
+
+	MsgPhasePreDeployHooks:  "Pre-deploy hooks",
+	MsgPhaseApply:           "Apply",
+	MsgPhasePostDeployHooks: "Post-deploy hooks",
+	MsgPhasePostDeployWaits: "Post-deploy waits",
+	MsgPhasePrune:           "Prune",
+
+	MsgValidatedFromResult:     "Validated objects from command result %[1]s",
+	MsgValidationWarnings:      "Validation Warnings",
+	MsgValidationErrors:        "Validation Errors",
+	MsgValidationResults:       "Results",
+	MsgValidationColumnObject:  "Object",
+	MsgValidationColumnMessage: "Message",
+}
+
+func init() {
+	RegisterCatalog(DefaultLang, defaultCatalog)
+}
+
+// AllMessageIds returns all message ids that must be covered by a catalog
+func AllMessageIds() []MessageId {
+	var ret []MessageId
+	for id := range defaultCatalog {
+		ret = append(ret, id)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i] < ret[j]
+	})
+	return ret
+}
@@ -0,0 +1,119 @@
+package k8s
+
+import (
+	"context"
+	errors2 "errors"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterIdSource describes the strategy that was used to determine a cluster ID
+type ClusterIdSource string
+
+const (
+	// ClusterIdSourceTarget is used when the cluster ID was configured in the target
+	ClusterIdSourceTarget ClusterIdSource = "target"
+	// ClusterIdSourceKubeSystem is used when the UID of the kube-system namespace was used
+	ClusterIdSourceKubeSystem ClusterIdSource = "kubeSystem"
+	// ClusterIdSourceConfigMap is used when the cluster ID was read from the ClusterIdConfigMapName ConfigMap
+	ClusterIdSourceConfigMap ClusterIdSource = "configMap"
+	// ClusterIdSourceApiServer is used when the cluster ID was derived from the API server URL and CA
+	ClusterIdSourceApiServer ClusterIdSource = "apiServer"
+)
+
+const (
+	// ClusterIdConfigMapNamespace and ClusterIdConfigMapName define the ConfigMap that can be created by cluster
+	// admins to provide a cluster ID to users that are not allowed to read the kube-system namespace
+	ClusterIdConfigMapNamespace = "kube-public"
+	ClusterIdConfigMapName      = "kluctl-cluster-id"
+	ClusterIdConfigMapKey       = "clusterId"
+)
+
+// ErrClusterIdUnavailable is returned when all strategies to determine the cluster ID failed
+var ErrClusterIdUnavailable = errors2.New("all strategies to determine the cluster ID failed")
+
+type clusterIdCache struct {
+	id     string
+	source ClusterIdSource
+	err    error
+	done   bool
+}
+
+// DetermineClusterId determines the cluster ID by trying the following strategies in order:
+// 1. The UID of the kube-system namespace, see GetClusterId
+// 2. The ClusterIdConfigMapKey value of the ClusterIdConfigMapName ConfigMap in the ClusterIdConfigMapNamespace
+// 3. A hash of the API server URL and the CA of the cluster
+// The next strategy is only tried if the previous one failed due to missing permissions or a missing object, so that
+// temporary errors don't lead to a different cluster ID. An error is returned if all strategies fail.
+func DetermineClusterId(ctx context.Context, c client.Client, config *rest.Config) (string, ClusterIdSource, error) {
+	var errs *multierror.Error
+
+	id, err := GetClusterId(ctx, c)
+	if err == nil {
+		return id, ClusterIdSourceKubeSystem, nil
+	}
+	if !isClusterIdFallbackError(err) {
+		return "", "", err
+	}
+	errs = multierror.Append(errs, fmt.Errorf("failed to read kube-system namespace: %w", err))
+
+	id, err = getClusterIdFromConfigMap(ctx, c)
+	if err == nil {
+		return id, ClusterIdSourceConfigMap, nil
+	}
+	if !isClusterIdFallbackError(err) {
+		return "", "", err
+	}
+	errs = multierror.Append(errs, fmt.Errorf("failed to read %s/%s ConfigMap: %w", ClusterIdConfigMapNamespace, ClusterIdConfigMapName, err))
+
+	id, err = BuildApiServerClusterId(config)
+	if err == nil {
+		return id, ClusterIdSourceApiServer, nil
+	}
+	errs = multierror.Append(errs, err)
+
+	return "", "", fmt.Errorf("%w: %s", ErrClusterIdUnavailable, errs.Error())
+}
+
+func isClusterIdFallbackError(err error) bool {
+	return errors.IsForbidden(err) || errors.IsNotFound(err) || errors.IsUnauthorized(err) || errors2.Is(err, errEmptyClusterId)
+}
+
+var errEmptyClusterId = fmt.Errorf("empty cluster ID")
+
+func getClusterIdFromConfigMap(ctx context.Context, c client.Client) (string, error) {
+	var cm corev1.ConfigMap
+	err := c.Get(ctx, client.ObjectKey{Namespace: ClusterIdConfigMapNamespace, Name: ClusterIdConfigMapName}, &cm)
+	if err != nil {
+		return "", err
+	}
+	id := cm.Data[ClusterIdConfigMapKey]
+	if id == "" {
+		return "", fmt.Errorf("%w in %s key", errEmptyClusterId, ClusterIdConfigMapKey)
+	}
+	return id, nil
+}
+
+// BuildApiServerClusterId derives a cluster ID from the API server URL and the CA used to verify it. The same cluster
+// reached via different URLs will result in different IDs.
+func BuildApiServerClusterId(config *rest.Config) (string, error) {
+	if config == nil || config.Host == "" {
+		return "", fmt.Errorf("no API server URL available to derive cluster ID from")
+	}
+	ca := config.TLSClientConfig.CAData
+	if len(ca) == 0 && config.TLSClientConfig.CAFile != "" {
+		b, err := os.ReadFile(config.TLSClientConfig.CAFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read CA file to derive cluster ID from: %w", err)
+		}
+		ca = b
+	}
+	return "api-" + utils.Sha256String(config.Host + "\n" + utils.Sha256Bytes(ca))[:32], nil
+}
@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newClusterIdTestClient(forbidNamespaces bool, objs ...client.Object) client.Client {
+	forbidden := func(name string) error {
+		return errors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, name, fmt.Errorf("forbidden"))
+	}
+	return fake.NewClientBuilder().WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*corev1.Namespace); ok && forbidNamespaces {
+				return forbidden(key.Name)
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if forbidNamespaces {
+				return forbidden("")
+			}
+			return c.List(ctx, list, opts...)
+		},
+	}).Build()
+}
+
+func TestDetermineClusterId(t *testing.T) {
+	ctx := context.Background()
+	config := &rest.Config{
+		Host: "https://cluster.example.com:6443",
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData: []byte("ca"),
+		},
+	}
+	kubeSystem := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "kube-system-uid"}}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ClusterIdConfigMapName, Namespace: ClusterIdConfigMapNamespace},
+		Data:       map[string]string{ClusterIdConfigMapKey: "cm-id"},
+	}
+
+	id, source, err := DetermineClusterId(ctx, newClusterIdTestClient(false, kubeSystem, cm), config)
+	assert.NoError(t, err)
+	assert.Equal(t, "kube-system-uid", id)
+	assert.Equal(t, ClusterIdSourceKubeSystem, source)
+
+	id, source, err = DetermineClusterId(ctx, newClusterIdTestClient(true, kubeSystem, cm), config)
+	assert.NoError(t, err)
+	assert.Equal(t, "cm-id", id)
+	assert.Equal(t, ClusterIdSourceConfigMap, source)
+
+	id, source, err = DetermineClusterId(ctx, newClusterIdTestClient(true, kubeSystem), config)
+	assert.NoError(t, err)
+	assert.Equal(t, ClusterIdSourceApiServer, source)
+	apiServerId, err := BuildApiServerClusterId(config)
+	assert.NoError(t, err)
+	assert.Equal(t, apiServerId, id)
+
+	_, _, err = DetermineClusterId(ctx, newClusterIdTestClient(true, kubeSystem), &rest.Config{})
+	assert.ErrorIs(t, err, ErrClusterIdUnavailable)
+}
+
+func TestBuildApiServerClusterId(t *testing.T) {
+	id1, err := BuildApiServerClusterId(&rest.Config{Host: "https://a", TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca1")}})
+	assert.NoError(t, err)
+	id2, err := BuildApiServerClusterId(&rest.Config{Host: "https://a", TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca2")}})
+	assert.NoError(t, err)
+	id3, err := BuildApiServerClusterId(&rest.Config{Host: "https://b", TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca1")}})
+	assert.NoError(t, err)
+	assert.NotEqual(t, id1, id2)
+	assert.NotEqual(t, id1, id3)
+
+	id4, err := BuildApiServerClusterId(&rest.Config{Host: "https://a", TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca1")}})
+	assert.NoError(t, err)
+	assert.Equal(t, id1, id4)
+}
@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/discovery"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -34,6 +35,9 @@ type K8sCluster struct {
 
 	DryRun bool
 
+	// ReadOnly prevents ReadWrite from disabling dry-run
+	ReadOnly bool
+
 	config         *rest.Config
 	discovery      discovery.DiscoveryInterface
 	mapper         meta.RESTMapper
@@ -45,6 +49,9 @@ type K8sCluster struct {
 
 	crdCache      map[k8s.ObjectRef]any
 	crdCacheMutex *sync.Mutex
+
+	clusterIdCache *clusterIdCache
+	clusterIdMutex *sync.Mutex
 }
 
 func NewK8sCluster(ctx context.Context,
@@ -63,6 +70,8 @@ func NewK8sCluster(ctx context.Context,
 		discoveryMutex: &sync.Mutex{},
 		crdCache:       map[k8s.ObjectRef]any{},
 		crdCacheMutex:  &sync.Mutex{},
+		clusterIdCache: &clusterIdCache{},
+		clusterIdMutex: &sync.Mutex{},
 	}
 
 	k.clients, err = newK8sClients(k, 16)
@@ -81,7 +90,7 @@ func NewK8sCluster(ctx context.Context,
 
 func (k *K8sCluster) ReadWrite() *K8sCluster {
 	k2 := *k
-	k2.DryRun = false
+	k2.DryRun = k.ReadOnly
 	return &k2
 }
 
@@ -98,6 +107,29 @@ func (k *K8sCluster) GetClusterId() (string, error) {
 	return clusterId, nil
 }
 
+// DetermineClusterId returns override if it is set and otherwise determines the cluster ID via DetermineClusterId. The
+// determined ID is cached, so that the strategies are only tried once per cluster.
+func (k *K8sCluster) DetermineClusterId(override string) (string, ClusterIdSource, error) {
+	if override != "" {
+		return override, ClusterIdSourceTarget, nil
+	}
+
+	k.clusterIdMutex.Lock()
+	defer k.clusterIdMutex.Unlock()
+	cache := k.clusterIdCache
+	if !cache.done {
+		_, err := k.clients.withCClientFromPool(k.ctx, true, func(c client.Client) error {
+			cache.id, cache.source, cache.err = DetermineClusterId(k.ctx, c, k.config)
+			return nil
+		})
+		if err != nil {
+			return "", "", err
+		}
+		cache.done = true
+	}
+	return cache.id, cache.source, cache.err
+}
+
 func (k *K8sCluster) doList(l client.ObjectList, namespace string, labels map[string]string) ([]*uo.UnstructuredObject, []ApiWarning, error) {
 	apiWarnings, err := k.clients.withCClientFromPool(k.ctx, true, func(c client.Client) error {
 		return c.List(k.ctx, l, client.InNamespace(namespace), client.MatchingLabels(labels))
@@ -360,6 +392,16 @@ func (k *K8sCluster) ApplyObject(o *uo.UnstructuredObject, options PatchOptions)
 	return uo.FromUnstructured(obj), apiWarnings, nil
 }
 
+// JsonPatchObject applies the given JSON patch to the object
+func (k *K8sCluster) JsonPatchObject(o *uo.UnstructuredObject, jsonPatch []byte, options PatchOptions) (*uo.UnstructuredObject, []ApiWarning, error) {
+	obj := o.Clone().ToUnstructured()
+	apiWarnings, err := k.doPatch(o.GetK8sRef(), obj, client.RawPatch(types.JSONPatchType, jsonPatch), options)
+	if err != nil {
+		return nil, apiWarnings, err
+	}
+	return uo.FromUnstructured(obj), apiWarnings, nil
+}
+
 type UpdateOptions struct {
 	ForceDryRun bool
 }
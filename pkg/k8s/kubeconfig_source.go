@@ -0,0 +1,263 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// SecretGetter returns the Secret with the given namespace and name. kubeContext specifies the context of the cluster
+// that holds the Secret, nil means the current context.
+type SecretGetter func(ctx context.Context, kubeContext *string, namespace string, name string) (*corev1.Secret, error)
+
+// KubeconfigResolver resolves the kubeconfig sources of targets. It is used by the CLI and the controller, and caches
+// resolved kubeconfigs for its lifetime, which is expected to be a single invocation/reconciliation.
+type KubeconfigResolver struct {
+	getSecret       SecretGetter
+	inClusterConfig func() (*rest.Config, error)
+
+	mutex sync.Mutex
+	cache map[string]*api.Config
+}
+
+func NewKubeconfigResolver(getSecret SecretGetter) *KubeconfigResolver {
+	return &KubeconfigResolver{
+		getSecret:       getSecret,
+		inClusterConfig: rest.InClusterConfig,
+		cache:           map[string]*api.Config{},
+	}
+}
+
+func describeTarget(targetName string) string {
+	if targetName == "" {
+		return "the no-name target"
+	}
+	return fmt.Sprintf("target '%s'", targetName)
+}
+
+// Resolve loads the kubeconfig from the given source. Errors name the target and the source type.
+func (r *KubeconfigResolver) Resolve(ctx context.Context, targetName string, src *types.KubeconfigSource) (*api.Config, error) {
+	sourceType := src.SourceType()
+	if sourceType == "" {
+		return nil, fmt.Errorf("invalid kubeconfigFrom for %s: exactly one of file, inCluster, secret or exec must be set", describeTarget(targetName))
+	}
+
+	cacheKey, err := json.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if c, ok := r.cache[string(cacheKey)]; ok {
+		return c.DeepCopy(), nil
+	}
+
+	c, err := r.load(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig for %s from %s source: %w", describeTarget(targetName), sourceType, err)
+	}
+	r.cache[string(cacheKey)] = c
+	return c.DeepCopy(), nil
+}
+
+func (r *KubeconfigResolver) load(ctx context.Context, src *types.KubeconfigSource) (*api.Config, error) {
+	switch {
+	case src.File != nil:
+		return clientcmd.LoadFromFile(*src.File)
+	case src.InCluster:
+		restConfig, err := r.inClusterConfig()
+		if err != nil {
+			return nil, err
+		}
+		return RestConfigToKubeconfig(restConfig), nil
+	case src.Secret != nil:
+		if r.getSecret == nil {
+			return nil, fmt.Errorf("reading kubeconfigs from secrets is not supported here")
+		}
+		secret, err := r.getSecret(ctx, src.Secret.Context, src.Secret.Namespace, src.Secret.Name)
+		if err != nil {
+			return nil, err
+		}
+		b, err := KubeconfigFromSecret(secret, src.Secret.Key)
+		if err != nil {
+			return nil, err
+		}
+		return clientcmd.Load(b)
+	case src.Exec != nil:
+		return loadKubeconfigFromExec(ctx, src.Exec)
+	}
+	return nil, fmt.Errorf("no kubeconfig source specified")
+}
+
+func loadKubeconfigFromExec(ctx context.Context, src *types.KubeconfigExecSource) (*api.Config, error) {
+	cmd := exec.CommandContext(ctx, src.Command[0], src.Command[1:]...)
+	cmd.Env = os.Environ()
+	for k, v := range src.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("command failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return clientcmd.Load(stdout.Bytes())
+}
+
+// KubeconfigFromSecret returns the kubeconfig stored in the given Secret. If key is empty, the 'value' and 'value.yaml'
+// keys are tried.
+func KubeconfigFromSecret(secret *corev1.Secret, key string) ([]byte, error) {
+	name := fmt.Sprintf("%s/%s", secret.Namespace, secret.Name)
+	switch {
+	case key != "":
+		b := secret.Data[key]
+		if b == nil {
+			return nil, fmt.Errorf("KubeConfig secret '%s' does not contain a '%s' key with a kubeconfig", name, key)
+		}
+		return b, nil
+	case secret.Data["value"] != nil:
+		return secret.Data["value"], nil
+	case secret.Data["value.yaml"] != nil:
+		return secret.Data["value.yaml"], nil
+	default:
+		return nil, fmt.Errorf("KubeConfig secret '%s' does not contain a 'value' key with a kubeconfig", name)
+	}
+}
+
+// DescribeKubeconfigSource returns a non-sensitive description of the given source, suitable to be stored in command
+// results. File paths and command arguments are omitted, as these might contain user names or credentials.
+func DescribeKubeconfigSource(src *types.KubeconfigSource) string {
+	switch {
+	case src == nil:
+		return ""
+	case src.File != nil:
+		return "file"
+	case src.InCluster:
+		return "inCluster"
+	case src.Secret != nil:
+		s := fmt.Sprintf("secret %s/%s", src.Secret.Namespace, src.Secret.Name)
+		if src.Secret.Context != nil {
+			s += fmt.Sprintf(" (context %s)", *src.Secret.Context)
+		}
+		return s
+	case src.Exec != nil:
+		return fmt.Sprintf("exec %s", filepath.Base(src.Exec.Command[0]))
+	}
+	return ""
+}
+
+// ParseKubeconfigSource parses the --kubeconfig-from command line syntax, which is one of 'file:<path>', 'in-cluster',
+// 'secret:[<context>/]<namespace>/<name>[:<key>]' or 'exec:<command> [<args>...]'.
+func ParseKubeconfigSource(s string) (*types.KubeconfigSource, error) {
+	t, v, _ := strings.Cut(s, ":")
+	switch t {
+	case "file":
+		if v == "" {
+			return nil, fmt.Errorf("missing path in kubeconfig source '%s'", s)
+		}
+		return &types.KubeconfigSource{File: &v}, nil
+	case "in-cluster":
+		if v != "" {
+			return nil, fmt.Errorf("unexpected value in kubeconfig source '%s'", s)
+		}
+		return &types.KubeconfigSource{InCluster: true}, nil
+	case "secret":
+		ref, key, _ := strings.Cut(v, ":")
+		parts := strings.Split(ref, "/")
+		ret := &types.KubeconfigSecretSource{Key: key}
+		switch len(parts) {
+		case 2:
+			ret.Namespace, ret.Name = parts[0], parts[1]
+		case 3:
+			ret.Context = &parts[0]
+			ret.Namespace, ret.Name = parts[1], parts[2]
+		default:
+			return nil, fmt.Errorf("invalid secret reference in kubeconfig source '%s', expected [<context>/]<namespace>/<name>[:<key>]", s)
+		}
+		if ret.Namespace == "" || ret.Name == "" {
+			return nil, fmt.Errorf("invalid secret reference in kubeconfig source '%s', expected [<context>/]<namespace>/<name>[:<key>]", s)
+		}
+		return &types.KubeconfigSource{Secret: ret}, nil
+	case "exec":
+		command := strings.Fields(v)
+		if len(command) == 0 {
+			return nil, fmt.Errorf("missing command in kubeconfig source '%s'", s)
+		}
+		return &types.KubeconfigSource{Exec: &types.KubeconfigExecSource{Command: command}}, nil
+	}
+	return nil, fmt.Errorf("invalid kubeconfig source '%s', must be one of file:<path>, in-cluster, secret:<ref> or exec:<command>", s)
+}
+
+// RestConfigToKubeconfig converts the given rest config into a kubeconfig with a single context named 'default'
+func RestConfigToKubeconfig(restConfig *rest.Config) *api.Config {
+	kubeConfig := api.NewConfig()
+	cluster := api.NewCluster()
+	cluster.Server = restConfig.Host
+	cluster.CertificateAuthority = restConfig.TLSClientConfig.CAFile
+	cluster.CertificateAuthorityData = restConfig.TLSClientConfig.CAData
+	cluster.InsecureSkipTLSVerify = restConfig.TLSClientConfig.Insecure
+	kubeConfig.Clusters["default"] = cluster
+
+	user := api.NewAuthInfo()
+	user.ClientKey = restConfig.KeyFile
+	user.ClientKeyData = restConfig.KeyData
+	user.ClientCertificate = restConfig.CertFile
+	user.ClientCertificateData = restConfig.CertData
+	user.TokenFile = restConfig.BearerTokenFile
+	user.Token = restConfig.BearerToken
+	user.Impersonate = restConfig.Impersonate.UserName
+	user.ImpersonateUID = restConfig.Impersonate.UID
+	user.ImpersonateUserExtra = restConfig.Impersonate.Extra
+	user.ImpersonateGroups = restConfig.Impersonate.Groups
+	user.Username = restConfig.Username
+	user.Password = restConfig.Password
+	user.AuthProvider = restConfig.AuthProvider
+	user.Exec = restConfig.ExecProvider
+	kubeConfig.AuthInfos["default"] = user
+
+	kctx := api.NewContext()
+	kctx.Cluster = "default"
+	kctx.AuthInfo = "default"
+	kubeConfig.Contexts["default"] = kctx
+	kubeConfig.CurrentContext = "default"
+
+	return kubeConfig
+}
+
+// ClientConfigFromKubeconfig builds the rest config and raw kubeconfig for the given context of kubeConfig. If context
+// is nil, the current context of kubeConfig is used.
+func ClientConfigFromKubeconfig(kubeConfig *api.Config, context *string) (*rest.Config, *api.Config, error) {
+	configOverrides := &clientcmd.ConfigOverrides{}
+	if context != nil {
+		configOverrides.CurrentContext = *context
+	}
+	clientConfig := clientcmd.NewDefaultClientConfig(*kubeConfig, configOverrides)
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if context != nil {
+		rawConfig.CurrentContext = *context
+	}
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	return restConfig, &rawConfig, nil
+}
@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestParseKubeconfigSource(t *testing.T) {
+	ctx := "mgmt"
+	tests := []struct {
+		s       string
+		want    *types.KubeconfigSource
+		wantErr bool
+	}{
+		{s: "file:/tmp/kubeconfig", want: &types.KubeconfigSource{File: &[]string{"/tmp/kubeconfig"}[0]}},
+		{s: "file:", wantErr: true},
+		{s: "in-cluster", want: &types.KubeconfigSource{InCluster: true}},
+		{s: "in-cluster:x", wantErr: true},
+		{s: "secret:ns/name", want: &types.KubeconfigSource{Secret: &types.KubeconfigSecretSource{Namespace: "ns", Name: "name"}}},
+		{s: "secret:mgmt/ns/name:key", want: &types.KubeconfigSource{Secret: &types.KubeconfigSecretSource{Context: &ctx, Namespace: "ns", Name: "name", Key: "key"}}},
+		{s: "secret:name", wantErr: true},
+		{s: "secret:ns/", wantErr: true},
+		{s: "exec:helper --cluster prod", want: &types.KubeconfigSource{Exec: &types.KubeconfigExecSource{Command: []string{"helper", "--cluster", "prod"}}}},
+		{s: "exec:", wantErr: true},
+		{s: "unknown:x", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.s, func(t *testing.T) {
+			src, err := ParseKubeconfigSource(tc.s)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, src)
+		})
+	}
+}
+
+func TestDescribeKubeconfigSource(t *testing.T) {
+	ctx := "mgmt"
+	file := "/home/user/secret/kubeconfig"
+	assert.Equal(t, "", DescribeKubeconfigSource(nil))
+	assert.Equal(t, "file", DescribeKubeconfigSource(&types.KubeconfigSource{File: &file}))
+	assert.Equal(t, "inCluster", DescribeKubeconfigSource(&types.KubeconfigSource{InCluster: true}))
+	assert.Equal(t, "secret ns/name (context mgmt)", DescribeKubeconfigSource(&types.KubeconfigSource{Secret: &types.KubeconfigSecretSource{Context: &ctx, Namespace: "ns", Name: "name"}}))
+	assert.Equal(t, "exec helper", DescribeKubeconfigSource(&types.KubeconfigSource{Exec: &types.KubeconfigExecSource{Command: []string{"/usr/bin/helper", "--token", "xyz"}}}))
+}
+
+func TestKubeconfigResolver(t *testing.T) {
+	kubeconfig, err := clientcmd.Write(*RestConfigToKubeconfig(&rest.Config{Host: "https://example.com"}))
+	assert.NoError(t, err)
+
+	calls := 0
+	r := NewKubeconfigResolver(func(ctx context.Context, kubeContext *string, namespace string, name string) (*corev1.Secret, error) {
+		calls++
+		if name == "missing" {
+			return nil, fmt.Errorf("not found")
+		}
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Data:       map[string][]byte{"value": kubeconfig},
+		}, nil
+	})
+
+	src := &types.KubeconfigSource{Secret: &types.KubeconfigSecretSource{Namespace: "ns", Name: "name"}}
+	c, err := r.Resolve(context.Background(), "prod", src)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com", c.Clusters["default"].Server)
+
+	// the second call is served from the cache
+	_, err = r.Resolve(context.Background(), "prod", src)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	_, err = r.Resolve(context.Background(), "prod", &types.KubeconfigSource{Secret: &types.KubeconfigSecretSource{Namespace: "ns", Name: "missing"}})
+	assert.EqualError(t, err, "failed to load kubeconfig for target 'prod' from secret source: not found")
+
+	_, err = r.Resolve(context.Background(), "prod", &types.KubeconfigSource{Secret: &types.KubeconfigSecretSource{Namespace: "ns", Name: "name", Key: "other"}})
+	assert.ErrorContains(t, err, "does not contain a 'other' key")
+
+	_, err = r.Resolve(context.Background(), "prod", &types.KubeconfigSource{InCluster: true, Secret: src.Secret})
+	assert.EqualError(t, err, "invalid kubeconfigFrom for target 'prod': exactly one of file, inCluster, secret or exec must be set")
+
+	_, err = r.Resolve(context.Background(), "", &types.KubeconfigSource{Exec: &types.KubeconfigExecSource{Command: []string{"false"}}})
+	assert.ErrorContains(t, err, "failed to load kubeconfig for the no-name target from exec source: command failed")
+}
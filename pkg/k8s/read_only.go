@@ -0,0 +1,64 @@
+package k8s
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// readOnlyAllowedCreates contains resources which are created via POST but never modify the cluster state
+var readOnlyAllowedCreates = []string{
+	"/apis/authorization.k8s.io/v1/selfsubjectaccessreviews",
+	"/apis/authorization.k8s.io/v1/selfsubjectrulesreviews",
+	"/apis/authentication.k8s.io/v1/selfsubjectreviews",
+}
+
+type ReadOnlyError struct {
+	Method string
+	Path   string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("refusing to perform %s request to %s in read-only mode", e.Method, e.Path)
+}
+
+// WrapReadOnly returns a copy of config which rejects all requests that might modify the cluster. Rejected requests
+// fail with a ReadOnlyError before they are sent to the cluster. Server-side dry-run requests are still allowed.
+func WrapReadOnly(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &readOnlyRoundTripper{rt: rt}
+	})
+	return config
+}
+
+type readOnlyRoundTripper struct {
+	rt http.RoundTripper
+}
+
+func (r *readOnlyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isReadOnlyRequest(req) {
+		return nil, &ReadOnlyError{Method: req.Method, Path: req.URL.Path}
+	}
+	return r.rt.RoundTrip(req)
+}
+
+func isReadOnlyRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if req.URL.Query().Get("dryRun") == "All" {
+		return true
+	}
+	if req.Method == http.MethodPost {
+		for _, p := range readOnlyAllowedCreates {
+			if strings.HasSuffix(req.URL.Path, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
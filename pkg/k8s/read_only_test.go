@@ -0,0 +1,50 @@
+package k8s
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+)
+
+func TestWrapReadOnly(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.String())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := WrapReadOnly(&rest.Config{Host: server.URL})
+	rt, err := rest.TransportFor(config)
+	assert.NoError(t, err)
+
+	do := func(method string, path string) error {
+		req, err := http.NewRequest(method, server.URL+path, nil)
+		assert.NoError(t, err)
+		resp, err := rt.RoundTrip(req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	assert.NoError(t, do(http.MethodGet, "/api/v1/namespaces/default/configmaps"))
+	assert.NoError(t, do(http.MethodPatch, "/api/v1/namespaces/default/configmaps/cm?dryRun=All"))
+	assert.NoError(t, do(http.MethodPost, "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews"))
+
+	for _, m := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		err := do(m, "/api/v1/namespaces/default/configmaps/cm")
+		var roErr *ReadOnlyError
+		assert.True(t, errors.As(err, &roErr), "expected read-only error for %s", m)
+	}
+
+	assert.Equal(t, []string{
+		"GET /api/v1/namespaces/default/configmaps",
+		"PATCH /api/v1/namespaces/default/configmaps/cm?dryRun=All",
+		"POST /apis/authorization.k8s.io/v1/selfsubjectaccessreviews",
+	}, requests)
+}
@@ -2,11 +2,15 @@ package kluctl_project
 
 import (
 	"fmt"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
 	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/sops"
+	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -27,31 +31,78 @@ func ParseArgs(argsList []string) (map[string]string, error) {
 	return args, nil
 }
 
-func ConvertArgsToVars(args map[string]string, allowLoadFromFiles bool) (*uo.UnstructuredObject, error) {
+// ConvertArgsToVars converts the parsed args into vars. If allowLoadFromFiles is true, values starting with @ are
+// loaded from the specified file, which is decrypted if it is sops encrypted. The names of all args that were loaded
+// from encrypted files are returned as well, so that they can be treated as sensitive.
+func ConvertArgsToVars(args map[string]string, allowLoadFromFiles bool, decrypter *decryptor.Decryptor) (*uo.UnstructuredObject, []string, error) {
 	vars := uo.New()
+	var sensitiveArgs []string
 	for n, v := range args {
 		if allowLoadFromFiles && strings.HasPrefix(v, "@") {
-			b, err := os.ReadFile(v[1:])
+			b, encrypted, err := LoadArgsFile(v[1:], decrypter)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			v = string(b)
+			if encrypted {
+				sensitiveArgs = append(sensitiveArgs, n)
+			}
 		} else if strings.HasPrefix(v, "\\@") {
 			v = v[1:]
 		}
 
-		var p []interface{}
-		for _, x := range strings.Split(n, ".") {
-			p = append(p, x)
-		}
 		var j any
 		err := yaml.ReadYamlString(v, &j)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		_ = vars.SetNestedField(j, p...)
+		_ = vars.SetNestedField(j, argNameToPath(n)...)
+	}
+	sort.Strings(sensitiveArgs)
+	return vars, sensitiveArgs, nil
+}
+
+// LoadArgsFile reads an args file and decrypts it in case it is sops encrypted. The returned bool is true if the file
+// was encrypted.
+func LoadArgsFile(path string, decrypter *decryptor.Decryptor) ([]byte, bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	format := formats.FormatForPath(path)
+	if format == formats.Binary {
+		// args files are always treated as yaml
+		format = formats.Yaml
+	}
+	decrypted, encrypted, err := sops.MaybeDecrypt(decrypter, b, format, format)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decrypt args file %s: %w", path, err)
 	}
-	return vars, nil
+	return decrypted, encrypted, nil
+}
+
+func argNameToPath(name string) []interface{} {
+	var p []interface{}
+	for _, x := range strings.Split(name, ".") {
+		p = append(p, x)
+	}
+	return p
+}
+
+// RedactArgs returns a copy of args with the values of all sensitive args replaced
+func RedactArgs(args *uo.UnstructuredObject, sensitiveArgs []string) *uo.UnstructuredObject {
+	if args == nil || len(sensitiveArgs) == 0 {
+		return args
+	}
+	ret := args.Clone()
+	for _, n := range sensitiveArgs {
+		p := argNameToPath(n)
+		if _, found, _ := ret.GetNestedField(p...); found {
+			_ = ret.SetNestedField("*****", p...)
+		}
+	}
+	return ret
 }
 
 func LoadDefaultArgs(args []types.DeploymentArg, deployArgs *uo.UnstructuredObject) error {
@@ -82,11 +133,7 @@ func LoadDefaultArgs(args []types.DeploymentArg, deployArgs *uo.UnstructuredObje
 
 func checkRequiredArgs(argsDef []types.DeploymentArg, args *uo.UnstructuredObject) error {
 	for _, a := range argsDef {
-		var p []interface{}
-		for _, x := range strings.Split(a.Name, ".") {
-			p = append(p, x)
-		}
-		_, found, _ := args.GetNestedField(p...)
+		_, found, _ := args.GetNestedField(argNameToPath(a.Name)...)
 		if !found {
 			if a.Default == nil {
 				return fmt.Errorf("required argument %s not set", a.Name)
@@ -0,0 +1,117 @@
+package kluctl_project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getsops/sops/v3/age"
+	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars/sops_test_resources"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeEncryptedArgsFile(t *testing.T, modify func(s string) string) string {
+	b, err := sops_test_resources.TestResources.ReadFile("test.yaml")
+	assert.NoError(t, err)
+	s := string(b)
+	if modify != nil {
+		s = modify(s)
+	}
+	p := filepath.Join(t.TempDir(), "args.yaml")
+	assert.NoError(t, os.WriteFile(p, []byte(s), 0o600))
+	return p
+}
+
+func newTestDecryptor(t *testing.T, withKey bool) *decryptor.Decryptor {
+	// prevent keys of the user running the tests from being used
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if withKey {
+		key, err := sops_test_resources.TestResources.ReadFile("test-key.txt")
+		assert.NoError(t, err)
+		t.Setenv(age.SopsAgeKeyEnv, string(key))
+	} else {
+		t.Setenv(age.SopsAgeKeyEnv, "")
+	}
+	d := decryptor.NewDecryptor("", decryptor.MaxEncryptedFileSize)
+	d.SetCheckSopsMac(true)
+	d.AddLocalKeyService()
+	return d
+}
+
+func TestLoadArgsFileEncrypted(t *testing.T) {
+	d := newTestDecryptor(t, true)
+	p := writeEncryptedArgsFile(t, nil)
+
+	b, encrypted, err := LoadArgsFile(p, d)
+	assert.NoError(t, err)
+	assert.True(t, encrypted)
+	o, err := uo.FromString(string(b))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"test1": map[string]any{"test2": float64(42)}}, o.Object)
+
+	plain := filepath.Join(t.TempDir(), "plain.yaml")
+	assert.NoError(t, os.WriteFile(plain, []byte("a: b\n"), 0o600))
+	b, encrypted, err = LoadArgsFile(plain, d)
+	assert.NoError(t, err)
+	assert.False(t, encrypted)
+	assert.Equal(t, "a: b\n", string(b))
+}
+
+func TestLoadArgsFileErrors(t *testing.T) {
+	p := writeEncryptedArgsFile(t, nil)
+	_, _, err := LoadArgsFile(p, newTestDecryptor(t, false))
+	assert.ErrorContains(t, err, "failed to decrypt args file "+p)
+	assert.ErrorContains(t, err, "cannot get sops data key")
+
+	// unencrypted values are covered by the MAC as well, so adding one causes a MAC mismatch
+	p = writeEncryptedArgsFile(t, func(s string) string {
+		return "injected_unencrypted: x\n" + s
+	})
+	_, _, err = LoadArgsFile(p, newTestDecryptor(t, true))
+	assert.ErrorContains(t, err, "failed to decrypt args file "+p)
+	assert.ErrorContains(t, err, "failed to verify sops data integrity: expected mac")
+}
+
+func TestConvertArgsToVarsEncryptedFile(t *testing.T) {
+	d := newTestDecryptor(t, true)
+	p := writeEncryptedArgsFile(t, nil)
+
+	vars, sensitiveArgs, err := ConvertArgsToVars(map[string]string{
+		"secret": "@" + p,
+		"plain":  "v",
+	}, true, d)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"secret"}, sensitiveArgs)
+	v, _, _ := vars.GetNestedField("secret", "test1", "test2")
+	assert.Equal(t, float64(42), v)
+}
+
+func TestGetRedactedExternalArgs(t *testing.T) {
+	p := &LoadedKluctlProject{
+		LoadArgs: LoadKluctlProjectArgs{
+			ExternalArgs: uo.FromMap(map[string]any{
+				"fromFile": "secret1",
+				"marked":   map[string]any{"nested": "secret2"},
+				"plain":    "visible",
+			}),
+			SensitiveArgs: []string{"fromFile"},
+		},
+	}
+	p.Config.Args = []types.DeploymentArg{
+		{Name: "marked", Sensitive: true},
+		{Name: "plain"},
+	}
+
+	redacted := p.GetRedactedExternalArgs()
+	assert.Equal(t, map[string]any{
+		"fromFile": "*****",
+		"marked":   "*****",
+		"plain":    "visible",
+	}, redacted.Object)
+	// the original args are not modified
+	assert.Equal(t, "secret1", p.LoadArgs.ExternalArgs.Object["fromFile"])
+}
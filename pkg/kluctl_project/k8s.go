@@ -2,12 +2,34 @@ package kluctl_project
 
 import (
 	"context"
+	"fmt"
 	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
+// GetKubeconfigSource returns the effective kubeconfig source for the given target, which is either the override from
+// LoadArgs or the kubeconfigFrom of the target. nil is returned if the default kubeconfig loading applies.
+func (p *LoadedKluctlProject) GetKubeconfigSource(targetName string) (*types.KubeconfigSource, error) {
+	if p.LoadArgs.KubeconfigResolver == nil {
+		return nil, nil
+	}
+	if p.LoadArgs.KubeconfigFromOverride != nil {
+		return p.LoadArgs.KubeconfigFromOverride, nil
+	}
+	if targetName == "" {
+		return nil, nil
+	}
+	t, err := p.FindTarget(targetName)
+	if err != nil {
+		return nil, err
+	}
+	return t.KubeconfigFrom, nil
+}
+
 func (p *LoadedKluctlProject) LoadK8sConfig(ctx context.Context, targetName string, contextOverride string, offlineK8s bool) (*rest.Config, string, error) {
 	if offlineK8s {
 		return nil, "", nil
@@ -20,14 +42,33 @@ func (p *LoadedKluctlProject) LoadK8sConfig(ctx context.Context, targetName stri
 			return nil, "", err
 		}
 		contextName = t.Context
+		if t.KubeconfigFrom != nil && p.LoadArgs.KubeconfigResolver == nil {
+			status.Warningf(ctx, "kubeconfigFrom of target '%s' is ignored, as kubeconfig sources can not be resolved here (e.g. inside the controller)", targetName)
+		}
 	}
 	if contextOverride != "" {
 		contextName = &contextOverride
 	}
 
-	var err error
+	src, err := p.GetKubeconfigSource(targetName)
+	if err != nil {
+		return nil, "", err
+	}
+
 	var clientConfig *rest.Config
 	var restConfig *api.Config
+	if src != nil {
+		kubeConfig, err := p.LoadArgs.KubeconfigResolver.Resolve(ctx, targetName, src)
+		if err != nil {
+			return nil, "", err
+		}
+		clientConfig, restConfig, err = k8s.ClientConfigFromKubeconfig(kubeConfig, contextName)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to build client config from %s kubeconfig source: %w", src.SourceType(), err)
+		}
+		return clientConfig, restConfig.CurrentContext, nil
+	}
+
 	clientConfig, restConfig, err = p.LoadArgs.ClientConfigGetter(contextName)
 	if err != nil {
 		if contextName == nil && clientcmd.IsEmptyConfig(err) {
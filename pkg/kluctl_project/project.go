@@ -3,8 +3,10 @@ package kluctl_project
 import (
 	"fmt"
 	"github.com/kluctl/kluctl/lib/go-jinja2"
+	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
 	types2 "github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"time"
 )
 
@@ -20,6 +22,9 @@ type LoadedKluctlProject struct {
 	J2    *jinja2.Jinja2
 	GitRP *repocache.GitRepoCache
 	OciRP *repocache.OciRepoCache
+
+	// ConfigWarnings contains the unknown fields that were ignored in .kluctl.yaml due to lenient mode
+	ConfigWarnings []yaml.UnknownField
 }
 
 func (c *LoadedKluctlProject) FindTarget(name string) (*types2.Target, error) {
@@ -30,3 +35,28 @@ func (c *LoadedKluctlProject) FindTarget(name string) (*types2.Target, error) {
 	}
 	return nil, fmt.Errorf("target %s not existent in kluctl project config", name)
 }
+
+// GetDeprecationErrors returns the ids of all deprecated features that must be treated as errors
+func (c *LoadedKluctlProject) GetDeprecationErrors() []string {
+	if c.Config.Deprecations == nil {
+		return nil
+	}
+	return c.Config.Deprecations.Errors
+}
+
+// GetSensitiveArgs returns the names of all args that are either marked as sensitive in the project config or were
+// loaded from encrypted args files
+func (c *LoadedKluctlProject) GetSensitiveArgs() []string {
+	ret := append([]string{}, c.LoadArgs.SensitiveArgs...)
+	for _, a := range c.Config.Args {
+		if a.Sensitive {
+			ret = append(ret, a.Name)
+		}
+	}
+	return ret
+}
+
+// GetRedactedExternalArgs returns the external args with the values of all sensitive args redacted
+func (c *LoadedKluctlProject) GetRedactedExternalArgs() *uo.UnstructuredObject {
+	return RedactArgs(c.LoadArgs.ExternalArgs, c.GetSensitiveArgs())
+}
@@ -2,11 +2,15 @@ package kluctl_project
 
 import (
 	"context"
+	"fmt"
 	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/deprecations"
 	helm_auth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
 	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"k8s.io/client-go/rest"
@@ -19,6 +23,11 @@ type LoadKluctlProjectArgs struct {
 	ProjectDir    string
 	ProjectConfig string
 	ExternalArgs  *uo.UnstructuredObject
+	// SensitiveArgs contains the names of external args that must not end up in command results, e.g. because they
+	// were loaded from encrypted files
+	SensitiveArgs []string
+	// Lenient causes unknown fields in project config files to be reported as warnings instead of errors
+	Lenient bool
 
 	GitRP *repocache.GitRepoCache
 	OciRP *repocache.OciRepoCache
@@ -28,6 +37,12 @@ type LoadKluctlProjectArgs struct {
 
 	AddKeyServersFunc  func(ctx context.Context, d *decryptor.Decryptor) error
 	ClientConfigGetter func(context *string) (*rest.Config, *api.Config, error)
+
+	// KubeconfigResolver is used to resolve the kubeconfigFrom sources of targets. If nil, kubeconfigFrom is ignored
+	// and ClientConfigGetter is always used.
+	KubeconfigResolver *k8s.KubeconfigResolver
+	// KubeconfigFromOverride overrides the kubeconfigFrom of all targets
+	KubeconfigFromOverride *types.KubeconfigSource
 }
 
 func (c *LoadedKluctlProject) getConfigPath() string {
@@ -54,11 +69,25 @@ func (c *LoadedKluctlProject) loadKluctlProject(ctx context.Context) error {
 	configPath := c.getConfigPath()
 
 	if configPath != "" {
-		err = yaml.ReadYamlFile(configPath, &c.Config)
+		fileName, err := filepath.Rel(c.LoadArgs.ProjectDir, configPath)
+		if err != nil {
+			fileName = configPath
+		}
+		c.ConfigWarnings, err = yaml.ReadYamlFileWithOptions(configPath, &c.Config, yaml.ReadOptions{
+			FileName: fileName,
+			Lenient:  c.LoadArgs.Lenient,
+		})
 		if err != nil {
 			return err
 		}
 	}
 
+	if c.Config.Deprecations != nil {
+		err = deprecations.ValidateIds(c.Config.Deprecations.Errors)
+		if err != nil {
+			return fmt.Errorf("invalid deprecations config: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,56 @@
+package target_context
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"strings"
+)
+
+// checkDiscriminator verifies that the rendered discriminator is non-empty and contains the target name. Empty
+// discriminators are only reported in error mode, as deployments already warn about missing discriminators.
+func checkDiscriminator(policy string, discriminator string, targetNames ...string) error {
+	if discriminator == "" {
+		if policy == types.DiscriminatorPolicyError {
+			return fmt.Errorf("the rendered discriminator is empty")
+		}
+		return nil
+	}
+
+	var names []string
+	for _, n := range targetNames {
+		if n == "" {
+			continue
+		}
+		if strings.Contains(discriminator, n) {
+			return nil
+		}
+		names = append(names, n)
+	}
+	if len(names) == 0 {
+		// the no-name target
+		return nil
+	}
+	return fmt.Errorf("the rendered discriminator '%s' does not contain the target name '%s', which might cause multiple targets to share the same discriminator and prune each other's objects", discriminator, names[0])
+}
+
+func validateDiscriminator(ctx context.Context, policy string, warnings *deployment.ConfigWarnings, discriminator string, targetNames ...string) error {
+	if policy == "" {
+		policy = types.DiscriminatorPolicyWarn
+	}
+	if policy == types.DiscriminatorPolicyIgnore {
+		return nil
+	}
+	err := checkDiscriminator(policy, discriminator, targetNames...)
+	if err == nil {
+		return nil
+	}
+	if policy == types.DiscriminatorPolicyError {
+		return fmt.Errorf("invalid discriminator: %w (see discriminatorPolicy in .kluctl.yaml)", err)
+	}
+	status.Warning(ctx, err.Error())
+	warnings.Add(err.Error())
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
 	"github.com/kluctl/kluctl/v2/pkg/clouds/gcp"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/deprecations"
 	"github.com/kluctl/kluctl/v2/pkg/helm/auth"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
@@ -43,9 +44,22 @@ type TargetContextParams struct {
 	HelmAuthProvider   auth.HelmAuthProvider
 	OciAuthProvider    auth_provider.OciAuthProvider
 	RenderOutputDir    string
+	// PruneExclude is merged with the prune exclusion rules of the project
+	PruneExclude []types.PruneExcludeRule
+	// KubeconfigSource is a non-sensitive description of the kubeconfig source, see k8s.DescribeKubeconfigSource
+	KubeconfigSource string
+	// NamespaceOverride overrides the namespaceOverride of the target, e.g. for preview environments
+	NamespaceOverride string
+	// StrictNamespaceOverride causes conflicting namespaces to fail when the target has a namespaceOverride
+	StrictNamespaceOverride bool
+	// ObjectPatches are applied after the patches of the project
+	ObjectPatches []types.ObjectPatch
 }
 
 func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject, contextName string, k *k8s.K8sCluster, params TargetContextParams) (*TargetContext, error) {
+	deprecationsCollector := deprecations.NewCollector()
+	ctx = deprecations.NewContext(ctx, deprecationsCollector)
+
 	repoRoot, err := filepath.Abs(p.LoadArgs.RepoRoot)
 	if err != nil {
 		return nil, err
@@ -61,7 +75,8 @@ func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject
 		if err != nil {
 			return nil, err
 		}
-		target = &*t
+		// the target is modified below, so we must not modify the shared target of the project
+		target = t.DeepCopy()
 	} else {
 		if len(p.Targets) != 0 {
 			status.Deprecation(ctx, "no-target", "Warning, tried to use Kluctl without explicitly specifying a target, while the Kluctl project contains target definitions. This was allowed in older version of Kluctl, but is forbidden since v2.23.0. If mixing deployments with and without targets was actually intended, please switch to creating and using a dedicated target that serves as a replacement for no-target deployments.")
@@ -69,13 +84,19 @@ func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject
 		} else if p.NoNameTarget == nil {
 			return nil, fmt.Errorf("missing no-name target, which is unexpected")
 		}
-		target = &*p.NoNameTarget
+		target = p.NoNameTarget.DeepCopy()
 	}
 	if params.TargetNameOverride != "" {
 		target.Name = params.TargetNameOverride
 	}
+	if params.NamespaceOverride != "" {
+		target.NamespaceOverride = params.NamespaceOverride
+	}
 	if params.Discriminator != "" {
 		target.Discriminator = params.Discriminator
+	} else if target.NamespaceOverride != "" && target.Discriminator != "" {
+		// each override namespace is a separate deployment and must not see the objects of the others as orphans
+		target.Discriminator = fmt.Sprintf("%s-%s", target.Discriminator, target.NamespaceOverride)
 	}
 
 	params.Images.PrependFixedImages(target.Images)
@@ -113,6 +134,23 @@ func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject
 		OciAuthProvider:  params.OciAuthProvider,
 		Discriminator:    target.Discriminator,
 		RenderDir:        params.RenderOutputDir,
+		Lenient:          p.LoadArgs.Lenient,
+		ConfigWarnings:   deployment.NewConfigWarnings(),
+		Deprecations:     deprecationsCollector,
+	}
+	dctx.PruneExclude = append(dctx.PruneExclude, p.Config.PruneExclude...)
+	dctx.PruneExclude = append(dctx.PruneExclude, params.PruneExclude...)
+	dctx.IgnoreUnservedApiVersions = p.Config.IgnoreUnservedApiVersions
+	dctx.DeferredKinds = p.Config.DeferredKinds
+	dctx.NamespaceOverride = target.NamespaceOverride
+	dctx.StrictNamespaceOverride = params.StrictNamespaceOverride
+	dctx.ObjectPatches = append(dctx.ObjectPatches, p.Config.Patches...)
+	dctx.ObjectPatches = append(dctx.ObjectPatches, params.ObjectPatches...)
+	dctx.ConfigWarnings.AddUnknownFields(p.ConfigWarnings)
+
+	err = validateDiscriminator(ctx, p.Config.DiscriminatorPolicy, dctx.ConfigWarnings, target.Discriminator, target.Name, params.TargetName)
+	if err != nil {
+		return nil, err
 	}
 
 	targetCtx := &TargetContext{
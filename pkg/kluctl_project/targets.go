@@ -2,10 +2,16 @@ package kluctl_project
 
 import (
 	"context"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	"github.com/kluctl/kluctl/lib/go-jinja2"
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
 	"sort"
+	"strings"
 )
 
 func (c *LoadedKluctlProject) loadTargets(ctx context.Context) error {
@@ -59,24 +65,131 @@ func (c *LoadedKluctlProject) loadTargets(ctx context.Context) error {
 	return nil
 }
 
+// maxTargetRenderIterations limits how often a target is rendered until all of its templates are resolved
+const maxTargetRenderIterations = 10
+
 func (c *LoadedKluctlProject) renderTarget(target *types.Target) error {
-	// Try rendering the target multiple times, until all values can be rendered successfully. This allows the target
-	// to reference itself in complex ways. We'll also try loading the cluster vars in each iteration.
+	// Render the target multiple times, until rendering does not change anything anymore. This allows templates to
+	// reference other fields of the target (e.g. '{{ target.args.x }}'), which might contain templates themselves.
+	// Each iteration renders all fields with the values of the previous iteration, so the result does not depend on
+	// the order of fields or targets.
 
-	var retErr error
-	for i := 0; i < 10; i++ {
+	var changed []string
+	for i := 0; i < maxTargetRenderIterations; i++ {
 		varsCtx, err := c.BuildVars(target)
 		if err != nil {
 			return err
 		}
+		if c.Config.Vars != nil {
+			varsCtx.UpdateChild("vars", c.Config.Vars)
+		}
+
+		o, err := uo.FromStruct(target)
+		if err != nil {
+			return err
+		}
 
-		changed, err := varsCtx.RenderStruct(target)
-		if err == nil && !changed {
+		var renderErrs *multierror.Error
+		changed, renderErrs, err = renderTargetFields(varsCtx, o)
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			if renderErrs != nil {
+				return renderErrs.ErrorOrNil()
+			}
+			cyclic := findCyclicTargetFields(o)
+			if len(cyclic) != 0 {
+				return fmt.Errorf("cyclic reference detected in %s", strings.Join(cyclic, ", "))
+			}
 			return nil
 		}
-		retErr = err
+
+		var rendered types.Target
+		err = o.ToStruct(&rendered)
+		if err != nil {
+			return err
+		}
+		*target = rendered
+	}
+	return fmt.Errorf("failed to resolve %s after %d iterations, which indicates a cyclic reference", strings.Join(changed, ", "), maxTargetRenderIterations)
+}
+
+type targetField struct {
+	keyPath uo.KeyPath
+	path    string
+	value   string
+}
+
+// renderTargetFields renders all string fields of the target and returns the paths of the fields that changed, sorted
+// by path. Errors of single fields are returned separately, as these might be resolved in later iterations.
+func renderTargetFields(varsCtx *vars.VarsCtx, o *uo.UnstructuredObject) ([]string, *multierror.Error, error) {
+	var fields []targetField
+	var jobs []*jinja2.RenderJob
+	err := o.NewIterator().IterateLeafs(func(it *uo.ObjectIterator) error {
+		s, ok := it.Value().(string)
+		if !ok {
+			return nil
+		}
+		fields = append(fields, targetField{
+			keyPath: it.KeyPathCopy(),
+			path:    it.KeyPath().ToJsonPath(),
+			value:   s,
+		})
+		jobs = append(jobs, &jinja2.RenderJob{Template: s})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = varsCtx.RenderStrings(jobs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changed []string
+	var errs []error
+	for i, f := range fields {
+		job := jobs[i]
+		if job.Error != nil {
+			errs = append(errs, fmt.Errorf("failed to render field %s: %w", f.path, job.Error))
+			continue
+		}
+		if *job.Result == f.value {
+			continue
+		}
+		changed = append(changed, f.path)
+		err = o.SetNestedField(*job.Result, f.keyPath...)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	sort.Strings(changed)
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+
+	var retErr *multierror.Error
+	for _, err := range errs {
+		retErr = multierror.Append(retErr, err)
 	}
-	return retErr
+	return changed, retErr, nil
+}
+
+// findCyclicTargetFields returns the paths of all fields that still contain templates after rendering did not change
+// anything anymore. Such templates render to themselves, which can only happen when fields reference each other.
+func findCyclicTargetFields(o *uo.UnstructuredObject) []string {
+	var ret []string
+	_ = o.NewIterator().IterateLeafs(func(it *uo.ObjectIterator) error {
+		s, ok := it.Value().(string)
+		if ok && (strings.Contains(s, "{{") || strings.Contains(s, "{%")) {
+			ret = append(ret, it.KeyPath().ToJsonPath())
+		}
+		return nil
+	})
+	sort.Strings(ret)
+	return ret
 }
 
 func (c *LoadedKluctlProject) buildTarget(configTarget *types.Target) (*types.Target, error) {
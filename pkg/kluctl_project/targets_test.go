@@ -0,0 +1,112 @@
+package kluctl_project
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newJinja2Must(t *testing.T) *jinja2.Jinja2 {
+	j2, err := kluctl_jinja2.NewKluctlJinja2(context.Background(), true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		j2.Close()
+	})
+	return j2
+}
+
+func newTestProject(t *testing.T, config types.KluctlProject) *LoadedKluctlProject {
+	return &LoadedKluctlProject{
+		J2:     newJinja2Must(t),
+		Config: config,
+	}
+}
+
+func TestRenderTargetReferences(t *testing.T) {
+	p := newTestProject(t, types.KluctlProject{
+		Vars: uo.FromMap(map[string]any{
+			"domain": "example.com",
+		}),
+	})
+
+	target := &types.Target{
+		Name:          "prod",
+		Discriminator: "kluctl-{{ target.args.host }}",
+		Args: uo.FromMap(map[string]any{
+			"host":   "{{ target.args.prefix }}.{{ vars.domain }}",
+			"prefix": "{{ target.name }}",
+		}),
+	}
+	err := p.renderTarget(target)
+	assert.NoError(t, err)
+	assert.Equal(t, "kluctl-prod.example.com", target.Discriminator)
+	assert.Equal(t, "prod.example.com", target.Args.Object["host"])
+	assert.Equal(t, "prod", target.Args.Object["prefix"])
+}
+
+func TestRenderTargetCycle(t *testing.T) {
+	p := newTestProject(t, types.KluctlProject{})
+
+	target := &types.Target{
+		Name: "prod",
+		Args: uo.FromMap(map[string]any{
+			"first":  "{{ target.args.second }}",
+			"second": "{{ target.args.first }}",
+			"other":  "ok",
+		}),
+	}
+	err := p.renderTarget(target)
+	assert.EqualError(t, err, "cyclic reference detected in args.first, args.second")
+
+	target = &types.Target{
+		Name: "prod",
+		Args: uo.FromMap(map[string]any{
+			"self": "x{{ target.args.self }}",
+		}),
+	}
+	err = p.renderTarget(target)
+	assert.ErrorContains(t, err, "failed to resolve args.self after 10 iterations")
+}
+
+func TestRenderTargetFieldErrors(t *testing.T) {
+	p := newTestProject(t, types.KluctlProject{})
+
+	target := &types.Target{
+		Name:          "prod",
+		Discriminator: "{{ target.args.missing.x }}",
+	}
+	err := p.renderTarget(target)
+	assert.ErrorContains(t, err, "failed to render field discriminator")
+}
+
+func TestLoadTargetsDeterministic(t *testing.T) {
+	buildConfig := func(reverse bool) types.KluctlProject {
+		targets := []types.Target{
+			{Name: "a", Args: uo.FromMap(map[string]any{"x": "{{ target.name }}-{{ vars.v }}"})},
+			{Name: "b", Args: uo.FromMap(map[string]any{"x": "{{ target.name }}-{{ vars.v }}"})},
+		}
+		if reverse {
+			targets[0], targets[1] = targets[1], targets[0]
+		}
+		return types.KluctlProject{
+			Vars:    uo.FromMap(map[string]any{"v": "1"}),
+			Targets: targets,
+		}
+	}
+
+	p1 := newTestProject(t, buildConfig(false))
+	assert.NoError(t, p1.loadTargets(context.Background()))
+	p2 := newTestProject(t, buildConfig(true))
+	assert.NoError(t, p2.loadTargets(context.Background()))
+
+	assert.Equal(t, p1.Targets, p2.Targets)
+	assert.Len(t, p1.Targets, 2)
+	assert.Equal(t, "a-1", p1.Targets[0].Args.Object["x"])
+	assert.Equal(t, "b-1", p1.Targets[1].Args.Object["x"])
+}
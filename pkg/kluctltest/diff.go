@@ -0,0 +1,88 @@
+package kluctltest
+
+import (
+	"sort"
+
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// DiffResult is the result of a diff against a fake cluster
+type DiffResult struct {
+	NewObjects     []k8s2.ObjectRef
+	ChangedObjects []result.ChangedObject
+	OrphanObjects  []k8s2.ObjectRef
+	Errors         []result.DeploymentError
+}
+
+// Diff diffs the rendered objects against a fake cluster that contains the given objects.
+//
+// As no API server is involved, applying is simulated by merging the rendered objects into the existing objects.
+// This means that fields which are removed from the rendered objects are not detected as removed and that defaulting
+// and admission do not happen. Objects carrying the discriminator of the target which are not rendered anymore are
+// reported as orphans. Use DiffCluster together with WithRestConfig for exact diffs.
+func (r *Rendered) Diff(clusterObjects ...*uo.UnstructuredObject) *DiffResult {
+	ctx := r.p.ctx
+	dc := r.TargetCtx.DeploymentCollection
+
+	dew := utils.NewDeploymentErrorsAndWarnings()
+	ru := utils.NewRemoteObjectsUtil(ctx, dew)
+	ru.AddRemoteObjects(clusterObjects)
+
+	ret := &DiffResult{}
+
+	appliedObjects := map[k8s2.ObjectRef]*uo.UnstructuredObject{}
+	localRefs := map[k8s2.ObjectRef]bool{}
+	for _, o := range r.Objects {
+		ref := o.GetK8sRef()
+		localRefs[ref] = true
+		ro := ru.GetRemoteObject(ref)
+		if ro == nil {
+			appliedObjects[ref] = o
+			ret.NewObjects = append(ret.NewObjects, ref)
+		} else {
+			appliedObjects[ref] = ro.MergeCopy(o)
+		}
+	}
+
+	du := utils.NewDiffUtil(dew, ru, nil, appliedObjects)
+	du.DiffDeploymentItems(dc.Deployments)
+	ret.ChangedObjects = du.ChangedObjects
+
+	discriminator := r.TargetCtx.Target.Discriminator
+	if discriminator != "" {
+		remoteObjects := utils.FilterPruneExcluded(ctx, ru.GetFilteredRemoteObjects(dc.Inclusion), dc.PruneExclude())
+		remoteObjects = utils.FilterOtherNamespaces(remoteObjects, dc.NamespaceOverride())
+		for _, o := range remoteObjects {
+			ref := o.GetK8sRef()
+			d := o.GetK8sLabel("kluctl.io/discriminator")
+			if d == nil || *d != discriminator || localRefs[ref] {
+				continue
+			}
+			ret.OrphanObjects = append(ret.OrphanObjects, ref)
+		}
+	}
+
+	sortRefs(ret.NewObjects)
+	sortRefs(ret.OrphanObjects)
+	ret.Errors = dew.GetErrorsList()
+	return ret
+}
+
+// DiffCluster performs a real diff against the cluster that was passed via WithRestConfig. The test fails if no
+// cluster was passed.
+func (r *Rendered) DiffCluster() *result.CommandResult {
+	if r.TargetCtx.SharedContext.K == nil {
+		r.p.t.Fatalf("DiffCluster requires a cluster, use WithRestConfig to pass one")
+	}
+	return commands.NewDiffCommand(r.TargetCtx).Run()
+}
+
+func sortRefs(refs []k8s2.ObjectRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].String() < refs[j].String()
+	})
+}
@@ -0,0 +1,172 @@
+// Package kluctltest provides helpers to test Kluctl projects in Go unit tests, without running the kluctl CLI.
+//
+// Projects are rendered in offline mode by default, meaning that no Kubernetes cluster is required. Diffs can be
+// performed against a fake cluster, which is simply a list of objects that are treated as the current cluster state.
+// A real cluster (e.g. envtest) can optionally be passed via WithRestConfig.
+package kluctltest
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/lib/git"
+	"github.com/kluctl/kluctl/lib/git/auth"
+	"github.com/kluctl/kluctl/lib/git/messages"
+	ssh_pool "github.com/kluctl/kluctl/lib/git/ssh-pool"
+	"github.com/kluctl/kluctl/lib/status"
+	helm_auth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
+	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	"github.com/kluctl/kluctl/v2/pkg/repocache"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	cp "github.com/otiai10/copy"
+	"k8s.io/client-go/rest"
+)
+
+// Project is a loaded Kluctl project that can be rendered for different targets
+type Project struct {
+	t   testing.TB
+	ctx context.Context
+
+	dir        string
+	args       *uo.UnstructuredObject
+	k8sVersion string
+	restConfig *rest.Config
+
+	project *kluctl_project.LoadedKluctlProject
+}
+
+type Option func(p *Project)
+
+// WithArgs sets the arguments that are passed to the project, equivalent to '-a' on the command line
+func WithArgs(args map[string]any) Option {
+	return func(p *Project) {
+		p.args = uo.FromMap(args)
+	}
+}
+
+// WithK8sVersion sets the Kubernetes version that is assumed while rendering in offline mode
+func WithK8sVersion(v string) Option {
+	return func(p *Project) {
+		p.k8sVersion = v
+	}
+}
+
+// WithRestConfig causes rendering and diffs to be performed against the given cluster, e.g. an envtest cluster.
+// The cluster is only accessed in dry-run mode.
+func WithRestConfig(restConfig *rest.Config) Option {
+	return func(p *Project) {
+		p.restConfig = restConfig
+	}
+}
+
+// LoadProject loads the project from the given directory. The test fails if loading fails.
+func LoadProject(t testing.TB, dir string, opts ...Option) *Project {
+	p, err := loadProject(t, dir, opts...)
+	if err != nil {
+		t.Fatalf("failed to load project %s: %s", dir, err.Error())
+	}
+	return p
+}
+
+// LoadProjectFS copies the given file system (e.g. an embed.FS) into a temporary directory and loads the project from
+// the given sub directory of it. The test fails if loading fails.
+func LoadProjectFS(t testing.TB, fsys fs.FS, dir string, opts ...Option) *Project {
+	tmpDir := t.TempDir()
+	err := cp.Copy(".", tmpDir, cp.Options{
+		FS:                fsys,
+		PermissionControl: cp.AddPermission(0o600),
+	})
+	if err != nil {
+		t.Fatalf("failed to copy project files: %s", err.Error())
+	}
+	return LoadProject(t, filepath.Join(tmpDir, dir), opts...)
+}
+
+func loadProject(t testing.TB, dir string, opts ...Option) (*Project, error) {
+	p := &Project{
+		t: t,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+
+	var err error
+	p.dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	ctx = utils.WithTmpBaseDir(ctx, t.TempDir())
+	ctx = utils.WithCacheDir(ctx, t.TempDir())
+	sh := status.NewSimpleStatusHandler(func(level status.Level, message string) {
+		t.Log(message)
+	}, false)
+	t.Cleanup(sh.Stop)
+	ctx = status.NewContext(ctx, sh)
+	ctx = repocache.WithFetchScheduler(ctx, repocache.NewFetchScheduler(0))
+	p.ctx = ctx
+
+	j2, err := kluctl_jinja2.NewKluctlJinja2(ctx, true, false)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(j2.Close)
+
+	repoRoot, err := git.DetectGitRepositoryRoot(p.dir)
+	if err != nil || repoRoot == "" {
+		repoRoot = p.dir
+	}
+
+	messageCallbacks := &messages.MessageCallbacks{
+		WarningFn: func(s string) { status.Warning(ctx, s) },
+		TraceFn:   func(s string) { status.Trace(ctx, s) },
+	}
+	gitAuth := auth.NewDefaultAuthProviders("KLUCTL_GIT", messageCallbacks)
+	ociAuth := auth_provider.NewDefaultAuthProviders("KLUCTL_REGISTRY")
+	helmAuth := helm_auth.NewDefaultAuthProviders("KLUCTL_HELM")
+
+	gitRp := repocache.NewGitRepoCache(ctx, &ssh_pool.SshPool{}, gitAuth, nil, 0)
+	t.Cleanup(gitRp.Clear)
+	ociRp := repocache.NewOciRepoCache(ctx, ociAuth, nil, 0)
+	t.Cleanup(ociRp.Clear)
+
+	loadArgs := kluctl_project.LoadKluctlProjectArgs{
+		RepoRoot:         repoRoot,
+		ProjectDir:       p.dir,
+		ExternalArgs:     p.args,
+		GitRP:            gitRp,
+		OciRP:            ociRp,
+		OciAuthProvider:  ociAuth,
+		HelmAuthProvider: helmAuth,
+	}
+	p.project, err = kluctl_project.LoadKluctlProject(ctx, loadArgs, j2)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Dir returns the absolute path of the project directory
+func (p *Project) Dir() string {
+	return p.dir
+}
+
+// LoadedProject gives access to the underlying loaded project, e.g. to inspect the targets
+func (p *Project) LoadedProject() *kluctl_project.LoadedKluctlProject {
+	return p.project
+}
+
+func (p *Project) renderDir() string {
+	d, err := os.MkdirTemp(utils.GetTmpBaseDir(p.ctx), "rendered-")
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return d
+}
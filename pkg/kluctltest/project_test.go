@@ -0,0 +1,87 @@
+package kluctltest
+
+import (
+	"embed"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+//go:embed all:testdata/project
+var testProjectFS embed.FS
+
+func TestRender(t *testing.T) {
+	p := LoadProject(t, "testdata/project")
+
+	r := p.Render("prod")
+	assert.Len(t, r.Objects, 2)
+
+	cm := r.Get("ConfigMap", "default", "app-config")
+	v, _, _ := cm.GetNestedString("data", "target")
+	assert.Equal(t, "prod", v)
+	assert.Equal(t, "kluctltest-prod", *cm.GetK8sLabel("kluctl.io/discriminator"))
+
+	var d appsv1.Deployment
+	r.Decode("Deployment.apps", "default", "app", &d)
+	assert.Equal(t, int32(3), *d.Spec.Replicas)
+
+	assert.Nil(t, r.Find("Deployment", "default", "other"))
+	assert.Empty(t, r.DuplicateRefs())
+}
+
+func TestRenderNamespaceOverrideTwice(t *testing.T) {
+	p := LoadProject(t, "testdata/project")
+
+	// rendering must not modify the loaded project, so that the discriminator is only suffixed once
+	for i := 0; i < 2; i++ {
+		r := p.Render("preview")
+		assert.Equal(t, "kluctltest-preview-preview", r.TargetCtx.Target.Discriminator)
+		cm := r.Get("ConfigMap", "preview", "app-config")
+		assert.Equal(t, "kluctltest-preview-preview", *cm.GetK8sLabel("kluctl.io/discriminator"))
+	}
+	target, err := p.project.FindTarget("preview")
+	assert.NoError(t, err)
+	assert.Equal(t, "kluctltest-preview", target.Discriminator)
+}
+
+func TestRenderFS(t *testing.T) {
+	p := LoadProjectFS(t, testProjectFS, "testdata/project", WithArgs(map[string]any{
+		"withDuplicate": true,
+	}))
+
+	r := p.Render("test")
+	dups := r.DuplicateRefs()
+	assert.Len(t, dups, 1)
+	assert.Equal(t, "app-config", dups[0].Name)
+}
+
+func TestDiff(t *testing.T) {
+	p := LoadProject(t, "testdata/project")
+	r := p.Render("test")
+
+	cm := r.Get("ConfigMap", "default", "app-config").Clone()
+	_ = cm.SetNestedField("old", "data", "target")
+	orphan := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":      "orphan",
+			"namespace": "default",
+			"labels": map[string]any{
+				"kluctl.io/discriminator": "kluctltest-test",
+			},
+		},
+	})
+
+	dr := r.Diff(cm, orphan)
+	assert.Empty(t, dr.Errors)
+	assert.Len(t, dr.NewObjects, 1)
+	assert.Equal(t, "app", dr.NewObjects[0].Name)
+	assert.Len(t, dr.ChangedObjects, 1)
+	assert.Equal(t, "app-config", dr.ChangedObjects[0].Ref.Name)
+	assert.Equal(t, "data.target", dr.ChangedObjects[0].Changes[0].JsonPath)
+	assert.Len(t, dr.OrphanObjects, 1)
+	assert.Equal(t, "orphan", dr.OrphanObjects[0].Name)
+}
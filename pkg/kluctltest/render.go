@@ -0,0 +1,136 @@
+package kluctltest
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// Rendered contains the rendered objects of a single target
+type Rendered struct {
+	p *Project
+
+	TargetCtx *target_context.TargetContext
+	Objects   []*uo.UnstructuredObject
+}
+
+// Render renders the given target. An empty target name selects the no-name target. Rendering happens in offline
+// mode, unless a cluster was passed via WithRestConfig. The test fails if rendering fails.
+func (p *Project) Render(target string) *Rendered {
+	r, err := p.RenderE(target)
+	if err != nil {
+		p.t.Fatalf("failed to render target '%s': %s", target, err.Error())
+	}
+	return r
+}
+
+// RenderE is like Render, but returns the error instead of failing the test
+func (p *Project) RenderE(target string) (*Rendered, error) {
+	images, err := deployment.NewImages()
+	if err != nil {
+		return nil, err
+	}
+
+	params := target_context.TargetContextParams{
+		TargetName:       target,
+		OfflineK8s:       p.restConfig == nil,
+		K8sVersion:       p.k8sVersion,
+		DryRun:           true,
+		Images:           images,
+		OciAuthProvider:  p.project.LoadArgs.OciAuthProvider,
+		HelmAuthProvider: p.project.LoadArgs.HelmAuthProvider,
+		RenderOutputDir:  p.renderDir(),
+	}
+
+	var k *k8s.K8sCluster
+	if p.restConfig != nil {
+		discovery, mapper, err := k8s.CreateDiscoveryAndMapper(p.ctx, p.restConfig)
+		if err != nil {
+			return nil, err
+		}
+		k, err = k8s.NewK8sCluster(p.ctx, p.restConfig, discovery, mapper, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	targetCtx, err := target_context.NewTargetContext(p.ctx, p.project, "", k, params)
+	if err != nil {
+		return nil, err
+	}
+	err = targetCtx.DeploymentCollection.Prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rendered{
+		p:         p,
+		TargetCtx: targetCtx,
+		Objects:   targetCtx.DeploymentCollection.LocalObjects(),
+	}, nil
+}
+
+// Find returns the rendered object with the given kind, namespace and name, or nil if no such object was rendered.
+// The kind is matched against the group qualified kind (e.g. "Deployment.apps") or only the kind.
+func (r *Rendered) Find(kind string, namespace string, name string) *uo.UnstructuredObject {
+	for _, o := range r.Objects {
+		ref := o.GetK8sRef()
+		if ref.Namespace != namespace || ref.Name != name {
+			continue
+		}
+		if ref.Kind == kind || ref.GroupKind().String() == kind {
+			return o
+		}
+	}
+	return nil
+}
+
+// Get is like Find, but fails the test if the object was not rendered
+func (r *Rendered) Get(kind string, namespace string, name string) *uo.UnstructuredObject {
+	o := r.Find(kind, namespace, name)
+	if o == nil {
+		r.p.t.Fatalf("object %s %s/%s was not rendered", kind, namespace, name)
+	}
+	return o
+}
+
+// Decode converts the rendered object with the given kind, namespace and name into a typed object, e.g.
+// *appsv1.Deployment. The test fails if the object was not rendered or can not be converted.
+func (r *Rendered) Decode(kind string, namespace string, name string, out any) {
+	o := r.Get(kind, namespace, name)
+	err := o.ToStruct(out)
+	if err != nil {
+		r.p.t.Fatalf("failed to decode %s: %s", o.GetK8sRef().String(), err.Error())
+	}
+}
+
+// DuplicateRefs returns all object refs that were rendered more than once, e.g. by different deployment items
+func (r *Rendered) DuplicateRefs() []k8s2.ObjectRef {
+	counts := map[k8s2.ObjectRef]int{}
+	for _, o := range r.Objects {
+		counts[o.GetK8sRef()]++
+	}
+	var ret []k8s2.ObjectRef
+	for ref, c := range counts {
+		if c > 1 {
+			ret = append(ret, ref)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
+
+// AssertNoDuplicateRefs fails the test if any object was rendered more than once
+func (r *Rendered) AssertNoDuplicateRefs() {
+	dups := r.DuplicateRefs()
+	if len(dups) != 0 {
+		r.p.t.Errorf("duplicate objects rendered: %s", fmt.Sprint(dups))
+	}
+}
@@ -0,0 +1,315 @@
+// Package localcache implements listing and cleaning of the local caches found in the kluctl cache dir.
+//
+// Entries of the git and helm caches are protected by the same file locks that are used when the caches are
+// populated, so that cleaning is safe against concurrently running kluctl processes.
+package localcache
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rogpeppe/go-internal/lockedfile"
+)
+
+type CacheType string
+
+const (
+	CacheTypeGit        CacheType = "git"
+	CacheTypeHelm       CacheType = "helm"
+	CacheTypeDiscovery  CacheType = "discovery"
+	CacheTypeYamlBundle CacheType = "yaml-bundle"
+)
+
+var AllCacheTypes = []CacheType{CacheTypeGit, CacheTypeHelm, CacheTypeDiscovery, CacheTypeYamlBundle}
+
+// gitLockFileName must match the lock file name used by MirroredGitRepo
+const gitLockFileName = ".cache.lock"
+
+func ParseCacheType(s string) (CacheType, error) {
+	for _, t := range AllCacheTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	var l []string
+	for _, t := range AllCacheTypes {
+		l = append(l, string(t))
+	}
+	return "", fmt.Errorf("invalid cache type %s, must be one of %s", s, strings.Join(l, ", "))
+}
+
+func getCacheTypeDir(cacheDir string, t CacheType) string {
+	switch t {
+	case CacheTypeGit:
+		return filepath.Join(cacheDir, "git-cache")
+	case CacheTypeHelm:
+		return filepath.Join(cacheDir, "helm-charts")
+	case CacheTypeDiscovery:
+		return filepath.Join(cacheDir, "kube-cache", "discovery")
+	case CacheTypeYamlBundle:
+		// must match the cache dir used by the yamlbundle package
+		return filepath.Join(cacheDir, "yaml-bundles")
+	default:
+		panic(fmt.Sprintf("unknown cache type %s", t))
+	}
+}
+
+// Entry is a single entry of a cache, e.g. a git mirror or a pulled Helm chart version
+type Entry struct {
+	Type CacheType `json:"type"`
+	Path string    `json:"path"`
+	Size int64     `json:"size"`
+	// LastUsed is the time the entry was last locked or modified
+	LastUsed time.Time `json:"lastUsed"`
+
+	// lockPath is empty for caches that do not use locking
+	lockPath string
+}
+
+// CacheInfo summarizes all entries of a single cache type
+type CacheInfo struct {
+	Type    CacheType `json:"type"`
+	Dir     string    `json:"dir"`
+	Entries int       `json:"entries"`
+	Size    int64     `json:"size"`
+}
+
+// getDirUsage returns the size and the newest modification time of all files in the given dir, ignoring the given
+// lock file
+func getDirUsage(dir string, ignore string) (int64, time.Time, error) {
+	var size int64
+	var newest time.Time
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if p == ignore || d.IsDir() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		size += fi.Size()
+		if fi.ModTime().After(newest) {
+			newest = fi.ModTime()
+		}
+		return nil
+	})
+	return size, newest, err
+}
+
+func buildEntry(t CacheType, dir string, lockPath string) (*Entry, error) {
+	size, lastUsed, err := getDirUsage(dir, lockPath)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsed.IsZero() {
+		// nothing cached, only the lock file is left
+		return nil, nil
+	}
+	if lockPath != "" {
+		// locks are re-created whenever the entry is used
+		if st, err := os.Stat(lockPath); err == nil && st.ModTime().After(lastUsed) {
+			lastUsed = st.ModTime()
+		}
+	}
+	return &Entry{
+		Type:     t,
+		Path:     dir,
+		Size:     size,
+		LastUsed: lastUsed,
+		lockPath: lockPath,
+	}, nil
+}
+
+func listEntries(cacheDir string, t CacheType) ([]Entry, error) {
+	baseDir := getCacheTypeDir(cacheDir, t)
+
+	var ret []Entry
+	add := func(dir string, lockPath string) error {
+		e, err := buildEntry(t, dir, lockPath)
+		if err != nil {
+			return err
+		}
+		if e != nil {
+			ret = append(ret, *e)
+		}
+		return nil
+	}
+
+	switch t {
+	case CacheTypeGit, CacheTypeDiscovery, CacheTypeYamlBundle:
+		// one entry per mirrored repository, per API host or per bundle digest
+		des, err := os.ReadDir(baseDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, de := range des {
+			if !de.IsDir() {
+				continue
+			}
+			dir := filepath.Join(baseDir, de.Name())
+			lockPath := ""
+			if t == CacheTypeGit {
+				lockPath = filepath.Join(dir, gitLockFileName)
+			}
+			err = add(dir, lockPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case CacheTypeHelm:
+		// pulled charts are stored in nested directories of varying depth, each having a sibling lock file
+		err := filepath.WalkDir(baseDir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !d.IsDir() || p == baseDir {
+				return nil
+			}
+			lockPath := p + ".lock"
+			if st, err := os.Stat(lockPath); err != nil || st.IsDir() {
+				return nil
+			}
+			err = add(p, lockPath)
+			if err != nil {
+				return err
+			}
+			return filepath.SkipDir
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// ListEntries returns all entries of the given cache types, sorted by type and path
+func ListEntries(cacheDir string, types []CacheType) ([]Entry, error) {
+	var ret []Entry
+	for _, t := range types {
+		l, err := listEntries(cacheDir, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s cache: %w", t, err)
+		}
+		ret = append(ret, l...)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Type != ret[j].Type {
+			return ret[i].Type < ret[j].Type
+		}
+		return ret[i].Path < ret[j].Path
+	})
+	return ret, nil
+}
+
+// BuildInfo summarizes the entries per cache type. All given types are included, even if they have no entries.
+func BuildInfo(cacheDir string, types []CacheType, entries []Entry) []CacheInfo {
+	var ret []CacheInfo
+	for _, t := range types {
+		ci := CacheInfo{
+			Type: t,
+			Dir:  getCacheTypeDir(cacheDir, t),
+		}
+		for _, e := range entries {
+			if e.Type == t {
+				ci.Entries++
+				ci.Size += e.Size
+			}
+		}
+		ret = append(ret, ci)
+	}
+	return ret
+}
+
+type CleanOptions struct {
+	// OlderThan selects all entries that were not used for the given duration
+	OlderThan time.Duration
+	// MaxSize selects the least recently used entries until the total size of the remaining entries fits into MaxSize
+	MaxSize *int64
+
+	Now time.Time
+}
+
+// SelectForRemoval returns the entries to remove according to the given options. Entries are returned in least
+// recently used order.
+func SelectForRemoval(entries []Entry, opts CleanOptions) []Entry {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].LastUsed.Before(sorted[j].LastUsed)
+	})
+
+	var total int64
+	for _, e := range sorted {
+		total += e.Size
+	}
+
+	var ret []Entry
+	for _, e := range sorted {
+		remove := opts.OlderThan == 0 && opts.MaxSize == nil
+		if opts.OlderThan != 0 && opts.Now.Sub(e.LastUsed) > opts.OlderThan {
+			remove = true
+		}
+		if opts.MaxSize != nil && total > *opts.MaxSize {
+			remove = true
+		}
+		if remove {
+			ret = append(ret, e)
+			total -= e.Size
+		}
+	}
+	return ret
+}
+
+// Remove removes the cached content of the entry. If the cache uses locking, the lock of the entry is acquired first,
+// waiting for concurrently running processes to release it. The lock file itself is kept, as other processes might
+// already wait for it.
+func (e *Entry) Remove() error {
+	if e.lockPath == "" {
+		return os.RemoveAll(e.Path)
+	}
+
+	lock, err := lockedfile.Create(e.lockPath)
+	if err != nil {
+		return fmt.Errorf("locking of %s failed: %w", e.lockPath, err)
+	}
+	defer lock.Close()
+
+	des, err := os.ReadDir(e.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, de := range des {
+		p := filepath.Join(e.Path, de.Name())
+		if p == e.lockPath {
+			continue
+		}
+		err = os.RemoveAll(p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
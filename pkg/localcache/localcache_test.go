@@ -0,0 +1,181 @@
+package localcache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rogpeppe/go-internal/lockedfile"
+	"github.com/stretchr/testify/assert"
+)
+
+var testNow = time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func writeTestFile(t *testing.T, p string, size int, mtime time.Time) {
+	err := os.MkdirAll(filepath.Dir(p), 0o700)
+	assert.NoError(t, err)
+	err = os.WriteFile(p, []byte(strings.Repeat("x", size)), 0o600)
+	assert.NoError(t, err)
+	err = os.Chtimes(p, mtime, mtime)
+	assert.NoError(t, err)
+}
+
+func buildTestCache(t *testing.T) string {
+	dir := t.TempDir()
+	old := testNow.Add(-30 * 24 * time.Hour)
+	recent := testNow.Add(-time.Hour)
+
+	writeTestFile(t, filepath.Join(dir, "git-cache", "repo1-abcdef", gitLockFileName), 0, old)
+	writeTestFile(t, filepath.Join(dir, "git-cache", "repo1-abcdef", "mirror", "HEAD"), 100, old)
+	writeTestFile(t, filepath.Join(dir, "git-cache", "repo2-123456", gitLockFileName), 0, recent)
+	writeTestFile(t, filepath.Join(dir, "git-cache", "repo2-123456", "mirror", "HEAD"), 200, old)
+	// only the lock is left, e.g. after cleaning
+	writeTestFile(t, filepath.Join(dir, "git-cache", "repo3-aaaaaa", gitLockFileName), 0, old)
+
+	writeTestFile(t, filepath.Join(dir, "helm-charts", "https_charts.example.com", "chart", "1.0.0.lock"), 0, old)
+	writeTestFile(t, filepath.Join(dir, "helm-charts", "https_charts.example.com", "chart", "1.0.0", "Chart.yaml"), 50, old)
+	// a Chart.lock inside a chart must not be treated as cache lock
+	writeTestFile(t, filepath.Join(dir, "helm-charts", "https_charts.example.com", "chart", "1.0.0", "Chart.lock"), 10, old)
+	writeTestFile(t, filepath.Join(dir, "helm-charts", "https_charts.example.com", "chart", "1.0.0", "charts", "dep", "Chart.yaml"), 10, old)
+
+	writeTestFile(t, filepath.Join(dir, "kube-cache", "discovery", "api.example.com-6443", "servergroups.json"), 300, recent)
+	return dir
+}
+
+func TestListEntries(t *testing.T) {
+	dir := buildTestCache(t)
+
+	entries, err := ListEntries(dir, AllCacheTypes)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 4)
+
+	assert.Equal(t, CacheTypeDiscovery, entries[0].Type)
+	assert.Equal(t, int64(300), entries[0].Size)
+
+	assert.Equal(t, CacheTypeGit, entries[1].Type)
+	assert.Equal(t, filepath.Join(dir, "git-cache", "repo1-abcdef"), entries[1].Path)
+	assert.Equal(t, int64(100), entries[1].Size)
+	assert.Equal(t, CacheTypeGit, entries[2].Type)
+	// the lock file is more recent than the content
+	assert.True(t, entries[2].LastUsed.Equal(testNow.Add(-time.Hour)))
+
+	assert.Equal(t, CacheTypeHelm, entries[3].Type)
+	assert.Equal(t, filepath.Join(dir, "helm-charts", "https_charts.example.com", "chart", "1.0.0"), entries[3].Path)
+	assert.Equal(t, int64(70), entries[3].Size)
+
+	info := BuildInfo(dir, AllCacheTypes, entries)
+	assert.Equal(t, []CacheInfo{
+		{Type: CacheTypeGit, Dir: filepath.Join(dir, "git-cache"), Entries: 2, Size: 300},
+		{Type: CacheTypeHelm, Dir: filepath.Join(dir, "helm-charts"), Entries: 1, Size: 70},
+		{Type: CacheTypeDiscovery, Dir: filepath.Join(dir, "kube-cache", "discovery"), Entries: 1, Size: 300},
+		{Type: CacheTypeYamlBundle, Dir: filepath.Join(dir, "yaml-bundles")},
+	}, info)
+
+	entries, err = ListEntries(dir, []CacheType{CacheTypeHelm})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	entries, err = ListEntries(t.TempDir(), AllCacheTypes)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestListEntriesYamlBundle(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "yaml-bundles", "0123abcd", "bundle.yaml"), 400, testNow)
+
+	entries, err := ListEntries(dir, []CacheType{CacheTypeYamlBundle})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, filepath.Join(dir, "yaml-bundles", "0123abcd"), entries[0].Path)
+	assert.Equal(t, int64(400), entries[0].Size)
+
+	assert.NoError(t, entries[0].Remove())
+	assert.NoDirExists(t, entries[0].Path)
+}
+
+func getPaths(entries []Entry) []string {
+	var ret []string
+	for _, e := range entries {
+		ret = append(ret, filepath.Base(e.Path))
+	}
+	return ret
+}
+
+func TestSelectForRemoval(t *testing.T) {
+	dir := buildTestCache(t)
+	entries, err := ListEntries(dir, AllCacheTypes)
+	assert.NoError(t, err)
+
+	// no filters selects everything
+	assert.Len(t, SelectForRemoval(entries, CleanOptions{Now: testNow}), 4)
+
+	l := SelectForRemoval(entries, CleanOptions{OlderThan: 24 * time.Hour, Now: testNow})
+	assert.Equal(t, []string{"repo1-abcdef", "1.0.0"}, getPaths(l))
+
+	// total size is 670, least recently used entries are removed first
+	maxSize := int64(550)
+	l = SelectForRemoval(entries, CleanOptions{MaxSize: &maxSize, Now: testNow})
+	assert.Equal(t, []string{"repo1-abcdef", "1.0.0"}, getPaths(l))
+	maxSize = 600
+	l = SelectForRemoval(entries, CleanOptions{MaxSize: &maxSize, Now: testNow})
+	assert.Equal(t, []string{"repo1-abcdef"}, getPaths(l))
+	maxSize = 0
+	assert.Len(t, SelectForRemoval(entries, CleanOptions{MaxSize: &maxSize, Now: testNow}), 4)
+}
+
+func TestRemove(t *testing.T) {
+	dir := buildTestCache(t)
+	entries, err := ListEntries(dir, AllCacheTypes)
+	assert.NoError(t, err)
+
+	for _, e := range entries {
+		assert.NoError(t, e.Remove())
+	}
+
+	entries, err = ListEntries(dir, AllCacheTypes)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// lock files are kept
+	assert.FileExists(t, filepath.Join(dir, "git-cache", "repo1-abcdef", gitLockFileName))
+	assert.FileExists(t, filepath.Join(dir, "helm-charts", "https_charts.example.com", "chart", "1.0.0.lock"))
+	assert.NoDirExists(t, filepath.Join(dir, "kube-cache", "discovery", "api.example.com-6443"))
+}
+
+func TestRemoveWaitsForLock(t *testing.T) {
+	dir := buildTestCache(t)
+	entries, err := ListEntries(dir, []CacheType{CacheTypeGit})
+	assert.NoError(t, err)
+	e := entries[0]
+
+	lock, err := lockedfile.Create(filepath.Join(e.Path, gitLockFileName))
+	assert.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- e.Remove()
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Remove did not wait for the lock")
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.DirExists(t, filepath.Join(e.Path, "mirror"))
+
+	assert.NoError(t, lock.Close())
+	assert.NoError(t, <-done)
+	assert.NoDirExists(t, filepath.Join(e.Path, "mirror"))
+}
+
+func TestParseCacheType(t *testing.T) {
+	ct, err := ParseCacheType("helm")
+	assert.NoError(t, err)
+	assert.Equal(t, CacheTypeHelm, ct)
+
+	_, err = ParseCacheType("render")
+	assert.ErrorContains(t, err, "must be one of git, helm, discovery")
+}
@@ -0,0 +1,47 @@
+package auth_provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/kluctl/kluctl/lib/credentials"
+	"github.com/kluctl/kluctl/lib/status"
+	"strings"
+)
+
+// OciExecAuthProvider retrieves credentials from an external executable, see credentials.ExecProvider for details.
+type OciExecAuthProvider struct {
+	Exec *credentials.ExecProvider
+}
+
+func (a *OciExecAuthProvider) FindAuthEntry(ctx context.Context, ociUrl string) (*AuthEntry, error) {
+	status.Tracef(ctx, "OciExecAuthProvider: BuildAuth for %s", ociUrl)
+
+	if !strings.HasPrefix(ociUrl, "oci://") {
+		return nil, fmt.Errorf("invalid oci url: %s", ociUrl)
+	}
+
+	ociRef, err := name.ParseReference(strings.TrimPrefix(ociUrl, "oci://"))
+	if err != nil {
+		return nil, err
+	}
+
+	registry := ociRef.Context().RegistryStr()
+	creds, err := a.Exec.GetCredentials(ctx, "oci", registry, ociRef.Context().RepositoryStr())
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil {
+		status.Trace(ctx, "OciExecAuthProvider: no credentials returned")
+		return nil, nil
+	}
+
+	e := &AuthEntry{
+		Registry: registry,
+	}
+	e.AuthConfig.Username = creds.Username
+	e.AuthConfig.Password = creds.Password
+	e.AuthConfig.IdentityToken = creds.IdentityToken
+	e.AuthConfig.RegistryToken = creds.Token
+	return e, nil
+}
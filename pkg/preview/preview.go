@@ -0,0 +1,186 @@
+// Package preview implements records of ephemeral preview environments, e.g. per pull request deployments of a
+// template target. Records are stored as ConfigMaps in the cluster the preview got deployed to, so that previews can
+// be listed and deleted without access to the Kluctl project.
+package preview
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PreviewLabel is set on all preview record ConfigMaps and on namespaces created for previews
+	PreviewLabel = "kluctl.io/preview"
+
+	configMapPrefix = "kluctl-preview-"
+	recordKey       = "preview"
+)
+
+type Preview struct {
+	Name string `json:"name"`
+	// Target is the name of the template target the preview was derived from
+	Target        string `json:"target"`
+	Namespace     string `json:"namespace"`
+	Discriminator string `json:"discriminator"`
+	// CreatedNamespace is true if the namespace was created for the preview and must be deleted together with it
+	CreatedNamespace bool `json:"createdNamespace,omitempty"`
+
+	CreationTime metav1.Time `json:"creationTime"`
+	LastDeployed metav1.Time `json:"lastDeployed"`
+}
+
+// ValidateName ensures that the name can be used as part of namespaces and ConfigMap names
+func ValidateName(name string) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) != 0 {
+		return fmt.Errorf("invalid preview name %s: %s", name, errs[0])
+	}
+	if len(configMapPrefix)+len(name) > validation.DNS1123SubdomainMaxLength {
+		return fmt.Errorf("invalid preview name %s: too long", name)
+	}
+	return nil
+}
+
+// IsExpired returns true if the preview was not deployed since maxAge
+func (p *Preview) IsExpired(maxAge time.Duration, now time.Time) bool {
+	return p.LastDeployed.Time.Before(now.Add(-maxAge))
+}
+
+func buildConfigMapName(name string) string {
+	return configMapPrefix + name
+}
+
+func parseConfigMap(cm *corev1.ConfigMap) (*Preview, error) {
+	var p Preview
+	err := yaml.ReadYamlString(cm.Data[recordKey], &p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse preview record %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+	return &p, nil
+}
+
+// Get returns the preview record with the given name or nil if it does not exist
+func Get(ctx context.Context, c client.Client, namespace string, name string) (*Preview, error) {
+	var cm corev1.ConfigMap
+	err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: buildConfigMapName(name)}, &cm)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseConfigMap(&cm)
+}
+
+// List returns all preview records of the namespace, sorted by name
+func List(ctx context.Context, c client.Client, namespace string) ([]Preview, error) {
+	var l corev1.ConfigMapList
+	err := c.List(ctx, &l, client.InNamespace(namespace), client.MatchingLabels{PreviewLabel: "true"})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	ret := make([]Preview, 0, len(l.Items))
+	for i := range l.Items {
+		p, err := parseConfigMap(&l.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, *p)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+// Write creates or updates the preview record. The namespace of the record is created if it does not exist yet.
+func Write(ctx context.Context, c client.Client, namespace string, p *Preview) error {
+	_, err := EnsureNamespace(ctx, c, namespace, nil)
+	if err != nil {
+		return err
+	}
+
+	j, err := yaml.WriteJsonString(p)
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{
+		PreviewLabel: "true",
+	}
+	data := map[string]string{
+		recordKey: j,
+	}
+
+	var cm corev1.ConfigMap
+	err = c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: buildConfigMapName(p.Name)}, &cm)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		cm = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      buildConfigMapName(p.Name),
+				Namespace: namespace,
+				Labels:    labels,
+			},
+			Data: data,
+		}
+		return c.Create(ctx, &cm)
+	}
+	cm.Labels = labels
+	cm.Data = data
+	return c.Update(ctx, &cm)
+}
+
+// Delete removes the preview record. A missing record is not treated as error.
+func Delete(ctx context.Context, c client.Client, namespace string, name string) error {
+	cm := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      buildConfigMapName(name),
+			Namespace: namespace,
+		},
+	}
+	err := c.Delete(ctx, &cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// EnsureNamespace creates the namespace with the given labels if it does not exist yet. It returns true if the
+// namespace got created.
+func EnsureNamespace(ctx context.Context, c client.Client, namespace string, labels map[string]string) (bool, error) {
+	var ns corev1.Namespace
+	err := c.Get(ctx, client.ObjectKey{Name: namespace}, &ns)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, err
+	}
+	ns = corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   namespace,
+			Labels: labels,
+		},
+	}
+	err = c.Create(ctx, &ns)
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
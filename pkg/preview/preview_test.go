@@ -0,0 +1,90 @@
+package preview
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestClient(t *testing.T) client.Client {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).Build()
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, ValidateName("pr-123"))
+	assert.Error(t, ValidateName("PR-123"))
+	assert.Error(t, ValidateName("pr_123"))
+	assert.Error(t, ValidateName(""))
+}
+
+func TestRecords(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	p, err := Get(ctx, c, "kluctl-results", "pr-1")
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+
+	now := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	for _, name := range []string{"pr-2", "pr-1"} {
+		err = Write(ctx, c, "kluctl-results", &Preview{
+			Name:          name,
+			Target:        "preview",
+			Namespace:     name,
+			Discriminator: "app-" + name,
+			CreationTime:  now,
+			LastDeployed:  now,
+		})
+		assert.NoError(t, err)
+	}
+
+	p, err = Get(ctx, c, "kluctl-results", "pr-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "app-pr-1", p.Discriminator)
+	assert.True(t, p.LastDeployed.Equal(&now))
+
+	l, err := List(ctx, c, "kluctl-results")
+	assert.NoError(t, err)
+	assert.Len(t, l, 2)
+	assert.Equal(t, "pr-1", l[0].Name)
+	assert.Equal(t, "pr-2", l[1].Name)
+
+	assert.NoError(t, Delete(ctx, c, "kluctl-results", "pr-1"))
+	assert.NoError(t, Delete(ctx, c, "kluctl-results", "pr-1"))
+	l, err = List(ctx, c, "kluctl-results")
+	assert.NoError(t, err)
+	assert.Len(t, l, 1)
+}
+
+func TestEnsureNamespace(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	created, err := EnsureNamespace(ctx, c, "pr-1", map[string]string{PreviewLabel: "pr-1"})
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = EnsureNamespace(ctx, c, "pr-1", nil)
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	var ns corev1.Namespace
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Name: "pr-1"}, &ns))
+	assert.Equal(t, "pr-1", ns.Labels[PreviewLabel])
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	p := Preview{LastDeployed: metav1.NewTime(now.Add(-48 * time.Hour))}
+	assert.True(t, p.IsExpired(24*time.Hour, now))
+	assert.False(t, p.IsExpired(72*time.Hour, now))
+}
@@ -0,0 +1,187 @@
+package projectlock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+)
+
+const DefaultLockFileName = ".kluctl-lock.yaml"
+
+// Drift describes a single input that was resolved differently than recorded in the lock
+type Drift struct {
+	// Input is a human-readable description of the input, e.g. the git url and ref
+	Input string
+	// Locked is the value found in the lock, empty if the input is not part of the lock
+	Locked string
+	// Actual is the value that was resolved
+	Actual string
+}
+
+func (d Drift) String() string {
+	if d.Locked == "" {
+		return fmt.Sprintf("%s resolved to %s, but is not part of the lock", d.Input, d.Actual)
+	}
+	return fmt.Sprintf("%s resolved to %s, but is locked to %s", d.Input, d.Actual, d.Locked)
+}
+
+// Compare compares the actually resolved inputs against the locked inputs and returns all inputs that deviate. Inputs
+// that are only part of the lock are ignored, as these are usually caused by conditional includes.
+func Compare(locked *types.ProjectLock, actual *types.ProjectLock) []Drift {
+	var ret []Drift
+	ret = append(ret, compareList(locked.Git, actual.Git, gitKey, func(l types.GitLock) string {
+		return fmt.Sprintf("git repository %s with ref '%s'", l.Url, l.Ref)
+	}, func(l types.GitLock) string {
+		return "commit " + l.Commit
+	})...)
+	ret = append(ret, compareList(locked.Oci, actual.Oci, ociKey, func(l types.OciLock) string {
+		return fmt.Sprintf("oci artifact %s with ref '%s'", l.Url, l.Ref)
+	}, func(l types.OciLock) string {
+		return "digest " + l.Digest
+	})...)
+	ret = append(ret, compareList(locked.HelmCharts, actual.HelmCharts, helmChartKey, func(l types.HelmChartLock) string {
+		return fmt.Sprintf("helm chart %s from %s with version %s", l.Chart, l.Repo, l.Version)
+	}, func(l types.HelmChartLock) string {
+		return "digest " + l.Digest
+	})...)
+	ret = append(ret, compareList(locked.Images, actual.Images, imageKey, func(l types.ImageLock) string {
+		return fmt.Sprintf("image %s of container '%s' in %s", l.Image, l.Container, l.Object)
+	}, func(l types.ImageLock) string {
+		return fmt.Sprintf("'%s'", l.ResultImage)
+	})...)
+	ret = append(ret, compareList(locked.VarsSources, actual.VarsSources, varsSourceKey, func(l types.VarsSourceLock) string {
+		return fmt.Sprintf("%s vars source %s", l.Type, l.Source)
+	}, func(l types.VarsSourceLock) string {
+		return "hash " + l.Hash
+	})...)
+	return ret
+}
+
+func compareList[T any](locked []T, actual []T, key func(T) string, describe func(T) string, value func(T) string) []Drift {
+	lockedByKey := map[string]T{}
+	for _, l := range locked {
+		lockedByKey[key(l)] = l
+	}
+	var ret []Drift
+	for _, a := range actual {
+		d := Drift{
+			Input:  describe(a),
+			Actual: value(a),
+		}
+		if l, ok := lockedByKey[key(a)]; ok {
+			if value(l) == d.Actual {
+				continue
+			}
+			d.Locked = value(l)
+		}
+		ret = append(ret, d)
+	}
+	return ret
+}
+
+func gitKey(l types.GitLock) string {
+	return l.Url + "#" + l.Ref
+}
+
+func ociKey(l types.OciLock) string {
+	return l.Url + ":" + l.Ref
+}
+
+func helmChartKey(l types.HelmChartLock) string {
+	return l.Repo + "/" + l.Chart + "@" + l.Version
+}
+
+func imageKey(l types.ImageLock) string {
+	return l.Object + "/" + l.Container + "/" + l.Image
+}
+
+func varsSourceKey(l types.VarsSourceLock) string {
+	return l.Type + ":" + l.Source
+}
+
+// LoadLockFile loads the lock file from the given path. A missing file results in an empty lock file.
+func LoadLockFile(path string) (*types.LockFile, error) {
+	var lf types.LockFile
+	if !utils.Exists(path) {
+		return &lf, nil
+	}
+	err := yaml.ReadYamlFile(path, &lf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lock file %s: %w", path, err)
+	}
+	return &lf, nil
+}
+
+// GetTargetLock returns the lock of the given target or an error if the lock file contains no entry for it
+func GetTargetLock(lf *types.LockFile, target string) (*types.ProjectLock, error) {
+	l, ok := lf.Targets[target]
+	if !ok {
+		return nil, fmt.Errorf("lock file contains no entry for target '%s', call 'kluctl lock write' first", target)
+	}
+	return &l, nil
+}
+
+// WriteTargetLock updates the lock of the given target in the lock file at the given path. Locks of other targets are
+// preserved.
+func WriteTargetLock(path string, target string, l *types.ProjectLock) error {
+	lf, err := LoadLockFile(path)
+	if err != nil {
+		return err
+	}
+	if lf.Targets == nil {
+		lf.Targets = map[string]types.ProjectLock{}
+	}
+	lf.Targets[target] = *l
+	return yaml.WriteYamlFile(path, lf)
+}
+
+// HashDir calculates a sha256 hash over the relative paths and contents of all files in the given directory
+func HashDir(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, p := range files {
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return "", err
+		}
+		_, _ = fmt.Fprintf(h, "%s\n", filepath.ToSlash(rel))
+		err = hashFile(h, p)
+		if err != nil {
+			return "", err
+		}
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,72 @@
+package projectlock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	locked := NewRecorder()
+	locked.RecordGit(types.GitLock{Url: "https://example.com/repo.git", Ref: "refs/heads/main", Commit: "c1"})
+	locked.RecordImage(types.ImageLock{Image: "app", Object: "default/Deployment/app", Container: "app", ResultImage: "app:1"})
+	locked.RecordOci(types.OciLock{Url: "oci://example.com/unused", Digest: "sha256:1"})
+
+	actual := NewRecorder()
+	actual.RecordGit(types.GitLock{Url: "https://example.com/repo.git", Ref: "refs/heads/main", Commit: "c1"})
+	assert.Empty(t, Compare(locked.GetLock(), actual.GetLock()))
+
+	actual.RecordGit(types.GitLock{Url: "https://example.com/repo.git", Ref: "refs/heads/main", Commit: "c2"})
+	actual.RecordImage(types.ImageLock{Image: "app", Object: "default/Deployment/app", Container: "app", ResultImage: "app:1"})
+	actual.RecordHelmChart(types.HelmChartLock{Repo: "https://charts.example.com", Chart: "redis", Version: "1.0.0", Digest: "sha256:2"})
+
+	drifts := Compare(locked.GetLock(), actual.GetLock())
+	assert.Equal(t, []Drift{
+		{Input: "git repository https://example.com/repo.git with ref 'refs/heads/main'", Locked: "commit c1", Actual: "commit c2"},
+		{Input: "helm chart redis from https://charts.example.com with version 1.0.0", Actual: "digest sha256:2"},
+	}, drifts)
+	assert.Equal(t, "git repository https://example.com/repo.git with ref 'refs/heads/main' resolved to commit c2, but is locked to commit c1", drifts[0].String())
+	assert.Equal(t, "helm chart redis from https://charts.example.com with version 1.0.0 resolved to digest sha256:2, but is not part of the lock", drifts[1].String())
+}
+
+func TestWriteTargetLock(t *testing.T) {
+	p := filepath.Join(t.TempDir(), DefaultLockFileName)
+
+	lf, err := LoadLockFile(p)
+	assert.NoError(t, err)
+	_, err = GetTargetLock(lf, "prod")
+	assert.ErrorContains(t, err, "no entry for target 'prod'")
+
+	r := NewRecorder()
+	r.RecordGit(types.GitLock{Url: "https://example.com/repo.git", Commit: "c1"})
+	assert.NoError(t, WriteTargetLock(p, "prod", r.GetLock()))
+	assert.NoError(t, WriteTargetLock(p, "test", &types.ProjectLock{}))
+
+	lf, err = LoadLockFile(p)
+	assert.NoError(t, err)
+	assert.Len(t, lf.Targets, 2)
+	l, err := GetTargetLock(lf, "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "c1", l.Git[0].Commit)
+}
+
+func TestHashDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("name: test"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "cm.yaml"), []byte("a: b"), 0o600))
+
+	h1, err := HashDir(dir)
+	assert.NoError(t, err)
+	h2, err := HashDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, h1, h2)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "cm.yaml"), []byte("a: c"), 0o600))
+	h3, err := HashDir(dir)
+	assert.NoError(t, err)
+	assert.NotEqual(t, h1, h3)
+}
@@ -0,0 +1,120 @@
+package projectlock
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+)
+
+// Recorder records all inputs that are resolved from external sources while a project is loaded and rendered, e.g.
+// git commits, OCI digests, Helm Charts, images and vars sources. A nil recorder ignores everything.
+type Recorder struct {
+	mutex sync.Mutex
+
+	git         map[string]types.GitLock
+	oci         map[string]types.OciLock
+	helmCharts  map[string]types.HelmChartLock
+	images      map[string]types.ImageLock
+	varsSources map[string]types.VarsSourceLock
+}
+
+type recorderKey struct{}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		git:         map[string]types.GitLock{},
+		oci:         map[string]types.OciLock{},
+		helmCharts:  map[string]types.HelmChartLock{},
+		images:      map[string]types.ImageLock{},
+		varsSources: map[string]types.VarsSourceLock{},
+	}
+}
+
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+func GetRecorder(ctx context.Context) *Recorder {
+	v := ctx.Value(recorderKey{})
+	if v == nil {
+		return nil
+	}
+	return v.(*Recorder)
+}
+
+func (r *Recorder) RecordGit(l types.GitLock) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.git[gitKey(l)] = l
+}
+
+func (r *Recorder) RecordOci(l types.OciLock) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.oci[ociKey(l)] = l
+}
+
+func (r *Recorder) RecordHelmChart(l types.HelmChartLock) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.helmCharts[helmChartKey(l)] = l
+}
+
+func (r *Recorder) RecordImage(l types.ImageLock) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.images[imageKey(l)] = l
+}
+
+func (r *Recorder) RecordVarsSource(l types.VarsSourceLock) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.varsSources[varsSourceKey(l)] = l
+}
+
+// GetLock returns everything recorded so far, sorted so that the result is stable between runs
+func (r *Recorder) GetLock() *types.ProjectLock {
+	if r == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return &types.ProjectLock{
+		Git:         sortedValues(r.git),
+		Oci:         sortedValues(r.oci),
+		HelmCharts:  sortedValues(r.helmCharts),
+		Images:      sortedValues(r.images),
+		VarsSources: sortedValues(r.varsSources),
+	}
+}
+
+func sortedValues[T any](m map[string]T) []T {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var ret []T
+	for _, k := range keys {
+		ret = append(ret, m[k])
+	}
+	return ret
+}
@@ -0,0 +1,339 @@
+package repocache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/stats"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const DefaultFetchConcurrency = 4
+
+type FetchPriority int
+
+const (
+	// FetchPriorityNormal is used for fetches that are not required to load the project itself, e.g. Helm Chart pulls
+	FetchPriorityNormal FetchPriority = iota
+	// FetchPriorityHigh is used for fetches that block loading of the project, e.g. git includes and OCI includes
+	FetchPriorityHigh
+)
+
+type FetchRequest struct {
+	// Type is the type of the source, e.g. git, oci or helm
+	Type string
+	// Source is the human-readable source, e.g. the url of the git repository
+	Source string
+	// Key identifies identical fetches. Fetches with the same key are only performed once per scheduler. An empty key
+	// disables deduplication.
+	Key      string
+	Priority FetchPriority
+	// Dir is the directory the fetch writes to. If set and stats are collected, the growth of the directory is
+	// recorded as fetched bytes.
+	Dir string
+}
+
+// FetchScheduler limits the number of concurrent network fetches (git fetches, Helm Chart pulls and OCI pulls),
+// retries fetches that failed due to rate limiting or server errors and records timings for all performed fetches.
+// A nil scheduler performs all fetches directly.
+type FetchScheduler struct {
+	concurrency    int
+	maxAttempts    int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+
+	mutex   sync.Mutex
+	running int
+	seq     int
+	waiters []*fetchWaiter
+	fetches map[string]*fetchEntry
+	entries []*fetchEntry
+}
+
+type fetchWaiter struct {
+	priority FetchPriority
+	seq      int
+	ch       chan struct{}
+}
+
+type fetchEntry struct {
+	timing result.FetchTiming
+	done   chan struct{}
+	err    error
+}
+
+type fetchSchedulerKey struct{}
+
+func NewFetchScheduler(concurrency int) *FetchScheduler {
+	if concurrency <= 0 {
+		concurrency = DefaultFetchConcurrency
+	}
+	return &FetchScheduler{
+		concurrency:    concurrency,
+		maxAttempts:    5,
+		retryBaseDelay: time.Second,
+		retryMaxDelay:  time.Second * 30,
+		fetches:        map[string]*fetchEntry{},
+	}
+}
+
+func WithFetchScheduler(ctx context.Context, s *FetchScheduler) context.Context {
+	return context.WithValue(ctx, fetchSchedulerKey{}, s)
+}
+
+func GetFetchScheduler(ctx context.Context) *FetchScheduler {
+	v := ctx.Value(fetchSchedulerKey{})
+	if v == nil {
+		return nil
+	}
+	return v.(*FetchScheduler)
+}
+
+// Run performs the fetch implemented by fn. It waits for a free slot, retries with jittered backoff on retryable
+// errors and returns the result of an already performed or currently running identical fetch if one exists.
+func (s *FetchScheduler) Run(ctx context.Context, req FetchRequest, fn func() error) error {
+	if s == nil {
+		return fn()
+	}
+
+	s.mutex.Lock()
+	if req.Key != "" {
+		if e, ok := s.fetches[req.Key]; ok {
+			e.timing.Deduplicated++
+			s.mutex.Unlock()
+			select {
+			case <-e.done:
+				return e.err
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	e := &fetchEntry{
+		timing: result.FetchTiming{
+			Type:   req.Type,
+			Source: req.Source,
+		},
+		done: make(chan struct{}),
+	}
+	if req.Key != "" {
+		s.fetches[req.Key] = e
+	}
+	s.entries = append(s.entries, e)
+	s.mutex.Unlock()
+
+	measureBytes := req.Dir != "" && stats.FromContext(ctx) != nil
+	var sizeBefore int64
+	if measureBytes {
+		sizeBefore, _ = utils.DirSize(req.Dir)
+	}
+
+	startTime := time.Now()
+	attempts, err := s.runWithRetries(ctx, req, fn)
+	endTime := time.Now()
+
+	var bytes int64
+	if measureBytes && err == nil {
+		sizeAfter, _ := utils.DirSize(req.Dir)
+		bytes = max(sizeAfter-sizeBefore, 0)
+		stats.RecordFetchedBytes(ctx, req.Type, bytes)
+	}
+
+	s.mutex.Lock()
+	e.timing.StartTime = metav1.NewMicroTime(startTime)
+	e.timing.EndTime = metav1.NewMicroTime(endTime)
+	e.timing.Attempts = attempts
+	e.timing.Bytes = bytes
+	if err != nil {
+		e.timing.Error = err.Error()
+	}
+	e.err = err
+	s.mutex.Unlock()
+	close(e.done)
+
+	if err != nil {
+		status.Tracef(ctx, "Fetching %s %s failed after %s (%d attempts): %s", req.Type, req.Source, endTime.Sub(startTime).Round(time.Millisecond), attempts, err.Error())
+	} else {
+		status.Tracef(ctx, "Fetched %s %s in %s (%d attempts)", req.Type, req.Source, endTime.Sub(startTime).Round(time.Millisecond), attempts)
+	}
+
+	return err
+}
+
+func (s *FetchScheduler) runWithRetries(ctx context.Context, req FetchRequest, fn func() error) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := s.acquire(ctx, req.Priority)
+		if err != nil {
+			return attempt - 1, err
+		}
+		err = fn()
+		s.release()
+
+		if err == nil || attempt >= s.maxAttempts || !IsRetryableFetchError(err) {
+			return attempt, err
+		}
+
+		delay := s.retryDelay(attempt)
+		status.Tracef(ctx, "Fetching %s %s failed with a retryable error, retrying in %s: %s", req.Type, req.Source, delay.Round(time.Millisecond), err.Error())
+
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return attempt, err
+		}
+	}
+}
+
+// retryDelay returns an exponential backoff with jitter, so that parallel fetches that got rate limited at the same
+// time do not retry at the same time
+func (s *FetchScheduler) retryDelay(attempt int) time.Duration {
+	d := s.retryBaseDelay << (attempt - 1)
+	if d <= 0 || d > s.retryMaxDelay {
+		d = s.retryMaxDelay
+	}
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+func (s *FetchScheduler) acquire(ctx context.Context, priority FetchPriority) error {
+	s.mutex.Lock()
+	if s.running < s.concurrency && len(s.waiters) == 0 {
+		s.running++
+		s.mutex.Unlock()
+		return nil
+	}
+	w := &fetchWaiter{
+		priority: priority,
+		seq:      s.seq,
+		ch:       make(chan struct{}),
+	}
+	s.seq++
+	s.waiters = append(s.waiters, w)
+	s.mutex.Unlock()
+
+	select {
+	case <-w.ch:
+		return nil
+	case <-ctx.Done():
+		s.mutex.Lock()
+		for i, w2 := range s.waiters {
+			if w2 == w {
+				s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+				s.mutex.Unlock()
+				return ctx.Err()
+			}
+		}
+		s.mutex.Unlock()
+		// we got the slot handed over in the meantime, so we must give it back
+		s.release()
+		return ctx.Err()
+	}
+}
+
+func (s *FetchScheduler) release() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.waiters) == 0 {
+		s.running--
+		return
+	}
+
+	// hand over the slot to the waiter with the highest priority, preferring the one that waits the longest
+	best := 0
+	for i, w := range s.waiters {
+		if w.priority > s.waiters[best].priority || (w.priority == s.waiters[best].priority && w.seq < s.waiters[best].seq) {
+			best = i
+		}
+	}
+	w := s.waiters[best]
+	s.waiters = append(s.waiters[:best], s.waiters[best+1:]...)
+	close(w.ch)
+}
+
+// GetTimings returns the timings of all fetches performed so far, sorted by start time
+func (s *FetchScheduler) GetTimings() []result.FetchTiming {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var ret []result.FetchTiming
+	for _, e := range s.entries {
+		select {
+		case <-e.done:
+			ret = append(ret, e.timing)
+		default:
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].StartTime.Before(&ret[j].StartTime)
+	})
+	return ret
+}
+
+var retryableFetchStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+var statusCodeRegex = regexp.MustCompile(`status code:? (\d{3})`)
+
+// IsRetryableFetchError returns true if the error was caused by rate limiting (429) or a server error (5xx). Git and
+// Helm do not return typed errors in all cases, so the error message is checked as well.
+func IsRetryableFetchError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	isRetryableCode := func(code int) bool {
+		for _, c := range retryableFetchStatusCodes {
+			if c == code {
+				return true
+			}
+		}
+		return false
+	}
+
+	var terr *transport.Error
+	if errors.As(err, &terr) {
+		return isRetryableCode(terr.StatusCode)
+	}
+
+	msg := err.Error()
+	for _, m := range statusCodeRegex.FindAllStringSubmatch(msg, -1) {
+		code, _ := strconv.Atoi(m[1])
+		if isRetryableCode(code) {
+			return true
+		}
+	}
+	for _, c := range retryableFetchStatusCodes {
+		if strings.Contains(msg, fmt.Sprintf("%d %s", c, http.StatusText(c))) {
+			return true
+		}
+	}
+	return false
+}
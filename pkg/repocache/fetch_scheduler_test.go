@@ -0,0 +1,180 @@
+package repocache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFetchScheduler(concurrency int) *FetchScheduler {
+	s := NewFetchScheduler(concurrency)
+	s.retryBaseDelay = time.Millisecond
+	s.retryMaxDelay = time.Millisecond * 10
+	return s
+}
+
+func TestFetchSchedulerConcurrency(t *testing.T) {
+	s := newTestFetchScheduler(2)
+
+	var running, maxRunning atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.Run(context.Background(), FetchRequest{Type: "git", Source: fmt.Sprintf("repo-%d", i)}, func() error {
+				n := running.Add(1)
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond * 10)
+				running.Add(-1)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxRunning.Load())
+	assert.Len(t, s.GetTimings(), 10)
+}
+
+func TestFetchSchedulerDeduplication(t *testing.T) {
+	s := newTestFetchScheduler(4)
+
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.Run(context.Background(), FetchRequest{Type: "git", Source: "repo", Key: "git:repo"}, func() error {
+				calls.Add(1)
+				time.Sleep(time.Millisecond * 10)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	timings := s.GetTimings()
+	assert.Len(t, timings, 1)
+	assert.Equal(t, 4, timings[0].Deduplicated)
+}
+
+func TestFetchSchedulerRetry(t *testing.T) {
+	s := newTestFetchScheduler(1)
+
+	attempts := 0
+	err := s.Run(context.Background(), FetchRequest{Type: "helm", Source: "chart"}, func() error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("failed to fetch https://example.com/index.yaml : 429 Too Many Requests")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 3, s.GetTimings()[0].Attempts)
+
+	attempts = 0
+	err = s.Run(context.Background(), FetchRequest{Type: "helm", Source: "chart2"}, func() error {
+		attempts++
+		return fmt.Errorf("not found")
+	})
+	assert.EqualError(t, err, "not found")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestFetchSchedulerPriority(t *testing.T) {
+	s := newTestFetchScheduler(1)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = s.Run(context.Background(), FetchRequest{Type: "git", Source: "blocker"}, func() error {
+			close(started)
+			<-block
+			return nil
+		})
+	}()
+	<-started
+
+	var mutex sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	run := func(source string, priority FetchPriority) {
+		defer wg.Done()
+		_ = s.Run(context.Background(), FetchRequest{Type: "git", Source: source, Priority: priority}, func() error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			order = append(order, source)
+			return nil
+		})
+	}
+
+	wg.Add(1)
+	go run("normal", FetchPriorityNormal)
+	assert.Eventually(t, func() bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		return len(s.waiters) == 1
+	}, time.Second, time.Millisecond)
+	wg.Add(1)
+	go run("high", FetchPriorityHigh)
+	assert.Eventually(t, func() bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		return len(s.waiters) == 2
+	}, time.Second, time.Millisecond)
+
+	close(block)
+	wg.Wait()
+
+	assert.Equal(t, []string{"high", "normal"}, order)
+}
+
+func TestFetchSchedulerBytes(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "existing"), make([]byte, 100), 0o600))
+
+	fetch := func() error {
+		return os.WriteFile(filepath.Join(dir, "fetched"), make([]byte, 50), 0o600)
+	}
+
+	// bytes are only measured when stats are collected
+	s := newTestFetchScheduler(1)
+	assert.NoError(t, s.Run(context.Background(), FetchRequest{Type: "git", Source: "repo", Dir: dir}, fetch))
+	assert.Equal(t, int64(0), s.GetTimings()[0].Bytes)
+
+	assert.NoError(t, os.Remove(filepath.Join(dir, "fetched")))
+
+	c := stats.NewCollector()
+	ctx := stats.NewContext(context.Background(), c)
+	s = newTestFetchScheduler(1)
+	assert.NoError(t, s.Run(ctx, FetchRequest{Type: "git", Source: "repo", Dir: dir}, fetch))
+	assert.Equal(t, int64(50), s.GetTimings()[0].Bytes)
+	assert.Equal(t, map[string]int64{"git": 50}, c.Build().FetchedBytes)
+}
+
+func TestIsRetryableFetchError(t *testing.T) {
+	assert.True(t, IsRetryableFetchError(fmt.Errorf("unexpected requesting \"https://example.com/repo.git/info/refs\" status code: 503")))
+	assert.True(t, IsRetryableFetchError(fmt.Errorf("failed to fetch: 502 Bad Gateway")))
+	assert.False(t, IsRetryableFetchError(fmt.Errorf("unexpected requesting \"https://example.com/repo.git/info/refs\" status code: 404")))
+	assert.False(t, IsRetryableFetchError(fmt.Errorf("authentication required")))
+	assert.False(t, IsRetryableFetchError(nil))
+}
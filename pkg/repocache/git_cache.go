@@ -12,7 +12,10 @@ import (
 
 	"github.com/kluctl/kluctl/lib/git/types"
 	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/projectlock"
 	"github.com/kluctl/kluctl/v2/pkg/sourceoverride"
+	"github.com/kluctl/kluctl/v2/pkg/stats"
+	types2 "github.com/kluctl/kluctl/v2/pkg/types"
 
 	"github.com/kluctl/kluctl/lib/git"
 	"github.com/kluctl/kluctl/lib/git/auth"
@@ -81,6 +84,20 @@ func (rp *GitRepoCache) Clear() {
 }
 
 func (rp *GitRepoCache) GetEntry(url string) (*GitCacheEntry, error) {
+	e, err := rp.getOrCreateEntry(url)
+	if err != nil {
+		return nil, err
+	}
+
+	// updating happens outside of reposMutex so that different repositories can be fetched in parallel
+	err = e.Update()
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (rp *GitRepoCache) getOrCreateEntry(url string) (*GitCacheEntry, error) {
 	rp.reposMutex.Lock()
 	defer rp.reposMutex.Unlock()
 
@@ -127,10 +144,6 @@ func (rp *GitRepoCache) GetEntry(url string) (*GitCacheEntry, error) {
 		}
 		rp.repos[repoKey] = e
 	}
-	err = e.Update()
-	if err != nil {
-		return nil, err
-	}
 	return e, nil
 }
 
@@ -150,12 +163,20 @@ func (e *GitCacheEntry) Update() error {
 
 	if !e.mr.HasUpdated() {
 		if time.Now().Sub(e.mr.LastUpdateTime()) <= e.rp.updateInterval {
+			stats.RecordCacheLookup(e.rp.ctx, "git", true)
 			e.mr.SetUpdated(true)
 		} else {
+			stats.RecordCacheLookup(e.rp.ctx, "git", false)
 			url := e.mr.Url()
 			s := status.Startf(e.rp.ctx, "Updating git cache for %s", url.String())
 			defer s.Failed()
-			err := e.mr.Update()
+			err := GetFetchScheduler(e.rp.ctx).Run(e.rp.ctx, FetchRequest{
+				Type:     "git",
+				Source:   url.String(),
+				Key:      "git:" + url.Normalize().String(),
+				Priority: FetchPriorityHigh,
+				Dir:      e.mr.MirrorDir(),
+			}, e.mr.Update)
 			if err != nil {
 				s.FailedWithMessage(err.Error())
 				return err
@@ -237,6 +258,10 @@ func (e *GitCacheEntry) GetClonedDir(ref *types.GitRef) (string, git.CheckoutInf
 	}
 	defer e.mr.Unlock()
 
+	requestedRef := ref.String()
+	if ref != nil && ref.Commit != "" {
+		requestedRef = ref.Commit
+	}
 	if ref == nil {
 		ref = &e.defaultRef
 	}
@@ -265,5 +290,12 @@ func (e *GitCacheEntry) GetClonedDir(ref *types.GitRef) (string, git.CheckoutInf
 		dir:  p,
 		info: checkoutInfo,
 	}
+
+	projectlock.GetRecorder(e.rp.ctx).RecordGit(types2.GitLock{
+		Url:    e.url.String(),
+		Ref:    requestedRef,
+		Commit: commit,
+	})
+
 	return p, checkoutInfo, nil
 }
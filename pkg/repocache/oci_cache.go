@@ -10,7 +10,9 @@ import (
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
 	"github.com/kluctl/kluctl/v2/pkg/oci/client"
+	"github.com/kluctl/kluctl/v2/pkg/projectlock"
 	"github.com/kluctl/kluctl/v2/pkg/sourceoverride"
+	"github.com/kluctl/kluctl/v2/pkg/stats"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	cp "github.com/otiai10/copy"
@@ -164,6 +166,7 @@ func (e *OciCacheEntry) GetExtractedDir(ref *types.OciRef) (string, git.Checkout
 
 	ed, ok := e.pulledDirs[*ref]
 	if ok {
+		stats.RecordCacheLookup(e.rp.ctx, "oci", true)
 		return ed.dir, ed.info, nil
 	}
 
@@ -181,12 +184,34 @@ func (e *OciCacheEntry) GetExtractedDir(ref *types.OciRef) (string, git.Checkout
 	}
 
 	image := strings.TrimPrefix(e.url.String(), "oci://") + ref.ImageSuffix()
-
-	md, err := e.ociClient.Pull(e.rp.ctx, image, ociDir)
+	stats.RecordCacheLookup(e.rp.ctx, "oci", false)
+
+	// no deduplication key is passed as pulledDirs already ensures that every image is only pulled once
+	var md *client.Metadata
+	err = GetFetchScheduler(e.rp.ctx).Run(e.rp.ctx, FetchRequest{
+		Type:     "oci",
+		Source:   image,
+		Priority: FetchPriorityHigh,
+		Dir:      ociDir,
+	}, func() error {
+		var err error
+		md, err = e.ociClient.Pull(e.rp.ctx, image, ociDir)
+		return err
+	})
 	if err != nil {
 		return "", git.CheckoutInfo{}, err
 	}
 
+	digest := md.Digest
+	if i := strings.LastIndex(digest, "@"); i != -1 {
+		digest = digest[i+1:]
+	}
+	projectlock.GetRecorder(e.rp.ctx).RecordOci(types.OciLock{
+		Url:    e.url.String(),
+		Ref:    ref.String(),
+		Digest: digest,
+	})
+
 	var cd clonedDir
 	cd.dir = ociDir
 
@@ -0,0 +1,476 @@
+package results
+
+import (
+	"fmt"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// well known names which do not leak any internal information and are thus kept as-is
+var anonymizeKeepNames = map[string]bool{
+	"default":         true,
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+// urlRegex finds URLs in free text, so that the hosts can be collected
+var urlRegex = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>\\]+`)
+
+// Anonymizer performs a consistent pseudonymization of command results. The same original value is always mapped to
+// the same placeholder, so that references between objects, errors and changes stay intact.
+type Anonymizer struct {
+	mapping  map[string]map[string]string
+	reverse  map[string]string
+	counters map[string]int
+
+	replaceRegex *regexp.Regexp
+}
+
+func NewAnonymizer() *Anonymizer {
+	return &Anonymizer{
+		mapping:  map[string]map[string]string{},
+		reverse:  map[string]string{},
+		counters: map[string]int{},
+	}
+}
+
+// Mapping returns the placeholder -> original mapping, grouped by category. It can be used to de-anonymize answers
+// that refer to placeholders.
+func (a *Anonymizer) Mapping() map[string]map[string]string {
+	ret := map[string]map[string]string{}
+	for category, m := range a.mapping {
+		m2 := map[string]string{}
+		for orig, placeholder := range m {
+			m2[placeholder] = orig
+		}
+		ret[category] = m2
+	}
+	return ret
+}
+
+func (a *Anonymizer) placeholder(category string, s string) string {
+	if s == "" || anonymizeKeepNames[s] {
+		return s
+	}
+	if p, ok := a.reverse[s]; ok {
+		// the same value might appear in different categories (e.g. a namespace named like the target), so we re-use
+		// the first placeholder to keep free text replacement consistent
+		return p
+	}
+	m, ok := a.mapping[category]
+	if !ok {
+		m = map[string]string{}
+		a.mapping[category] = m
+	}
+	a.counters[category]++
+	p := fmt.Sprintf("%s-%d", category, a.counters[category])
+	m[s] = p
+	a.reverse[s] = p
+	a.replaceRegex = nil
+	return p
+}
+
+func (a *Anonymizer) placeholderPtr(category string, s *string) *string {
+	if s == nil {
+		return nil
+	}
+	p := a.placeholder(category, *s)
+	return &p
+}
+
+func (a *Anonymizer) buildReplaceRegex() *regexp.Regexp {
+	if a.replaceRegex != nil {
+		return a.replaceRegex
+	}
+	var originals []string
+	for orig := range a.reverse {
+		// very short values would lead to too many false positives
+		if len(orig) < 3 {
+			continue
+		}
+		originals = append(originals, orig)
+	}
+	if len(originals) == 0 {
+		return nil
+	}
+	// longest first, so that "my-app-db" is replaced before "my-app"
+	sort.Slice(originals, func(i, j int) bool {
+		if len(originals[i]) != len(originals[j]) {
+			return len(originals[i]) > len(originals[j])
+		}
+		return originals[i] < originals[j]
+	})
+	for i := range originals {
+		originals[i] = regexp.QuoteMeta(originals[i])
+	}
+	a.replaceRegex = regexp.MustCompile(`\b(` + strings.Join(originals, "|") + `)\b`)
+	return a.replaceRegex
+}
+
+func (a *Anonymizer) replaceText(s string) string {
+	r := a.buildReplaceRegex()
+	if r == nil || s == "" {
+		return s
+	}
+	return r.ReplaceAllStringFunc(s, func(m string) string {
+		return a.reverse[m]
+	})
+}
+
+func (a *Anonymizer) collectRef(ref k8s.ObjectRef) {
+	a.placeholder("namespace", ref.Namespace)
+	a.placeholder("name", ref.Name)
+}
+
+func (a *Anonymizer) collectHost(host string) {
+	host = strings.TrimPrefix(host, "*.")
+	// in-cluster service hosts are built from names and namespaces, which are already replaced individually
+	if host == "" || strings.HasSuffix(host, ".svc") || strings.HasSuffix(host, ".cluster.local") {
+		return
+	}
+	a.placeholder("host", host)
+}
+
+func (a *Anonymizer) collectHostsFromText(s string) {
+	for _, m := range urlRegex.FindAllString(s, -1) {
+		u, err := url.Parse(m)
+		if err != nil {
+			continue
+		}
+		a.collectHost(u.Hostname())
+	}
+}
+
+// collectHostsFromObject collects the hosts of all URLs found in string values and the hosts of Ingresses and Gateway
+// API routes, which are not URLs and thus not found otherwise
+func (a *Anonymizer) collectHostsFromObject(o *uo.UnstructuredObject) {
+	if o == nil {
+		return
+	}
+	_ = o.NewIterator().IterateLeafs(func(it *uo.ObjectIterator) error {
+		if s, ok := it.Value().(string); ok {
+			a.collectHostsFromText(s)
+		}
+		return nil
+	})
+
+	gvk := o.GetK8sGVK()
+	var hosts []string
+	switch {
+	case gvk.Group == "networking.k8s.io" && gvk.Kind == "Ingress":
+		rules, _, _ := o.GetNestedObjectList("spec", "rules")
+		for _, r := range rules {
+			h, _, _ := r.GetNestedString("host")
+			hosts = append(hosts, h)
+		}
+		tls, _, _ := o.GetNestedObjectList("spec", "tls")
+		for _, x := range tls {
+			l, _, _ := x.GetNestedStringList("hosts")
+			hosts = append(hosts, l...)
+		}
+	case gvk.Group == "gateway.networking.k8s.io":
+		hosts, _, _ = o.GetNestedStringList("spec", "hostnames")
+	}
+	for _, h := range hosts {
+		a.collectHost(h)
+	}
+}
+
+func (a *Anonymizer) collectHostsFromJson(j *apiextensionsv1.JSON) {
+	if j != nil {
+		a.collectHostsFromText(string(j.Raw))
+	}
+}
+
+func (a *Anonymizer) anonymizeRef(ref k8s.ObjectRef) k8s.ObjectRef {
+	ref.Namespace = a.placeholder("namespace", ref.Namespace)
+	ref.Name = a.placeholder("name", ref.Name)
+	return ref
+}
+
+func (a *Anonymizer) anonymizeRepoKey(k gittypes.RepoKey) gittypes.RepoKey {
+	if k.Host == "" && k.Path == "" {
+		return k
+	}
+	k.Host = a.placeholder("host", k.Host)
+	k.Path = a.placeholder("path", k.Path)
+	return k
+}
+
+func (a *Anonymizer) anonymizeGitUrl(u *gittypes.GitUrl) *gittypes.GitUrl {
+	if u == nil {
+		return nil
+	}
+	ret := *u
+	ret.User = nil
+	ret.Host = a.placeholder("host", u.Hostname())
+	ret.Path = "/" + a.placeholder("path", strings.TrimPrefix(u.Path, "/"))
+	ret.RawPath = ""
+	return &ret
+}
+
+func (a *Anonymizer) anonymizeObject(o *uo.UnstructuredObject) (*uo.UnstructuredObject, error) {
+	if o == nil {
+		return nil, nil
+	}
+	o = o.Clone()
+	err := o.NewIterator().IterateLeafs(func(it *uo.ObjectIterator) error {
+		s, ok := it.Value().(string)
+		if !ok {
+			return nil
+		}
+		return it.SetValue(a.replaceText(s))
+	})
+	if err != nil {
+		return nil, err
+	}
+	// keys (e.g. labels and annotations) might contain names and hosts as well
+	a.anonymizeKeys(o.Object)
+	return o, nil
+}
+
+func (a *Anonymizer) anonymizeKeys(v any) {
+	switch v2 := v.(type) {
+	case map[string]any:
+		for k, x := range v2 {
+			a.anonymizeKeys(x)
+			k2 := a.replaceText(k)
+			if k2 != k {
+				delete(v2, k)
+				v2[k2] = x
+			}
+		}
+	case []any:
+		for _, x := range v2 {
+			a.anonymizeKeys(x)
+		}
+	}
+}
+
+func (a *Anonymizer) anonymizeJson(j *apiextensionsv1.JSON) *apiextensionsv1.JSON {
+	if j == nil {
+		return nil
+	}
+	// placeholders never need escaping, so replacing inside the raw JSON is safe
+	return &apiextensionsv1.JSON{Raw: []byte(a.replaceText(string(j.Raw)))}
+}
+
+func (a *Anonymizer) anonymizeFixedImages(l []types.FixedImage) {
+	for i := range l {
+		fi := &l[i]
+		fi.Image = a.placeholderPtr("image", fi.Image)
+		fi.ImageRegex = a.placeholderPtr("image", fi.ImageRegex)
+		fi.ResultImage = a.placeholder("image", fi.ResultImage)
+		fi.DeployedImage = a.placeholderPtr("image", fi.DeployedImage)
+		fi.Namespace = a.placeholderPtr("namespace", fi.Namespace)
+		fi.Deployment = a.placeholderPtr("name", fi.Deployment)
+		fi.Container = a.placeholderPtr("name", fi.Container)
+		fi.DeploymentDir = a.placeholderPtr("path", fi.DeploymentDir)
+		if fi.Object != nil {
+			ref := a.anonymizeRef(*fi.Object)
+			fi.Object = &ref
+		}
+	}
+}
+
+func (a *Anonymizer) anonymizeErrors(l []result.DeploymentError) []result.DeploymentError {
+	for i := range l {
+		l[i].Ref = a.anonymizeRef(l[i].Ref)
+		l[i].Message = a.replaceText(l[i].Message)
+		for j := range l[i].Causes {
+			l[i].Causes[j].Field = a.replaceText(l[i].Causes[j].Field)
+			l[i].Causes[j].Message = a.replaceText(l[i].Causes[j].Message)
+		}
+	}
+	return l
+}
+
+// AnonymizeCommandResult returns an anonymized copy of the given command result. Names, namespaces, cluster ids,
+// contexts, discriminators, repository URLs and hosts (found in URLs, Ingresses and Gateway API routes) are replaced by
+// placeholders, in values and map keys. The structure, object counts, kinds and change types are kept intact.
+func (a *Anonymizer) AnonymizeCommandResult(crIn *result.CommandResult) (*result.CommandResult, error) {
+	cr := crIn.DeepCopy()
+
+	// first pass, collect all values that need to be replaced in free text (messages, diffs, rendered objects, ...)
+	a.placeholder("target", cr.Target.Name)
+	a.placeholder("target", cr.TargetKey.TargetName)
+	a.placeholder("cluster", cr.ClusterInfo.ClusterId)
+	a.placeholder("cluster", cr.TargetKey.ClusterId)
+	if cr.Target.Context != nil {
+		a.placeholder("context", *cr.Target.Context)
+	}
+	if cr.KluctlDeployment != nil {
+		a.placeholder("namespace", cr.KluctlDeployment.Namespace)
+		a.placeholder("name", cr.KluctlDeployment.Name)
+	}
+	a.collectHost(cr.ProjectKey.RepoKey.Host)
+	if cr.GitInfo.Url != nil {
+		a.collectHost(cr.GitInfo.Url.Hostname())
+	}
+	a.collectHostsFromObject(cr.Target.Args)
+	a.collectHostsFromObject(cr.Command.Args)
+	a.collectHostsFromObject(cr.OverridesPatch)
+	for _, o := range cr.Objects {
+		a.collectRef(o.Ref)
+		for _, o2 := range []*uo.UnstructuredObject{o.Rendered, o.Remote, o.Applied, o.DeletedManifest} {
+			a.collectHostsFromObject(o2)
+		}
+		for _, c := range append(append([]result.Change{}, o.Changes...), o.IgnoredChanges...) {
+			a.collectHostsFromJson(c.OldValue)
+			a.collectHostsFromJson(c.NewValue)
+			a.collectHostsFromText(c.UnifiedDiff)
+		}
+	}
+	for _, e := range append(append([]result.DeploymentError{}, cr.Errors...), cr.Warnings...) {
+		a.collectRef(e.Ref)
+		a.collectHostsFromText(e.Message)
+	}
+	for _, p := range cr.Phases {
+		for _, ref := range p.Objects {
+			a.collectRef(ref)
+		}
+	}
+	if cr.ResourceDelta != nil {
+		for _, x := range cr.ResourceDelta.Namespaces {
+			a.placeholder("namespace", x.Namespace)
+		}
+	}
+	for _, c := range cr.OwnershipConflicts {
+		a.collectRef(c.Ref)
+		a.placeholder("target", c.OtherTarget)
+		a.placeholder("discriminator", c.OtherDiscriminator)
+	}
+
+	// second pass, replace everything
+	cr.ProjectKey.RepoKey = a.anonymizeRepoKey(cr.ProjectKey.RepoKey)
+	cr.GitInfo.Url = a.anonymizeGitUrl(cr.GitInfo.Url)
+
+	cr.TargetKey.TargetName = a.placeholder("target", cr.TargetKey.TargetName)
+	cr.TargetKey.ClusterId = a.placeholder("cluster", cr.TargetKey.ClusterId)
+	cr.TargetKey.Discriminator = a.placeholder("discriminator", cr.TargetKey.Discriminator)
+	cr.ClusterInfo.ClusterId = a.placeholder("cluster", cr.ClusterInfo.ClusterId)
+
+	cr.Target.Name = a.placeholder("target", cr.Target.Name)
+	cr.Target.Context = a.placeholderPtr("context", cr.Target.Context)
+	cr.Target.Discriminator = a.placeholder("discriminator", cr.Target.Discriminator)
+
+	cr.Command.Target = a.placeholder("target", cr.Command.Target)
+	cr.Command.TargetNameOverride = a.placeholder("target", cr.Command.TargetNameOverride)
+	cr.Command.ContextOverride = a.placeholder("context", cr.Command.ContextOverride)
+	if cr.Command.Invocation != nil {
+		for i, x := range cr.Command.Invocation.Args {
+			cr.Command.Invocation.Args[i] = a.replaceText(x)
+		}
+		for k, v := range cr.Command.Invocation.Env {
+			cr.Command.Invocation.Env[k] = a.replaceText(v)
+		}
+	}
+
+	if cr.KluctlDeployment != nil {
+		cr.KluctlDeployment.Name = a.placeholder("name", cr.KluctlDeployment.Name)
+		cr.KluctlDeployment.Namespace = a.placeholder("namespace", cr.KluctlDeployment.Namespace)
+		cr.KluctlDeployment.ClusterId = a.placeholder("cluster", cr.KluctlDeployment.ClusterId)
+	}
+
+	a.anonymizeFixedImages(cr.SeenImages)
+	a.anonymizeFixedImages(cr.Target.Images)
+	a.anonymizeFixedImages(cr.Command.Images)
+	if cr.Target.Aws != nil {
+		cr.Target.Aws.Profile = a.placeholderPtr("aws-profile", cr.Target.Aws.Profile)
+		if cr.Target.Aws.ServiceAccount != nil {
+			cr.Target.Aws.ServiceAccount.Name = a.placeholder("name", cr.Target.Aws.ServiceAccount.Name)
+			cr.Target.Aws.ServiceAccount.Namespace = a.placeholder("namespace", cr.Target.Aws.ServiceAccount.Namespace)
+		}
+	}
+
+	var err error
+	cr.Target.Args, err = a.anonymizeObject(cr.Target.Args)
+	if err != nil {
+		return nil, err
+	}
+	cr.Command.Args, err = a.anonymizeObject(cr.Command.Args)
+	if err != nil {
+		return nil, err
+	}
+	cr.OverridesPatch, err = a.anonymizeObject(cr.OverridesPatch)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cr.Objects {
+		o := &cr.Objects[i]
+		o.Ref = a.anonymizeRef(o.Ref)
+		for j := range o.Changes {
+			c := &o.Changes[j]
+			c.OldValue = a.anonymizeJson(c.OldValue)
+			c.NewValue = a.anonymizeJson(c.NewValue)
+			c.UnifiedDiff = a.replaceText(c.UnifiedDiff)
+			c.JsonPath = a.replaceText(c.JsonPath)
+		}
+		for j := range o.IgnoredChanges {
+			c := &o.IgnoredChanges[j]
+			c.JsonPath = a.replaceText(c.JsonPath)
+			c.OldValue = a.anonymizeJson(c.OldValue)
+			c.NewValue = a.anonymizeJson(c.NewValue)
+			c.UnifiedDiff = a.replaceText(c.UnifiedDiff)
+		}
+		o.Rendered, err = a.anonymizeObject(o.Rendered)
+		if err != nil {
+			return nil, err
+		}
+		o.Remote, err = a.anonymizeObject(o.Remote)
+		if err != nil {
+			return nil, err
+		}
+		o.Applied, err = a.anonymizeObject(o.Applied)
+		if err != nil {
+			return nil, err
+		}
+		o.DeletedManifest, err = a.anonymizeObject(o.DeletedManifest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range cr.Phases {
+		p := &cr.Phases[i]
+		for j := range p.Objects {
+			p.Objects[j] = a.anonymizeRef(p.Objects[j])
+		}
+	}
+	if cr.ResourceDelta != nil {
+		for i := range cr.ResourceDelta.Namespaces {
+			x := &cr.ResourceDelta.Namespaces[i]
+			x.Namespace = a.placeholder("namespace", x.Namespace)
+		}
+	}
+	for i := range cr.OwnershipConflicts {
+		c := &cr.OwnershipConflicts[i]
+		c.Ref = a.anonymizeRef(c.Ref)
+		c.OtherTarget = a.placeholder("target", c.OtherTarget)
+		c.OtherDiscriminator = a.placeholder("discriminator", c.OtherDiscriminator)
+	}
+	for i := range cr.Fetches {
+		f := &cr.Fetches[i]
+		f.Source = a.placeholder("source", f.Source)
+		f.Error = a.replaceText(f.Error)
+	}
+
+	cr.Errors = a.anonymizeErrors(cr.Errors)
+	cr.Warnings = a.anonymizeErrors(cr.Warnings)
+
+	// the deployment project config might contain arbitrary internal information (vars sources, URLs, ...), so
+	// we don't include it at all
+	cr.Deployment = nil
+
+	return cr, nil
+}
@@ -0,0 +1,129 @@
+package results
+
+import (
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAnonymizeCommandResult(t *testing.T) {
+	ref := k8s.ObjectRef{Kind: "ConfigMap", Name: "my-config", Namespace: "internal-ns"}
+	rendered := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":      "my-config",
+			"namespace": "internal-ns",
+		},
+		"data": map[string]any{
+			"url": "http://my-config.internal-ns.svc",
+		},
+	})
+	cr := &result.CommandResult{
+		ClusterInfo: result.ClusterInfo{ClusterId: "cluster-abc"},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: ref, Changes: []result.Change{{Type: "update", JsonPath: "data.url"}}}, Rendered: rendered},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "Namespace", Name: "default"}}},
+		},
+		Errors: []result.DeploymentError{{Ref: ref, Message: "failed to apply my-config in internal-ns"}},
+	}
+
+	a := NewAnonymizer()
+	acr, err := a.AnonymizeCommandResult(cr)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "cluster-1", acr.ClusterInfo.ClusterId)
+	assert.Equal(t, k8s.ObjectRef{Kind: "ConfigMap", Name: "name-1", Namespace: "namespace-1"}, acr.Objects[0].Ref)
+	assert.Equal(t, "default", acr.Objects[1].Ref.Name)
+	assert.Equal(t, "data.url", acr.Objects[0].Changes[0].JsonPath)
+	assert.Equal(t, acr.Objects[0].Ref, acr.Errors[0].Ref)
+	assert.Equal(t, "failed to apply name-1 in namespace-1", acr.Errors[0].Message)
+	url, _, _ := acr.Objects[0].Rendered.GetNestedString("data", "url")
+	assert.Equal(t, "http://name-1.namespace-1.svc", url)
+
+	// the original must not be modified
+	assert.Equal(t, "my-config", cr.Objects[0].Ref.Name)
+
+	m := a.Mapping()
+	assert.Equal(t, "my-config", m["name"]["name-1"])
+	assert.Equal(t, "internal-ns", m["namespace"]["namespace-1"])
+}
+
+func TestAnonymizeCommandResultHosts(t *testing.T) {
+	ingress := uo.FromMap(map[string]any{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata": map[string]any{
+			"name":      "my-ingress",
+			"namespace": "internal-ns",
+			"annotations": map[string]any{
+				"cert-manager.io/cluster-issuer": "letsencrypt",
+			},
+		},
+		"spec": map[string]any{
+			"rules": []any{
+				map[string]any{"host": "app.corp.example.com"},
+			},
+			"tls": []any{
+				map[string]any{"hosts": []any{"*.wildcard.example.com"}},
+			},
+		},
+	})
+	cm := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":      "my-config",
+			"namespace": "internal-ns",
+			"labels": map[string]any{
+				"db.internal.example.org/owner": "team",
+			},
+		},
+		"data": map[string]any{
+			"backend": "https://user@db.internal.example.org:5432/path?x=y",
+			"public":  "see app.corp.example.com and foo.wildcard.example.com",
+		},
+	})
+	cr := &result.CommandResult{
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Group: "networking.k8s.io", Kind: "Ingress", Name: "my-ingress", Namespace: "internal-ns"}}, Rendered: ingress},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "my-config", Namespace: "internal-ns"}}, Rendered: cm},
+		},
+		Errors: []result.DeploymentError{{Message: "failed to connect to db.internal.example.org"}},
+	}
+
+	a := NewAnonymizer()
+	acr, err := a.AnonymizeCommandResult(cr)
+	assert.NoError(t, err)
+
+	y, err := yaml.WriteYamlString(acr)
+	assert.NoError(t, err)
+	for _, s := range []string{"app.corp.example.com", "wildcard.example.com", "db.internal.example.org"} {
+		assert.NotContains(t, y, s)
+	}
+
+	m := a.Mapping()["host"]
+	reverse := map[string]string{}
+	for p, h := range m {
+		reverse[h] = p
+	}
+	assert.Len(t, reverse, 3)
+
+	host, _, _ := acr.Objects[0].Rendered.GetNestedString("spec", "rules", 0, "host")
+	assert.Equal(t, reverse["app.corp.example.com"], host)
+	tlsHosts, _, _ := acr.Objects[0].Rendered.GetNestedStringList("spec", "tls", 0, "hosts")
+	assert.Equal(t, []string{"*." + reverse["wildcard.example.com"]}, tlsHosts)
+	annotations := acr.Objects[0].Rendered.GetK8sAnnotations()
+	assert.Equal(t, "letsencrypt", annotations["cert-manager.io/cluster-issuer"])
+
+	backend, _, _ := acr.Objects[1].Rendered.GetNestedString("data", "backend")
+	assert.Equal(t, "https://user@"+reverse["db.internal.example.org"]+":5432/path?x=y", backend)
+	public, _, _ := acr.Objects[1].Rendered.GetNestedString("data", "public")
+	assert.Equal(t, "see "+reverse["app.corp.example.com"]+" and foo."+reverse["wildcard.example.com"], public)
+	labels := acr.Objects[1].Rendered.GetK8sLabels()
+	assert.Equal(t, map[string]string{reverse["db.internal.example.org"] + "/owner": "team"}, labels)
+	assert.Equal(t, "failed to connect to "+reverse["db.internal.example.org"], acr.Errors[0].Message)
+}
@@ -0,0 +1,188 @@
+// Package clusternotify implements deploy notifications that are written as ConfigMaps into the target cluster, so
+// that in-cluster consumers can react to deployments without the need for webhooks.
+//
+// The ConfigMaps do not carry the discriminator label of the deployment, which means that they are never considered
+// to be orphan objects.
+package clusternotify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NotificationLabel is set on all notification ConfigMaps
+	NotificationLabel = "kluctl.io/deploy-notification"
+	// TargetHashLabel identifies the project and target of the notification and is used to apply retention
+	TargetHashLabel = "kluctl.io/deploy-notification-target-hash"
+
+	DefaultKeep = 10
+
+	// maxErrorSummary is the maximum number of errors listed in the error summary
+	maxErrorSummary = 10
+)
+
+// BuildTargetHash returns a label-safe hash of the project and target of the command result
+func BuildTargetHash(cr *result.CommandResult) (string, error) {
+	s, err := yaml.WriteJsonString(map[string]any{
+		"project": cr.ProjectKey,
+		"target":  cr.TargetKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])[:32], nil
+}
+
+func buildErrorSummary(errs []result.DeploymentError) string {
+	var lines []string
+	for i, e := range errs {
+		if i == maxErrorSummary {
+			lines = append(lines, fmt.Sprintf("... and %d more errors", len(errs)-maxErrorSummary))
+			break
+		}
+		if e.Ref.Name != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", e.Ref.String(), e.Message))
+		} else {
+			lines = append(lines, e.Message)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// BuildConfigMap builds the notification ConfigMap for the given command result
+func BuildConfigMap(cr *result.CommandResult, namespace string) (*corev1.ConfigMap, error) {
+	targetHash, err := BuildTargetHash(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := cr.BuildSummary()
+
+	data := map[string]string{
+		"resultId":       cr.Id,
+		"command":        cr.Command.Command,
+		"target":         cr.TargetKey.TargetName,
+		"discriminator":  cr.TargetKey.Discriminator,
+		"clusterId":      cr.TargetKey.ClusterId,
+		"startTime":      cr.Command.StartTime.UTC().Format(time.RFC3339),
+		"endTime":        cr.Command.EndTime.UTC().Format(time.RFC3339),
+		"success":        strconv.FormatBool(len(cr.Errors) == 0),
+		"newObjects":     strconv.Itoa(summary.NewObjects),
+		"changedObjects": strconv.Itoa(summary.ChangedObjects),
+		"deletedObjects": strconv.Itoa(summary.DeletedObjects),
+		"orphanObjects":  strconv.Itoa(summary.OrphanObjects),
+		"errors":         strconv.Itoa(len(cr.Errors)),
+		"warnings":       strconv.Itoa(len(cr.Warnings)),
+	}
+	if cr.ProjectKey.RepoKey.String() != "" {
+		data["projectRepoKey"] = cr.ProjectKey.RepoKey.String()
+	}
+	if cr.ProjectKey.SubDir != "" {
+		data["projectSubDir"] = cr.ProjectKey.SubDir
+	}
+	if cr.GitInfo.Commit != "" {
+		data["sourceRevision"] = cr.GitInfo.Commit
+	}
+	if cr.GitInfo.Ref != nil {
+		data["sourceRef"] = cr.GitInfo.Ref.String()
+	}
+	if len(cr.Errors) != 0 {
+		data["errorSummary"] = buildErrorSummary(cr.Errors)
+	}
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deploy-notification-" + cr.Id,
+			Namespace: namespace,
+			Labels: map[string]string{
+				NotificationLabel: "true",
+				TargetHashLabel:   targetHash,
+			},
+		},
+		Data: data,
+	}, nil
+}
+
+func ensureNamespace(ctx context.Context, c client.Client, namespace string) error {
+	var ns corev1.Namespace
+	err := c.Get(ctx, client.ObjectKey{Name: namespace}, &ns)
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return err
+	}
+	ns = corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespace,
+		},
+	}
+	err = c.Create(ctx, &ns)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// Write creates the notification ConfigMap and then deletes old notifications of the same project and target, so that
+// only the newest keep notifications remain
+func Write(ctx context.Context, c client.Client, cm *corev1.ConfigMap, keep int) error {
+	err := ensureNamespace(ctx, c, cm.Namespace)
+	if err != nil {
+		return err
+	}
+
+	err = c.Create(ctx, cm, client.FieldOwner("kluctl-notifications"))
+	if err != nil {
+		return err
+	}
+
+	return cleanupOld(ctx, c, cm.Namespace, cm.Labels[TargetHashLabel], keep)
+}
+
+func cleanupOld(ctx context.Context, c client.Client, namespace string, targetHash string, keep int) error {
+	var l corev1.ConfigMapList
+	err := c.List(ctx, &l, client.InNamespace(namespace), client.MatchingLabels{
+		NotificationLabel: "true",
+		TargetHashLabel:   targetHash,
+	})
+	if err != nil {
+		return err
+	}
+
+	// newest first, RFC3339 timestamps in UTC sort lexicographically
+	sort.SliceStable(l.Items, func(i, j int) bool {
+		a, b := l.Items[i].Data["startTime"], l.Items[j].Data["startTime"]
+		if a != b {
+			return a > b
+		}
+		return l.Items[i].Name > l.Items[j].Name
+	})
+
+	for i := keep; i < len(l.Items); i++ {
+		err = c.Delete(ctx, &l.Items[i])
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete old deploy notification %s: %w", l.Items[i].Name, err)
+		}
+	}
+	return nil
+}
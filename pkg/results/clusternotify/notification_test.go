@@ -0,0 +1,102 @@
+package clusternotify
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestResult(id string, target string, minute int) *result.CommandResult {
+	return &result.CommandResult{
+		Id:         id,
+		ProjectKey: gittypes.ProjectKey{SubDir: "p1"},
+		TargetKey:  result.TargetKey{TargetName: target, ClusterId: "cluster-1", Discriminator: "d1"},
+		Command: result.CommandInfo{
+			Command:   "deploy",
+			StartTime: metav1.NewTime(time.Date(2024, 1, 1, 0, minute, 0, 0, time.UTC)),
+			EndTime:   metav1.NewTime(time.Date(2024, 1, 1, 0, minute, 30, 0, time.UTC)),
+		},
+		GitInfo: gittypes.GitInfo{Commit: "abcdef", Ref: &gittypes.GitRef{Branch: "main"}},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "new"}, New: true}},
+		},
+	}
+}
+
+func newTestClient(t *testing.T) client.Client {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).Build()
+}
+
+func TestBuildConfigMap(t *testing.T) {
+	cr := newTestResult("id-1", "t1", 0)
+	for i := 0; i < 12; i++ {
+		cr.Errors = append(cr.Errors, result.DeploymentError{Message: fmt.Sprintf("error %d", i)})
+	}
+	cr.Errors[0].Ref = k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: "cm"}
+
+	cm, err := BuildConfigMap(cr, "kluctl-results")
+	assert.NoError(t, err)
+	assert.Equal(t, "deploy-notification-id-1", cm.Name)
+	assert.Equal(t, "kluctl-results", cm.Namespace)
+	assert.Equal(t, "true", cm.Labels[NotificationLabel])
+	assert.Len(t, cm.Labels[TargetHashLabel], 32)
+
+	assert.Equal(t, "id-1", cm.Data["resultId"])
+	assert.Equal(t, "deploy", cm.Data["command"])
+	assert.Equal(t, "t1", cm.Data["target"])
+	assert.Equal(t, "abcdef", cm.Data["sourceRevision"])
+	assert.Equal(t, "refs/heads/main", cm.Data["sourceRef"])
+	assert.Equal(t, "2024-01-01T00:00:00Z", cm.Data["startTime"])
+	assert.Equal(t, "1", cm.Data["newObjects"])
+	assert.Equal(t, "0", cm.Data["changedObjects"])
+	assert.Equal(t, "12", cm.Data["errors"])
+	assert.Equal(t, "false", cm.Data["success"])
+	assert.Contains(t, cm.Data["errorSummary"], "ns/ConfigMap/cm: error 0\nerror 1\n")
+	assert.Contains(t, cm.Data["errorSummary"], "error 9\n... and 2 more errors")
+
+	cm2, err := BuildConfigMap(newTestResult("id-2", "t2", 0), "kluctl-results")
+	assert.NoError(t, err)
+	assert.NotEqual(t, cm.Labels[TargetHashLabel], cm2.Labels[TargetHashLabel])
+	assert.NotContains(t, cm2.Data, "errorSummary")
+}
+
+func TestWriteRetention(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	write := func(id string, target string, minute int) {
+		cm, err := BuildConfigMap(newTestResult(id, target, minute), "kluctl-results")
+		assert.NoError(t, err)
+		assert.NoError(t, Write(ctx, c, cm, 2))
+	}
+
+	// written out of order on purpose, retention must be based on the start time
+	write("id-2", "t1", 2)
+	write("id-1", "t1", 1)
+	write("id-3", "t1", 3)
+	write("id-other", "t2", 0)
+
+	var l corev1.ConfigMapList
+	assert.NoError(t, c.List(ctx, &l, client.InNamespace("kluctl-results")))
+	var names []string
+	for _, cm := range l.Items {
+		names = append(names, cm.Name)
+	}
+	assert.ElementsMatch(t, []string{"deploy-notification-id-2", "deploy-notification-id-3", "deploy-notification-id-other"}, names)
+
+	var ns corev1.Namespace
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Name: "kluctl-results"}, &ns))
+}
@@ -0,0 +1,167 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/results/mdreport"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+const DefaultApiURL = "https://api.github.com"
+
+// MaxCommentLength is the maximum size of an issue/pull request comment accepted by GitHub
+const MaxCommentLength = 65536
+
+const commentsPerPage = 100
+
+// Marker returns the invisible HTML marker that identifies comments created by kluctl for the given target. It is used
+// to find and update the comment of previous runs instead of creating a new one on every run.
+func Marker(targetName string) string {
+	return fmt.Sprintf("<!-- kluctl-result target=%q -->", targetName)
+}
+
+// BuildComment renders the markdown comment for the given command result. If the rendered comment exceeds maxLength,
+// the per-object diffs are omitted first. If it is still too large, it is truncated at a line boundary.
+func BuildComment(cr *result.CommandResult, marker string, maxLength int) string {
+	comment := marker + "\n" + mdreport.Render(cr, mdreport.Options{Collapsible: true})
+	if len(comment) <= maxLength {
+		return comment
+	}
+
+	const notice = "\n_The result is too large for a pull request comment, per-object diffs were omitted._\n"
+	comment = marker + "\n" + mdreport.Render(cr, mdreport.Options{Short: true}) + notice
+	if len(comment) <= maxLength {
+		return comment
+	}
+
+	const truncatedNotice = "\n_The result is too large for a pull request comment and was truncated._\n"
+	cut := maxLength - len(truncatedNotice)
+	if cut < 0 {
+		cut = 0
+	}
+	comment = comment[:cut]
+	if i := strings.LastIndexByte(comment, '\n'); i != -1 {
+		comment = comment[:i+1]
+	}
+	return comment + truncatedNotice
+}
+
+type Client struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+func NewClient(apiURL string, token string) *Client {
+	if apiURL == "" {
+		apiURL = DefaultApiURL
+	}
+	return &Client{
+		url:        strings.TrimSuffix(apiURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type comment struct {
+	Id   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+func (c *Client) do(ctx context.Context, method string, u string, body any, out any) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s failed with status %d: %s", method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	if out != nil {
+		err = json.Unmarshal(b, out)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) findComment(ctx context.Context, repo string, pr int, marker string) (*comment, error) {
+	for page := 1; ; page++ {
+		var comments []comment
+		u := fmt.Sprintf("%s/repos/%s/issues/%d/comments?per_page=%d&page=%d", c.url, repo, pr, commentsPerPage, page)
+		err := c.do(ctx, http.MethodGet, u, nil, &comments)
+		if err != nil {
+			return nil, err
+		}
+		for _, x := range comments {
+			if strings.Contains(x.Body, marker) {
+				return &x, nil
+			}
+		}
+		if len(comments) < commentsPerPage {
+			return nil, nil
+		}
+	}
+}
+
+// UpsertComment updates the pull request comment that contains marker or creates a new comment if none exists yet.
+// repo must be in the form 'owner/repo'. It returns the id of the comment.
+func (c *Client) UpsertComment(ctx context.Context, repo string, pr int, marker string, body string) (int64, error) {
+	if len(strings.Split(repo, "/")) != 2 {
+		return 0, fmt.Errorf("invalid repository '%s', must be in the form 'owner/repo'", repo)
+	}
+
+	existing, err := c.findComment(ctx, repo, pr, marker)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pull request comments: %w", err)
+	}
+
+	var x comment
+	if existing != nil {
+		u := fmt.Sprintf("%s/repos/%s/issues/comments/%s", c.url, repo, strconv.FormatInt(existing.Id, 10))
+		err = c.do(ctx, http.MethodPatch, u, map[string]string{"body": body}, &x)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update pull request comment: %w", err)
+		}
+	} else {
+		u := fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.url, repo, pr)
+		err = c.do(ctx, http.MethodPost, u, map[string]string{"body": body}, &x)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create pull request comment: %w", err)
+		}
+	}
+	return x.Id, nil
+}
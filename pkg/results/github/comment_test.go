@@ -0,0 +1,143 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGithub struct {
+	mutex    sync.Mutex
+	comments []comment
+	nextId   int64
+}
+
+func (f *fakeGithub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	const base = "/repos/org/repo/issues/"
+	var body map[string]string
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == base+"123/comments":
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		start := (page - 1) * perPage
+		end := start + perPage
+		if start > len(f.comments) {
+			start = len(f.comments)
+		}
+		if end > len(f.comments) {
+			end = len(f.comments)
+		}
+		_ = json.NewEncoder(w).Encode(f.comments[start:end])
+	case r.Method == http.MethodPost && r.URL.Path == base+"123/comments":
+		f.nextId++
+		c := comment{Id: f.nextId, Body: body["body"]}
+		f.comments = append(f.comments, c)
+		_ = json.NewEncoder(w).Encode(c)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, base+"comments/"):
+		for i := range f.comments {
+			if r.URL.Path == base+"comments/"+strconv.FormatInt(f.comments[i].Id, 10) {
+				f.comments[i].Body = body["body"]
+				_ = json.NewEncoder(w).Encode(f.comments[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestResult() *result.CommandResult {
+	return &result.CommandResult{
+		Target:  types.Target{Name: "prod"},
+		Command: result.CommandInfo{Command: "diff"},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "cm", Namespace: "ns"}, Changes: []result.Change{
+				{Type: "update", JsonPath: "data.key", UnifiedDiff: "-old\n+" + strings.Repeat("x", 1000)},
+			}}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "new", Namespace: "ns"}, New: true}},
+		},
+	}
+}
+
+func TestUpsertComment(t *testing.T) {
+	f := &fakeGithub{}
+	// make sure that pagination is handled
+	for i := 0; i < commentsPerPage; i++ {
+		f.nextId++
+		f.comments = append(f.comments, comment{Id: f.nextId, Body: "unrelated"})
+	}
+	s := httptest.NewServer(f)
+	defer s.Close()
+
+	c := NewClient(s.URL, "token")
+	marker := Marker("prod")
+
+	id, err := c.UpsertComment(context.Background(), "org/repo", 123, marker, marker+"\nfirst")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), id)
+	assert.Len(t, f.comments, 101)
+
+	id, err = c.UpsertComment(context.Background(), "org/repo", 123, marker, marker+"\nsecond")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), id)
+	assert.Len(t, f.comments, 101)
+	assert.Equal(t, marker+"\nsecond", f.comments[100].Body)
+
+	// a different target gets its own comment
+	id, err = c.UpsertComment(context.Background(), "org/repo", 123, Marker("test"), Marker("test")+"\nother")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(102), id)
+
+	_, err = NewClient(s.URL, "wrong").UpsertComment(context.Background(), "org/repo", 123, marker, "x")
+	assert.ErrorContains(t, err, "failed to list pull request comments")
+	assert.ErrorContains(t, err, "status 401")
+
+	_, err = c.UpsertComment(context.Background(), "repo", 123, marker, "x")
+	assert.ErrorContains(t, err, "must be in the form 'owner/repo'")
+}
+
+func TestBuildComment(t *testing.T) {
+	cr := newTestResult()
+	marker := Marker("prod")
+
+	n := BuildComment(cr, marker, MaxCommentLength)
+	assert.True(t, strings.HasPrefix(n, marker+"\n"))
+	assert.Contains(t, n, "### kluctl diff on target `prod`")
+	assert.Contains(t, n, "```diff\n# data.key\n-old\n+xxx")
+
+	// diffs are dropped first
+	n = BuildComment(cr, marker, 500)
+	assert.LessOrEqual(t, len(n), 500)
+	assert.NotContains(t, n, "```diff")
+	assert.Contains(t, n, "- `ns/ConfigMap/cm`")
+	assert.Contains(t, n, "per-object diffs were omitted")
+
+	// then the summary is truncated
+	n = BuildComment(cr, marker, 200)
+	assert.LessOrEqual(t, len(n), 200)
+	assert.True(t, strings.HasPrefix(n, marker+"\n"))
+	assert.Contains(t, n, "was truncated")
+}
@@ -0,0 +1,178 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/results/mdreport"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+const DefaultURL = "https://gitlab.com"
+
+// MaxNoteLength is the maximum size of a note accepted by GitLab
+const MaxNoteLength = 1000000
+
+// Marker returns the invisible HTML marker that identifies notes created by kluctl for the given target and
+// discriminator. It is used to find and update the note of previous runs instead of creating a new one on every run.
+func Marker(targetName string, discriminator string) string {
+	return fmt.Sprintf("<!-- kluctl-result target=%q discriminator=%q -->", targetName, discriminator)
+}
+
+// BuildNote renders the markdown note for the given command result. If the rendered note exceeds maxLength, the
+// per-object diffs are omitted first. If it is still too large, it is truncated and a link to artifactURL (if given) is
+// added.
+func BuildNote(cr *result.CommandResult, marker string, maxLength int, artifactURL string) string {
+	note := marker + "\n" + mdreport.Render(cr, mdreport.Options{})
+	if len(note) <= maxLength {
+		return note
+	}
+
+	var notice string
+	if artifactURL != "" {
+		notice = fmt.Sprintf("\n_The result is too large for a merge request note, see the [full result](%s)._\n", artifactURL)
+	} else {
+		notice = "\n_The result is too large for a merge request note and was truncated._\n"
+	}
+
+	note = marker + "\n" + mdreport.Render(cr, mdreport.Options{Short: true}) + notice
+	if len(note) <= maxLength {
+		return note
+	}
+
+	// cut at a line boundary, leaving room for the notice
+	cut := maxLength - len(notice)
+	if cut < 0 {
+		cut = 0
+	}
+	note = note[:cut]
+	if i := strings.LastIndexByte(note, '\n'); i != -1 {
+		note = note[:i+1]
+	}
+	return note + notice
+}
+
+type Client struct {
+	url         string
+	token       string
+	tokenHeader string
+	httpClient  *http.Client
+}
+
+// NewClient creates a client that authenticates with a personal, project or group access token
+func NewClient(baseURL string, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	return &Client{
+		url:         strings.TrimSuffix(baseURL, "/"),
+		token:       token,
+		tokenHeader: "PRIVATE-TOKEN",
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewJobTokenClient creates a client that authenticates with the CI_JOB_TOKEN of a GitLab CI job
+func NewJobTokenClient(baseURL string, jobToken string) *Client {
+	c := NewClient(baseURL, jobToken)
+	c.tokenHeader = "JOB-TOKEN"
+	return c
+}
+
+type note struct {
+	Id   int    `json:"id"`
+	Body string `json:"body"`
+}
+
+func (c *Client) notesURL(project string, mrIID int) string {
+	return fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d/notes", c.url, url.PathEscape(project), mrIID)
+}
+
+func (c *Client) do(ctx context.Context, method string, u string, body any, out any) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(c.tokenHeader, c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s failed with status %d: %s", method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	if out != nil {
+		err = json.Unmarshal(b, out)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func (c *Client) findNote(ctx context.Context, project string, mrIID int, marker string) (*note, error) {
+	page := "1"
+	for page != "" {
+		var notes []note
+		resp, err := c.do(ctx, http.MethodGet, fmt.Sprintf("%s?per_page=100&page=%s", c.notesURL(project, mrIID), page), nil, &notes)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			if strings.Contains(n.Body, marker) {
+				return &n, nil
+			}
+		}
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return nil, nil
+}
+
+// UpsertNote updates the merge request note that contains marker or creates a new note if none exists yet. It returns
+// the id of the note.
+func (c *Client) UpsertNote(ctx context.Context, project string, mrIID int, marker string, body string) (int, error) {
+	existing, err := c.findNote(ctx, project, mrIID, marker)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list merge request notes: %w", err)
+	}
+
+	var n note
+	if existing != nil {
+		_, err = c.do(ctx, http.MethodPut, c.notesURL(project, mrIID)+"/"+strconv.Itoa(existing.Id), map[string]string{"body": body}, &n)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update merge request note: %w", err)
+		}
+	} else {
+		_, err = c.do(ctx, http.MethodPost, c.notesURL(project, mrIID), map[string]string{"body": body}, &n)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create merge request note: %w", err)
+		}
+	}
+	return n.Id, nil
+}
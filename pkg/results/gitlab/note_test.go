@@ -0,0 +1,142 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGitlab struct {
+	mutex  sync.Mutex
+	notes  []note
+	nextId int
+	paths  []string
+}
+
+func (f *fakeGitlab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.paths = append(f.paths, r.Method+" "+r.URL.EscapedPath())
+	if r.Header.Get("PRIVATE-TOKEN") != "token" && r.Header.Get("JOB-TOKEN") != "job-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	const base = "/api/v4/projects/group%2Fproject/merge_requests/7/notes"
+	var body map[string]string
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.EscapedPath() == base:
+		_ = json.NewEncoder(w).Encode(f.notes)
+	case r.Method == http.MethodPost && r.URL.EscapedPath() == base:
+		f.nextId++
+		n := note{Id: f.nextId, Body: body["body"]}
+		f.notes = append(f.notes, n)
+		_ = json.NewEncoder(w).Encode(n)
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.EscapedPath(), base+"/"):
+		for i := range f.notes {
+			if r.URL.EscapedPath() == base+"/"+strconv.Itoa(f.notes[i].Id) {
+				f.notes[i].Body = body["body"]
+				_ = json.NewEncoder(w).Encode(f.notes[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestResult() *result.CommandResult {
+	return &result.CommandResult{
+		Target:  types.Target{Name: "prod"},
+		Command: result.CommandInfo{Command: "diff"},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "cm", Namespace: "ns"}, Changes: []result.Change{
+				{Type: "update", JsonPath: "data.key", UnifiedDiff: "-old\n+" + strings.Repeat("x", 1000)},
+			}}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "new", Namespace: "ns"}, New: true}},
+		},
+	}
+}
+
+func TestUpsertNote(t *testing.T) {
+	f := &fakeGitlab{
+		notes:  []note{{Id: 1, Body: "unrelated"}},
+		nextId: 1,
+	}
+	s := httptest.NewServer(f)
+	defer s.Close()
+
+	c := NewClient(s.URL, "token")
+	marker := Marker("prod", "")
+
+	id, err := c.UpsertNote(context.Background(), "group/project", 7, marker, marker+"\nfirst")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, id)
+	assert.Len(t, f.notes, 2)
+
+	id, err = c.UpsertNote(context.Background(), "group/project", 7, marker, marker+"\nsecond")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, id)
+	assert.Len(t, f.notes, 2)
+	assert.Equal(t, marker+"\nsecond", f.notes[1].Body)
+
+	// a different target gets its own note
+	id, err = c.UpsertNote(context.Background(), "group/project", 7, Marker("test", ""), Marker("test", "")+"\nother")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
+
+	// same for a different discriminator
+	id, err = c.UpsertNote(context.Background(), "group/project", 7, Marker("prod", "d2"), Marker("prod", "d2")+"\nother")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, id)
+
+	// CI job tokens are sent via a different header
+	id, err = NewJobTokenClient(s.URL, "job-token").UpsertNote(context.Background(), "group/project", 7, marker, marker+"\nthird")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, id)
+	assert.Equal(t, marker+"\nthird", f.notes[1].Body)
+
+	_, err = NewClient(s.URL, "wrong").UpsertNote(context.Background(), "group/project", 7, marker, "x")
+	assert.ErrorContains(t, err, "failed to list merge request notes")
+	assert.ErrorContains(t, err, "status 401")
+}
+
+func TestBuildNote(t *testing.T) {
+	cr := newTestResult()
+	marker := Marker("prod", "")
+
+	n := BuildNote(cr, marker, MaxNoteLength, "")
+	assert.True(t, strings.HasPrefix(n, marker+"\n"))
+	assert.Contains(t, n, "### kluctl diff on target `prod`")
+	assert.Contains(t, n, "```diff\n# data.key\n-old\n+xxx")
+	assert.Contains(t, n, "- `ns/ConfigMap/new`")
+
+	// diffs are dropped first
+	n = BuildNote(cr, marker, 500, "https://example.com/artifact")
+	assert.LessOrEqual(t, len(n), 500)
+	assert.NotContains(t, n, "```diff")
+	assert.Contains(t, n, "- `ns/ConfigMap/cm`")
+	assert.Contains(t, n, "[full result](https://example.com/artifact)")
+
+	// then the summary is truncated
+	n = BuildNote(cr, marker, 200, "")
+	assert.LessOrEqual(t, len(n), 200)
+	assert.True(t, strings.HasPrefix(n, marker+"\n"))
+	assert.Contains(t, n, "was truncated")
+}
@@ -0,0 +1,115 @@
+package htmlreport
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+//go:embed report.html
+var reportTemplateStr string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"diffLines":  diffLines,
+	"formatTime": formatTime,
+}).Parse(reportTemplateStr))
+
+type diffLine struct {
+	Class string
+	Text  string
+}
+
+type reportObject struct {
+	result.ResultObject
+	Status string
+}
+
+type reportData struct {
+	Result *result.CommandResult
+
+	Objects        []reportObject
+	ChangedObjects []reportObject
+
+	NewCount     int
+	ChangedCount int
+	DeletedCount int
+	OrphanCount  int
+	HookCount    int
+}
+
+func diffLines(s string) []diffLine {
+	var ret []diffLine
+	for _, l := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		c := ""
+		switch {
+		case strings.HasPrefix(l, "+"):
+			c = "add"
+		case strings.HasPrefix(l, "-"):
+			c = "del"
+		}
+		ret = append(ret, diffLine{Class: c, Text: l})
+	}
+	return ret
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+func buildReportData(cr *result.CommandResult) *reportData {
+	d := &reportData{
+		Result: cr,
+	}
+	for _, o := range cr.Objects {
+		ro := reportObject{ResultObject: o}
+		switch {
+		case o.New:
+			ro.Status = "new"
+			d.NewCount++
+		case o.Deleted:
+			ro.Status = "deleted"
+			d.DeletedCount++
+		case o.Orphan:
+			ro.Status = "orphan"
+			d.OrphanCount++
+		case len(o.Changes) != 0:
+			ro.Status = "changed"
+		case o.Hook:
+			ro.Status = "hook"
+		default:
+			ro.Status = "unchanged"
+		}
+		if o.Hook {
+			d.HookCount++
+		}
+		if len(o.Changes) != 0 {
+			d.ChangedCount++
+			d.ChangedObjects = append(d.ChangedObjects, ro)
+		}
+		d.Objects = append(d.Objects, ro)
+	}
+	return d
+}
+
+// Render writes a self-contained HTML report of the given command result, consisting of a summary, the list of
+// objects and collapsible diffs for all changed objects.
+func Render(w io.Writer, cr *result.CommandResult) error {
+	return reportTemplate.Execute(w, buildReportData(cr))
+}
+
+// RenderString is like Render but returns the report as string.
+func RenderString(cr *result.CommandResult) (string, error) {
+	var b strings.Builder
+	err := Render(&b, cr)
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
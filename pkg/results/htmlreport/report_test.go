@@ -0,0 +1,82 @@
+package htmlreport
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCommandResult() *result.CommandResult {
+	return &result.CommandResult{
+		Id:     "test-id",
+		Target: types.Target{Name: "prod"},
+		Command: result.CommandInfo{
+			Command: "deploy",
+		},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "new-cm", Namespace: "ns"}, New: true}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "changed-cm", Namespace: "ns"}, Changes: []result.Change{
+				{Type: "update", JsonPath: "data.key", UnifiedDiff: "-old<value>\n+new"},
+			}}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "Secret", Name: "orphan", Namespace: "ns"}, Orphan: true}},
+		},
+		Errors: []result.DeploymentError{
+			{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "changed-cm", Namespace: "ns"}, Message: "something failed"},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	s, err := RenderString(newTestCommandResult())
+	assert.NoError(t, err)
+
+	assert.Contains(t, s, "kluctl deploy on target prod")
+	assert.Contains(t, s, "New: 1")
+	assert.Contains(t, s, "Changed: 1")
+	assert.Contains(t, s, "Orphan: 1")
+	assert.Contains(t, s, "Errors: 1")
+	assert.Contains(t, s, "something failed")
+	assert.Contains(t, s, "ns/ConfigMap/new-cm")
+	assert.Contains(t, s, `<span class="status status-orphan">orphan</span>`)
+	assert.Contains(t, s, "<summary>ns/ConfigMap/changed-cm (1 changes)</summary>")
+	// diff lines are escaped and highlighted
+	assert.Contains(t, s, `<span class="del">-old&lt;value&gt;</span>`)
+	assert.Contains(t, s, `<span class="add">&#43;new</span>`)
+}
+
+func TestServe(t *testing.T) {
+	// find a free port on localhost
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := l.Addr().String()
+	_ = l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Serve(ctx, address, newTestCommandResult())
+	}()
+
+	var resp *http.Response
+	assert.Eventually(t, func() bool {
+		resp, err = http.Get("http://" + address + "/")
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	b, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(string(b), "<!DOCTYPE html>"))
+
+	cancel()
+	assert.NoError(t, <-errCh)
+}
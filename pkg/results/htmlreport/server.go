@@ -0,0 +1,81 @@
+package htmlreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+const DefaultServeAddress = "127.0.0.1:0"
+
+// Serve starts a temporary HTTP server that serves the HTML report of the given command result. The report is rendered
+// once upfront and kept in memory only. The server shuts down when ctx is cancelled or when the process receives
+// SIGINT/SIGTERM (e.g. Ctrl-C).
+func Serve(ctx context.Context, address string, cr *result.CommandResult) error {
+	if address == "" {
+		address = DefaultServeAddress
+	}
+
+	s, err := RenderString(cr)
+	if err != nil {
+		return err
+	}
+	report := []byte(s)
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(report)
+	})
+
+	httpServer := http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	status.Infof(ctx, "Command result is available at: http://%s (press Ctrl-C to stop)", listener.Addr().String())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err = <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		return fmt.Errorf("failed to shut down result server: %w", err)
+	}
+	err = <-errCh
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
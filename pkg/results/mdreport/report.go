@@ -0,0 +1,251 @@
+package mdreport
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// Options controls how command results are rendered
+type Options struct {
+	// Short omits the per-object diffs
+	Short bool
+	// Collapsible wraps diffs and manifests that are longer than collapsibleMinLines into <details> blocks
+	Collapsible bool
+	// ShowNewObjectYaml adds the rendered manifests of new objects. Ignored when Short is set.
+	ShowNewObjectYaml bool
+	// ShowDeletedObjectYaml adds the live manifests of deleted and orphan objects. Ignored when Short is set.
+	ShowDeletedObjectYaml bool
+	// MaxObjectYamlLines truncates manifests after the given number of lines. 0 disables truncation.
+	MaxObjectYamlLines int
+}
+
+const collapsibleMinLines = 20
+
+// Render renders the given command result as markdown, suitable for merge/pull request comments
+func Render(cr *result.CommandResult, opts Options) string {
+	buf := &strings.Builder{}
+
+	title := fmt.Sprintf("kluctl %s", cr.Command.Command)
+	if cr.Target.Name != "" {
+		title += fmt.Sprintf(" on target `%s`", cr.Target.Name)
+	}
+	if cr.Command.DryRun {
+		title += " (dry-run)"
+	}
+	if cr.Command.Partial {
+		title += " (partial)"
+	}
+	buf.WriteString(fmt.Sprintf("### %s\n\n", title))
+	if cr.Command.PartialNote != "" {
+		buf.WriteString(fmt.Sprintf("_%s_\n\n", cr.Command.PartialNote))
+	}
+
+	var newObjects, changedObjects, deletedObjects, orphanObjects []k8s.ObjectRef
+	var movedObjects []result.ResultObject
+	for _, o := range cr.Objects {
+		if o.New {
+			newObjects = append(newObjects, o.Ref)
+		}
+		if len(o.Changes) != 0 {
+			changedObjects = append(changedObjects, o.Ref)
+		}
+		if o.Deleted {
+			deletedObjects = append(deletedObjects, o.Ref)
+		}
+		if o.Orphan {
+			orphanObjects = append(orphanObjects, o.Ref)
+		}
+		if o.Moved != nil {
+			movedObjects = append(movedObjects, o)
+		}
+	}
+
+	buf.WriteString(fmt.Sprintf("New: %d, Changed: %d, Deleted: %d, Orphan: %d, Errors: %d, Warnings: %d\n",
+		len(newObjects), len(changedObjects), len(deletedObjects), len(orphanObjects), len(cr.Errors), len(cr.Warnings)))
+
+	writeRefs(buf, "New objects", newObjects)
+	if opts.ShowNewObjectYaml && !opts.Short {
+		for _, o := range cr.Objects {
+			if !o.New {
+				continue
+			}
+			m := o.Rendered
+			if m == nil {
+				m = o.Applied
+			}
+			writeManifest(buf, o.Ref, m, opts)
+		}
+	}
+	writeRefs(buf, "Changed objects", changedObjects)
+	if len(changedObjects) != 0 && !opts.Short {
+		for _, o := range cr.Objects {
+			if len(o.Changes) != 0 {
+				writeChanges(buf, o.Ref, o.Changes, opts.Collapsible)
+			}
+		}
+	}
+	if len(movedObjects) != 0 {
+		buf.WriteString("\n#### Moved objects\n\n")
+		for _, o := range movedObjects {
+			buf.WriteString(fmt.Sprintf("- `%s` (from `%s` to `%s`)\n", o.Ref.String(), o.Moved.FromItem, o.Moved.ToItem))
+		}
+	}
+	if cr.Command.DryRun {
+		writeRefs(buf, "Would delete objects", deletedObjects)
+	} else {
+		writeRefs(buf, "Deleted objects", deletedObjects)
+	}
+	writeLiveManifests(buf, cr, func(o result.ResultObject) bool { return o.Deleted }, opts)
+	writeRefs(buf, "Orphan objects", orphanObjects)
+	writeLiveManifests(buf, cr, func(o result.ResultObject) bool { return o.Orphan }, opts)
+	writeErrors(buf, "Warnings", cr.Warnings)
+	writeErrors(buf, "Errors", cr.Errors)
+
+	return buf.String()
+}
+
+// RenderValidateResult renders the given validation result as markdown, suitable for merge/pull request comments
+func RenderValidateResult(vr *result.ValidateResult) string {
+	buf := &strings.Builder{}
+
+	title := "kluctl validate"
+	if vr.TargetKey.TargetName != "" {
+		title += fmt.Sprintf(" on target `%s`", vr.TargetKey.TargetName)
+	}
+	buf.WriteString(fmt.Sprintf("### %s\n\n", title))
+	if vr.SourceResultId != "" {
+		buf.WriteString(fmt.Sprintf("_Validated objects from command result `%s`_\n\n", vr.SourceResultId))
+	}
+
+	ready := "no"
+	if vr.Ready {
+		ready = "yes"
+	}
+	buf.WriteString(fmt.Sprintf("Ready: %s, Errors: %d, Warnings: %d\n", ready, len(vr.Errors), len(vr.Warnings)))
+
+	writeErrors(buf, "Warnings", vr.Warnings)
+	writeErrors(buf, "Errors", vr.Errors)
+	if len(vr.Results) != 0 {
+		buf.WriteString("\n#### Results\n\n")
+		for _, e := range vr.Results {
+			buf.WriteString(fmt.Sprintf("- `%s`: %s\n", e.Ref.String(), e.Message))
+		}
+	}
+
+	return buf.String()
+}
+
+func writeRefs(buf *strings.Builder, title string, refs []k8s.ObjectRef) {
+	if len(refs) == 0 {
+		return
+	}
+	buf.WriteString(fmt.Sprintf("\n#### %s\n\n", title))
+	for _, ref := range refs {
+		buf.WriteString(fmt.Sprintf("- `%s`\n", ref.String()))
+	}
+}
+
+func writeErrors(buf *strings.Builder, title string, errors []result.DeploymentError) {
+	if len(errors) == 0 {
+		return
+	}
+	buf.WriteString(fmt.Sprintf("\n#### %s\n\n", title))
+	for _, e := range errors {
+		if s := e.Ref.String(); s != "" {
+			buf.WriteString(fmt.Sprintf("- `%s`: %s\n", s, e.Message))
+		} else {
+			buf.WriteString(fmt.Sprintf("- %s\n", e.Message))
+		}
+	}
+}
+
+func writeChanges(buf *strings.Builder, ref k8s.ObjectRef, changes []result.Change, collapsible bool) {
+	body := &strings.Builder{}
+	for _, c := range changes {
+		body.WriteString(fmt.Sprintf("# %s\n", c.JsonPath))
+		body.WriteString(strings.TrimSuffix(c.UnifiedDiff, "\n"))
+		body.WriteString("\n")
+	}
+	f := fence(body.String())
+
+	lines := strings.Count(body.String(), "\n")
+	if collapsible && lines > collapsibleMinLines {
+		buf.WriteString(fmt.Sprintf("\n<details>\n<summary>Diff for <code>%s</code> (%d lines)</summary>\n\n", html.EscapeString(ref.String()), lines))
+		buf.WriteString(fmt.Sprintf("%sdiff\n%s%s\n", f, body.String(), f))
+		buf.WriteString("\n</details>\n")
+		return
+	}
+
+	buf.WriteString(fmt.Sprintf("\nDiff for `%s`:\n\n", ref.String()))
+	buf.WriteString(fmt.Sprintf("%sdiff\n%s%s\n", f, body.String(), f))
+}
+
+// writeLiveManifests writes the manifests of all matching objects as they currently exist in the cluster
+func writeLiveManifests(buf *strings.Builder, cr *result.CommandResult, filter func(o result.ResultObject) bool, opts Options) {
+	if !opts.ShowDeletedObjectYaml || opts.Short {
+		return
+	}
+	for _, o := range cr.Objects {
+		if !filter(o) {
+			continue
+		}
+		m := o.Remote
+		if m == nil {
+			m = o.DeletedManifest
+		}
+		writeManifest(buf, o.Ref, m, opts)
+	}
+}
+
+func writeManifest(buf *strings.Builder, ref k8s.ObjectRef, m *uo.UnstructuredObject, opts Options) {
+	if m == nil {
+		return
+	}
+	body, err := yaml.WriteYamlString(m)
+	if err != nil {
+		body = fmt.Sprintf("# failed to convert manifest to yaml: %s\n", err.Error())
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(body, "\n"), "\n")
+	if opts.MaxObjectYamlLines > 0 && len(lines) > opts.MaxObjectYamlLines {
+		body = strings.Join(lines[:opts.MaxObjectYamlLines], "")
+		body += fmt.Sprintf("# (truncated, %d more lines)\n", len(lines)-opts.MaxObjectYamlLines)
+	}
+	f := fence(body)
+
+	n := strings.Count(body, "\n")
+	if opts.Collapsible && n > collapsibleMinLines {
+		buf.WriteString(fmt.Sprintf("\n<details>\n<summary>Manifest of <code>%s</code> (%d lines)</summary>\n\n", html.EscapeString(ref.String()), n))
+		buf.WriteString(fmt.Sprintf("%syaml\n%s%s\n", f, body, f))
+		buf.WriteString("\n</details>\n")
+		return
+	}
+
+	buf.WriteString(fmt.Sprintf("\nManifest of `%s`:\n\n", ref.String()))
+	buf.WriteString(fmt.Sprintf("%syaml\n%s%s\n", f, body, f))
+}
+
+// fence returns a code fence that is longer than any backtick sequence found in s
+func fence(s string) string {
+	longest := 0
+	cur := 0
+	for _, c := range s {
+		if c == '`' {
+			cur++
+			if cur > longest {
+				longest = cur
+			}
+		} else {
+			cur = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
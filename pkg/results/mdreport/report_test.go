@@ -0,0 +1,99 @@
+package mdreport
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestResult(diffLines int) *result.CommandResult {
+	ref := func(name string) k8s.ObjectRef {
+		return k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: name}
+	}
+	diff := &strings.Builder{}
+	for i := 0; i < diffLines; i++ {
+		diff.WriteString(fmt.Sprintf("+line %d\n", i))
+	}
+	return &result.CommandResult{
+		Command: result.CommandInfo{Command: "diff"},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: ref("new"), New: true}},
+			{BaseObject: result.BaseObject{Ref: ref("changed"), Changes: []result.Change{
+				{Type: "update", JsonPath: "data.a", UnifiedDiff: diff.String()},
+			}}},
+			{BaseObject: result.BaseObject{Ref: ref("orphan"), Orphan: true}},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	s := Render(buildTestResult(2), Options{})
+	assert.Contains(t, s, "#### New objects\n\n- `ns/ConfigMap/new`\n")
+	assert.Contains(t, s, "#### Changed objects\n\n- `ns/ConfigMap/changed`\n")
+	assert.Contains(t, s, "#### Orphan objects\n\n- `ns/ConfigMap/orphan`\n")
+	assert.Contains(t, s, "Diff for `ns/ConfigMap/changed`:\n\n```diff\n# data.a\n+line 0\n+line 1\n```\n")
+
+	s = Render(buildTestResult(2), Options{Short: true})
+	assert.NotContains(t, s, "```diff")
+}
+
+func TestRenderCollapsible(t *testing.T) {
+	// short diffs are never collapsed
+	s := Render(buildTestResult(2), Options{Collapsible: true})
+	assert.NotContains(t, s, "<details>")
+	assert.Contains(t, s, "```diff")
+
+	s = Render(buildTestResult(50), Options{Collapsible: true})
+	assert.Contains(t, s, "<details>\n<summary>Diff for <code>ns/ConfigMap/changed</code> (51 lines)</summary>\n\n```diff\n")
+	assert.Contains(t, s, "```\n\n</details>\n")
+
+	s = Render(buildTestResult(50), Options{})
+	assert.NotContains(t, s, "<details>")
+}
+
+func TestRenderObjectYaml(t *testing.T) {
+	cr := buildTestResult(2)
+	m := uo.New()
+	m.SetK8sGVKs("", "v1", "ConfigMap")
+	m.SetK8sName("x")
+	for i := 0; i < 30; i++ {
+		_ = m.SetNestedField("v", "data", fmt.Sprintf("k%02d", i))
+	}
+	cr.Objects[0].Rendered = m
+	cr.Objects[2].Remote = m
+
+	s := Render(cr, Options{})
+	assert.NotContains(t, s, "```yaml")
+
+	s = Render(cr, Options{ShowNewObjectYaml: true})
+	assert.Contains(t, s, "- `ns/ConfigMap/new`\n\nManifest of `ns/ConfigMap/new`:\n\n```yaml\napiVersion: v1\ndata:\n  k00: v\n")
+	assert.NotContains(t, s, "Manifest of `ns/ConfigMap/orphan`")
+
+	s = Render(cr, Options{ShowDeletedObjectYaml: true, MaxObjectYamlLines: 3})
+	assert.NotContains(t, s, "Manifest of `ns/ConfigMap/new`")
+	assert.Contains(t, s, "Manifest of `ns/ConfigMap/orphan`:\n\n```yaml\napiVersion: v1\ndata:\n  k00: v\n# (truncated, 32 more lines)\n```\n")
+
+	s = Render(cr, Options{ShowNewObjectYaml: true, Collapsible: true})
+	assert.Contains(t, s, "<details>\n<summary>Manifest of <code>ns/ConfigMap/new</code> (35 lines)</summary>\n\n```yaml\n")
+
+	s = Render(cr, Options{ShowNewObjectYaml: true, ShowDeletedObjectYaml: true, Short: true})
+	assert.NotContains(t, s, "```yaml")
+}
+
+func TestRenderValidateResult(t *testing.T) {
+	vr := &result.ValidateResult{
+		TargetKey: result.TargetKey{TargetName: "prod"},
+		Warnings:  []result.DeploymentError{{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "cm", Namespace: "ns"}, Message: "warning"}},
+		Errors:    []result.DeploymentError{{Message: "global error"}},
+	}
+	s := RenderValidateResult(vr)
+	assert.Contains(t, s, "### kluctl validate on target `prod`\n")
+	assert.Contains(t, s, "Ready: no, Errors: 1, Warnings: 1\n")
+	assert.Contains(t, s, "#### Warnings\n\n- `ns/ConfigMap/cm`: warning\n")
+	assert.Contains(t, s, "#### Errors\n\n- global error\n")
+}
@@ -0,0 +1,141 @@
+package patchreport
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+const devNull = "/dev/null"
+
+var unsafePathCharsRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// Render renders the given command result as a unified diff document, with one file per object. New objects are
+// rendered as full additions and deleted/orphan objects as full removals. Changed objects are diffed by comparing the
+// remote and the applied versions of the objects, so the result contains the hunks of whole objects instead of the
+// per-field changes found in the command result.
+func Render(cr *result.CommandResult) (string, error) {
+	buf := &strings.Builder{}
+	for _, o := range cr.Objects {
+		var oldObject, newObject *uo.UnstructuredObject
+		switch {
+		case o.New:
+			newObject = getNewObject(o)
+		case o.Deleted || o.Orphan:
+			oldObject = o.Remote
+		case len(o.Changes) != 0:
+			oldObject = o.Remote
+			newObject = getNewObject(o)
+			if oldObject == nil || newObject == nil {
+				continue
+			}
+		default:
+			continue
+		}
+		if oldObject == nil && newObject == nil {
+			continue
+		}
+
+		err := writeObjectPatch(buf, o.Ref, oldObject, newObject)
+		if err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+func getNewObject(o result.ResultObject) *uo.UnstructuredObject {
+	if o.Applied != nil {
+		return o.Applied
+	}
+	return o.Rendered
+}
+
+// BuildPath returns a path for the given object ref that is safe to be used in patch file headers, e.g.
+// apps_v1/Deployment/ns/name.yaml
+func BuildPath(ref k8s.ObjectRef) string {
+	gv := ref.Version
+	if ref.Group != "" {
+		gv = ref.Group + "_" + ref.Version
+	}
+	elems := []string{gv, ref.Kind}
+	if ref.Namespace != "" {
+		elems = append(elems, ref.Namespace)
+	}
+	elems = append(elems, ref.Name+".yaml")
+	for i, e := range elems {
+		elems[i] = unsafePathCharsRegex.ReplaceAllString(e, "_")
+	}
+	return strings.Join(elems, "/")
+}
+
+func objectToString(o *uo.UnstructuredObject) (string, error) {
+	if o == nil {
+		return "", nil
+	}
+	return yaml.WriteYamlString(diff.StripServerFields(o))
+}
+
+func writeObjectPatch(buf *strings.Builder, ref k8s.ObjectRef, oldObject *uo.UnstructuredObject, newObject *uo.UnstructuredObject) error {
+	oldStr, err := objectToString(oldObject)
+	if err != nil {
+		return err
+	}
+	newStr, err := objectToString(newObject)
+	if err != nil {
+		return err
+	}
+	if oldStr == newStr {
+		return nil
+	}
+
+	path := BuildPath(ref)
+	oldPath, newPath := "a/"+path, "b/"+path
+	if oldObject == nil {
+		oldPath = devNull
+	}
+	if newObject == nil {
+		newPath = devNull
+	}
+	buf.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldPath, newPath))
+
+	if oldObject == nil || newObject == nil {
+		// full additions/removals are written manually, as gotextdiff does not produce valid hunk headers for them
+		writeFullHunk(buf, oldStr, newStr)
+		return nil
+	}
+
+	edits := myers.ComputeEdits(span.URIFromPath(path), oldStr, newStr)
+	u := fmt.Sprint(gotextdiff.ToUnified(oldPath, newPath, oldStr, edits))
+	// skip the header, as it was already written
+	lines := strings.SplitN(u, "\n", 3)
+	buf.WriteString(lines[2])
+	return nil
+}
+
+func writeFullHunk(buf *strings.Builder, oldStr string, newStr string) {
+	prefix := "+"
+	s := newStr
+	if newStr == "" {
+		prefix = "-"
+		s = oldStr
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if prefix == "+" {
+		buf.WriteString(fmt.Sprintf("@@ -0,0 +1,%d @@\n", len(lines)))
+	} else {
+		buf.WriteString(fmt.Sprintf("@@ -1,%d +0,0 @@\n", len(lines)))
+	}
+	for _, l := range lines {
+		buf.WriteString(prefix + l + "\n")
+	}
+}
@@ -0,0 +1,102 @@
+package patchreport
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildConfigMap(name string, data map[string]any) *uo.UnstructuredObject {
+	o := uo.New()
+	o.SetK8sGVKs("", "v1", "ConfigMap")
+	o.SetK8sName(name)
+	o.SetK8sNamespace("ns")
+	_ = o.SetNestedField(data, "data")
+	return o
+}
+
+func buildTestResult() *result.CommandResult {
+	ref := func(name string) k8s.ObjectRef {
+		return k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: name}
+	}
+
+	remoteChanged := buildConfigMap("changed", map[string]any{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "6", "g": "7", "h": "8", "i": "9"})
+	remoteChanged.SetK8sResourceVersion("1234")
+	appliedChanged := buildConfigMap("changed", map[string]any{"a": "1", "b": "2", "c": "3", "d": "4", "e": "x", "f": "6", "g": "7", "h": "8", "i": "9"})
+
+	return &result.CommandResult{
+		Objects: []result.ResultObject{
+			{
+				BaseObject: result.BaseObject{Ref: ref("new"), New: true},
+				Applied:    buildConfigMap("new", map[string]any{"a": "1"}),
+			},
+			{
+				BaseObject: result.BaseObject{Ref: ref("changed"), Changes: []result.Change{{Type: "update", JsonPath: "data.e"}}},
+				Remote:     remoteChanged,
+				Applied:    appliedChanged,
+			},
+			{
+				BaseObject: result.BaseObject{Ref: ref("orphan"), Orphan: true},
+				Remote:     buildConfigMap("orphan", map[string]any{"a": "1"}),
+			},
+			{
+				BaseObject: result.BaseObject{Ref: ref("unchanged")},
+				Remote:     buildConfigMap("unchanged", map[string]any{"a": "1"}),
+				Applied:    buildConfigMap("unchanged", map[string]any{"a": "1"}),
+			},
+		},
+	}
+}
+
+func TestBuildPath(t *testing.T) {
+	assert.Equal(t, "apps_v1/Deployment/ns/name.yaml", BuildPath(k8s.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "ns", Name: "name"}))
+	assert.Equal(t, "v1/Namespace/ns.yaml", BuildPath(k8s.ObjectRef{Version: "v1", Kind: "Namespace", Name: "ns"}))
+	assert.Equal(t, "rbac.authorization.k8s.io_v1/ClusterRole/system_aggregate.yaml", BuildPath(k8s.ObjectRef{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole", Name: "system:aggregate"}))
+}
+
+func TestRender(t *testing.T) {
+	s, err := Render(buildTestResult())
+	assert.NoError(t, err)
+
+	assert.Contains(t, s, "--- /dev/null\n+++ b/v1/ConfigMap/ns/new.yaml\n@@ -0,0 +1,7 @@\n+apiVersion: v1\n")
+	assert.Contains(t, s, "--- a/v1/ConfigMap/ns/changed.yaml\n+++ b/v1/ConfigMap/ns/changed.yaml\n@@ -4,7 +4,7 @@\n")
+	assert.Contains(t, s, "-  e: \"5\"\n+  e: x\n")
+	assert.Contains(t, s, "--- a/v1/ConfigMap/ns/orphan.yaml\n+++ /dev/null\n@@ -1,7 +0,0 @@\n-apiVersion: v1\n")
+	assert.NotContains(t, s, "unchanged")
+	// server populated fields must not show up
+	assert.NotContains(t, s, "resourceVersion")
+}
+
+func TestRenderGitApply(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	cr := buildTestResult()
+	s, err := Render(cr)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	for _, o := range cr.Objects {
+		if o.New || o.Remote == nil {
+			continue
+		}
+		str, err := objectToString(o.Remote)
+		assert.NoError(t, err)
+		p := filepath.Join(dir, BuildPath(o.Ref))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o700))
+		assert.NoError(t, os.WriteFile(p, []byte(str), 0o600))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "changes.patch"), []byte(s), 0o600))
+
+	cmd := exec.Command("git", "apply", "--check", "changes.patch")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
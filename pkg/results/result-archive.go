@@ -0,0 +1,178 @@
+package results
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/version"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ResultArchiveVersion is the version of the archive format written by WriteResultArchive. It must be incremented
+	// when the layout of the archive changes in an incompatible way, so that old archives can still be read.
+	ResultArchiveVersion = 1
+
+	resultArchiveMetadataFile = "metadata.yaml"
+	resultArchiveResultFile   = "result.json"
+
+	// maxResultArchiveFileSize limits the size of single files read from result archives
+	maxResultArchiveFileSize = 256 << (10 * 2)
+)
+
+// ResultArchiveMetadata describes the content of a result archive
+type ResultArchiveMetadata struct {
+	Version       int                 `json:"version"`
+	KluctlVersion string              `json:"kluctlVersion,omitempty"`
+	ExportTime    metav1.Time         `json:"exportTime"`
+	ResultId      string              `json:"resultId"`
+	Command       string              `json:"command,omitempty"`
+	ProjectKey    gittypes.ProjectKey `json:"projectKey"`
+	TargetKey     result.TargetKey    `json:"targetKey"`
+}
+
+// WriteResultArchive bundles the compacted command result together with metadata into a gzip compressed tar archive.
+// The result is obfuscated before it is written, unless it is signed, as signed results are already obfuscated.
+func WriteResultArchive(cr *result.CommandResult) ([]byte, error) {
+	cr = cr.DeepCopy()
+	if cr.Signature == nil {
+		var obfuscator diff.Obfuscator
+		err := obfuscator.ObfuscateResult(cr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	md := ResultArchiveMetadata{
+		Version:       ResultArchiveVersion,
+		KluctlVersion: version.GetVersion(),
+		ExportTime:    metav1.Now(),
+		ResultId:      cr.Id,
+		Command:       cr.Command.Command,
+		ProjectKey:    cr.ProjectKey,
+		TargetKey:     cr.TargetKey,
+	}
+	mdYaml, err := yaml.WriteYamlString(&md)
+	if err != nil {
+		return nil, err
+	}
+	crJson, err := json.Marshal(cr.ToCompacted())
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct {
+		name    string
+		content []byte
+	}{
+		{resultArchiveMetadataFile, []byte(mdYaml)},
+		{resultArchiveResultFile, crJson},
+	} {
+		err = tw.WriteHeader(&tar.Header{
+			Name:    f.name,
+			Mode:    0o644,
+			Size:    int64(len(f.content)),
+			ModTime: md.ExportTime.Time.Truncate(time.Second),
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = tw.Write(f.content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tw.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = gz.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadResultArchive reads an archive written by WriteResultArchive. Archives written by older versions of kluctl are
+// supported, while archives with a newer version than ResultArchiveVersion are rejected.
+func ReadResultArchive(r io.Reader) (*ResultArchiveMetadata, *result.CommandResult, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid result archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid result archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > maxResultArchiveFileSize {
+			return nil, nil, fmt.Errorf("invalid result archive: %s is too large", hdr.Name)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid result archive: %w", err)
+		}
+		files[hdr.Name] = b
+	}
+
+	mdYaml, ok := files[resultArchiveMetadataFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid result archive: %s is missing", resultArchiveMetadataFile)
+	}
+	var md ResultArchiveMetadata
+	err = yaml.ReadYamlBytes(mdYaml, &md)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid result archive metadata: %w", err)
+	}
+
+	switch {
+	case md.Version < 1:
+		return nil, nil, fmt.Errorf("invalid result archive version %d", md.Version)
+	case md.Version > ResultArchiveVersion:
+		return nil, nil, fmt.Errorf("result archive version %d is not supported by this version of kluctl, the newest supported version is %d", md.Version, ResultArchiveVersion)
+	}
+
+	crJson, ok := files[resultArchiveResultFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid result archive: %s is missing", resultArchiveResultFile)
+	}
+	var ccr result.CompactedCommandResult
+	err = json.Unmarshal(crJson, &ccr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid command result in result archive: %w", err)
+	}
+	return &md, ccr.ToNonCompacted(), nil
+}
+
+// ReadResultArchiveFile reads the result archive from the given file. See ReadResultArchive for details.
+func ReadResultArchiveFile(p string) (*ResultArchiveMetadata, *result.CommandResult, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return ReadResultArchive(f)
+}
@@ -0,0 +1,68 @@
+package results
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultArchive(t *testing.T) {
+	cr := newSpoolTestResult("id-1")
+	cr.Command.Command = "deploy"
+
+	b, err := WriteResultArchive(cr)
+	assert.NoError(t, err)
+
+	md, cr2, err := ReadResultArchive(bytes.NewReader(b))
+	assert.NoError(t, err)
+	assert.Equal(t, ResultArchiveVersion, md.Version)
+	assert.Equal(t, "id-1", md.ResultId)
+	assert.Equal(t, "deploy", md.Command)
+	assert.Equal(t, "id-1", cr2.Id)
+	assert.Len(t, cr2.Objects, 1)
+
+	// exported results are always obfuscated
+	v, _, _ := cr2.Objects[0].Rendered.GetNestedString("data", "password")
+	assert.NotEqual(t, "c2VjcmV0", v)
+	// the original result is not modified
+	v, _, _ = cr.Objects[0].Rendered.GetNestedString("data", "password")
+	assert.Equal(t, "c2VjcmV0", v)
+}
+
+func buildTestArchive(t *testing.T, files map[string]string) []byte {
+	buf := bytes.NewBuffer(nil)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestResultArchiveInvalid(t *testing.T) {
+	_, _, err := ReadResultArchive(bytes.NewReader([]byte("not an archive")))
+	assert.ErrorContains(t, err, "invalid result archive")
+
+	_, _, err = ReadResultArchive(bytes.NewReader(buildTestArchive(t, map[string]string{
+		"result.json": "{}",
+	})))
+	assert.ErrorContains(t, err, "metadata.yaml is missing")
+
+	_, _, err = ReadResultArchive(bytes.NewReader(buildTestArchive(t, map[string]string{
+		"metadata.yaml": "version: 2\n",
+		"result.json":   "{}",
+	})))
+	assert.ErrorContains(t, err, "result archive version 2 is not supported")
+
+	_, _, err = ReadResultArchive(bytes.NewReader(buildTestArchive(t, map[string]string{
+		"metadata.yaml": "version: 1\n",
+	})))
+	assert.ErrorContains(t, err, "result.json is missing")
+}
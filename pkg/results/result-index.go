@@ -0,0 +1,247 @@
+package results
+
+import (
+	"compress/gzip"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// ResultIndexVersion is the version of the index format. It must be increased whenever the format changes. Indexes
+// with an older version are rebuilt from the stored results, indexes with a newer version are only read via a
+// temporary in-memory rebuild and never overwritten, so that older kluctl versions don't clobber them.
+const ResultIndexVersion = 1
+
+const resultIndexName = "kluctl-results-index"
+
+// DefaultResultsNamespace is the namespace that command results are written to by default. Read-only stores without an
+// explicit namespace read the index from this namespace.
+const DefaultResultsNamespace = "kluctl-results"
+
+// ResultIndex contains the index entries of all command results stored in a single namespace
+type ResultIndex struct {
+	Version int                              `json:"version"`
+	Entries []result.CommandResultIndexEntry `json:"entries"`
+}
+
+func newResultIndex() *ResultIndex {
+	return &ResultIndex{
+		Version: ResultIndexVersion,
+	}
+}
+
+// Put adds or replaces the entry with the same id
+func (idx *ResultIndex) Put(e result.CommandResultIndexEntry) {
+	for i := range idx.Entries {
+		if idx.Entries[i].Id == e.Id {
+			idx.Entries[i] = e
+			return
+		}
+	}
+	idx.Entries = append(idx.Entries, e)
+}
+
+// Remove removes all entries with the given ids
+func (idx *ResultIndex) Remove(ids ...string) {
+	m := map[string]bool{}
+	for _, id := range ids {
+		m[id] = true
+	}
+	ret := make([]result.CommandResultIndexEntry, 0, len(idx.Entries))
+	for _, e := range idx.Entries {
+		if !m[e.Id] {
+			ret = append(ret, e)
+		}
+	}
+	idx.Entries = ret
+}
+
+// List returns all entries matching the filter, sorted by start time with the newest entries first
+func (idx *ResultIndex) List(options ListResultSummariesOptions) []result.CommandResultIndexEntry {
+	ret := make([]result.CommandResultIndexEntry, 0, len(idx.Entries))
+	for _, e := range idx.Entries {
+		if FilterProject(e.ProjectKey, options.ProjectFilter) {
+			ret = append(ret, e)
+		}
+	}
+	sortIndexEntries(ret)
+	return ret
+}
+
+func sortIndexEntries(l []result.CommandResultIndexEntry) {
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].StartTime != l[j].StartTime {
+			return l[i].StartTime.After(l[j].StartTime.Time)
+		}
+		return l[i].Id < l[j].Id
+	})
+}
+
+func encodeResultIndex(idx *ResultIndex) ([]byte, error) {
+	j, err := yaml.WriteJsonString(idx)
+	if err != nil {
+		return nil, err
+	}
+	return utils.CompressGzip([]byte(j), gzip.BestCompression)
+}
+
+func decodeResultIndex(b []byte) (*ResultIndex, error) {
+	j, err := utils.UncompressGzip(b)
+	if err != nil {
+		return nil, err
+	}
+	var idx ResultIndex
+	err = yaml.ReadYamlBytes(j, &idx)
+	if err != nil {
+		return nil, err
+	}
+	return &idx, nil
+}
+
+// indexNamespace returns the namespace that contains the index
+func (s *ResultStoreSecrets) indexNamespace() string {
+	if s.writeNamespace == "" {
+		return DefaultResultsNamespace
+	}
+	return s.writeNamespace
+}
+
+// getIndexSecret returns the index secret of the results namespace or nil if it does not exist
+func (s *ResultStoreSecrets) getIndexSecret() (*corev1.Secret, *ResultIndex, error) {
+	var secret corev1.Secret
+	err := s.client.Get(s.ctx, client.ObjectKey{Namespace: s.indexNamespace(), Name: resultIndexName}, &secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	idx, err := decodeResultIndex(secret.Data["index"])
+	if err != nil {
+		// treat broken indexes like outdated ones, so that they get rebuilt
+		status.Warningf(s.ctx, "Failed to decode result index %s/%s, rebuilding it: %s", s.indexNamespace(), resultIndexName, err)
+		idx = &ResultIndex{}
+	}
+	return &secret, idx, nil
+}
+
+// buildIndex builds the index from the summaries of all command results stored in the results namespace. Only the
+// metadata of the result secrets is listed.
+func (s *ResultStoreSecrets) buildIndex() (*ResultIndex, error) {
+	var l metav1.PartialObjectMetadataList
+	l.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "SecretList"})
+	err := s.client.List(s.ctx, &l, client.InNamespace(s.indexNamespace()), client.HasLabels{"kluctl.io/command-result-id"})
+	if err != nil {
+		return nil, err
+	}
+
+	idx := newResultIndex()
+	for _, x := range l.Items {
+		summary, err := s.parseCommandSummary(x.GetAnnotations())
+		if err != nil || summary == nil {
+			continue
+		}
+		idx.Put(*summary.BuildIndexEntry())
+	}
+	return idx, nil
+}
+
+// writeIndex creates or updates the index secret. secret must be nil if it does not exist yet, in which case it is
+// created. Concurrent modifications result in conflict or already-exists errors.
+func (s *ResultStoreSecrets) writeIndex(secret *corev1.Secret, idx *ResultIndex) error {
+	b, err := encodeResultIndex(idx)
+	if err != nil {
+		return err
+	}
+	if secret == nil {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      resultIndexName,
+				Namespace: s.indexNamespace(),
+				Labels: map[string]string{
+					"kluctl.io/results-index": "true",
+				},
+			},
+			Data: map[string][]byte{
+				"index": b,
+			},
+		}
+		return s.client.Create(s.ctx, secret)
+	}
+	secret.Data = map[string][]byte{
+		"index": b,
+	}
+	return s.client.Update(s.ctx, secret)
+}
+
+// updateIndex applies f to the index of the results namespace. Missing and outdated indexes are backfilled first, while
+// indexes with a newer version are left untouched.
+func (s *ResultStoreSecrets) updateIndex(f func(idx *ResultIndex)) error {
+	isRetryable := func(err error) bool {
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, isRetryable, func() error {
+		secret, idx, err := s.getIndexSecret()
+		if err != nil {
+			return err
+		}
+		if idx != nil && idx.Version > ResultIndexVersion {
+			return nil
+		}
+		if idx == nil || idx.Version < ResultIndexVersion {
+			idx, err = s.buildIndex()
+			if err != nil {
+				return err
+			}
+		}
+		f(idx)
+		return s.writeIndex(secret, idx)
+	})
+}
+
+// tryUpdateIndex is like updateIndex, but only prints a warning on failure. The index is deleted in that case, so that
+// it gets backfilled on the next read.
+func (s *ResultStoreSecrets) tryUpdateIndex(f func(idx *ResultIndex)) {
+	err := s.updateIndex(f)
+	if err == nil {
+		return
+	}
+	status.Warningf(s.ctx, "Failed to update result index: %s", err)
+	err = s.client.Delete(s.ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: s.indexNamespace(), Name: resultIndexName}})
+	if err != nil && !errors.IsNotFound(err) {
+		status.Warningf(s.ctx, "Failed to delete outdated result index: %s", err)
+	}
+}
+
+// ListCommandResultIndex lists the index entries of all command results stored in the results namespace. Only the index
+// is read, which is backfilled on demand if it does not exist yet or has an outdated format.
+func (s *ResultStoreSecrets) ListCommandResultIndex(options ListResultSummariesOptions) ([]result.CommandResultIndexEntry, error) {
+	secret, idx, err := s.getIndexSecret()
+	if err != nil {
+		return nil, err
+	}
+	if idx != nil && idx.Version == ResultIndexVersion {
+		return idx.List(options), nil
+	}
+	isNewer := idx != nil && idx.Version > ResultIndexVersion
+
+	idx, err = s.buildIndex()
+	if err != nil {
+		return nil, err
+	}
+	if s.allowWrite && !isNewer {
+		err = s.writeIndex(secret, idx)
+		if err != nil && !errors.IsConflict(err) && !errors.IsAlreadyExists(err) {
+			status.Warningf(s.ctx, "Failed to backfill result index: %s", err)
+		}
+	}
+	return idx.List(options), nil
+}
@@ -0,0 +1,144 @@
+package results
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newIndexTestStore(t *testing.T, objs ...client.Object) *ResultStoreSecrets {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return &ResultStoreSecrets{
+		ctx:            context.Background(),
+		client:         c,
+		allowWrite:     true,
+		writeNamespace: DefaultResultsNamespace,
+	}
+}
+
+func newIndexTestSummary(id string, minute int) *result.CommandResultSummary {
+	return &result.CommandResultSummary{
+		Id:          id,
+		ProjectKey:  gittypes.ProjectKey{SubDir: "p1"},
+		TargetKey:   result.TargetKey{TargetName: "t1", ClusterId: "cluster-1"},
+		ClusterInfo: result.ClusterInfo{ClusterId: "cluster-1"},
+		Command: result.CommandInfo{
+			Initiator: result.CommandInititiator_CommandLine,
+			Command:   "deploy",
+			StartTime: metav1.NewTime(time.Date(2024, 1, 1, 0, minute, 0, 0, time.UTC)),
+		},
+		NewObjects: 1,
+		Errors:     []result.DeploymentError{{Message: "e"}},
+	}
+}
+
+func newIndexTestResultSecret(t *testing.T, summary *result.CommandResultSummary) *corev1.Secret {
+	j, err := yaml.WriteJsonString(summary)
+	assert.NoError(t, err)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cr-" + summary.Id,
+			Namespace: DefaultResultsNamespace,
+			Labels: map[string]string{
+				"kluctl.io/result":            "true",
+				"kluctl.io/command-result-id": summary.Id,
+			},
+			Annotations: map[string]string{
+				"kluctl.io/command-result-summary": j,
+			},
+		},
+	}
+}
+
+func getIndexIds(t *testing.T, s *ResultStoreSecrets) []string {
+	secret, idx, err := s.getIndexSecret()
+	assert.NoError(t, err)
+	assert.NotNil(t, secret)
+	var ids []string
+	for _, e := range idx.List(ListResultSummariesOptions{}) {
+		ids = append(ids, e.Id)
+	}
+	return ids
+}
+
+func TestResultIndexBackfill(t *testing.T) {
+	s := newIndexTestStore(t,
+		newIndexTestResultSecret(t, newIndexTestSummary("id1", 1)),
+		newIndexTestResultSecret(t, newIndexTestSummary("id2", 2)),
+	)
+
+	entries, err := s.ListCommandResultIndex(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	// newest first
+	assert.Equal(t, "id2", entries[0].Id)
+	assert.Equal(t, "cluster-1", entries[0].ClusterId)
+	assert.Equal(t, "deploy", entries[0].Command)
+	assert.Equal(t, result.CommandResultCounts{NewObjects: 1, Errors: 1}, entries[0].Counts)
+
+	// the backfilled index got written
+	assert.Equal(t, []string{"id2", "id1"}, getIndexIds(t, s))
+
+	entries, err = s.ListCommandResultIndex(ListResultSummariesOptions{ProjectFilter: &gittypes.ProjectKey{SubDir: "other"}})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestResultIndexUpdate(t *testing.T) {
+	s := newIndexTestStore(t, newIndexTestResultSecret(t, newIndexTestSummary("id1", 1)))
+
+	// the first update backfills the missing index
+	s.tryUpdateIndex(func(idx *ResultIndex) {
+		idx.Put(*newIndexTestSummary("id2", 2).BuildIndexEntry())
+	})
+	assert.Equal(t, []string{"id2", "id1"}, getIndexIds(t, s))
+
+	s.tryUpdateIndex(func(idx *ResultIndex) {
+		idx.Put(*newIndexTestSummary("id3", 3).BuildIndexEntry())
+		idx.Remove("id1")
+	})
+	assert.Equal(t, []string{"id3", "id2"}, getIndexIds(t, s))
+}
+
+func TestResultIndexVersions(t *testing.T) {
+	writeIndex := func(s *ResultStoreSecrets, idx *ResultIndex) {
+		assert.NoError(t, s.writeIndex(nil, idx))
+	}
+
+	// outdated indexes are rebuilt
+	s := newIndexTestStore(t, newIndexTestResultSecret(t, newIndexTestSummary("id1", 1)))
+	writeIndex(s, &ResultIndex{Version: ResultIndexVersion - 1, Entries: []result.CommandResultIndexEntry{{Id: "stale"}}})
+	entries, err := s.ListCommandResultIndex(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "id1", entries[0].Id)
+	_, idx, err := s.getIndexSecret()
+	assert.NoError(t, err)
+	assert.Equal(t, ResultIndexVersion, idx.Version)
+
+	// newer indexes are read via an in-memory rebuild and never overwritten
+	s = newIndexTestStore(t, newIndexTestResultSecret(t, newIndexTestSummary("id1", 1)))
+	writeIndex(s, &ResultIndex{Version: ResultIndexVersion + 1})
+	entries, err = s.ListCommandResultIndex(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	s.tryUpdateIndex(func(idx *ResultIndex) {
+		idx.Put(*newIndexTestSummary("id2", 2).BuildIndexEntry())
+	})
+	_, idx, err = s.getIndexSecret()
+	assert.NoError(t, err)
+	assert.Equal(t, ResultIndexVersion+1, idx.Version)
+	assert.Empty(t, idx.Entries)
+}
@@ -0,0 +1,130 @@
+package results
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+// RetentionPolicy decides which command results are removed from a result store
+type RetentionPolicy struct {
+	// KeepPerTarget is the number of most recent results kept per project and target. Zero or less disables the limit.
+	KeepPerTarget int
+	// MaxAge removes all results that were started longer than MaxAge ago. Zero disables the limit.
+	MaxAge time.Duration
+}
+
+func (p RetentionPolicy) IsEmpty() bool {
+	return p.KeepPerTarget <= 0 && p.MaxAge <= 0
+}
+
+type retentionKey struct {
+	project gittypes.ProjectKey
+	target  result.TargetKey
+}
+
+// SelectExpiredCommandResults returns all summaries that must be removed according to the policy. Summaries are grouped
+// by project and target and must be sorted with the most recent result first, as returned by
+// ListCommandResultSummaries.
+func SelectExpiredCommandResults(summaries []result.CommandResultSummary, policy RetentionPolicy, now time.Time) []result.CommandResultSummary {
+	counts := map[retentionKey]int{}
+	var ret []result.CommandResultSummary
+	for _, rs := range summaries {
+		k := retentionKey{project: rs.ProjectKey, target: rs.TargetKey}
+		counts[k]++
+
+		expired := false
+		if policy.KeepPerTarget > 0 && counts[k] > policy.KeepPerTarget {
+			expired = true
+		}
+		if policy.MaxAge > 0 && rs.Command.StartTime.Time.Before(now.Add(-policy.MaxAge)) {
+			expired = true
+		}
+		if expired {
+			ret = append(ret, rs)
+		}
+	}
+	return ret
+}
+
+// PruneTargetReport contains the number of removed and kept results of a single target
+type PruneTargetReport struct {
+	ProjectKey gittypes.ProjectKey `json:"projectKey"`
+	TargetKey  result.TargetKey    `json:"targetKey"`
+	Removed    int                 `json:"removed"`
+	Kept       int                 `json:"kept"`
+	// RemovedIds contains the ids of the removed results, sorted from most recent to oldest
+	RemovedIds []string `json:"removedIds,omitempty"`
+}
+
+type PruneReport struct {
+	DryRun  bool                `json:"dryRun"`
+	Targets []PruneTargetReport `json:"targets"`
+}
+
+// PruneCommandResults removes all command results from the store that are expired according to the policy. All parts
+// of the results are removed, including their entries in the result index. If dryRun is true, the report is built
+// without removing anything. Only results matching the list options are considered.
+func PruneCommandResults(store ResultStore, options ListResultSummariesOptions, policy RetentionPolicy, dryRun bool, now time.Time) (*PruneReport, error) {
+	if policy.IsEmpty() {
+		return nil, fmt.Errorf("retention policy must either limit the number of results per target or their age")
+	}
+
+	summaries, err := store.ListCommandResultSummaries(options)
+	if err != nil {
+		return nil, err
+	}
+	expired := SelectExpiredCommandResults(summaries, policy, now)
+
+	targets := map[retentionKey]*PruneTargetReport{}
+	getTarget := func(rs *result.CommandResultSummary) *PruneTargetReport {
+		k := retentionKey{project: rs.ProjectKey, target: rs.TargetKey}
+		t, ok := targets[k]
+		if !ok {
+			t = &PruneTargetReport{ProjectKey: rs.ProjectKey, TargetKey: rs.TargetKey}
+			targets[k] = t
+		}
+		return t
+	}
+	for _, rs := range summaries {
+		getTarget(&rs).Kept++
+	}
+
+	for _, rs := range expired {
+		if !dryRun {
+			err = store.DeleteCommandResult(rs.Id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete command result %s: %w", rs.Id, err)
+			}
+		}
+		t := getTarget(&rs)
+		t.Kept--
+		t.Removed++
+		t.RemovedIds = append(t.RemovedIds, rs.Id)
+	}
+
+	report := &PruneReport{DryRun: dryRun}
+	for _, t := range targets {
+		report.Targets = append(report.Targets, *t)
+	}
+	sort.Slice(report.Targets, func(i, j int) bool {
+		a, b := &report.Targets[i], &report.Targets[j]
+		if a.ProjectKey.RepoKey.String() != b.ProjectKey.RepoKey.String() {
+			return a.ProjectKey.RepoKey.String() < b.ProjectKey.RepoKey.String()
+		}
+		if a.ProjectKey.SubDir != b.ProjectKey.SubDir {
+			return a.ProjectKey.SubDir < b.ProjectKey.SubDir
+		}
+		if a.TargetKey.TargetName != b.TargetKey.TargetName {
+			return a.TargetKey.TargetName < b.TargetKey.TargetName
+		}
+		if a.TargetKey.Discriminator != b.TargetKey.Discriminator {
+			return a.TargetKey.Discriminator < b.TargetKey.Discriminator
+		}
+		return a.TargetKey.ClusterId < b.TargetKey.ClusterId
+	})
+	return report, nil
+}
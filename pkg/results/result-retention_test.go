@@ -0,0 +1,92 @@
+package results
+
+import (
+	"testing"
+	"time"
+
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type pruneTestStore struct {
+	ResultStore
+	summaries []result.CommandResultSummary
+	deleted   []string
+}
+
+func (s *pruneTestStore) ListCommandResultSummaries(options ListResultSummariesOptions) ([]result.CommandResultSummary, error) {
+	return s.summaries, nil
+}
+
+func (s *pruneTestStore) DeleteCommandResult(rsId string) error {
+	s.deleted = append(s.deleted, rsId)
+	return nil
+}
+
+var pruneTestNow = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+func newPruneTestSummary(id string, target string, age time.Duration) result.CommandResultSummary {
+	return result.CommandResultSummary{
+		Id:         id,
+		ProjectKey: gittypes.ProjectKey{SubDir: "p1"},
+		TargetKey:  result.TargetKey{TargetName: target, ClusterId: "cluster-1"},
+		Command: result.CommandInfo{
+			StartTime: metav1.NewTime(pruneTestNow.Add(-age)),
+		},
+	}
+}
+
+func newPruneTestStore() *pruneTestStore {
+	return &pruneTestStore{
+		summaries: []result.CommandResultSummary{
+			newPruneTestSummary("a1", "a", time.Hour),
+			newPruneTestSummary("b1", "b", 2*time.Hour),
+			newPruneTestSummary("a2", "a", 24*time.Hour),
+			newPruneTestSummary("a3", "a", 48*time.Hour),
+			newPruneTestSummary("b2", "b", 72*time.Hour),
+		},
+	}
+}
+
+func TestSelectExpiredCommandResults(t *testing.T) {
+	ids := func(l []result.CommandResultSummary) []string {
+		var ret []string
+		for _, x := range l {
+			ret = append(ret, x.Id)
+		}
+		return ret
+	}
+	summaries := newPruneTestStore().summaries
+
+	assert.Equal(t, []string{"a2", "a3", "b2"}, ids(SelectExpiredCommandResults(summaries, RetentionPolicy{KeepPerTarget: 1}, pruneTestNow)))
+	assert.Equal(t, []string{"a3", "b2"}, ids(SelectExpiredCommandResults(summaries, RetentionPolicy{MaxAge: 36 * time.Hour}, pruneTestNow)))
+	assert.Equal(t, []string{"a3", "b2"}, ids(SelectExpiredCommandResults(summaries, RetentionPolicy{KeepPerTarget: 2, MaxAge: 60 * time.Hour}, pruneTestNow)))
+	assert.Empty(t, SelectExpiredCommandResults(summaries, RetentionPolicy{}, pruneTestNow))
+}
+
+func TestPruneCommandResults(t *testing.T) {
+	s := newPruneTestStore()
+
+	_, err := PruneCommandResults(s, ListResultSummariesOptions{}, RetentionPolicy{}, false, pruneTestNow)
+	assert.Error(t, err)
+
+	report, err := PruneCommandResults(s, ListResultSummariesOptions{}, RetentionPolicy{KeepPerTarget: 1}, true, pruneTestNow)
+	assert.NoError(t, err)
+	assert.Empty(t, s.deleted)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Targets, 2)
+	assert.Equal(t, "a", report.Targets[0].TargetKey.TargetName)
+	assert.Equal(t, 2, report.Targets[0].Removed)
+	assert.Equal(t, 1, report.Targets[0].Kept)
+	assert.Equal(t, []string{"a2", "a3"}, report.Targets[0].RemovedIds)
+	assert.Equal(t, "b", report.Targets[1].TargetKey.TargetName)
+	assert.Equal(t, 1, report.Targets[1].Removed)
+	assert.Equal(t, 1, report.Targets[1].Kept)
+
+	report, err = PruneCommandResults(s, ListResultSummariesOptions{}, RetentionPolicy{KeepPerTarget: 1}, false, pruneTestNow)
+	assert.NoError(t, err)
+	assert.False(t, report.DryRun)
+	assert.Equal(t, []string{"a2", "a3", "b2"}, s.deleted)
+}
@@ -0,0 +1,208 @@
+package results
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	DefaultSpoolMaxEntries = 50
+	DefaultSpoolMaxAge     = 7 * 24 * time.Hour
+
+	spoolUnknownClusterDir = "unknown-cluster"
+)
+
+// ResultSpool buffers command results that could not be written to a result store, so that writing them can be
+// retried later. Spooled results are always obfuscated. The number and age of spooled results is capped, the oldest
+// results are dropped when the cap is exceeded.
+type ResultSpool struct {
+	dir        string
+	maxEntries int
+	maxAge     time.Duration
+}
+
+// SpoolEntry is a single spooled command result together with the result store that it failed to be written to
+type SpoolEntry struct {
+	Path string `json:"-"`
+
+	WriterName string `json:"writerName"`
+	// Namespace is the namespace of the in-cluster result store. It is empty for other result stores.
+	Namespace string                `json:"namespace,omitempty"`
+	SpoolTime metav1.Time           `json:"spoolTime"`
+	Result    *result.CommandResult `json:"result"`
+}
+
+func DefaultSpoolDir(ctx context.Context) string {
+	return filepath.Join(utils.GetCacheDir(ctx), "result-spool")
+}
+
+func NewResultSpool(dir string) *ResultSpool {
+	return &ResultSpool{
+		dir:        dir,
+		maxEntries: DefaultSpoolMaxEntries,
+		maxAge:     DefaultSpoolMaxAge,
+	}
+}
+
+func (s *ResultSpool) clusterDir(clusterId string) string {
+	if clusterId == "" {
+		clusterId = spoolUnknownClusterDir
+	}
+	return filepath.Join(s.dir, clusterId)
+}
+
+// Add spools the given command result for the given result store and returns the path of the spooled file
+func (s *ResultSpool) Add(writerName string, namespace string, cr *result.CommandResult) (string, error) {
+	cr = cr.DeepCopy()
+	// signed results are already obfuscated, obfuscating them again would invalidate the signature
+	if cr.Signature == nil {
+		var obfuscator diff.Obfuscator
+		err := obfuscator.ObfuscateResult(cr)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	e := SpoolEntry{
+		WriterName: writerName,
+		Namespace:  namespace,
+		SpoolTime:  metav1.Now(),
+		Result:     cr,
+	}
+	b, err := json.Marshal(&e)
+	if err != nil {
+		return "", err
+	}
+	b, err = utils.CompressGzip(b, gzip.BestCompression)
+	if err != nil {
+		return "", err
+	}
+
+	dir := s.clusterDir(cr.ClusterInfo.ClusterId)
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.Sum256([]byte(writerName))
+	p := filepath.Join(dir, fmt.Sprintf("%s-%s.json.gz", cr.Id, hex.EncodeToString(h[:])[:8]))
+	err = os.WriteFile(p, b, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.enforceRetention()
+	if err != nil {
+		return "", err
+	}
+	return p, nil
+}
+
+// List returns all spooled results for the given cluster, oldest first
+func (s *ResultSpool) List(clusterId string) ([]*SpoolEntry, error) {
+	dir := s.clusterDir(clusterId)
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ret []*SpoolEntry
+	for _, de := range des {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), ".json.gz") {
+			continue
+		}
+		e, err := readSpoolEntry(filepath.Join(dir, de.Name()))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, e)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].SpoolTime.Before(&ret[j].SpoolTime)
+	})
+	return ret, nil
+}
+
+// Remove removes the given entry from the spool, e.g. after it was successfully written
+func (s *ResultSpool) Remove(e *SpoolEntry) error {
+	err := os.Remove(e.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func readSpoolEntry(p string) (*SpoolEntry, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	b, err = utils.UncompressGzip(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spooled result %s: %w", p, err)
+	}
+	var e SpoolEntry
+	err = json.Unmarshal(b, &e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spooled result %s: %w", p, err)
+	}
+	e.Path = p
+	return &e, nil
+}
+
+// enforceRetention removes spooled files that are older than maxAge and the oldest files exceeding maxEntries. The
+// cap is applied over all clusters, based on file modification times.
+func (s *ResultSpool) enforceRetention() error {
+	type spoolFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []spoolFile
+	err := filepath.WalkDir(s.dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, ".json.gz") {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, spoolFile{path: p, modTime: fi.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	for i, f := range files {
+		if i >= s.maxEntries || time.Since(f.modTime) > s.maxAge {
+			err = os.Remove(f.path)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package results
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSpoolTestResult(id string) *result.CommandResult {
+	secret := uo.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      "secret",
+			"namespace": "default",
+		},
+		"data": map[string]interface{}{
+			"password": "c2VjcmV0",
+		},
+	})
+	return &result.CommandResult{
+		Id:          id,
+		ClusterInfo: result.ClusterInfo{ClusterId: "cluster-1"},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "Secret", Name: "secret", Namespace: "default"}}, Rendered: secret},
+		},
+	}
+}
+
+func TestResultSpool(t *testing.T) {
+	dir := t.TempDir()
+	s := NewResultSpool(dir)
+
+	p, err := s.Add("cluster", "kluctl-results", newSpoolTestResult("id-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "cluster-1"), filepath.Dir(p))
+
+	entries, err := s.List("cluster-1")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "cluster", entries[0].WriterName)
+	assert.Equal(t, "kluctl-results", entries[0].Namespace)
+	assert.Equal(t, "id-1", entries[0].Result.Id)
+
+	// spooled results are always obfuscated
+	v, _, _ := entries[0].Result.Objects[0].Rendered.GetNestedString("data", "password")
+	assert.NotEqual(t, "c2VjcmV0", v)
+
+	otherEntries, err := s.List("other-cluster")
+	assert.NoError(t, err)
+	assert.Len(t, otherEntries, 0)
+
+	err = s.Remove(entries[0])
+	assert.NoError(t, err)
+	entries, err = s.List("cluster-1")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 0)
+}
+
+func TestResultSpoolRetention(t *testing.T) {
+	dir := t.TempDir()
+	s := NewResultSpool(dir)
+	s.maxEntries = 2
+
+	p1, err := s.Add("cluster", "ns", newSpoolTestResult("id-1"))
+	assert.NoError(t, err)
+	// make sure the first entry is the oldest one
+	old := time.Now().Add(-time.Minute)
+	assert.NoError(t, os.Chtimes(p1, old, old))
+
+	_, err = s.Add("cluster", "ns", newSpoolTestResult("id-2"))
+	assert.NoError(t, err)
+	_, err = s.Add("cluster", "ns", newSpoolTestResult("id-3"))
+	assert.NoError(t, err)
+
+	entries, err := s.List("cluster-1")
+	assert.NoError(t, err)
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.Result.Id)
+	}
+	assert.ElementsMatch(t, []string{"id-2", "id-3"}, ids)
+
+	// entries exceeding the max age are removed as well
+	s.maxAge = time.Second
+	tooOld := time.Now().Add(-time.Hour)
+	for _, e := range entries {
+		assert.NoError(t, os.Chtimes(e.Path, tooOld, tooOld))
+	}
+	_, err = s.Add("cluster", "ns", newSpoolTestResult("id-4"))
+	assert.NoError(t, err)
+	entries, err = s.List("cluster-1")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "id-4", entries[0].Result.Id)
+}
@@ -0,0 +1,658 @@
+package results
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/lib/yaml"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	aws2 "github.com/kluctl/kluctl/v2/pkg/clouds/aws"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const s3IndexVersion = 1
+
+// defaultS3PollInterval is the interval used to poll the indexes in watches
+const defaultS3PollInterval = 10 * time.Second
+
+// ResultStoreS3 stores command and validate results in a S3 compatible bucket. Each result is stored as a single
+// gzipped JSON object, keyed by a hash of the project and target and the result id. A small index object per target
+// contains the summaries of all results of that target, so that listing only requires reading the indexes:
+//
+//	<prefix>/targets/<hash>/command-index.json.gz
+//	<prefix>/targets/<hash>/command-results/<id>.json.gz
+//	<prefix>/targets/<hash>/validate-index.json.gz
+//	<prefix>/targets/<hash>/validate-results/<id>.json.gz
+//
+// Object storage does not allow atomic read-modify-write of the indexes, so concurrent writes for the same target from
+// different processes might lose index entries (last writer wins). Missing or broken indexes are rebuilt from the
+// stored results. Watches are implemented by polling the indexes. KluctlDeployments are not stored at all, as these
+// are only available in clusters.
+type ResultStoreS3 struct {
+	ctx    context.Context
+	client aws2.S3Interface
+	bucket string
+	prefix string
+
+	allowWrite               bool
+	keepCommandResultsCount  int
+	keepValidateResultsCount int
+	maxCommandResultAge      time.Duration
+
+	pollInterval time.Duration
+
+	mutex sync.Mutex
+	// resultDirs maps result ids to the target directories that contain them
+	resultDirs map[string]string
+}
+
+type s3ResultIndex[T any] struct {
+	Version   int `json:"version"`
+	Summaries []T `json:"summaries"`
+}
+
+func NewResultStoreS3(ctx context.Context, client aws2.S3Interface, bucket string, prefix string, allowWrite bool, keepCommandResultsCount int, keepValidateResultsCount int) *ResultStoreS3 {
+	return &ResultStoreS3{
+		ctx:                      ctx,
+		client:                   client,
+		bucket:                   bucket,
+		prefix:                   prefix,
+		allowWrite:               allowWrite,
+		keepCommandResultsCount:  keepCommandResultsCount,
+		keepValidateResultsCount: keepValidateResultsCount,
+		pollInterval:             defaultS3PollInterval,
+		resultDirs:               map[string]string{},
+	}
+}
+
+// SetMaxCommandResultAge configures the store to remove command results of the written target that are older than
+// maxAge after each write, in addition to the results exceeding the keep count. Zero disables the age limit.
+func (s *ResultStoreS3) SetMaxCommandResultAge(maxAge time.Duration) {
+	s.maxCommandResultAge = maxAge
+}
+
+func (s *ResultStoreS3) targetsPrefix() string {
+	return path.Join(s.prefix, "targets") + "/"
+}
+
+func (s *ResultStoreS3) buildTargetDir(projectKey gittypes.ProjectKey, targetKey result.TargetKey) (string, error) {
+	j, err := yaml.WriteJsonString(map[string]any{
+		"project": projectKey,
+		"target":  targetKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.targetsPrefix() + utils.Sha256String(j), nil
+}
+
+func (s *ResultStoreS3) putObject(key string, v any) error {
+	j, err := yaml.WriteJsonString(v)
+	if err != nil {
+		return err
+	}
+	compressed, err := utils.CompressGzip([]byte(j), gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(s.ctx, &s3.PutObjectInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(key),
+		Body:            bytes.NewReader(compressed),
+		ContentType:     aws.String("application/json"),
+		ContentEncoding: aws.String("gzip"),
+	})
+	return err
+}
+
+// getObject reads the object into v. Missing objects result in errors for which aws2.IsS3NotFound returns true.
+func (s *ResultStoreS3) getObject(key string, v any) error {
+	o, err := s.client.GetObject(s.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return err
+	}
+	defer o.Body.Close()
+	b, err := io.ReadAll(o.Body)
+	if err != nil {
+		return err
+	}
+	b, err = utils.UncompressGzip(b)
+	if err != nil {
+		return err
+	}
+	return yaml.ReadYamlBytes(b, v)
+}
+
+func (s *ResultStoreS3) deleteObject(key string) error {
+	_, err := s.client.DeleteObject(s.ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// listKeys lists all keys with the given prefix. If delimiter is set, only the common prefixes are returned.
+func (s *ResultStoreS3) listKeys(prefix string, delimiter string) ([]string, error) {
+	var ret []string
+	var token *string
+	for {
+		input := &s3.ListObjectsV2Input{
+			Bucket:            aws.String(s.bucket),
+			Prefix:            aws.String(prefix),
+			ContinuationToken: token,
+		}
+		if delimiter != "" {
+			input.Delimiter = aws.String(delimiter)
+		}
+		o, err := s.client.ListObjectsV2(s.ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if delimiter != "" {
+			for _, cp := range o.CommonPrefixes {
+				ret = append(ret, strings.TrimSuffix(aws.ToString(cp.Prefix), delimiter))
+			}
+		} else {
+			for _, c := range o.Contents {
+				ret = append(ret, aws.ToString(c.Key))
+			}
+		}
+		if !aws.ToBool(o.IsTruncated) || o.NextContinuationToken == nil {
+			return ret, nil
+		}
+		token = o.NextContinuationToken
+	}
+}
+
+func (s *ResultStoreS3) listTargetDirs() ([]string, error) {
+	return s.listKeys(s.targetsPrefix(), "/")
+}
+
+func (s *ResultStoreS3) rememberResultDir(id string, dir string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.resultDirs[id] = dir
+}
+
+// s3ResultKind describes how results of one kind are laid out and read
+type s3ResultKind[T any] struct {
+	indexName     string
+	resultsDir    string
+	getId         func(x *T) string
+	getProjectKey func(x *T) gittypes.ProjectKey
+	less          func(a *T, b *T) bool
+	// readSummary reads the stored result and builds its summary, which is required to rebuild indexes
+	readSummary func(s *ResultStoreS3, key string) (*T, error)
+}
+
+var s3CommandResults = s3ResultKind[result.CommandResultSummary]{
+	indexName:     "command-index.json.gz",
+	resultsDir:    "command-results",
+	getId:         func(x *result.CommandResultSummary) string { return x.Id },
+	getProjectKey: func(x *result.CommandResultSummary) gittypes.ProjectKey { return x.ProjectKey },
+	less:          lessCommandSummary,
+	readSummary: func(s *ResultStoreS3, key string) (*result.CommandResultSummary, error) {
+		var ccr result.CompactedCommandResult
+		err := s.getObject(key, &ccr)
+		if err != nil {
+			return nil, err
+		}
+		return ccr.ToNonCompacted().BuildSummary(), nil
+	},
+}
+
+var s3ValidateResults = s3ResultKind[result.ValidateResultSummary]{
+	indexName:     "validate-index.json.gz",
+	resultsDir:    "validate-results",
+	getId:         func(x *result.ValidateResultSummary) string { return x.Id },
+	getProjectKey: func(x *result.ValidateResultSummary) gittypes.ProjectKey { return x.ProjectKey },
+	less:          lessValidateSummary,
+	readSummary: func(s *ResultStoreS3, key string) (*result.ValidateResultSummary, error) {
+		var vr result.ValidateResult
+		err := s.getObject(key, &vr)
+		if err != nil {
+			return nil, err
+		}
+		summary := vr.BuildSummary()
+		return &summary, nil
+	},
+}
+
+func (k *s3ResultKind[T]) indexKey(dir string) string {
+	return dir + "/" + k.indexName
+}
+
+func (k *s3ResultKind[T]) resultKey(dir string, id string) string {
+	return dir + "/" + k.resultsDir + "/" + id + ".json.gz"
+}
+
+// readIndex reads the index of the target directory. Missing or broken indexes are rebuilt from the stored results.
+func (k *s3ResultKind[T]) readIndex(s *ResultStoreS3, dir string) (*s3ResultIndex[T], error) {
+	var idx s3ResultIndex[T]
+	err := s.getObject(k.indexKey(dir), &idx)
+	if err == nil {
+		return &idx, nil
+	}
+	if !aws2.IsS3NotFound(err) {
+		if s.ctx.Err() != nil {
+			return nil, err
+		}
+		status.Warningf(s.ctx, "Failed to read result index %s, rebuilding it: %s", k.indexKey(dir), err)
+	}
+	return k.rebuildIndex(s, dir)
+}
+
+func (k *s3ResultKind[T]) rebuildIndex(s *ResultStoreS3, dir string) (*s3ResultIndex[T], error) {
+	keys, err := s.listKeys(dir+"/"+k.resultsDir+"/", "")
+	if err != nil {
+		return nil, err
+	}
+	idx := &s3ResultIndex[T]{
+		Version: s3IndexVersion,
+	}
+	for _, key := range keys {
+		summary, err := k.readSummary(s, key)
+		if err != nil {
+			status.Warningf(s.ctx, "Failed to read result %s: %s", key, err)
+			continue
+		}
+		idx.Summaries = append(idx.Summaries, *summary)
+	}
+	k.sortSummaries(idx.Summaries)
+	return idx, nil
+}
+
+func (k *s3ResultKind[T]) sortSummaries(l []T) {
+	sort.Slice(l, func(i, j int) bool {
+		return k.less(&l[i], &l[j])
+	})
+}
+
+// updateIndex applies f to the index of the target directory and writes it back. f returns the ids of the results
+// that must be deleted, which happens after the index got written so that the index never references deleted
+// results.
+func (k *s3ResultKind[T]) updateIndex(s *ResultStoreS3, dir string, f func(idx *s3ResultIndex[T]) []string) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	idx, err := k.readIndex(s, dir)
+	if err != nil {
+		return err
+	}
+	deleted := f(idx)
+	idx.Version = s3IndexVersion
+	k.sortSummaries(idx.Summaries)
+	err = s.putObject(k.indexKey(dir), idx)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range deleted {
+		err = s.deleteObject(k.resultKey(dir, id))
+		if err != nil {
+			status.Warningf(s.ctx, "Failed to delete old result %s: %s", id, err)
+		} else {
+			status.Infof(s.ctx, "Deleted old result %s", id)
+		}
+		delete(s.resultDirs, id)
+	}
+	return nil
+}
+
+// write stores the result and adds its summary to the index. All results exceeding keepCount or older than expiredTime
+// are removed afterwards.
+func (k *s3ResultKind[T]) write(s *ResultStoreS3, dir string, obj any, summary *T, keepCount int, expiredTime func(x *T) bool) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+	id := k.getId(summary)
+	err := s.putObject(k.resultKey(dir, id), obj)
+	if err != nil {
+		return err
+	}
+	err = k.updateIndex(s, dir, func(idx *s3ResultIndex[T]) []string {
+		idx.Summaries = k.removeSummaries(idx.Summaries, id)
+		idx.Summaries = append(idx.Summaries, *summary)
+		k.sortSummaries(idx.Summaries)
+
+		var keep []T
+		var deleted []string
+		for i := range idx.Summaries {
+			x := &idx.Summaries[i]
+			if len(keep) >= keepCount || expiredTime(x) {
+				deleted = append(deleted, k.getId(x))
+				continue
+			}
+			keep = append(keep, *x)
+		}
+		idx.Summaries = keep
+		return deleted
+	})
+	if err != nil {
+		return err
+	}
+	s.rememberResultDir(id, dir)
+	return nil
+}
+
+func (k *s3ResultKind[T]) removeSummaries(l []T, ids ...string) []T {
+	ret := make([]T, 0, len(l))
+	for i := range l {
+		if utils.FindStrInSlice(ids, k.getId(&l[i])) == -1 {
+			ret = append(ret, l[i])
+		}
+	}
+	return ret
+}
+
+type s3SummaryAndDir[T any] struct {
+	dir     string
+	summary T
+}
+
+func (k *s3ResultKind[T]) list(s *ResultStoreS3, options ListResultSummariesOptions) ([]s3SummaryAndDir[T], error) {
+	dirs, err := s.listTargetDirs()
+	if err != nil {
+		return nil, err
+	}
+	var ret []s3SummaryAndDir[T]
+	for _, dir := range dirs {
+		idx, err := k.readIndex(s, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, x := range idx.Summaries {
+			if !FilterProject(k.getProjectKey(&x), options.ProjectFilter) {
+				continue
+			}
+			ret = append(ret, s3SummaryAndDir[T]{dir: dir, summary: x})
+		}
+	}
+
+	s.mutex.Lock()
+	for _, x := range ret {
+		s.resultDirs[k.getId(&x.summary)] = x.dir
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(ret, func(i, j int) bool {
+		return k.less(&ret[i].summary, &ret[j].summary)
+	})
+	return ret, nil
+}
+
+func (k *s3ResultKind[T]) listSummaries(s *ResultStoreS3, options ListResultSummariesOptions) ([]T, error) {
+	l, err := k.list(s, options)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]T, 0, len(l))
+	for _, x := range l {
+		ret = append(ret, x.summary)
+	}
+	return ret, nil
+}
+
+// findResultDir returns the target directory containing the result with the given id or an empty string if it does
+// not exist. All indexes are read if the result is not known yet.
+func (k *s3ResultKind[T]) findResultDir(s *ResultStoreS3, id string) (string, error) {
+	s.mutex.Lock()
+	dir, ok := s.resultDirs[id]
+	s.mutex.Unlock()
+	if ok {
+		return dir, nil
+	}
+
+	l, err := k.list(s, ListResultSummariesOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, x := range l {
+		if k.getId(&x.summary) == id {
+			return x.dir, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *ResultStoreS3) WriteCommandResult(cr *result.CommandResult) error {
+	dir, err := s.buildTargetDir(cr.ProjectKey, cr.TargetKey)
+	if err != nil {
+		return err
+	}
+
+	var expiredTime time.Time
+	if s.maxCommandResultAge > 0 {
+		expiredTime = time.Now().Add(-s.maxCommandResultAge)
+	}
+	return s3CommandResults.write(s, dir, cr.ToCompacted(), cr.BuildSummary(), s.keepCommandResultsCount, func(x *result.CommandResultSummary) bool {
+		return x.Command.StartTime.Time.Before(expiredTime)
+	})
+}
+
+func (s *ResultStoreS3) WriteValidateResult(vr *result.ValidateResult) error {
+	dir, err := s.buildTargetDir(vr.ProjectKey, vr.TargetKey)
+	if err != nil {
+		return err
+	}
+
+	summary := vr.BuildSummary()
+	return s3ValidateResults.write(s, dir, vr, &summary, s.keepValidateResultsCount, func(x *result.ValidateResultSummary) bool {
+		return false
+	})
+}
+
+func (s *ResultStoreS3) DeleteCommandResult(rsId string) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+	if rsId == "" {
+		return fmt.Errorf("empty rsId is not allowed")
+	}
+
+	dir, err := s3CommandResults.findResultDir(s, rsId)
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		return nil
+	}
+	return s3CommandResults.updateIndex(s, dir, func(idx *s3ResultIndex[result.CommandResultSummary]) []string {
+		idx.Summaries = s3CommandResults.removeSummaries(idx.Summaries, rsId)
+		return []string{rsId}
+	})
+}
+
+func (s *ResultStoreS3) ListCommandResultSummaries(options ListResultSummariesOptions) ([]result.CommandResultSummary, error) {
+	return s3CommandResults.listSummaries(s, options)
+}
+
+// ListCommandResultIndex builds the index entries from the per-target indexes, which already only contain summaries.
+func (s *ResultStoreS3) ListCommandResultIndex(options ListResultSummariesOptions) ([]result.CommandResultIndexEntry, error) {
+	summaries, err := s.ListCommandResultSummaries(options)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]result.CommandResultIndexEntry, 0, len(summaries))
+	for _, x := range summaries {
+		ret = append(ret, *x.BuildIndexEntry())
+	}
+	sortIndexEntries(ret)
+	return ret, nil
+}
+
+func (s *ResultStoreS3) WatchCommandResultSummaries(options ListResultSummariesOptions) (<-chan WatchCommandResultSummaryEvent, context.CancelFunc, error) {
+	return pollS3Summaries(s, s3CommandResults.getId, func() ([]result.CommandResultSummary, error) {
+		return s.ListCommandResultSummaries(options)
+	}, func(x *result.CommandResultSummary, isDelete bool) WatchCommandResultSummaryEvent {
+		return WatchCommandResultSummaryEvent{Summary: x, Delete: isDelete}
+	})
+}
+
+func (s *ResultStoreS3) GetCommandResult(options GetCommandResultOptions) (*result.CommandResult, error) {
+	dir, err := s3CommandResults.findResultDir(s, options.Id)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("command result with id %s not found", options.Id)
+	}
+
+	var ccr result.CompactedCommandResult
+	err = s.getObject(s3CommandResults.resultKey(dir, options.Id), &ccr)
+	if err != nil {
+		if aws2.IsS3NotFound(err) {
+			return nil, fmt.Errorf("command result with id %s not found", options.Id)
+		}
+		return nil, err
+	}
+	cr := ccr.ToNonCompacted()
+	if options.Reduced {
+		cr = cr.ToReducedObjects()
+	}
+	return cr, nil
+}
+
+func (s *ResultStoreS3) ListValidateResultSummaries(options ListResultSummariesOptions) ([]result.ValidateResultSummary, error) {
+	return s3ValidateResults.listSummaries(s, options)
+}
+
+func (s *ResultStoreS3) WatchValidateResultSummaries(options ListResultSummariesOptions) (<-chan WatchValidateResultSummaryEvent, context.CancelFunc, error) {
+	return pollS3Summaries(s, s3ValidateResults.getId, func() ([]result.ValidateResultSummary, error) {
+		return s.ListValidateResultSummaries(options)
+	}, func(x *result.ValidateResultSummary, isDelete bool) WatchValidateResultSummaryEvent {
+		return WatchValidateResultSummaryEvent{Summary: x, Delete: isDelete}
+	})
+}
+
+func (s *ResultStoreS3) GetValidateResult(options GetValidateResultOptions) (*result.ValidateResult, error) {
+	dir, err := s3ValidateResults.findResultDir(s, options.Id)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return nil, nil
+	}
+
+	var vr result.ValidateResult
+	err = s.getObject(s3ValidateResults.resultKey(dir, options.Id), &vr)
+	if err != nil {
+		if aws2.IsS3NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &vr, nil
+}
+
+func (s *ResultStoreS3) ListKluctlDeployments() ([]WatchKluctlDeploymentEvent, error) {
+	return nil, nil
+}
+
+func (s *ResultStoreS3) WatchKluctlDeployments() (<-chan WatchKluctlDeploymentEvent, context.CancelFunc, error) {
+	ch := make(chan WatchKluctlDeploymentEvent)
+	ctx, cancel := context.WithCancel(s.ctx)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, cancel, nil
+}
+
+func (s *ResultStoreS3) GetKluctlDeployment(clusterId string, name string, namespace string) (*kluctlv1.KluctlDeployment, error) {
+	return nil, fmt.Errorf("KluctlDeployments are not available in object storage result stores")
+}
+
+// pollS3Summaries emits update events for all initial summaries and then polls for changes. The returned channel is
+// closed when the watch is cancelled.
+func pollS3Summaries[T any, E any](s *ResultStoreS3, getId func(x *T) string, list func() ([]T, error), buildEvent func(x *T, isDelete bool) E) (<-chan E, context.CancelFunc, error) {
+	initial, err := list()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan E)
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	send := func(x *T, isDelete bool) bool {
+		select {
+		case ch <- buildEvent(x, isDelete):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	known := map[string]*T{}
+	emitChanges := func(l []T) bool {
+		newKnown := make(map[string]*T, len(l))
+		for i := range l {
+			x := &l[i]
+			id := getId(x)
+			newKnown[id] = x
+			if old, ok := known[id]; ok && reflect.DeepEqual(old, x) {
+				continue
+			}
+			if !send(x, false) {
+				return false
+			}
+		}
+		for id, x := range known {
+			if _, ok := newKnown[id]; !ok {
+				if !send(x, true) {
+					return false
+				}
+			}
+		}
+		known = newKnown
+		return true
+	}
+
+	go func() {
+		defer close(ch)
+		if !emitChanges(initial) {
+			return
+		}
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			l, err := list()
+			if err != nil {
+				if ctx.Err() == nil {
+					status.Warningf(ctx, "Failed to poll results: %s", err)
+				}
+				continue
+			}
+			if !emitChanges(l) {
+				return
+			}
+		}
+	}()
+
+	return ch, cancel, nil
+}
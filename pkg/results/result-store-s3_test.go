@@ -0,0 +1,181 @@
+package results
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestS3CommandResult(id string, target string, startTime time.Time) *result.CommandResult {
+	return &result.CommandResult{
+		Id: id,
+		Command: result.CommandInfo{
+			Initiator: result.CommandInititiator_CommandLine,
+			Command:   "deploy",
+			StartTime: metav1.NewTime(startTime),
+			EndTime:   metav1.NewTime(startTime.Add(time.Second)),
+		},
+		TargetKey: result.TargetKey{
+			TargetName: target,
+			ClusterId:  "cluster",
+		},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "cm-" + id}}},
+		},
+	}
+}
+
+func getS3SummaryIds(t *testing.T, s *ResultStoreS3) []string {
+	summaries, err := s.ListCommandResultSummaries(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	var ret []string
+	for _, x := range summaries {
+		ret = append(ret, x.Id)
+	}
+	return ret
+}
+
+func TestResultStoreS3(t *testing.T) {
+	fakeAws := aws.NewFakeClientFactory()
+	s := NewResultStoreS3(context.Background(), fakeAws, "my-bucket", "results", true, 2, 2)
+
+	now := time.Now()
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-1", "t1", now.Add(-3*time.Hour))))
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-2", "t1", now.Add(-2*time.Hour))))
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-3", "t2", now.Add(-1*time.Hour))))
+	assert.Equal(t, []string{"id-3", "id-2", "id-1"}, getS3SummaryIds(t, s))
+
+	// a fresh store must be able to find results without a cached id mapping
+	s2 := NewResultStoreS3(context.Background(), fakeAws, "my-bucket", "results", false, 0, 0)
+	cr, err := s2.GetCommandResult(GetCommandResultOptions{Id: "id-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "id-2", cr.Id)
+	assert.Len(t, cr.Objects, 1)
+	assert.Equal(t, "cm-id-2", cr.Objects[0].Ref.Name)
+
+	_, err = s2.GetCommandResult(GetCommandResultOptions{Id: "missing"})
+	assert.ErrorContains(t, err, "command result with id missing not found")
+
+	entries, err := s2.ListCommandResultIndex(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "id-3", entries[0].Id)
+
+	assert.ErrorContains(t, s2.WriteCommandResult(newTestS3CommandResult("id-4", "t1", now)), "read-only")
+
+	// exceeds the keep count of t1
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-4", "t1", now)))
+	assert.Equal(t, []string{"id-4", "id-3", "id-2"}, getS3SummaryIds(t, s))
+	_, err = s2.GetCommandResult(GetCommandResultOptions{Id: "id-1"})
+	assert.Error(t, err)
+
+	assert.NoError(t, s.DeleteCommandResult("id-3"))
+	assert.Equal(t, []string{"id-4", "id-2"}, getS3SummaryIds(t, s))
+	for k := range fakeAws.S3Objects {
+		assert.NotContains(t, k, "id-3")
+	}
+}
+
+func TestResultStoreS3MaxAge(t *testing.T) {
+	fakeAws := aws.NewFakeClientFactory()
+	s := NewResultStoreS3(context.Background(), fakeAws, "my-bucket", "", true, 5, 5)
+	s.SetMaxCommandResultAge(90 * time.Minute)
+
+	now := time.Now()
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-1", "t1", now.Add(-2*time.Hour))))
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-2", "t1", now)))
+	assert.Equal(t, []string{"id-2"}, getS3SummaryIds(t, s))
+}
+
+func TestResultStoreS3RebuildIndex(t *testing.T) {
+	fakeAws := aws.NewFakeClientFactory()
+	s := NewResultStoreS3(context.Background(), fakeAws, "my-bucket", "results", true, 5, 5)
+
+	now := time.Now()
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-1", "t1", now.Add(-time.Hour))))
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-2", "t1", now)))
+
+	for k := range fakeAws.S3Objects {
+		if strings.HasSuffix(k, "/command-index.json.gz") {
+			fakeAws.S3Objects[k] = []byte("broken")
+		}
+	}
+	assert.Equal(t, []string{"id-2", "id-1"}, getS3SummaryIds(t, s))
+}
+
+func TestResultStoreS3ValidateResults(t *testing.T) {
+	fakeAws := aws.NewFakeClientFactory()
+	s := NewResultStoreS3(context.Background(), fakeAws, "my-bucket", "results", true, 5, 1)
+
+	now := time.Now()
+	for i, id := range []string{"vr-1", "vr-2"} {
+		err := s.WriteValidateResult(&result.ValidateResult{
+			Id:        id,
+			TargetKey: result.TargetKey{TargetName: "t1"},
+			StartTime: metav1.NewTime(now.Add(time.Duration(i) * time.Minute)),
+		})
+		assert.NoError(t, err)
+	}
+
+	summaries, err := s.ListValidateResultSummaries(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, "vr-2", summaries[0].Id)
+
+	vr, err := s.GetValidateResult(GetValidateResultOptions{Id: "vr-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "vr-2", vr.Id)
+	vr, err = s.GetValidateResult(GetValidateResultOptions{Id: "vr-1"})
+	assert.NoError(t, err)
+	assert.Nil(t, vr)
+
+	// command results of the same target don't interfere with validate results
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-1", "t1", now)))
+	assert.Equal(t, []string{"id-1"}, getS3SummaryIds(t, s))
+}
+
+func TestResultStoreS3Watch(t *testing.T) {
+	fakeAws := aws.NewFakeClientFactory()
+	s := NewResultStoreS3(context.Background(), fakeAws, "my-bucket", "results", true, 5, 5)
+	s.pollInterval = 10 * time.Millisecond
+
+	now := time.Now()
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-1", "t1", now.Add(-time.Hour))))
+
+	ch, cancel, err := s.WatchCommandResultSummaries(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+
+	nextEvent := func() WatchCommandResultSummaryEvent {
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout while waiting for event")
+		}
+		return WatchCommandResultSummaryEvent{}
+	}
+
+	e := nextEvent()
+	assert.Equal(t, "id-1", e.Summary.Id)
+	assert.False(t, e.Delete)
+
+	assert.NoError(t, s.WriteCommandResult(newTestS3CommandResult("id-2", "t1", now)))
+	e = nextEvent()
+	assert.Equal(t, "id-2", e.Summary.Id)
+	assert.False(t, e.Delete)
+
+	assert.NoError(t, s.DeleteCommandResult("id-1"))
+	e = nextEvent()
+	assert.Equal(t, "id-1", e.Summary.Id)
+	assert.True(t, e.Delete)
+
+	cancel()
+	for range ch {
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 type ResultStoreSecrets struct {
@@ -41,6 +42,7 @@ type ResultStoreSecrets struct {
 	writeNamespace           string
 	keepCommandResultsCount  int
 	keepValidateResultsCount int
+	maxCommandResultAge      time.Duration
 
 	mutex sync.Mutex
 }
@@ -86,6 +88,12 @@ func NewResultStoreSecrets(ctx context.Context, config *rest.Config, client_ cli
 	return s, nil
 }
 
+// SetMaxCommandResultAge configures the store to remove command results of the written target that are older than
+// maxAge after each write, in addition to the results exceeding the keep count. Zero disables the age limit.
+func (s *ResultStoreSecrets) SetMaxCommandResultAge(maxAge time.Duration) {
+	s.maxCommandResultAge = maxAge
+}
+
 var invalidChars = regexp.MustCompile(`[^a-zA-Z0-9-]`)
 
 func (s *ResultStoreSecrets) buildName(prefix string, id string, projectKey gittypes.ProjectKey) string {
@@ -150,6 +158,8 @@ func (s *ResultStoreSecrets) WriteCommandResult(cr *result.CommandResult) error
 		return err
 	}
 
+	cr = cr.ToSorted()
+
 	crJson, err := yaml.WriteJsonString(cr.ToReducedObjects())
 	if err != nil {
 		return err
@@ -211,6 +221,10 @@ func (s *ResultStoreSecrets) WriteCommandResult(cr *result.CommandResult) error
 		return err
 	}
 
+	s.tryUpdateIndex(func(idx *ResultIndex) {
+		idx.Put(*summary.BuildIndexEntry())
+	})
+
 	err = s.cleanupOldCommandResults(cr.ProjectKey, cr.TargetKey)
 	if err != nil {
 		return err
@@ -236,6 +250,10 @@ func (s *ResultStoreSecrets) DeleteCommandResult(rsId string) error {
 	if err != nil {
 		return err
 	}
+
+	s.tryUpdateIndex(func(idx *ResultIndex) {
+		idx.Remove(rsId)
+	})
 	return nil
 }
 
@@ -315,6 +333,7 @@ func (s *ResultStoreSecrets) cleanupOldCommandResults(project gittypes.ProjectKe
 
 	results, err := s.ListCommandResultSummaries(ListResultSummariesOptions{
 		ProjectFilter: &project,
+		Namespace:     s.writeNamespace,
 	})
 	if err != nil {
 		if errors.IsForbidden(err) {
@@ -323,14 +342,20 @@ func (s *ResultStoreSecrets) cleanupOldCommandResults(project gittypes.ProjectKe
 		return err
 	}
 
+	var expiredTime time.Time
+	if s.maxCommandResultAge > 0 {
+		expiredTime = time.Now().Add(-s.maxCommandResultAge)
+	}
+
 	cnt := 0
+	var deleted []string
 	for _, rs := range results {
 		if rs.TargetKey != target {
 			continue
 		}
 		cnt++
 
-		if cnt > s.keepCommandResultsCount {
+		if cnt > s.keepCommandResultsCount || rs.Command.StartTime.Time.Before(expiredTime) {
 			err := s.client.DeleteAllOf(s.ctx, &corev1.Secret{}, client.InNamespace(s.writeNamespace), client.MatchingLabels{
 				"kluctl.io/command-result-id": rs.Id,
 			})
@@ -338,9 +363,15 @@ func (s *ResultStoreSecrets) cleanupOldCommandResults(project gittypes.ProjectKe
 				status.Warningf(s.ctx, "Failed to delete old command result %s: %s", rs.Id, err)
 			} else {
 				status.Infof(s.ctx, "Deleted old command result %s", rs.Id)
+				deleted = append(deleted, rs.Id)
 			}
 		}
 	}
+	if len(deleted) != 0 {
+		s.tryUpdateIndex(func(idx *ResultIndex) {
+			idx.Remove(deleted...)
+		})
+	}
 	return nil
 }
 
@@ -368,12 +399,12 @@ func (s *ResultStoreSecrets) cleanupOrphanedResults() error {
 		deploymentsMap[result.KluctlDeploymentInfo{Name: d.Deployment.Name, Namespace: d.Deployment.Namespace, ClusterId: s.clusterId}] = true
 	}
 
-	tryDeleteResult := func(secretKey client.ObjectKey, deployment *result.KluctlDeploymentInfo, id string, t string) {
+	tryDeleteResult := func(secretKey client.ObjectKey, deployment *result.KluctlDeploymentInfo, id string, t string) bool {
 		if secretKey.Namespace != s.writeNamespace {
-			return
+			return false
 		}
 		if _, ok := deploymentsMap[*deployment]; ok {
-			return
+			return false
 		}
 		secret := corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
@@ -384,16 +415,25 @@ func (s *ResultStoreSecrets) cleanupOrphanedResults() error {
 		err := s.client.Delete(s.ctx, &secret)
 		if err != nil {
 			status.Warningf(s.ctx, "Failed to delete orphaned %s %s for KluctlDeployment %s: %s", t, id, deployment.Name, err)
-		} else {
-			status.Infof(s.ctx, "Deleted orphaned %s %s for KluctlDeployment %s", t, id, deployment.Name)
+			return false
 		}
+		status.Infof(s.ctx, "Deleted orphaned %s %s for KluctlDeployment %s", t, id, deployment.Name)
+		return true
 	}
 
+	var deletedCommandResults []string
 	for _, e := range commandResults {
 		if e.summary.KluctlDeployment == nil {
 			continue
 		}
-		tryDeleteResult(e.name, e.summary.KluctlDeployment, e.summary.Id, "command result")
+		if tryDeleteResult(e.name, e.summary.KluctlDeployment, e.summary.Id, "command result") {
+			deletedCommandResults = append(deletedCommandResults, e.summary.Id)
+		}
+	}
+	if len(deletedCommandResults) != 0 {
+		s.tryUpdateIndex(func(idx *ResultIndex) {
+			idx.Remove(deletedCommandResults...)
+		})
 	}
 	for _, e := range validateResults {
 		if e.summary.KluctlDeployment == nil {
@@ -478,6 +518,9 @@ func (s *ResultStoreSecrets) doListCommandResultSummaries(options ListResultSumm
 	ret := make([]commandResultSummaryAndName, 0, len(l.Items))
 
 	for _, x := range l.Items {
+		if options.Namespace != "" && x.GetNamespace() != options.Namespace {
+			continue
+		}
 		summary, err := s.parseCommandSummary(x.GetAnnotations())
 		if err != nil {
 			continue
@@ -700,6 +743,9 @@ func (s *ResultStoreSecrets) doListValidateResultSummaries(options ListResultSum
 	ret := make([]validateResultSummaryAndName, 0, len(l.Items))
 
 	for _, x := range l.Items {
+		if options.Namespace != "" && x.GetNamespace() != options.Namespace {
+			continue
+		}
 		summary, err := s.parseValidateSummary(x.GetAnnotations())
 		if err != nil {
 			continue
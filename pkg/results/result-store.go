@@ -9,6 +9,9 @@ import (
 
 type ListResultSummariesOptions struct {
 	ProjectFilter *gittypes.ProjectKey `json:"projectFilter,omitempty"`
+	// Namespace limits the results to the given namespace. It is only supported by stores that keep results in
+	// namespaces.
+	Namespace string `json:"namespace,omitempty"`
 }
 
 type GetCommandResultOptions struct {
@@ -36,12 +39,20 @@ type WatchKluctlDeploymentEvent struct {
 	Delete     bool                       `json:"delete"`
 }
 
+// ResultWriter is implemented by everything that command results can be written to
+type ResultWriter interface {
+	WriteCommandResult(cr *result.CommandResult) error
+}
+
 type ResultStore interface {
 	WriteCommandResult(cr *result.CommandResult) error
 	WriteValidateResult(vr *result.ValidateResult) error
 	DeleteCommandResult(rsId string) error
 
 	ListCommandResultSummaries(options ListResultSummariesOptions) ([]result.CommandResultSummary, error)
+	// ListCommandResultIndex lists lightweight index entries instead of full summaries. Full results must then be
+	// fetched lazily via GetCommandResult.
+	ListCommandResultIndex(options ListResultSummariesOptions) ([]result.CommandResultIndexEntry, error)
 	WatchCommandResultSummaries(options ListResultSummariesOptions) (<-chan WatchCommandResultSummaryEvent, context.CancelFunc, error)
 	GetCommandResult(options GetCommandResultOptions) (*result.CommandResult, error)
 
@@ -0,0 +1,172 @@
+// Package resultdiff compares two command results, e.g. to find out what changed between two deployments of the same
+// target.
+package resultdiff
+
+import (
+	"sort"
+
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ResultInfo struct {
+	Id        string           `json:"id"`
+	Command   string           `json:"command,omitempty"`
+	TargetKey result.TargetKey `json:"targetKey"`
+	StartTime metav1.Time      `json:"startTime"`
+}
+
+type ChangedObject struct {
+	Ref     k8s.ObjectRef   `json:"ref"`
+	Changes []result.Change `json:"changes"`
+}
+
+// ResultDiff describes the differences between an older result A and a newer result B
+type ResultDiff struct {
+	A ResultInfo `json:"a"`
+	B ResultInfo `json:"b"`
+
+	// AddedObjects are objects that are only part of B
+	AddedObjects []k8s.ObjectRef `json:"addedObjects,omitempty"`
+	// RemovedObjects are objects that are only part of A
+	RemovedObjects []k8s.ObjectRef `json:"removedObjects,omitempty"`
+	// ChangedObjects are objects that are part of both results but with different manifests
+	ChangedObjects []ChangedObject `json:"changedObjects,omitempty"`
+
+	AddedErrors     []result.DeploymentError `json:"addedErrors,omitempty"`
+	RemovedErrors   []result.DeploymentError `json:"removedErrors,omitempty"`
+	AddedWarnings   []result.DeploymentError `json:"addedWarnings,omitempty"`
+	RemovedWarnings []result.DeploymentError `json:"removedWarnings,omitempty"`
+}
+
+func (d *ResultDiff) IsEmpty() bool {
+	return len(d.AddedObjects) == 0 && len(d.RemovedObjects) == 0 && len(d.ChangedObjects) == 0 &&
+		len(d.AddedErrors) == 0 && len(d.RemovedErrors) == 0 && len(d.AddedWarnings) == 0 && len(d.RemovedWarnings) == 0
+}
+
+func buildResultInfo(cr *result.CommandResult) ResultInfo {
+	return ResultInfo{
+		Id:        cr.Id,
+		Command:   cr.Command.Command,
+		TargetKey: cr.TargetKey,
+		StartTime: cr.Command.StartTime,
+	}
+}
+
+// Compare compares the manifests, errors and warnings of the two results. The manifest of an object is the applied
+// manifest, or the rendered manifest for results of commands that did not apply anything (e.g. diff).
+//
+// All manifests are obfuscated before they are compared, so that secret values never show up in the result, even if
+// one of the results was written with obfuscation disabled. Values obfuscated in hash mode are redacted as well, as
+// hashes of different results are salted differently and can't be compared.
+func Compare(a *result.CommandResult, b *result.CommandResult) (*ResultDiff, error) {
+	ret := &ResultDiff{
+		A: buildResultInfo(a),
+		B: buildResultInfo(b),
+	}
+
+	obfuscator := &diff.Obfuscator{
+		Mode:             diff.ObfuscateModeRedact,
+		Rules:            append(append([]types.ObfuscationRule{}, getObfuscationRules(a)...), getObfuscationRules(b)...),
+		RedactHashTokens: true,
+	}
+
+	objectsA, err := collectManifests(a, obfuscator)
+	if err != nil {
+		return nil, err
+	}
+	objectsB, err := collectManifests(b, obfuscator)
+	if err != nil {
+		return nil, err
+	}
+
+	for ref, ob := range objectsB {
+		oa, ok := objectsA[ref]
+		if !ok {
+			ret.AddedObjects = append(ret.AddedObjects, ref)
+			continue
+		}
+		changes, err := diff.Diff(oa, ob)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) != 0 {
+			ret.ChangedObjects = append(ret.ChangedObjects, ChangedObject{Ref: ref, Changes: changes})
+		}
+	}
+	for ref := range objectsA {
+		if _, ok := objectsB[ref]; !ok {
+			ret.RemovedObjects = append(ret.RemovedObjects, ref)
+		}
+	}
+
+	sortRefs(ret.AddedObjects)
+	sortRefs(ret.RemovedObjects)
+	sort.Slice(ret.ChangedObjects, func(i, j int) bool {
+		return ret.ChangedObjects[i].Ref.Less(ret.ChangedObjects[j].Ref)
+	})
+
+	ret.AddedErrors, ret.RemovedErrors = compareErrors(a.Errors, b.Errors)
+	ret.AddedWarnings, ret.RemovedWarnings = compareErrors(a.Warnings, b.Warnings)
+
+	return ret, nil
+}
+
+func getObfuscationRules(cr *result.CommandResult) []types.ObfuscationRule {
+	if cr.Deployment == nil {
+		return nil
+	}
+	return cr.Deployment.Obfuscate
+}
+
+func collectManifests(cr *result.CommandResult, obfuscator *diff.Obfuscator) (map[k8s.ObjectRef]*uo.UnstructuredObject, error) {
+	ret := map[k8s.ObjectRef]*uo.UnstructuredObject{}
+	for _, o := range cr.Objects {
+		m := o.Applied
+		if m == nil {
+			m = o.Rendered
+		}
+		if m == nil || o.Deleted {
+			continue
+		}
+		m, err := obfuscator.ObfuscateObject(diff.StripServerFields(m))
+		if err != nil {
+			return nil, err
+		}
+		ret[o.Ref] = m
+	}
+	return ret, nil
+}
+
+func sortRefs(l []k8s.ObjectRef) {
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].Less(l[j])
+	})
+}
+
+type errorKey struct {
+	ref     k8s.ObjectRef
+	message string
+}
+
+// compareErrors returns the errors that are only part of b (added) and the errors that are only part of a (removed)
+func compareErrors(a []result.DeploymentError, b []result.DeploymentError) ([]result.DeploymentError, []result.DeploymentError) {
+	diffErrors := func(x []result.DeploymentError, y []result.DeploymentError) []result.DeploymentError {
+		m := map[errorKey]bool{}
+		for _, e := range y {
+			m[errorKey{ref: e.Ref, message: e.Message}] = true
+		}
+		var ret []result.DeploymentError
+		for _, e := range x {
+			if !m[errorKey{ref: e.Ref, message: e.Message}] {
+				ret = append(ret, e)
+			}
+		}
+		return ret
+	}
+	return diffErrors(b, a), diffErrors(a, b)
+}
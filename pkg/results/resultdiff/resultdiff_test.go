@@ -0,0 +1,110 @@
+package resultdiff
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildObject(kind string, name string, field string, data map[string]any) result.ResultObject {
+	o := uo.New()
+	o.SetK8sGVKs("", "v1", kind)
+	o.SetK8sName(name)
+	o.SetK8sNamespace("ns")
+	o.SetK8sResourceVersion("1")
+	_ = o.SetNestedField(data, field)
+	return result.ResultObject{
+		BaseObject: result.BaseObject{Ref: o.GetK8sRef()},
+		Applied:    o,
+	}
+}
+
+func ref(kind string, name string) k8s.ObjectRef {
+	return k8s.ObjectRef{Version: "v1", Kind: kind, Namespace: "ns", Name: name}
+}
+
+func TestCompare(t *testing.T) {
+	a := &result.CommandResult{
+		Id: "a",
+		Objects: []result.ResultObject{
+			buildObject("ConfigMap", "unchanged", "data", map[string]any{"a": "1"}),
+			buildObject("ConfigMap", "changed", "data", map[string]any{"a": "1", "b": "2"}),
+			buildObject("ConfigMap", "removed", "data", map[string]any{"a": "1"}),
+		},
+		Errors: []result.DeploymentError{
+			{Ref: ref("ConfigMap", "changed"), Message: "fixed"},
+			{Ref: ref("ConfigMap", "changed"), Message: "still broken"},
+		},
+	}
+	b := &result.CommandResult{
+		Id: "b",
+		Objects: []result.ResultObject{
+			buildObject("ConfigMap", "unchanged", "data", map[string]any{"a": "1"}),
+			buildObject("ConfigMap", "changed", "data", map[string]any{"a": "1", "b": "3"}),
+			buildObject("ConfigMap", "added", "data", map[string]any{"a": "1"}),
+		},
+		Errors: []result.DeploymentError{
+			{Ref: ref("ConfigMap", "changed"), Message: "still broken"},
+		},
+		Warnings: []result.DeploymentError{
+			{Ref: ref("ConfigMap", "added"), Message: "new warning"},
+		},
+	}
+	// server populated fields are ignored
+	b.Objects[0].Applied.SetK8sResourceVersion("2")
+
+	d, err := Compare(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", d.A.Id)
+	assert.Equal(t, "b", d.B.Id)
+	assert.Equal(t, []k8s.ObjectRef{ref("ConfigMap", "added")}, d.AddedObjects)
+	assert.Equal(t, []k8s.ObjectRef{ref("ConfigMap", "removed")}, d.RemovedObjects)
+	assert.Len(t, d.ChangedObjects, 1)
+	assert.Equal(t, ref("ConfigMap", "changed"), d.ChangedObjects[0].Ref)
+	assert.Len(t, d.ChangedObjects[0].Changes, 1)
+	assert.Equal(t, `data["b"]`, d.ChangedObjects[0].Changes[0].JsonPath)
+	assert.Equal(t, []result.DeploymentError{{Ref: ref("ConfigMap", "changed"), Message: "fixed"}}, d.RemovedErrors)
+	assert.Empty(t, d.AddedErrors)
+	assert.Equal(t, []result.DeploymentError{{Ref: ref("ConfigMap", "added"), Message: "new warning"}}, d.AddedWarnings)
+	assert.Empty(t, d.RemovedWarnings)
+	assert.False(t, d.IsEmpty())
+
+	d, err = Compare(a, a)
+	assert.NoError(t, err)
+	assert.True(t, d.IsEmpty())
+}
+
+func TestCompareObfuscatesSecrets(t *testing.T) {
+	b64 := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	a := &result.CommandResult{
+		Objects: []result.ResultObject{
+			buildObject("Secret", "s", "data", map[string]any{"a": b64("secret1")}),
+		},
+	}
+	b := &result.CommandResult{
+		Objects: []result.ResultObject{
+			buildObject("Secret", "s", "data", map[string]any{"a": b64("secret2"), "b": b64("secret3")}),
+		},
+	}
+
+	d, err := Compare(a, b)
+	assert.NoError(t, err)
+	assert.Len(t, d.ChangedObjects, 1)
+	for _, c := range d.ChangedObjects[0].Changes {
+		for _, s := range []string{"secret1", "secret2", "secret3", b64("secret1"), b64("secret2"), b64("secret3")} {
+			assert.NotContains(t, c.UnifiedDiff, s)
+			if c.OldValue != nil {
+				assert.NotContains(t, string(c.OldValue.Raw), s)
+			}
+			if c.NewValue != nil {
+				assert.NotContains(t, string(c.NewValue.Raw), s)
+			}
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"k8s.io/apimachinery/pkg/types"
 	"sort"
 	"sync"
@@ -19,6 +20,10 @@ type ResultsCollector struct {
 	commandResultSummaries map[string]commandSummaryEntry
 	commandResultWatches   []*commandResultWatchEntry
 
+	// commandResultIndexStores remembers the store of every command result that was listed via the index, so that
+	// full results can be fetched lazily without watching the summaries of all stores
+	commandResultIndexStores map[string]ResultStore
+
 	validateResultSummaries map[string]validateSummaryEntry
 	validateResultWatches   []*validateResultWatchEntry
 
@@ -59,11 +64,12 @@ type kluctlDeploymentWatchEntry struct {
 
 func NewResultsCollector(ctx context.Context, stores []ResultStore) *ResultsCollector {
 	ret := &ResultsCollector{
-		ctx:                     ctx,
-		stores:                  stores,
-		commandResultSummaries:  map[string]commandSummaryEntry{},
-		validateResultSummaries: map[string]validateSummaryEntry{},
-		kluctlDeployments:       map[types.UID]kluctlDeploymentEntry{},
+		ctx:                      ctx,
+		stores:                   stores,
+		commandResultSummaries:   map[string]commandSummaryEntry{},
+		commandResultIndexStores: map[string]ResultStore{},
+		validateResultSummaries:  map[string]validateSummaryEntry{},
+		kluctlDeployments:        map[types.UID]kluctlDeploymentEntry{},
 	}
 
 	return ret
@@ -313,14 +319,47 @@ func (rc *ResultsCollector) WatchCommandResultSummaries(options ListResultSummar
 	return w.ch, cancel, nil
 }
 
+// ListCommandResultIndex queries the indexes of all stores in parallel and returns the merged entries
+func (rc *ResultsCollector) ListCommandResultIndex(options ListResultSummariesOptions) ([]result.CommandResultIndexEntry, error) {
+	g := utils.NewGoHelperR[[]result.CommandResultIndexEntry](rc.ctx, 8)
+	for _, store := range rc.stores {
+		store := store
+		g.RunRE(func() ([]result.CommandResultIndexEntry, error) {
+			return store.ListCommandResultIndex(options)
+		})
+	}
+	g.Wait()
+	if err := g.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	var ret []result.CommandResultIndexEntry
+	for i, entries := range g.Results() {
+		for _, e := range entries {
+			rc.commandResultIndexStores[e.Id] = rc.stores[i]
+		}
+		ret = append(ret, entries...)
+	}
+	sortIndexEntries(ret)
+	return ret, nil
+}
+
 func (rc *ResultsCollector) GetCommandResult(options GetCommandResultOptions) (*result.CommandResult, error) {
 	rc.mutex.Lock()
-	se, ok := rc.commandResultSummaries[options.Id]
+	var store ResultStore
+	if se, ok := rc.commandResultSummaries[options.Id]; ok {
+		store = se.store
+	} else {
+		store = rc.commandResultIndexStores[options.Id]
+	}
 	rc.mutex.Unlock()
-	if !ok {
+	if store == nil {
 		return nil, nil
 	}
-	return se.store.GetCommandResult(options)
+	return store.GetCommandResult(options)
 }
 
 func (rc *ResultsCollector) ListValidateResultSummaries(options ListResultSummariesOptions) ([]result.ValidateResultSummary, error) {
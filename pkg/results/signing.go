@@ -0,0 +1,311 @@
+package results
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	SignatureAlgorithmEcdsaSha256 = "ecdsa-sha256"
+	SignatureAlgorithmEd25519     = "ed25519"
+)
+
+type VerifyStatus string
+
+const (
+	VerifyStatusValid    VerifyStatus = "valid"
+	VerifyStatusInvalid  VerifyStatus = "invalid"
+	VerifyStatusUnsigned VerifyStatus = "unsigned"
+	// VerifyStatusUntrusted means that the signature matches the public key stored in the result, but no trusted key
+	// was given. Anyone able to modify the result could have re-signed it with their own key, so this does not prove
+	// the integrity of the result.
+	VerifyStatusUntrusted VerifyStatus = "valid (untrusted key)"
+)
+
+type VerifyResult struct {
+	Status VerifyStatus `json:"status"`
+	// KeyId is the fingerprint of the key that the result was signed with
+	KeyId   string `json:"keyId,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ResultSigner signs command results with a private key before they are written to result stores
+type ResultSigner struct {
+	key       crypto.Signer
+	algorithm string
+	keyId     string
+	publicKey string
+}
+
+// LoadResultSigner loads a PEM encoded ECDSA or Ed25519 private key. Keys generated via 'cosign generate-key-pair'
+// are supported as well, in which case the password is used to decrypt the key.
+func LoadResultSigner(path string, password []byte) (*ResultSigner, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePrivateKey(b, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing key %s: %w", path, err)
+	}
+	return NewResultSigner(key)
+}
+
+func NewResultSigner(key crypto.Signer) (*ResultSigner, error) {
+	s := &ResultSigner{
+		key: key,
+	}
+	switch key.(type) {
+	case *ecdsa.PrivateKey:
+		s.algorithm = SignatureAlgorithmEcdsaSha256
+	case ed25519.PrivateKey:
+		s.algorithm = SignatureAlgorithmEd25519
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T, only ECDSA and Ed25519 keys are supported", key)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	s.keyId = buildKeyId(der)
+	s.publicKey = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	return s, nil
+}
+
+func (s *ResultSigner) KeyId() string {
+	return s.keyId
+}
+
+// Sign signs the command result and stores the signature in the result. It must be called after the result got
+// obfuscated and right before it is written, as every later modification invalidates the signature.
+func (s *ResultSigner) Sign(cr *result.CommandResult) error {
+	payload, err := buildSigningPayload(cr)
+	if err != nil {
+		return err
+	}
+
+	var sig []byte
+	switch s.algorithm {
+	case SignatureAlgorithmEcdsaSha256:
+		h := sha256.Sum256(payload)
+		sig, err = s.key.Sign(rand.Reader, h[:], crypto.SHA256)
+	case SignatureAlgorithmEd25519:
+		sig, err = s.key.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sign command result: %w", err)
+	}
+
+	cr.Signature = &result.CommandResultSignature{
+		Algorithm: s.algorithm,
+		KeyId:     s.keyId,
+		PublicKey: s.publicKey,
+		Signature: base64.StdEncoding.EncodeToString(sig),
+	}
+	return nil
+}
+
+// VerifyCommandResult verifies the signature of the given command result. Results without a signature (e.g. results
+// written by older versions) are reported as unsigned. The result must be signed by trustedKey to be reported as
+// valid. If trustedKey is nil, matching signatures are reported as untrusted.
+func VerifyCommandResult(cr *result.CommandResult, trustedKey crypto.PublicKey) VerifyResult {
+	if cr.Signature == nil {
+		return VerifyResult{Status: VerifyStatusUnsigned}
+	}
+
+	sig := cr.Signature
+	ret := VerifyResult{
+		Status: VerifyStatusInvalid,
+		KeyId:  sig.KeyId,
+	}
+
+	block, _ := pem.Decode([]byte(sig.PublicKey))
+	if block == nil {
+		ret.Message = "failed to decode public key"
+		return ret
+	}
+	if buildKeyId(block.Bytes) != sig.KeyId {
+		ret.Message = "key id does not match public key"
+		return ret
+	}
+	if trustedKey != nil {
+		der, err := x509.MarshalPKIXPublicKey(trustedKey)
+		if err != nil {
+			ret.Message = err.Error()
+			return ret
+		}
+		if buildKeyId(der) != sig.KeyId {
+			ret.Message = "result was not signed by the trusted key"
+			return ret
+		}
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		ret.Message = fmt.Sprintf("failed to parse public key: %s", err.Error())
+		return ret
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		ret.Message = fmt.Sprintf("failed to decode signature: %s", err.Error())
+		return ret
+	}
+	payload, err := buildSigningPayload(cr)
+	if err != nil {
+		ret.Message = err.Error()
+		return ret
+	}
+
+	ok := false
+	switch sig.Algorithm {
+	case SignatureAlgorithmEcdsaSha256:
+		if pub2, ok2 := pub.(*ecdsa.PublicKey); ok2 {
+			h := sha256.Sum256(payload)
+			ok = ecdsa.VerifyASN1(pub2, h[:], sigBytes)
+		}
+	case SignatureAlgorithmEd25519:
+		if pub2, ok2 := pub.(ed25519.PublicKey); ok2 {
+			ok = ed25519.Verify(pub2, payload, sigBytes)
+		}
+	default:
+		ret.Message = fmt.Sprintf("unsupported signature algorithm %s", sig.Algorithm)
+		return ret
+	}
+	if !ok {
+		ret.Message = "signature does not match the command result"
+		return ret
+	}
+	if trustedKey == nil {
+		ret.Status = VerifyStatusUntrusted
+		ret.Message = "no trusted key was given"
+		return ret
+	}
+	ret.Status = VerifyStatusValid
+	return ret
+}
+
+// LoadPublicKey loads a PEM encoded public key, e.g. a cosign.pub
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("%s does not contain a PEM encoded public key", path)
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// buildSigningPayload returns the canonical JSON representation of the command result without its signature. The
+// result is normalized via an unstructured object, so that the payload does not depend on how the result was stored
+// and loaded again. Lists are sorted the same way as when the result is stored, so that the order in which objects
+// and changes were recorded does not matter.
+func buildSigningPayload(cr *result.CommandResult) ([]byte, error) {
+	cr2 := *cr.ToSorted()
+	cr2.Signature = nil
+	u, err := uo.FromStruct(&cr2)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(u.Object)
+}
+
+func buildKeyId(publicKeyDer []byte) string {
+	h := sha256.Sum256(publicKeyDer)
+	return "sha256:" + hex.EncodeToString(h[:])
+}
+
+// cosignEncryptedKey is the format of encrypted private keys as written by cosign
+type cosignEncryptedKey struct {
+	Kdf struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func parsePrivateKey(b []byte, password []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	der := block.Bytes
+	switch block.Type {
+	case "ENCRYPTED SIGSTORE PRIVATE KEY", "ENCRYPTED COSIGN PRIVATE KEY":
+		var err error
+		der, err = decryptCosignKey(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(der)
+	case "PRIVATE KEY":
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %s", block.Type)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+func decryptCosignKey(b []byte, password []byte) ([]byte, error) {
+	var ek cosignEncryptedKey
+	err := json.Unmarshal(b, &ek)
+	if err != nil {
+		return nil, err
+	}
+	if ek.Kdf.Name != "scrypt" || ek.Cipher.Name != "nacl/secretbox" {
+		return nil, fmt.Errorf("unsupported key encryption %s/%s", ek.Kdf.Name, ek.Cipher.Name)
+	}
+	if len(ek.Cipher.Nonce) != 24 {
+		return nil, fmt.Errorf("invalid nonce length")
+	}
+
+	key, err := scrypt.Key(password, ek.Kdf.Salt, ek.Kdf.Params.N, ek.Kdf.Params.R, ek.Kdf.Params.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	var secretKey [32]byte
+	copy(nonce[:], ek.Cipher.Nonce)
+	copy(secretKey[:], key)
+
+	der, ok := secretbox.Open(nil, ek.Ciphertext, &nonce, &secretKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to decrypt key, wrong password?")
+	}
+	return der, nil
+}
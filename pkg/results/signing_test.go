@@ -0,0 +1,127 @@
+package results
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// storeAndLoad simulates writing and reading the result the same way the Secrets based result store does it
+func storeAndLoad(t *testing.T, cr *result.CommandResult) *result.CommandResult {
+	reduced, err := yaml.WriteJsonString(cr.ToReducedObjects())
+	assert.NoError(t, err)
+	objects, err := yaml.WriteJsonString(result.CompactedObjects(cr.Objects))
+	assert.NoError(t, err)
+
+	var ret result.CommandResult
+	assert.NoError(t, yaml.ReadYamlString(reduced, &ret))
+	var objects2 result.CompactedObjects
+	assert.NoError(t, yaml.ReadYamlString(objects, &objects2))
+	ret.Objects = objects2
+	return &ret
+}
+
+func TestSignAndVerifyCommandResult(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	for _, key := range []crypto.Signer{ecKey, edKey} {
+		s, err := NewResultSigner(key)
+		assert.NoError(t, err)
+
+		cr := newSpoolTestResult("id-1")
+		cr.Command.Initiator = result.CommandInititiator_CommandLine
+		assert.NoError(t, s.Sign(cr))
+
+		loaded := storeAndLoad(t, cr)
+		vr := VerifyCommandResult(loaded, key.Public())
+		assert.Equal(t, VerifyStatusValid, vr.Status, vr.Message)
+		assert.Equal(t, s.KeyId(), vr.KeyId)
+
+		loaded.Objects[0].Rendered.SetK8sName("other")
+		vr = VerifyCommandResult(loaded, key.Public())
+		assert.Equal(t, VerifyStatusInvalid, vr.Status)
+	}
+}
+
+func TestVerifyCommandResultTrustedKey(t *testing.T) {
+	key1, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	key2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	s, err := NewResultSigner(key1)
+	assert.NoError(t, err)
+	cr := newSpoolTestResult("id-1")
+	assert.NoError(t, s.Sign(cr))
+
+	assert.Equal(t, VerifyStatusValid, VerifyCommandResult(cr, key1.Public()).Status)
+	assert.Equal(t, VerifyStatusInvalid, VerifyCommandResult(cr, key2.Public()).Status)
+
+	// without a trusted key, a result that was modified and re-signed with another key would look valid as well
+	assert.Equal(t, VerifyStatusUntrusted, VerifyCommandResult(cr, nil).Status)
+	cr.Objects[0].Rendered.SetK8sName("other")
+	s2, err := NewResultSigner(key2)
+	assert.NoError(t, err)
+	assert.NoError(t, s2.Sign(cr))
+	assert.Equal(t, VerifyStatusUntrusted, VerifyCommandResult(cr, nil).Status)
+	assert.Equal(t, VerifyStatusInvalid, VerifyCommandResult(cr, key1.Public()).Status)
+}
+
+func TestVerifyUnsignedCommandResult(t *testing.T) {
+	vr := VerifyCommandResult(newSpoolTestResult("id-1"), nil)
+	assert.Equal(t, VerifyStatusUnsigned, vr.Status)
+}
+
+func TestLoadCosignEncryptedKey(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	var ek cosignEncryptedKey
+	ek.Kdf.Name = "scrypt"
+	ek.Kdf.Params.N = 1024
+	ek.Kdf.Params.R = 8
+	ek.Kdf.Params.P = 1
+	ek.Kdf.Salt = []byte("0123456789abcdef0123456789abcdef")
+	ek.Cipher.Name = "nacl/secretbox"
+	ek.Cipher.Nonce = []byte("0123456789abcdef01234567")
+
+	secretKey, err := scrypt.Key([]byte("password"), ek.Kdf.Salt, ek.Kdf.Params.N, ek.Kdf.Params.R, ek.Kdf.Params.P, 32)
+	assert.NoError(t, err)
+	var nonce [24]byte
+	var secretKey2 [32]byte
+	copy(nonce[:], ek.Cipher.Nonce)
+	copy(secretKey2[:], secretKey)
+	ek.Ciphertext = secretbox.Seal(nil, der, &nonce, &secretKey2)
+
+	b, err := json.Marshal(&ek)
+	assert.NoError(t, err)
+	p := filepath.Join(t.TempDir(), "cosign.key")
+	err = os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: b}), 0o600)
+	assert.NoError(t, err)
+
+	_, err = LoadResultSigner(p, []byte("wrong"))
+	assert.ErrorContains(t, err, "wrong password")
+
+	s, err := LoadResultSigner(p, []byte("password"))
+	assert.NoError(t, err)
+
+	expected, err := NewResultSigner(key)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.KeyId(), s.KeyId())
+}
@@ -0,0 +1,150 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+const (
+	ColorError     = "danger"
+	ColorChanges   = "warning"
+	ColorNoChanges = "good"
+)
+
+// Message is the payload of a Slack incoming webhook
+type Message struct {
+	Text        string       `json:"text"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+type Attachment struct {
+	Color     string  `json:"color,omitempty"`
+	Fallback  string  `json:"fallback,omitempty"`
+	Title     string  `json:"title,omitempty"`
+	TitleLink string  `json:"title_link,omitempty"`
+	Fields    []Field `json:"fields,omitempty"`
+}
+
+type Field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// BuildResultURL returns the URL of the command result in the webui, or an empty string if no webui URL is given
+func BuildResultURL(webuiURL string, resultId string) string {
+	if webuiURL == "" || resultId == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/?commandResultId=%s", strings.TrimSuffix(webuiURL, "/"), url.QueryEscape(resultId))
+}
+
+// escape escapes the control characters of Slack's mrkdwn format
+func escape(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	s = strings.ReplaceAll(s, ">", "&gt;")
+	return s
+}
+
+// BuildMessage builds a compact notification message for the given command result. The result must already be
+// obfuscated. If webuiURL is not empty, the message links to the result in the webui.
+func BuildMessage(cr *result.CommandResult, webuiURL string) *Message {
+	counts := cr.BuildCounts()
+
+	title := fmt.Sprintf("kluctl %s", cr.Command.Command)
+	if cr.Target.Name != "" {
+		title += fmt.Sprintf(" on target %s", cr.Target.Name)
+	}
+	if cr.Command.DryRun {
+		title += " (dry-run)"
+	}
+
+	var color, outcome string
+	switch {
+	case counts.Errors != 0:
+		color = ColorError
+		outcome = "failed"
+	case counts.NewObjects != 0 || counts.ChangedObjects != 0 || counts.DeletedObjects != 0:
+		color = ColorChanges
+		outcome = "succeeded with changes"
+	default:
+		color = ColorNoChanges
+		outcome = "succeeded without changes"
+	}
+
+	a := Attachment{
+		Color:     color,
+		Fallback:  fmt.Sprintf("%s %s", title, outcome),
+		Title:     escape(title),
+		TitleLink: BuildResultURL(webuiURL, cr.Id),
+	}
+	addField := func(title string, value string) {
+		a.Fields = append(a.Fields, Field{Title: title, Value: value, Short: true})
+	}
+	if cr.Target.Name != "" {
+		addField("Target", escape(cr.Target.Name))
+	}
+	if cr.Command.Initiator != "" {
+		addField("Initiator", escape(string(cr.Command.Initiator)))
+	}
+	addField("New", strconv.Itoa(counts.NewObjects))
+	addField("Changed", strconv.Itoa(counts.ChangedObjects))
+	addField("Deleted", strconv.Itoa(counts.DeletedObjects))
+	addField("Errors", strconv.Itoa(counts.Errors))
+	if !cr.Command.StartTime.IsZero() && !cr.Command.EndTime.IsZero() {
+		d := cr.Command.EndTime.Sub(cr.Command.StartTime.Time).Round(time.Second)
+		addField("Duration", d.String())
+	}
+
+	return &Message{
+		Text:        escape(fmt.Sprintf("%s %s", title, outcome)),
+		Attachments: []Attachment{a},
+	}
+}
+
+// PostMessage posts the given message to a Slack incoming webhook
+func PostMessage(ctx context.Context, webhookURL string, msg *Message) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(b))
+	if err != nil {
+		// don't include the parse error, as it contains the URL
+		return fmt.Errorf("invalid Slack webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		// the error contains the URL, which is a secret in case of webhooks
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("posting to Slack webhook failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
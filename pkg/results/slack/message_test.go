@@ -0,0 +1,118 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestResult() *result.CommandResult {
+	startTime := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	return &result.CommandResult{
+		Id:     "result-1",
+		Target: types.Target{Name: "prod"},
+		Command: result.CommandInfo{
+			Command:   "deploy",
+			Initiator: result.CommandInititiator_CommandLine,
+			StartTime: metav1.NewTime(startTime),
+			EndTime:   metav1.NewTime(startTime.Add(90 * time.Second)),
+		},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "cm", Namespace: "ns"}, Changes: []result.Change{
+				{Type: "update", JsonPath: "data.key"},
+			}}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "new", Namespace: "ns"}, New: true}},
+		},
+	}
+}
+
+func getFieldValues(m *Message) map[string]string {
+	ret := map[string]string{}
+	for _, f := range m.Attachments[0].Fields {
+		ret[f.Title] = f.Value
+	}
+	return ret
+}
+
+func TestBuildMessage(t *testing.T) {
+	cr := newTestResult()
+
+	m := BuildMessage(cr, "https://kluctl.example.com/")
+	assert.Equal(t, "kluctl deploy on target prod succeeded with changes", m.Text)
+	assert.Len(t, m.Attachments, 1)
+	assert.Equal(t, ColorChanges, m.Attachments[0].Color)
+	assert.Equal(t, "https://kluctl.example.com/?commandResultId=result-1", m.Attachments[0].TitleLink)
+	assert.Equal(t, map[string]string{
+		"Target":    "prod",
+		"Initiator": "CommandLine",
+		"New":       "1",
+		"Changed":   "1",
+		"Deleted":   "0",
+		"Errors":    "0",
+		"Duration":  "1m30s",
+	}, getFieldValues(m))
+
+	// no link without webui URL
+	m = BuildMessage(cr, "")
+	assert.Empty(t, m.Attachments[0].TitleLink)
+
+	cr.Errors = []result.DeploymentError{{Message: "error"}}
+	m = BuildMessage(cr, "")
+	assert.Equal(t, ColorError, m.Attachments[0].Color)
+	assert.Equal(t, "kluctl deploy on target prod failed", m.Text)
+	assert.Equal(t, "1", getFieldValues(m)["Errors"])
+
+	cr.Errors = nil
+	cr.Objects = nil
+	m = BuildMessage(cr, "")
+	assert.Equal(t, ColorNoChanges, m.Attachments[0].Color)
+	assert.Equal(t, "kluctl deploy on target prod succeeded without changes", m.Text)
+}
+
+func TestBuildMessageEscaping(t *testing.T) {
+	cr := newTestResult()
+	cr.Target.Name = "<!channel> & co"
+
+	m := BuildMessage(cr, "")
+	assert.Equal(t, "kluctl deploy on target &lt;!channel&gt; &amp; co succeeded with changes", m.Text)
+	assert.Equal(t, "&lt;!channel&gt; &amp; co", getFieldValues(m)["Target"])
+}
+
+func TestPostMessage(t *testing.T) {
+	var received Message
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/T0/B0/secret" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("no_service"))
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	m := BuildMessage(newTestResult(), "")
+	err := PostMessage(context.Background(), s.URL+"/services/T0/B0/secret", m)
+	assert.NoError(t, err)
+	assert.Equal(t, *m, received)
+
+	err = PostMessage(context.Background(), s.URL+"/services/T0/B0/wrong", m)
+	assert.ErrorContains(t, err, "status 404: no_service")
+	assert.NotContains(t, err.Error(), "wrong")
+
+	// the webhook URL is secret and must not end up in errors
+	s.Close()
+	err = PostMessage(context.Background(), s.URL+"/services/T0/B0/secret", m)
+	assert.Error(t, err)
+	assert.False(t, strings.Contains(err.Error(), "secret"))
+}
@@ -0,0 +1,116 @@
+// Package tmplreport renders command and validation results with user provided Go templates.
+package tmplreport
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+// CommandResultData is the context passed to templates that render command results. The objects are pre-grouped, so
+// that templates do not have to re-implement the categorization of objects.
+type CommandResultData struct {
+	// Result is the compacted and sorted command result, as written by the 'yaml' and 'json' formats
+	Result  *result.CompactedCommandResult
+	Summary result.CommandResultCounts
+
+	// Objects contains all objects, sorted by their reference
+	Objects            []result.ResultObject
+	NewObjects         []result.ResultObject
+	ChangedObjects     []result.ResultObject
+	DeletedObjects     []result.ResultObject
+	OrphanObjects      []result.ResultObject
+	AppliedHookObjects []result.ResultObject
+
+	Errors   []result.DeploymentError
+	Warnings []result.DeploymentError
+}
+
+// ValidateResultData is the context passed to templates that render validation results
+type ValidateResultData struct {
+	Result *result.ValidateResult
+
+	Results  []result.ValidateResultEntry
+	Errors   []result.DeploymentError
+	Warnings []result.DeploymentError
+}
+
+// Parse parses the given template text. name is used in error messages, which also contain line numbers.
+func Parse(name string, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(sprig.TxtFuncMap()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return t, nil
+}
+
+// ParseFile reads and parses the given template file
+func ParseFile(path string) (*template.Template, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	return Parse(path, string(b))
+}
+
+func BuildCommandResultData(cr *result.CommandResult) *CommandResultData {
+	ccr := cr.ToCompacted()
+	d := &CommandResultData{
+		Result:   ccr,
+		Summary:  *ccr.Summary,
+		Objects:  ccr.CompactedObjects,
+		Errors:   ccr.Errors,
+		Warnings: ccr.Warnings,
+	}
+	for _, o := range d.Objects {
+		if o.New {
+			d.NewObjects = append(d.NewObjects, o)
+		}
+		if len(o.Changes) != 0 {
+			d.ChangedObjects = append(d.ChangedObjects, o)
+		}
+		if o.Deleted {
+			d.DeletedObjects = append(d.DeletedObjects, o)
+		}
+		if o.Orphan {
+			d.OrphanObjects = append(d.OrphanObjects, o)
+		}
+		if o.Hook {
+			d.AppliedHookObjects = append(d.AppliedHookObjects, o)
+		}
+	}
+	return d
+}
+
+func BuildValidateResultData(vr *result.ValidateResult) *ValidateResultData {
+	return &ValidateResultData{
+		Result:   vr,
+		Results:  vr.Results,
+		Errors:   vr.Errors,
+		Warnings: vr.Warnings,
+	}
+}
+
+// Execute executes the template against the given data and returns the output
+func Execute(t *template.Template, data any) (string, error) {
+	var b strings.Builder
+	err := t.Execute(&b, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// RenderCommandResult renders the command result with the given template
+func RenderCommandResult(t *template.Template, cr *result.CommandResult) (string, error) {
+	return Execute(t, BuildCommandResultData(cr))
+}
+
+// RenderValidateResult renders the validation result with the given template
+func RenderValidateResult(t *template.Template, vr *result.ValidateResult) (string, error) {
+	return Execute(t, BuildValidateResultData(vr))
+}
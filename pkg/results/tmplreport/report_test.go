@@ -0,0 +1,97 @@
+package tmplreport
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func newTestCommandResult() *result.CommandResult {
+	return &result.CommandResult{
+		Id:     "test-id",
+		Target: types.Target{Name: "prod"},
+		Command: result.CommandInfo{
+			Command: "deploy",
+		},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "new-cm", Namespace: "ns"}, New: true}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Name: "app", Namespace: "ns"}, Changes: []result.Change{
+				{Type: "update", JsonPath: "spec.replicas", UnifiedDiff: "-1\n+2"},
+				{Type: "update", JsonPath: "spec.template.spec.containers[0].image", UnifiedDiff: "-app:1\n+app:2"},
+			}}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "changed-cm", Namespace: "ns"}, Changes: []result.Change{
+				{Type: "update", JsonPath: "data.key", UnifiedDiff: "-old\n+new"},
+			}}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "Secret", Name: "deleted", Namespace: "ns"}, Deleted: true}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "Secret", Name: "orphan", Namespace: "ns"}, Orphan: true}},
+		},
+		Errors: []result.DeploymentError{
+			{Ref: k8s.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Name: "app", Namespace: "ns"}, Message: "something failed"},
+		},
+	}
+}
+
+func assertGolden(t *testing.T, path string, s string) {
+	if *updateGolden {
+		assert.NoError(t, os.WriteFile(path, []byte(s), 0o600))
+	}
+	golden, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(golden), s)
+}
+
+func TestRenderCommandResultGolden(t *testing.T) {
+	for _, name := range []string{"changed-objects.csv", "slack"} {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := ParseFile(filepath.Join("testdata", name+".tmpl"))
+			assert.NoError(t, err)
+			s, err := RenderCommandResult(tmpl, newTestCommandResult())
+			assert.NoError(t, err)
+			assertGolden(t, filepath.Join("testdata", name+".golden"), s)
+		})
+	}
+}
+
+func TestBuildCommandResultData(t *testing.T) {
+	d := BuildCommandResultData(newTestCommandResult())
+	assert.Len(t, d.Objects, 5)
+	assert.Len(t, d.NewObjects, 1)
+	assert.Len(t, d.ChangedObjects, 2)
+	assert.Len(t, d.DeletedObjects, 1)
+	assert.Len(t, d.OrphanObjects, 1)
+	assert.Equal(t, 2, d.Summary.ChangedObjects)
+	// objects are sorted
+	assert.Equal(t, "changed-cm", d.ChangedObjects[0].Ref.Name)
+}
+
+func TestRenderValidateResult(t *testing.T) {
+	tmpl, err := Parse("validate", `{{ range .Errors }}{{ .Ref.Name }}: {{ .Message }}{{ end }}`)
+	assert.NoError(t, err)
+	s, err := RenderValidateResult(tmpl, &result.ValidateResult{
+		Errors: []result.DeploymentError{{Ref: k8s.ObjectRef{Name: "x"}, Message: "not ready"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "x: not ready", s)
+}
+
+func TestTemplateErrors(t *testing.T) {
+	_, err := Parse("my.tmpl", "line1\n{{ .Foo ")
+	assert.ErrorContains(t, err, "my.tmpl:2:")
+
+	tmpl, err := Parse("my.tmpl", "line1\nline2 {{ .Foo }}")
+	assert.NoError(t, err)
+	_, err = RenderCommandResult(tmpl, newTestCommandResult())
+	assert.ErrorContains(t, err, "my.tmpl:2:")
+	assert.ErrorContains(t, err, "can't evaluate field Foo")
+
+	_, err = Parse("my.tmpl", "{{ doesNotExist }}")
+	assert.ErrorContains(t, err, `function "doesNotExist" not defined`)
+}
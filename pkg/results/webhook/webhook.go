@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+type EventType string
+
+const (
+	EventTypeCommandResult  EventType = "commandResult"
+	EventTypeValidateResult EventType = "validateResult"
+)
+
+// Event is the payload posted to result webhooks. Id is the id of the contained result, which receivers can use to
+// deduplicate events.
+type Event struct {
+	Type           EventType                      `json:"type"`
+	Id             string                         `json:"id"`
+	CommandResult  *result.CompactedCommandResult `json:"commandResult,omitempty"`
+	ValidateResult *result.ValidateResult         `json:"validateResult,omitempty"`
+}
+
+// NewCommandResultEvent builds the event for the given command result. The result must already be obfuscated.
+func NewCommandResultEvent(cr *result.CommandResult) *Event {
+	return &Event{
+		Type:          EventTypeCommandResult,
+		Id:            cr.Id,
+		CommandResult: cr.ToCompacted(),
+	}
+}
+
+func NewValidateResultEvent(vr *result.ValidateResult) *Event {
+	return &Event{
+		Type:           EventTypeValidateResult,
+		Id:             vr.Id,
+		ValidateResult: vr,
+	}
+}
+
+// ParseHeader parses a header in the form 'Name: value'
+func ParseHeader(s string) (string, string, error) {
+	name, value, found := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return "", "", fmt.Errorf("invalid header, must be in the form 'Name: value'")
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+type Client struct {
+	Headers http.Header
+	// Retries is the number of retries performed when the webhook responds with a 5xx status
+	Retries int
+	// Backoff is the delay before the first retry. It is doubled for each following retry.
+	Backoff time.Duration
+
+	httpClient *http.Client
+}
+
+// NewClient creates a webhook client. The timeout is applied to each attempt, so that a dead endpoint can't block
+// the caller forever.
+func NewClient(headers http.Header, timeout time.Duration, retries int) *Client {
+	return &Client{
+		Headers:    headers,
+		Retries:    retries,
+		Backoff:    time.Second,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Post posts the event to the given webhook URL. Requests that fail with a 5xx status are retried with exponential
+// backoff. Returned errors never contain the webhook URL, as it might contain credentials.
+func (c *Client) Post(ctx context.Context, webhookURL string, e *Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	backoff := c.Backoff
+	for i := 0; ; i++ {
+		retry, err := c.post(ctx, webhookURL, b)
+		if err == nil || !retry || i >= c.Retries {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) post(ctx context.Context, webhookURL string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		// don't include the parse error, as it contains the URL
+		return false, fmt.Errorf("invalid webhook URL")
+	}
+	for n, v := range c.Headers {
+		req.Header[n] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return false, urlErr.Err
+		}
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode >= 500, fmt.Errorf("webhook failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return false, nil
+}
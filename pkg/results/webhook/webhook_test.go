@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestResult() *result.CommandResult {
+	return &result.CommandResult{
+		Id:      "result-1",
+		Target:  types.Target{Name: "prod"},
+		Command: result.CommandInfo{Command: "deploy"},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Kind: "ConfigMap", Name: "new", Namespace: "ns"}, New: true}},
+		},
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	n, v, err := ParseHeader("Authorization: Bearer a:b")
+	assert.NoError(t, err)
+	assert.Equal(t, "Authorization", n)
+	assert.Equal(t, "Bearer a:b", v)
+
+	_, _, err = ParseHeader("Authorization")
+	assert.Error(t, err)
+	_, _, err = ParseHeader(": value")
+	assert.Error(t, err)
+}
+
+func TestEvents(t *testing.T) {
+	e := NewCommandResultEvent(newTestResult())
+	b, err := json.Marshal(e)
+	assert.NoError(t, err)
+
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(b, &m))
+	assert.Equal(t, "commandResult", m["type"])
+	assert.Equal(t, "result-1", m["id"])
+	assert.Contains(t, m, "commandResult")
+	assert.NotContains(t, m, "validateResult")
+
+	e = NewValidateResultEvent(&result.ValidateResult{Id: "validate-1", Ready: true})
+	b, err = json.Marshal(e)
+	assert.NoError(t, err)
+	m = nil
+	assert.NoError(t, json.Unmarshal(b, &m))
+	assert.Equal(t, "validateResult", m["type"])
+	assert.Equal(t, "validate-1", m["id"])
+	assert.NotContains(t, m, "commandResult")
+}
+
+func TestPost(t *testing.T) {
+	var calls atomic.Int32
+	var received Event
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer s.Close()
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer token")
+	c := NewClient(headers, 5*time.Second, 3)
+	c.Backoff = time.Millisecond
+
+	err := c.Post(context.Background(), s.URL, NewCommandResultEvent(newTestResult()))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, EventTypeCommandResult, received.Type)
+	assert.Equal(t, "result-1", received.Id)
+	assert.Len(t, received.CommandResult.ToNonCompacted().Objects, 1)
+
+	// retries exhausted
+	calls.Store(0)
+	c.Retries = 1
+	err = c.Post(context.Background(), s.URL, NewCommandResultEvent(newTestResult()))
+	assert.ErrorContains(t, err, "status 503")
+	assert.Equal(t, int32(2), calls.Load())
+
+	// 4xx is not retried
+	calls.Store(0)
+	c = NewClient(nil, 5*time.Second, 3)
+	err = c.Post(context.Background(), s.URL, NewCommandResultEvent(newTestResult()))
+	assert.ErrorContains(t, err, "status 401")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestPostTimeout(t *testing.T) {
+	done := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer s.Close()
+	defer close(done)
+
+	c := NewClient(nil, 100*time.Millisecond, 3)
+	err := c.Post(context.Background(), s.URL+"/secret-token", NewCommandResultEvent(newTestResult()))
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+}
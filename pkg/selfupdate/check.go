@@ -0,0 +1,71 @@
+package selfupdate
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/kluctl/kluctl/lib/yaml"
+)
+
+// CheckInterval is the minimum time between two update checks that actually query the releases API
+const CheckInterval = 24 * time.Hour
+
+type versionCheckState struct {
+	LastVersionCheck time.Time `json:"lastVersionCheck"`
+	LatestVersion    string    `json:"latestVersion,omitempty"`
+}
+
+type CheckResult struct {
+	CurrentVersion *semver.Version
+	LatestVersion  *semver.Version
+}
+
+func (r *CheckResult) UpdateAvailable() bool {
+	return r.CurrentVersion.LessThan(r.LatestVersion)
+}
+
+// CheckForUpdate compares currentVersion with the latest release. The latest version is cached in cacheDir, so that
+// the releases API is queried at most once per CheckInterval. Passing force bypasses the cache. If the last check
+// failed less than CheckInterval ago, nil is returned without querying the releases API again.
+func (c *Client) CheckForUpdate(ctx context.Context, cacheDir string, currentVersion string, force bool) (*CheckResult, error) {
+	cur, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	statePath := filepath.Join(cacheDir, "version_check.yaml")
+	var state versionCheckState
+	if !force {
+		err = yaml.ReadYamlFile(statePath, &state)
+		if err == nil && time.Since(state.LastVersionCheck) < CheckInterval {
+			if state.LatestVersion == "" {
+				return nil, nil
+			}
+			latest, err := semver.NewVersion(state.LatestVersion)
+			if err == nil {
+				return &CheckResult{CurrentVersion: cur, LatestVersion: latest}, nil
+			}
+		}
+	}
+
+	// the check time is recorded even when the check fails, so that offline environments don't query the API on
+	// every invocation
+	state = versionCheckState{LastVersionCheck: time.Now()}
+	defer func() {
+		_ = yaml.WriteYamlFile(statePath, &state)
+	}()
+
+	r, err := c.GetLatestRelease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := r.Version()
+	if err != nil {
+		return nil, err
+	}
+	state.LatestVersion = latest.String()
+
+	return &CheckResult{CurrentVersion: cur, LatestVersion: latest}, nil
+}
@@ -0,0 +1,95 @@
+package selfupdate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const DefaultLatestReleaseURL = "https://api.github.com/repos/kluctl/kluctl/releases/latest"
+
+type Asset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+type Release struct {
+	TagName string  `json:"tag_name"`
+	Assets  []Asset `json:"assets"`
+}
+
+func (r *Release) Version() (*semver.Version, error) {
+	v, err := semver.NewVersion(strings.TrimPrefix(r.TagName, "v"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse release version '%s': %w", r.TagName, err)
+	}
+	return v, nil
+}
+
+func (r *Release) FindAsset(name string) *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+type Client struct {
+	latestReleaseURL string
+	signingKey       string
+	httpClient       *http.Client
+}
+
+// NewClient creates a client for the GitHub releases API. Proxies are honored via the usual HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY environment variables.
+func NewClient(latestReleaseURL string) *Client {
+	if latestReleaseURL == "" {
+		latestReleaseURL = DefaultLatestReleaseURL
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	return &Client{
+		latestReleaseURL: latestReleaseURL,
+		signingKey:       releaseSigningKey,
+		httpClient:       &http.Client{Transport: transport, Timeout: 5 * time.Minute},
+	}
+}
+
+func (c *Client) get(ctx context.Context, u string, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s failed with status %d", u, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (c *Client) GetLatestRelease(ctx context.Context) (*Release, error) {
+	b, err := c.get(ctx, c.latestReleaseURL, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	var r Release
+	err = json.Unmarshal(b, &r)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,223 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestArchive(t *testing.T, content string) []byte {
+	buf := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "kluctl", Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+type fakeReleases struct {
+	archive    []byte
+	checksum   string
+	signingKey *ecdsa.PrivateKey
+	apiCalls   atomic.Int32
+	failApi    bool
+	serverURL  string
+}
+
+func (f *fakeReleases) checksums() []byte {
+	return []byte(fmt.Sprintf("%s  kluctl_v2.100.0_linux_amd64.tar.gz\n%s  kluctl_v2.100.0_darwin_arm64.tar.gz\n", f.checksum, f.checksum))
+}
+
+func (f *fakeReleases) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/latest":
+		f.apiCalls.Add(1)
+		if f.failApi {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Release{
+			TagName: "v2.100.0",
+			Assets: []Asset{
+				{Name: "kluctl_v2.100.0_linux_amd64.tar.gz", BrowserDownloadURL: f.serverURL + "/archive"},
+				{Name: "kluctl_v2.100.0_checksums.txt", BrowserDownloadURL: f.serverURL + "/checksums"},
+				{Name: "kluctl_v2.100.0_checksums.txt.sig", BrowserDownloadURL: f.serverURL + "/checksums.sig"},
+			},
+		})
+	case "/archive":
+		_, _ = w.Write(f.archive)
+	case "/checksums":
+		_, _ = w.Write(f.checksums())
+	case "/checksums.sig":
+		h := sha256.Sum256(f.checksums())
+		sig, _ := ecdsa.SignASN1(rand.Reader, f.signingKey, h[:])
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(sig)))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func encodeTestSigningKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	assert.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func newFakeReleases(t *testing.T) (*fakeReleases, *Client) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	f := &fakeReleases{archive: buildTestArchive(t, "new-binary"), signingKey: key}
+	h := sha256.Sum256(f.archive)
+	f.checksum = hex.EncodeToString(h[:])
+	s := httptest.NewServer(f)
+	t.Cleanup(s.Close)
+	f.serverURL = s.URL
+	c := NewClient(s.URL + "/latest")
+	c.signingKey = encodeTestSigningKey(t, key)
+	return f, c
+}
+
+func TestCheckForUpdate(t *testing.T) {
+	f, c := newFakeReleases(t)
+	cacheDir := t.TempDir()
+
+	r, err := c.CheckForUpdate(context.Background(), cacheDir, "2.99.0", false)
+	assert.NoError(t, err)
+	assert.True(t, r.UpdateAvailable())
+	assert.Equal(t, "2.100.0", r.LatestVersion.String())
+	assert.Equal(t, int32(1), f.apiCalls.Load())
+
+	// cached
+	r, err = c.CheckForUpdate(context.Background(), cacheDir, "2.100.0", false)
+	assert.NoError(t, err)
+	assert.False(t, r.UpdateAvailable())
+	assert.Equal(t, int32(1), f.apiCalls.Load())
+
+	// forced
+	_, err = c.CheckForUpdate(context.Background(), cacheDir, "2.100.0", true)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), f.apiCalls.Load())
+
+	// expired
+	old := time.Now().Add(-CheckInterval - time.Minute)
+	assert.NoError(t, os.WriteFile(filepath.Join(cacheDir, "version_check.yaml"), []byte(fmt.Sprintf("lastVersionCheck: %s\nlatestVersion: 2.100.0\n", old.Format(time.RFC3339))), 0o600))
+	_, err = c.CheckForUpdate(context.Background(), cacheDir, "2.100.0", false)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), f.apiCalls.Load())
+}
+
+func TestCheckForUpdateFailed(t *testing.T) {
+	f, c := newFakeReleases(t)
+	f.failApi = true
+	cacheDir := t.TempDir()
+
+	_, err := c.CheckForUpdate(context.Background(), cacheDir, "2.99.0", false)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), f.apiCalls.Load())
+
+	// the failed check is throttled as well
+	r, err := c.CheckForUpdate(context.Background(), cacheDir, "2.99.0", false)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+	assert.Equal(t, int32(1), f.apiCalls.Load())
+
+	// expired
+	old := time.Now().Add(-CheckInterval - time.Minute)
+	assert.NoError(t, os.WriteFile(filepath.Join(cacheDir, "version_check.yaml"), []byte(fmt.Sprintf("lastVersionCheck: %s\n", old.Format(time.RFC3339))), 0o600))
+	f.failApi = false
+	r, err = c.CheckForUpdate(context.Background(), cacheDir, "2.99.0", false)
+	assert.NoError(t, err)
+	assert.True(t, r.UpdateAvailable())
+	assert.Equal(t, int32(2), f.apiCalls.Load())
+}
+
+func TestUpdate(t *testing.T) {
+	f, c := newFakeReleases(t)
+	exePath := filepath.Join(t.TempDir(), "kluctl")
+	assert.NoError(t, os.WriteFile(exePath, []byte("old-binary"), 0o755))
+
+	r, err := c.GetLatestRelease(context.Background())
+	assert.NoError(t, err)
+
+	err = c.Update(context.Background(), r, exePath, "windows", "amd64")
+	assert.ErrorContains(t, err, "has no archive for windows/amd64")
+
+	err = c.Update(context.Background(), r, exePath, "linux", "amd64")
+	assert.NoError(t, err)
+	b, _ := os.ReadFile(exePath)
+	assert.Equal(t, "new-binary", string(b))
+	st, _ := os.Stat(exePath)
+	assert.Equal(t, os.FileMode(0o755), st.Mode().Perm())
+
+	// the executable must stay untouched on checksum mismatches
+	assert.NoError(t, os.WriteFile(exePath, []byte("old-binary"), 0o755))
+	f.checksum = "0000"
+	err = c.Update(context.Background(), r, exePath, "linux", "amd64")
+	assert.ErrorContains(t, err, "checksum mismatch")
+	b, _ = os.ReadFile(exePath)
+	assert.Equal(t, "old-binary", string(b))
+
+	entries, _ := os.ReadDir(filepath.Dir(exePath))
+	assert.Len(t, entries, 1)
+}
+
+func TestUpdateVerifiesChecksumsSignature(t *testing.T) {
+	_, c := newFakeReleases(t)
+	exePath := filepath.Join(t.TempDir(), "kluctl")
+	assert.NoError(t, os.WriteFile(exePath, []byte("old-binary"), 0o755))
+
+	r, err := c.GetLatestRelease(context.Background())
+	assert.NoError(t, err)
+
+	// checksums signed by another key, e.g. when the release was tampered with
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	c.signingKey = encodeTestSigningKey(t, otherKey)
+	err = c.Update(context.Background(), r, exePath, "linux", "amd64")
+	assert.ErrorContains(t, err, "invalid signature of checksums file")
+
+	c.signingKey = ""
+	err = c.Update(context.Background(), r, exePath, "linux", "amd64")
+	assert.ErrorContains(t, err, "built without a release signing key")
+
+	b, _ := os.ReadFile(exePath)
+	assert.Equal(t, "old-binary", string(b))
+}
+
+func TestCheckInstallLocation(t *testing.T) {
+	assert.ErrorContains(t, CheckInstallLocation("/opt/homebrew/Cellar/kluctl/2.0.0/bin/kluctl"), "brew upgrade kluctl")
+	assert.ErrorContains(t, CheckInstallLocation("/nix/store/abc-kluctl/bin/kluctl"), "Nix")
+	assert.NoError(t, CheckInstallLocation(filepath.Join(t.TempDir(), "kluctl")))
+}
+
+func TestArchiveName(t *testing.T) {
+	v := semver.MustParse("2.1.0")
+	assert.Equal(t, "kluctl_v2.1.0_linux_arm64.tar.gz", ArchiveName(v, "linux", "arm64"))
+	assert.Equal(t, "kluctl_v2.1.0_windows_amd64.zip", ArchiveName(v, "windows", "amd64"))
+	assert.Equal(t, "kluctl_v2.1.0_checksums.txt", ChecksumsName(v))
+	assert.Equal(t, "kluctl_v2.1.0_checksums.txt.sig", ChecksumsSignatureName(v))
+}
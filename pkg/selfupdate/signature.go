@@ -0,0 +1,64 @@
+package selfupdate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// releaseSigningKey is the base64 encoded PEM public key (e.g. a cosign.pub) that the checksums files of releases are
+// signed with. It is injected at build time via ldflags, so that the key does not have to be trusted on download.
+var releaseSigningKey = ""
+
+// ChecksumsSignatureName returns the name of the signature of the checksums file of the given release, as written by
+// 'cosign sign-blob'
+func ChecksumsSignatureName(version *semver.Version) string {
+	return ChecksumsName(version) + ".sig"
+}
+
+func parseReleaseSigningKey(s string) (crypto.PublicKey, error) {
+	if s == "" {
+		return nil, fmt.Errorf("this kluctl binary was built without a release signing key, so downloaded releases can't be verified. Please download the latest release manually from %s", releasesPage)
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode release signing key: %w", err)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("release signing key is not a PEM encoded public key")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// verifyChecksumsSignature verifies the base64 encoded signature of the checksums file, as written by
+// 'cosign sign-blob --key'
+func verifyChecksumsSignature(key crypto.PublicKey, checksums []byte, signature []byte) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("failed to decode signature of checksums file: %w", err)
+	}
+
+	ok := false
+	switch key2 := key.(type) {
+	case *ecdsa.PublicKey:
+		h := sha256.Sum256(checksums)
+		ok = ecdsa.VerifyASN1(key2, h[:], sig)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key2, checksums, sig)
+	default:
+		return fmt.Errorf("unsupported release signing key type %T", key)
+	}
+	if !ok {
+		return fmt.Errorf("invalid signature of checksums file")
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const releasesPage = "https://github.com/kluctl/kluctl/releases"
+
+// ArchiveName returns the name of the release archive for the given platform, as produced by goreleaser
+func ArchiveName(version *semver.Version, goos string, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("kluctl_v%s_%s_%s.%s", version.String(), goos, goarch, ext)
+}
+
+// ChecksumsName returns the name of the checksums file of the given release
+func ChecksumsName(version *semver.Version) string {
+	return fmt.Sprintf("kluctl_v%s_checksums.txt", version.String())
+}
+
+// CheckInstallLocation returns an error with instructions on how to update if the executable at exePath was installed
+// by a package manager or can not be replaced by the current user
+func CheckInstallLocation(exePath string) error {
+	p := filepath.ToSlash(exePath)
+	if strings.Contains(p, "/Cellar/") || strings.Contains(p, "/homebrew/") || strings.Contains(p, "/linuxbrew/") {
+		return fmt.Errorf("kluctl was installed via Homebrew, please update it via 'brew upgrade kluctl'")
+	}
+	if strings.HasPrefix(p, "/nix/store/") {
+		return fmt.Errorf("kluctl was installed via Nix, please update it via your Nix configuration")
+	}
+
+	dir := filepath.Dir(exePath)
+	f, err := os.CreateTemp(dir, ".kluctl-update-check-")
+	if err != nil {
+		return fmt.Errorf("the install location %s is not writable (%w), please re-run with sufficient permissions or download the latest release manually from %s", dir, err, releasesPage)
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return nil
+}
+
+func findChecksum(checksums []byte, name string) (string, error) {
+	s := bufio.NewScanner(bytes.NewReader(checksums))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 2 && fields[1] == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum found for %s", name)
+}
+
+func extractBinary(archive []byte, goos string) ([]byte, error) {
+	if goos == "windows" {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if f.Name != "kluctl.exe" {
+				continue
+			}
+			r, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return io.ReadAll(r)
+		}
+		return nil, fmt.Errorf("kluctl.exe not found in release archive")
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag == tar.TypeReg && h.Name == "kluctl" {
+			return io.ReadAll(tr)
+		}
+	}
+	return nil, fmt.Errorf("kluctl binary not found in release archive")
+}
+
+// replaceExecutable atomically replaces the executable at exePath with bin. The new binary is written next to the
+// old one and then renamed, so that the executable is never left in a partially written state.
+func replaceExecutable(exePath string, bin []byte, goos string) error {
+	st, err := os.Stat(exePath)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exePath), ".kluctl-update-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(bin)
+	if err == nil {
+		err = tmp.Chmod(st.Mode().Perm() | 0o111)
+	}
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+
+	if goos == "windows" {
+		// running executables can't be overwritten on Windows, but they can be renamed
+		oldPath := exePath + ".old"
+		_ = os.Remove(oldPath)
+		err = os.Rename(exePath, oldPath)
+		if err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), exePath)
+}
+
+// Update downloads the release archive for the given platform, verifies it against the checksums file of the release
+// and then replaces the executable at exePath with the binary found in the archive. The checksums file itself is
+// verified against its signature and the release signing key built into kluctl, as the checksums file is downloaded
+// from the same location as the archive.
+func (c *Client) Update(ctx context.Context, release *Release, exePath string, goos string, goarch string) error {
+	signingKey, err := parseReleaseSigningKey(c.signingKey)
+	if err != nil {
+		return err
+	}
+	v, err := release.Version()
+	if err != nil {
+		return err
+	}
+
+	archiveName := ArchiveName(v, goos, goarch)
+	archiveAsset := release.FindAsset(archiveName)
+	if archiveAsset == nil {
+		return fmt.Errorf("release %s has no archive for %s/%s", release.TagName, goos, goarch)
+	}
+	checksumsAsset := release.FindAsset(ChecksumsName(v))
+	if checksumsAsset == nil {
+		return fmt.Errorf("release %s has no checksums file", release.TagName)
+	}
+
+	signatureAsset := release.FindAsset(ChecksumsSignatureName(v))
+	if signatureAsset == nil {
+		return fmt.Errorf("release %s has no signature for the checksums file", release.TagName)
+	}
+
+	checksums, err := c.get(ctx, checksumsAsset.BrowserDownloadURL, time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to download checksums: %w", err)
+	}
+	signature, err := c.get(ctx, signatureAsset.BrowserDownloadURL, time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to download signature of checksums: %w", err)
+	}
+	err = verifyChecksumsSignature(signingKey, checksums, signature)
+	if err != nil {
+		return err
+	}
+	expected, err := findChecksum(checksums, archiveName)
+	if err != nil {
+		return err
+	}
+
+	archive, err := c.get(ctx, archiveAsset.BrowserDownloadURL, 5*time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", archiveName, err)
+	}
+	h := sha256.Sum256(archive)
+	if actual := hex.EncodeToString(h[:]); actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", archiveName, expected, actual)
+	}
+
+	bin, err := extractBinary(archive, goos)
+	if err != nil {
+		return err
+	}
+	return replaceExecutable(exePath, bin, goos)
+}
@@ -92,7 +92,7 @@ type Decryptor struct {
 	// checkSopsMac instructs the decryptor to perform the SOPS data integrity
 	// check using the MAC. Not enabled by default, as arbitrary data gets
 	// injected into most resources, causing the integrity check to fail.
-	// Mostly kept around for feature completeness and documentation purposes.
+	// Enabled via SetCheckSopsMac for whole files that are decrypted as-is, e.g. args files.
 	checkSopsMac bool
 
 	// keyServices are the SOPS keyservice.KeyServiceClient's available to the
@@ -109,6 +109,11 @@ func NewDecryptor(root string, maxFileSize int64) *Decryptor {
 	}
 }
 
+// SetCheckSopsMac enables or disables the SOPS data integrity check
+func (d *Decryptor) SetCheckSopsMac(check bool) {
+	d.checkSopsMac = check
+}
+
 func (d *Decryptor) AddLocalKeyService() {
 	d.AddKeyServiceClient(keyservice.NewLocalClient())
 }
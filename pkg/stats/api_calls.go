@@ -0,0 +1,80 @@
+package stats
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// WrapRestConfig returns a copy of config which counts all requests in the Collector of the context. The config is
+// returned unmodified if no Collector is installed.
+func WrapRestConfig(ctx context.Context, config *rest.Config) *rest.Config {
+	c := FromContext(ctx)
+	if c == nil {
+		return config
+	}
+	config = rest.CopyConfig(config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &countingRoundTripper{rt: rt, c: c}
+	})
+	return config
+}
+
+type countingRoundTripper struct {
+	rt http.RoundTripper
+	c  *Collector
+}
+
+func (r *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.c.RecordApiCall(requestVerb(req))
+	return r.rt.RoundTrip(req)
+}
+
+// requestVerb derives the Kubernetes API verb from the request. Discovery requests are counted as get.
+func requestVerb(req *http.Request) string {
+	isList := isCollectionPath(req.URL.Path)
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		w := req.URL.Query().Get("watch")
+		if w == "true" || w == "1" {
+			return "watch"
+		}
+		if isList {
+			return "list"
+		}
+		return "get"
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		if isList {
+			return "deletecollection"
+		}
+		return "delete"
+	default:
+		return strings.ToLower(req.Method)
+	}
+}
+
+// isCollectionPath returns true if the path points to a collection of resources instead of a single resource, e.g.
+// /api/v1/namespaces/default/configmaps vs. /api/v1/namespaces/default/configmaps/cm
+func isCollectionPath(p string) bool {
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		parts = parts[3:]
+	default:
+		return false
+	}
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	return len(parts) == 1
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package stats
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// peakMemory returns the maximum resident set size of the process
+func peakMemory() int64 {
+	var ru unix.Rusage
+	err := unix.Getrusage(unix.RUSAGE_SELF, &ru)
+	if err != nil {
+		return 0
+	}
+	// ru_maxrss is reported in bytes on darwin and in kilobytes everywhere else
+	if runtime.GOOS == "darwin" {
+		return int64(ru.Maxrss)
+	}
+	return int64(ru.Maxrss) * 1024
+}
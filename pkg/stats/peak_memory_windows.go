@@ -0,0 +1,15 @@
+//go:build windows
+
+package stats
+
+import (
+	"runtime"
+)
+
+// peakMemory returns the memory obtained from the OS by the Go runtime, which is the closest approximation of the
+// peak memory usage that is available without additional dependencies
+func peakMemory() int64 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return int64(ms.Sys)
+}
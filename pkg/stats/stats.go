@@ -0,0 +1,141 @@
+// Package stats collects performance metrics of a single command invocation, e.g. the number of Kubernetes API calls
+// or the number of fetched bytes. Metrics are only collected when a Collector is installed into the context, which
+// is done when --stats is passed. All recording functions are no-ops otherwise.
+package stats
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Collector struct {
+	startTime time.Time
+
+	mutex            sync.Mutex
+	apiCalls         map[string]int
+	fetchedBytes     map[string]int64
+	caches           map[string]result.CacheStats
+	templateCounters []func() int64
+	timings          *result.CommandTimings
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		startTime:    time.Now(),
+		apiCalls:     map[string]int{},
+		fetchedBytes: map[string]int64{},
+		caches:       map[string]result.CacheStats{},
+	}
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, c *Collector) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+func FromContext(ctx context.Context) *Collector {
+	v, _ := ctx.Value(contextKey{}).(*Collector)
+	return v
+}
+
+func (c *Collector) RecordApiCall(verb string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.apiCalls[verb]++
+}
+
+func RecordFetchedBytes(ctx context.Context, sourceType string, n int64) {
+	c := FromContext(ctx)
+	if c == nil || n <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.fetchedBytes[sourceType] += n
+}
+
+// RecordCacheLookup records a hit or miss of the cache for the given source type
+func RecordCacheLookup(ctx context.Context, sourceType string, hit bool) {
+	c := FromContext(ctx)
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	s := c.caches[sourceType]
+	if hit {
+		s.Hits++
+	} else {
+		s.Misses++
+	}
+	c.caches[sourceType] = s
+}
+
+// AddTemplateCounter registers a function that returns the number of rendered templates, e.g. of a Jinja2 renderer.
+// All registered counters are summed up.
+func AddTemplateCounter(ctx context.Context, f func() int64) {
+	c := FromContext(ctx)
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.templateCounters = append(c.templateCounters, f)
+}
+
+// SetTimings remembers the timings of the command result, so that they can be printed together with the stats
+func (c *Collector) SetTimings(t *result.CommandTimings) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.timings = t.DeepCopy()
+}
+
+func (c *Collector) GetTimings() *result.CommandTimings {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.timings
+}
+
+// Build returns a snapshot of all metrics collected so far
+func (c *Collector) Build() *result.CommandStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	s := &result.CommandStats{
+		WallTime:   metav1.Duration{Duration: time.Since(c.startTime)},
+		PeakMemory: peakMemory(),
+	}
+	if len(c.apiCalls) != 0 {
+		s.ApiCalls = map[string]int{}
+		for k, v := range c.apiCalls {
+			s.ApiCalls[k] = v
+		}
+	}
+	if len(c.fetchedBytes) != 0 {
+		s.FetchedBytes = map[string]int64{}
+		for k, v := range c.fetchedBytes {
+			s.FetchedBytes[k] = v
+		}
+	}
+	if len(c.caches) != 0 {
+		s.Caches = map[string]result.CacheStats{}
+		for k, v := range c.caches {
+			s.Caches[k] = v
+		}
+	}
+	for _, f := range c.templateCounters {
+		s.RenderedTemplates += f()
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+)
+
+func TestNoCollector(t *testing.T) {
+	ctx := context.Background()
+
+	// must not panic
+	RecordFetchedBytes(ctx, "git", 10)
+	RecordCacheLookup(ctx, "git", true)
+	AddTemplateCounter(ctx, func() int64 { return 1 })
+
+	config := &rest.Config{Host: "https://example.com"}
+	assert.Same(t, config, WrapRestConfig(ctx, config))
+}
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	ctx := NewContext(context.Background(), c)
+
+	RecordFetchedBytes(ctx, "git", 10)
+	RecordFetchedBytes(ctx, "git", 5)
+	RecordFetchedBytes(ctx, "helm", 0)
+	RecordCacheLookup(ctx, "git", true)
+	RecordCacheLookup(ctx, "git", true)
+	RecordCacheLookup(ctx, "git", false)
+	AddTemplateCounter(ctx, func() int64 { return 3 })
+	AddTemplateCounter(ctx, func() int64 { return 4 })
+	c.RecordApiCall("get")
+
+	s := c.Build()
+	assert.Equal(t, map[string]int64{"git": 15}, s.FetchedBytes)
+	assert.Equal(t, map[string]result.CacheStats{"git": {Hits: 2, Misses: 1}}, s.Caches)
+	assert.InDelta(t, 2.0/3.0, s.Caches["git"].HitRate(), 0.001)
+	assert.Equal(t, int64(7), s.RenderedTemplates)
+	assert.Equal(t, map[string]int{"get": 1}, s.ApiCalls)
+	assert.Greater(t, s.WallTime.Duration, int64(0))
+}
+
+func TestRequestVerb(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		verb   string
+	}{
+		{http.MethodGet, "/api/v1/namespaces/default/configmaps/cm", "get"},
+		{http.MethodGet, "/api/v1/namespaces/default/configmaps", "list"},
+		{http.MethodGet, "/api/v1/namespaces", "list"},
+		{http.MethodGet, "/api/v1/namespaces/default", "get"},
+		{http.MethodGet, "/apis/apps/v1/deployments", "list"},
+		{http.MethodGet, "/apis/apps/v1/namespaces/default/deployments/d/status", "get"},
+		{http.MethodGet, "/apis/apps/v1/namespaces/default/deployments?watch=true", "watch"},
+		{http.MethodGet, "/apis/apps/v1", "get"},
+		{http.MethodGet, "/api", "get"},
+		{http.MethodPost, "/api/v1/namespaces/default/configmaps", "create"},
+		{http.MethodPut, "/api/v1/namespaces/default/configmaps/cm", "update"},
+		{http.MethodPatch, "/api/v1/namespaces/default/configmaps/cm", "patch"},
+		{http.MethodDelete, "/api/v1/namespaces/default/configmaps/cm", "delete"},
+		{http.MethodDelete, "/api/v1/namespaces/default/configmaps", "deletecollection"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method+" "+tc.url, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			assert.Equal(t, tc.verb, requestVerb(req))
+		})
+	}
+}
+
+func TestWrapRestConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewCollector()
+	ctx := NewContext(context.Background(), c)
+	config := WrapRestConfig(ctx, &rest.Config{Host: server.URL})
+
+	client, err := rest.HTTPClientFor(config)
+	assert.NoError(t, err)
+	for _, p := range []string{"/api/v1/namespaces/default/configmaps", "/api/v1/namespaces/default/configmaps/cm"} {
+		resp, err := client.Get(server.URL + p)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	assert.Equal(t, map[string]int{"list": 1, "get": 1}, c.Build().ApiCalls)
+}
@@ -5,6 +5,8 @@ import (
 	yaml2 "github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/ohler55/ojg/jp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type DeploymentItemConfig struct {
@@ -12,6 +14,7 @@ type DeploymentItemConfig struct {
 	Include       *string                  `json:"include,omitempty"`
 	Git           *GitProject              `json:"git,omitempty"`
 	Oci           *OciProject              `json:"oci,omitempty"`
+	YamlBundle    *YamlBundleConfig        `json:"yamlBundle,omitempty"`
 	DeleteObjects []DeleteObjectItemConfig `json:"deleteObjects,omitempty"`
 
 	Tags    []string `json:"tags,omitempty"`
@@ -20,6 +23,7 @@ type DeploymentItemConfig struct {
 
 	WaitReadiness        bool                            `json:"waitReadiness,omitempty"`
 	WaitReadinessObjects []WaitReadinessObjectItemConfig `json:"waitReadinessObjects,omitempty"`
+	PostDeployWaits      []PostDeployWaitConfig          `json:"postDeployWaits,omitempty"`
 
 	Args     *uo.UnstructuredObject `json:"args,omitempty"`
 	PassVars bool                   `json:"passVars,omitempty"`
@@ -58,6 +62,9 @@ func ValidateDeploymentItemConfig(sl validator.StructLevel) {
 	if cnt > 1 {
 		sl.ReportError(s, "self", "self", "only one of path, include, git and oci can be set at the same time", "")
 	}
+	if s.YamlBundle != nil && s.Path == nil {
+		sl.ReportError(s, "yamlBundle", "YamlBundle", "yamlBundle can only be used together with path", "")
+	}
 	if s.Path == nil && s.WaitReadiness {
 		sl.ReportError(s, "waitReadiness", "WaitReadiness", "only kustomize deployments are allowed to have waitReadiness set", "")
 	}
@@ -98,6 +105,44 @@ func ValidateWaitReadinessObjectItemConfig(sl validator.StructLevel) {
 	}
 }
 
+type PostDeployWaitSeverity string
+
+const (
+	PostDeployWaitSeverityError   PostDeployWaitSeverity = "error"
+	PostDeployWaitSeverityWarning PostDeployWaitSeverity = "warning"
+)
+
+type PostDeployWaitConfig struct {
+	ObjectRefItem
+
+	// Fields is a list of JSON paths that must resolve to non-empty values
+	Fields []string `json:"fields,omitempty"`
+	// Conditions is a list of condition types that must have the status True
+	Conditions []string                        `json:"conditions,omitempty"`
+	DnsLookups []PostDeployWaitDnsLookupConfig `json:"dnsLookups,omitempty"`
+
+	Timeout  *metav1.Duration       `json:"timeout,omitempty"`
+	Severity PostDeployWaitSeverity `json:"severity,omitempty" validate:"omitempty,oneof=error warning"`
+}
+
+func ValidatePostDeployWaitConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(PostDeployWaitConfig)
+	if s.Group == nil && s.Kind == nil {
+		sl.ReportError(s, "self", "self", "at least one of group or kind must be set", "")
+	}
+	if len(s.Fields)+len(s.Conditions)+len(s.DnsLookups) == 0 {
+		sl.ReportError(s, "self", "self", "at least one of fields, conditions or dnsLookups must be set", "")
+	}
+}
+
+type PostDeployWaitDnsLookupConfig struct {
+	// HostsFieldPath is a JSON path pointing to the host names that must resolve
+	HostsFieldPath string `json:"hostsFieldPath" validate:"required"`
+	// AddressesFieldPath is an optional JSON path pointing to the addresses that at least one resolved address must
+	// match
+	AddressesFieldPath *string `json:"addressesFieldPath,omitempty"`
+}
+
 type SingleStringOrList []string
 
 func (s *SingleStringOrList) UnmarshalJSON(b []byte) error {
@@ -132,6 +177,38 @@ func ValidateIgnoreForDiffItemConfig(sl validator.StructLevel) {
 	}
 }
 
+// ObfuscationRule specifies fields of matching objects that are obfuscated in command results, in addition to the
+// data of Secrets which is always obfuscated. Field paths may contain wildcards, e.g. `spec.template.*.password`.
+type ObfuscationRule struct {
+	Group     *string            `json:"group,omitempty"`
+	Kind      *string            `json:"kind,omitempty"`
+	FieldPath SingleStringOrList `json:"fieldPath"`
+}
+
+func ValidateObfuscationRule(sl validator.StructLevel) {
+	s := sl.Current().Interface().(ObfuscationRule)
+	if s.Group == nil && s.Kind == nil {
+		sl.ReportError(s, "self", "self", "at least one of group or kind must be set", "")
+	}
+	if len(s.FieldPath) == 0 {
+		sl.ReportError(s, "fieldPath", "FieldPath", "at least one fieldPath must be set", "")
+	}
+	for _, fp := range s.FieldPath {
+		if _, err := jp.ParseString(fp); err != nil {
+			sl.ReportError(s, "fieldPath", "FieldPath", "invalid fieldPath", "")
+		}
+	}
+}
+
+// ListMergeKeysConfig specifies the keys used to match elements of a list of maps when diffing. Lists with merge keys
+// are compared element by element instead of index by index, so that pure reorders do not produce changes.
+type ListMergeKeysConfig struct {
+	Group     *string  `json:"group,omitempty"`
+	Kind      *string  `json:"kind,omitempty"`
+	FieldPath string   `json:"fieldPath" validate:"required"`
+	Keys      []string `json:"keys" validate:"required,min=1"`
+}
+
 type ConflictResolutionAction string
 
 const (
@@ -157,6 +234,19 @@ func ValidateConflictResolutionConfig(sl validator.StructLevel) {
 	}
 }
 
+type ObjectOrderConfig struct {
+	Group  *string `json:"group,omitempty"`
+	Kind   *string `json:"kind,omitempty"`
+	Weight int     `json:"weight"`
+}
+
+func ValidateObjectOrderConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(ObjectOrderConfig)
+	if s.Group == nil && s.Kind == nil {
+		sl.ReportError(s, "self", "self", "at least one of group or kind must be set", "")
+	}
+}
+
 type DeploymentProjectConfig struct {
 	Vars []VarsSource `json:"vars,omitempty"`
 
@@ -170,13 +260,24 @@ type DeploymentProjectConfig struct {
 	Tags              []string          `json:"tags,omitempty"`
 
 	IgnoreForDiff      []IgnoreForDiffItemConfig  `json:"ignoreForDiff,omitempty"`
+	ListMergeKeys      []ListMergeKeysConfig      `json:"listMergeKeys,omitempty"`
 	ConflictResolution []ConflictResolutionConfig `json:"conflictResolution,omitempty"`
+	ObjectOrder        []ObjectOrderConfig        `json:"objectOrder,omitempty"`
+
+	// Obfuscate specifies additional fields that are obfuscated in command results
+	Obfuscate []ObfuscationRule `json:"obfuscate,omitempty"`
+
+	// ArgoCDCompatibility enables mapping of Argo CD sync-wave and hook annotations to kluctl ordering and hooks
+	ArgoCDCompatibility *bool `json:"argoCDCompatibility,omitempty"`
 }
 
 func init() {
 	yaml2.Validator.RegisterStructValidation(ValidateDeploymentItemConfig, DeploymentItemConfig{})
 	yaml2.Validator.RegisterStructValidation(ValidateDeleteObjectItemConfig, DeleteObjectItemConfig{})
 	yaml2.Validator.RegisterStructValidation(ValidateWaitReadinessObjectItemConfig, WaitReadinessObjectItemConfig{})
+	yaml2.Validator.RegisterStructValidation(ValidatePostDeployWaitConfig, PostDeployWaitConfig{})
 	yaml2.Validator.RegisterStructValidation(ValidateIgnoreForDiffItemConfig, IgnoreForDiffItemConfig{})
 	yaml2.Validator.RegisterStructValidation(ValidateConflictResolutionConfig, ConflictResolutionConfig{})
+	yaml2.Validator.RegisterStructValidation(ValidateObjectOrderConfig, ObjectOrderConfig{})
+	yaml2.Validator.RegisterStructValidation(ValidateObfuscationRule, ObfuscationRule{})
 }
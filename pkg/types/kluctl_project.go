@@ -1,8 +1,15 @@
 package types
 
 import (
+	"github.com/go-playground/validator/v10"
+	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 )
 
 type ServiceAccountRef struct {
@@ -15,27 +22,464 @@ type AwsConfig struct {
 	ServiceAccount *ServiceAccountRef `json:"serviceAccount,omitempty"`
 }
 
+// ChangelogRule describes how changes matching the rule are described in the changelog output format
+type ChangelogRule struct {
+	Group *string `json:"group,omitempty"`
+	Kind  *string `json:"kind,omitempty"`
+	// ChangeType restricts the rule to one of the change types insert, update or delete
+	ChangeType *string `json:"changeType,omitempty" validate:"omitempty,oneof=insert update delete"`
+	// FieldPathRegex is matched against the JSON path of the change. Named groups are available in Message
+	FieldPathRegex string `json:"fieldPathRegex" validate:"required"`
+	// Message is the sentence that describes the change, e.g. "scaled ${object} from ${old} to ${new} replicas"
+	Message string `json:"message" validate:"required"`
+}
+
+func ValidateChangelogRule(sl validator.StructLevel) {
+	s := sl.Current().Interface().(ChangelogRule)
+	if _, err := regexp.Compile(s.FieldPathRegex); err != nil {
+		sl.ReportError(s, "fieldPathRegex", "FieldPathRegex", "invalid regex", "")
+	}
+}
+
+// PruneExcludeRule excludes matching objects from orphan detection, so that these are never pruned. All fields are
+// glob patterns, fields that are not set match all objects.
+type PruneExcludeRule struct {
+	// Group is matched against the API group of the object. An empty string matches the core API group.
+	Group     *string `json:"group,omitempty"`
+	Kind      *string `json:"kind,omitempty"`
+	Namespace *string `json:"namespace,omitempty"`
+	// Reason is shown in debug output when an object is excluded
+	Reason string `json:"reason,omitempty"`
+}
+
+func ValidatePruneExcludeRule(sl validator.StructLevel) {
+	s := sl.Current().Interface().(PruneExcludeRule)
+	if s.Group == nil && s.Kind == nil && s.Namespace == nil {
+		sl.ReportError(s, "self", "self", "at least one of group, kind or namespace must be set", "")
+	}
+	for _, x := range []struct {
+		name    string
+		pattern *string
+	}{{"group", s.Group}, {"kind", s.Kind}, {"namespace", s.Namespace}} {
+		if x.pattern == nil {
+			continue
+		}
+		if _, err := path.Match(*x.pattern, ""); err != nil {
+			sl.ReportError(s, x.name, x.name, "invalid glob pattern", "")
+		}
+	}
+}
+
+// Matches returns true if the given object coordinates match all fields of the rule
+func (r PruneExcludeRule) Matches(group string, kind string, namespace string) bool {
+	match := func(pattern *string, s string) bool {
+		if pattern == nil {
+			return true
+		}
+		m, _ := path.Match(*pattern, s)
+		return m
+	}
+	return match(r.Group, group) && match(r.Kind, kind) && match(r.Namespace, namespace)
+}
+
+func (r PruneExcludeRule) String() string {
+	var parts []string
+	if r.Group != nil {
+		parts = append(parts, "group="+*r.Group)
+	}
+	if r.Kind != nil {
+		parts = append(parts, "kind="+*r.Kind)
+	}
+	if r.Namespace != nil {
+		parts = append(parts, "namespace="+*r.Namespace)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ObjectPatchSelector selects the rendered objects that an ObjectPatch is applied to. Group, kind, namespace and name
+// are glob patterns. All fields that are set must match.
+type ObjectPatchSelector struct {
+	// Group is matched against the API group of the object. An empty string matches the core API group.
+	Group     *string           `json:"group,omitempty"`
+	Kind      *string           `json:"kind,omitempty"`
+	Namespace *string           `json:"namespace,omitempty"`
+	Name      *string           `json:"name,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Tags matches objects of deployment items that have at least one of the given tags
+	Tags []string `json:"tags,omitempty"`
+}
+
+func ValidateObjectPatchSelector(sl validator.StructLevel) {
+	s := sl.Current().Interface().(ObjectPatchSelector)
+	for _, x := range []struct {
+		name    string
+		pattern *string
+	}{{"group", s.Group}, {"kind", s.Kind}, {"namespace", s.Namespace}, {"name", s.Name}} {
+		if x.pattern == nil {
+			continue
+		}
+		if _, err := path.Match(*x.pattern, ""); err != nil {
+			sl.ReportError(s, x.name, x.name, "invalid glob pattern", "")
+		}
+	}
+}
+
+// Matches returns true if the given object and the tags of its deployment item match all fields of the selector
+func (s ObjectPatchSelector) Matches(o *uo.UnstructuredObject, tags []string) bool {
+	ref := o.GetK8sRef()
+	match := func(pattern *string, v string) bool {
+		if pattern == nil {
+			return true
+		}
+		m, _ := path.Match(*pattern, v)
+		return m
+	}
+	if !match(s.Group, ref.Group) || !match(s.Kind, ref.Kind) || !match(s.Namespace, ref.Namespace) || !match(s.Name, ref.Name) {
+		return false
+	}
+	labels := o.GetK8sLabels()
+	for k, v := range s.Labels {
+		if x, ok := labels[k]; !ok || x != v {
+			return false
+		}
+	}
+	if len(s.Tags) != 0 {
+		found := false
+		for _, t := range s.Tags {
+			if slices.Contains(tags, t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (s ObjectPatchSelector) String() string {
+	var parts []string
+	for _, x := range []struct {
+		name string
+		v    *string
+	}{{"group", s.Group}, {"kind", s.Kind}, {"namespace", s.Namespace}, {"name", s.Name}} {
+		if x.v != nil {
+			parts = append(parts, x.name+"="+*x.v)
+		}
+	}
+	var labelKeys []string
+	for k := range s.Labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		parts = append(parts, "label."+k+"="+s.Labels[k])
+	}
+	for _, t := range s.Tags {
+		parts = append(parts, "tag="+t)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ObjectPatch is applied to all rendered objects that match the selector, after rendering and before diffing and
+// applying. Exactly one of Patch and JsonPatch must be set.
+type ObjectPatch struct {
+	Selector ObjectPatchSelector `json:"selector"`
+	// Patch is a strategic merge patch. For kinds that are unknown to kluctl (e.g. custom resources), it is applied as
+	// JSON merge patch.
+	Patch *uo.UnstructuredObject `json:"patch,omitempty"`
+	// JsonPatch is a list of RFC 6902 JSON patch operations
+	JsonPatch []*uo.UnstructuredObject `json:"jsonPatch,omitempty"`
+}
+
+func ValidateObjectPatch(sl validator.StructLevel) {
+	s := sl.Current().Interface().(ObjectPatch)
+	if (s.Patch == nil) == (len(s.JsonPatch) == 0) {
+		sl.ReportError(s, "self", "self", "exactly one of patch or jsonPatch must be set", "")
+	}
+}
+
+type ChangelogConfig struct {
+	// Rules are tried before the built-in rules
+	Rules []ChangelogRule `json:"rules,omitempty"`
+}
+
+type OutputConfig struct {
+	Formats     []string         `json:"formats,omitempty"`
+	ShortOutput *bool            `json:"shortOutput,omitempty"`
+	NoObfuscate *bool            `json:"noObfuscate,omitempty"`
+	Changelog   *ChangelogConfig `json:"changelog,omitempty"`
+
+	// SecuritySensitiveKinds contains the kinds ('Kind' or 'group/Kind') whose changes are shown in the
+	// security-relevant changes section. Defaults to RBAC kinds, NetworkPolicies, admission policies, webhooks and CRDs.
+	SecuritySensitiveKinds []string `json:"securitySensitiveKinds,omitempty"`
+}
+
+func ValidateOutputConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(OutputConfig)
+	if s.NoObfuscate != nil && *s.NoObfuscate {
+		sl.ReportError(s, "noObfuscate", "NoObfuscate", "noObfuscate can only be disabled via the command line", "")
+	}
+}
+
+type ResultStoreType string
+
+const (
+	ResultStoreTypeCluster ResultStoreType = "cluster"
+	ResultStoreTypeS3      ResultStoreType = "s3"
+)
+
+type ClusterResultStoreConfig struct {
+	// Namespace to write command results to. Defaults to the namespace given via --command-result-namespace
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type S3ResultStoreConfig struct {
+	Bucket string `json:"bucket" validate:"required"`
+	// Prefix is prepended to the keys of all written command results
+	Prefix string `json:"prefix,omitempty"`
+	// The aws region
+	Region *string `json:"region,omitempty"`
+	// AWS credentials profile to use. The AWS_PROFILE environemnt variables will take precedence in case it is also set
+	Profile *string `json:"profile,omitempty"`
+}
+
+type ResultStoreConfig struct {
+	Type ResultStoreType `json:"type" validate:"oneof=cluster s3"`
+	// Name is used to identify the store in status output and warnings
+	Name string `json:"name,omitempty"`
+
+	Cluster *ClusterResultStoreConfig `json:"cluster,omitempty"`
+	S3      *S3ResultStoreConfig      `json:"s3,omitempty"`
+}
+
+func ValidateResultStoreConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(ResultStoreConfig)
+	if s.Type == ResultStoreTypeS3 && s.S3 == nil {
+		sl.ReportError(s, "s3", "S3", "s3 must be set for result stores of type s3", "")
+	}
+	if s.Type != ResultStoreTypeCluster && s.Cluster != nil {
+		sl.ReportError(s, "cluster", "Cluster", "cluster can only be set for result stores of type cluster", "")
+	}
+	if s.Type != ResultStoreTypeS3 && s.S3 != nil {
+		sl.ReportError(s, "s3", "S3", "s3 can only be set for result stores of type s3", "")
+	}
+}
+
+type ResultsConfig struct {
+	// Stores specifies all result stores that command results are written to. An empty list disables writing of
+	// command results.
+	Stores []ResultStoreConfig `json:"stores"`
+}
+
+// KubeconfigSource specifies where the kubeconfig of a target comes from. Exactly one source must be specified.
+type KubeconfigSource struct {
+	// File loads the kubeconfig from the given path
+	File *string `json:"file,omitempty"`
+	// InCluster uses the in-cluster config of the pod kluctl is running in
+	InCluster bool `json:"inCluster,omitempty"`
+	// Secret loads the kubeconfig from a Secret, e.g. a Secret stored in a management cluster
+	Secret *KubeconfigSecretSource `json:"secret,omitempty"`
+	// Exec runs a command that prints the kubeconfig to stdout
+	Exec *KubeconfigExecSource `json:"exec,omitempty"`
+}
+
+type KubeconfigSecretSource struct {
+	// Context specifies the kubeconfig context of the cluster that holds the Secret. Defaults to the current context.
+	Context   *string `json:"context,omitempty"`
+	Namespace string  `json:"namespace" validate:"required"`
+	Name      string  `json:"name" validate:"required"`
+	// Key specifies the key inside the Secret. Defaults to 'value' or 'value.yaml'.
+	Key string `json:"key,omitempty"`
+}
+
+type KubeconfigExecSource struct {
+	Command []string          `json:"command" validate:"required,min=1"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// SourceType returns the type of the configured source, or an empty string if none or more than one source is set
+func (s *KubeconfigSource) SourceType() string {
+	var types []string
+	if s.File != nil {
+		types = append(types, "file")
+	}
+	if s.InCluster {
+		types = append(types, "inCluster")
+	}
+	if s.Secret != nil {
+		types = append(types, "secret")
+	}
+	if s.Exec != nil {
+		types = append(types, "exec")
+	}
+	if len(types) != 1 {
+		return ""
+	}
+	return types[0]
+}
+
+func ValidateKubeconfigSource(sl validator.StructLevel) {
+	s := sl.Current().Interface().(KubeconfigSource)
+	if s.SourceType() == "" {
+		sl.ReportError(s, "self", "self", "exactly one of file, inCluster, secret or exec must be set", "")
+	}
+}
+
 type Target struct {
-	Name          string                 `json:"name"`
-	Context       *string                `json:"context,omitempty"`
-	Args          *uo.UnstructuredObject `json:"args,omitempty"`
-	Aws           *AwsConfig             `json:"aws,omitempty"`
-	Images        []FixedImage           `json:"images,omitempty"`
-	Discriminator string                 `json:"discriminator,omitempty"`
+	Name           string                 `json:"name"`
+	Context        *string                `json:"context,omitempty"`
+	KubeconfigFrom *KubeconfigSource      `json:"kubeconfigFrom,omitempty"`
+	Args           *uo.UnstructuredObject `json:"args,omitempty"`
+	Aws            *AwsConfig             `json:"aws,omitempty"`
+	Images         []FixedImage           `json:"images,omitempty"`
+	Discriminator  string                 `json:"discriminator,omitempty"`
+	Output         *OutputConfig          `json:"output,omitempty"`
+	Results        *ResultsConfig         `json:"results,omitempty"`
+
+	// ClusterId overrides the automatically determined cluster ID, which is used to differentiate command results
+	ClusterId string `json:"clusterId,omitempty"`
+
+	// NamespaceOverride moves all namespaced objects into the given namespace, e.g. for per pull request preview
+	// environments
+	NamespaceOverride string `json:"namespaceOverride,omitempty"`
+
+	// SafetyThreshold aborts deployments before anything is applied when too many objects would be changed or deleted
+	SafetyThreshold *SafetyThresholdConfig `json:"safetyThreshold,omitempty"`
+
+	// Notifications overrides the notifications configured on project level
+	Notifications *NotificationsConfig `json:"notifications,omitempty"`
+
+	// Confirmation controls how warnings found in the diff are handled before confirming a deployment
+	Confirmation *ConfirmationConfig `json:"confirmation,omitempty"`
+}
+
+// NotificationsConfig configures where notifications about command results are sent to
+type NotificationsConfig struct {
+	Slack *SlackNotificationConfig `json:"slack,omitempty"`
+	// ConfigMap enables notifications written as ConfigMaps into the target cluster
+	ConfigMap *ConfigMapNotificationConfig `json:"configMap,omitempty"`
+}
+
+type SlackNotificationConfig struct {
+	// WebhookUrlEnv is the name of the environment variable that contains the URL of the Slack incoming webhook.
+	// Webhook URLs are secrets, which is why they can't be specified in .kluctl.yaml directly.
+	WebhookUrlEnv string `json:"webhookUrlEnv" validate:"required"`
+	// WebuiUrl is the URL of the Kluctl Webui, used to link to the command result
+	WebuiUrl string `json:"webuiUrl,omitempty"`
+}
+
+type ConfigMapNotificationConfig struct {
+	// Namespace is the namespace the notification ConfigMaps are written to. Defaults to kluctl-results.
+	Namespace string `json:"namespace,omitempty"`
+	// Keep is the number of notifications to keep per project and target. Defaults to 10.
+	Keep *int `json:"keep,omitempty" validate:"omitempty,gte=1"`
+}
+
+// ConfirmationCategory is a category of warnings found in the diff that is performed before confirming a deployment
+type ConfirmationCategory string
+
+const (
+	ConfirmationCategoryOrphanObjects      ConfirmationCategory = "orphanObjects"
+	ConfirmationCategoryOwnershipConflicts ConfirmationCategory = "ownershipConflicts"
+	ConfirmationCategoryDeprecations       ConfirmationCategory = "deprecations"
+	// ConfirmationCategoryWarnings covers all warnings that don't fall into one of the other categories, e.g. warnings
+	// returned by the Kubernetes API server or admission policies
+	ConfirmationCategoryWarnings ConfirmationCategory = "warnings"
+)
+
+// AllConfirmationCategories returns all categories in the order they are presented to the user
+func AllConfirmationCategories() []ConfirmationCategory {
+	return []ConfirmationCategory{
+		ConfirmationCategoryOrphanObjects,
+		ConfirmationCategoryOwnershipConflicts,
+		ConfirmationCategoryDeprecations,
+		ConfirmationCategoryWarnings,
+	}
+}
+
+type ConfirmationConfig struct {
+	// Acknowledge lists the categories that must be explicitly acknowledged by typing "ack" before the confirmation
+	// prompt. Categories that are not listed are only displayed as part of the diff.
+	Acknowledge []ConfirmationCategory `json:"acknowledge,omitempty" validate:"dive,oneof=orphanObjects ownershipConflicts deprecations warnings"`
+	// AbortNonInteractive lists the categories that abort the command when the confirmation is skipped via --yes
+	AbortNonInteractive []ConfirmationCategory `json:"abortNonInteractive,omitempty" validate:"dive,oneof=orphanObjects ownershipConflicts deprecations warnings"`
+}
+
+type SafetyThresholdConfig struct {
+	// MaxChangedObjects is the maximum number of objects that may be created or changed
+	MaxChangedObjects *int `json:"maxChangedObjects,omitempty" validate:"omitempty,gte=0"`
+	// MaxDeletedObjects is the maximum number of objects that may be deleted while pruning
+	MaxDeletedObjects *int `json:"maxDeletedObjects,omitempty" validate:"omitempty,gte=0"`
 }
 
 type DeploymentArg struct {
 	Name    string                `json:"name" validate:"required"`
 	Default *apiextensionsv1.JSON `json:"default,omitempty"`
+
+	// Sensitive causes the value of the arg to be redacted when the command line is recorded in command results
+	Sensitive bool `json:"sensitive,omitempty"`
 }
 
+// DeprecationsConfig controls how the usage of deprecated project features is handled
+type DeprecationsConfig struct {
+	// Errors contains the ids of deprecated features that cause loading of the project to fail instead of only
+	// producing a warning. See 'kluctl lint --deprecations-only' for a list of used deprecated features.
+	Errors []string `json:"errors,omitempty"`
+}
+
+const (
+	DiscriminatorPolicyError  = "error"
+	DiscriminatorPolicyWarn   = "warn"
+	DiscriminatorPolicyIgnore = "ignore"
+)
+
 type KluctlProject struct {
-	Targets       []Target        `json:"targets,omitempty"`
-	Args          []DeploymentArg `json:"args,omitempty"`
-	Discriminator string          `json:"discriminator,omitempty"`
-	Aws           *AwsConfig      `json:"aws,omitempty"`
+	Targets []Target        `json:"targets,omitempty"`
+	Args    []DeploymentArg `json:"args,omitempty"`
+	// Vars contains values that can be referenced in target definitions via 'vars', e.g. '{{ vars.domain }}'
+	Vars          *uo.UnstructuredObject `json:"vars,omitempty"`
+	Discriminator string                 `json:"discriminator,omitempty"`
+	// DiscriminatorPolicy controls how invalid rendered discriminators are handled, see DiscriminatorPolicyXXX
+	DiscriminatorPolicy string        `json:"discriminatorPolicy,omitempty" validate:"omitempty,oneof=error warn ignore"`
+	Aws                 *AwsConfig    `json:"aws,omitempty"`
+	Output              *OutputConfig `json:"output,omitempty"`
+
+	// PruneExclude excludes matching objects from orphan detection and pruning
+	PruneExclude []PruneExcludeRule `json:"pruneExclude,omitempty"`
+
+	// Obfuscate specifies additional fields that are obfuscated in command results
+	Obfuscate []ObfuscationRule `json:"obfuscate,omitempty"`
+
+	// IgnoreUnservedApiVersions contains glob patterns of API versions (e.g. "monitoring.coreos.com/v1") or API
+	// versions with kinds (e.g. "monitoring.coreos.com/v1/ServiceMonitor") that are not reported when the cluster
+	// does not serve them
+	IgnoreUnservedApiVersions []string `json:"ignoreUnservedApiVersions,omitempty"`
+
+	// DeferredKinds contains glob patterns of group/kinds (e.g. "monitoring.coreos.com/ServiceMonitor") that are
+	// excluded from the missing kinds preflight check of deployments, e.g. because their CRDs are installed by hooks
+	DeferredKinds []string `json:"deferredKinds,omitempty"`
+
+	Deprecations *DeprecationsConfig `json:"deprecations,omitempty"`
+
+	// Patches are applied to all matching rendered objects
+	Patches []ObjectPatch `json:"patches,omitempty"`
+
+	// Notifications configures notifications about command results
+	Notifications *NotificationsConfig `json:"notifications,omitempty"`
 }
 
 type KluctlLibraryProject struct {
 	Args []DeploymentArg `json:"args,omitempty"`
 }
+
+func init() {
+	yaml.Validator.RegisterStructValidation(ValidateOutputConfig, OutputConfig{})
+	yaml.Validator.RegisterStructValidation(ValidateChangelogRule, ChangelogRule{})
+	yaml.Validator.RegisterStructValidation(ValidatePruneExcludeRule, PruneExcludeRule{})
+	yaml.Validator.RegisterStructValidation(ValidateObjectPatchSelector, ObjectPatchSelector{})
+	yaml.Validator.RegisterStructValidation(ValidateObjectPatch, ObjectPatch{})
+	yaml.Validator.RegisterStructValidation(ValidateResultStoreConfig, ResultStoreConfig{})
+	yaml.Validator.RegisterStructValidation(ValidateKubeconfigSource, KubeconfigSource{})
+}
@@ -0,0 +1,54 @@
+package types
+
+// LockFile is the content of the lock file written by 'kluctl lock write'. It contains one ProjectLock per target.
+type LockFile struct {
+	Targets map[string]ProjectLock `json:"targets,omitempty"`
+}
+
+// ProjectLock records all inputs of a target that were resolved from external sources while rendering it
+type ProjectLock struct {
+	Git         []GitLock        `json:"git,omitempty"`
+	Oci         []OciLock        `json:"oci,omitempty"`
+	HelmCharts  []HelmChartLock  `json:"helmCharts,omitempty"`
+	Images      []ImageLock      `json:"images,omitempty"`
+	VarsSources []VarsSourceLock `json:"varsSources,omitempty"`
+}
+
+type GitLock struct {
+	Url string `json:"url"`
+	// Ref is the requested ref, empty if the default branch was requested
+	Ref    string `json:"ref,omitempty"`
+	Commit string `json:"commit"`
+}
+
+type OciLock struct {
+	Url string `json:"url"`
+	// Ref is the requested tag and/or digest
+	Ref    string `json:"ref,omitempty"`
+	Digest string `json:"digest"`
+}
+
+type HelmChartLock struct {
+	Repo    string `json:"repo"`
+	Chart   string `json:"chart"`
+	Version string `json:"version"`
+	// Digest is the sha256 hash over the files of the pulled chart
+	Digest string `json:"digest"`
+}
+
+type ImageLock struct {
+	Image     string `json:"image"`
+	Object    string `json:"object"`
+	Container string `json:"container,omitempty"`
+	// ResultImage is the image returned by images.get_image(), empty if no fixed image matched
+	ResultImage string `json:"resultImage,omitempty"`
+}
+
+type VarsSourceLock struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+	// Hash is the sha256 hash of the loaded vars
+	Hash string `json:"hash"`
+	// Commit is the commit the vars were loaded from, only set for git vars sources
+	Commit string `json:"commit,omitempty"`
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
 )
 
 type Change struct {
@@ -25,6 +27,117 @@ type ChangedObject struct {
 type DeploymentError struct {
 	Ref     k8s.ObjectRef `json:"ref"`
 	Message string        `json:"message"`
+	// Causes contains the field level causes reported by the Kubernetes API server, if the error was a Status error
+	// with details
+	Causes []ErrorCause `json:"causes,omitempty"`
+
+	// Deprecation is only set for warnings about the usage of deprecated project features
+	Deprecation *DeprecationInfo `json:"deprecation,omitempty"`
+}
+
+// DeprecationInfo identifies a deprecated project feature, see the deprecations package
+type DeprecationInfo struct {
+	Id string `json:"id"`
+	// Replacement describes what to use instead, it is empty if there is no replacement
+	Replacement string `json:"replacement,omitempty"`
+	// RemovedIn is the kluctl version in which the feature stops working
+	RemovedIn string `json:"removedIn"`
+}
+
+// ErrorCause describes a single cause of a Kubernetes API error, e.g. a field that failed validation
+type ErrorCause struct {
+	Field   string `json:"field,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type RerunJobStatus string
+
+const (
+	RerunJobStatusPending   RerunJobStatus = "Pending"
+	RerunJobStatusStarted   RerunJobStatus = "Started"
+	RerunJobStatusCompleted RerunJobStatus = "Completed"
+	RerunJobStatusFailed    RerunJobStatus = "Failed"
+)
+
+// RerunJob describes a Job that got (re-)created due to the kluctl.io/rerun-job annotation
+type RerunJob struct {
+	Ref    k8s.ObjectRef  `json:"ref"`
+	Reason string         `json:"reason"`
+	Status RerunJobStatus `json:"status"`
+}
+
+// OwnershipConflict describes an object that got applied by this target while the live object carried the
+// discriminator of a different target, meaning that both targets manage the same object
+type OwnershipConflict struct {
+	Ref                k8s.ObjectRef `json:"ref"`
+	OtherDiscriminator string        `json:"otherDiscriminator"`
+
+	// OtherTarget and OtherResultId are only set if the other target could be determined from the result store
+	OtherTarget   string `json:"otherTarget,omitempty"`
+	OtherResultId string `json:"otherResultId,omitempty"`
+}
+
+type ConfirmationDecisionType string
+
+const (
+	// ConfirmationDisplayed means that the warnings were only displayed as part of the diff
+	ConfirmationDisplayed ConfirmationDecisionType = "displayed"
+	// ConfirmationAcknowledged means that the warnings were explicitly acknowledged by the user
+	ConfirmationAcknowledged ConfirmationDecisionType = "acknowledged"
+	// ConfirmationRejected means that the user refused to acknowledge the warnings
+	ConfirmationRejected ConfirmationDecisionType = "rejected"
+	// ConfirmationAutoConfirmed means that acknowledgement was required, but skipped via --yes
+	ConfirmationAutoConfirmed ConfirmationDecisionType = "autoConfirmed"
+	// ConfirmationAborted means that the command was aborted, as the warnings must not be confirmed via --yes
+	ConfirmationAborted ConfirmationDecisionType = "aborted"
+)
+
+// ConfirmationInfo records how warnings found in the diff were handled before confirming a deployment
+type ConfirmationInfo struct {
+	Interactive bool                   `json:"interactive"`
+	Decisions   []ConfirmationDecision `json:"decisions,omitempty"`
+}
+
+type ConfirmationDecision struct {
+	Category types.ConfirmationCategory `json:"category"`
+	Count    int                        `json:"count"`
+	Decision ConfirmationDecisionType   `json:"decision"`
+}
+
+type PhaseType string
+
+const (
+	PhasePreDeployHooks  PhaseType = "preDeployHooks"
+	PhaseApply           PhaseType = "apply"
+	PhasePostDeployHooks PhaseType = "postDeployHooks"
+	PhasePostDeployWaits PhaseType = "postDeployWaits"
+	PhasePrune           PhaseType = "prune"
+)
+
+// Phase describes a single execution phase of a command, e.g. the pre-deploy hooks of a deployment item. Phases of
+// different deployment items might overlap, as deployment items are applied in parallel.
+type Phase struct {
+	Type           PhaseType        `json:"type"`
+	DeploymentItem string           `json:"deploymentItem,omitempty"`
+	StartTime      metav1.MicroTime `json:"startTime"`
+	EndTime        metav1.MicroTime `json:"endTime"`
+	Objects        []k8s.ObjectRef  `json:"objects,omitempty"`
+}
+
+// FetchTiming describes a single network fetch (e.g. a git fetch or a Helm Chart pull) that was performed while
+// loading the project
+type FetchTiming struct {
+	Type      string           `json:"type"`
+	Source    string           `json:"source"`
+	StartTime metav1.MicroTime `json:"startTime"`
+	EndTime   metav1.MicroTime `json:"endTime"`
+	Attempts  int              `json:"attempts"`
+	// Deduplicated is the number of identical fetch requests that were served by this fetch
+	Deduplicated int    `json:"deduplicated,omitempty"`
+	Error        string `json:"error,omitempty"`
+	// Bytes is the number of bytes added to the local cache by this fetch. It is only measured when --stats is used.
+	Bytes int64 `json:"bytes,omitempty"`
 }
 
 type KluctlDeploymentInfo struct {
@@ -60,39 +173,190 @@ func (k TargetKey) Less(o TargetKey) bool {
 }
 
 type CommandInfo struct {
-	Initiator             CommandInitiator       `json:"initiator" validate:"oneof=CommandLine KluctlDeployment"`
-	StartTime             metav1.Time            `json:"startTime"`
-	EndTime               metav1.Time            `json:"endTime"`
-	Command               string                 `json:"command,omitempty"`
-	Target                string                 `json:"target,omitempty"`
-	TargetNameOverride    string                 `json:"targetNameOverride,omitempty"`
-	ContextOverride       string                 `json:"contextOverride,omitempty"`
-	Args                  *uo.UnstructuredObject `json:"args,omitempty"`
-	Images                []types.FixedImage     `json:"images,omitempty"`
-	DryRun                bool                   `json:"dryRun,omitempty"`
-	NoWait                bool                   `json:"noWait,omitempty"`
-	ForceApply            bool                   `json:"forceApply,omitempty"`
-	ReplaceOnError        bool                   `json:"replaceOnError,omitempty"`
-	ForceReplaceOnError   bool                   `json:"forceReplaceOnError,omitempty"`
-	AbortOnError          bool                   `json:"abortOnError,omitempty"`
-	IncludeTags           []string               `json:"includeTags,omitempty"`
-	ExcludeTags           []string               `json:"excludeTags,omitempty"`
-	IncludeDeploymentDirs []string               `json:"includeDeploymentDirs,omitempty"`
-	ExcludeDeploymentDirs []string               `json:"excludeDeploymentDirs,omitempty"`
+	Initiator               CommandInitiator       `json:"initiator" validate:"oneof=CommandLine KluctlDeployment"`
+	StartTime               metav1.Time            `json:"startTime"`
+	EndTime                 metav1.Time            `json:"endTime"`
+	Command                 string                 `json:"command,omitempty"`
+	Target                  string                 `json:"target,omitempty"`
+	TargetNameOverride      string                 `json:"targetNameOverride,omitempty"`
+	ContextOverride         string                 `json:"contextOverride,omitempty"`
+	Args                    *uo.UnstructuredObject `json:"args,omitempty"`
+	Images                  []types.FixedImage     `json:"images,omitempty"`
+	DryRun                  bool                   `json:"dryRun,omitempty"`
+	ReadOnly                bool                   `json:"readOnly,omitempty"`
+	NoWait                  bool                   `json:"noWait,omitempty"`
+	ForceApply              bool                   `json:"forceApply,omitempty"`
+	ReplaceOnError          bool                   `json:"replaceOnError,omitempty"`
+	ForceReplaceOnError     bool                   `json:"forceReplaceOnError,omitempty"`
+	AbortOnError            bool                   `json:"abortOnError,omitempty"`
+	OverrideSafetyThreshold bool                   `json:"overrideSafetyThreshold,omitempty"`
+	IncludeTags             []string               `json:"includeTags,omitempty"`
+	ExcludeTags             []string               `json:"excludeTags,omitempty"`
+	IncludeDeploymentDirs   []string               `json:"includeDeploymentDirs,omitempty"`
+	ExcludeDeploymentDirs   []string               `json:"excludeDeploymentDirs,omitempty"`
+	Invocation              *InvocationInfo        `json:"invocation,omitempty"`
+
+	// RollbackSourceResultId is the id of the command result that was rolled back to
+	RollbackSourceResultId string `json:"rollbackSourceResultId,omitempty"`
+
+	// HookRunId is the run id that was added to all applied hooks via the kluctl.io/hook-run-id label
+	HookRunId string `json:"hookRunId,omitempty"`
+
+	// Partial is true if only the deployment items affected by changed files were rendered and diffed
+	Partial bool `json:"partial,omitempty"`
+	// PartialNote explains why the result is partial or why a full result was produced instead
+	PartialNote string `json:"partialNote,omitempty"`
+}
+
+// InvocationInfo describes how the kluctl CLI was invoked to produce a command result. Values of sensitive flags,
+// sensitive args and environment variables that are not known to be safe are redacted.
+type InvocationInfo struct {
+	// Args contains the command and all flags that differ from their defaults, excluding the executable name
+	Args []string `json:"args,omitempty"`
+	// Env contains all KLUCTL_ environment variables that were set
+	Env map[string]string `json:"env,omitempty"`
+
+	KluctlVersion string `json:"kluctlVersion,omitempty"`
+	GoVersion     string `json:"goVersion,omitempty"`
+	Platform      string `json:"platform,omitempty"`
 }
 
 type ClusterInfo struct {
 	ClusterId string `json:"clusterId"`
+	// ClusterIdSource is the strategy that produced ClusterId, which is one of "target", "kubeSystem", "configMap" and
+	// "apiServer". It is empty for results written by older versions.
+	ClusterIdSource string `json:"clusterIdSource,omitempty"`
+	// KubeconfigSource is a non-sensitive description of where the kubeconfig was loaded from. It is empty when the
+	// default kubeconfig loading applied.
+	KubeconfigSource string `json:"kubeconfigSource,omitempty"`
 }
 
 type BaseObject struct {
 	Ref     k8s.ObjectRef `json:"ref"`
 	Changes []Change      `json:"changes,omitempty"`
+	// IgnoredChanges contains the changes that were suppressed by object level ignore rules, which are the
+	// kluctl.io/ignore-diff-field* annotations and spec.replicas of objects targeted by a HorizontalPodAutoscaler
+	IgnoredChanges []Change `json:"ignoredChanges,omitempty"`
 
 	New     bool `json:"new,omitempty"`
 	Orphan  bool `json:"orphan,omitempty"`
 	Deleted bool `json:"deleted,omitempty"`
 	Hook    bool `json:"hook,omitempty"`
+
+	// SecurityRelevant is set on new, changed and deleted objects of security-sensitive kinds, e.g. RBAC objects
+	SecurityRelevant bool `json:"securityRelevant,omitempty"`
+
+	// ObfuscatedByRules is set when obfuscation rules replaced values in the stored manifests of the object. Such
+	// manifests contain placeholders and must not be applied again, e.g. by rollbacks.
+	ObfuscatedByRules bool `json:"obfuscatedByRules,omitempty"`
+
+	OwnershipMigration *OwnershipMigration `json:"ownershipMigration,omitempty"`
+	Moved              *ObjectMove         `json:"moved,omitempty"`
+
+	Timings *ObjectTimings `json:"timings,omitempty"`
+}
+
+// ObjectMove records that an object was moved from one deployment item to another. Moved objects only get their
+// tracking metadata updated and are not counted as new, changed or orphan objects.
+type ObjectMove struct {
+	FromItem string `json:"fromItem"`
+	ToItem   string `json:"toItem"`
+}
+
+// OwnershipMigration records that field ownership of an object was taken over from other field managers, e.g. from
+// kubectl client-side apply
+type OwnershipMigration struct {
+	// From contains the field managers that ownership was taken from
+	From []string `json:"from"`
+	// CoOwners contains the field managers that still own fields of the object after the migration
+	CoOwners []string `json:"coOwners,omitempty"`
+}
+
+// ObjectTimings records how long the operations performed on a single object took. Durations are summed up if an
+// operation was performed multiple times, e.g. when a hook is applied in multiple phases.
+type ObjectTimings struct {
+	// Apply is the time spent in apply calls to the API server, including retries
+	Apply *metav1.Duration `json:"apply,omitempty"`
+	// WaitHook is the time spent waiting for a hook to become ready
+	WaitHook *metav1.Duration `json:"waitHook,omitempty"`
+	// WaitReadiness is the time spent waiting for the object to become ready
+	WaitReadiness *metav1.Duration `json:"waitReadiness,omitempty"`
+}
+
+// Total returns the sum of all recorded durations
+func (t *ObjectTimings) Total() time.Duration {
+	var ret time.Duration
+	for _, d := range []*metav1.Duration{t.Apply, t.WaitHook, t.WaitReadiness} {
+		if d != nil {
+			ret += d.Duration
+		}
+	}
+	return ret
+}
+
+// CommandTimings records the total durations of the different stages of a command. Stages that were not performed
+// are omitted.
+type CommandTimings struct {
+	// Render is the time it took to render all deployment items
+	Render *metav1.Duration `json:"render,omitempty"`
+	// Diff is the time it took to simulate the apply and to calculate the diff
+	Diff *metav1.Duration `json:"diff,omitempty"`
+	// Apply is the time it took to apply all deployment items, including hooks and waits
+	Apply *metav1.Duration `json:"apply,omitempty"`
+	// Hooks is the time spent in pre- and post-deploy hook phases. Phases of deployment items that were applied in
+	// parallel are summed up, so this might exceed Apply.
+	Hooks *metav1.Duration `json:"hooks,omitempty"`
+	// Prune is the time it took to delete orphan objects
+	Prune *metav1.Duration `json:"prune,omitempty"`
+}
+
+// CommandStats contains performance metrics of a command. It is only set if the command was invoked with --stats.
+type CommandStats struct {
+	// WallTime is the time from the start of the command until the command result was finished
+	WallTime metav1.Duration `json:"wallTime"`
+	// ApiCalls contains the number of Kubernetes API requests per verb, e.g. get, list or patch
+	ApiCalls map[string]int `json:"apiCalls,omitempty"`
+	// FetchedBytes contains the number of fetched bytes per source type, e.g. git, oci or helm
+	FetchedBytes map[string]int64 `json:"fetchedBytes,omitempty"`
+	// Caches contains the cache hits and misses per source type
+	Caches map[string]CacheStats `json:"caches,omitempty"`
+	// RenderedTemplates is the number of rendered Jinja2 templates, including rendered files and templated strings
+	RenderedTemplates int64 `json:"renderedTemplates,omitempty"`
+	// PeakMemory is the peak memory usage of the kluctl process in bytes
+	PeakMemory int64 `json:"peakMemory,omitempty"`
+}
+
+type CacheStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+// HitRate returns the ratio of hits to all cache lookups, or 0 if the cache was not used
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// ResourceDelta describes how the CPU and memory requests and limits of all workloads change. Quantities are multiplied
+// with the replica counts of the workloads, using the minimum replicas of HorizontalPodAutoscalers when present.
+type ResourceDelta struct {
+	Total ResourceQuantities `json:"total"`
+	// Namespaces contains the delta per namespace, sorted by namespace. Namespaces without changes are omitted.
+	Namespaces []NamespaceResourceDelta `json:"namespaces,omitempty"`
+}
+
+type NamespaceResourceDelta struct {
+	Namespace string             `json:"namespace"`
+	Delta     ResourceQuantities `json:"delta"`
+}
+
+type ResourceQuantities struct {
+	CpuRequests    resource.Quantity `json:"cpuRequests"`
+	CpuLimits      resource.Quantity `json:"cpuLimits"`
+	MemoryRequests resource.Quantity `json:"memoryRequests"`
+	MemoryLimits   resource.Quantity `json:"memoryLimits"`
 }
 
 type ResultObject struct {
@@ -101,6 +365,10 @@ type ResultObject struct {
 	Rendered *uo.UnstructuredObject `json:"rendered,omitempty"`
 	Remote   *uo.UnstructuredObject `json:"remote,omitempty"`
 	Applied  *uo.UnstructuredObject `json:"applied,omitempty"`
+
+	// DeletedManifest is the last live manifest of a deleted object, stripped from all server-populated fields, so
+	// that it can be restored via 'kluctl results restore-object'
+	DeletedManifest *uo.UnstructuredObject `json:"deletedManifest,omitempty"`
 }
 
 type CommandResult struct {
@@ -122,12 +390,48 @@ type CommandResult struct {
 	Errors     []DeploymentError  `json:"errors,omitempty"`
 	Warnings   []DeploymentError  `json:"warnings,omitempty"`
 	SeenImages []types.FixedImage `json:"seenImages,omitempty"`
+	RerunJobs  []RerunJob         `json:"rerunJobs,omitempty"`
+	Phases     []Phase            `json:"phases,omitempty"`
+	Fetches    []FetchTiming      `json:"fetches,omitempty"`
+	Timings    *CommandTimings    `json:"timings,omitempty"`
+	Stats      *CommandStats      `json:"stats,omitempty"`
+
+	// ResourceDelta is only set if the command changes the requested or limited resources of workloads
+	ResourceDelta *ResourceDelta `json:"resourceDelta,omitempty"`
+
+	OwnershipConflicts []OwnershipConflict `json:"ownershipConflicts,omitempty"`
+
+	// Confirmation records how warnings found in the diff were handled before the deployment was confirmed. It is only
+	// set if a confirmation policy is configured for the target.
+	Confirmation *ConfirmationInfo `json:"confirmation,omitempty"`
+
+	// ProjectLock contains all inputs that were resolved from external sources, see 'kluctl lock write'
+	ProjectLock *types.ProjectLock `json:"projectLock,omitempty"`
+
+	// Signature is only set when the command result was signed before it was written to the result store
+	Signature *CommandResultSignature `json:"signature,omitempty"`
+}
+
+// CommandResultSignature is a signature over the obfuscated command result, excluding the signature itself
+type CommandResultSignature struct {
+	// Algorithm is either ecdsa-sha256 or ed25519
+	Algorithm string `json:"algorithm"`
+	// KeyId is the hex encoded sha256 fingerprint of the DER encoded public key
+	KeyId string `json:"keyId"`
+	// PublicKey is the PEM encoded public key that the signature can be verified with
+	PublicKey string `json:"publicKey"`
+	// Signature is the base64 encoded signature
+	Signature string `json:"signature"`
 }
 
+// ToCompacted returns the compacted form of the command result. Objects, changes, errors and warnings are sorted, so
+// that serializing command results with identical outcomes leads to identical output.
 func (cr *CommandResult) ToCompacted() *CompactedCommandResult {
 	ret := &CompactedCommandResult{
-		CommandResult: *cr,
+		CommandResult: *cr.ToSorted(),
 	}
+	counts := cr.BuildCounts()
+	ret.Summary = &counts
 	ret.CompactedObjects = ret.Objects
 	ret.Objects = nil
 	return ret
@@ -141,6 +445,7 @@ func (cr *CommandResult) ToReducedObjects() *CommandResult {
 		ret.Objects[i].Rendered = BuildReducedObject(o.Rendered)
 		ret.Objects[i].Remote = BuildReducedObject(o.Remote)
 		ret.Objects[i].Applied = BuildReducedObject(o.Applied)
+		ret.Objects[i].DeletedManifest = nil
 	}
 	return &ret
 }
@@ -149,6 +454,9 @@ type CompactedCommandResult struct {
 	CommandResult
 
 	CompactedObjects CompactedObjects `json:"compactedObjects,omitempty"`
+
+	// Summary is only informational and ignored when converting back via ToNonCompacted
+	Summary *CommandResultCounts `json:"summary,omitempty"`
 }
 
 func (ccr *CompactedCommandResult) ToNonCompacted() *CommandResult {
@@ -3,6 +3,8 @@ package result
 import (
 	gittypes "github.com/kluctl/kluctl/lib/git/types"
 	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type CommandResultSummary struct {
@@ -27,6 +29,12 @@ type CommandResultSummary struct {
 	ChangedObjects int `json:"changedObjects"`
 	OrphanObjects  int `json:"orphanObjects"`
 	DeletedObjects int `json:"deletedObjects"`
+	MovedObjects   int `json:"movedObjects,omitempty"`
+
+	SecurityRelevantObjects int `json:"securityRelevantObjects,omitempty"`
+
+	// Kinds contains the per-kind breakdown of the object counts, see CommandResultCounts.Kinds
+	Kinds map[string]KindCounts `json:"kinds,omitempty"`
 
 	Errors   []DeploymentError `json:"errors"`
 	Warnings []DeploymentError `json:"warnings"`
@@ -50,29 +58,167 @@ func (cr *CommandResult) BuildSummary() *CommandResultSummary {
 	}
 
 	ret := &CommandResultSummary{
-		Id:                  cr.Id,
-		ReconcileId:         cr.ReconcileId,
-		ProjectKey:          cr.ProjectKey,
-		TargetKey:           cr.TargetKey,
-		Target:              cr.Target,
-		Command:             cr.Command,
-		KluctlDeployment:    cr.KluctlDeployment,
-		GitInfo:             cr.GitInfo,
-		ClusterInfo:         cr.ClusterInfo,
-		RenderedObjectsHash: cr.RenderedObjectsHash,
-		RenderedObjects:     count(func(o ResultObject) bool { return o.Rendered != nil }),
-		RemoteObjects:       count(func(o ResultObject) bool { return o.Remote != nil }),
-		AppliedObjects:      count(func(o ResultObject) bool { return o.Applied != nil }),
-		AppliedHookObjects:  count(func(o ResultObject) bool { return o.Hook }),
-		NewObjects:          count(func(o ResultObject) bool { return o.New }),
-		ChangedObjects:      count(func(o ResultObject) bool { return len(o.Changes) != 0 }),
-		OrphanObjects:       count(func(o ResultObject) bool { return o.Orphan }),
-		DeletedObjects:      count(func(o ResultObject) bool { return o.Deleted }),
-		Errors:              cr.Errors,
-		Warnings:            cr.Warnings,
+		Id:                      cr.Id,
+		ReconcileId:             cr.ReconcileId,
+		ProjectKey:              cr.ProjectKey,
+		TargetKey:               cr.TargetKey,
+		Target:                  cr.Target,
+		Command:                 cr.Command,
+		KluctlDeployment:        cr.KluctlDeployment,
+		GitInfo:                 cr.GitInfo,
+		ClusterInfo:             cr.ClusterInfo,
+		RenderedObjectsHash:     cr.RenderedObjectsHash,
+		RenderedObjects:         count(func(o ResultObject) bool { return o.Rendered != nil }),
+		RemoteObjects:           count(func(o ResultObject) bool { return o.Remote != nil }),
+		AppliedObjects:          count(func(o ResultObject) bool { return o.Applied != nil }),
+		AppliedHookObjects:      count(func(o ResultObject) bool { return o.Hook }),
+		NewObjects:              count(func(o ResultObject) bool { return o.New }),
+		ChangedObjects:          count(func(o ResultObject) bool { return len(o.Changes) != 0 }),
+		OrphanObjects:           count(func(o ResultObject) bool { return o.Orphan }),
+		DeletedObjects:          count(func(o ResultObject) bool { return o.Deleted }),
+		MovedObjects:            count(func(o ResultObject) bool { return o.Moved != nil }),
+		SecurityRelevantObjects: count(func(o ResultObject) bool { return o.SecurityRelevant }),
+		Errors:                  cr.Errors,
+		Warnings:                cr.Warnings,
 	}
 	for _, o := range cr.Objects {
 		ret.TotalChanges += len(o.Changes)
 	}
+	ret.Kinds = cr.BuildCounts().Kinds
 	return ret
 }
+
+// CommandResultCounts contains the number of objects per category of a command result, together with the number of
+// errors and warnings
+type CommandResultCounts struct {
+	NewObjects         int `json:"newObjects"`
+	ChangedObjects     int `json:"changedObjects"`
+	DeletedObjects     int `json:"deletedObjects"`
+	OrphanObjects      int `json:"orphanObjects"`
+	AppliedHookObjects int `json:"appliedHookObjects"`
+	Errors             int `json:"errors"`
+	Warnings           int `json:"warnings"`
+
+	SecurityRelevantObjects int `json:"securityRelevantObjects,omitempty"`
+
+	// Kinds maps kinds to the number of objects of that kind per category. Kinds of the core API group are used as is
+	// (e.g. 'ConfigMap'), all other kinds are prefixed with their group (e.g. 'apps/Deployment'). Only kinds with at
+	// least one counted object are included.
+	Kinds map[string]KindCounts `json:"kinds,omitempty"`
+}
+
+// KindCounts contains the number of objects of a single kind per category
+type KindCounts struct {
+	New          int `json:"new,omitempty"`
+	Changed      int `json:"changed,omitempty"`
+	Deleted      int `json:"deleted,omitempty"`
+	Orphan       int `json:"orphan,omitempty"`
+	AppliedHooks int `json:"appliedHooks,omitempty"`
+}
+
+// BuildKindKey returns the key used in CommandResultCounts.Kinds for the given ref
+func BuildKindKey(ref k8s.ObjectRef) string {
+	if ref.Group == "" {
+		return ref.Kind
+	}
+	return ref.Group + "/" + ref.Kind
+}
+
+func (cr *CommandResult) BuildCounts() CommandResultCounts {
+	ret := CommandResultCounts{
+		Errors:   len(cr.Errors),
+		Warnings: len(cr.Warnings),
+	}
+	for _, o := range cr.Objects {
+		ret.Add(o)
+	}
+	return ret
+}
+
+// Add counts the given object into all matching categories
+func (c *CommandResultCounts) Add(o ResultObject) {
+	var kc KindCounts
+	if o.New {
+		c.NewObjects++
+		kc.New++
+	}
+	if len(o.Changes) != 0 {
+		c.ChangedObjects++
+		kc.Changed++
+	}
+	if o.Deleted {
+		c.DeletedObjects++
+		kc.Deleted++
+	}
+	if o.Orphan {
+		c.OrphanObjects++
+		kc.Orphan++
+	}
+	if o.Hook {
+		c.AppliedHookObjects++
+		kc.AppliedHooks++
+	}
+	if o.SecurityRelevant {
+		c.SecurityRelevantObjects++
+	}
+
+	if kc == (KindCounts{}) {
+		return
+	}
+	if c.Kinds == nil {
+		c.Kinds = map[string]KindCounts{}
+	}
+	key := BuildKindKey(o.Ref)
+	x := c.Kinds[key]
+	x.New += kc.New
+	x.Changed += kc.Changed
+	x.Deleted += kc.Deleted
+	x.Orphan += kc.Orphan
+	x.AppliedHooks += kc.AppliedHooks
+	c.Kinds[key] = x
+}
+
+// IsEmpty returns true if nothing was counted
+func (c CommandResultCounts) IsEmpty() bool {
+	return c.NewObjects == 0 && c.ChangedObjects == 0 && c.DeletedObjects == 0 && c.OrphanObjects == 0 &&
+		c.AppliedHookObjects == 0 && c.Errors == 0 && c.Warnings == 0 && c.SecurityRelevantObjects == 0 &&
+		len(c.Kinds) == 0
+}
+
+// CommandResultIndexEntry is a lightweight summary record of a command result. Result stores maintain an index of
+// these records, so that results can be listed without reading the full summaries.
+type CommandResultIndexEntry struct {
+	Id         string              `json:"id"`
+	ProjectKey gittypes.ProjectKey `json:"projectKey"`
+	TargetKey  TargetKey           `json:"targetKey"`
+	ClusterId  string              `json:"clusterId"`
+	Command    string              `json:"command"`
+	StartTime  metav1.Time         `json:"startTime"`
+	EndTime    metav1.Time         `json:"endTime"`
+
+	Counts CommandResultCounts `json:"counts"`
+}
+
+func (s *CommandResultSummary) BuildIndexEntry() *CommandResultIndexEntry {
+	return &CommandResultIndexEntry{
+		Id:         s.Id,
+		ProjectKey: s.ProjectKey,
+		TargetKey:  s.TargetKey,
+		ClusterId:  s.ClusterInfo.ClusterId,
+		Command:    s.Command.Command,
+		StartTime:  s.Command.StartTime,
+		EndTime:    s.Command.EndTime,
+		Counts: CommandResultCounts{
+			NewObjects:         s.NewObjects,
+			ChangedObjects:     s.ChangedObjects,
+			DeletedObjects:     s.DeletedObjects,
+			OrphanObjects:      s.OrphanObjects,
+			AppliedHookObjects: s.AppliedHookObjects,
+			Errors:             len(s.Errors),
+			Warnings:           len(s.Warnings),
+
+			SecurityRelevantObjects: s.SecurityRelevantObjects,
+			Kinds:                   s.Kinds,
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCountsKinds(t *testing.T) {
+	deployment := func(name string) k8s.ObjectRef {
+		return k8s.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "ns", Name: name}
+	}
+	cm := func(name string) k8s.ObjectRef {
+		return k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: "ns", Name: name}
+	}
+	cr := &CommandResult{
+		Objects: []ResultObject{
+			{BaseObject: BaseObject{Ref: deployment("d1"), New: true}},
+			{BaseObject: BaseObject{Ref: deployment("d2"), Changes: []Change{{JsonPath: "spec.replicas"}}}},
+			{BaseObject: BaseObject{Ref: deployment("d3"), Changes: []Change{{JsonPath: "spec.replicas"}}}},
+			{BaseObject: BaseObject{Ref: deployment("unchanged")}},
+			{BaseObject: BaseObject{Ref: cm("orphan"), Orphan: true}},
+			{BaseObject: BaseObject{Ref: cm("deleted"), Deleted: true}},
+			{BaseObject: BaseObject{Ref: k8s.ObjectRef{Group: "batch", Version: "v1", Kind: "Job", Name: "hook"}, Hook: true}},
+		},
+		Errors: []DeploymentError{{Message: "error"}},
+	}
+
+	expectedKinds := map[string]KindCounts{
+		"apps/Deployment": {New: 1, Changed: 2},
+		"ConfigMap":       {Deleted: 1, Orphan: 1},
+		"batch/Job":       {AppliedHooks: 1},
+	}
+
+	counts := cr.BuildCounts()
+	assert.Equal(t, CommandResultCounts{
+		NewObjects:         1,
+		ChangedObjects:     2,
+		DeletedObjects:     1,
+		OrphanObjects:      1,
+		AppliedHookObjects: 1,
+		Errors:             1,
+		Kinds:              expectedKinds,
+	}, counts)
+	assert.False(t, counts.IsEmpty())
+	assert.True(t, (&CommandResult{}).BuildCounts().IsEmpty())
+
+	summary := cr.BuildSummary()
+	assert.Equal(t, expectedKinds, summary.Kinds)
+	assert.Equal(t, expectedKinds, summary.BuildIndexEntry().Counts.Kinds)
+}
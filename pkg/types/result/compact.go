@@ -17,9 +17,10 @@ type CompactedObjects []ResultObject
 type CompactedObject struct {
 	BaseObject
 
-	Rendered string `json:"rendered,omitempty"`
-	Remote   string `json:"remote,omitempty"`
-	Applied  string `json:"applied,omitempty"`
+	Rendered        string `json:"rendered,omitempty"`
+	Remote          string `json:"remote,omitempty"`
+	Applied         string `json:"applied,omitempty"`
+	DeletedManifest string `json:"deletedManifest,omitempty"`
 }
 
 func (l CompactedObjects) MarshalJSON() ([]byte, error) {
@@ -64,6 +65,7 @@ func (l CompactedObjects) MarshalJSON() ([]byte, error) {
 			compactedList[i].Rendered = createPatchOrFull(&prevJson, o.Rendered)
 			compactedList[i].Remote = createPatchOrFull(&prevJson, o.Remote)
 			compactedList[i].Applied = createPatchOrFull(&prevJson, o.Applied)
+			compactedList[i].DeletedManifest = createPatchOrFull(&prevJson, o.DeletedManifest)
 		}()
 	}
 	wg.Wait()
@@ -141,6 +143,10 @@ func (l *CompactedObjects) UnmarshalJSON(b []byte) error {
 			if err != nil {
 				return err
 			}
+			o2.DeletedManifest, err = patchAndUnmarshal(&prevJson, o.DeletedManifest)
+			if err != nil {
+				return err
+			}
 			ret[i] = o2
 			return nil
 		})
@@ -0,0 +1,56 @@
+package result
+
+import (
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+)
+
+// DefaultSecuritySensitiveKinds is used when no security-sensitive kinds are configured in .kluctl.yaml
+var DefaultSecuritySensitiveKinds = []string{
+	"rbac.authorization.k8s.io/Role",
+	"rbac.authorization.k8s.io/RoleBinding",
+	"rbac.authorization.k8s.io/ClusterRole",
+	"rbac.authorization.k8s.io/ClusterRoleBinding",
+	"networking.k8s.io/NetworkPolicy",
+	"policy/PodSecurityPolicy",
+	"admissionregistration.k8s.io/ValidatingAdmissionPolicy",
+	"admissionregistration.k8s.io/ValidatingAdmissionPolicyBinding",
+	"admissionregistration.k8s.io/ValidatingWebhookConfiguration",
+	"admissionregistration.k8s.io/MutatingWebhookConfiguration",
+	"apiextensions.k8s.io/CustomResourceDefinition",
+}
+
+// MatchKindSelector returns true if the ref matches the given selector, which is either 'Kind' (matching all groups)
+// or 'group/Kind'. Kinds are matched case-insensitive.
+func MatchKindSelector(ref k8s.ObjectRef, selector string) bool {
+	group, kind, hasGroup := strings.Cut(selector, "/")
+	if !hasGroup {
+		group, kind = "", group
+	}
+	if !strings.EqualFold(kind, ref.Kind) {
+		return false
+	}
+	return !hasGroup || group == ref.Group
+}
+
+// MarkSecurityRelevant sets SecurityRelevant on all new, changed and deleted objects that match one of the given kind
+// selectors. DefaultSecuritySensitiveKinds is used if kinds is nil.
+func (cr *CommandResult) MarkSecurityRelevant(kinds []string) {
+	if kinds == nil {
+		kinds = DefaultSecuritySensitiveKinds
+	}
+	for i := range cr.Objects {
+		o := &cr.Objects[i]
+		o.SecurityRelevant = false
+		if !o.New && !o.Deleted && len(o.Changes) == 0 {
+			continue
+		}
+		for _, k := range kinds {
+			if MatchKindSelector(o.Ref, k) {
+				o.SecurityRelevant = true
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchKindSelector(t *testing.T) {
+	role := k8s.ObjectRef{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role", Name: "r"}
+	assert.True(t, MatchKindSelector(role, "Role"))
+	assert.True(t, MatchKindSelector(role, "role"))
+	assert.True(t, MatchKindSelector(role, "rbac.authorization.k8s.io/Role"))
+	assert.False(t, MatchKindSelector(role, "other.io/Role"))
+	assert.False(t, MatchKindSelector(role, "ClusterRole"))
+}
+
+func TestMarkSecurityRelevant(t *testing.T) {
+	ref := func(group string, kind string, name string) k8s.ObjectRef {
+		return k8s.ObjectRef{Group: group, Version: "v1", Kind: kind, Name: name}
+	}
+	cr := &CommandResult{
+		Objects: []ResultObject{
+			{BaseObject: BaseObject{Ref: ref("rbac.authorization.k8s.io", "ClusterRole", "new"), New: true}},
+			{BaseObject: BaseObject{Ref: ref("rbac.authorization.k8s.io", "ClusterRole", "unchanged")}},
+			{BaseObject: BaseObject{Ref: ref("networking.k8s.io", "NetworkPolicy", "changed"), Changes: []Change{{JsonPath: "spec"}}}},
+			{BaseObject: BaseObject{Ref: ref("admissionregistration.k8s.io", "ValidatingWebhookConfiguration", "deleted"), Deleted: true}},
+			{BaseObject: BaseObject{Ref: ref("", "ConfigMap", "cm"), New: true}},
+		},
+	}
+
+	cr.MarkSecurityRelevant(nil)
+	var marked []bool
+	for _, o := range cr.Objects {
+		marked = append(marked, o.SecurityRelevant)
+	}
+	assert.Equal(t, []bool{true, false, true, true, false}, marked)
+	assert.Equal(t, 3, cr.BuildSummary().SecurityRelevantObjects)
+	assert.Equal(t, 3, cr.BuildCounts().SecurityRelevantObjects)
+
+	// configured kinds replace the defaults
+	cr.MarkSecurityRelevant([]string{"ConfigMap"})
+	marked = nil
+	for _, o := range cr.Objects {
+		marked = append(marked, o.SecurityRelevant)
+	}
+	assert.Equal(t, []bool{false, false, false, false, true}, marked)
+}
@@ -0,0 +1,77 @@
+package result
+
+import (
+	"sort"
+)
+
+// ToSorted returns a shallow copy of the command result with all lists that are built concurrently (objects, changes,
+// errors and warnings) sorted deterministically. The original command result is not modified.
+func (cr *CommandResult) ToSorted() *CommandResult {
+	ret := *cr
+
+	ret.Objects = make([]ResultObject, len(cr.Objects))
+	for i, o := range cr.Objects {
+		ret.Objects[i] = o
+		ret.Objects[i].Changes = sortChanges(o.Changes)
+		ret.Objects[i].IgnoredChanges = sortChanges(o.IgnoredChanges)
+	}
+	sort.SliceStable(ret.Objects, func(i, j int) bool {
+		return lessBaseObject(&ret.Objects[i].BaseObject, &ret.Objects[j].BaseObject)
+	})
+	if cr.Objects == nil {
+		ret.Objects = nil
+	}
+
+	ret.Errors = sortDeploymentErrors(cr.Errors)
+	ret.Warnings = sortDeploymentErrors(cr.Warnings)
+
+	return &ret
+}
+
+// lessBaseObject sorts by ref first. Hooks might be recorded multiple times for the same ref, in which case the
+// remaining flags decide.
+func lessBaseObject(a *BaseObject, b *BaseObject) bool {
+	if a.Ref != b.Ref {
+		return a.Ref.Less(b.Ref)
+	}
+	flags := func(o *BaseObject) []bool {
+		return []bool{o.New, o.Orphan, o.Deleted, o.Hook}
+	}
+	fa, fb := flags(a), flags(b)
+	for i := range fa {
+		if fa[i] != fb[i] {
+			return !fa[i]
+		}
+	}
+	return false
+}
+
+func sortChanges(l []Change) []Change {
+	if l == nil {
+		return nil
+	}
+	ret := make([]Change, len(l))
+	copy(ret, l)
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].JsonPath != ret[j].JsonPath {
+			return ret[i].JsonPath < ret[j].JsonPath
+		}
+		return ret[i].Type < ret[j].Type
+	})
+	return ret
+}
+
+func sortDeploymentErrors(l []DeploymentError) []DeploymentError {
+	if l == nil {
+		return nil
+	}
+	ret := make([]DeploymentError, len(l))
+	copy(ret, l)
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Ref != ret[j].Ref {
+			return ret[i].Ref.Less(ret[j].Ref)
+		}
+		return ret[i].Message < ret[j].Message
+	})
+	return ret
+}
@@ -0,0 +1,104 @@
+package result
+
+import (
+	"flag"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+const compactedGoldenFile = "testdata/compacted-command-result.yaml"
+
+func buildSortTestResult() *CommandResult {
+	cm := func(ns string, name string) *uo.UnstructuredObject {
+		o := uo.New()
+		o.SetK8sGVKs("", "v1", "ConfigMap")
+		o.SetK8sNamespace(ns)
+		o.SetK8sName(name)
+		_ = o.SetNestedField(map[string]any{"b": "2", "a": "1", "c": "3"}, "data")
+		return o
+	}
+	ref := func(ns string, name string) k8s.ObjectRef {
+		return k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Namespace: ns, Name: name}
+	}
+	json := func(s string) *apiextensionsv1.JSON {
+		return &apiextensionsv1.JSON{Raw: []byte(s)}
+	}
+
+	startTime := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return &CommandResult{
+		Id: "id",
+		Command: CommandInfo{
+			Command:   "deploy",
+			StartTime: startTime,
+			EndTime:   startTime,
+		},
+		Objects: []ResultObject{
+			{BaseObject: BaseObject{Ref: ref("ns-b", "cm"), New: true}, Rendered: cm("ns-b", "cm")},
+			{BaseObject: BaseObject{Ref: ref("ns-a", "cm"), Changes: []Change{
+				{Type: "update", JsonPath: "data.b", OldValue: json(`"1"`), NewValue: json(`"2"`)},
+				{Type: "insert", JsonPath: "data.a", NewValue: json(`"1"`)},
+				{Type: "delete", JsonPath: "data.c", OldValue: json(`"3"`)},
+			}}, Rendered: cm("ns-a", "cm"), Remote: cm("ns-a", "cm")},
+			{BaseObject: BaseObject{Ref: ref("ns-a", "orphan"), Orphan: true}, Remote: cm("ns-a", "orphan")},
+		},
+		Warnings: []DeploymentError{
+			{Ref: ref("ns-b", "cm"), Message: "warning 2"},
+			{Ref: ref("ns-a", "cm"), Message: "warning 1"},
+			{Message: "global warning"},
+		},
+	}
+}
+
+func shuffleSortTestResult(cr *CommandResult, r *rand.Rand) {
+	r.Shuffle(len(cr.Objects), func(i, j int) {
+		cr.Objects[i], cr.Objects[j] = cr.Objects[j], cr.Objects[i]
+	})
+	for _, o := range cr.Objects {
+		r.Shuffle(len(o.Changes), func(i, j int) {
+			o.Changes[i], o.Changes[j] = o.Changes[j], o.Changes[i]
+		})
+	}
+	r.Shuffle(len(cr.Warnings), func(i, j int) {
+		cr.Warnings[i], cr.Warnings[j] = cr.Warnings[j], cr.Warnings[i]
+	})
+}
+
+func TestCompactedYamlIsDeterministic(t *testing.T) {
+	y, err := yaml.WriteYamlString(buildSortTestResult().ToCompacted())
+	assert.NoError(t, err)
+
+	if *updateGolden {
+		assert.NoError(t, os.WriteFile(compactedGoldenFile, []byte(y), 0o600))
+	}
+	golden, err := os.ReadFile(compactedGoldenFile)
+	assert.NoError(t, err)
+	assert.Equal(t, string(golden), y)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		cr := buildSortTestResult()
+		shuffleSortTestResult(cr, r)
+		y2, err := yaml.WriteYamlString(cr.ToCompacted())
+		assert.NoError(t, err)
+		assert.Equal(t, string(golden), y2)
+	}
+}
+
+func TestToSortedDoesNotModifyOriginal(t *testing.T) {
+	cr := buildSortTestResult()
+	_ = cr.ToSorted()
+	assert.Equal(t, "ns-b", cr.Objects[0].Ref.Namespace)
+	assert.Equal(t, "data.b", cr.Objects[1].Changes[0].JsonPath)
+	assert.Equal(t, "warning 2", cr.Warnings[0].Message)
+}
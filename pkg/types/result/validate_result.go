@@ -21,9 +21,11 @@ type ValidateResult struct {
 	KluctlDeployment    *KluctlDeploymentInfo  `json:"kluctlDeployment,omitempty"`
 	OverridesPatch      *uo.UnstructuredObject `json:"overridesPatch,omitempty"`
 	RenderedObjectsHash string                 `json:"renderedObjectsHash,omitempty"`
+	SourceResultId      string                 `json:"sourceResultId,omitempty"`
 	StartTime           metav1.Time            `json:"startTime"`
 	EndTime             metav1.Time            `json:"endTime"`
 	Ready               bool                   `json:"ready"`
+	ReadOnly            bool                   `json:"readOnly,omitempty"`
 	Warnings            []DeploymentError      `json:"warnings,omitempty"`
 	Errors              []DeploymentError      `json:"errors,omitempty"`
 	Results             []ValidateResultEntry  `json:"results,omitempty"`
@@ -36,6 +38,7 @@ type ValidateResultSummary struct {
 	TargetKey           TargetKey             `json:"targetKey"`
 	KluctlDeployment    *KluctlDeploymentInfo `json:"kluctlDeployment,omitempty"`
 	RenderedObjectsHash string                `json:"renderedObjectsHash,omitempty"`
+	SourceResultId      string                `json:"sourceResultId,omitempty"`
 	StartTime           metav1.Time           `json:"startTime"`
 	EndTime             metav1.Time           `json:"endTime"`
 	Ready               bool                  `json:"ready"`
@@ -53,6 +56,7 @@ func (vr *ValidateResult) BuildSummary() ValidateResultSummary {
 		TargetKey:           vr.TargetKey,
 		KluctlDeployment:    vr.KluctlDeployment,
 		RenderedObjectsHash: vr.RenderedObjectsHash,
+		SourceResultId:      vr.SourceResultId,
 		StartTime:           vr.StartTime,
 		EndTime:             vr.EndTime,
 		Ready:               vr.Ready,
@@ -22,7 +22,9 @@ package result
 
 import (
 	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -36,6 +38,28 @@ func (in *BaseObject) DeepCopyInto(out *BaseObject) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.IgnoredChanges != nil {
+		in, out := &in.IgnoredChanges, &out.IgnoredChanges
+		*out = make([]Change, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OwnershipMigration != nil {
+		in, out := &in.OwnershipMigration, &out.OwnershipMigration
+		*out = new(OwnershipMigration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Moved != nil {
+		in, out := &in.Moved, &out.Moved
+		*out = new(ObjectMove)
+		**out = **in
+	}
+	if in.Timings != nil {
+		in, out := &in.Timings, &out.Timings
+		*out = new(ObjectTimings)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BaseObject.
@@ -48,6 +72,21 @@ func (in *BaseObject) DeepCopy() *BaseObject {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheStats) DeepCopyInto(out *CacheStats) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheStats.
+func (in *CacheStats) DeepCopy() *CacheStats {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Change) DeepCopyInto(out *Change) {
 	*out = *in
@@ -147,6 +186,11 @@ func (in *CommandInfo) DeepCopyInto(out *CommandInfo) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Invocation != nil {
+		in, out := &in.Invocation, &out.Invocation
+		*out = new(InvocationInfo)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandInfo.
@@ -192,12 +236,16 @@ func (in *CommandResult) DeepCopyInto(out *CommandResult) {
 	if in.Errors != nil {
 		in, out := &in.Errors, &out.Errors
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Warnings != nil {
 		in, out := &in.Warnings, &out.Warnings
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.SeenImages != nil {
 		in, out := &in.SeenImages, &out.SeenImages
@@ -206,6 +254,60 @@ func (in *CommandResult) DeepCopyInto(out *CommandResult) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RerunJobs != nil {
+		in, out := &in.RerunJobs, &out.RerunJobs
+		*out = make([]RerunJob, len(*in))
+		copy(*out, *in)
+	}
+	if in.Phases != nil {
+		in, out := &in.Phases, &out.Phases
+		*out = make([]Phase, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Fetches != nil {
+		in, out := &in.Fetches, &out.Fetches
+		*out = make([]FetchTiming, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Timings != nil {
+		in, out := &in.Timings, &out.Timings
+		*out = new(CommandTimings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Stats != nil {
+		in, out := &in.Stats, &out.Stats
+		*out = new(CommandStats)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceDelta != nil {
+		in, out := &in.ResourceDelta, &out.ResourceDelta
+		*out = new(ResourceDelta)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OwnershipConflicts != nil {
+		in, out := &in.OwnershipConflicts, &out.OwnershipConflicts
+		*out = make([]OwnershipConflict, len(*in))
+		copy(*out, *in)
+	}
+	if in.Confirmation != nil {
+		in, out := &in.Confirmation, &out.Confirmation
+		*out = new(ConfirmationInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProjectLock != nil {
+		in, out := &in.ProjectLock, &out.ProjectLock
+		*out = new(types.ProjectLock)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Signature != nil {
+		in, out := &in.Signature, &out.Signature
+		*out = new(CommandResultSignature)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandResult.
@@ -218,6 +320,63 @@ func (in *CommandResult) DeepCopy() *CommandResult {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommandResultIndexEntry) DeepCopyInto(out *CommandResultIndexEntry) {
+	*out = *in
+	out.ProjectKey = in.ProjectKey
+	out.TargetKey = in.TargetKey
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	in.Counts.DeepCopyInto(&out.Counts)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandResultIndexEntry.
+func (in *CommandResultIndexEntry) DeepCopy() *CommandResultIndexEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(CommandResultIndexEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommandResultSignature) DeepCopyInto(out *CommandResultSignature) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandResultSignature.
+func (in *CommandResultSignature) DeepCopy() *CommandResultSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(CommandResultSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommandResultCounts) DeepCopyInto(out *CommandResultCounts) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make(map[string]KindCounts, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandResultCounts.
+func (in *CommandResultCounts) DeepCopy() *CommandResultCounts {
+	if in == nil {
+		return nil
+	}
+	out := new(CommandResultCounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommandResultSummary) DeepCopyInto(out *CommandResultSummary) {
 	*out = *in
@@ -232,15 +391,26 @@ func (in *CommandResultSummary) DeepCopyInto(out *CommandResultSummary) {
 	}
 	in.GitInfo.DeepCopyInto(&out.GitInfo)
 	out.ClusterInfo = in.ClusterInfo
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make(map[string]KindCounts, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Errors != nil {
 		in, out := &in.Errors, &out.Errors
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Warnings != nil {
 		in, out := &in.Warnings, &out.Warnings
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -254,6 +424,83 @@ func (in *CommandResultSummary) DeepCopy() *CommandResultSummary {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommandStats) DeepCopyInto(out *CommandStats) {
+	*out = *in
+	out.WallTime = in.WallTime
+	if in.ApiCalls != nil {
+		in, out := &in.ApiCalls, &out.ApiCalls
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FetchedBytes != nil {
+		in, out := &in.FetchedBytes, &out.FetchedBytes
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Caches != nil {
+		in, out := &in.Caches, &out.Caches
+		*out = make(map[string]CacheStats, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandStats.
+func (in *CommandStats) DeepCopy() *CommandStats {
+	if in == nil {
+		return nil
+	}
+	out := new(CommandStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommandTimings) DeepCopyInto(out *CommandTimings) {
+	*out = *in
+	if in.Render != nil {
+		in, out := &in.Render, &out.Render
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Apply != nil {
+		in, out := &in.Apply, &out.Apply
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Prune != nil {
+		in, out := &in.Prune, &out.Prune
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandTimings.
+func (in *CommandTimings) DeepCopy() *CommandTimings {
+	if in == nil {
+		return nil
+	}
+	out := new(CommandTimings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CompactedCommandResult) DeepCopyInto(out *CompactedCommandResult) {
 	*out = *in
@@ -265,6 +512,11 @@ func (in *CompactedCommandResult) DeepCopyInto(out *CompactedCommandResult) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(CommandResultCounts)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompactedCommandResult.
@@ -314,10 +566,55 @@ func (in CompactedObjects) DeepCopy() CompactedObjects {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfirmationDecision) DeepCopyInto(out *ConfirmationDecision) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfirmationDecision.
+func (in *ConfirmationDecision) DeepCopy() *ConfirmationDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfirmationDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfirmationInfo) DeepCopyInto(out *ConfirmationInfo) {
+	*out = *in
+	if in.Decisions != nil {
+		in, out := &in.Decisions, &out.Decisions
+		*out = make([]ConfirmationDecision, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfirmationInfo.
+func (in *ConfirmationInfo) DeepCopy() *ConfirmationInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfirmationInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentError) DeepCopyInto(out *DeploymentError) {
 	*out = *in
 	out.Ref = in.Ref
+	if in.Causes != nil {
+		in, out := &in.Causes, &out.Causes
+		*out = make([]ErrorCause, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deprecation != nil {
+		in, out := &in.Deprecation, &out.Deprecation
+		*out = new(DeprecationInfo)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentError.
@@ -330,6 +627,21 @@ func (in *DeploymentError) DeepCopy() *DeploymentError {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeprecationInfo) DeepCopyInto(out *DeprecationInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeprecationInfo.
+func (in *DeprecationInfo) DeepCopy() *DeprecationInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(DeprecationInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DriftDetectionResult) DeepCopyInto(out *DriftDetectionResult) {
 	*out = *in
@@ -345,12 +657,16 @@ func (in *DriftDetectionResult) DeepCopyInto(out *DriftDetectionResult) {
 	if in.Warnings != nil {
 		in, out := &in.Warnings, &out.Warnings
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Errors != nil {
 		in, out := &in.Errors, &out.Errors
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Objects != nil {
 		in, out := &in.Objects, &out.Objects
@@ -387,6 +703,80 @@ func (in *DriftedObject) DeepCopy() *DriftedObject {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ErrorCause) DeepCopyInto(out *ErrorCause) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ErrorCause.
+func (in *ErrorCause) DeepCopy() *ErrorCause {
+	if in == nil {
+		return nil
+	}
+	out := new(ErrorCause)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FetchTiming) DeepCopyInto(out *FetchTiming) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FetchTiming.
+func (in *FetchTiming) DeepCopy() *FetchTiming {
+	if in == nil {
+		return nil
+	}
+	out := new(FetchTiming)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InvocationInfo) DeepCopyInto(out *InvocationInfo) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InvocationInfo.
+func (in *InvocationInfo) DeepCopy() *InvocationInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(InvocationInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KindCounts) DeepCopyInto(out *KindCounts) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KindCounts.
+func (in *KindCounts) DeepCopy() *KindCounts {
+	if in == nil {
+		return nil
+	}
+	out := new(KindCounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KluctlDeploymentInfo) DeepCopyInto(out *KluctlDeploymentInfo) {
 	*out = *in
@@ -402,6 +792,188 @@ func (in *KluctlDeploymentInfo) DeepCopy() *KluctlDeploymentInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceResourceDelta) DeepCopyInto(out *NamespaceResourceDelta) {
+	*out = *in
+	in.Delta.DeepCopyInto(&out.Delta)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceResourceDelta.
+func (in *NamespaceResourceDelta) DeepCopy() *NamespaceResourceDelta {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceResourceDelta)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectMove) DeepCopyInto(out *ObjectMove) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectMove.
+func (in *ObjectMove) DeepCopy() *ObjectMove {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectMove)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectTimings) DeepCopyInto(out *ObjectTimings) {
+	*out = *in
+	if in.Apply != nil {
+		in, out := &in.Apply, &out.Apply
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.WaitHook != nil {
+		in, out := &in.WaitHook, &out.WaitHook
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.WaitReadiness != nil {
+		in, out := &in.WaitReadiness, &out.WaitReadiness
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTimings.
+func (in *ObjectTimings) DeepCopy() *ObjectTimings {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectTimings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OwnershipConflict) DeepCopyInto(out *OwnershipConflict) {
+	*out = *in
+	out.Ref = in.Ref
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnershipConflict.
+func (in *OwnershipConflict) DeepCopy() *OwnershipConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(OwnershipConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OwnershipMigration) DeepCopyInto(out *OwnershipMigration) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CoOwners != nil {
+		in, out := &in.CoOwners, &out.CoOwners
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnershipMigration.
+func (in *OwnershipMigration) DeepCopy() *OwnershipMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(OwnershipMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Phase) DeepCopyInto(out *Phase) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]k8s.ObjectRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Phase.
+func (in *Phase) DeepCopy() *Phase {
+	if in == nil {
+		return nil
+	}
+	out := new(Phase)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RerunJob) DeepCopyInto(out *RerunJob) {
+	*out = *in
+	out.Ref = in.Ref
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RerunJob.
+func (in *RerunJob) DeepCopy() *RerunJob {
+	if in == nil {
+		return nil
+	}
+	out := new(RerunJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceDelta) DeepCopyInto(out *ResourceDelta) {
+	*out = *in
+	in.Total.DeepCopyInto(&out.Total)
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]NamespaceResourceDelta, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceDelta.
+func (in *ResourceDelta) DeepCopy() *ResourceDelta {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceDelta)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceQuantities) DeepCopyInto(out *ResourceQuantities) {
+	*out = *in
+	out.CpuRequests = in.CpuRequests.DeepCopy()
+	out.CpuLimits = in.CpuLimits.DeepCopy()
+	out.MemoryRequests = in.MemoryRequests.DeepCopy()
+	out.MemoryLimits = in.MemoryLimits.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQuantities.
+func (in *ResourceQuantities) DeepCopy() *ResourceQuantities {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceQuantities)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResultObject) DeepCopyInto(out *ResultObject) {
 	*out = *in
@@ -418,6 +990,10 @@ func (in *ResultObject) DeepCopyInto(out *ResultObject) {
 		in, out := &in.Applied, &out.Applied
 		*out = (*in).DeepCopy()
 	}
+	if in.DeletedManifest != nil {
+		in, out := &in.DeletedManifest, &out.DeletedManifest
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultObject.
@@ -464,12 +1040,16 @@ func (in *ValidateResult) DeepCopyInto(out *ValidateResult) {
 	if in.Warnings != nil {
 		in, out := &in.Warnings, &out.Warnings
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Errors != nil {
 		in, out := &in.Errors, &out.Errors
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Results != nil {
 		in, out := &in.Results, &out.Results
@@ -3,8 +3,11 @@ package types
 import (
 	"fmt"
 	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/go-playground/validator/v10"
@@ -68,3 +71,57 @@ func TestValidateVarsSource(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePruneExcludeRule(t *testing.T) {
+	validate := validator.New()
+	validate.RegisterStructValidation(ValidatePruneExcludeRule, PruneExcludeRule{})
+
+	assert.NoError(t, validate.Struct(&PruneExcludeRule{Group: utils.Ptr("*.cert-manager.io")}))
+	assert.NoError(t, validate.Struct(&PruneExcludeRule{Kind: utils.Ptr("Secret"), Namespace: utils.Ptr("kube-*")}))
+	assert.Error(t, validate.Struct(&PruneExcludeRule{Reason: "no selector"}))
+	assert.Error(t, validate.Struct(&PruneExcludeRule{Kind: utils.Ptr("[")}))
+}
+
+func TestPruneExcludeRuleMatches(t *testing.T) {
+	r := PruneExcludeRule{Group: utils.Ptr("*cert-manager.io")}
+	assert.True(t, r.Matches("cert-manager.io", "Certificate", "default"))
+	assert.True(t, r.Matches("acme.cert-manager.io", "Order", "default"))
+	assert.False(t, r.Matches("apps", "Deployment", "default"))
+
+	// an empty group only matches the core group
+	r = PruneExcludeRule{Group: utils.Ptr(""), Namespace: utils.Ptr("kube-*")}
+	assert.True(t, r.Matches("", "ConfigMap", "kube-system"))
+	assert.False(t, r.Matches("", "ConfigMap", "default"))
+	assert.False(t, r.Matches("apps", "Deployment", "kube-system"))
+}
+
+func TestValidateYamlBundle(t *testing.T) {
+	validate := yaml.Validator
+
+	parse := func(s string) YamlUrl {
+		u, err := url.Parse(s)
+		assert.NoError(t, err)
+		return YamlUrl{URL: *u}
+	}
+	digest := strings.Repeat("ab", 32)
+
+	assert.NoError(t, validate.Struct(&YamlBundleConfig{Urls: []YamlBundleUrl{{Url: parse("https://example.com/install.yaml"), Sha256: digest}}}))
+	assert.ErrorContains(t, validate.Struct(&YamlBundleConfig{}), "Urls")
+	assert.ErrorContains(t, validate.Struct(&YamlBundleUrl{Url: parse("file:///install.yaml"), Sha256: digest}), "is not a http or https url")
+	assert.ErrorContains(t, validate.Struct(&YamlBundleUrl{Url: parse("https://example.com/install.yaml"), Sha256: "abc"}), "is not a valid hex encoded sha256 digest")
+
+	bundle := &YamlBundleConfig{Urls: []YamlBundleUrl{{Url: parse("https://example.com/install.yaml"), Sha256: digest}}}
+	assert.NoError(t, validate.Struct(&DeploymentItemConfig{Path: utils.Ptr("bundle"), YamlBundle: bundle}))
+	assert.ErrorContains(t, validate.Struct(&DeploymentItemConfig{YamlBundle: bundle}), "yamlBundle can only be used together with path")
+}
+
+func TestValidateConfirmationConfig(t *testing.T) {
+	validate := yaml.Validator
+
+	assert.NoError(t, validate.Struct(&ConfirmationConfig{
+		Acknowledge:         []ConfirmationCategory{ConfirmationCategoryOrphanObjects, ConfirmationCategoryWarnings},
+		AbortNonInteractive: []ConfirmationCategory{ConfirmationCategoryOwnershipConflicts, ConfirmationCategoryDeprecations},
+	}))
+	assert.ErrorContains(t, validate.Struct(&ConfirmationConfig{Acknowledge: []ConfirmationCategory{"orphans"}}), "Acknowledge[0]")
+	assert.ErrorContains(t, validate.Struct(&ConfirmationConfig{AbortNonInteractive: []ConfirmationCategory{"errors"}}), "AbortNonInteractive[0]")
+}
@@ -124,12 +124,39 @@ type VarsSourceVault struct {
 	Path    string `json:"path" validate:"required"`
 }
 
+type VarsSourceOnError string
+
+const (
+	VarsSourceOnErrorFail   VarsSourceOnError = "fail"
+	VarsSourceOnErrorWarn   VarsSourceOnError = "warn"
+	VarsSourceOnErrorIgnore VarsSourceOnError = "ignore"
+)
+
+type VarsSourceOnMissingGlobMatch string
+
+const (
+	VarsSourceOnMissingGlobMatchError VarsSourceOnMissingGlobMatch = "error"
+	VarsSourceOnMissingGlobMatchWarn  VarsSourceOnMissingGlobMatch = "warn"
+)
+
 type VarsSource struct {
 	IgnoreMissing *bool `json:"ignoreMissing,omitempty"`
 	NoOverride    *bool `json:"noOverride,omitempty"`
 	Sensitive     *bool `json:"sensitive,omitempty"`
 	Multidoc      *bool `json:"multidoc,omitempty"`
 
+	// Order overrides the merge order of this entry inside its list. Entries are loaded and merged in ascending order,
+	// so entries with a higher order take precedence. Entries with the same order keep their original position.
+	Order *int `json:"order,omitempty"`
+	// OnMissingGlobMatch specifies how a file pattern that matches no files is handled. Can be "error" (the default)
+	// or "warn".
+	OnMissingGlobMatch *VarsSourceOnMissingGlobMatch `json:"onMissingGlobMatch,omitempty" validate:"omitempty,oneof=error warn"`
+
+	// OnError specifies how failures while loading the vars source are handled. Can be "fail" (the default), "warn"
+	// or "ignore". With "warn" and "ignore", loading continues with the vars specified in Default.
+	OnError *VarsSourceOnError     `json:"onError,omitempty" validate:"omitempty,oneof=fail warn ignore"`
+	Default *uo.UnstructuredObject `json:"default,omitempty"`
+
 	Values            *uo.UnstructuredObject              `json:"values,omitempty" isVarsSource:"true"`
 	File              *string                             `json:"file,omitempty" isVarsSource:"true"`
 	Git               *VarsSourceGit                      `json:"git,omitempty" isVarsSource:"true"`
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kluctl/kluctl/lib/yaml"
+)
+
+// YamlBundleConfig configures plain YAML bundles (e.g. an upstream install.yaml) to be fetched at render time
+type YamlBundleConfig struct {
+	Urls []YamlBundleUrl `json:"urls" validate:"required,min=1,dive"`
+	// Template enables rendering of the fetched documents with the templating engine. Disabled by default, as
+	// upstream bundles often contain content that conflicts with Jinja2 syntax.
+	Template bool `json:"template,omitempty"`
+}
+
+type YamlBundleUrl struct {
+	Url YamlUrl `json:"url" validate:"required"`
+	// Sha256 is the hex encoded sha256 digest the fetched content must match
+	Sha256 string `json:"sha256" validate:"required"`
+}
+
+func ValidateYamlBundleUrl(sl validator.StructLevel) {
+	u := sl.Current().Interface().(YamlBundleUrl)
+	if u.Url.Scheme != "http" && u.Url.Scheme != "https" {
+		sl.ReportError(u.Url, "url", "Url", fmt.Sprintf("'%s' is not a http or https url", u.Url.Redacted()), "")
+	}
+	if b, err := hex.DecodeString(u.Sha256); err != nil || len(b) != 32 {
+		sl.ReportError(u.Sha256, "sha256", "Sha256", fmt.Sprintf("'%s' is not a valid hex encoded sha256 digest", u.Sha256), "")
+	}
+}
+
+func init() {
+	yaml.Validator.RegisterStructValidation(ValidateYamlBundleUrl, YamlBundleUrl{})
+}
@@ -23,7 +23,9 @@ package types
 import (
 	gittypes "github.com/kluctl/kluctl/lib/git/types"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -52,6 +54,118 @@ func (in *AwsConfig) DeepCopy() *AwsConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangelogConfig) DeepCopyInto(out *ChangelogConfig) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]ChangelogRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangelogConfig.
+func (in *ChangelogConfig) DeepCopy() *ChangelogConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangelogConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangelogRule) DeepCopyInto(out *ChangelogRule) {
+	*out = *in
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.ChangeType != nil {
+		in, out := &in.ChangeType, &out.ChangeType
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangelogRule.
+func (in *ChangelogRule) DeepCopy() *ChangelogRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangelogRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterResultStoreConfig) DeepCopyInto(out *ClusterResultStoreConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterResultStoreConfig.
+func (in *ClusterResultStoreConfig) DeepCopy() *ClusterResultStoreConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterResultStoreConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapNotificationConfig) DeepCopyInto(out *ConfigMapNotificationConfig) {
+	*out = *in
+	if in.Keep != nil {
+		in, out := &in.Keep, &out.Keep
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapNotificationConfig.
+func (in *ConfigMapNotificationConfig) DeepCopy() *ConfigMapNotificationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapNotificationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfirmationConfig) DeepCopyInto(out *ConfirmationConfig) {
+	*out = *in
+	if in.Acknowledge != nil {
+		in, out := &in.Acknowledge, &out.Acknowledge
+		*out = make([]ConfirmationCategory, len(*in))
+		copy(*out, *in)
+	}
+	if in.AbortNonInteractive != nil {
+		in, out := &in.AbortNonInteractive, &out.AbortNonInteractive
+		*out = make([]ConfirmationCategory, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfirmationConfig.
+func (in *ConfirmationConfig) DeepCopy() *ConfirmationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfirmationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConflictResolutionConfig) DeepCopyInto(out *ConflictResolutionConfig) {
 	*out = *in
@@ -161,6 +275,11 @@ func (in *DeploymentItemConfig) DeepCopyInto(out *DeploymentItemConfig) {
 		*out = new(OciProject)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.YamlBundle != nil {
+		in, out := &in.YamlBundle, &out.YamlBundle
+		*out = new(YamlBundleConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DeleteObjects != nil {
 		in, out := &in.DeleteObjects, &out.DeleteObjects
 		*out = make([]DeleteObjectItemConfig, len(*in))
@@ -185,6 +304,13 @@ func (in *DeploymentItemConfig) DeepCopyInto(out *DeploymentItemConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PostDeployWaits != nil {
+		in, out := &in.PostDeployWaits, &out.PostDeployWaits
+		*out = make([]PostDeployWaitConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Args != nil {
 		in, out := &in.Args, &out.Args
 		*out = (*in).DeepCopy()
@@ -271,6 +397,13 @@ func (in *DeploymentProjectConfig) DeepCopyInto(out *DeploymentProjectConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ListMergeKeys != nil {
+		in, out := &in.ListMergeKeys, &out.ListMergeKeys
+		*out = make([]ListMergeKeysConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ConflictResolution != nil {
 		in, out := &in.ConflictResolution, &out.ConflictResolution
 		*out = make([]ConflictResolutionConfig, len(*in))
@@ -278,6 +411,25 @@ func (in *DeploymentProjectConfig) DeepCopyInto(out *DeploymentProjectConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ObjectOrder != nil {
+		in, out := &in.ObjectOrder, &out.ObjectOrder
+		*out = make([]ObjectOrderConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Obfuscate != nil {
+		in, out := &in.Obfuscate, &out.Obfuscate
+		*out = make([]ObfuscationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ArgoCDCompatibility != nil {
+		in, out := &in.ArgoCDCompatibility, &out.ArgoCDCompatibility
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentProjectConfig.
@@ -290,6 +442,26 @@ func (in *DeploymentProjectConfig) DeepCopy() *DeploymentProjectConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeprecationsConfig) DeepCopyInto(out *DeprecationsConfig) {
+	*out = *in
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeprecationsConfig.
+func (in *DeprecationsConfig) DeepCopy() *DeprecationsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DeprecationsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FixedImage) DeepCopyInto(out *FixedImage) {
 	*out = *in
@@ -442,6 +614,21 @@ func (in *GitFilesRefMatch) DeepCopy() *GitFilesRefMatch {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitLock) DeepCopyInto(out *GitLock) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitLock.
+func (in *GitLock) DeepCopy() *GitLock {
+	if in == nil {
+		return nil
+	}
+	out := new(GitLock)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitProject) DeepCopyInto(out *GitProject) {
 	*out = *in
@@ -524,6 +711,21 @@ func (in *HelmChartConfig2) DeepCopy() *HelmChartConfig2 {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartLock) DeepCopyInto(out *HelmChartLock) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartLock.
+func (in *HelmChartLock) DeepCopy() *HelmChartLock {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartLock)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IgnoreForDiffItemConfig) DeepCopyInto(out *IgnoreForDiffItemConfig) {
 	*out = *in
@@ -569,6 +771,21 @@ func (in *IgnoreForDiffItemConfig) DeepCopy() *IgnoreForDiffItemConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageLock) DeepCopyInto(out *ImageLock) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageLock.
+func (in *ImageLock) DeepCopy() *ImageLock {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageLock)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KluctlLibraryProject) DeepCopyInto(out *KluctlLibraryProject) {
 	*out = *in
@@ -608,11 +825,61 @@ func (in *KluctlProject) DeepCopyInto(out *KluctlProject) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Vars != nil {
+		in, out := &in.Vars, &out.Vars
+		*out = (*in).DeepCopy()
+	}
 	if in.Aws != nil {
 		in, out := &in.Aws, &out.Aws
 		*out = new(AwsConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		*out = new(OutputConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PruneExclude != nil {
+		in, out := &in.PruneExclude, &out.PruneExclude
+		*out = make([]PruneExcludeRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Obfuscate != nil {
+		in, out := &in.Obfuscate, &out.Obfuscate
+		*out = make([]ObfuscationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IgnoreUnservedApiVersions != nil {
+		in, out := &in.IgnoreUnservedApiVersions, &out.IgnoreUnservedApiVersions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeferredKinds != nil {
+		in, out := &in.DeferredKinds, &out.DeferredKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deprecations != nil {
+		in, out := &in.Deprecations, &out.Deprecations
+		*out = new(DeprecationsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]ObjectPatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KluctlProject.
@@ -626,109 +893,695 @@ func (in *KluctlProject) DeepCopy() *KluctlProject {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectRefItem) DeepCopyInto(out *ObjectRefItem) {
+func (in *KubeconfigExecSource) DeepCopyInto(out *KubeconfigExecSource) {
 	*out = *in
-	if in.Group != nil {
-		in, out := &in.Group, &out.Group
-		*out = new(string)
-		**out = **in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Kind != nil {
-		in, out := &in.Kind, &out.Kind
-		*out = new(string)
-		**out = **in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectRefItem.
-func (in *ObjectRefItem) DeepCopy() *ObjectRefItem {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeconfigExecSource.
+func (in *KubeconfigExecSource) DeepCopy() *KubeconfigExecSource {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectRefItem)
+	out := new(KubeconfigExecSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OciProject) DeepCopyInto(out *OciProject) {
+func (in *KubeconfigSecretSource) DeepCopyInto(out *KubeconfigSecretSource) {
 	*out = *in
-	if in.Ref != nil {
-		in, out := &in.Ref, &out.Ref
-		*out = new(OciRef)
+	if in.Context != nil {
+		in, out := &in.Context, &out.Context
+		*out = new(string)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OciProject.
-func (in *OciProject) DeepCopy() *OciProject {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeconfigSecretSource.
+func (in *KubeconfigSecretSource) DeepCopy() *KubeconfigSecretSource {
 	if in == nil {
 		return nil
 	}
-	out := new(OciProject)
+	out := new(KubeconfigSecretSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OciRef) DeepCopyInto(out *OciRef) {
+func (in *KubeconfigSource) DeepCopyInto(out *KubeconfigSource) {
 	*out = *in
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(string)
+		**out = **in
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(KubeconfigSecretSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(KubeconfigExecSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OciRef.
-func (in *OciRef) DeepCopy() *OciRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeconfigSource.
+func (in *KubeconfigSource) DeepCopy() *KubeconfigSource {
 	if in == nil {
 		return nil
 	}
-	out := new(OciRef)
+	out := new(KubeconfigSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceAccountRef) DeepCopyInto(out *ServiceAccountRef) {
+func (in *ListMergeKeysConfig) DeepCopyInto(out *ListMergeKeysConfig) {
 	*out = *in
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountRef.
-func (in *ServiceAccountRef) DeepCopy() *ServiceAccountRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListMergeKeysConfig.
+func (in *ListMergeKeysConfig) DeepCopy() *ListMergeKeysConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceAccountRef)
+	out := new(ListMergeKeysConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in SingleStringOrList) DeepCopyInto(out *SingleStringOrList) {
-	{
-		in := &in
-		*out = make(SingleStringOrList, len(*in))
-		copy(*out, *in)
+func (in *LockFile) DeepCopyInto(out *LockFile) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make(map[string]ProjectLock, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SingleStringOrList.
-func (in SingleStringOrList) DeepCopy() SingleStringOrList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LockFile.
+func (in *LockFile) DeepCopy() *LockFile {
 	if in == nil {
 		return nil
 	}
-	out := new(SingleStringOrList)
+	out := new(LockFile)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Target) DeepCopyInto(out *Target) {
+func (in *NotificationsConfig) DeepCopyInto(out *NotificationsConfig) {
 	*out = *in
-	if in.Context != nil {
-		in, out := &in.Context, &out.Context
-		*out = new(string)
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SlackNotificationConfig)
 		**out = **in
 	}
-	if in.Args != nil {
-		in, out := &in.Args, &out.Args
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ConfigMapNotificationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationsConfig.
+func (in *NotificationsConfig) DeepCopy() *NotificationsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObfuscationRule) DeepCopyInto(out *ObfuscationRule) {
+	*out = *in
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.FieldPath != nil {
+		in, out := &in.FieldPath, &out.FieldPath
+		*out = make(SingleStringOrList, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObfuscationRule.
+func (in *ObfuscationRule) DeepCopy() *ObfuscationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ObfuscationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectOrderConfig) DeepCopyInto(out *ObjectOrderConfig) {
+	*out = *in
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectOrderConfig.
+func (in *ObjectOrderConfig) DeepCopy() *ObjectOrderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectOrderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectPatch) DeepCopyInto(out *ObjectPatch) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	if in.Patch != nil {
+		in, out := &in.Patch, &out.Patch
+		*out = (*in).DeepCopy()
+	}
+	if in.JsonPatch != nil {
+		in, out := &in.JsonPatch, &out.JsonPatch
+		*out = make([]*uo.UnstructuredObject, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = (*in).DeepCopy()
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectPatch.
+func (in *ObjectPatch) DeepCopy() *ObjectPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectPatchSelector) DeepCopyInto(out *ObjectPatchSelector) {
+	*out = *in
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectPatchSelector.
+func (in *ObjectPatchSelector) DeepCopy() *ObjectPatchSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectPatchSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectRefItem) DeepCopyInto(out *ObjectRefItem) {
+	*out = *in
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectRefItem.
+func (in *ObjectRefItem) DeepCopy() *ObjectRefItem {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectRefItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OciLock) DeepCopyInto(out *OciLock) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OciLock.
+func (in *OciLock) DeepCopy() *OciLock {
+	if in == nil {
+		return nil
+	}
+	out := new(OciLock)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OciProject) DeepCopyInto(out *OciProject) {
+	*out = *in
+	if in.Ref != nil {
+		in, out := &in.Ref, &out.Ref
+		*out = new(OciRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OciProject.
+func (in *OciProject) DeepCopy() *OciProject {
+	if in == nil {
+		return nil
+	}
+	out := new(OciProject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OciRef) DeepCopyInto(out *OciRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OciRef.
+func (in *OciRef) DeepCopy() *OciRef {
+	if in == nil {
+		return nil
+	}
+	out := new(OciRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputConfig) DeepCopyInto(out *OutputConfig) {
+	*out = *in
+	if in.Formats != nil {
+		in, out := &in.Formats, &out.Formats
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ShortOutput != nil {
+		in, out := &in.ShortOutput, &out.ShortOutput
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NoObfuscate != nil {
+		in, out := &in.NoObfuscate, &out.NoObfuscate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Changelog != nil {
+		in, out := &in.Changelog, &out.Changelog
+		*out = new(ChangelogConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecuritySensitiveKinds != nil {
+		in, out := &in.SecuritySensitiveKinds, &out.SecuritySensitiveKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputConfig.
+func (in *OutputConfig) DeepCopy() *OutputConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostDeployWaitConfig) DeepCopyInto(out *PostDeployWaitConfig) {
+	*out = *in
+	in.ObjectRefItem.DeepCopyInto(&out.ObjectRefItem)
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DnsLookups != nil {
+		in, out := &in.DnsLookups, &out.DnsLookups
+		*out = make([]PostDeployWaitDnsLookupConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostDeployWaitConfig.
+func (in *PostDeployWaitConfig) DeepCopy() *PostDeployWaitConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PostDeployWaitConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostDeployWaitDnsLookupConfig) DeepCopyInto(out *PostDeployWaitDnsLookupConfig) {
+	*out = *in
+	if in.AddressesFieldPath != nil {
+		in, out := &in.AddressesFieldPath, &out.AddressesFieldPath
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostDeployWaitDnsLookupConfig.
+func (in *PostDeployWaitDnsLookupConfig) DeepCopy() *PostDeployWaitDnsLookupConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PostDeployWaitDnsLookupConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectLock) DeepCopyInto(out *ProjectLock) {
+	*out = *in
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = make([]GitLock, len(*in))
+		copy(*out, *in)
+	}
+	if in.Oci != nil {
+		in, out := &in.Oci, &out.Oci
+		*out = make([]OciLock, len(*in))
+		copy(*out, *in)
+	}
+	if in.HelmCharts != nil {
+		in, out := &in.HelmCharts, &out.HelmCharts
+		*out = make([]HelmChartLock, len(*in))
+		copy(*out, *in)
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]ImageLock, len(*in))
+		copy(*out, *in)
+	}
+	if in.VarsSources != nil {
+		in, out := &in.VarsSources, &out.VarsSources
+		*out = make([]VarsSourceLock, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectLock.
+func (in *ProjectLock) DeepCopy() *ProjectLock {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectLock)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PruneExcludeRule) DeepCopyInto(out *PruneExcludeRule) {
+	*out = *in
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PruneExcludeRule.
+func (in *PruneExcludeRule) DeepCopy() *PruneExcludeRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PruneExcludeRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultStoreConfig) DeepCopyInto(out *ResultStoreConfig) {
+	*out = *in
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(ClusterResultStoreConfig)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3ResultStoreConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultStoreConfig.
+func (in *ResultStoreConfig) DeepCopy() *ResultStoreConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultStoreConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultsConfig) DeepCopyInto(out *ResultsConfig) {
+	*out = *in
+	if in.Stores != nil {
+		in, out := &in.Stores, &out.Stores
+		*out = make([]ResultStoreConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultsConfig.
+func (in *ResultsConfig) DeepCopy() *ResultsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ResultStoreConfig) DeepCopyInto(out *S3ResultStoreConfig) {
+	*out = *in
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
+	if in.Profile != nil {
+		in, out := &in.Profile, &out.Profile
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ResultStoreConfig.
+func (in *S3ResultStoreConfig) DeepCopy() *S3ResultStoreConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ResultStoreConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SafetyThresholdConfig) DeepCopyInto(out *SafetyThresholdConfig) {
+	*out = *in
+	if in.MaxChangedObjects != nil {
+		in, out := &in.MaxChangedObjects, &out.MaxChangedObjects
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxDeletedObjects != nil {
+		in, out := &in.MaxDeletedObjects, &out.MaxDeletedObjects
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SafetyThresholdConfig.
+func (in *SafetyThresholdConfig) DeepCopy() *SafetyThresholdConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SafetyThresholdConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountRef) DeepCopyInto(out *ServiceAccountRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountRef.
+func (in *ServiceAccountRef) DeepCopy() *ServiceAccountRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccountRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in SingleStringOrList) DeepCopyInto(out *SingleStringOrList) {
+	{
+		in := &in
+		*out = make(SingleStringOrList, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SingleStringOrList.
+func (in SingleStringOrList) DeepCopy() SingleStringOrList {
+	if in == nil {
+		return nil
+	}
+	out := new(SingleStringOrList)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackNotificationConfig) DeepCopyInto(out *SlackNotificationConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackNotificationConfig.
+func (in *SlackNotificationConfig) DeepCopy() *SlackNotificationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackNotificationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Target) DeepCopyInto(out *Target) {
+	*out = *in
+	if in.Context != nil {
+		in, out := &in.Context, &out.Context
+		*out = new(string)
+		**out = **in
+	}
+	if in.KubeconfigFrom != nil {
+		in, out := &in.KubeconfigFrom, &out.KubeconfigFrom
+		*out = new(KubeconfigSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
 		*out = (*in).DeepCopy()
 	}
 	if in.Aws != nil {
@@ -743,6 +1596,31 @@ func (in *Target) DeepCopyInto(out *Target) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		*out = new(OutputConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = new(ResultsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SafetyThreshold != nil {
+		in, out := &in.SafetyThreshold, &out.SafetyThreshold
+		*out = new(SafetyThresholdConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Confirmation != nil {
+		in, out := &in.Confirmation, &out.Confirmation
+		*out = new(ConfirmationConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Target.
@@ -793,6 +1671,25 @@ func (in *VarsSource) DeepCopyInto(out *VarsSource) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Order != nil {
+		in, out := &in.Order, &out.Order
+		*out = new(int)
+		**out = **in
+	}
+	if in.OnMissingGlobMatch != nil {
+		in, out := &in.OnMissingGlobMatch, &out.OnMissingGlobMatch
+		*out = new(VarsSourceOnMissingGlobMatch)
+		**out = **in
+	}
+	if in.OnError != nil {
+		in, out := &in.OnError, &out.OnError
+		*out = new(VarsSourceOnError)
+		**out = **in
+	}
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = (*in).DeepCopy()
+	}
 	if in.Values != nil {
 		in, out := &in.Values, &out.Values
 		*out = (*in).DeepCopy()
@@ -1041,6 +1938,21 @@ func (in *VarsSourceHttp) DeepCopy() *VarsSourceHttp {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceLock) DeepCopyInto(out *VarsSourceLock) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceLock.
+func (in *VarsSourceLock) DeepCopy() *VarsSourceLock {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceLock)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceVault) DeepCopyInto(out *VarsSourceVault) {
 	*out = *in
@@ -1072,6 +1984,44 @@ func (in *WaitReadinessObjectItemConfig) DeepCopy() *WaitReadinessObjectItemConf
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YamlBundleConfig) DeepCopyInto(out *YamlBundleConfig) {
+	*out = *in
+	if in.Urls != nil {
+		in, out := &in.Urls, &out.Urls
+		*out = make([]YamlBundleUrl, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YamlBundleConfig.
+func (in *YamlBundleConfig) DeepCopy() *YamlBundleConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(YamlBundleConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YamlBundleUrl) DeepCopyInto(out *YamlBundleUrl) {
+	*out = *in
+	in.Url.DeepCopyInto(&out.Url)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YamlBundleUrl.
+func (in *YamlBundleUrl) DeepCopy() *YamlBundleUrl {
+	if in == nil {
+		return nil
+	}
+	out := new(YamlBundleUrl)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YamlUrl.
 func (in *YamlUrl) DeepCopy() *YamlUrl {
 	if in == nil {
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"context"
+	"slices"
+)
+
+type debugTopicsKey struct{}
+
+// WithDebugTopics returns a context with the given debug topics enabled, e.g. via '--debug=vars'
+func WithDebugTopics(ctx context.Context, topics []string) context.Context {
+	return context.WithValue(ctx, debugTopicsKey{}, topics)
+}
+
+// IsDebugTopicEnabled returns true if the given debug topic was enabled
+func IsDebugTopicEnabled(ctx context.Context, topic string) bool {
+	v, _ := ctx.Value(debugTopicsKey{}).([]string)
+	return slices.Contains(v, topic)
+}
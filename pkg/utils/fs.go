@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"io/fs"
 	"k8s.io/client-go/util/homedir"
 	"os"
 	"path/filepath"
@@ -34,6 +35,25 @@ func IsDirectory(path string) bool {
 	return fileInfo.IsDir()
 }
 
+// DirSize returns the summed up size of all regular files inside dir
+func DirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
 func CheckInDir(root string, path string) error {
 	absRoot, err := filepath.Abs(filepath.Clean(root))
 	if err != nil {
@@ -0,0 +1,12 @@
+package utils
+
+import (
+	"net/http"
+)
+
+// NewHttpTransport returns a new transport that honors the proxy configuration from the environment (HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY) and uses the system CA pool (which honors SSL_CERT_FILE and SSL_CERT_DIR). All HTTP
+// clients used to talk to user provided endpoints should be based on this transport.
+func NewHttpTransport() *http.Transport {
+	return http.DefaultTransport.(*http.Transport).Clone()
+}
@@ -23,43 +23,115 @@ func (t *PrettyTable) SortRows(col int) {
 	})
 }
 
-func (t *PrettyTable) Render(limitWidths []int) string {
-	cols := len(t.rows[0])
+// ansiSequenceEnd returns the end of the ANSI escape sequence starting at i or -1 if there is none
+func ansiSequenceEnd(s string, i int) int {
+	if i+1 >= len(s) || s[i] != '\x1b' || s[i+1] != '[' {
+		return -1
+	}
+	for j := i + 2; j < len(s); j++ {
+		if s[j] >= 0x40 && s[j] <= 0x7e {
+			return j + 1
+		}
+	}
+	return -1
+}
 
-	maxWidth := func(col int, maxW int) int {
-		w := 0
-		for _, l := range t.rows {
-			for _, cl := range strings.Split(l[col], "\n") {
-				if len(cl) > w {
-					w = len(cl)
-				}
+// visibleLen returns the length of s without ANSI escape sequences
+func visibleLen(s string) int {
+	l := 0
+	for i := 0; i < len(s); {
+		if e := ansiSequenceEnd(s, i); e != -1 {
+			i = e
+			continue
+		}
+		l++
+		i++
+	}
+	return l
+}
+
+// wrapCell splits the cell content into lines of at most width visible characters. ANSI escape sequences are not
+// counted and styles that are active at the end of a wrapped line are reset and then restored on the next line, so
+// that the table borders are never styled.
+func wrapCell(s string, width int) []string {
+	if s == "" {
+		return nil
+	}
+	if width < 1 {
+		width = 1
+	}
+	s = strings.TrimSuffix(s, "\n")
+
+	var ret []string
+	for _, line := range strings.Split(s, "\n") {
+		active := ""
+		cur := &strings.Builder{}
+		curLen := 0
+		flush := func() {
+			if active != "" {
+				cur.WriteString("\x1b[0m")
 			}
+			ret = append(ret, cur.String())
+			cur.Reset()
+			cur.WriteString(active)
+			curLen = 0
 		}
-		if maxW != -1 {
-			if maxW < w {
-				w = maxW
+		for i := 0; i < len(line); {
+			if e := ansiSequenceEnd(line, i); e != -1 {
+				seq := line[i:e]
+				if seq == "\x1b[0m" || seq == "\x1b[m" {
+					active = ""
+				} else if strings.HasSuffix(seq, "m") {
+					active += seq
+				}
+				cur.WriteString(seq)
+				i = e
+				continue
 			}
+			if curLen == width {
+				flush()
+			}
+			cur.WriteByte(line[i])
+			curLen++
+			i++
 		}
-		return w
-	}
-	subStr := func(str string, s int, e int) string {
-		if s > len(str) {
-			s = len(str)
+		if active != "" {
+			cur.WriteString("\x1b[0m")
 		}
-		if e > len(str) {
-			e = len(str)
+		ret = append(ret, cur.String())
+	}
+	return ret
+}
+
+func (t *PrettyTable) naturalWidth(col int) int {
+	w := 0
+	for _, l := range t.rows {
+		for _, cl := range strings.Split(l[col], "\n") {
+			if vl := visibleLen(cl); vl > w {
+				w = vl
+			}
 		}
-		return str[s:e]
 	}
+	return w
+}
+
+// tableOverhead returns the number of characters used by borders and padding of a table with the given number of
+// columns
+func tableOverhead(cols int) int {
+	return (cols-1)*3 + 4
+}
+
+func (t *PrettyTable) Render(limitWidths []int) string {
+	cols := len(t.rows[0])
 
 	widths := make([]int, cols)
 	widthSum := 0
 	for i := 0; i < cols; i++ {
-		w := -1
-		if i < len(limitWidths) {
+		w := t.naturalWidth(i)
+		if i < len(limitWidths) && limitWidths[i] != -1 && limitWidths[i] < w {
 			w = limitWidths[i]
 		}
-		widths[i] = maxWidth(i, w)
+		widths[i] = w
 		if i != cols-1 {
 			widthSum += widths[i]
 		}
@@ -68,13 +140,93 @@ func (t *PrettyTable) Render(limitWidths []int) string {
 	if len(limitWidths) < cols {
 		tw := term.GetWidth()
 		// last column should use all remaining space
-		tw = tw - widthSum - (cols-1)*3 - 4
+		tw = tw - widthSum - tableOverhead(cols)
 		if tw <= 0 {
 			tw = 1
 		}
 		widths[len(limitWidths)] = tw
 	}
 
+	return t.renderWithWidths(widths)
+}
+
+// RenderFit renders the table so that it fits into the given total width. If the natural widths of all columns fit,
+// the last column is extended to use the remaining space. Otherwise, columns that are narrower than their fair share
+// keep their natural width and the remaining space is distributed between the other columns, proportionally to their
+// natural widths.
+func (t *PrettyTable) RenderFit(width int) string {
+	cols := len(t.rows[0])
+	available := width - tableOverhead(cols)
+
+	widths := make([]int, cols)
+	naturalSum := 0
+	for i := 0; i < cols; i++ {
+		widths[i] = t.naturalWidth(i)
+		naturalSum += widths[i]
+	}
+
+	if naturalSum <= available {
+		widths[cols-1] += available - naturalSum
+		return t.renderWithWidths(widths)
+	}
+
+	fixed := make([]bool, cols)
+	remaining := available
+	for {
+		flexibleCols := 0
+		for i := 0; i < cols; i++ {
+			if !fixed[i] {
+				flexibleCols++
+			}
+		}
+		if flexibleCols == 0 {
+			break
+		}
+		fairShare := remaining / flexibleCols
+		changed := false
+		for i := 0; i < cols; i++ {
+			if !fixed[i] && widths[i] <= fairShare {
+				fixed[i] = true
+				remaining -= widths[i]
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	flexibleSum := 0
+	lastFlexible := -1
+	for i := 0; i < cols; i++ {
+		if !fixed[i] {
+			flexibleSum += widths[i]
+			lastFlexible = i
+		}
+	}
+	if lastFlexible != -1 {
+		used := 0
+		for i := 0; i < cols; i++ {
+			if fixed[i] {
+				continue
+			}
+			if i == lastFlexible {
+				widths[i] = remaining - used
+			} else {
+				widths[i] = remaining * widths[i] / flexibleSum
+			}
+			if widths[i] < 1 {
+				widths[i] = 1
+			}
+			used += widths[i]
+		}
+	}
+	return t.renderWithWidths(widths)
+}
+
+func (t *PrettyTable) renderWithWidths(widths []int) string {
+	cols := len(widths)
+
 	hsep := "+-"
 	for i := 0; i < cols; i++ {
 		hsep += strings.Repeat("-", widths[i])
@@ -86,34 +238,25 @@ func (t *PrettyTable) Render(limitWidths []int) string {
 
 	buf := bytes.NewBuffer(nil)
 	buf.WriteString(hsep)
-	pos := make([]int, cols)
+	cellLines := make([][]string, cols)
 	for _, l := range t.rows {
+		height := 0
 		for i := 0; i < cols; i++ {
-			pos[i] = 0
-		}
-
-		for {
-			anyLess := false
-			for i := 0; i < cols; i++ {
-				if pos[i] < len(l[i]) {
-					anyLess = true
-				}
-			}
-			if !anyLess {
-				break
+			cellLines[i] = wrapCell(l[i], widths[i])
+			if len(cellLines[i]) > height {
+				height = len(cellLines[i])
 			}
+		}
 
+		for j := 0; j < height; j++ {
 			buf.WriteString("| ")
 			for i := 0; i < cols; i++ {
-				x := subStr(l[i], pos[i], pos[i]+widths[i])
-				newLine := strings.IndexRune(x, '\n')
-				if newLine != -1 {
-					x = x[:newLine]
-					pos[i] += 1
+				x := ""
+				if j < len(cellLines[i]) {
+					x = cellLines[i][j]
 				}
-				pos[i] += len(x)
 				buf.WriteString(x)
-				buf.WriteString(strings.Repeat(" ", widths[i]-len(x)))
+				buf.WriteString(strings.Repeat(" ", widths[i]-visibleLen(x)))
 				if i != cols-1 {
 					buf.WriteString(" | ")
 				}
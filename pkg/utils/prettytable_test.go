@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrettyTableRender(t *testing.T) {
+	var table PrettyTable
+	table.AddRow("a", "b")
+	table.AddRow("long value", "x\ny")
+	assert.Equal(t, `+------------+---+
+| a          | b |
++------------+---+
+| long value | x |
+|            | y |
++------------+---+
+`, table.Render([]int{-1, -1}))
+
+	assert.Equal(t, `+------+---+
+| a    | b |
++------+---+
+| long | x |
+|  val | y |
+| ue   |   |
++------+---+
+`, table.Render([]int{4, -1}))
+}
+
+func TestPrettyTableRenderAnsi(t *testing.T) {
+	const red = "\x1b[31m"
+	const reset = "\x1b[0m"
+
+	var table PrettyTable
+	table.AddRow("a", "b")
+	table.AddRow(red+"abcdef"+reset, "x")
+
+	// escape sequences don't count into the column width
+	assert.Equal(t, `+--------+---+
+| a      | b |
++--------+---+
+| `+red+`abcdef`+reset+` | x |
++--------+---+
+`, table.Render([]int{-1, -1}))
+
+	// wrapped lines restore the active style
+	assert.Equal(t, `+------+---+
+| a    | b |
++------+---+
+| `+red+`abcd`+reset+` | x |
+| `+red+`ef`+reset+`   |   |
++------+---+
+`, table.Render([]int{4, -1}))
+}
+
+func TestVisibleLen(t *testing.T) {
+	assert.Equal(t, 3, visibleLen("abc"))
+	assert.Equal(t, 3, visibleLen("\x1b[1;32mabc\x1b[0m"))
+	assert.Equal(t, 0, visibleLen(""))
+}
+
+func TestPrettyTableRenderFit(t *testing.T) {
+	var table PrettyTable
+	table.AddRow("a", "b")
+	table.AddRow("path", "x")
+
+	// the last column uses the remaining space
+	assert.Equal(t, `+------+-------+
+| a    | b     |
++------+-------+
+| path | x     |
++------+-------+
+`, table.RenderFit(16))
+
+	table = PrettyTable{}
+	table.AddRow("abcdefgh", "0123456789abcdef")
+
+	// columns are shrunk proportionally to their natural widths
+	assert.Equal(t, `+------+----------+
+| abcd | 01234567 |
+| efgh | 89abcdef |
++------+----------+
+`, table.RenderFit(19))
+
+	// columns narrower than their fair share keep their natural width
+	assert.Equal(t, `+----------+----------+
+| abcdefgh | 01234567 |
+|          | 89abcdef |
++----------+----------+
+`, table.RenderFit(23))
+}
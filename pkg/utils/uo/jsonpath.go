@@ -14,8 +14,12 @@ type KeyPath []interface{}
 
 func keyPathFromJsonPath(e jp.Expr) (KeyPath, error) {
 	ret := make(KeyPath, 0, len(e))
-	for _, f := range e {
+	for i, f := range e {
 		switch tf := f.(type) {
+		case jp.Root:
+			if i != 0 {
+				return nil, fmt.Errorf("unexpected root element in jsonpath: path=%s", e.String())
+			}
 		case jp.Child:
 			ret = append(ret, string(tf))
 		case jp.Nth:
@@ -0,0 +1,208 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// PostDeployWaitsKey is used as annotation in result entries that were produced by post-deploy waits
+const PostDeployWaitsKey = "postDeployWaits"
+
+// lookupHost is overridden in tests
+var lookupHost = net.DefaultResolver.LookupHost
+
+// PostDeployWaitResult is the outcome of a single evaluation of a post-deploy wait
+type PostDeployWaitResult struct {
+	// Fulfilled is true if all fields, conditions and DNS lookups of the wait are fulfilled
+	Fulfilled bool
+	// Observed contains the values observed while evaluating the wait, e.g. field values and resolved addresses
+	Observed []string
+	// Reason describes why the wait is not fulfilled
+	Reason string
+}
+
+func (r *PostDeployWaitResult) ObservedString() string {
+	if len(r.Observed) == 0 {
+		return "-"
+	}
+	return strings.Join(r.Observed, ", ")
+}
+
+func (r *PostDeployWaitResult) notFulfilled(reason string, args ...any) {
+	if r.Fulfilled {
+		r.Fulfilled = false
+		r.Reason = fmt.Sprintf(reason, args...)
+	}
+}
+
+func isEmptyValue(v any) bool {
+	switch v2 := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v2 == ""
+	case []any:
+		return len(v2) == 0
+	case map[string]any:
+		return len(v2) == 0
+	}
+	return false
+}
+
+func getStringValues(o *uo.UnstructuredObject, p string) ([]string, error) {
+	jp, err := uo.NewMyJsonPath(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field path %s: %w", p, err)
+	}
+	var ret []string
+	for _, v := range jp.Get(o) {
+		if isEmptyValue(v) {
+			continue
+		}
+		if l, ok := v.([]any); ok {
+			for _, x := range l {
+				if !isEmptyValue(x) {
+					ret = append(ret, fmt.Sprint(x))
+				}
+			}
+		} else {
+			ret = append(ret, fmt.Sprint(v))
+		}
+	}
+	return ret, nil
+}
+
+// EvaluatePostDeployWait evaluates the given post-deploy wait against the given object. A nil object is treated as not
+// existing. An error is only returned for invalid configurations, unfulfilled waits are reported via the result.
+func EvaluatePostDeployWait(ctx context.Context, o *uo.UnstructuredObject, c *types.PostDeployWaitConfig) (*PostDeployWaitResult, error) {
+	ret := &PostDeployWaitResult{Fulfilled: true}
+	if o == nil {
+		ret.notFulfilled("object does not exist")
+		return ret, nil
+	}
+
+	for _, p := range c.Fields {
+		values, err := getStringValues(o, p)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			ret.Observed = append(ret.Observed, fmt.Sprintf("%s=<empty>", p))
+			ret.notFulfilled("field %s is empty", p)
+		} else {
+			ret.Observed = append(ret.Observed, fmt.Sprintf("%s=%s", p, strings.Join(values, ",")))
+		}
+	}
+
+	conditions, _, _ := o.GetNestedObjectList("status", "conditions")
+	for _, ct := range c.Conditions {
+		s := "<missing>"
+		for _, x := range conditions {
+			t, _, _ := x.GetNestedString("type")
+			if t == ct {
+				s, _, _ = x.GetNestedString("status")
+				break
+			}
+		}
+		ret.Observed = append(ret.Observed, fmt.Sprintf("%s=%s", ct, s))
+		if s != "True" {
+			ret.notFulfilled("condition %s is not True", ct)
+		}
+	}
+
+	for _, l := range c.DnsLookups {
+		hosts, err := getStringValues(o, l.HostsFieldPath)
+		if err != nil {
+			return nil, err
+		}
+		var expectedAddresses []string
+		if l.AddressesFieldPath != nil {
+			expectedAddresses, err = getStringValues(o, *l.AddressesFieldPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(hosts) == 0 {
+			ret.notFulfilled("no hosts found at %s", l.HostsFieldPath)
+			continue
+		}
+		for _, h := range hosts {
+			addrs, err := lookupHost(ctx, h)
+			if err != nil {
+				ret.Observed = append(ret.Observed, fmt.Sprintf("%s -> <unresolved>", h))
+				ret.notFulfilled("DNS lookup for %s failed: %s", h, err.Error())
+				continue
+			}
+			ret.Observed = append(ret.Observed, fmt.Sprintf("%s -> %s", h, strings.Join(addrs, ",")))
+			if l.AddressesFieldPath == nil {
+				continue
+			}
+			if len(expectedAddresses) == 0 {
+				ret.notFulfilled("no addresses found at %s", *l.AddressesFieldPath)
+				continue
+			}
+			found := false
+			for _, a := range addrs {
+				if slices.Contains(expectedAddresses, a) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				ret.notFulfilled("%s does not resolve to any of %s", h, strings.Join(expectedAddresses, ","))
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// ValidatePostDeployWait evaluates the given post-deploy wait once and converts the outcome into a validation result.
+// An unfulfilled wait marks the result as not ready (unless the severity is warning) and is additionally reported as an
+// error or warning (depending on the configured severity) if notReadyIsError is true.
+func ValidatePostDeployWait(ctx context.Context, ref k8s2.ObjectRef, o *uo.UnstructuredObject, c *types.PostDeployWaitConfig, notReadyIsError bool) (ret result.ValidateResult) {
+	ret.Ready = true
+
+	r, err := EvaluatePostDeployWait(ctx, o, c)
+	if err != nil {
+		ret.Ready = false
+		ret.Errors = append(ret.Errors, result.DeploymentError{Ref: ref, Message: fmt.Sprintf("invalid post-deploy wait: %s", err.Error())})
+		return
+	}
+
+	if r.Fulfilled {
+		ret.Results = append(ret.Results, result.ValidateResultEntry{
+			Ref:        ref,
+			Annotation: PostDeployWaitsKey,
+			Message:    fmt.Sprintf("post-deploy wait fulfilled: %s", r.ObservedString()),
+		})
+		return
+	}
+
+	msg := fmt.Sprintf("post-deploy wait not fulfilled: %s (observed: %s)", r.Reason, r.ObservedString())
+	// waits with severity warning never block readiness
+	ret.Ready = c.Severity == types.PostDeployWaitSeverityWarning
+	ret.Results = append(ret.Results, result.ValidateResultEntry{
+		Ref:        ref,
+		Annotation: PostDeployWaitsKey,
+		Message:    msg,
+	})
+	if notReadyIsError {
+		e := result.DeploymentError{Ref: ref, Message: msg}
+		if c.Severity == types.PostDeployWaitSeverityWarning {
+			ret.Warnings = append(ret.Warnings, e)
+		} else {
+			ret.Errors = append(ret.Errors, e)
+		}
+	}
+	return
+}
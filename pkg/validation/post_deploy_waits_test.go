@@ -0,0 +1,152 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPostDeployWaitTestObject() *uo.UnstructuredObject {
+	return uo.FromStringMust(`
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: ing
+  namespace: ns
+spec:
+  rules:
+  - host: app.example.com
+  - host: api.example.com
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+  - type: Synced
+    status: "False"
+  loadBalancer:
+    ingress:
+    - ip: 10.0.0.1
+`)
+}
+
+func TestEvaluatePostDeployWait(t *testing.T) {
+	oldLookupHost := lookupHost
+	defer func() { lookupHost = oldLookupHost }()
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		switch host {
+		case "app.example.com", "api.example.com":
+			return []string{"10.0.0.1"}, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+
+	strPtr := func(s string) *string { return &s }
+
+	type testCase struct {
+		name      string
+		c         types.PostDeployWaitConfig
+		fulfilled bool
+		observed  []string
+		err       bool
+	}
+
+	tests := []testCase{
+		{name: "field", c: types.PostDeployWaitConfig{
+			Fields: []string{"status.loadBalancer.ingress[*].ip"},
+		}, fulfilled: true, observed: []string{"status.loadBalancer.ingress[*].ip=10.0.0.1"}},
+		{name: "field-empty", c: types.PostDeployWaitConfig{
+			Fields: []string{"status.loadBalancer.ingress[*].hostname"},
+		}, fulfilled: false, observed: []string{"status.loadBalancer.ingress[*].hostname=<empty>"}},
+		{name: "condition", c: types.PostDeployWaitConfig{
+			Conditions: []string{"Ready"},
+		}, fulfilled: true, observed: []string{"Ready=True"}},
+		{name: "condition-false", c: types.PostDeployWaitConfig{
+			Conditions: []string{"Ready", "Synced", "Missing"},
+		}, fulfilled: false, observed: []string{"Ready=True", "Synced=False", "Missing=<missing>"}},
+		{name: "dns", c: types.PostDeployWaitConfig{
+			DnsLookups: []types.PostDeployWaitDnsLookupConfig{{
+				HostsFieldPath:     "spec.rules[*].host",
+				AddressesFieldPath: strPtr("status.loadBalancer.ingress[*].ip"),
+			}},
+		}, fulfilled: true, observed: []string{"app.example.com -> 10.0.0.1", "api.example.com -> 10.0.0.1"}},
+		{name: "dns-no-hosts", c: types.PostDeployWaitConfig{
+			DnsLookups: []types.PostDeployWaitDnsLookupConfig{{
+				HostsFieldPath: "spec.tls[*].hosts",
+			}},
+		}, fulfilled: false},
+		{name: "invalid-path", c: types.PostDeployWaitConfig{
+			Fields: []string{"status..["},
+		}, err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := EvaluatePostDeployWait(context.Background(), newPostDeployWaitTestObject(), &tc.c)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.fulfilled, r.Fulfilled)
+			if tc.observed != nil {
+				assert.Equal(t, tc.observed, r.Observed)
+			}
+			if !tc.fulfilled {
+				assert.NotEmpty(t, r.Reason)
+			}
+		})
+	}
+}
+
+func TestEvaluatePostDeployWaitDnsMismatch(t *testing.T) {
+	oldLookupHost := lookupHost
+	defer func() { lookupHost = oldLookupHost }()
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		return []string{"10.0.0.2"}, nil
+	}
+
+	o := newPostDeployWaitTestObject()
+	addresses := "status.loadBalancer.ingress[*].ip"
+	r, err := EvaluatePostDeployWait(context.Background(), o, &types.PostDeployWaitConfig{
+		DnsLookups: []types.PostDeployWaitDnsLookupConfig{{
+			HostsFieldPath:     "spec.rules[0].host",
+			AddressesFieldPath: &addresses,
+		}},
+	})
+	assert.NoError(t, err)
+	assert.False(t, r.Fulfilled)
+	assert.Equal(t, "app.example.com does not resolve to any of 10.0.0.1", r.Reason)
+	assert.Equal(t, []string{"app.example.com -> 10.0.0.2"}, r.Observed)
+}
+
+func TestValidatePostDeployWait(t *testing.T) {
+	o := newPostDeployWaitTestObject()
+	ref := o.GetK8sRef()
+
+	r := ValidatePostDeployWait(context.Background(), ref, o, &types.PostDeployWaitConfig{Conditions: []string{"Ready"}}, true)
+	assert.True(t, r.Ready)
+	assert.Empty(t, r.Errors)
+	assert.Len(t, r.Results, 1)
+
+	r = ValidatePostDeployWait(context.Background(), ref, o, &types.PostDeployWaitConfig{Conditions: []string{"Synced"}}, true)
+	assert.False(t, r.Ready)
+	assert.Len(t, r.Errors, 1)
+	assert.Len(t, r.Results, 1)
+
+	r = ValidatePostDeployWait(context.Background(), ref, o, &types.PostDeployWaitConfig{
+		Conditions: []string{"Synced"},
+		Severity:   types.PostDeployWaitSeverityWarning,
+	}, true)
+	assert.True(t, r.Ready)
+	assert.Empty(t, r.Errors)
+	assert.Len(t, r.Warnings, 1)
+
+	r = ValidatePostDeployWait(context.Background(), ref, nil, &types.PostDeployWaitConfig{Conditions: []string{"Ready"}}, false)
+	assert.False(t, r.Ready)
+	assert.Empty(t, r.Errors)
+	assert.Len(t, r.Results, 1)
+}
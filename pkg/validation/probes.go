@@ -0,0 +1,168 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	ProbeUrlAnnotation       = "kluctl.io/validate-probe-url"
+	ProbeStatusAnnotation    = "kluctl.io/validate-probe-status"
+	ProbeBodyRegexAnnotation = "kluctl.io/validate-probe-body-regex"
+	ProbeTimeoutAnnotation   = "kluctl.io/validate-probe-timeout"
+)
+
+const defaultProbeTimeout = 10 * time.Second
+
+// limit the amount of body we read, as we only need it for regex matching
+const maxProbeBodySize = 1024 * 1024
+
+type probe struct {
+	url       string
+	statuses  []int
+	bodyRegex *regexp.Regexp
+	timeout   time.Duration
+}
+
+// HasProbe returns true if the object declares a validation probe
+func HasProbe(o *uo.UnstructuredObject) bool {
+	return o.GetK8sAnnotation(ProbeUrlAnnotation) != nil
+}
+
+func parseProbe(o *uo.UnstructuredObject) (*probe, error) {
+	p := &probe{
+		statuses: []int{http.StatusOK},
+		timeout:  defaultProbeTimeout,
+	}
+
+	urlTmpl, err := template.New("url").Option("missingkey=error").Parse(*o.GetK8sAnnotation(ProbeUrlAnnotation))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", ProbeUrlAnnotation, err)
+	}
+	buf := bytes.NewBuffer(nil)
+	err = urlTmpl.Execute(buf, o.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s annotation: %w", ProbeUrlAnnotation, err)
+	}
+	p.url = strings.TrimSpace(buf.String())
+
+	if s := o.GetK8sAnnotation(ProbeStatusAnnotation); s != nil {
+		p.statuses = nil
+		for _, x := range strings.Split(*s, ",") {
+			status, err := strconv.ParseInt(strings.TrimSpace(x), 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s annotation: %w", ProbeStatusAnnotation, err)
+			}
+			p.statuses = append(p.statuses, int(status))
+		}
+	}
+	if s := o.GetK8sAnnotation(ProbeBodyRegexAnnotation); s != nil {
+		p.bodyRegex, err = regexp.Compile(*s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s annotation: %w", ProbeBodyRegexAnnotation, err)
+		}
+	}
+	if s := o.GetK8sAnnotation(ProbeTimeoutAnnotation); s != nil {
+		p.timeout, err = time.ParseDuration(*s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s annotation: %w", ProbeTimeoutAnnotation, err)
+		}
+	}
+	return p, nil
+}
+
+func (p *probe) run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{
+		Transport: utils.NewHttpTransport(),
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBodySize))
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, s := range p.statuses {
+		if s == resp.StatusCode {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	if p.bodyRegex != nil && !p.bodyRegex.Match(body) {
+		return fmt.Errorf("response body does not match %s", p.bodyRegex.String())
+	}
+	return nil
+}
+
+// ValidateProbe executes the probe declared via the kluctl.io/validate-probe-url annotation. The outcome of the probe
+// is always reported as a result entry. A failing probe marks the object as not ready and is additionally reported
+// as an error if notReadyIsError is true. Invalid probe configurations are always reported as errors.
+func ValidateProbe(ctx context.Context, o *uo.UnstructuredObject, notReadyIsError bool) (ret result.ValidateResult) {
+	ref := o.GetK8sRef()
+
+	ret.Ready = true
+
+	if !HasProbe(o) || o.GetK8sAnnotationBoolNoError("kluctl.io/validate-ignore", false) {
+		return
+	}
+
+	addError := func(msg string) {
+		ret.Ready = false
+		ret.Errors = append(ret.Errors, result.DeploymentError{Ref: ref, Message: msg})
+	}
+
+	p, err := parseProbe(o)
+	if err != nil {
+		addError(fmt.Sprintf("invalid probe: %s", err.Error()))
+		return
+	}
+
+	err = p.run(ctx)
+	if err != nil {
+		msg := fmt.Sprintf("probe %s failed: %s", p.url, err.Error())
+		ret.Ready = false
+		ret.Results = append(ret.Results, result.ValidateResultEntry{
+			Ref:        ref,
+			Annotation: ProbeUrlAnnotation,
+			Message:    msg,
+		})
+		if notReadyIsError {
+			addError(msg)
+		}
+		return
+	}
+
+	ret.Results = append(ret.Results, result.ValidateResultEntry{
+		Ref:        ref,
+		Annotation: ProbeUrlAnnotation,
+		Message:    fmt.Sprintf("probe %s succeeded", p.url),
+	})
+	return
+}
@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newProbeTestObject(annotations map[string]string) *uo.UnstructuredObject {
+	o := uo.New()
+	_ = o.SetNestedField("v1", "apiVersion")
+	_ = o.SetNestedField("Service", "kind")
+	o.SetK8sName("svc")
+	o.SetK8sNamespace("ns")
+	for k, v := range annotations {
+		o.SetK8sAnnotation(k, v)
+	}
+	return o
+}
+
+func TestValidateProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ns/svc":
+			_, _ = w.Write([]byte(`{"status": "ok"}`))
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	type testCase struct {
+		name        string
+		annotations map[string]string
+		ready       bool
+		errors      int
+		results     int
+	}
+
+	tests := []testCase{
+		{name: "no-probe", annotations: nil, ready: true},
+		{name: "template", annotations: map[string]string{
+			ProbeUrlAnnotation: server.URL + "/{{ .metadata.namespace }}/{{ .metadata.name }}",
+		}, ready: true, results: 1},
+		{name: "body-match", annotations: map[string]string{
+			ProbeUrlAnnotation:       server.URL + "/ns/svc",
+			ProbeBodyRegexAnnotation: `"status":\s*"ok"`,
+		}, ready: true, results: 1},
+		{name: "body-mismatch", annotations: map[string]string{
+			ProbeUrlAnnotation:       server.URL + "/ns/svc",
+			ProbeBodyRegexAnnotation: `"status":\s*"failed"`,
+		}, ready: false, errors: 1, results: 1},
+		{name: "status", annotations: map[string]string{
+			ProbeUrlAnnotation:    server.URL + "/created",
+			ProbeStatusAnnotation: "200, 201",
+		}, ready: true, results: 1},
+		{name: "unexpected-status", annotations: map[string]string{
+			ProbeUrlAnnotation: server.URL + "/missing",
+		}, ready: false, errors: 1, results: 1},
+		{name: "invalid-template", annotations: map[string]string{
+			ProbeUrlAnnotation: server.URL + "/{{ .metadata.missing }}",
+		}, ready: false, errors: 1},
+		{name: "invalid-timeout", annotations: map[string]string{
+			ProbeUrlAnnotation:     server.URL + "/ns/svc",
+			ProbeTimeoutAnnotation: "invalid",
+		}, ready: false, errors: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := ValidateProbe(context.Background(), newProbeTestObject(tc.annotations), true)
+			assert.Equal(t, tc.ready, r.Ready)
+			assert.Len(t, r.Errors, tc.errors)
+			assert.Len(t, r.Results, tc.results)
+		})
+	}
+}
+
+func TestValidateProbeNotReadyIsNoError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	r := ValidateProbe(context.Background(), newProbeTestObject(map[string]string{
+		ProbeUrlAnnotation: server.URL,
+	}), false)
+	assert.False(t, r.Ready)
+	assert.Empty(t, r.Errors)
+	assert.Len(t, r.Results, 1)
+}
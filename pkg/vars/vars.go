@@ -64,6 +64,15 @@ func (vc *VarsCtx) RenderStruct(o interface{}) (bool, error) {
 	return vc.J2.RenderStruct(o, jinja2.WithGlobals(globals))
 }
 
+// RenderStrings renders all jobs with the vars as globals. Errors are reported per job, see jinja2.RenderJob.
+func (vc *VarsCtx) RenderStrings(jobs []*jinja2.RenderJob) error {
+	globals, err := vc.Vars.ToMap()
+	if err != nil {
+		return err
+	}
+	return vc.J2.RenderStrings(jobs, jinja2.WithGlobals(globals))
+}
+
 func (vc *VarsCtx) RenderFile(p string, searchDirs []string) (string, error) {
 	globals, err := vc.Vars.ToMap()
 	if err != nil {
@@ -81,15 +90,16 @@ func (vc *VarsCtx) RenderFile(p string, searchDirs []string) (string, error) {
 }
 
 func (vc *VarsCtx) RenderYamlFile(p string, searchDirs []string, out interface{}) error {
+	_, err := vc.RenderYamlFileWithOptions(p, searchDirs, out, yaml.ReadOptions{})
+	return err
+}
+
+func (vc *VarsCtx) RenderYamlFileWithOptions(p string, searchDirs []string, out interface{}, opts yaml.ReadOptions) ([]yaml.UnknownField, error) {
 	rendered, err := vc.RenderFile(p, searchDirs)
 	if err != nil {
-		return err
-	}
-	err = yaml.ReadYamlString(rendered, out)
-	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return yaml.ReadYamlStringWithOptions(rendered, out, opts)
 }
 
 func (vc *VarsCtx) RenderDirectory(sourceDir string, targetDir string, excludePatterns []string, searchDirs []string, templateIgnoreRoot string) error {
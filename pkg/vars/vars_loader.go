@@ -6,23 +6,29 @@ import (
 	errors2 "errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	types2 "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
 	"github.com/getsops/sops/v3/cmd/sops/formats"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
 	"github.com/kluctl/kluctl/lib/go-jinja2"
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
 	"github.com/kluctl/kluctl/v2/pkg/clouds/azure"
 	"github.com/kluctl/kluctl/v2/pkg/clouds/gcp"
+	"github.com/kluctl/kluctl/v2/pkg/deprecations"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/projectlock"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
 	"github.com/kluctl/kluctl/v2/pkg/sops"
 	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/kluctl/kluctl/v2/pkg/vars/vault"
@@ -45,6 +51,9 @@ type VarsLoader struct {
 	gcp  gcp.GcpClientFactory
 
 	credentialsCache map[string]usernamePassword
+
+	warnings      []result.DeploymentError
+	warningsMutex sync.Mutex
 }
 
 func NewVarsLoader(ctx context.Context, k *k8s.K8sCluster, sops *decryptor.Decryptor, rp *repocache.GitRepoCache, aws aws.AwsClientFactory, gcp gcp.GcpClientFactory) *VarsLoader {
@@ -59,9 +68,33 @@ func NewVarsLoader(ctx context.Context, k *k8s.K8sCluster, sops *decryptor.Decry
 	}
 }
 
+// LoadVarsList loads and merges all vars sources of the list. Entries are processed in the order of their position,
+// unless the merge order is overridden via 'order'.
 func (v *VarsLoader) LoadVarsList(ctx context.Context, varsCtx *VarsCtx, varsList []types.VarsSource, searchDirs []string, rootKey string) error {
-	for i, _ := range varsList {
+	indexes := make([]int, len(varsList))
+	for i := range varsList {
+		indexes[i] = i
+	}
+	getOrder := func(i int) int {
+		if varsList[i].Order == nil {
+			return 0
+		}
+		return *varsList[i].Order
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return getOrder(indexes[i]) < getOrder(indexes[j])
+	})
+
+	traceVars := utils.IsDebugTopicEnabled(ctx, "vars")
+	if traceVars && len(varsList) != 0 {
+		status.Tracef(ctx, "vars: merging %d vars sources in the following order", len(varsList))
+	}
+	for _, i := range indexes {
 		source := &varsList[i]
+		if traceVars {
+			sourceType, ident := describeVarsSource(source)
+			status.Tracef(ctx, "vars: entry %d (order=%d): %s %s", i, getOrder(i), sourceType, ident)
+		}
 		err := v.LoadVars(ctx, varsCtx, source, searchDirs, rootKey)
 		if err != nil {
 			return err
@@ -115,6 +148,7 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 
 	var newValue any
 	var sensitive bool
+	var gitCommit string
 	if source.Values != nil {
 		if rootKey != "" {
 			newValue = uo.FromMap(map[string]interface{}{
@@ -123,10 +157,12 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 		} else {
 			newValue = source.Values
 		}
+	} else if source.File != nil && isGlobPattern(*source.File) {
+		newValue, sensitive, err = v.loadFileGlob(ctx, varsCtx, &source, *source.File, ignoreMissing, searchDirs, multidoc)
 	} else if source.File != nil {
 		newValue, sensitive, err = v.loadFile(varsCtx, *source.File, ignoreMissing, searchDirs, multidoc)
 	} else if source.Git != nil {
-		newValue, sensitive, err = v.loadGit(ctx, varsCtx, source.Git, ignoreMissing, multidoc)
+		newValue, sensitive, gitCommit, err = v.loadGit(ctx, varsCtx, &source, ignoreMissing, multidoc)
 	} else if source.GitFiles != nil {
 		newValue, sensitive, err = v.loadGitFiles(ctx, varsCtx, source.GitFiles, ignoreMissing)
 	} else if source.ClusterConfigMap != nil {
@@ -158,7 +194,10 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 		return fmt.Errorf("invalid vars source")
 	}
 	if err != nil {
-		return err
+		newValue, err = v.handleLoadError(&source, err)
+		if err != nil {
+			return err
+		}
 	}
 
 	if sourceIn.Sensitive != nil {
@@ -194,6 +233,11 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 	sourceIn.RenderedSensitive = sensitive
 	sourceIn.RenderedVars = newVars.Clone()
 
+	err = v.recordLock(ctx, &source, newVars, sensitive, gitCommit)
+	if err != nil {
+		return err
+	}
+
 	if source.NoOverride == nil || !*source.NoOverride {
 		varsCtx.Vars.Merge(newVars)
 	} else {
@@ -204,6 +248,35 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 	return nil
 }
 
+// recordLock records the content hash of vars that were loaded from git or http sources, together with the commit for
+// git sources. Sensitive vars are not recorded.
+func (v *VarsLoader) recordLock(ctx context.Context, source *types.VarsSource, newVars *uo.UnstructuredObject, sensitive bool, gitCommit string) error {
+	r := projectlock.GetRecorder(ctx)
+	if r == nil || sensitive {
+		return nil
+	}
+
+	var l types.VarsSourceLock
+	if source.Git != nil {
+		l.Type = "git"
+		l.Source = fmt.Sprintf("%s#%s:%s", source.Git.Url.String(), source.Git.Ref.String(), source.Git.Path)
+		l.Commit = gitCommit
+	} else if source.Http != nil {
+		l.Type = "http"
+		l.Source = source.Http.Url.Redacted()
+	} else {
+		return nil
+	}
+
+	b, err := yaml.WriteYamlBytes(newVars)
+	if err != nil {
+		return err
+	}
+	l.Hash = "sha256:" + utils.Sha256Bytes(b)
+	r.RecordVarsSource(l)
+	return nil
+}
+
 func (v *VarsLoader) mergeVars(varsCtx *VarsCtx, newVars *uo.UnstructuredObject, rootKey string) {
 	if rootKey == "" {
 		varsCtx.Update(newVars)
@@ -243,6 +316,87 @@ func (v *VarsLoader) loadFile(varsCtx *VarsCtx, path string, ignoreMissing bool,
 	return newVars, sensitive, nil
 }
 
+func isGlobPattern(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// loadFileGlob loads all vars files matching the given pattern. The pattern is matched relative to all search dirs and
+// the matching files are merged in lexical order.
+func (v *VarsLoader) loadFileGlob(ctx context.Context, varsCtx *VarsCtx, source *types.VarsSource, pattern string, ignoreMissing bool, searchDirs []string, multidoc bool) (any, bool, error) {
+	if multidoc {
+		return nil, false, fmt.Errorf("multidoc can not be used with the vars file pattern %s", pattern)
+	}
+
+	matches, err := globSearchDirs(pattern, searchDirs)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid vars file pattern %s: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		if ignoreMissing {
+			return uo.New(), false, nil
+		}
+		err = fmt.Errorf("vars file pattern %s did not match any files", pattern)
+		if source.OnMissingGlobMatch == nil || *source.OnMissingGlobMatch != types.VarsSourceOnMissingGlobMatchWarn {
+			return nil, false, err
+		}
+		status.Warning(ctx, err.Error())
+		v.addWarning(err.Error())
+		return uo.New(), false, nil
+	}
+
+	traceVars := utils.IsDebugTopicEnabled(ctx, "vars")
+	newVars := uo.New()
+	sensitive := false
+	for _, m := range matches {
+		if traceVars {
+			status.Tracef(ctx, "vars: merging %s (matched by %s)", m, pattern)
+		}
+		x, s, err := v.loadFile(varsCtx, m, false, searchDirs, false)
+		if err != nil {
+			return nil, false, err
+		}
+		newVars.Merge(x.(*uo.UnstructuredObject))
+		sensitive = sensitive || s
+	}
+	return newVars, sensitive, nil
+}
+
+// globSearchDirs returns the sorted and de-duplicated list of files matching the pattern. Relative patterns are matched
+// inside all search dirs and the results are relative to the search dir they were found in.
+func globSearchDirs(pattern string, searchDirs []string) ([]string, error) {
+	if filepath.IsAbs(pattern) {
+		searchDirs = []string{""}
+	}
+
+	found := map[string]bool{}
+	for _, dir := range searchDirs {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if utils.IsDirectory(m) {
+				continue
+			}
+			if dir != "" {
+				m, err = filepath.Rel(dir, m)
+				if err != nil {
+					return nil, err
+				}
+				m = filepath.ToSlash(m)
+			}
+			found[m] = true
+		}
+	}
+
+	ret := make([]string, 0, len(found))
+	for m := range found {
+		ret = append(ret, m)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
 func (v *VarsLoader) loadSystemEnvs(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool, rootKey string) (*uo.UnstructuredObject, error) {
 	newVars := uo.New()
 	err := source.SystemEnvVars.NewIterator().IterateLeafs(func(it *uo.ObjectIterator) error {
@@ -361,18 +515,46 @@ func (v *VarsLoader) loadVault(varsCtx *VarsCtx, source *types.VarsSource, ignor
 	return v.loadFromString(varsCtx, *secret, multidoc)
 }
 
-func (v *VarsLoader) loadGit(ctx context.Context, varsCtx *VarsCtx, gitFile *types.VarsSourceGit, ignoreMissing bool, multidoc bool) (any, bool, error) {
+// loadGit loads the vars file (or all files matching the glob pattern) from the given git repository. The repository
+// is cloned via the repo cache, so that authentication and caching is shared with git includes. Returns the checked out
+// commit as well.
+func (v *VarsLoader) loadGit(ctx context.Context, varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool, multidoc bool) (any, bool, string, error) {
+	gitFile := source.Git
+	doError := func(err error) (any, bool, string, error) {
+		return nil, false, "", fmt.Errorf("failed to load vars from git repository %s (ref: %s, path: %s): %w", gitFile.Url.String(), describeGitRef(gitFile.Ref), gitFile.Path, err)
+	}
+
 	ge, err := v.rp.GetEntry(gitFile.Url.String())
 	if err != nil {
-		return nil, false, err
+		return doError(err)
+	}
+
+	clonedDir, ci, err := ge.GetClonedDir(gitFile.Ref)
+	if err != nil {
+		return doError(err)
 	}
 
-	clonedDir, _, err := ge.GetClonedDir(gitFile.Ref)
+	var newValue any
+	var sensitive bool
+	if isGlobPattern(gitFile.Path) {
+		newValue, sensitive, err = v.loadFileGlob(ctx, varsCtx, source, gitFile.Path, ignoreMissing, []string{clonedDir}, multidoc)
+	} else {
+		newValue, sensitive, err = v.loadFile(varsCtx, gitFile.Path, ignoreMissing, []string{clonedDir}, multidoc)
+	}
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to load vars from git repository %s: %w", gitFile.Url.String(), err)
+		return doError(err)
 	}
+	return newValue, sensitive, ci.CheckedOutCommit, nil
+}
 
-	return v.loadFile(varsCtx, gitFile.Path, ignoreMissing, []string{clonedDir}, multidoc)
+func describeGitRef(ref *gittypes.GitRef) string {
+	if ref == nil {
+		return "default branch"
+	}
+	if ref.Commit != "" {
+		return ref.Commit
+	}
+	return ref.String()
 }
 
 func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource types.VarsSourceClusterConfigMapOrSecret, kind string, ignoreMissing bool, multidoc bool, base64Decode bool) (any, error) {
@@ -449,7 +631,7 @@ func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource t
 	if varsSource.TargetPath == "" {
 		return parsed, nil
 	} else {
-		status.Deprecation(v.ctx, "cm-or-secret-target-path", "'targetPath' in clusterConfigMap and clusterSecret is deprecated, use the common 'targetPath' property from one level above instead.")
+		deprecations.Report(v.ctx, deprecations.CmOrSecretTargetPath)
 
 		p, err := uo.NewMyJsonPath(varsSource.TargetPath)
 		if err != nil {
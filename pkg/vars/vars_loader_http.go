@@ -12,16 +12,19 @@ import (
 	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/prompts"
 	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 )
 
 func (v *VarsLoader) doHttp(httpSource *types.VarsSourceHttp, ignoreMissing bool, username string, password string) (*http.Response, string, error) {
+	transport := utils.NewHttpTransport()
+	// This disables HTTP2.0 support, as it does not play well together with NTLM
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+
 	client := &http.Client{
 		Transport: ntlmssp.Negotiator{
-			RoundTripper: &http.Transport{
-				// This disables HTTP2.0 support, as it does not play well together with NTLM
-				TLSNextProto: make(map[string]func(string, *tls.Conn) http.RoundTripper),
-			},
+			RoundTripper: transport,
 		},
 	}
 
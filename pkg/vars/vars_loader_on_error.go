@@ -0,0 +1,143 @@
+package vars
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+func (v *VarsLoader) addWarning(msg string) {
+	v.warningsMutex.Lock()
+	defer v.warningsMutex.Unlock()
+	v.warnings = append(v.warnings, result.DeploymentError{Message: msg})
+}
+
+// GetWarnings returns the warnings which resulted from vars sources with onError set to "warn"
+func (v *VarsLoader) GetWarnings() []result.DeploymentError {
+	v.warningsMutex.Lock()
+	defer v.warningsMutex.Unlock()
+	return append([]result.DeploymentError{}, v.warnings...)
+}
+
+// handleLoadError decides, based on onError, whether the error is returned or replaced by the default vars of the
+// source.
+func (v *VarsLoader) handleLoadError(source *types.VarsSource, err error) (any, error) {
+	onError := types.VarsSourceOnErrorFail
+	if source.OnError != nil {
+		onError = *source.OnError
+	}
+
+	switch onError {
+	case types.VarsSourceOnErrorWarn, types.VarsSourceOnErrorIgnore:
+	default:
+		return nil, err
+	}
+
+	if onError == types.VarsSourceOnErrorWarn {
+		v.addWarning(buildVarsSourceErrorMessage(source, err))
+	}
+
+	if source.Default != nil {
+		return source.Default.Clone(), nil
+	}
+	return uo.New(), nil
+}
+
+// buildVarsSourceErrorMessage builds a message that identifies the failed vars source. Only non-sensitive
+// properties of the source are included, e.g. credentials, query parameters and headers of http sources are omitted.
+func buildVarsSourceErrorMessage(source *types.VarsSource, err error) string {
+	sourceType, ident := describeVarsSource(source)
+
+	errStr := err.Error()
+	if source.Http != nil {
+		// http errors usually contain the full url, but not necessarily in the same form, e.g. url.Error redacts the
+		// password but keeps the query
+		u := source.Http.Url.URL
+		for _, x := range []string{u.String(), u.Redacted()} {
+			errStr = strings.ReplaceAll(errStr, x, ident)
+		}
+	}
+	errStr = redactUrlsInText(errStr)
+
+	if ident != "" {
+		return fmt.Sprintf("failed to load vars from %s vars source (%s): %s", sourceType, ident, errStr)
+	}
+	return fmt.Sprintf("failed to load vars from %s vars source: %s", sourceType, errStr)
+}
+
+func describeVarsSource(source *types.VarsSource) (string, string) {
+	switch {
+	case source.File != nil:
+		return "file", *source.File
+	case source.Git != nil:
+		return "git", fmt.Sprintf("url=%s, path=%s", redactUrl(source.Git.Url.URL), source.Git.Path)
+	case source.GitFiles != nil:
+		return "gitFiles", fmt.Sprintf("url=%s", redactUrl(source.GitFiles.Url.URL))
+	case source.ClusterConfigMap != nil:
+		return "clusterConfigMap", describeConfigMapOrSecret(source.ClusterConfigMap)
+	case source.ClusterSecret != nil:
+		return "clusterSecret", describeConfigMapOrSecret(source.ClusterSecret)
+	case source.ClusterObject != nil:
+		o := source.ClusterObject
+		return "clusterObject", fmt.Sprintf("kind=%s, namespace=%s, %s, path=%s", o.Kind, o.Namespace, describeNameOrLabels(o.Name, o.Labels), o.Path)
+	case source.SystemEnvVars != nil:
+		return "systemEnvVars", ""
+	case source.Http != nil:
+		return "http", redactUrl(source.Http.Url.URL)
+	case source.AwsSecretsManager != nil:
+		return "awsSecretsManager", fmt.Sprintf("secretName=%s", source.AwsSecretsManager.SecretName)
+	case source.GcpSecretManager != nil:
+		return "gcpSecretManager", fmt.Sprintf("secretName=%s", source.GcpSecretManager.SecretName)
+	case source.Vault != nil:
+		return "vault", fmt.Sprintf("address=%s, path=%s", source.Vault.Address, source.Vault.Path)
+	case source.AzureKeyVault != nil:
+		return "azureKeyVault", fmt.Sprintf("vaultUri=%s, secretName=%s", source.AzureKeyVault.VaultUri, source.AzureKeyVault.SecretName)
+	}
+	return "unknown", ""
+}
+
+func describeConfigMapOrSecret(s *types.VarsSourceClusterConfigMapOrSecret) string {
+	return fmt.Sprintf("namespace=%s, %s, key=%s", s.Namespace, describeNameOrLabels(s.Name, s.Labels), s.Key)
+}
+
+func describeNameOrLabels(name string, labels map[string]string) string {
+	if name != "" {
+		return fmt.Sprintf("name=%s", name)
+	}
+	var l []string
+	for k, v := range labels {
+		l = append(l, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(l)
+	return fmt.Sprintf("labels=%s", strings.Join(l, ","))
+}
+
+// urlRegex finds URLs in free text, e.g. error messages
+var urlRegex = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>]+`)
+
+// redactUrlsInText removes credentials, query parameters and fragments from all URLs found in s
+func redactUrlsInText(s string) string {
+	return urlRegex.ReplaceAllStringFunc(s, func(m string) string {
+		u, err := url.Parse(m)
+		if err != nil {
+			// we can't tell which part is sensitive
+			return "<redacted-url>"
+		}
+		return redactUrl(*u)
+	})
+}
+
+func redactUrl(u url.URL) string {
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/webui"
 	"github.com/tkrajina/typescriptify-golang-structs/typescriptify"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -16,6 +17,7 @@ func main() {
 		WithBackupDir("").
 		Add(result.CommandResult{}).
 		Add(result.CommandResultSummary{}).
+		Add(result.CommandResultIndexEntry{}).
 		Add(result.ValidateResult{}).
 		Add(result.ValidateResultSummary{}).
 		Add(result.DriftDetectionResult{}).
@@ -30,6 +32,9 @@ func main() {
 		ManageType(types.YamlUrl{}, typescriptify.TypeOptions{TSType: "string"}).
 		ManageType(uo.UnstructuredObject{}, typescriptify.TypeOptions{TSType: "any"}).
 		ManageType(metav1.Time{}, typescriptify.TypeOptions{TSType: "string"}).
+		ManageType(metav1.MicroTime{}, typescriptify.TypeOptions{TSType: "string"}).
+		ManageType(metav1.Duration{}, typescriptify.TypeOptions{TSType: "string"}).
+		ManageType(resource.Quantity{}, typescriptify.TypeOptions{TSType: "string"}).
 		ManageType(apiextensionsv1.JSON{}, typescriptify.TypeOptions{TSType: "any"})
 
 	converter.AddImport("import { GitRef } from './models-static'")
@@ -137,6 +137,7 @@ func (s *CommandResultsServer) Run(host string, port int, openBrowser bool) erro
 
 	api := router.Group("/api", s.auth.authHandler)
 	api.GET("/getShortNames", s.getShortNames)
+	api.GET("/listCommandResultIndex", s.listCommandResultIndex)
 	api.GET("/getCommandResult", s.getCommandResult)
 	api.GET("/getCommandResultObject", s.getCommandResultObject)
 	api.GET("/getValidateResult", s.getValidateResult)
@@ -336,6 +337,17 @@ func (s *CommandResultsServer) checkObjectAccess(c *gin.Context, user *User, o *
 	return true, o
 }
 
+// listCommandResultIndex returns the lightweight index entries of the command results of all clusters. Full results
+// must be fetched via getCommandResult.
+func (s *CommandResultsServer) listCommandResultIndex(c *gin.Context) {
+	entries, err := s.store.ListCommandResultIndex(results.ListResultSummariesOptions{})
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
 func (s *CommandResultsServer) getCommandResult(c *gin.Context) {
 	var params resultIdParam
 
@@ -367,6 +379,7 @@ func (s *CommandResultsServer) getCommandResult(c *gin.Context) {
 		_, o.Rendered = s.checkObjectAccess(c, user, o.Rendered, "rendered")
 		_, o.Remote = s.checkObjectAccess(c, user, o.Remote, "remote")
 		_, o.Applied = s.checkObjectAccess(c, user, o.Applied, "applied")
+		_, o.DeletedManifest = s.checkObjectAccess(c, user, o.DeletedManifest, "deletedManifest")
 	}
 
 	c.JSON(http.StatusOK, sr)
@@ -431,6 +444,8 @@ func (s *CommandResultsServer) getCommandResultObject(c *gin.Context) {
 		ok, o2 = s.checkObjectAccess(c, user, found.Remote, objectType.ObjectType)
 	case "applied":
 		ok, o2 = s.checkObjectAccess(c, user, found.Applied, objectType.ObjectType)
+	case "deletedManifest":
+		ok, o2 = s.checkObjectAccess(c, user, found.DeletedManifest, objectType.ObjectType)
 	default:
 		c.AbortWithStatus(http.StatusNotFound)
 		return
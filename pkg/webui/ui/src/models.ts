@@ -2,14 +2,446 @@
 
 import { GitRef } from './models-static'
 
+export class CommandResultSignature {
+    algorithm: string;
+    keyId: string;
+    publicKey: string;
+    signature: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.algorithm = source["algorithm"];
+        this.keyId = source["keyId"];
+        this.publicKey = source["publicKey"];
+        this.signature = source["signature"];
+    }
+}
+export class VarsSourceLock {
+    type: string;
+    source: string;
+    hash: string;
+    commit?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.type = source["type"];
+        this.source = source["source"];
+        this.hash = source["hash"];
+        this.commit = source["commit"];
+    }
+}
+export class ImageLock {
+    image: string;
+    object: string;
+    container?: string;
+    resultImage?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.image = source["image"];
+        this.object = source["object"];
+        this.container = source["container"];
+        this.resultImage = source["resultImage"];
+    }
+}
+export class HelmChartLock {
+    repo: string;
+    chart: string;
+    version: string;
+    digest: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.repo = source["repo"];
+        this.chart = source["chart"];
+        this.version = source["version"];
+        this.digest = source["digest"];
+    }
+}
+export class OciLock {
+    url: string;
+    ref?: string;
+    digest: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.url = source["url"];
+        this.ref = source["ref"];
+        this.digest = source["digest"];
+    }
+}
+export class GitLock {
+    url: string;
+    ref?: string;
+    commit: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.url = source["url"];
+        this.ref = source["ref"];
+        this.commit = source["commit"];
+    }
+}
+export class ProjectLock {
+    git?: GitLock[];
+    oci?: OciLock[];
+    helmCharts?: HelmChartLock[];
+    images?: ImageLock[];
+    varsSources?: VarsSourceLock[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.git = this.convertValues(source["git"], GitLock);
+        this.oci = this.convertValues(source["oci"], OciLock);
+        this.helmCharts = this.convertValues(source["helmCharts"], HelmChartLock);
+        this.images = this.convertValues(source["images"], ImageLock);
+        this.varsSources = this.convertValues(source["varsSources"], VarsSourceLock);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class ConfirmationDecision {
+    category: string;
+    count: number;
+    decision: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.category = source["category"];
+        this.count = source["count"];
+        this.decision = source["decision"];
+    }
+}
+export class ConfirmationInfo {
+    interactive: boolean;
+    decisions?: ConfirmationDecision[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.interactive = source["interactive"];
+        this.decisions = this.convertValues(source["decisions"], ConfirmationDecision);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class OwnershipConflict {
+    ref: ObjectRef;
+    otherDiscriminator: string;
+    otherTarget?: string;
+    otherResultId?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.ref = this.convertValues(source["ref"], ObjectRef);
+        this.otherDiscriminator = source["otherDiscriminator"];
+        this.otherTarget = source["otherTarget"];
+        this.otherResultId = source["otherResultId"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class NamespaceResourceDelta {
+    namespace: string;
+    delta: ResourceQuantities;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.namespace = source["namespace"];
+        this.delta = this.convertValues(source["delta"], ResourceQuantities);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class ResourceQuantities {
+    cpuRequests: string;
+    cpuLimits: string;
+    memoryRequests: string;
+    memoryLimits: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.cpuRequests = source["cpuRequests"];
+        this.cpuLimits = source["cpuLimits"];
+        this.memoryRequests = source["memoryRequests"];
+        this.memoryLimits = source["memoryLimits"];
+    }
+}
+export class ResourceDelta {
+    total: ResourceQuantities;
+    namespaces?: NamespaceResourceDelta[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.total = this.convertValues(source["total"], ResourceQuantities);
+        this.namespaces = this.convertValues(source["namespaces"], NamespaceResourceDelta);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class CacheStats {
+    hits: number;
+    misses: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.hits = source["hits"];
+        this.misses = source["misses"];
+    }
+}
+export class CommandStats {
+    wallTime: string;
+    apiCalls?: {[key: string]: number};
+    fetchedBytes?: {[key: string]: number};
+    caches?: {[key: string]: CacheStats};
+    renderedTemplates?: number;
+    peakMemory?: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.wallTime = source["wallTime"];
+        this.apiCalls = source["apiCalls"];
+        this.fetchedBytes = source["fetchedBytes"];
+        this.caches = this.convertValues(source["caches"], CacheStats, true);
+        this.renderedTemplates = source["renderedTemplates"];
+        this.peakMemory = source["peakMemory"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class CommandTimings {
+    render?: string;
+    diff?: string;
+    apply?: string;
+    hooks?: string;
+    prune?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.render = source["render"];
+        this.diff = source["diff"];
+        this.apply = source["apply"];
+        this.hooks = source["hooks"];
+        this.prune = source["prune"];
+    }
+}
+export class FetchTiming {
+    type: string;
+    source: string;
+    startTime: string;
+    endTime: string;
+    attempts: number;
+    deduplicated?: number;
+    error?: string;
+    bytes?: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.type = source["type"];
+        this.source = source["source"];
+        this.startTime = source["startTime"];
+        this.endTime = source["endTime"];
+        this.attempts = source["attempts"];
+        this.deduplicated = source["deduplicated"];
+        this.error = source["error"];
+        this.bytes = source["bytes"];
+    }
+}
+export class Phase {
+    type: string;
+    deploymentItem?: string;
+    startTime: string;
+    endTime: string;
+    objects?: ObjectRef[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.type = source["type"];
+        this.deploymentItem = source["deploymentItem"];
+        this.startTime = source["startTime"];
+        this.endTime = source["endTime"];
+        this.objects = this.convertValues(source["objects"], ObjectRef);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class RerunJob {
+    ref: ObjectRef;
+    reason: string;
+    status: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.ref = this.convertValues(source["ref"], ObjectRef);
+        this.reason = source["reason"];
+        this.status = source["status"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class DeprecationInfo {
+    id: string;
+    replacement?: string;
+    removedIn: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.id = source["id"];
+        this.replacement = source["replacement"];
+        this.removedIn = source["removedIn"];
+    }
+}
+export class ErrorCause {
+    field?: string;
+    reason?: string;
+    message?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.field = source["field"];
+        this.reason = source["reason"];
+        this.message = source["message"];
+    }
+}
 export class DeploymentError {
     ref: ObjectRef;
     message: string;
+    causes?: ErrorCause[];
+    deprecation?: DeprecationInfo;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
         this.ref = this.convertValues(source["ref"], ObjectRef);
         this.message = source["message"];
+        this.causes = this.convertValues(source["causes"], ErrorCause);
+        this.deprecation = this.convertValues(source["deprecation"], DeprecationInfo);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -30,6 +462,38 @@ export class DeploymentError {
 	    return a;
 	}
 }
+export class ObjectTimings {
+    apply?: string;
+    waitHook?: string;
+    waitReadiness?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.apply = source["apply"];
+        this.waitHook = source["waitHook"];
+        this.waitReadiness = source["waitReadiness"];
+    }
+}
+export class ObjectMove {
+    fromItem: string;
+    toItem: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.fromItem = source["fromItem"];
+        this.toItem = source["toItem"];
+    }
+}
+export class OwnershipMigration {
+    from: string[];
+    coOwners?: string[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.from = source["from"];
+        this.coOwners = source["coOwners"];
+    }
+}
 export class Change {
     type: string;
     jsonPath: string;
@@ -49,25 +513,39 @@ export class Change {
 export class ResultObject {
     ref: ObjectRef;
     changes?: Change[];
+    ignoredChanges?: Change[];
     new?: boolean;
     orphan?: boolean;
     deleted?: boolean;
     hook?: boolean;
+    securityRelevant?: boolean;
+    obfuscatedByRules?: boolean;
+    ownershipMigration?: OwnershipMigration;
+    moved?: ObjectMove;
+    timings?: ObjectTimings;
     rendered?: any;
     remote?: any;
     applied?: any;
+    deletedManifest?: any;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
         this.ref = this.convertValues(source["ref"], ObjectRef);
         this.changes = this.convertValues(source["changes"], Change);
+        this.ignoredChanges = this.convertValues(source["ignoredChanges"], Change);
         this.new = source["new"];
         this.orphan = source["orphan"];
         this.deleted = source["deleted"];
         this.hook = source["hook"];
+        this.securityRelevant = source["securityRelevant"];
+        this.obfuscatedByRules = source["obfuscatedByRules"];
+        this.ownershipMigration = this.convertValues(source["ownershipMigration"], OwnershipMigration);
+        this.moved = this.convertValues(source["moved"], ObjectMove);
+        this.timings = this.convertValues(source["timings"], ObjectTimings);
         this.rendered = source["rendered"];
         this.remote = source["remote"];
         this.applied = source["applied"];
+        this.deletedManifest = source["deletedManifest"];
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -88,6 +566,30 @@ export class ResultObject {
 	    return a;
 	}
 }
+export class ObfuscationRule {
+    group?: string;
+    kind?: string;
+    fieldPath: string[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.fieldPath = source["fieldPath"];
+    }
+}
+export class ObjectOrderConfig {
+    group?: string;
+    kind?: string;
+    weight: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.weight = source["weight"];
+    }
+}
 export class ConflictResolutionConfig {
     fieldPath?: string[];
     fieldPathRegex?: string[];
@@ -110,6 +612,20 @@ export class ConflictResolutionConfig {
         this.action = source["action"];
     }
 }
+export class ListMergeKeysConfig {
+    group?: string;
+    kind?: string;
+    fieldPath: string;
+    keys: string[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.fieldPath = source["fieldPath"];
+        this.keys = source["keys"];
+    }
+}
 export class IgnoreForDiffItemConfig {
     fieldPath?: string[];
     fieldPathRegex?: string[];
@@ -178,6 +694,58 @@ export class HelmChartConfig {
 	    return a;
 	}
 }
+export class PostDeployWaitDnsLookupConfig {
+    hostsFieldPath: string;
+    addressesFieldPath?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.hostsFieldPath = source["hostsFieldPath"];
+        this.addressesFieldPath = source["addressesFieldPath"];
+    }
+}
+export class PostDeployWaitConfig {
+    group?: string;
+    kind?: string;
+    name: string;
+    namespace?: string;
+    fields?: string[];
+    conditions?: string[];
+    dnsLookups?: PostDeployWaitDnsLookupConfig[];
+    timeout?: string;
+    severity?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.name = source["name"];
+        this.namespace = source["namespace"];
+        this.fields = source["fields"];
+        this.conditions = source["conditions"];
+        this.dnsLookups = this.convertValues(source["dnsLookups"], PostDeployWaitDnsLookupConfig);
+        this.timeout = source["timeout"];
+        this.severity = source["severity"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class WaitReadinessObjectItemConfig {
     group?: string;
     kind?: string;
@@ -206,6 +774,44 @@ export class DeleteObjectItemConfig {
         this.namespace = source["namespace"];
     }
 }
+export class YamlBundleUrl {
+    url: string;
+    sha256: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.url = source["url"];
+        this.sha256 = source["sha256"];
+    }
+}
+export class YamlBundleConfig {
+    urls: YamlBundleUrl[];
+    template?: boolean;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.urls = this.convertValues(source["urls"], YamlBundleUrl);
+        this.template = source["template"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class OciRef {
     digest?: string;
     tag?: string;
@@ -263,12 +869,14 @@ export class DeploymentItemConfig {
     include?: string;
     git?: GitProject;
     oci?: OciProject;
+    yamlBundle?: YamlBundleConfig;
     deleteObjects?: DeleteObjectItemConfig[];
     tags?: string[];
     barrier?: boolean;
     message?: string;
     waitReadiness?: boolean;
     waitReadinessObjects?: WaitReadinessObjectItemConfig[];
+    postDeployWaits?: PostDeployWaitConfig[];
     args?: any;
     passVars?: boolean;
     vars?: VarsSource[];
@@ -286,12 +894,14 @@ export class DeploymentItemConfig {
         this.include = source["include"];
         this.git = this.convertValues(source["git"], GitProject);
         this.oci = this.convertValues(source["oci"], OciProject);
+        this.yamlBundle = this.convertValues(source["yamlBundle"], YamlBundleConfig);
         this.deleteObjects = this.convertValues(source["deleteObjects"], DeleteObjectItemConfig);
         this.tags = source["tags"];
         this.barrier = source["barrier"];
         this.message = source["message"];
         this.waitReadiness = source["waitReadiness"];
         this.waitReadinessObjects = this.convertValues(source["waitReadinessObjects"], WaitReadinessObjectItemConfig);
+        this.postDeployWaits = this.convertValues(source["postDeployWaits"], PostDeployWaitConfig);
         this.args = source["args"];
         this.passVars = source["passVars"];
         this.vars = this.convertValues(source["vars"], VarsSource);
@@ -479,6 +1089,10 @@ export class VarsSource {
     noOverride?: boolean;
     sensitive?: boolean;
     multidoc?: boolean;
+    order?: number;
+    onMissingGlobMatch?: string;
+    onError?: string;
+    default?: any;
     values?: any;
     file?: string;
     git?: VarsSourceGit;
@@ -499,27 +1113,335 @@ export class VarsSource {
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.ignoreMissing = source["ignoreMissing"];
-        this.noOverride = source["noOverride"];
-        this.sensitive = source["sensitive"];
-        this.multidoc = source["multidoc"];
-        this.values = source["values"];
-        this.file = source["file"];
-        this.git = this.convertValues(source["git"], VarsSourceGit);
-        this.gitFiles = this.convertValues(source["gitFiles"], VarsSourceGitFiles);
-        this.clusterConfigMap = this.convertValues(source["clusterConfigMap"], VarsSourceClusterConfigMapOrSecret);
-        this.clusterSecret = this.convertValues(source["clusterSecret"], VarsSourceClusterConfigMapOrSecret);
-        this.clusterObject = this.convertValues(source["clusterObject"], VarsSourceClusterObject);
-        this.systemEnvVars = source["systemEnvVars"];
-        this.http = this.convertValues(source["http"], VarsSourceHttp);
-        this.awsSecretsManager = this.convertValues(source["awsSecretsManager"], VarsSourceAwsSecretsManager);
-        this.gcpSecretManager = this.convertValues(source["gcpSecretManager"], VarsSourceGcpSecretManager);
-        this.vault = this.convertValues(source["vault"], VarsSourceVault);
-        this.azureKeyVault = this.convertValues(source["azureKeyVault"], VarSourceAzureKeyVault);
-        this.targetPath = source["targetPath"];
-        this.when = source["when"];
-        this.renderedSensitive = source["renderedSensitive"];
-        this.renderedVars = source["renderedVars"];
+        this.ignoreMissing = source["ignoreMissing"];
+        this.noOverride = source["noOverride"];
+        this.sensitive = source["sensitive"];
+        this.multidoc = source["multidoc"];
+        this.order = source["order"];
+        this.onMissingGlobMatch = source["onMissingGlobMatch"];
+        this.onError = source["onError"];
+        this.default = source["default"];
+        this.values = source["values"];
+        this.file = source["file"];
+        this.git = this.convertValues(source["git"], VarsSourceGit);
+        this.gitFiles = this.convertValues(source["gitFiles"], VarsSourceGitFiles);
+        this.clusterConfigMap = this.convertValues(source["clusterConfigMap"], VarsSourceClusterConfigMapOrSecret);
+        this.clusterSecret = this.convertValues(source["clusterSecret"], VarsSourceClusterConfigMapOrSecret);
+        this.clusterObject = this.convertValues(source["clusterObject"], VarsSourceClusterObject);
+        this.systemEnvVars = source["systemEnvVars"];
+        this.http = this.convertValues(source["http"], VarsSourceHttp);
+        this.awsSecretsManager = this.convertValues(source["awsSecretsManager"], VarsSourceAwsSecretsManager);
+        this.gcpSecretManager = this.convertValues(source["gcpSecretManager"], VarsSourceGcpSecretManager);
+        this.vault = this.convertValues(source["vault"], VarsSourceVault);
+        this.azureKeyVault = this.convertValues(source["azureKeyVault"], VarSourceAzureKeyVault);
+        this.targetPath = source["targetPath"];
+        this.when = source["when"];
+        this.renderedSensitive = source["renderedSensitive"];
+        this.renderedVars = source["renderedVars"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class DeploymentProjectConfig {
+    vars?: VarsSource[];
+    when?: string;
+    deployments?: DeploymentItemConfig[];
+    commonLabels?: {[key: string]: string};
+    commonAnnotations?: {[key: string]: string};
+    overrideNamespace?: string;
+    tags?: string[];
+    ignoreForDiff?: IgnoreForDiffItemConfig[];
+    listMergeKeys?: ListMergeKeysConfig[];
+    conflictResolution?: ConflictResolutionConfig[];
+    objectOrder?: ObjectOrderConfig[];
+    obfuscate?: ObfuscationRule[];
+    argoCDCompatibility?: boolean;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.vars = this.convertValues(source["vars"], VarsSource);
+        this.when = source["when"];
+        this.deployments = this.convertValues(source["deployments"], DeploymentItemConfig);
+        this.commonLabels = source["commonLabels"];
+        this.commonAnnotations = source["commonAnnotations"];
+        this.overrideNamespace = source["overrideNamespace"];
+        this.tags = source["tags"];
+        this.ignoreForDiff = this.convertValues(source["ignoreForDiff"], IgnoreForDiffItemConfig);
+        this.listMergeKeys = this.convertValues(source["listMergeKeys"], ListMergeKeysConfig);
+        this.conflictResolution = this.convertValues(source["conflictResolution"], ConflictResolutionConfig);
+        this.objectOrder = this.convertValues(source["objectOrder"], ObjectOrderConfig);
+        this.obfuscate = this.convertValues(source["obfuscate"], ObfuscationRule);
+        this.argoCDCompatibility = source["argoCDCompatibility"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class ClusterInfo {
+    clusterId: string;
+    clusterIdSource?: string;
+    kubeconfigSource?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.clusterId = source["clusterId"];
+        this.clusterIdSource = source["clusterIdSource"];
+        this.kubeconfigSource = source["kubeconfigSource"];
+    }
+}
+export class GitInfo {
+    url?: string;
+    ref?: GitRef;
+    subDir: string;
+    commit: string;
+    dirty: boolean;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.url = source["url"];
+        this.ref = new GitRef(source["ref"]);
+        this.subDir = source["subDir"];
+        this.commit = source["commit"];
+        this.dirty = source["dirty"];
+    }
+}
+export class KluctlDeploymentInfo {
+    name: string;
+    namespace: string;
+    clusterId: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.namespace = source["namespace"];
+        this.clusterId = source["clusterId"];
+    }
+}
+export class InvocationInfo {
+    args?: string[];
+    env?: {[key: string]: string};
+    kluctlVersion?: string;
+    goVersion?: string;
+    platform?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.args = source["args"];
+        this.env = source["env"];
+        this.kluctlVersion = source["kluctlVersion"];
+        this.goVersion = source["goVersion"];
+        this.platform = source["platform"];
+    }
+}
+export class CommandInfo {
+    initiator: string;
+    startTime: string;
+    endTime: string;
+    command?: string;
+    target?: string;
+    targetNameOverride?: string;
+    contextOverride?: string;
+    args?: any;
+    images?: FixedImage[];
+    dryRun?: boolean;
+    readOnly?: boolean;
+    noWait?: boolean;
+    forceApply?: boolean;
+    replaceOnError?: boolean;
+    forceReplaceOnError?: boolean;
+    abortOnError?: boolean;
+    overrideSafetyThreshold?: boolean;
+    includeTags?: string[];
+    excludeTags?: string[];
+    includeDeploymentDirs?: string[];
+    excludeDeploymentDirs?: string[];
+    invocation?: InvocationInfo;
+    rollbackSourceResultId?: string;
+    hookRunId?: string;
+    partial?: boolean;
+    partialNote?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.initiator = source["initiator"];
+        this.startTime = source["startTime"];
+        this.endTime = source["endTime"];
+        this.command = source["command"];
+        this.target = source["target"];
+        this.targetNameOverride = source["targetNameOverride"];
+        this.contextOverride = source["contextOverride"];
+        this.args = source["args"];
+        this.images = this.convertValues(source["images"], FixedImage);
+        this.dryRun = source["dryRun"];
+        this.readOnly = source["readOnly"];
+        this.noWait = source["noWait"];
+        this.forceApply = source["forceApply"];
+        this.replaceOnError = source["replaceOnError"];
+        this.forceReplaceOnError = source["forceReplaceOnError"];
+        this.abortOnError = source["abortOnError"];
+        this.overrideSafetyThreshold = source["overrideSafetyThreshold"];
+        this.includeTags = source["includeTags"];
+        this.excludeTags = source["excludeTags"];
+        this.includeDeploymentDirs = source["includeDeploymentDirs"];
+        this.excludeDeploymentDirs = source["excludeDeploymentDirs"];
+        this.invocation = this.convertValues(source["invocation"], InvocationInfo);
+        this.rollbackSourceResultId = source["rollbackSourceResultId"];
+        this.hookRunId = source["hookRunId"];
+        this.partial = source["partial"];
+        this.partialNote = source["partialNote"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class ConfirmationConfig {
+    acknowledge?: string[];
+    abortNonInteractive?: string[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.acknowledge = source["acknowledge"];
+        this.abortNonInteractive = source["abortNonInteractive"];
+    }
+}
+export class ConfigMapNotificationConfig {
+    namespace?: string;
+    keep?: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.namespace = source["namespace"];
+        this.keep = source["keep"];
+    }
+}
+export class SlackNotificationConfig {
+    webhookUrlEnv: string;
+    webuiUrl?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.webhookUrlEnv = source["webhookUrlEnv"];
+        this.webuiUrl = source["webuiUrl"];
+    }
+}
+export class NotificationsConfig {
+    slack?: SlackNotificationConfig;
+    configMap?: ConfigMapNotificationConfig;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.slack = this.convertValues(source["slack"], SlackNotificationConfig);
+        this.configMap = this.convertValues(source["configMap"], ConfigMapNotificationConfig);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class SafetyThresholdConfig {
+    maxChangedObjects?: number;
+    maxDeletedObjects?: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.maxChangedObjects = source["maxChangedObjects"];
+        this.maxDeletedObjects = source["maxDeletedObjects"];
+    }
+}
+export class S3ResultStoreConfig {
+    bucket: string;
+    prefix?: string;
+    region?: string;
+    profile?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.bucket = source["bucket"];
+        this.prefix = source["prefix"];
+        this.region = source["region"];
+        this.profile = source["profile"];
+    }
+}
+export class ClusterResultStoreConfig {
+    namespace?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.namespace = source["namespace"];
+    }
+}
+export class ResultStoreConfig {
+    type: string;
+    name?: string;
+    cluster?: ClusterResultStoreConfig;
+    s3?: S3ResultStoreConfig;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.type = source["type"];
+        this.name = source["name"];
+        this.cluster = this.convertValues(source["cluster"], ClusterResultStoreConfig);
+        this.s3 = this.convertValues(source["s3"], S3ResultStoreConfig);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -540,28 +1462,12 @@ export class VarsSource {
 	    return a;
 	}
 }
-export class DeploymentProjectConfig {
-    vars?: VarsSource[];
-    when?: string;
-    deployments?: DeploymentItemConfig[];
-    commonLabels?: {[key: string]: string};
-    commonAnnotations?: {[key: string]: string};
-    overrideNamespace?: string;
-    tags?: string[];
-    ignoreForDiff?: IgnoreForDiffItemConfig[];
-    conflictResolution?: ConflictResolutionConfig[];
+export class ResultsConfig {
+    stores: ResultStoreConfig[];
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.vars = this.convertValues(source["vars"], VarsSource);
-        this.when = source["when"];
-        this.deployments = this.convertValues(source["deployments"], DeploymentItemConfig);
-        this.commonLabels = source["commonLabels"];
-        this.commonAnnotations = source["commonAnnotations"];
-        this.overrideNamespace = source["overrideNamespace"];
-        this.tags = source["tags"];
-        this.ignoreForDiff = this.convertValues(source["ignoreForDiff"], IgnoreForDiffItemConfig);
-        this.conflictResolution = this.convertValues(source["conflictResolution"], ConflictResolutionConfig);
+        this.stores = this.convertValues(source["stores"], ResultStoreConfig);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -582,84 +1488,62 @@ export class DeploymentProjectConfig {
 	    return a;
 	}
 }
-export class ClusterInfo {
-    clusterId: string;
+export class ChangelogRule {
+    group?: string;
+    kind?: string;
+    changeType?: string;
+    fieldPathRegex: string;
+    message: string;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.clusterId = source["clusterId"];
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.changeType = source["changeType"];
+        this.fieldPathRegex = source["fieldPathRegex"];
+        this.message = source["message"];
     }
 }
-export class GitInfo {
-    url?: string;
-    ref?: GitRef;
-    subDir: string;
-    commit: string;
-    dirty: boolean;
+export class ChangelogConfig {
+    rules?: ChangelogRule[];
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.url = source["url"];
-        this.ref = new GitRef(source["ref"]);
-        this.subDir = source["subDir"];
-        this.commit = source["commit"];
-        this.dirty = source["dirty"];
+        this.rules = this.convertValues(source["rules"], ChangelogRule);
     }
-}
-export class KluctlDeploymentInfo {
-    name: string;
-    namespace: string;
-    clusterId: string;
 
-    constructor(source: any = {}) {
-        if ('string' === typeof source) source = JSON.parse(source);
-        this.name = source["name"];
-        this.namespace = source["namespace"];
-        this.clusterId = source["clusterId"];
-    }
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
 }
-export class CommandInfo {
-    initiator: string;
-    startTime: string;
-    endTime: string;
-    command?: string;
-    target?: string;
-    targetNameOverride?: string;
-    contextOverride?: string;
-    args?: any;
-    images?: FixedImage[];
-    dryRun?: boolean;
-    noWait?: boolean;
-    forceApply?: boolean;
-    replaceOnError?: boolean;
-    forceReplaceOnError?: boolean;
-    abortOnError?: boolean;
-    includeTags?: string[];
-    excludeTags?: string[];
-    includeDeploymentDirs?: string[];
-    excludeDeploymentDirs?: string[];
+export class OutputConfig {
+    formats?: string[];
+    shortOutput?: boolean;
+    noObfuscate?: boolean;
+    changelog?: ChangelogConfig;
+    securitySensitiveKinds?: string[];
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.initiator = source["initiator"];
-        this.startTime = source["startTime"];
-        this.endTime = source["endTime"];
-        this.command = source["command"];
-        this.target = source["target"];
-        this.targetNameOverride = source["targetNameOverride"];
-        this.contextOverride = source["contextOverride"];
-        this.args = source["args"];
-        this.images = this.convertValues(source["images"], FixedImage);
-        this.dryRun = source["dryRun"];
-        this.noWait = source["noWait"];
-        this.forceApply = source["forceApply"];
-        this.replaceOnError = source["replaceOnError"];
-        this.forceReplaceOnError = source["forceReplaceOnError"];
-        this.abortOnError = source["abortOnError"];
-        this.includeTags = source["includeTags"];
-        this.excludeTags = source["excludeTags"];
-        this.includeDeploymentDirs = source["includeDeploymentDirs"];
-        this.excludeDeploymentDirs = source["excludeDeploymentDirs"];
+        this.formats = source["formats"];
+        this.shortOutput = source["shortOutput"];
+        this.noObfuscate = source["noObfuscate"];
+        this.changelog = this.convertValues(source["changelog"], ChangelogConfig);
+        this.securitySensitiveKinds = source["securitySensitiveKinds"];
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -778,22 +1662,94 @@ export class AwsConfig {
 	    return a;
 	}
 }
+export class KubeconfigExecSource {
+    command: string[];
+    env?: {[key: string]: string};
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.command = source["command"];
+        this.env = source["env"];
+    }
+}
+export class KubeconfigSecretSource {
+    context?: string;
+    namespace: string;
+    name: string;
+    key?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.context = source["context"];
+        this.namespace = source["namespace"];
+        this.name = source["name"];
+        this.key = source["key"];
+    }
+}
+export class KubeconfigSource {
+    file?: string;
+    inCluster?: boolean;
+    secret?: KubeconfigSecretSource;
+    exec?: KubeconfigExecSource;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.file = source["file"];
+        this.inCluster = source["inCluster"];
+        this.secret = this.convertValues(source["secret"], KubeconfigSecretSource);
+        this.exec = this.convertValues(source["exec"], KubeconfigExecSource);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class Target {
     name: string;
     context?: string;
+    kubeconfigFrom?: KubeconfigSource;
     args?: any;
     aws?: AwsConfig;
     images?: FixedImage[];
     discriminator?: string;
+    output?: OutputConfig;
+    results?: ResultsConfig;
+    clusterId?: string;
+    namespaceOverride?: string;
+    safetyThreshold?: SafetyThresholdConfig;
+    notifications?: NotificationsConfig;
+    confirmation?: ConfirmationConfig;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
         this.name = source["name"];
         this.context = source["context"];
+        this.kubeconfigFrom = this.convertValues(source["kubeconfigFrom"], KubeconfigSource);
         this.args = source["args"];
         this.aws = this.convertValues(source["aws"], AwsConfig);
         this.images = this.convertValues(source["images"], FixedImage);
         this.discriminator = source["discriminator"];
+        this.output = this.convertValues(source["output"], OutputConfig);
+        this.results = this.convertValues(source["results"], ResultsConfig);
+        this.clusterId = source["clusterId"];
+        this.namespaceOverride = source["namespaceOverride"];
+        this.safetyThreshold = this.convertValues(source["safetyThreshold"], SafetyThresholdConfig);
+        this.notifications = this.convertValues(source["notifications"], NotificationsConfig);
+        this.confirmation = this.convertValues(source["confirmation"], ConfirmationConfig);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -853,6 +1809,16 @@ export class CommandResult {
     errors?: DeploymentError[];
     warnings?: DeploymentError[];
     seenImages?: FixedImage[];
+    rerunJobs?: RerunJob[];
+    phases?: Phase[];
+    fetches?: FetchTiming[];
+    timings?: CommandTimings;
+    stats?: CommandStats;
+    resourceDelta?: ResourceDelta;
+    ownershipConflicts?: OwnershipConflict[];
+    confirmation?: ConfirmationInfo;
+    projectLock?: ProjectLock;
+    signature?: CommandResultSignature;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -872,6 +1838,16 @@ export class CommandResult {
         this.errors = this.convertValues(source["errors"], DeploymentError);
         this.warnings = this.convertValues(source["warnings"], DeploymentError);
         this.seenImages = this.convertValues(source["seenImages"], FixedImage);
+        this.rerunJobs = this.convertValues(source["rerunJobs"], RerunJob);
+        this.phases = this.convertValues(source["phases"], Phase);
+        this.fetches = this.convertValues(source["fetches"], FetchTiming);
+        this.timings = this.convertValues(source["timings"], CommandTimings);
+        this.stats = this.convertValues(source["stats"], CommandStats);
+        this.resourceDelta = this.convertValues(source["resourceDelta"], ResourceDelta);
+        this.ownershipConflicts = this.convertValues(source["ownershipConflicts"], OwnershipConflict);
+        this.confirmation = this.convertValues(source["confirmation"], ConfirmationInfo);
+        this.projectLock = this.convertValues(source["projectLock"], ProjectLock);
+        this.signature = this.convertValues(source["signature"], CommandResultSignature);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -892,6 +1868,22 @@ export class CommandResult {
 	    return a;
 	}
 }
+export class KindCounts {
+    new?: number;
+    changed?: number;
+    deleted?: number;
+    orphan?: number;
+    appliedHooks?: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.new = source["new"];
+        this.changed = source["changed"];
+        this.deleted = source["deleted"];
+        this.orphan = source["orphan"];
+        this.appliedHooks = source["appliedHooks"];
+    }
+}
 export class CommandResultSummary {
     id: string;
     reconcileId: string;
@@ -911,6 +1903,9 @@ export class CommandResultSummary {
     changedObjects: number;
     orphanObjects: number;
     deletedObjects: number;
+    movedObjects?: number;
+    securityRelevantObjects?: number;
+    kinds?: {[key: string]: KindCounts};
     errors: DeploymentError[];
     warnings: DeploymentError[];
     totalChanges: number;
@@ -935,6 +1930,9 @@ export class CommandResultSummary {
         this.changedObjects = source["changedObjects"];
         this.orphanObjects = source["orphanObjects"];
         this.deletedObjects = source["deletedObjects"];
+        this.movedObjects = source["movedObjects"];
+        this.securityRelevantObjects = source["securityRelevantObjects"];
+        this.kinds = this.convertValues(source["kinds"], KindCounts, true);
         this.errors = this.convertValues(source["errors"], DeploymentError);
         this.warnings = this.convertValues(source["warnings"], DeploymentError);
         this.totalChanges = source["totalChanges"];
@@ -958,6 +1956,88 @@ export class CommandResultSummary {
 	    return a;
 	}
 }
+export class CommandResultCounts {
+    newObjects: number;
+    changedObjects: number;
+    deletedObjects: number;
+    orphanObjects: number;
+    appliedHookObjects: number;
+    errors: number;
+    warnings: number;
+    securityRelevantObjects?: number;
+    kinds?: {[key: string]: KindCounts};
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.newObjects = source["newObjects"];
+        this.changedObjects = source["changedObjects"];
+        this.deletedObjects = source["deletedObjects"];
+        this.orphanObjects = source["orphanObjects"];
+        this.appliedHookObjects = source["appliedHookObjects"];
+        this.errors = source["errors"];
+        this.warnings = source["warnings"];
+        this.securityRelevantObjects = source["securityRelevantObjects"];
+        this.kinds = this.convertValues(source["kinds"], KindCounts, true);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class CommandResultIndexEntry {
+    id: string;
+    projectKey: ProjectKey;
+    targetKey: TargetKey;
+    clusterId: string;
+    command: string;
+    startTime: string;
+    endTime: string;
+    counts: CommandResultCounts;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.id = source["id"];
+        this.projectKey = this.convertValues(source["projectKey"], ProjectKey);
+        this.targetKey = this.convertValues(source["targetKey"], TargetKey);
+        this.clusterId = source["clusterId"];
+        this.command = source["command"];
+        this.startTime = source["startTime"];
+        this.endTime = source["endTime"];
+        this.counts = this.convertValues(source["counts"], CommandResultCounts);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class ValidateResultEntry {
     ref: ObjectRef;
     annotation: string;
@@ -996,9 +2076,11 @@ export class ValidateResult {
     kluctlDeployment?: KluctlDeploymentInfo;
     overridesPatch?: any;
     renderedObjectsHash?: string;
+    sourceResultId?: string;
     startTime: string;
     endTime: string;
     ready: boolean;
+    readOnly?: boolean;
     warnings?: DeploymentError[];
     errors?: DeploymentError[];
     results?: ValidateResultEntry[];
@@ -1012,9 +2094,11 @@ export class ValidateResult {
         this.kluctlDeployment = this.convertValues(source["kluctlDeployment"], KluctlDeploymentInfo);
         this.overridesPatch = source["overridesPatch"];
         this.renderedObjectsHash = source["renderedObjectsHash"];
+        this.sourceResultId = source["sourceResultId"];
         this.startTime = source["startTime"];
         this.endTime = source["endTime"];
         this.ready = source["ready"];
+        this.readOnly = source["readOnly"];
         this.warnings = this.convertValues(source["warnings"], DeploymentError);
         this.errors = this.convertValues(source["errors"], DeploymentError);
         this.results = this.convertValues(source["results"], ValidateResultEntry);
@@ -1045,6 +2129,7 @@ export class ValidateResultSummary {
     targetKey: TargetKey;
     kluctlDeployment?: KluctlDeploymentInfo;
     renderedObjectsHash?: string;
+    sourceResultId?: string;
     startTime: string;
     endTime: string;
     ready: boolean;
@@ -1060,6 +2145,7 @@ export class ValidateResultSummary {
         this.targetKey = this.convertValues(source["targetKey"], TargetKey);
         this.kluctlDeployment = this.convertValues(source["kluctlDeployment"], KluctlDeploymentInfo);
         this.renderedObjectsHash = source["renderedObjectsHash"];
+        this.sourceResultId = source["sourceResultId"];
         this.startTime = source["startTime"];
         this.endTime = source["endTime"];
         this.ready = source["ready"];
@@ -1089,20 +2175,32 @@ export class ValidateResultSummary {
 export class DriftedObject {
     ref: ObjectRef;
     changes?: Change[];
+    ignoredChanges?: Change[];
     new?: boolean;
     orphan?: boolean;
     deleted?: boolean;
     hook?: boolean;
+    securityRelevant?: boolean;
+    obfuscatedByRules?: boolean;
+    ownershipMigration?: OwnershipMigration;
+    moved?: ObjectMove;
+    timings?: ObjectTimings;
     lastResourceVersion: string;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
         this.ref = this.convertValues(source["ref"], ObjectRef);
         this.changes = this.convertValues(source["changes"], Change);
+        this.ignoredChanges = this.convertValues(source["ignoredChanges"], Change);
         this.new = source["new"];
         this.orphan = source["orphan"];
         this.deleted = source["deleted"];
         this.hook = source["hook"];
+        this.securityRelevant = source["securityRelevant"];
+        this.obfuscatedByRules = source["obfuscatedByRules"];
+        this.ownershipMigration = this.convertValues(source["ownershipMigration"], OwnershipMigration);
+        this.moved = this.convertValues(source["moved"], ObjectMove);
+        this.timings = this.convertValues(source["timings"], ObjectTimings);
         this.lastResourceVersion = source["lastResourceVersion"];
     }
 
@@ -0,0 +1,116 @@
+// Package yamlbundle implements fetching of plain YAML bundles from URLs. Fetched content is verified against the
+// pinned sha256 digest and cached by digest, so that later runs do not need network access.
+package yamlbundle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+)
+
+// OutputFileName is the name of the file that receives the fetched bundles inside the rendered deployment item dir
+const OutputFileName = "yaml-bundle-rendered.yaml"
+
+const cacheFileName = "bundle.yaml"
+
+func GetCacheDir(ctx context.Context) string {
+	return filepath.Join(utils.GetCacheDir(ctx), "yaml-bundles")
+}
+
+func digest(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func readCached(ctx context.Context, expected string) ([]byte, bool) {
+	p := filepath.Join(GetCacheDir(ctx), expected, cacheFileName)
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+	if digest(b) != expected {
+		// corrupted cache entry, fetch it again
+		_ = os.RemoveAll(filepath.Dir(p))
+		return nil, false
+	}
+	return b, true
+}
+
+func writeCached(ctx context.Context, expected string, b []byte) error {
+	dir := filepath.Join(GetCacheDir(ctx), expected)
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that concurrent readers never see partial content
+	tmp, err := os.CreateTemp(dir, cacheFileName+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, cacheFileName))
+}
+
+func fetch(ctx context.Context, u *types.YamlUrl) ([]byte, error) {
+	transport := utils.NewHttpTransport()
+	transport.Proxy = http.ProxyFromEnvironment
+	client := &http.Client{Transport: transport}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("request failed with status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Fetch returns the content of the given bundle url. The content is served from the cache if it was fetched before,
+// otherwise it is downloaded and verified against the pinned digest before it gets cached.
+func Fetch(ctx context.Context, u types.YamlBundleUrl) ([]byte, error) {
+	expected := strings.ToLower(u.Sha256)
+
+	if b, ok := readCached(ctx, expected); ok {
+		return b, nil
+	}
+
+	b, err := fetch(ctx, &u.Url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching yaml bundle from %s failed: %w", u.Url.Redacted(), err)
+	}
+
+	actual := digest(b)
+	if actual != expected {
+		return nil, fmt.Errorf("checksum mismatch for yaml bundle %s: expected sha256 %s, got sha256 %s", u.Url.Redacted(), expected, actual)
+	}
+
+	err = writeCached(ctx, expected, b)
+	if err != nil {
+		return nil, fmt.Errorf("caching yaml bundle from %s failed: %w", u.Url.Redacted(), err)
+	}
+	return b, nil
+}
@@ -0,0 +1,95 @@
+package yamlbundle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const testBundle = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`
+
+func newTestUrl(t *testing.T, u string, sha256 string) types.YamlBundleUrl {
+	u2, err := url.Parse(u)
+	assert.NoError(t, err)
+	return types.YamlBundleUrl{Url: types.YamlUrl{URL: *u2}, Sha256: sha256}
+}
+
+func TestFetch(t *testing.T) {
+	ctx := utils.WithCacheDir(context.Background(), t.TempDir())
+
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/install.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(testBundle))
+	}))
+	defer s.Close()
+
+	u := newTestUrl(t, s.URL+"/install.yaml", digest([]byte(testBundle)))
+	b, err := Fetch(ctx, u)
+	assert.NoError(t, err)
+	assert.Equal(t, testBundle, string(b))
+	assert.Equal(t, 1, requests)
+	assert.FileExists(t, filepath.Join(GetCacheDir(ctx), u.Sha256, cacheFileName))
+
+	// served from the cache without network access
+	s.Close()
+	b, err = Fetch(ctx, u)
+	assert.NoError(t, err)
+	assert.Equal(t, testBundle, string(b))
+	assert.Equal(t, 1, requests)
+}
+
+func TestFetchErrors(t *testing.T) {
+	ctx := utils.WithCacheDir(context.Background(), t.TempDir())
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/install.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(testBundle))
+	}))
+	defer s.Close()
+
+	wrongDigest := digest([]byte("other"))
+	_, err := Fetch(ctx, newTestUrl(t, s.URL+"/install.yaml", wrongDigest))
+	assert.EqualError(t, err, "checksum mismatch for yaml bundle "+s.URL+"/install.yaml: expected sha256 "+wrongDigest+", got sha256 "+digest([]byte(testBundle)))
+	assert.NoDirExists(t, filepath.Join(GetCacheDir(ctx), wrongDigest))
+
+	_, err = Fetch(ctx, newTestUrl(t, s.URL+"/missing.yaml", wrongDigest))
+	assert.EqualError(t, err, "fetching yaml bundle from "+s.URL+"/missing.yaml failed: request failed with status code 404")
+}
+
+func TestFetchCorruptedCache(t *testing.T) {
+	ctx := utils.WithCacheDir(context.Background(), t.TempDir())
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testBundle))
+	}))
+	defer s.Close()
+
+	u := newTestUrl(t, s.URL+"/install.yaml", digest([]byte(testBundle)))
+	p := filepath.Join(GetCacheDir(ctx), u.Sha256, cacheFileName)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o700))
+	assert.NoError(t, os.WriteFile(p, []byte("corrupted"), 0o600))
+
+	b, err := Fetch(ctx, u)
+	assert.NoError(t, err)
+	assert.Equal(t, testBundle, string(b))
+}